@@ -0,0 +1,87 @@
+package cloud
+
+import (
+	"time"
+)
+
+// DefaultCallPolicyKey is the CallPolicies key of the policy
+// for CPI methods that do not have a policy of their own
+const DefaultCallPolicyKey = "default"
+
+// DefaultRetryDelay is the delay before the first retry when a policy does not set one;
+// the delay doubles with every further retry
+const DefaultRetryDelay = 5 * time.Second
+
+// CallPolicy limits how long a CPI call may take and how often
+// it is retried after errors that the CPI reports as ok to retry.
+// Nil values are unset; no timeout and no retries when nothing sets them.
+// Zero values are kept apart from unset ones so that overrides can
+// turn off a timeout or retries set elsewhere.
+type CallPolicy struct {
+	Timeout    *time.Duration
+	Retries    *int
+	RetryDelay *time.Duration
+}
+
+// CallPolicies are keyed by CPI method name (e.g. 'create_vm') or DefaultCallPolicyKey
+type CallPolicies map[string]CallPolicy
+
+// For returns the policy of the method with unset values taken from the default policy
+func (p CallPolicies) For(method string) CallPolicy {
+	return p[DefaultCallPolicyKey].merge(p[method])
+}
+
+// Merge returns policies where the values set in overrides replace those in p
+func (p CallPolicies) Merge(overrides CallPolicies) CallPolicies {
+	merged := CallPolicies{}
+
+	for method, policy := range p {
+		merged[method] = policy
+	}
+
+	for method, policy := range overrides {
+		merged[method] = merged[method].merge(policy)
+	}
+
+	return merged
+}
+
+func (p CallPolicy) merge(override CallPolicy) CallPolicy {
+	if override.Timeout != nil {
+		p.Timeout = override.Timeout
+	}
+
+	if override.Retries != nil {
+		p.Retries = override.Retries
+	}
+
+	if override.RetryDelay != nil {
+		p.RetryDelay = override.RetryDelay
+	}
+
+	return p
+}
+
+// TimeoutOrZero returns the timeout or zero when it is unset
+func (p CallPolicy) TimeoutOrZero() time.Duration {
+	if p.Timeout == nil {
+		return 0
+	}
+	return *p.Timeout
+}
+
+// RetriesOrZero returns the number of retries or zero when it is unset
+func (p CallPolicy) RetriesOrZero() int {
+	if p.Retries == nil {
+		return 0
+	}
+	return *p.Retries
+}
+
+// RetryDelayOrDefault returns the retry delay or DefaultRetryDelay when it is unset
+func (p CallPolicy) RetryDelayOrDefault() time.Duration {
+	if p.RetryDelay == nil || *p.RetryDelay <= 0 {
+		return DefaultRetryDelay
+	}
+	return *p.RetryDelay
+}
@@ -0,0 +1,67 @@
+package cloud_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cloud"
+)
+
+var _ = Describe("CallPolicies", func() {
+	policies := CallPolicies{
+		DefaultCallPolicyKey: {Timeout: duration(time.Hour), Retries: count(1)},
+		"create_vm":          {Retries: count(3), RetryDelay: duration(time.Second)},
+	}
+
+	Describe("For", func() {
+		It("fills in unset values from the default policy", func() {
+			Expect(policies.For("create_vm")).To(Equal(CallPolicy{Timeout: duration(time.Hour), Retries: count(3), RetryDelay: duration(time.Second)}))
+			Expect(policies.For("delete_vm")).To(Equal(CallPolicy{Timeout: duration(time.Hour), Retries: count(1)}))
+		})
+
+		It("keeps zero values set by the method", func() {
+			policies := CallPolicies{
+				DefaultCallPolicyKey: {Timeout: duration(time.Hour), Retries: count(1)},
+				"create_vm":          {Timeout: duration(0), Retries: count(0)},
+			}
+
+			policy := policies.For("create_vm")
+			Expect(policy.TimeoutOrZero()).To(BeZero())
+			Expect(policy.RetriesOrZero()).To(BeZero())
+		})
+
+		It("returns no timeout and no retries without policies", func() {
+			policy := CallPolicies(nil).For("create_vm")
+			Expect(policy).To(Equal(CallPolicy{}))
+			Expect(policy.TimeoutOrZero()).To(BeZero())
+			Expect(policy.RetriesOrZero()).To(BeZero())
+			Expect(policy.RetryDelayOrDefault()).To(Equal(DefaultRetryDelay))
+		})
+	})
+
+	Describe("Merge", func() {
+		It("replaces only the values set in the overrides", func() {
+			merged := policies.Merge(CallPolicies{"create_vm": {Timeout: duration(time.Minute)}})
+
+			Expect(merged["create_vm"]).To(Equal(CallPolicy{Timeout: duration(time.Minute), Retries: count(3), RetryDelay: duration(time.Second)}))
+			Expect(merged[DefaultCallPolicyKey]).To(Equal(CallPolicy{Timeout: duration(time.Hour), Retries: count(1)}))
+			Expect(policies["create_vm"].Timeout).To(BeNil())
+		})
+
+		It("replaces values with zero values set in the overrides", func() {
+			merged := policies.Merge(CallPolicies{"create_vm": {Retries: count(0)}})
+
+			Expect(merged.For("create_vm").RetriesOrZero()).To(BeZero())
+		})
+	})
+})
+
+func duration(d time.Duration) *time.Duration {
+	return &d
+}
+
+func count(c int) *int {
+	return &c
+}
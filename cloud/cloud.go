@@ -33,6 +33,7 @@ type Cloud interface {
 	AttachDisk(vmCID, diskCID string) (interface{}, error)
 	DetachDisk(vmCID, diskCID string) error
 	DeleteDisk(diskCID string) error
+	HasDisk(diskCID string) (bool, error)
 	Info() (cpiInfo CpiInfo, err error)
 	fmt.Stringer
 }
@@ -60,14 +61,7 @@ func NewCloud(
 	logger boshlog.Logger,
 ) Cloud {
 
-	cmdContext := CmdContext{
-		DirectorID: directorID,
-		Vm: &VM{
-			Stemcell: &Stemcell{
-				ApiVersion: stemcellApiVersion,
-			},
-		},
-	}
+	cmdContext := NewCmdContext(directorID, stemcellApiVersion)
 
 	logger.Debug("cloud", "Init cloud with stemcell version: %v", stemcellApiVersion)
 
@@ -79,6 +73,18 @@ func NewCloud(
 	}
 }
 
+// NewCmdContext returns the context sent with every CPI call
+func NewCmdContext(directorID string, stemcellApiVersion int) CmdContext {
+	return CmdContext{
+		DirectorID: directorID,
+		Vm: &VM{
+			Stemcell: &Stemcell{
+				ApiVersion: stemcellApiVersion,
+			},
+		},
+	}
+}
+
 func (c cloud) CreateStemcell(imagePath string, cloudProperties biproperty.Map) (string, error) {
 	c.logger.Debug(c.logTag, "Creating stemcell")
 
@@ -149,6 +155,29 @@ func (c cloud) HasVM(vmCID string) (bool, error) {
 	return found, nil
 }
 
+func (c cloud) HasDisk(diskCID string) (bool, error) {
+	cpiInfo, err := c.Info()
+	if err != nil {
+		return false, err
+	}
+
+	method := "has_disk"
+	cmdOutput, err := c.cpiCmdRunner.Run(c.context, method, cpiInfo.ApiVersion, diskCID)
+	if err != nil {
+		return false, err
+	}
+
+	if cmdOutput.Error != nil {
+		return false, NewCPIError(method, *cmdOutput.Error)
+	}
+
+	found, ok := cmdOutput.Result.(bool)
+	if !ok {
+		return false, bosherr.Errorf("Unexpected external CPI command result: '%#v'", cmdOutput.Result)
+	}
+	return found, nil
+}
+
 func (c cloud) CreateVM(
 	agentID string,
 	stemcellCID string,
@@ -190,8 +219,10 @@ func (c cloud) CreateVM(
 
 	if c.shouldInterpretV2Contract(cpiInfo.ApiVersion) {
 		var result []interface{}
-		if result, ok = cmdOutput.Result.([]interface{}); ok {
+		if result, ok = cmdOutput.Result.([]interface{}); ok && len(result) > 0 {
 			cidString, ok = result[0].(string)
+		} else {
+			ok = false
 		}
 	} else {
 		cidString, ok = cmdOutput.Result.(string)
@@ -367,6 +367,42 @@ var _ = Describe("Cloud", func() {
 		})
 	})
 
+	Describe("HasDisk", func() {
+		It("return true when disk exists", func() {
+			fakeCPICmdRunner.RunCmdOutputs = []CmdOutput{
+				{Result: infoResult},
+				{Result: true},
+			}
+
+			found, err := cloud.HasDisk("fake-disk-cid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			Expect(fakeCPICmdRunner.CurrentRunInput[1]).To(Equal(fakebicloud.RunInput{
+				Context:    expectedContext,
+				Method:     "has_disk",
+				Arguments:  []interface{}{"fake-disk-cid"},
+				ApiVersion: 1,
+			}))
+		})
+
+		It("return false when disk does not exist", func() {
+			fakeCPICmdRunner.RunCmdOutputs = []CmdOutput{
+				{Result: infoResult},
+				{Result: false},
+			}
+
+			found, err := cloud.HasDisk("fake-disk-cid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+
+		itHandlesCPIErrors("has_disk", func() error {
+			_, err := cloud.HasDisk("fake-disk-cid")
+			return err
+		})
+	})
+
 	Describe("CreateVM", func() {
 		var (
 			agentID           string
@@ -473,6 +509,25 @@ var _ = Describe("Cloud", func() {
 						Expect(err.Error()).To(ContainSubstring("Unexpected external CPI command result: '[]interface {}"))
 					})
 				})
+
+				Context("when the cpi's response is an empty array", func() {
+					BeforeEach(func() {
+						fakeCPICmdRunner.RunCmdOutputs = []CmdOutput{
+							{
+								Result: infoResultWithApiV2,
+							},
+							{
+								Result: []interface{}{},
+							},
+						}
+					})
+
+					It("returns error", func() {
+						_, err := cloud.CreateVM(agentID, stemcellCID, cloudProperties, networkInterfaces, env)
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("Unexpected external CPI command result: '[]interface {}{}'"))
+					})
+				})
 			})
 		})
 
@@ -0,0 +1,70 @@
+package cloud
+
+import (
+	"encoding/json"
+	"regexp"
+)
+
+const redactedArgument = "<redacted>"
+
+// credentialKeys hold the agent, message bus and blobstore
+// credentials in the env given to create_vm
+var credentialKeys = map[string]bool{
+	"agent":      true,
+	"mbus":       true,
+	"blobstore":  true,
+	"blobstores": true,
+}
+
+// credentialKeyPattern matches the keys of credentials anywhere in the arguments,
+// e.g. the access keys and passwords in cloud_properties
+var credentialKeyPattern = regexp.MustCompile(`(?i)(password|passphrase|secret|token|credential|private|(^|_)key$)`)
+
+// redactArguments returns the arguments as JSON values
+// with the values of credential keys redacted in all of them
+func redactArguments(args []interface{}) []interface{} {
+	redacted := normalizeArguments(args)
+
+	for _, arg := range redacted {
+		redactCredentials(arg)
+	}
+
+	return redacted
+}
+
+func redactCredentials(value interface{}) {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, nested := range typedValue {
+			if credentialKeys[key] || credentialKeyPattern.MatchString(key) {
+				typedValue[key] = redactedArgument
+			} else {
+				redactCredentials(nested)
+			}
+		}
+	case []interface{}:
+		for _, nested := range typedValue {
+			redactCredentials(nested)
+		}
+	}
+}
+
+// normalizeArguments copies the arguments as they are sent to the CPI,
+// i.e. with maps and slices of any type turned into JSON objects and arrays
+func normalizeArguments(args []interface{}) []interface{} {
+	normalized := []interface{}{}
+
+	if len(args) == 0 {
+		return normalized
+	}
+
+	bytes, err := json.Marshal(args)
+	if err == nil {
+		err = json.Unmarshal(bytes, &normalized)
+	}
+	if err != nil {
+		return append(normalized, args...)
+	}
+
+	return normalized
+}
@@ -0,0 +1,242 @@
+package cloud
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"os"
+	"reflect"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// CPICallRecord is one line of a CPI call recording
+type CPICallRecord struct {
+	Method     string        `json:"method"`
+	Arguments  []interface{} `json:"arguments"`
+	Context    CmdContext    `json:"context"`
+	ApiVersion int           `json:"api_version"`
+
+	Result interface{} `json:"result"`
+	Error  *CmdError   `json:"error,omitempty"`
+	Log    string      `json:"log"`
+
+	// RunError is set when the CPI could not be executed or its output could not be parsed
+	RunError string `json:"run_error,omitempty"`
+
+	DurationMs int64 `json:"duration_ms"`
+}
+
+type recordingCPICmdRunner struct {
+	cmdRunner   CPICmdRunner
+	fs          boshsys.FileSystem
+	timeService clock.Clock
+	path        string
+	logger      boshlog.Logger
+	logTag      string
+
+	lock sync.Mutex
+}
+
+// NewRecordingCPICmdRunner appends every CPI call made through cmdRunner
+// to the JSON Lines file at path. Credentials in the arguments are redacted.
+// The file is synced and closed after each call so that the recording is complete
+// however the command running the CPI ends.
+func NewRecordingCPICmdRunner(
+	cmdRunner CPICmdRunner,
+	fs boshsys.FileSystem,
+	timeService clock.Clock,
+	path string,
+	logger boshlog.Logger,
+) CPICmdRunner {
+	return &recordingCPICmdRunner{
+		cmdRunner:   cmdRunner,
+		fs:          fs,
+		timeService: timeService,
+		path:        path,
+		logger:      logger,
+		logTag:      "recordingCPICmdRunner",
+	}
+}
+
+func (r *recordingCPICmdRunner) Run(context CmdContext, method string, apiVersion int, args ...interface{}) (CmdOutput, error) {
+	startTime := r.timeService.Now()
+
+	cmdOutput, err := r.cmdRunner.Run(context, method, apiVersion, args...)
+
+	record := CPICallRecord{
+		Method:     method,
+		Arguments:  redactArguments(args),
+		Context:    context,
+		ApiVersion: apiVersion,
+		Result:     cmdOutput.Result,
+		Error:      cmdOutput.Error,
+		Log:        cmdOutput.Log,
+		DurationMs: int64(r.timeService.Since(startTime) / time.Millisecond),
+	}
+
+	if err != nil {
+		record.RunError = err.Error()
+	}
+
+	recordErr := r.record(record)
+	if recordErr != nil {
+		// recording is a debugging aid so it should not change the outcome of the call
+		r.logger.Warn(r.logTag, "Failed to record CPI call '%s': %s", method, recordErr.Error())
+	}
+
+	return cmdOutput, err
+}
+
+func (r *recordingCPICmdRunner) record(record CPICallRecord) error {
+	line, err := json.Marshal(record)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling CPI call record")
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	file, err := r.fs.OpenFile(r.path, os.O_WRONLY|os.O_CREATE|os.O_APPEND, 0600)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Opening CPI call recording '%s'", r.path)
+	}
+
+	_, err = file.Write(append(line, '\n'))
+	if err != nil {
+		file.Close() //nolint:errcheck
+		return bosherr.WrapErrorf(err, "Writing CPI call recording '%s'", r.path)
+	}
+
+	if syncer, ok := file.(interface{ Sync() error }); ok {
+		err = syncer.Sync()
+		if err != nil {
+			file.Close() //nolint:errcheck
+			return bosherr.WrapErrorf(err, "Syncing CPI call recording '%s'", r.path)
+		}
+	}
+
+	err = file.Close()
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Closing CPI call recording '%s'", r.path)
+	}
+
+	return nil
+}
+
+type replayCPICmdRunner struct {
+	path    string
+	records []CPICallRecord
+
+	lock sync.Mutex
+	next int
+}
+
+// NewReplayCPICmdRunner answers CPI calls with the responses of a recording
+// made by NewRecordingCPICmdRunner, in the order they were recorded.
+// Calls must have the recorded arguments apart from those that change
+// from run to run, such as agent ids and timestamps.
+func NewReplayCPICmdRunner(fs boshsys.FileSystem, path string) (CPICmdRunner, error) {
+	contents, err := fs.ReadFile(path)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Reading CPI call recording '%s'", path)
+	}
+
+	var records []CPICallRecord
+
+	scanner := bufio.NewScanner(bytes.NewReader(contents))
+	scanner.Buffer(make([]byte, 64*1024), len(contents)+1)
+
+	for lineNum := 1; scanner.Scan(); lineNum++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var record CPICallRecord
+
+		err := json.Unmarshal(line, &record)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Unmarshalling CPI call recording '%s' line %d", path, lineNum)
+		}
+
+		records = append(records, record)
+	}
+
+	err = scanner.Err()
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Reading CPI call recording '%s'", path)
+	}
+
+	return &replayCPICmdRunner{path: path, records: records}, nil
+}
+
+func (r *replayCPICmdRunner) Run(context CmdContext, method string, apiVersion int, args ...interface{}) (CmdOutput, error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.next >= len(r.records) {
+		return CmdOutput{}, bosherr.Errorf("Replaying CPI call '%s': recording '%s' has no more calls", method, r.path)
+	}
+
+	record := r.records[r.next]
+
+	if record.Method != method {
+		return CmdOutput{}, bosherr.Errorf("Replaying CPI call '%s': recording '%s' expected call %d to be '%s'", method, r.path, r.next+1, record.Method)
+	}
+
+	recorded := replayedArguments(method, record.Arguments)
+	actual := replayedArguments(method, redactArguments(args))
+
+	if !reflect.DeepEqual(recorded, actual) {
+		recordedJSON, _ := json.Marshal(recorded) //nolint:errcheck
+		actualJSON, _ := json.Marshal(actual)     //nolint:errcheck
+
+		return CmdOutput{}, bosherr.Errorf(
+			"Replaying CPI call '%s': recording '%s' expected call %d to have arguments %s but got %s",
+			method, r.path, r.next+1, recordedJSON, actualJSON,
+		)
+	}
+
+	r.next++
+
+	if record.RunError != "" {
+		return CmdOutput{}, bosherr.Error(record.RunError)
+	}
+
+	return CmdOutput{
+		Result: record.Result,
+		Error:  record.Error,
+		Log:    record.Log,
+	}, nil
+}
+
+// volatileArguments are the positions of arguments that change from run to run
+var volatileArguments = map[string][]int{
+	"create_vm":       {0}, // agent id
+	"create_stemcell": {0}, // path of the extracted image
+}
+
+// replayedArguments are the arguments that a replayed call is compared by
+func replayedArguments(method string, args []interface{}) []interface{} {
+	comparable := normalizeArguments(args)
+
+	for _, i := range volatileArguments[method] {
+		if i < len(comparable) {
+			comparable[i] = nil
+		}
+	}
+
+	for _, arg := range comparable {
+		if metadata, ok := arg.(map[string]interface{}); ok {
+			delete(metadata, "created_at")
+		}
+	}
+
+	return comparable
+}
@@ -0,0 +1,295 @@
+package cloud_test
+
+import (
+	"encoding/json"
+	"errors"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	fakebicloud "github.com/cloudfoundry/bosh-cli/v7/cloud/fakes"
+)
+
+var _ = Describe("CPI call recording", func() {
+	var (
+		fs               boshsys.FileSystem
+		recordingPath    string
+		fakeCPICmdRunner *fakebicloud.FakeCPICmdRunner
+		logger           boshlog.Logger
+		context          CmdContext
+		notImplemented   CmdOutput
+	)
+
+	BeforeEach(func() {
+		logger = boshlog.NewLogger(boshlog.LevelNone)
+		// the recording is appended to so it needs a real file
+		fs = boshsys.NewOsFileSystem(logger)
+		recordingPath = filepath.Join(GinkgoT().TempDir(), "recording.jsonl")
+		fakeCPICmdRunner = fakebicloud.NewFakeCPICmdRunner()
+		context = CmdContext{DirectorID: "fake-director-id"}
+		notImplemented = CmdOutput{Error: &CmdError{Type: NotImplementedError, Message: "info"}}
+	})
+
+	readRecords := func() []CPICallRecord {
+		contents, err := fs.ReadFileString(recordingPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		var records []CPICallRecord
+		for _, line := range strings.Split(strings.TrimSpace(contents), "\n") {
+			var record CPICallRecord
+			Expect(json.Unmarshal([]byte(line), &record)).To(Succeed())
+			records = append(records, record)
+		}
+		return records
+	}
+
+	Describe("RecordingCPICmdRunner", func() {
+		var (
+			fakeClock *fakeclock.FakeClock
+			recorder  CPICmdRunner
+		)
+
+		BeforeEach(func() {
+			fakeClock = fakeclock.NewFakeClock(time.Now())
+			recorder = NewRecordingCPICmdRunner(fakeCPICmdRunner, fs, fakeClock, recordingPath, logger)
+		})
+
+		It("appends every call with its response and duration", func() {
+			fakeCPICmdRunner.RunCmdOutputs = []CmdOutput{
+				{Result: "fake-vm-cid", Log: "fake-log"},
+				{Result: nil, Error: &CmdError{Type: "Bosh::Clouds::VMNotFound", Message: "gone"}},
+			}
+
+			output, err := recorder.Run(context, "create_vm", 1, "fake-agent-id")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.Result).To(Equal("fake-vm-cid"))
+
+			_, err = recorder.Run(context, "delete_vm", 1, "fake-vm-cid")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readRecords()).To(Equal([]CPICallRecord{
+				{
+					Method:     "create_vm",
+					Arguments:  []interface{}{"fake-agent-id"},
+					Context:    context,
+					ApiVersion: 1,
+					Result:     "fake-vm-cid",
+					Log:        "fake-log",
+				},
+				{
+					Method:     "delete_vm",
+					Arguments:  []interface{}{"fake-vm-cid"},
+					Context:    context,
+					ApiVersion: 1,
+					Error:      &CmdError{Type: "Bosh::Clouds::VMNotFound", Message: "gone"},
+				},
+			}))
+		})
+
+		It("records errors running the CPI and still returns them", func() {
+			fakeCPICmdRunner.RunErrs = []error{errors.New("fake-run-error")}
+
+			_, err := recorder.Run(context, "info", 1)
+			Expect(err).To(MatchError("fake-run-error"))
+
+			Expect(readRecords()[0].RunError).To(Equal("fake-run-error"))
+		})
+
+		It("appends to an existing recording", func() {
+			err := fs.WriteFileString(recordingPath, `{"method":"info","arguments":[]}`+"\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = recorder.Run(context, "has_vm", 1, "fake-vm-cid")
+			Expect(err).ToNot(HaveOccurred())
+
+			records := readRecords()
+			Expect(records).To(HaveLen(2))
+			Expect(records[0].Method).To(Equal("info"))
+			Expect(records[1].Method).To(Equal("has_vm"))
+		})
+
+		It("redacts agent, mbus and blobstore credentials in the env of create_vm", func() {
+			env := biproperty.Map{
+				"bosh": biproperty.Map{
+					"mbus":       biproperty.Map{"cert": biproperty.Map{"private_key": "fake-key"}},
+					"blobstores": []interface{}{biproperty.Map{"options": biproperty.Map{"password": "fake-password"}}},
+					"agent":      biproperty.Map{"env": "fake-agent-env"},
+					"group":      "fake-group",
+				},
+			}
+
+			_, err := recorder.Run(context, "create_vm", 1, "fake-agent-id", "fake-stemcell-cid", biproperty.Map{}, biproperty.Map{}, []interface{}{}, env)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readRecords()[0].Arguments[5]).To(Equal(map[string]interface{}{
+				"bosh": map[string]interface{}{
+					"mbus":       "<redacted>",
+					"blobstores": "<redacted>",
+					"agent":      "<redacted>",
+					"group":      "fake-group",
+				},
+			}))
+
+			contents, err := fs.ReadFileString(recordingPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).ToNot(ContainSubstring("fake-key"))
+			Expect(contents).ToNot(ContainSubstring("fake-password"))
+
+			Expect(fakeCPICmdRunner.CurrentRunInput[0].Arguments[5]).To(Equal(env))
+		})
+
+		It("redacts credentials by key in the arguments of every call", func() {
+			cloudProperties := biproperty.Map{
+				"access_key_id":     "fake-access-key-id",
+				"secret_access_key": "fake-secret-access-key",
+				"encryption": biproperty.Map{
+					"key":       "fake-encryption-key",
+					"key_name":  "fake-key-name",
+					"api_token": "fake-api-token",
+				},
+				"instance_type": "fake-instance-type",
+			}
+
+			_, err := recorder.Run(context, "create_disk", 1, 1024, cloudProperties, "fake-vm-cid")
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(readRecords()[0].Arguments[1]).To(Equal(map[string]interface{}{
+				"access_key_id":     "fake-access-key-id",
+				"secret_access_key": "<redacted>",
+				"encryption": map[string]interface{}{
+					"key":       "<redacted>",
+					"key_name":  "fake-key-name",
+					"api_token": "<redacted>",
+				},
+				"instance_type": "fake-instance-type",
+			}))
+
+			contents, err := fs.ReadFileString(recordingPath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).ToNot(ContainSubstring("fake-secret-access-key"))
+			Expect(contents).ToNot(ContainSubstring("fake-encryption-key"))
+			Expect(contents).ToNot(ContainSubstring("fake-api-token"))
+		})
+
+		It("closes the recording after each call", func() {
+			fakeFS := fakesys.NewFakeFileSystem()
+			recorder = NewRecordingCPICmdRunner(fakeCPICmdRunner, fakeFS, fakeClock, "/recording.jsonl", logger)
+
+			_, err := recorder.Run(context, "info", 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			stats, err := fakeFS.FindFileStats("/recording.jsonl")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(stats.Open).To(BeFalse())
+		})
+
+		It("does not fail the call when the recording cannot be written", func() {
+			fakeFS := fakesys.NewFakeFileSystem()
+			fakeFS.OpenFileErr = errors.New("fake-open-error")
+			recorder = NewRecordingCPICmdRunner(fakeCPICmdRunner, fakeFS, fakeClock, "/recording.jsonl", logger)
+
+			_, err := recorder.Run(context, "info", 1)
+			Expect(err).ToNot(HaveOccurred())
+		})
+	})
+
+	Describe("ReplayCPICmdRunner", func() {
+		BeforeEach(func() {
+			fakeCPICmdRunner.RunCmdOutputs = []CmdOutput{notImplemented, {Result: true}}
+			recordingCloud := NewCloud(NewRecordingCPICmdRunner(fakeCPICmdRunner, fs, fakeclock.NewFakeClock(time.Now()), recordingPath, logger), "fake-director-id", 1, logger)
+
+			found, err := recordingCloud.HasVM("fake-vm-cid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+		})
+
+		It("answers calls with the recorded responses without running the CPI", func() {
+			replay, err := NewReplayCPICmdRunner(fs, recordingPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			cloud := NewCloud(replay, "fake-director-id", 1, logger)
+
+			found, err := cloud.HasVM("fake-vm-cid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+		})
+
+		It("returns an error when calls are made in a different order", func() {
+			replay, err := NewReplayCPICmdRunner(fs, recordingPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = replay.Run(context, "has_vm", 1, "fake-vm-cid")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("expected call 1 to be 'info'"))
+		})
+
+		It("returns an error when a call has other arguments than recorded", func() {
+			replay, err := NewReplayCPICmdRunner(fs, recordingPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = replay.Run(context, "info", 1)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = replay.Run(context, "has_vm", 1, "other-vm-cid")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring(`expected call 2 to have arguments ["fake-vm-cid"] but got ["other-vm-cid"]`))
+		})
+
+		It("ignores arguments that change from run to run", func() {
+			recorder := NewRecordingCPICmdRunner(fakeCPICmdRunner, fs, fakeclock.NewFakeClock(time.Now()), recordingPath, logger)
+			fakeCPICmdRunner.RunCmdOutputs = []CmdOutput{{Result: "fake-vm-cid"}, {Result: nil}}
+
+			_, err := recorder.Run(context, "create_vm", 1, "first-agent-id", "fake-stemcell-cid")
+			Expect(err).ToNot(HaveOccurred())
+			_, err = recorder.Run(context, "set_vm_metadata", 1, "fake-vm-cid", map[string]string{"name": "fake-name", "created_at": "first-time"})
+			Expect(err).ToNot(HaveOccurred())
+
+			replay, err := NewReplayCPICmdRunner(fs, recordingPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = replay.Run(context, "info", 1)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = replay.Run(context, "has_vm", 1, "fake-vm-cid")
+			Expect(err).ToNot(HaveOccurred())
+
+			output, err := replay.Run(context, "create_vm", 1, "second-agent-id", "fake-stemcell-cid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(output.Result).To(Equal("fake-vm-cid"))
+
+			_, err = replay.Run(context, "set_vm_metadata", 1, "fake-vm-cid", map[string]string{"name": "fake-name", "created_at": "second-time"})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns an error when the recording has no more calls", func() {
+			replay, err := NewReplayCPICmdRunner(fs, recordingPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = replay.Run(context, "info", 1)
+			Expect(err).ToNot(HaveOccurred())
+			_, err = replay.Run(context, "has_vm", 1, "fake-vm-cid")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = replay.Run(context, "delete_vm", 1)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("has no more calls"))
+		})
+
+		It("returns an error when the recording is not valid", func() {
+			err := fs.WriteFileString(recordingPath, "not-json\n")
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = NewReplayCPICmdRunner(fs, recordingPath)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("line 1"))
+		})
+	})
+})
@@ -6,7 +6,9 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 
+	"code.cloudfoundry.org/clock"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
@@ -66,23 +68,42 @@ type CPICmdRunner interface {
 	Run(context CmdContext, method string, apiVersion int, args ...interface{}) (CmdOutput, error)
 }
 
+// cpiKillGracePeriod is how long a timed out CPI process may take to exit before it is killed
+const cpiKillGracePeriod = 10 * time.Second
+
 type cpiCmdRunner struct {
-	cmdRunner boshsys.CmdRunner
-	cpi       CPI
-	logger    boshlog.Logger
-	logTag    string
+	cmdRunner   boshsys.CmdRunner
+	cpi         CPI
+	policies    CallPolicies
+	timeService clock.Clock
+	logger      boshlog.Logger
+	logTag      string
 }
 
 func NewCPICmdRunner(
 	cmdRunner boshsys.CmdRunner,
 	cpi CPI,
 	logger boshlog.Logger,
+) CPICmdRunner {
+	return NewCPICmdRunnerWithTimeouts(cmdRunner, cpi, nil, nil, logger)
+}
+
+// NewCPICmdRunnerWithTimeouts terminates CPI processes that run longer
+// than the timeout of the policy for their method
+func NewCPICmdRunnerWithTimeouts(
+	cmdRunner boshsys.CmdRunner,
+	cpi CPI,
+	policies CallPolicies,
+	timeService clock.Clock,
+	logger boshlog.Logger,
 ) CPICmdRunner {
 	return &cpiCmdRunner{
-		cmdRunner: cmdRunner,
-		cpi:       cpi,
-		logger:    logger,
-		logTag:    "cpiCmdRunner",
+		cmdRunner:   cmdRunner,
+		cpi:         cpi,
+		policies:    policies,
+		timeService: timeService,
+		logger:      logger,
+		logTag:      "cpiCmdRunner",
 	}
 }
 
@@ -120,8 +141,13 @@ func (r *cpiCmdRunner) Run(context CmdContext, method string, apiVersion int, ar
 		UseIsolatedEnv: useIsolatedEnv,
 		Stdin:          bytes.NewReader(inputBytes),
 	}
-	stdout, stderr, exitCode, err := r.cmdRunner.RunComplexCommand(cmd)
-	r.logger.Debug(r.logTag, "Exit Code %d when executing external CPI command '%s'\nSTDIN: '%s'\nSTDOUT: '%s'\nSTDERR: '%s'", exitCode, cmdPath, string(inputBytes), stdout, stderr)
+	stdout, stderr, exitCode, err := r.runCommand(cmd, method)
+
+	// the arguments hold credentials that do not belong in logs
+	cmdInput.Arguments = redactArguments(args)
+	redactedInputBytes, _ := json.Marshal(cmdInput) //nolint:errcheck
+
+	r.logger.Debug(r.logTag, "Exit Code %d when executing external CPI command '%s'\nSTDIN: '%s'\nSTDOUT: '%s'\nSTDERR: '%s'", exitCode, cmdPath, string(redactedInputBytes), stdout, stderr)
 	if err != nil {
 		return CmdOutput{}, bosherr.WrapErrorf(err, "Executing external CPI command: '%s'", cmdPath)
 	}
@@ -136,3 +162,29 @@ func (r *cpiCmdRunner) Run(context CmdContext, method string, apiVersion int, ar
 
 	return cmdOutput, err
 }
+
+func (r *cpiCmdRunner) runCommand(cmd boshsys.Command, method string) (string, string, int, error) {
+	timeout := r.policies.For(method).TimeoutOrZero()
+	if timeout <= 0 {
+		return r.cmdRunner.RunComplexCommand(cmd)
+	}
+
+	process, err := r.cmdRunner.RunComplexCommandAsync(cmd)
+	if err != nil {
+		return "", "", -1, err
+	}
+
+	resultCh := process.Wait()
+
+	select {
+	case result := <-resultCh:
+		return result.Stdout, result.Stderr, result.ExitStatus, result.Error
+	case <-r.timeService.After(timeout):
+		err = process.TerminateNicely(cpiKillGracePeriod)
+		if err != nil {
+			r.logger.Warn(r.logTag, "Terminating timed out CPI method '%s': %s", method, err.Error())
+		}
+
+		return "", "", -1, bosherr.Errorf("CPI method '%s' timed out after %s", method, timeout)
+	}
+}
@@ -5,8 +5,11 @@ import (
 	"errors"
 	"io"
 	"os"
+	"time"
 
+	"code.cloudfoundry.org/clock"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -262,4 +265,48 @@ var _ = Describe("CpiCmdRunner", func() {
 			})
 		})
 	})
+
+	Describe("Run with timeouts", func() {
+		var (
+			process *fakesys.FakeProcess
+		)
+
+		BeforeEach(func() {
+			policies := CallPolicies{"create_vm": {Timeout: duration(10 * time.Millisecond)}}
+			cpiCmdRunner = NewCPICmdRunnerWithTimeouts(cmdRunner, cpi, policies, clock.NewClock(), boshlog.NewLogger(boshlog.LevelNone))
+		})
+
+		It("terminates the CPI and returns an error when the method takes longer than its timeout", func() {
+			process = &fakesys.FakeProcess{TerminatedNicelyCallBack: func(*fakesys.FakeProcess) {}}
+			cmdRunner.AddProcess("/jobs/cpi/bin/cpi", process)
+
+			_, err := cpiCmdRunner.Run(context, "create_vm", apiVersion)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("CPI method 'create_vm' timed out after 10ms"))
+			Expect(process.TerminatedNicely).To(BeTrue())
+		})
+
+		It("returns the result when the method finishes in time", func() {
+			outputBytes, err := json.Marshal(CmdOutput{Result: "fake-vm-cid"})
+			Expect(err).NotTo(HaveOccurred())
+
+			process = &fakesys.FakeProcess{WaitResult: boshsys.Result{Stdout: string(outputBytes)}}
+			cmdRunner.AddProcess("/jobs/cpi/bin/cpi", process)
+
+			cmdOutput, err := cpiCmdRunner.Run(context, "create_vm", apiVersion)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmdOutput.Result).To(Equal("fake-vm-cid"))
+			Expect(process.TerminatedNicely).To(BeFalse())
+		})
+
+		It("runs methods without a timeout to completion", func() {
+			outputBytes, err := json.Marshal(CmdOutput{Result: "fake-disk-cid"})
+			Expect(err).NotTo(HaveOccurred())
+			cmdRunner.AddCmdResult("/jobs/cpi/bin/cpi", fakesys.FakeCmdResult{Stdout: string(outputBytes)})
+
+			cmdOutput, err := cpiCmdRunner.Run(context, "create_disk", apiVersion)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(cmdOutput.Result).To(Equal("fake-disk-cid"))
+		})
+	})
 })
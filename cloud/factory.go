@@ -1,6 +1,9 @@
 package cloud
 
 import (
+	"os"
+
+	"code.cloudfoundry.org/clock"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
@@ -10,27 +13,76 @@ import (
 
 type Factory interface {
 	NewCloud(installation biinstall.Installation, directorID string, stemcellApiVersion int) (Cloud, error)
+	NewCPICmdRunner(installation biinstall.Installation) (CPICmdRunner, error)
+}
+
+// CPI calls are recorded to the JSON Lines file named by RecordCPICallsEnv
+// and answered from the recording named by ReplayCPICallsEnv instead of running the CPI
+// unless the factory is given paths of its own
+const (
+	RecordCPICallsEnv = "BOSH_CPI_RECORD_PATH"
+	ReplayCPICallsEnv = "BOSH_CPI_REPLAY_PATH"
+)
+
+// CPICallRecording names the files CPI calls are recorded to and replayed from
+type CPICallRecording struct {
+	RecordPath string
+	ReplayPath string
 }
 
 type factory struct {
-	fs        boshsys.FileSystem
-	cmdRunner boshsys.CmdRunner
-	logger    boshlog.Logger
+	fs          boshsys.FileSystem
+	cmdRunner   boshsys.CmdRunner
+	timeService clock.Clock
+	policies    CallPolicies
+	recording   CPICallRecording
+	reporter    RetryReporter
+	logger      boshlog.Logger
+
+	// all clouds replay from the same position in the recording
+	replayCmdRunner CPICmdRunner
 }
 
 func NewFactory(
 	fs boshsys.FileSystem,
 	cmdRunner boshsys.CmdRunner,
+	timeService clock.Clock,
+	policies CallPolicies,
+	recording CPICallRecording,
+	reporter RetryReporter,
 	logger boshlog.Logger,
 ) Factory {
+	if recording.RecordPath == "" {
+		recording.RecordPath = os.Getenv(RecordCPICallsEnv)
+	}
+
+	if recording.ReplayPath == "" {
+		recording.ReplayPath = os.Getenv(ReplayCPICallsEnv)
+	}
+
 	return &factory{
-		fs:        fs,
-		cmdRunner: cmdRunner,
-		logger:    logger,
+		fs:          fs,
+		cmdRunner:   cmdRunner,
+		timeService: timeService,
+		policies:    policies,
+		recording:   recording,
+		reporter:    reporter,
+		logger:      logger,
 	}
 }
 
 func (f *factory) NewCloud(installation biinstall.Installation, directorID string, stemcellApiVersion int) (Cloud, error) {
+	cpiCmdRunner, err := f.NewCPICmdRunner(installation)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCloud(cpiCmdRunner, directorID, stemcellApiVersion, f.logger), nil
+}
+
+// NewCPICmdRunner returns a runner for the CPI of the installation
+// that applies the call policies, recording and replay configured for the factory
+func (f *factory) NewCPICmdRunner(installation biinstall.Installation) (CPICmdRunner, error) {
 	cpiJob := installation.Job()
 	target := installation.Target()
 	cpi := CPI{
@@ -39,11 +91,40 @@ func (f *factory) NewCloud(installation biinstall.Installation, directorID strin
 		PackagesDir: target.PackagesPath(),
 	}
 
-	cmdPath := cpi.ExecutablePath()
-	if !f.fs.FileExists(cmdPath) {
-		return nil, bosherr.Errorf("Installed CPI job '%s' does not contain the required executable '%s'", cpiJob.Name, cmdPath)
+	// policies given to the factory override those of the installation manifest
+	policies := CallPolicies{}
+	for method, policy := range installation.Manifest().CPICalls {
+		policies[method] = CallPolicy{Timeout: policy.Timeout, Retries: policy.Retries, RetryDelay: policy.RetryDelay}
 	}
+	policies = policies.Merge(f.policies)
 
-	cpiCmdRunner := NewCPICmdRunner(f.cmdRunner, cpi, f.logger)
-	return NewCloud(cpiCmdRunner, directorID, stemcellApiVersion, f.logger), nil
+	var cpiCmdRunner CPICmdRunner
+
+	if replayPath := f.recording.ReplayPath; replayPath != "" {
+		if f.replayCmdRunner == nil {
+			f.logger.Info("cloudFactory", "Replaying CPI calls from '%s'", replayPath)
+
+			replayCmdRunner, err := NewReplayCPICmdRunner(f.fs, replayPath)
+			if err != nil {
+				return nil, err
+			}
+
+			f.replayCmdRunner = replayCmdRunner
+		}
+
+		cpiCmdRunner = f.replayCmdRunner
+	} else {
+		cmdPath := cpi.ExecutablePath()
+		if !f.fs.FileExists(cmdPath) {
+			return nil, bosherr.Errorf("Installed CPI job '%s' does not contain the required executable '%s'", cpiJob.Name, cmdPath)
+		}
+
+		cpiCmdRunner = NewCPICmdRunnerWithTimeouts(f.cmdRunner, cpi, policies, f.timeService, f.logger)
+	}
+
+	if recordPath := f.recording.RecordPath; recordPath != "" {
+		cpiCmdRunner = NewRecordingCPICmdRunner(cpiCmdRunner, f.fs, f.timeService, recordPath, f.logger)
+	}
+
+	return NewRetryingCPICmdRunner(cpiCmdRunner, policies, f.timeService, f.reporter, f.logger), nil
 }
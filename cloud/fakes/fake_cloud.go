@@ -15,6 +15,10 @@ type FakeCloud struct {
 	HasVMFound bool
 	HasVMErr   error
 
+	HasDiskInput HasDiskInput
+	HasDiskFound bool
+	HasDiskErr   error
+
 	CreateVMInput CreateVMInput
 	CreateVMCID   string
 	CreateVMErr   error
@@ -60,6 +64,10 @@ type HasVMInput struct {
 	VMCID string
 }
 
+type HasDiskInput struct {
+	DiskCID string
+}
+
 type CreateVMInput struct {
 	AgentID            string
 	StemcellCID        string
@@ -126,6 +134,13 @@ func (c *FakeCloud) HasVM(vmCID string) (bool, error) {
 	return c.HasVMFound, c.HasVMErr
 }
 
+func (c *FakeCloud) HasDisk(diskCID string) (bool, error) {
+	c.HasDiskInput = HasDiskInput{
+		DiskCID: diskCID,
+	}
+	return c.HasDiskFound, c.HasDiskErr
+}
+
 func (c *FakeCloud) CreateVM(
 	agentID string,
 	stemcellCID string,
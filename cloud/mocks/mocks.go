@@ -152,6 +152,21 @@ func (mr *MockCloudMockRecorder) DetachDisk(arg0, arg1 interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DetachDisk", reflect.TypeOf((*MockCloud)(nil).DetachDisk), arg0, arg1)
 }
 
+// HasDisk mocks base method.
+func (m *MockCloud) HasDisk(arg0 string) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HasDisk", arg0)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HasDisk indicates an expected call of HasDisk.
+func (mr *MockCloudMockRecorder) HasDisk(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HasDisk", reflect.TypeOf((*MockCloud)(nil).HasDisk), arg0)
+}
+
 // HasVM mocks base method.
 func (m *MockCloud) HasVM(arg0 string) (bool, error) {
 	m.ctrl.T.Helper()
@@ -261,3 +276,18 @@ func (mr *MockFactoryMockRecorder) NewCloud(arg0, arg1, arg2 interface{}) *gomoc
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewCloud", reflect.TypeOf((*MockFactory)(nil).NewCloud), arg0, arg1, arg2)
 }
+
+// NewCPICmdRunner mocks base method.
+func (m *MockFactory) NewCPICmdRunner(arg0 installation.Installation) (cloud.CPICmdRunner, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "NewCPICmdRunner", arg0)
+	ret0, _ := ret[0].(cloud.CPICmdRunner)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// NewCPICmdRunner indicates an expected call of NewCPICmdRunner.
+func (mr *MockFactoryMockRecorder) NewCPICmdRunner(arg0 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "NewCPICmdRunner", reflect.TypeOf((*MockFactory)(nil).NewCPICmdRunner), arg0)
+}
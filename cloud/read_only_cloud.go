@@ -0,0 +1,82 @@
+package cloud
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+)
+
+type readOnlyCloud struct {
+	cloud Cloud
+}
+
+// NewReadOnlyCloud wraps a cloud so that only CPI methods which inspect the
+// IaaS are delegated. Every method that would change the IaaS returns an error.
+func NewReadOnlyCloud(cloud Cloud) Cloud {
+	return readOnlyCloud{cloud: cloud}
+}
+
+func (c readOnlyCloud) CreateStemcell(imagePath string, cloudProperties biproperty.Map) (string, error) {
+	return "", c.refuse("create_stemcell")
+}
+
+func (c readOnlyCloud) DeleteStemcell(stemcellCID string) error {
+	return c.refuse("delete_stemcell")
+}
+
+func (c readOnlyCloud) HasVM(vmCID string) (bool, error) {
+	return c.cloud.HasVM(vmCID)
+}
+
+func (c readOnlyCloud) HasDisk(diskCID string) (bool, error) {
+	return c.cloud.HasDisk(diskCID)
+}
+
+func (c readOnlyCloud) CreateVM(
+	agentID string,
+	stemcellCID string,
+	cloudProperties biproperty.Map,
+	networksInterfaces map[string]biproperty.Map,
+	env biproperty.Map,
+) (string, error) {
+	return "", c.refuse("create_vm")
+}
+
+func (c readOnlyCloud) SetVMMetadata(vmCID string, metadata VMMetadata) error {
+	return c.refuse("set_vm_metadata")
+}
+
+func (c readOnlyCloud) SetDiskMetadata(diskCID string, metadata DiskMetadata) error {
+	return c.refuse("set_disk_metadata")
+}
+
+func (c readOnlyCloud) DeleteVM(vmCID string) error {
+	return c.refuse("delete_vm")
+}
+
+func (c readOnlyCloud) CreateDisk(size int, cloudProperties biproperty.Map, vmCID string) (string, error) {
+	return "", c.refuse("create_disk")
+}
+
+func (c readOnlyCloud) AttachDisk(vmCID, diskCID string) (interface{}, error) {
+	return nil, c.refuse("attach_disk")
+}
+
+func (c readOnlyCloud) DetachDisk(vmCID, diskCID string) error {
+	return c.refuse("detach_disk")
+}
+
+func (c readOnlyCloud) DeleteDisk(diskCID string) error {
+	return c.refuse("delete_disk")
+}
+
+func (c readOnlyCloud) Info() (CpiInfo, error) {
+	return c.cloud.Info()
+}
+
+func (c readOnlyCloud) String() string {
+	return c.cloud.String()
+}
+
+func (c readOnlyCloud) refuse(method string) error {
+	return bosherr.Errorf("Refusing to call CPI '%s' method on a read-only cloud", method)
+}
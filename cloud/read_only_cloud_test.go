@@ -0,0 +1,99 @@
+package cloud_test
+
+import (
+	"errors"
+
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	fakebicloud "github.com/cloudfoundry/bosh-cli/v7/cloud/fakes"
+)
+
+var _ = Describe("ReadOnlyCloud", func() {
+	var (
+		fakeCloud *fakebicloud.FakeCloud
+		cloud     Cloud
+	)
+
+	BeforeEach(func() {
+		fakeCloud = fakebicloud.NewFakeCloud()
+		cloud = NewReadOnlyCloud(fakeCloud)
+	})
+
+	Describe("HasVM", func() {
+		It("delegates to the wrapped cloud", func() {
+			fakeCloud.HasVMFound = true
+
+			found, err := cloud.HasVM("fake-vm-cid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(fakeCloud.HasVMInput).To(Equal(fakebicloud.HasVMInput{VMCID: "fake-vm-cid"}))
+		})
+
+		It("returns errors from the wrapped cloud", func() {
+			fakeCloud.HasVMErr = errors.New("fake-has-vm-error")
+
+			_, err := cloud.HasVM("fake-vm-cid")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("fake-has-vm-error"))
+		})
+	})
+
+	Describe("HasDisk", func() {
+		It("delegates to the wrapped cloud", func() {
+			fakeCloud.HasDiskFound = true
+
+			found, err := cloud.HasDisk("fake-disk-cid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(fakeCloud.HasDiskInput).To(Equal(fakebicloud.HasDiskInput{DiskCID: "fake-disk-cid"}))
+		})
+	})
+
+	Describe("Info", func() {
+		It("delegates to the wrapped cloud", func() {
+			fakeCloud.InfoResult = CpiInfo{ApiVersion: 2}
+
+			info, err := cloud.Info()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(info.ApiVersion).To(Equal(2))
+		})
+	})
+
+	Describe("mutating methods", func() {
+		It("refuses to create a stemcell", func() {
+			_, err := cloud.CreateStemcell("fake-image-path", biproperty.Map{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Refusing to call CPI 'create_stemcell' method"))
+			Expect(fakeCloud.CreateStemcellInputs).To(BeEmpty())
+		})
+
+		It("refuses to create a VM", func() {
+			_, err := cloud.CreateVM("fake-agent-id", "fake-stemcell-cid", biproperty.Map{}, nil, biproperty.Map{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Refusing to call CPI 'create_vm' method"))
+			Expect(fakeCloud.CreateVMInput).To(Equal(fakebicloud.CreateVMInput{}))
+		})
+
+		It("refuses to create a disk", func() {
+			_, err := cloud.CreateDisk(1024, biproperty.Map{}, "fake-vm-cid")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Refusing to call CPI 'create_disk' method"))
+		})
+
+		It("refuses to delete a disk", func() {
+			err := cloud.DeleteDisk("fake-disk-cid")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Refusing to call CPI 'delete_disk' method"))
+			Expect(fakeCloud.DeleteDiskInputs).To(BeEmpty())
+		})
+
+		It("refuses to delete a VM", func() {
+			err := cloud.DeleteVM("fake-vm-cid")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Refusing to call CPI 'delete_vm' method"))
+		})
+	})
+})
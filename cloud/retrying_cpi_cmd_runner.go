@@ -0,0 +1,66 @@
+package cloud
+
+import (
+	"code.cloudfoundry.org/clock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+)
+
+// RetryReporter is told about every CPI call that is about to be retried
+type RetryReporter interface {
+	CPICallRetrying(method string, attempt int, attempts int, err Error)
+}
+
+type retryingCPICmdRunner struct {
+	cmdRunner   CPICmdRunner
+	policies    CallPolicies
+	timeService clock.Clock
+	reporter    RetryReporter
+	logger      boshlog.Logger
+	logTag      string
+}
+
+// NewRetryingCPICmdRunner retries CPI calls that fail with errors the CPI reports as ok to retry,
+// as often as the policy for their method allows
+func NewRetryingCPICmdRunner(
+	cmdRunner CPICmdRunner,
+	policies CallPolicies,
+	timeService clock.Clock,
+	reporter RetryReporter,
+	logger boshlog.Logger,
+) CPICmdRunner {
+	return &retryingCPICmdRunner{
+		cmdRunner:   cmdRunner,
+		policies:    policies,
+		timeService: timeService,
+		reporter:    reporter,
+		logger:      logger,
+		logTag:      "retryingCPICmdRunner",
+	}
+}
+
+func (r *retryingCPICmdRunner) Run(context CmdContext, method string, apiVersion int, args ...interface{}) (CmdOutput, error) {
+	policy := r.policies.For(method)
+	attempts := policy.RetriesOrZero() + 1
+	delay := policy.RetryDelayOrDefault()
+
+	for attempt := 1; ; attempt++ {
+		cmdOutput, err := r.cmdRunner.Run(context, method, apiVersion, args...)
+		if err != nil || cmdOutput.Error == nil || attempt >= attempts {
+			return cmdOutput, err
+		}
+
+		cpiErr := NewCPIError(method, *cmdOutput.Error)
+		if !cpiErr.OkToRetry() {
+			return cmdOutput, err
+		}
+
+		r.logger.Info(r.logTag, "Retrying CPI method '%s' in %s (attempt %d of %d): %s", method, delay, attempt+1, attempts, cpiErr.Error())
+
+		if r.reporter != nil {
+			r.reporter.CPICallRetrying(method, attempt+1, attempts, cpiErr)
+		}
+
+		r.timeService.Sleep(delay)
+		delay *= 2
+	}
+}
@@ -0,0 +1,97 @@
+package cloud_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	fakebicloud "github.com/cloudfoundry/bosh-cli/v7/cloud/fakes"
+)
+
+type retryReport struct {
+	Method   string
+	Attempt  int
+	Attempts int
+	Type     string
+}
+
+type fakeRetryReporter struct {
+	Reports []retryReport
+}
+
+func (r *fakeRetryReporter) CPICallRetrying(method string, attempt int, attempts int, err Error) {
+	r.Reports = append(r.Reports, retryReport{method, attempt, attempts, err.Type()})
+}
+
+var _ = Describe("RetryingCPICmdRunner", func() {
+	var (
+		fakeCPICmdRunner *fakebicloud.FakeCPICmdRunner
+		reporter         *fakeRetryReporter
+		runner           CPICmdRunner
+		context          CmdContext
+		retryableOutput  CmdOutput
+	)
+
+	BeforeEach(func() {
+		fakeCPICmdRunner = fakebicloud.NewFakeCPICmdRunner()
+		reporter = &fakeRetryReporter{}
+		context = CmdContext{DirectorID: "fake-director-id"}
+		retryableOutput = CmdOutput{Error: &CmdError{Type: "Bosh::Clouds::VMCreationFailed", Message: "try again", OkToRetry: true}}
+
+		policies := CallPolicies{"create_vm": {Retries: count(2), RetryDelay: duration(time.Millisecond)}}
+		runner = NewRetryingCPICmdRunner(fakeCPICmdRunner, policies, clock.NewClock(), reporter, boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	It("retries errors that the CPI reports as ok to retry and reports every retry", func() {
+		fakeCPICmdRunner.RunCmdOutputs = []CmdOutput{retryableOutput, retryableOutput, {Result: "fake-vm-cid"}}
+
+		output, err := runner.Run(context, "create_vm", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output.Result).To(Equal("fake-vm-cid"))
+
+		Expect(fakeCPICmdRunner.CurrentRunInput).To(HaveLen(3))
+		Expect(reporter.Reports).To(Equal([]retryReport{
+			{"create_vm", 2, 3, "Bosh::Clouds::VMCreationFailed"},
+			{"create_vm", 3, 3, "Bosh::Clouds::VMCreationFailed"},
+		}))
+	})
+
+	It("returns the last error when no retries are left", func() {
+		fakeCPICmdRunner.RunCmdOutputs = []CmdOutput{retryableOutput}
+
+		output, err := runner.Run(context, "create_vm", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(output).To(Equal(retryableOutput))
+		Expect(fakeCPICmdRunner.CurrentRunInput).To(HaveLen(3))
+	})
+
+	It("does not retry errors that are not ok to retry", func() {
+		fakeCPICmdRunner.RunCmdOutputs = []CmdOutput{{Error: &CmdError{Type: "Bosh::Clouds::CloudError", Message: "quota"}}}
+
+		_, err := runner.Run(context, "create_vm", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fakeCPICmdRunner.CurrentRunInput).To(HaveLen(1))
+		Expect(reporter.Reports).To(BeEmpty())
+	})
+
+	It("does not retry when the CPI could not be run", func() {
+		fakeCPICmdRunner.RunErrs = []error{errors.New("fake-run-error")}
+
+		_, err := runner.Run(context, "create_vm", 1)
+		Expect(err).To(MatchError("fake-run-error"))
+		Expect(fakeCPICmdRunner.CurrentRunInput).To(HaveLen(1))
+	})
+
+	It("does not retry methods without retries", func() {
+		fakeCPICmdRunner.RunCmdOutputs = []CmdOutput{retryableOutput}
+
+		_, err := runner.Run(context, "attach_disk", 1)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fakeCPICmdRunner.CurrentRunInput).To(HaveLen(1))
+	})
+})
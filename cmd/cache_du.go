@@ -0,0 +1,62 @@
+package cmd
+
+import (
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+type CacheDuCmd struct {
+	ui        boshui.UI
+	inspector CacheInspector
+}
+
+func NewCacheDuCmd(ui boshui.UI, inspector CacheInspector) CacheDuCmd {
+	return CacheDuCmd{ui: ui, inspector: inspector}
+}
+
+func (c CacheDuCmd) Run() error {
+	entries, err := c.inspector.Entries()
+	if err != nil {
+		return err
+	}
+
+	var kinds []string
+
+	counts := map[string]int{}
+	sizes := map[string]int64{}
+
+	var total int64
+
+	for _, entry := range entries {
+		if _, found := counts[entry.Kind]; !found {
+			kinds = append(kinds, entry.Kind)
+		}
+
+		counts[entry.Kind]++
+		sizes[entry.Kind] += entry.Size
+		total += entry.Size
+	}
+
+	table := boshtbl.Table{
+		Content: "kinds",
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Kind"),
+			boshtbl.NewHeader("Entries"),
+			boshtbl.NewHeader("Size"),
+		},
+		SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+		Notes:  []string{"Total: " + boshtbl.NewValueBytes(uint64(total)).String()},
+	}
+
+	for _, kind := range kinds {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(kind),
+			boshtbl.NewValueInt(counts[kind]),
+			boshtbl.NewValueBytes(uint64(sizes[kind])),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	return nil
+}
@@ -0,0 +1,53 @@
+package cmd_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/cmdfakes"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+var _ = Describe("CacheDuCmd", func() {
+	var (
+		ui        *fakeui.FakeUI
+		inspector *cmdfakes.FakeCacheInspector
+		command   cmd.CacheDuCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		inspector = &cmdfakes.FakeCacheInspector{}
+		command = cmd.NewCacheDuCmd(ui, inspector)
+	})
+
+	Describe("Run", func() {
+		It("sums the size of entries by kind", func() {
+			inspector.EntriesReturns([]cmd.CacheEntry{
+				{Kind: cmd.CacheEntryTarball, ID: "release", Size: 1024},
+				{Kind: cmd.CacheEntryInstallation, ID: "fake-installation-id", Size: 3 * 1024},
+				{Kind: cmd.CacheEntryTarball, ID: "stemcell", Size: 2 * 1024},
+			}, nil)
+
+			err := command.Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Table).To(Equal(boshtbl.Table{
+				Content: "kinds",
+				Header: []boshtbl.Header{
+					boshtbl.NewHeader("Kind"),
+					boshtbl.NewHeader("Entries"),
+					boshtbl.NewHeader("Size"),
+				},
+				SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+				Notes:  []string{"Total: 6.0 KiB"},
+				Rows: [][]boshtbl.Value{
+					{boshtbl.NewValueString("tarball"), boshtbl.NewValueInt(2), boshtbl.NewValueBytes(3 * 1024)},
+					{boshtbl.NewValueString("installation"), boshtbl.NewValueInt(1), boshtbl.NewValueBytes(3 * 1024)},
+				},
+			}))
+		})
+	})
+})
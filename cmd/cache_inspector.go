@@ -0,0 +1,148 @@
+package cmd
+
+import (
+	"fmt"
+	"path/filepath"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	boshinst "github.com/cloudfoundry/bosh-cli/v7/installation"
+	bitarball "github.com/cloudfoundry/bosh-cli/v7/installation/tarball"
+	bistatepkg "github.com/cloudfoundry/bosh-cli/v7/state/pkg"
+)
+
+const (
+	CacheEntryTarball         = "tarball"
+	CacheEntryPartialTarball  = "partial tarball"
+	CacheEntryInstallation    = "installation"
+	CacheEntryCompiledPackage = "compiled package"
+)
+
+type CacheEntry struct {
+	Kind string
+
+	// ID is the description of a tarball, the ID of an installation
+	// or the name and fingerprint of a compiled package
+	ID string
+
+	// Source is the URL of a tarball or the state file that last used an installation
+	Source string
+	SHA1   string
+
+	Path     string
+	Size     int64
+	LastUsed time.Time
+}
+
+//counterfeiter:generate . CacheInspector
+
+type CacheInspector interface {
+	Entries() ([]CacheEntry, error)
+	Remove(CacheEntry) error
+}
+
+type cacheInspector struct {
+	tarballCache bitarball.Cache
+	targetLister boshinst.TargetLister
+
+	// compiledPackageCache is nil unless a compiled packages cache was given
+	compiledPackageCache bistatepkg.CompiledPackageCache
+}
+
+func NewCacheInspector(
+	tarballCache bitarball.Cache,
+	targetLister boshinst.TargetLister,
+	compiledPackageCache bistatepkg.CompiledPackageCache,
+) CacheInspector {
+	return cacheInspector{
+		tarballCache:         tarballCache,
+		targetLister:         targetLister,
+		compiledPackageCache: compiledPackageCache,
+	}
+}
+
+func (i cacheInspector) Entries() ([]CacheEntry, error) {
+	var entries []CacheEntry
+
+	tarballs, err := i.tarballCache.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, tarball := range tarballs {
+		entry := CacheEntry{
+			Kind:     CacheEntryTarball,
+			ID:       tarball.Description,
+			Source:   tarball.URL,
+			SHA1:     tarball.SHA1,
+			Path:     tarball.Path,
+			Size:     tarball.Size,
+			LastUsed: tarball.LastUsed,
+		}
+
+		if entry.ID == "" {
+			entry.ID = filepath.Base(tarball.Path)
+		}
+
+		if tarball.Partial {
+			entry.Kind = CacheEntryPartialTarball
+		}
+
+		entries = append(entries, entry)
+	}
+
+	targets, err := i.targetLister.List()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, target := range targets {
+		entries = append(entries, CacheEntry{
+			Kind:     CacheEntryInstallation,
+			ID:       target.ID,
+			Source:   target.StatePath,
+			Path:     target.Path(),
+			Size:     target.Size,
+			LastUsed: target.LastUsed,
+		})
+	}
+
+	if i.compiledPackageCache == nil {
+		return entries, nil
+	}
+
+	packages, err := i.compiledPackageCache.Entries()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pkg := range packages {
+		entries = append(entries, CacheEntry{
+			Kind:     CacheEntryCompiledPackage,
+			ID:       fmt.Sprintf("%s/%s", pkg.Name, pkg.Fingerprint),
+			Path:     pkg.Path,
+			Size:     pkg.Size,
+			LastUsed: pkg.CachedAt,
+		})
+	}
+
+	return entries, nil
+}
+
+func (i cacheInspector) Remove(entry CacheEntry) error {
+	switch entry.Kind {
+	case CacheEntryTarball, CacheEntryPartialTarball:
+		return i.tarballCache.Remove(bitarball.CacheEntry{Path: entry.Path})
+
+	case CacheEntryInstallation:
+		return i.targetLister.Remove(boshinst.InstalledTarget{Target: boshinst.NewTarget(entry.Path), ID: entry.ID})
+
+	case CacheEntryCompiledPackage:
+		if i.compiledPackageCache != nil {
+			return i.compiledPackageCache.Remove(bistatepkg.CachedCompiledPackage{Path: entry.Path})
+		}
+	}
+
+	return bosherr.Errorf("Unknown cache entry '%s' of kind '%s'", entry.ID, entry.Kind)
+}
@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+type CacheListCmd struct {
+	ui        boshui.UI
+	inspector CacheInspector
+}
+
+func NewCacheListCmd(ui boshui.UI, inspector CacheInspector) CacheListCmd {
+	return CacheListCmd{ui: ui, inspector: inspector}
+}
+
+func (c CacheListCmd) Run() error {
+	entries, err := c.inspector.Entries()
+	if err != nil {
+		return err
+	}
+
+	c.ui.PrintTable(cacheEntriesTable("cache entries", entries))
+
+	return nil
+}
+
+func cacheEntriesTable(content string, entries []CacheEntry) boshtbl.Table {
+	table := boshtbl.Table{
+		Content: content,
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Kind"),
+			boshtbl.NewHeader("ID"),
+			boshtbl.NewHeader("Source"),
+			boshtbl.NewHeader("SHA1"),
+			boshtbl.NewHeader("Size"),
+			boshtbl.NewHeader("Last used"),
+		},
+		SortBy: []boshtbl.ColumnSort{
+			{Column: 0, Asc: true},
+			{Column: 5, Asc: false},
+		},
+	}
+
+	for _, entry := range entries {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(entry.Kind),
+			boshtbl.NewValueString(entry.ID),
+			boshtbl.NewValueString(entry.Source),
+			boshtbl.NewValueString(entry.SHA1),
+			boshtbl.NewValueBytes(uint64(entry.Size)),
+			boshtbl.NewValueTime(entry.LastUsed),
+		})
+	}
+
+	return table
+}
@@ -0,0 +1,83 @@
+package cmd_test
+
+import (
+	"errors"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/cmdfakes"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+var _ = Describe("CacheListCmd", func() {
+	var (
+		ui        *fakeui.FakeUI
+		inspector *cmdfakes.FakeCacheInspector
+		command   cmd.CacheListCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		inspector = &cmdfakes.FakeCacheInspector{}
+		command = cmd.NewCacheListCmd(ui, inspector)
+	})
+
+	Describe("Run", func() {
+		It("lists cache entries", func() {
+			lastUsed := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+			inspector.EntriesReturns([]cmd.CacheEntry{
+				{Kind: cmd.CacheEntryTarball, ID: "release 'fake-release'", Source: "https://example.com/release.tgz", SHA1: "fake-sha1", Size: 1024, LastUsed: lastUsed},
+				{Kind: cmd.CacheEntryInstallation, ID: "fake-installation-id", Source: "/state.json", Size: 2048, LastUsed: lastUsed},
+			}, nil)
+
+			err := command.Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Table).To(Equal(boshtbl.Table{
+				Content: "cache entries",
+				Header: []boshtbl.Header{
+					boshtbl.NewHeader("Kind"),
+					boshtbl.NewHeader("ID"),
+					boshtbl.NewHeader("Source"),
+					boshtbl.NewHeader("SHA1"),
+					boshtbl.NewHeader("Size"),
+					boshtbl.NewHeader("Last used"),
+				},
+				SortBy: []boshtbl.ColumnSort{
+					{Column: 0, Asc: true},
+					{Column: 5, Asc: false},
+				},
+				Rows: [][]boshtbl.Value{
+					{
+						boshtbl.NewValueString("tarball"),
+						boshtbl.NewValueString("release 'fake-release'"),
+						boshtbl.NewValueString("https://example.com/release.tgz"),
+						boshtbl.NewValueString("fake-sha1"),
+						boshtbl.NewValueBytes(1024),
+						boshtbl.NewValueTime(lastUsed),
+					},
+					{
+						boshtbl.NewValueString("installation"),
+						boshtbl.NewValueString("fake-installation-id"),
+						boshtbl.NewValueString("/state.json"),
+						boshtbl.NewValueString(""),
+						boshtbl.NewValueBytes(2048),
+						boshtbl.NewValueTime(lastUsed),
+					},
+				},
+			}))
+		})
+
+		It("returns an error if listing entries fails", func() {
+			inspector.EntriesReturns(nil, errors.New("fake-err"))
+
+			err := command.Run()
+			Expect(err).To(MatchError("fake-err"))
+		})
+	})
+})
@@ -0,0 +1,154 @@
+package cmd
+
+import (
+	"sort"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/dustin/go-humanize"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+type CachePruneCmd struct {
+	ui                             boshui.UI
+	inspector                      CacheInspector
+	deploymentStateServiceProvider func(statePath string) biconfig.DeploymentStateService
+	timeService                    clock.Clock
+}
+
+func NewCachePruneCmd(
+	ui boshui.UI,
+	inspector CacheInspector,
+	deploymentStateServiceProvider func(statePath string) biconfig.DeploymentStateService,
+	timeService clock.Clock,
+) CachePruneCmd {
+	return CachePruneCmd{
+		ui:                             ui,
+		inspector:                      inspector,
+		deploymentStateServiceProvider: deploymentStateServiceProvider,
+		timeService:                    timeService,
+	}
+}
+
+func (c CachePruneCmd) Run(opts CachePruneOpts) error {
+	if opts.OlderThan <= 0 && opts.MaxSize == "" {
+		return bosherr.Error("Expected --older-than or --max-size to choose entries to remove")
+	}
+
+	var maxSize uint64
+
+	if opts.MaxSize != "" {
+		var err error
+
+		maxSize, err = humanize.ParseBytes(opts.MaxSize)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Parsing --max-size '%s'", opts.MaxSize)
+		}
+	}
+
+	usedInstallationIDs := map[string]bool{}
+
+	for _, statePath := range opts.StatePaths {
+		// pruning the cache must never write the state, e.g. a director ID
+		deploymentStateService := biconfig.NewReadOnlyDeploymentStateService(c.deploymentStateServiceProvider(statePath))
+
+		// a mistyped path must not leave the installation of the environment unprotected
+		if !deploymentStateService.Exists() {
+			return bosherr.Errorf("Expected deployment state '%s' to exist", statePath)
+		}
+
+		deploymentState, err := deploymentStateService.Load()
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Loading deployment state '%s'", statePath)
+		}
+
+		usedInstallationIDs[deploymentState.InstallationID] = true
+	}
+
+	entries, err := c.inspector.Entries()
+	if err != nil {
+		return err
+	}
+
+	pruned := c.entriesToPrune(entries, usedInstallationIDs, opts.OlderThan, opts.MaxSize != "", maxSize)
+
+	if len(pruned) == 0 {
+		c.ui.PrintLinef("No cache entries to remove")
+		return nil
+	}
+
+	c.ui.PrintTable(cacheEntriesTable("cache entries to remove", pruned))
+
+	if opts.DryRun {
+		return nil
+	}
+
+	err = c.ui.AskForConfirmation()
+	if err != nil {
+		return err
+	}
+
+	var freed int64
+
+	for _, entry := range pruned {
+		err := c.inspector.Remove(entry)
+		if err != nil {
+			return err
+		}
+
+		freed += entry.Size
+	}
+
+	c.ui.PrintLinef("Removed %d cache entries and freed %s", len(pruned), humanize.IBytes(uint64(freed)))
+
+	return nil
+}
+
+// entriesToPrune removes the least recently used entries first;
+// installations of the given state files are never removed and do not
+// count towards the max size since removing other entries cannot make them fit
+func (c CachePruneCmd) entriesToPrune(
+	entries []CacheEntry,
+	usedInstallationIDs map[string]bool,
+	olderThan time.Duration,
+	limitSize bool,
+	maxSize uint64,
+) []CacheEntry {
+	var candidates []CacheEntry
+	var total uint64
+
+	for _, entry := range entries {
+		if entry.Kind == CacheEntryInstallation && usedInstallationIDs[entry.ID] {
+			continue
+		}
+
+		candidates = append(candidates, entry)
+		total += uint64(entry.Size)
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].LastUsed.Before(candidates[j].LastUsed)
+	})
+
+	now := c.timeService.Now()
+
+	var pruned []CacheEntry
+
+	for _, entry := range candidates {
+		tooOld := olderThan > 0 && now.Sub(entry.LastUsed) > olderThan
+		tooLarge := limitSize && total > maxSize
+
+		if !tooOld && !tooLarge {
+			continue
+		}
+
+		pruned = append(pruned, entry)
+		total -= uint64(entry.Size)
+	}
+
+	return pruned
+}
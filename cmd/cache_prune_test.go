@@ -0,0 +1,161 @@
+package cmd_test
+
+import (
+	"errors"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/cmdfakes"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("CachePruneCmd", func() {
+	var (
+		ui        *fakeui.FakeUI
+		fs        *fakesys.FakeFileSystem
+		inspector *cmdfakes.FakeCacheInspector
+		now       time.Time
+		command   cmd.CachePruneCmd
+
+		oldTarball, recentTarball, oldInstallation, recentInstallation cmd.CacheEntry
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		fs = fakesys.NewFakeFileSystem()
+		inspector = &cmdfakes.FakeCacheInspector{}
+		now = time.Date(2020, time.February, 1, 0, 0, 0, 0, time.UTC)
+
+		deploymentStateServiceProvider := func(statePath string) biconfig.DeploymentStateService {
+			return biconfig.NewFileSystemDeploymentStateService(fs, fakeuuid.NewFakeGenerator(), boshlog.NewLogger(boshlog.LevelNone), statePath)
+		}
+
+		command = cmd.NewCachePruneCmd(ui, inspector, deploymentStateServiceProvider, fakeclock.NewFakeClock(now))
+
+		oldTarball = cmd.CacheEntry{Kind: cmd.CacheEntryTarball, ID: "old-tarball", Size: 1000, LastUsed: now.Add(-72 * time.Hour)}
+		recentTarball = cmd.CacheEntry{Kind: cmd.CacheEntryTarball, ID: "recent-tarball", Size: 1000, LastUsed: now.Add(-1 * time.Hour)}
+		oldInstallation = cmd.CacheEntry{Kind: cmd.CacheEntryInstallation, ID: "old-installation", Size: 1000, LastUsed: now.Add(-96 * time.Hour)}
+		recentInstallation = cmd.CacheEntry{Kind: cmd.CacheEntryInstallation, ID: "recent-installation", Size: 1000, LastUsed: now.Add(-2 * time.Hour)}
+
+		inspector.EntriesReturns([]cmd.CacheEntry{recentTarball, oldTarball, recentInstallation, oldInstallation}, nil)
+	})
+
+	removed := func() []cmd.CacheEntry {
+		var entries []cmd.CacheEntry
+		for i := 0; i < inspector.RemoveCallCount(); i++ {
+			entries = append(entries, inspector.RemoveArgsForCall(i))
+		}
+		return entries
+	}
+
+	Describe("Run", func() {
+		It("removes entries not used for longer than --older-than", func() {
+			err := command.Run(opts.CachePruneOpts{OlderThan: 24 * time.Hour})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.AskedConfirmationCalled).To(BeTrue())
+			Expect(removed()).To(Equal([]cmd.CacheEntry{oldInstallation, oldTarball}))
+			Expect(ui.Said).To(ContainElement("Removed 2 cache entries and freed 2.0 KiB"))
+		})
+
+		It("removes least recently used entries until the rest fits in --max-size", func() {
+			err := command.Run(opts.CachePruneOpts{MaxSize: "2500B"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(removed()).To(Equal([]cmd.CacheEntry{oldInstallation, oldTarball}))
+		})
+
+		It("never removes installations of the given state files", func() {
+			err := fs.WriteFileString("/state.json", `{"installation_id":"old-installation"}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = command.Run(opts.CachePruneOpts{MaxSize: "2500B", StatePaths: []string{"/state.json"}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(removed()).To(Equal([]cmd.CacheEntry{oldTarball}))
+		})
+
+		It("does not count installations of the given state files towards --max-size", func() {
+			err := fs.WriteFileString("/state.json", `{"installation_id":"old-installation"}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = command.Run(opts.CachePruneOpts{MaxSize: "500B", StatePaths: []string{"/state.json"}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(removed()).To(Equal([]cmd.CacheEntry{oldTarball, recentInstallation, recentTarball}))
+		})
+
+		It("does not write the given state files", func() {
+			err := fs.WriteFileString("/state.json", `{"installation_id":"old-installation"}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = command.Run(opts.CachePruneOpts{MaxSize: "2500B", StatePaths: []string{"/state.json"}})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString("/state.json")).To(Equal(`{"installation_id":"old-installation"}`))
+		})
+
+		It("returns an error without removing entries when a state file does not exist", func() {
+			err := command.Run(opts.CachePruneOpts{MaxSize: "2500B", StatePaths: []string{"/missing-state.json"}})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected deployment state '/missing-state.json' to exist"))
+
+			Expect(inspector.RemoveCallCount()).To(Equal(0))
+			Expect(fs.FileExists("/missing-state.json")).To(BeFalse())
+		})
+
+		It("only shows the entries with --dry-run", func() {
+			err := command.Run(opts.CachePruneOpts{OlderThan: 24 * time.Hour, DryRun: true})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Table.Content).To(Equal("cache entries to remove"))
+			Expect(ui.Table.Rows).To(HaveLen(2))
+			Expect(ui.AskedConfirmationCalled).To(BeFalse())
+			Expect(inspector.RemoveCallCount()).To(Equal(0))
+		})
+
+		It("does not remove entries when not confirmed", func() {
+			ui.AskedConfirmationErr = errors.New("fake-err")
+
+			err := command.Run(opts.CachePruneOpts{OlderThan: 24 * time.Hour})
+			Expect(err).To(MatchError("fake-err"))
+			Expect(inspector.RemoveCallCount()).To(Equal(0))
+		})
+
+		It("says so when there is nothing to remove", func() {
+			err := command.Run(opts.CachePruneOpts{OlderThan: 240 * time.Hour})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Said).To(Equal([]string{"No cache entries to remove"}))
+			Expect(ui.AskedConfirmationCalled).To(BeFalse())
+		})
+
+		It("requires --older-than or --max-size", func() {
+			err := command.Run(opts.CachePruneOpts{})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected --older-than or --max-size"))
+		})
+
+		It("returns an error for an invalid --max-size", func() {
+			err := command.Run(opts.CachePruneOpts{MaxSize: "lots"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Parsing --max-size 'lots'"))
+		})
+
+		It("returns an error if removing an entry fails", func() {
+			inspector.RemoveReturns(errors.New("fake-err"))
+
+			err := command.Run(opts.CachePruneOpts{OlderThan: 24 * time.Hour})
+			Expect(err).To(MatchError("fake-err"))
+		})
+	})
+})
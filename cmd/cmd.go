@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os/signal"
 	"path/filepath"
 	"time"
 
@@ -9,18 +11,26 @@ import (
 	"github.com/cppforlife/go-patch/patch"
 
 	cmdconf "github.com/cloudfoundry/bosh-cli/v7/cmd/config"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
 	"github.com/cloudfoundry/bosh-cli/v7/crypto"
+	bidepl "github.com/cloudfoundry/bosh-cli/v7/deployment"
+	bidepltpl "github.com/cloudfoundry/bosh-cli/v7/deployment/template"
 	boshdir "github.com/cloudfoundry/bosh-cli/v7/director"
 	boshtpl "github.com/cloudfoundry/bosh-cli/v7/director/template"
+	boshinst "github.com/cloudfoundry/bosh-cli/v7/installation"
+	bitarball "github.com/cloudfoundry/bosh-cli/v7/installation/tarball"
 	boshrel "github.com/cloudfoundry/bosh-cli/v7/release"
 	boshreldir "github.com/cloudfoundry/bosh-cli/v7/releasedir"
 	boshssh "github.com/cloudfoundry/bosh-cli/v7/ssh"
+	bistatepkg "github.com/cloudfoundry/bosh-cli/v7/state/pkg"
 	bistemcell "github.com/cloudfoundry/bosh-cli/v7/stemcell"
 	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
 	boshuit "github.com/cloudfoundry/bosh-cli/v7/ui/task"
 
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshfu "github.com/cloudfoundry/bosh-utils/fileutil"
+	"github.com/cloudfoundry/bosh-utils/httpclient"
 
 	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
 	"github.com/cloudfoundry/bosh-cli/v7/pcap"
@@ -56,6 +66,8 @@ func (c Cmd) Execute() (cmdErr error) {
 				panic(r)
 			}
 		}
+
+		cmdErr = c.handleTaskNotTracked(cmdErr)
 	}()
 
 	c.configureUI()
@@ -71,16 +83,49 @@ func (c Cmd) Execute() (cmdErr error) {
 		return NewEnvironmentsCmd(c.config(), deps.UI).Run()
 
 	case *CreateEnvOpts:
+		var tarballProvider bitarball.Provider
+
+		if len(opts.Bundle) > 0 {
+			bundle, err := c.envBundle(*opts)
+			if err != nil {
+				return err
+			}
+
+			defer bundle.Cleanup() //nolint:errcheck
+
+			tarballProvider = bundle
+		}
+
 		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) DeploymentPreparer {
-			return NewEnvFactory(deps, manifestPath, statePath, vars, op, opts.RecreatePersistentDisks).Preparer()
+			return NewEnvFactory(deps, manifestPath, statePath, vars, op, EnvFactoryOpts{
+				RecreatePersistentDisks: opts.RecreatePersistentDisks,
+				CompileWorkers:          opts.CompileWorkers,
+				CompiledPackagesCache:   opts.CompiledPackagesCache,
+				CompiledPackageDigests:  opts.CompiledPackageDigests,
+				CPICallPolicies:         opts.CPICallFlags.AsCallPolicies(),
+				CPICallRecording:        opts.CPICallFlags.AsCallRecording(),
+				Hooks:                   opts.HookFlags.AsHooks(),
+				TarballProvider:         tarballProvider,
+				DryRun:                  opts.DryRun,
+			}).Preparer()
 		}
 
 		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
 		return NewCreateEnvCmd(deps.UI, envProvider).Run(stage, *opts)
 
+	case *CreateEnvBundleOpts:
+		bundler := NewEnvFactory(deps, opts.Args.Manifest.Path, "", opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp(), EnvFactoryOpts{}).Bundler()
+
+		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
+		return NewCreateEnvBundleCmd(deps.UI, bundler).Run(stage, *opts)
+
 	case *DeleteEnvOpts:
 		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) DeploymentDeleter {
-			return NewEnvFactory(deps, manifestPath, statePath, vars, op, false).Deleter()
+			return NewEnvFactory(deps, manifestPath, statePath, vars, op, EnvFactoryOpts{
+				CPICallPolicies:  opts.CPICallFlags.AsCallPolicies(),
+				CPICallRecording: opts.CPICallFlags.AsCallRecording(),
+				Hooks:            opts.HookFlags.AsHooks(),
+			}).Deleter()
 		}
 
 		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
@@ -88,7 +133,7 @@ func (c Cmd) Execute() (cmdErr error) {
 
 	case *StopEnvOpts:
 		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) DeploymentStateManager {
-			return NewEnvFactory(deps, manifestPath, statePath, vars, op, false).StateManager()
+			return NewEnvFactory(deps, manifestPath, statePath, vars, op, EnvFactoryOpts{}).StateManager()
 		}
 
 		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
@@ -96,12 +141,110 @@ func (c Cmd) Execute() (cmdErr error) {
 
 	case *StartEnvOpts:
 		envProvider := func(manifestPath string, statePath string, vars boshtpl.Variables, op patch.Op) DeploymentStateManager {
-			return NewEnvFactory(deps, manifestPath, statePath, vars, op, false).StateManager()
+			return NewEnvFactory(deps, manifestPath, statePath, vars, op, EnvFactoryOpts{}).StateManager()
 		}
 
 		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
 		return NewStartEnvCmd(deps.UI, envProvider).Run(stage, *opts)
 
+	case *EnvStateHistoryOpts:
+		history := biconfig.NewDeploymentStateHistory(deps.FS, opts.StatePath, biconfig.DeploymentStateHistoryLimit)
+		return NewEnvStateHistoryCmd(deps.UI, history).Run(*opts)
+
+	case *EnvStateDiffOpts:
+		history := biconfig.NewDeploymentStateHistory(deps.FS, opts.StatePath, biconfig.DeploymentStateHistoryLimit)
+		return NewEnvStateDiffCmd(deps.UI, deps.FS, history).Run(*opts)
+
+	case *EnvStateRestoreOpts:
+		history := biconfig.NewDeploymentStateHistory(deps.FS, opts.StatePath, biconfig.DeploymentStateHistoryLimit)
+		return NewEnvStateRestoreCmd(deps.UI, history, c.deploymentStateService(opts.StatePath)).Run(*opts)
+
+	case *EnvStateUnlockOpts:
+		return NewEnvStateUnlockCmd(deps.UI, c.deploymentStateService(opts.StatePath)).Run(*opts)
+
+	case *EnvStateInspectOpts:
+		deploymentStateService := biconfig.NewReadOnlyDeploymentStateService(c.deploymentStateService(opts.StatePath))
+		return NewEnvStateInspectCmd(
+			deps.UI,
+			deploymentStateService,
+			biconfig.NewStemcellRepo(deploymentStateService, deps.UUIDGen),
+			biconfig.NewDiskRepo(deploymentStateService, deps.UUIDGen),
+			biconfig.NewVMRepo(deploymentStateService),
+			biconfig.NewReleaseRepo(deploymentStateService, deps.UUIDGen),
+		).Run()
+
+	case *EnvStateValidateOpts:
+		deploymentStateService := biconfig.NewReadOnlyDeploymentStateService(c.deploymentStateService(opts.StatePath))
+
+		var cloudProvider EnvCloudProvider
+		if opts.CloudManifest != "" {
+			cloudProvider = NewEnvFactory(deps, opts.CloudManifest, opts.StatePath, opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp(), EnvFactoryOpts{
+				ReadOnlyState: true,
+			}).CloudProvider()
+		}
+
+		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
+		return NewEnvStateValidateCmd(deps.UI, deploymentStateService, c.stateChecker(deploymentStateService), cloudProvider).Run(stage)
+
+	case *EnvStateRepairOpts:
+		deploymentStateService := c.deploymentStateService(opts.StatePath)
+
+		var cloudProvider EnvCloudProvider
+		if opts.CloudManifest != "" {
+			cloudProvider = NewEnvFactory(deps, opts.CloudManifest, opts.StatePath, opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp(), EnvFactoryOpts{}).CloudProvider()
+		}
+
+		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
+		return NewEnvStateRepairCmd(deps.UI, deploymentStateService, c.stateChecker(deploymentStateService), cloudProvider).Run(stage)
+
+	case *CacheListOpts:
+		return NewCacheListCmd(deps.UI, c.cacheInspector(opts.CacheFlags)).Run()
+
+	case *CacheDuOpts:
+		return NewCacheDuCmd(deps.UI, c.cacheInspector(opts.CacheFlags)).Run()
+
+	case *CachePruneOpts:
+		return NewCachePruneCmd(deps.UI, c.cacheInspector(opts.CacheFlags), c.deploymentStateService, deps.Time).Run(*opts)
+
+	case *EnvCheckOpts:
+		checker := NewEnvFactory(deps, opts.Args.Manifest.Path, opts.StatePath, opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp(), EnvFactoryOpts{}).EnvChecker()
+
+		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
+		return NewEnvCheckCmd(deps.UI, checker).Run(stage)
+
+	case *EnvManifestOpts:
+		deploymentStateService := c.deploymentStateService(opts.StatePath)
+		return NewEnvManifestCmd(deps.UI, deploymentStateService, biconfig.NewManifestRepo(deploymentStateService)).Run(*opts)
+
+	case *EnvManifestDiffOpts:
+		deploymentStateService := c.deploymentStateService(biconfig.DeploymentStatePath(opts.Args.Manifest.Path, opts.StatePath))
+		return NewEnvManifestDiffCmd(deps.UI, deploymentStateService, biconfig.NewManifestRepo(deploymentStateService)).Run(*opts)
+
+	case *CPICallOpts:
+		statePath := biconfig.DeploymentStatePath(opts.Args.Manifest.Path, opts.StatePath)
+		cloudProvider := NewEnvFactory(deps, opts.Args.Manifest.Path, opts.StatePath, opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp(), EnvFactoryOpts{}).CloudProvider()
+
+		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
+		return NewCPICallCmd(deps.UI, c.deploymentStateService(statePath), cloudProvider).Run(stage, *opts)
+
+	case *CPITestOpts:
+		// the suite creates its own VM so it must not use the state of an environment
+		stateDir, err := deps.FS.TempDir("bosh-cpi-test")
+		if err != nil {
+			return err
+		}
+
+		defer deps.FS.RemoveAll(stateDir) //nolint:errcheck
+
+		statePath := filepath.Join(stateDir, "state.json")
+		tester := NewEnvFactory(deps, opts.Args.Manifest.Path, statePath, opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp(), EnvFactoryOpts{
+			CPICallPolicies:  opts.CPICallFlags.AsCallPolicies(),
+			CPICallRecording: opts.CPICallFlags.AsCallRecording(),
+		}).CPITester()
+
+		stage := boshui.NewStage(deps.UI, deps.Time, deps.Logger)
+		return NewCPITestCmd(deps.UI, deps.FS, tester).Run(stage, *opts)
+
 	case *AliasEnvOpts:
 		sessionFactory := func(config cmdconf.Config) Session {
 			return NewSessionFromOpts(c.BoshOpts, config, deps.UI, true, false, deps.FS, deps.Logger)
@@ -136,11 +279,35 @@ func (c Cmd) Execute() (cmdErr error) {
 		return NewLogOutCmd(sess.Environment(), config, deps.UI).Run()
 
 	case *TaskOpts:
-		eventsTaskReporter := boshuit.NewReporter(deps.UI, true)
+		var eventsTaskReporter boshuit.Reporter = boshuit.NewReporter(deps.UI, true)
+		if c.BoshOpts.NDJSONOpt {
+			eventsTaskReporter = boshuit.NewNDJSONReporter(boshui.NDJSONWriter(deps.UI), deps.Time)
+		}
+
 		plainTaskReporter := boshuit.NewReporter(deps.UI, false)
-		return NewTaskCmd(eventsTaskReporter, plainTaskReporter, c.director()).Run(*opts)
+		sess := c.session()
+
+		director, err := sess.Director()
+		if err != nil {
+			return err
+		}
+
+		offsets, err := NewFSTaskOffsets(c.BoshOpts.ConfigPathOpt, sess.Environment(), deps.FS)
+		if err != nil {
+			return err
+		}
+
+		return NewTaskCmd(deps.UI, eventsTaskReporter, plainTaskReporter, director, offsets).Run(*opts)
 
 	case *TasksOpts:
+		if opts.Watch {
+			if c.BoshOpts.JSONOpt {
+				return errors.New("Expected --watch to not be used with --json")
+			}
+
+			return NewTasksWatchCmd(deps.UI, c.director(), deps.Time, deps.UI.IsTTY(), signal.Notify, signal.Stop).Run(*opts)
+		}
+
 		return NewTasksCmd(deps.UI, c.director()).Run(*opts)
 
 	case *CancelTaskOpts:
@@ -297,7 +464,7 @@ func (c Cmd) Execute() (cmdErr error) {
 
 	case *UpdateRuntimeConfigOpts:
 		director := c.director()
-		releaseManager := c.releaseManager(director)
+		releaseManager := c.releaseManager(director.WithTaskTracking())
 		return NewUpdateRuntimeConfigCmd(deps.UI, director, releaseManager).Run(*opts)
 
 	case *ManifestOpts:
@@ -340,7 +507,8 @@ func (c Cmd) Execute() (cmdErr error) {
 
 	case *DeployOpts:
 		director, deployment := c.directorAndDeployment()
-		releaseManager := c.releaseManager(director)
+		// Releases have to be uploaded before the deploy even when it is not tracked
+		releaseManager := c.releaseManager(director.WithTaskTracking())
 		return NewDeployCmd(deps.UI, deployment, releaseManager, director).Run(*opts)
 
 	case *StartOpts:
@@ -514,9 +682,17 @@ func (c Cmd) configureUI() {
 	}
 
 	if c.BoshOpts.JSONOpt {
+		if c.BoshOpts.NDJSONOpt {
+			c.panicIfErr(errors.New("Expected only one of --json or --ndjson to be given"))
+		}
+
 		c.deps.UI.EnableJSON()
 	}
 
+	if c.BoshOpts.NDJSONOpt {
+		c.deps.UI.EnableNDJSON()
+	}
+
 	if c.BoshOpts.NonInteractiveOpt {
 		c.deps.UI.EnableNonInteractive()
 	}
@@ -539,6 +715,19 @@ func (c Cmd) configureFS() {
 	c.panicIfErr(err)
 }
 
+// handleTaskNotTracked tells how to follow a task queued with --no-track
+// instead of failing the command
+func (c Cmd) handleTaskNotTracked(err error) error {
+	notTrackedErr, ok := boshdir.AsTaskNotTrackedError(err)
+	if !ok {
+		return err
+	}
+
+	c.deps.UI.PrintLinef("Task %d queued, attach with 'bosh task %d --attach'", notTrackedErr.ID, notTrackedErr.ID)
+
+	return nil
+}
+
 func (c Cmd) config() cmdconf.Config {
 	config, err := cmdconf.NewFSConfigFromPath(c.BoshOpts.ConfigPathOpt, c.deps.FS)
 	c.panicIfErr(err)
@@ -636,6 +825,64 @@ func (c Cmd) releaseDir(dir DirOrCWDArg) boshreldir.ReleaseDir {
 	return relDirProv.NewFSReleaseDir(dir.Path, c.BoshOpts.Parallel)
 }
 
+func (c Cmd) deploymentStateService(statePath string) biconfig.DeploymentStateService {
+	httpClient := httpclient.NewHTTPClient(httpclient.CreateExternalDefaultClient(nil), c.deps.Logger)
+	return biconfig.NewDeploymentStateService(c.deps.FS, httpClient, c.deps.UUIDGen, c.deps.Logger, statePath)
+}
+
+// envBundle reads the bundle of create-env and rejects it unless the
+// manifest, variables and ops files of create-env interpolate to its manifest
+func (c Cmd) envBundle(opts CreateEnvOpts) (bitarball.Bundle, error) {
+	template, err := bidepltpl.NewDeploymentTemplateFactory(c.deps.FS).NewDeploymentTemplateFromPath(opts.Args.Manifest.Path)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Reading deployment manifest '%s'", opts.Args.Manifest.Path)
+	}
+
+	interpolatedTemplate, err := template.Evaluate(opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp())
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Evaluating manifest")
+	}
+
+	bundle, err := bitarball.NewBundleReader(c.deps.FS, c.deps.Compressor, c.deps.Logger).Read(opts.Bundle)
+	if err != nil {
+		return nil, err
+	}
+
+	err = bundle.VerifyManifest(interpolatedTemplate.Content())
+	if err != nil {
+		bundle.Cleanup() //nolint:errcheck
+		return nil, err
+	}
+
+	return bundle, nil
+}
+
+func (c Cmd) cacheInspector(flags CacheFlags) CacheInspector {
+	workspacePath := workspaceRootPath()
+
+	var compiledPackageCache bistatepkg.CompiledPackageCache
+	if flags.CompiledPackagesCache != "" {
+		// only lists and removes packages, so no packages dir is needed
+		compiledPackageCache = bistatepkg.NewCompiledPackageCache(flags.CompiledPackagesCache, "", compiledPackageDigestsPath(workspacePath, flags.CompiledPackageDigests), c.deps.FS, c.deps.DigestCreationAlgorithms, c.deps.Logger)
+	}
+
+	return NewCacheInspector(
+		bitarball.NewCache(tarballCachePath(workspacePath), c.deps.FS, c.deps.Logger),
+		boshinst.NewTargetLister(installationsPath(workspacePath), c.deps.FS),
+		compiledPackageCache,
+	)
+}
+
+func (c Cmd) stateChecker(deploymentStateService biconfig.DeploymentStateService) bidepl.StateChecker {
+	return bidepl.NewStateChecker(
+		deploymentStateService,
+		biconfig.NewStemcellRepo(deploymentStateService, c.deps.UUIDGen),
+		biconfig.NewDiskRepo(deploymentStateService, c.deps.UUIDGen),
+		biconfig.NewVMRepo(deploymentStateService),
+		biconfig.NewReleaseRepo(deploymentStateService, c.deps.UUIDGen),
+	)
+}
+
 func (c Cmd) panicIfErr(err error) {
 	if err != nil {
 		panic(cmdConveniencePanic{err})
@@ -97,6 +97,24 @@ var _ = Describe("Cmd", func() {
 			})
 		})
 
+		It("returns error if both json and ndjson output are requested", func() {
+			boshCmd.BoshOpts = opts.BoshOpts{JSONOpt: true, NDJSONOpt: true}
+			boshCmd.Opts = &opts.InterpolateOpts{}
+
+			err := boshCmd.Execute()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected only one of --json or --ndjson to be given"))
+		})
+
+		It("returns error if tasks are watched with json output", func() {
+			boshCmd.BoshOpts = opts.BoshOpts{JSONOpt: true}
+			boshCmd.Opts = &opts.TasksOpts{Watch: true}
+
+			err := boshCmd.Execute()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected --watch to not be used with --json"))
+		})
+
 		It("returns error if changing tmp root fails", func() {
 			fs.ChangeTempRootErr = errors.New("fake-err")
 
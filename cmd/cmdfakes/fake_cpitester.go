@@ -0,0 +1,118 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package cmdfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cpi/conformance"
+	"github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+type FakeCPITester struct {
+	TestStub        func(ui.Stage) (conformance.Report, error)
+	testMutex       sync.RWMutex
+	testArgsForCall []struct {
+		arg1 ui.Stage
+	}
+	testReturns struct {
+		result1 conformance.Report
+		result2 error
+	}
+	testReturnsOnCall map[int]struct {
+		result1 conformance.Report
+		result2 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeCPITester) Test(arg1 ui.Stage) (conformance.Report, error) {
+	fake.testMutex.Lock()
+	ret, specificReturn := fake.testReturnsOnCall[len(fake.testArgsForCall)]
+	fake.testArgsForCall = append(fake.testArgsForCall, struct {
+		arg1 ui.Stage
+	}{arg1})
+	stub := fake.TestStub
+	fakeReturns := fake.testReturns
+	fake.recordInvocation("Test", []interface{}{arg1})
+	fake.testMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1, ret.result2
+	}
+	return fakeReturns.result1, fakeReturns.result2
+}
+
+func (fake *FakeCPITester) TestCallCount() int {
+	fake.testMutex.RLock()
+	defer fake.testMutex.RUnlock()
+	return len(fake.testArgsForCall)
+}
+
+func (fake *FakeCPITester) TestCalls(stub func(ui.Stage) (conformance.Report, error)) {
+	fake.testMutex.Lock()
+	defer fake.testMutex.Unlock()
+	fake.TestStub = stub
+}
+
+func (fake *FakeCPITester) TestArgsForCall(i int) ui.Stage {
+	fake.testMutex.RLock()
+	defer fake.testMutex.RUnlock()
+	argsForCall := fake.testArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeCPITester) TestReturns(result1 conformance.Report, result2 error) {
+	fake.testMutex.Lock()
+	defer fake.testMutex.Unlock()
+	fake.TestStub = nil
+	fake.testReturns = struct {
+		result1 conformance.Report
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCPITester) TestReturnsOnCall(i int, result1 conformance.Report, result2 error) {
+	fake.testMutex.Lock()
+	defer fake.testMutex.Unlock()
+	fake.TestStub = nil
+	if fake.testReturnsOnCall == nil {
+		fake.testReturnsOnCall = make(map[int]struct {
+			result1 conformance.Report
+			result2 error
+		})
+	}
+	fake.testReturnsOnCall[i] = struct {
+		result1 conformance.Report
+		result2 error
+	}{result1, result2}
+}
+
+func (fake *FakeCPITester) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.testMutex.RLock()
+	defer fake.testMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeCPITester) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ cmd.CPITester = new(FakeCPITester)
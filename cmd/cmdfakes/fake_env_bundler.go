@@ -0,0 +1,114 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package cmdfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+type FakeEnvBundler struct {
+	BundleStub        func(string, ui.Stage) error
+	bundleMutex       sync.RWMutex
+	bundleArgsForCall []struct {
+		arg1 string
+		arg2 ui.Stage
+	}
+	bundleReturns struct {
+		result1 error
+	}
+	bundleReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeEnvBundler) Bundle(arg1 string, arg2 ui.Stage) error {
+	fake.bundleMutex.Lock()
+	ret, specificReturn := fake.bundleReturnsOnCall[len(fake.bundleArgsForCall)]
+	fake.bundleArgsForCall = append(fake.bundleArgsForCall, struct {
+		arg1 string
+		arg2 ui.Stage
+	}{arg1, arg2})
+	stub := fake.BundleStub
+	fakeReturns := fake.bundleReturns
+	fake.recordInvocation("Bundle", []interface{}{arg1, arg2})
+	fake.bundleMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeEnvBundler) BundleCallCount() int {
+	fake.bundleMutex.RLock()
+	defer fake.bundleMutex.RUnlock()
+	return len(fake.bundleArgsForCall)
+}
+
+func (fake *FakeEnvBundler) BundleCalls(stub func(string, ui.Stage) error) {
+	fake.bundleMutex.Lock()
+	defer fake.bundleMutex.Unlock()
+	fake.BundleStub = stub
+}
+
+func (fake *FakeEnvBundler) BundleArgsForCall(i int) (string, ui.Stage) {
+	fake.bundleMutex.RLock()
+	defer fake.bundleMutex.RUnlock()
+	argsForCall := fake.bundleArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeEnvBundler) BundleReturns(result1 error) {
+	fake.bundleMutex.Lock()
+	defer fake.bundleMutex.Unlock()
+	fake.BundleStub = nil
+	fake.bundleReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeEnvBundler) BundleReturnsOnCall(i int, result1 error) {
+	fake.bundleMutex.Lock()
+	defer fake.bundleMutex.Unlock()
+	fake.BundleStub = nil
+	if fake.bundleReturnsOnCall == nil {
+		fake.bundleReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.bundleReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeEnvBundler) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.bundleMutex.RLock()
+	defer fake.bundleMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeEnvBundler) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ cmd.EnvBundler = new(FakeEnvBundler)
@@ -0,0 +1,269 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package cmdfakes
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cloud"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+type FakeEnvCloudProvider struct {
+	WithCPICmdRunnerStub        func(ui.Stage, func(cloud.CPICmdRunner, cloud.CmdContext) error) error
+	withCPICmdRunnerMutex       sync.RWMutex
+	withCPICmdRunnerArgsForCall []struct {
+		arg1 ui.Stage
+		arg2 func(cloud.CPICmdRunner, cloud.CmdContext) error
+	}
+	withCPICmdRunnerReturns struct {
+		result1 error
+	}
+	withCPICmdRunnerReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WithCloudStub        func(ui.Stage, func(cloud.Cloud) error) error
+	withCloudMutex       sync.RWMutex
+	withCloudArgsForCall []struct {
+		arg1 ui.Stage
+		arg2 func(cloud.Cloud) error
+	}
+	withCloudReturns struct {
+		result1 error
+	}
+	withCloudReturnsOnCall map[int]struct {
+		result1 error
+	}
+	WithStemcellCloudStub        func(ui.Stage, int, func(cloud.Cloud) error) error
+	withStemcellCloudMutex       sync.RWMutex
+	withStemcellCloudArgsForCall []struct {
+		arg1 ui.Stage
+		arg2 int
+		arg3 func(cloud.Cloud) error
+	}
+	withStemcellCloudReturns struct {
+		result1 error
+	}
+	withStemcellCloudReturnsOnCall map[int]struct {
+		result1 error
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeEnvCloudProvider) WithCPICmdRunner(arg1 ui.Stage, arg2 func(cloud.CPICmdRunner, cloud.CmdContext) error) error {
+	fake.withCPICmdRunnerMutex.Lock()
+	ret, specificReturn := fake.withCPICmdRunnerReturnsOnCall[len(fake.withCPICmdRunnerArgsForCall)]
+	fake.withCPICmdRunnerArgsForCall = append(fake.withCPICmdRunnerArgsForCall, struct {
+		arg1 ui.Stage
+		arg2 func(cloud.CPICmdRunner, cloud.CmdContext) error
+	}{arg1, arg2})
+	stub := fake.WithCPICmdRunnerStub
+	fakeReturns := fake.withCPICmdRunnerReturns
+	fake.recordInvocation("WithCPICmdRunner", []interface{}{arg1, arg2})
+	fake.withCPICmdRunnerMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeEnvCloudProvider) WithCPICmdRunnerCallCount() int {
+	fake.withCPICmdRunnerMutex.RLock()
+	defer fake.withCPICmdRunnerMutex.RUnlock()
+	return len(fake.withCPICmdRunnerArgsForCall)
+}
+
+func (fake *FakeEnvCloudProvider) WithCPICmdRunnerCalls(stub func(ui.Stage, func(cloud.CPICmdRunner, cloud.CmdContext) error) error) {
+	fake.withCPICmdRunnerMutex.Lock()
+	defer fake.withCPICmdRunnerMutex.Unlock()
+	fake.WithCPICmdRunnerStub = stub
+}
+
+func (fake *FakeEnvCloudProvider) WithCPICmdRunnerArgsForCall(i int) (ui.Stage, func(cloud.CPICmdRunner, cloud.CmdContext) error) {
+	fake.withCPICmdRunnerMutex.RLock()
+	defer fake.withCPICmdRunnerMutex.RUnlock()
+	argsForCall := fake.withCPICmdRunnerArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeEnvCloudProvider) WithCPICmdRunnerReturns(result1 error) {
+	fake.withCPICmdRunnerMutex.Lock()
+	defer fake.withCPICmdRunnerMutex.Unlock()
+	fake.WithCPICmdRunnerStub = nil
+	fake.withCPICmdRunnerReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeEnvCloudProvider) WithCPICmdRunnerReturnsOnCall(i int, result1 error) {
+	fake.withCPICmdRunnerMutex.Lock()
+	defer fake.withCPICmdRunnerMutex.Unlock()
+	fake.WithCPICmdRunnerStub = nil
+	if fake.withCPICmdRunnerReturnsOnCall == nil {
+		fake.withCPICmdRunnerReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.withCPICmdRunnerReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeEnvCloudProvider) WithCloud(arg1 ui.Stage, arg2 func(cloud.Cloud) error) error {
+	fake.withCloudMutex.Lock()
+	ret, specificReturn := fake.withCloudReturnsOnCall[len(fake.withCloudArgsForCall)]
+	fake.withCloudArgsForCall = append(fake.withCloudArgsForCall, struct {
+		arg1 ui.Stage
+		arg2 func(cloud.Cloud) error
+	}{arg1, arg2})
+	stub := fake.WithCloudStub
+	fakeReturns := fake.withCloudReturns
+	fake.recordInvocation("WithCloud", []interface{}{arg1, arg2})
+	fake.withCloudMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeEnvCloudProvider) WithCloudCallCount() int {
+	fake.withCloudMutex.RLock()
+	defer fake.withCloudMutex.RUnlock()
+	return len(fake.withCloudArgsForCall)
+}
+
+func (fake *FakeEnvCloudProvider) WithCloudCalls(stub func(ui.Stage, func(cloud.Cloud) error) error) {
+	fake.withCloudMutex.Lock()
+	defer fake.withCloudMutex.Unlock()
+	fake.WithCloudStub = stub
+}
+
+func (fake *FakeEnvCloudProvider) WithCloudArgsForCall(i int) (ui.Stage, func(cloud.Cloud) error) {
+	fake.withCloudMutex.RLock()
+	defer fake.withCloudMutex.RUnlock()
+	argsForCall := fake.withCloudArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeEnvCloudProvider) WithCloudReturns(result1 error) {
+	fake.withCloudMutex.Lock()
+	defer fake.withCloudMutex.Unlock()
+	fake.WithCloudStub = nil
+	fake.withCloudReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeEnvCloudProvider) WithCloudReturnsOnCall(i int, result1 error) {
+	fake.withCloudMutex.Lock()
+	defer fake.withCloudMutex.Unlock()
+	fake.WithCloudStub = nil
+	if fake.withCloudReturnsOnCall == nil {
+		fake.withCloudReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.withCloudReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeEnvCloudProvider) WithStemcellCloud(arg1 ui.Stage, arg2 int, arg3 func(cloud.Cloud) error) error {
+	fake.withStemcellCloudMutex.Lock()
+	ret, specificReturn := fake.withStemcellCloudReturnsOnCall[len(fake.withStemcellCloudArgsForCall)]
+	fake.withStemcellCloudArgsForCall = append(fake.withStemcellCloudArgsForCall, struct {
+		arg1 ui.Stage
+		arg2 int
+		arg3 func(cloud.Cloud) error
+	}{arg1, arg2, arg3})
+	stub := fake.WithStemcellCloudStub
+	fakeReturns := fake.withStemcellCloudReturns
+	fake.recordInvocation("WithStemcellCloud", []interface{}{arg1, arg2, arg3})
+	fake.withStemcellCloudMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2, arg3)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeEnvCloudProvider) WithStemcellCloudCallCount() int {
+	fake.withStemcellCloudMutex.RLock()
+	defer fake.withStemcellCloudMutex.RUnlock()
+	return len(fake.withStemcellCloudArgsForCall)
+}
+
+func (fake *FakeEnvCloudProvider) WithStemcellCloudCalls(stub func(ui.Stage, int, func(cloud.Cloud) error) error) {
+	fake.withStemcellCloudMutex.Lock()
+	defer fake.withStemcellCloudMutex.Unlock()
+	fake.WithStemcellCloudStub = stub
+}
+
+func (fake *FakeEnvCloudProvider) WithStemcellCloudArgsForCall(i int) (ui.Stage, int, func(cloud.Cloud) error) {
+	fake.withStemcellCloudMutex.RLock()
+	defer fake.withStemcellCloudMutex.RUnlock()
+	argsForCall := fake.withStemcellCloudArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2, argsForCall.arg3
+}
+
+func (fake *FakeEnvCloudProvider) WithStemcellCloudReturns(result1 error) {
+	fake.withStemcellCloudMutex.Lock()
+	defer fake.withStemcellCloudMutex.Unlock()
+	fake.WithStemcellCloudStub = nil
+	fake.withStemcellCloudReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeEnvCloudProvider) WithStemcellCloudReturnsOnCall(i int, result1 error) {
+	fake.withStemcellCloudMutex.Lock()
+	defer fake.withStemcellCloudMutex.Unlock()
+	fake.WithStemcellCloudStub = nil
+	if fake.withStemcellCloudReturnsOnCall == nil {
+		fake.withStemcellCloudReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.withStemcellCloudReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeEnvCloudProvider) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.withCPICmdRunnerMutex.RLock()
+	defer fake.withCPICmdRunnerMutex.RUnlock()
+	fake.withCloudMutex.RLock()
+	defer fake.withCloudMutex.RUnlock()
+	fake.withStemcellCloudMutex.RLock()
+	defer fake.withStemcellCloudMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeEnvCloudProvider) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ cmd.EnvCloudProvider = new(FakeEnvCloudProvider)
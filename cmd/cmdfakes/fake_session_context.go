@@ -6,6 +6,7 @@ import (
 
 	"github.com/cloudfoundry/bosh-cli/v7/cmd"
 	"github.com/cloudfoundry/bosh-cli/v7/cmd/config"
+	"github.com/cloudfoundry/bosh-cli/v7/director"
 )
 
 type FakeSessionContext struct {
@@ -59,6 +60,36 @@ type FakeSessionContext struct {
 	environmentReturnsOnCall map[int]struct {
 		result1 string
 	}
+	NDJSONStub        func() bool
+	nDJSONMutex       sync.RWMutex
+	nDJSONArgsForCall []struct {
+	}
+	nDJSONReturns struct {
+		result1 bool
+	}
+	nDJSONReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	NoTrackStub        func() bool
+	noTrackMutex       sync.RWMutex
+	noTrackArgsForCall []struct {
+	}
+	noTrackReturns struct {
+		result1 bool
+	}
+	noTrackReturnsOnCall map[int]struct {
+		result1 bool
+	}
+	TaskInterruptActionStub        func() director.TaskInterruptAction
+	taskInterruptActionMutex       sync.RWMutex
+	taskInterruptActionArgsForCall []struct {
+	}
+	taskInterruptActionReturns struct {
+		result1 director.TaskInterruptAction
+	}
+	taskInterruptActionReturnsOnCall map[int]struct {
+		result1 director.TaskInterruptAction
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -296,6 +327,10 @@ func (fake *FakeSessionContext) Environment() string {
 func (fake *FakeSessionContext) EnvironmentCallCount() int {
 	fake.environmentMutex.RLock()
 	defer fake.environmentMutex.RUnlock()
+	fake.nDJSONMutex.RLock()
+	defer fake.nDJSONMutex.RUnlock()
+	fake.noTrackMutex.RLock()
+	defer fake.noTrackMutex.RUnlock()
 	return len(fake.environmentArgsForCall)
 }
 
@@ -328,6 +363,165 @@ func (fake *FakeSessionContext) EnvironmentReturnsOnCall(i int, result1 string)
 	}{result1}
 }
 
+func (fake *FakeSessionContext) NDJSON() bool {
+	fake.nDJSONMutex.Lock()
+	ret, specificReturn := fake.nDJSONReturnsOnCall[len(fake.nDJSONArgsForCall)]
+	fake.nDJSONArgsForCall = append(fake.nDJSONArgsForCall, struct {
+	}{})
+	stub := fake.NDJSONStub
+	fakeReturns := fake.nDJSONReturns
+	fake.recordInvocation("NDJSON", []interface{}{})
+	fake.nDJSONMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSessionContext) NDJSONCallCount() int {
+	fake.nDJSONMutex.RLock()
+	defer fake.nDJSONMutex.RUnlock()
+	return len(fake.nDJSONArgsForCall)
+}
+
+func (fake *FakeSessionContext) NDJSONCalls(stub func() bool) {
+	fake.nDJSONMutex.Lock()
+	defer fake.nDJSONMutex.Unlock()
+	fake.NDJSONStub = stub
+}
+
+func (fake *FakeSessionContext) NDJSONReturns(result1 bool) {
+	fake.nDJSONMutex.Lock()
+	defer fake.nDJSONMutex.Unlock()
+	fake.NDJSONStub = nil
+	fake.nDJSONReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeSessionContext) NDJSONReturnsOnCall(i int, result1 bool) {
+	fake.nDJSONMutex.Lock()
+	defer fake.nDJSONMutex.Unlock()
+	fake.NDJSONStub = nil
+	if fake.nDJSONReturnsOnCall == nil {
+		fake.nDJSONReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.nDJSONReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeSessionContext) NoTrack() bool {
+	fake.noTrackMutex.Lock()
+	ret, specificReturn := fake.noTrackReturnsOnCall[len(fake.noTrackArgsForCall)]
+	fake.noTrackArgsForCall = append(fake.noTrackArgsForCall, struct {
+	}{})
+	stub := fake.NoTrackStub
+	fakeReturns := fake.noTrackReturns
+	fake.recordInvocation("NoTrack", []interface{}{})
+	fake.noTrackMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSessionContext) NoTrackCallCount() int {
+	fake.noTrackMutex.RLock()
+	defer fake.noTrackMutex.RUnlock()
+	return len(fake.noTrackArgsForCall)
+}
+
+func (fake *FakeSessionContext) NoTrackCalls(stub func() bool) {
+	fake.noTrackMutex.Lock()
+	defer fake.noTrackMutex.Unlock()
+	fake.NoTrackStub = stub
+}
+
+func (fake *FakeSessionContext) NoTrackReturns(result1 bool) {
+	fake.noTrackMutex.Lock()
+	defer fake.noTrackMutex.Unlock()
+	fake.NoTrackStub = nil
+	fake.noTrackReturns = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeSessionContext) NoTrackReturnsOnCall(i int, result1 bool) {
+	fake.noTrackMutex.Lock()
+	defer fake.noTrackMutex.Unlock()
+	fake.NoTrackStub = nil
+	if fake.noTrackReturnsOnCall == nil {
+		fake.noTrackReturnsOnCall = make(map[int]struct {
+			result1 bool
+		})
+	}
+	fake.noTrackReturnsOnCall[i] = struct {
+		result1 bool
+	}{result1}
+}
+
+func (fake *FakeSessionContext) TaskInterruptAction() director.TaskInterruptAction {
+	fake.taskInterruptActionMutex.Lock()
+	ret, specificReturn := fake.taskInterruptActionReturnsOnCall[len(fake.taskInterruptActionArgsForCall)]
+	fake.taskInterruptActionArgsForCall = append(fake.taskInterruptActionArgsForCall, struct {
+	}{})
+	stub := fake.TaskInterruptActionStub
+	fakeReturns := fake.taskInterruptActionReturns
+	fake.recordInvocation("TaskInterruptAction", []interface{}{})
+	fake.taskInterruptActionMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeSessionContext) TaskInterruptActionCallCount() int {
+	fake.taskInterruptActionMutex.RLock()
+	defer fake.taskInterruptActionMutex.RUnlock()
+	return len(fake.taskInterruptActionArgsForCall)
+}
+
+func (fake *FakeSessionContext) TaskInterruptActionCalls(stub func() director.TaskInterruptAction) {
+	fake.taskInterruptActionMutex.Lock()
+	defer fake.taskInterruptActionMutex.Unlock()
+	fake.TaskInterruptActionStub = stub
+}
+
+func (fake *FakeSessionContext) TaskInterruptActionReturns(result1 director.TaskInterruptAction) {
+	fake.taskInterruptActionMutex.Lock()
+	defer fake.taskInterruptActionMutex.Unlock()
+	fake.TaskInterruptActionStub = nil
+	fake.taskInterruptActionReturns = struct {
+		result1 director.TaskInterruptAction
+	}{result1}
+}
+
+func (fake *FakeSessionContext) TaskInterruptActionReturnsOnCall(i int, result1 director.TaskInterruptAction) {
+	fake.taskInterruptActionMutex.Lock()
+	defer fake.taskInterruptActionMutex.Unlock()
+	fake.TaskInterruptActionStub = nil
+	if fake.taskInterruptActionReturnsOnCall == nil {
+		fake.taskInterruptActionReturnsOnCall = make(map[int]struct {
+			result1 director.TaskInterruptAction
+		})
+	}
+	fake.taskInterruptActionReturnsOnCall[i] = struct {
+		result1 director.TaskInterruptAction
+	}{result1}
+}
+
 func (fake *FakeSessionContext) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -341,6 +535,8 @@ func (fake *FakeSessionContext) Invocations() map[string][][]interface{} {
 	defer fake.deploymentMutex.RUnlock()
 	fake.environmentMutex.RLock()
 	defer fake.environmentMutex.RUnlock()
+	fake.taskInterruptActionMutex.RLock()
+	defer fake.taskInterruptActionMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -153,9 +153,12 @@ var globalFlags = []string{
 	"--help\thelp for bosh",
 	"-h\thelp for bosh",
 	"--json\tOutput as JSON",
+	"--ndjson\tStream task events and task state changes as newline-delimited JSON to stdout and all other output to stderr",
 	"--no-color\tToggle colorized output",
+	"--no-track\tReturn as soon as a task that changes the environment is queued instead of tracking it, env: BOSH_NO_TRACK",
 	"--non-interactive\tDon't ask for user input, env: BOSH_NON_INTERACTIVE",
 	"-n\tDon't ask for user input, env: BOSH_NON_INTERACTIVE",
+	"--on-task-interrupt\tCancel, detach from or watch a tracked task when interrupted without asking, env: BOSH_ON_TASK_INTERRUPT",
 	"--parallel\tThe max number of parallel operations",
 	"--sha2\tUse SHA256 checksums, env: BOSH_SHA2",
 	"--tty\tForce TTY-like output",
@@ -167,6 +170,7 @@ var globalCommands = []string{
 	"alias-env\tAlias environment to save URL and CA certificate",
 	"attach-disk\tAttach disk to an instance",
 	"blobs\tList blobs",
+	"cache\tInspect and prune cached tarballs, installations and compiled packages",
 	"cancel-task\tCancel task at its next checkpoint",
 	"cancel-tasks\tCancel tasks at their next checkpoints",
 	"clean-up\tClean up old unused resources except orphaned disks",
@@ -175,8 +179,11 @@ var globalCommands = []string{
 	"completion\tGenerate the autocompletion script for the specified shell",
 	"config\tShow current config for either ID or both type and name",
 	"configs\tList configs",
+	"cpi-call\tCall a CPI method with the CPI installed for a BOSH environment",
 	"cpi-config\tShow current CPI config",
+	"cpi-test\tTest that a CPI release conforms to the CPI contract used by create-env",
 	"create-env\tCreate or update BOSH environment",
+	"create-env-bundle\tBundle an interpolated manifest with its release and stemcell tarballs for create-env without network access",
 	"create-recovery-plan\tInteractively generate a recovery plan for disaster repair",
 	"create-release\tCreate release",
 	"curl\tMake an HTTP request to the Director",
@@ -195,6 +202,10 @@ var globalCommands = []string{
 	"deployments\tList deployments",
 	"diff-config\tDiff two configs by ID or content",
 	"disks\tList disks",
+	"env-check\tCheck the VM, disks and agent of a BOSH environment against its state",
+	"env-manifest\tShow the manifest last deployed with create-env",
+	"env-manifest-diff\tShow differences between the manifest last deployed with create-env and a manifest",
+	"env-state\tInspect, validate, repair, unlock and restore saved versions of BOSH environment state",
 	"environment\tShow environment",
 	"environments\tList environments",
 	"errands\tList errands",
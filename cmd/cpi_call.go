@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+type CPICallCmd struct {
+	ui                     boshui.UI
+	deploymentStateService biconfig.DeploymentStateService
+	cloudProvider          EnvCloudProvider
+}
+
+func NewCPICallCmd(
+	ui boshui.UI,
+	deploymentStateService biconfig.DeploymentStateService,
+	cloudProvider EnvCloudProvider,
+) CPICallCmd {
+	return CPICallCmd{
+		ui:                     ui,
+		deploymentStateService: deploymentStateService,
+		cloudProvider:          cloudProvider,
+	}
+}
+
+func (c CPICallCmd) Run(stage boshui.Stage, opts CPICallOpts) (err error) {
+	if !c.deploymentStateService.Exists() {
+		return bosherr.Errorf("Expected deployment state '%s' to exist", c.deploymentStateService.Path())
+	}
+
+	// CPI methods may create or delete resources that the state refers to
+	err = c.deploymentStateService.Lock()
+	if err != nil {
+		return bosherr.WrapError(err, "Locking deployment state")
+	}
+	defer func() {
+		unlockErr := c.deploymentStateService.Unlock()
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	arguments := []interface{}{}
+
+	if len(opts.Arguments) > 0 {
+		err := json.Unmarshal([]byte(opts.Arguments), &arguments)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Expected arguments to be a JSON array")
+		}
+	}
+
+	apiVersion := opts.ApiVersion
+	if apiVersion == 0 {
+		apiVersion = bicloud.DefaultCPIVersion
+	}
+
+	var cmdOutput bicloud.CmdOutput
+
+	err = c.cloudProvider.WithCPICmdRunner(stage, func(cpiCmdRunner bicloud.CPICmdRunner, context bicloud.CmdContext) error {
+		if len(opts.Context) > 0 {
+			decoder := json.NewDecoder(bytes.NewReader([]byte(opts.Context)))
+			decoder.DisallowUnknownFields()
+
+			// values given in the flag replace those of the environment
+			err := decoder.Decode(&context)
+			if err != nil {
+				return bosherr.WrapErrorf(err, "Expected context to be a JSON object with 'director_uuid' or 'vm'")
+			}
+		}
+
+		return stage.Perform("Calling CPI method '"+opts.Args.Method+"'", func() error {
+			var err error
+
+			cmdOutput, err = cpiCmdRunner.Run(context, opts.Args.Method, apiVersion, arguments...)
+			if err != nil {
+				return bosherr.WrapErrorf(err, "Calling CPI method '%s'", opts.Args.Method)
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+
+	var cmdErr string
+	if cmdOutput.Error != nil {
+		cmdErr = bicloud.NewCPIError(opts.Args.Method, *cmdOutput.Error).Error()
+	}
+
+	c.ui.PrintTable(boshtbl.Table{
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Method"),
+			boshtbl.NewHeader("Result"),
+			boshtbl.NewHeader("Error"),
+			boshtbl.NewHeader("Log"),
+		},
+		Rows: [][]boshtbl.Value{
+			{
+				boshtbl.NewValueString(opts.Args.Method),
+				boshtbl.NewValueInterface(cmdOutput.Result),
+				boshtbl.NewValueString(cmdErr),
+				boshtbl.NewValueString(cmdOutput.Log),
+			},
+		},
+		Transpose: true,
+	})
+
+	if cmdOutput.Error != nil {
+		return bicloud.NewCPIError(opts.Args.Method, *cmdOutput.Error)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package cmd_test
+
+import (
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	fakebicloud "github.com/cloudfoundry/bosh-cli/v7/cloud/fakes"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/cmdfakes"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	fakebiui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+var _ = Describe("CPICallCmd", func() {
+	var (
+		ui                     *fakebiui.FakeUI
+		stage                  *fakebiui.FakeStage
+		deploymentStateService biconfig.DeploymentStateService
+		cloudProvider          *cmdfakes.FakeEnvCloudProvider
+		fakeCPICmdRunner       *fakebicloud.FakeCPICmdRunner
+		callOpts               opts.CPICallOpts
+		command                cmd.CPICallCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakebiui.FakeUI{}
+		stage = fakebiui.NewFakeStage()
+		fs := fakesys.NewFakeFileSystem()
+		deploymentStateService = biconfig.NewFileSystemDeploymentStateService(fs, fakeuuid.NewFakeGenerator(), boshlog.NewLogger(boshlog.LevelNone), "/state.json")
+
+		fakeCPICmdRunner = fakebicloud.NewFakeCPICmdRunner()
+		cloudProvider = &cmdfakes.FakeEnvCloudProvider{}
+		cloudProvider.WithCPICmdRunnerStub = func(stage boshui.Stage, fn func(bicloud.CPICmdRunner, bicloud.CmdContext) error) error {
+			return fn(fakeCPICmdRunner, bicloud.NewCmdContext("fake-director-id", 2))
+		}
+
+		callOpts = opts.CPICallOpts{Args: opts.CPICallArgs{Method: "has_vm"}}
+		command = cmd.NewCPICallCmd(ui, deploymentStateService, cloudProvider)
+	})
+
+	Context("when the deployment state exists", func() {
+		BeforeEach(func() {
+			err := deploymentStateService.Save(biconfig.DeploymentState{DirectorID: "fake-director-id"})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("calls the method with the arguments and prints the result and log", func() {
+			fakeCPICmdRunner.RunCmdOutputs = []bicloud.CmdOutput{{Result: true, Log: "fake-log"}}
+			callOpts.Arguments = `["fake-vm-cid", {"key": "value"}]`
+
+			err := command.Run(stage, callOpts)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeCPICmdRunner.CurrentRunInput).To(Equal([]fakebicloud.RunInput{
+				{
+					Context:    bicloud.NewCmdContext("fake-director-id", 2),
+					Method:     "has_vm",
+					Arguments:  []interface{}{"fake-vm-cid", map[string]interface{}{"key": "value"}},
+					ApiVersion: 1,
+				},
+			}))
+
+			Expect(stage.PerformCalls[0].Name).To(Equal("Calling CPI method 'has_vm'"))
+
+			Expect(ui.Table.Rows).To(Equal([][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("has_vm"),
+					boshtbl.NewValueInterface(true),
+					boshtbl.NewValueString(""),
+					boshtbl.NewValueString("fake-log"),
+				},
+			}))
+		})
+
+		It("replaces the context values and the api version given in the options", func() {
+			fakeCPICmdRunner.RunCmdOutputs = []bicloud.CmdOutput{{}}
+			callOpts.Context = `{"director_uuid": "other-director-id"}`
+			callOpts.ApiVersion = 2
+
+			err := command.Run(stage, callOpts)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeCPICmdRunner.CurrentRunInput[0].Context).To(Equal(bicloud.NewCmdContext("other-director-id", 2)))
+			Expect(fakeCPICmdRunner.CurrentRunInput[0].ApiVersion).To(Equal(2))
+			Expect(fakeCPICmdRunner.CurrentRunInput[0].Arguments).To(BeEmpty())
+		})
+
+		It("prints the CPI error and fails", func() {
+			fakeCPICmdRunner.RunCmdOutputs = []bicloud.CmdOutput{{Error: &bicloud.CmdError{Type: "Bosh::Clouds::VMNotFound", Message: "gone"}}}
+
+			err := command.Run(stage, callOpts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Bosh::Clouds::VMNotFound"))
+
+			Expect(ui.Table.Rows[0][2]).To(Equal(boshtbl.NewValueString(err.Error())))
+		})
+
+		It("returns an error when the arguments are not a JSON array", func() {
+			callOpts.Arguments = `{"not": "an array"}`
+
+			err := command.Run(stage, callOpts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected arguments to be a JSON array"))
+			Expect(cloudProvider.WithCPICmdRunnerCallCount()).To(Equal(0))
+		})
+
+		It("returns an error when the context has unknown values", func() {
+			callOpts.Context = `{"unknown": "value"}`
+
+			err := command.Run(stage, callOpts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected context to be a JSON object"))
+			Expect(fakeCPICmdRunner.CurrentRunInput).To(BeEmpty())
+		})
+	})
+
+	It("returns an error when the deployment state does not exist", func() {
+		err := command.Run(stage, callOpts)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected deployment state '/state.json' to exist"))
+	})
+})
@@ -0,0 +1,20 @@
+package cmd
+
+import (
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+type cpiRetryReporter struct {
+	ui biui.UI
+}
+
+// NewCPIRetryReporter continues the line of the stage step that made the CPI call
+// so that retries show up next to the step they delay
+func NewCPIRetryReporter(ui biui.UI) bicloud.RetryReporter {
+	return cpiRetryReporter{ui: ui}
+}
+
+func (r cpiRetryReporter) CPICallRetrying(method string, attempt int, attempts int, err bicloud.Error) {
+	r.ui.BeginLinef(" Retrying CPI '%s' after '%s' (attempt %d of %d)...", method, err.Type(), attempt, attempts)
+}
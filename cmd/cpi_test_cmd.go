@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"bytes"
+	"io"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconformance "github.com/cloudfoundry/bosh-cli/v7/cpi/conformance"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+type CPITestCmd struct {
+	ui     boshui.UI
+	fs     boshsys.FileSystem
+	tester CPITester
+}
+
+func NewCPITestCmd(ui boshui.UI, fs boshsys.FileSystem, tester CPITester) CPITestCmd {
+	return CPITestCmd{ui: ui, fs: fs, tester: tester}
+}
+
+func (c CPITestCmd) Run(stage boshui.Stage, opts CPITestOpts) error {
+	report, err := c.tester.Test(stage)
+	if err != nil {
+		return err
+	}
+
+	table := boshtbl.Table{
+		Content: "steps",
+
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Step"),
+			boshtbl.NewHeader("Status"),
+			boshtbl.NewHeader("Duration"),
+			boshtbl.NewHeader("Error"),
+		},
+	}
+
+	for _, step := range report.Steps {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(step.Name),
+			boshtbl.NewValueFmt(boshtbl.NewValueString(string(step.Status)), step.Status == biconformance.StepFailed),
+			boshtbl.NewValueString(step.Duration.String()),
+			boshtbl.NewValueString(step.Error),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	if len(opts.JUnitReport) > 0 {
+		err = c.writeReport(opts.JUnitReport, report.WriteJUnit)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(opts.JSONReport) > 0 {
+		err = c.writeReport(opts.JSONReport, report.WriteJSON)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !report.Passed() {
+		return bosherr.Errorf("CPI conformance tests failed: %d failed and %d skipped of %d steps",
+			report.Count(biconformance.StepFailed), report.Count(biconformance.StepSkipped), len(report.Steps))
+	}
+
+	return nil
+}
+
+func (c CPITestCmd) writeReport(path string, write func(io.Writer) error) error {
+	buffer := &bytes.Buffer{}
+
+	err := write(buffer)
+	if err != nil {
+		return err
+	}
+
+	err = c.fs.WriteFile(path, buffer.Bytes())
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing report '%s'", path)
+	}
+
+	return nil
+}
@@ -0,0 +1,105 @@
+package cmd_test
+
+import (
+	"errors"
+	"time"
+
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/cmdfakes"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconformance "github.com/cloudfoundry/bosh-cli/v7/cpi/conformance"
+	fakebiui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+var _ = Describe("CPITestCmd", func() {
+	var (
+		ui      *fakebiui.FakeUI
+		fs      *fakesys.FakeFileSystem
+		stage   *fakebiui.FakeStage
+		tester  *cmdfakes.FakeCPITester
+		command cmd.CPITestCmd
+		report  biconformance.Report
+	)
+
+	BeforeEach(func() {
+		ui = &fakebiui.FakeUI{}
+		fs = fakesys.NewFakeFileSystem()
+		stage = fakebiui.NewFakeStage()
+		tester = &cmdfakes.FakeCPITester{}
+		command = cmd.NewCPITestCmd(ui, fs, tester)
+
+		report = biconformance.Report{
+			Steps: []biconformance.StepResult{
+				{Name: "info", Status: biconformance.StepPassed, Duration: time.Second},
+				{Name: "create_stemcell", Status: biconformance.StepPassed, Duration: 2 * time.Second},
+			},
+		}
+	})
+
+	It("prints the result of every step", func() {
+		tester.TestReturns(report, nil)
+
+		err := command.Run(stage, opts.CPITestOpts{})
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(tester.TestArgsForCall(0)).To(Equal(stage))
+		Expect(ui.Table.Content).To(Equal("steps"))
+		Expect(ui.Table.Rows).To(Equal([][]boshtbl.Value{
+			{
+				boshtbl.NewValueString("info"),
+				boshtbl.NewValueFmt(boshtbl.NewValueString("passed"), false),
+				boshtbl.NewValueString("1s"),
+				boshtbl.NewValueString(""),
+			},
+			{
+				boshtbl.NewValueString("create_stemcell"),
+				boshtbl.NewValueFmt(boshtbl.NewValueString("passed"), false),
+				boshtbl.NewValueString("2s"),
+				boshtbl.NewValueString(""),
+			},
+		}))
+	})
+
+	It("writes JUnit and JSON reports", func() {
+		tester.TestReturns(report, nil)
+
+		err := command.Run(stage, opts.CPITestOpts{JUnitReport: "/report.xml", JSONReport: "/report.json"})
+		Expect(err).ToNot(HaveOccurred())
+
+		junit, err := fs.ReadFileString("/report.xml")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(junit).To(ContainSubstring(`<testsuite name="cpi-test" tests="2" failures="0" skipped="0" time="3.000">`))
+
+		json, err := fs.ReadFileString("/report.json")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json).To(ContainSubstring(`"passed": true`))
+	})
+
+	It("fails when steps fail or are skipped", func() {
+		report.Steps = append(report.Steps,
+			biconformance.StepResult{Name: "create_vm", Status: biconformance.StepFailed, Error: "fake-error"},
+			biconformance.StepResult{Name: "has_vm", Status: biconformance.StepSkipped},
+		)
+		tester.TestReturns(report, nil)
+
+		err := command.Run(stage, opts.CPITestOpts{JSONReport: "/report.json"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("CPI conformance tests failed: 1 failed and 1 skipped of 4 steps"))
+
+		Expect(ui.Table.Rows[2][1]).To(Equal(boshtbl.NewValueFmt(boshtbl.NewValueString("failed"), true)))
+		Expect(fs.FileExists("/report.json")).To(BeTrue())
+	})
+
+	It("returns an error when the suite cannot be run", func() {
+		tester.TestReturns(biconformance.Report{}, errors.New("fake-install-error"))
+
+		err := command.Run(stage, opts.CPITestOpts{})
+		Expect(err).To(MatchError("fake-install-error"))
+		Expect(ui.Tables).To(BeEmpty())
+	})
+})
@@ -0,0 +1,158 @@
+package cmd
+
+import (
+	"path/filepath"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
+	"github.com/cppforlife/go-patch/patch"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	biconformance "github.com/cloudfoundry/bosh-cli/v7/cpi/conformance"
+	boshtpl "github.com/cloudfoundry/bosh-cli/v7/director/template"
+	bistemcell "github.com/cloudfoundry/bosh-cli/v7/stemcell"
+	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+// defaultCPITestDiskSize is used when the manifest does not give the job a persistent disk
+const defaultCPITestDiskSize = 1024
+
+//counterfeiter:generate . CPITester
+
+// CPITester runs the CPI conformance suite with the CPI, stemcell, resource pool,
+// networks and disk pool of a create-env manifest
+type CPITester interface {
+	Test(stage biui.Stage) (biconformance.Report, error)
+}
+
+type cpiTester struct {
+	logTag                                  string
+	logger                                  boshlog.Logger
+	cloudProvider                           EnvCloudProvider
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser
+	deploymentManifestParser                DeploymentManifestParser
+	stemcellFetcher                         bistemcell.Fetcher
+	suite                                   biconformance.Suite
+	uuidGenerator                           boshuuid.Generator
+	deploymentManifestPath                  string
+	deploymentVars                          boshtpl.Variables
+	deploymentOp                            patch.Op
+}
+
+func NewCPITester(
+	logTag string,
+	logger boshlog.Logger,
+	cloudProvider EnvCloudProvider,
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser,
+	deploymentManifestParser DeploymentManifestParser,
+	stemcellFetcher bistemcell.Fetcher,
+	suite biconformance.Suite,
+	uuidGenerator boshuuid.Generator,
+	deploymentManifestPath string,
+	deploymentVars boshtpl.Variables,
+	deploymentOp patch.Op,
+) CPITester {
+	return &cpiTester{
+		logTag:                                  logTag,
+		logger:                                  logger,
+		cloudProvider:                           cloudProvider,
+		releaseSetAndInstallationManifestParser: releaseSetAndInstallationManifestParser,
+		deploymentManifestParser:                deploymentManifestParser,
+		stemcellFetcher:                         stemcellFetcher,
+		suite:                                   suite,
+		uuidGenerator:                           uuidGenerator,
+		deploymentManifestPath:                  deploymentManifestPath,
+		deploymentVars:                          deploymentVars,
+		deploymentOp:                            deploymentOp,
+	}
+}
+
+func (t *cpiTester) Test(stage biui.Stage) (biconformance.Report, error) {
+	releaseSetManifest, _, err := t.releaseSetAndInstallationManifestParser.ReleaseSetAndInstallationManifest(t.deploymentManifestPath, t.deploymentVars, t.deploymentOp)
+	if err != nil {
+		return biconformance.Report{}, err
+	}
+
+	deploymentManifest, err := t.deploymentManifestParser.GetDeploymentManifestWithoutReleaseJobs(t.deploymentManifestPath, t.deploymentVars, t.deploymentOp, releaseSetManifest, stage)
+	if err != nil {
+		return biconformance.Report{}, err
+	}
+
+	jobName := deploymentManifest.JobName()
+
+	resourcePool, err := deploymentManifest.ResourcePool(jobName)
+	if err != nil {
+		return biconformance.Report{}, err
+	}
+
+	networks, err := deploymentManifest.NetworkInterfaces(jobName)
+	if err != nil {
+		return biconformance.Report{}, err
+	}
+
+	diskPool, err := deploymentManifest.DiskPool(jobName)
+	if err != nil {
+		return biconformance.Report{}, err
+	}
+
+	if diskPool.DiskSize == 0 {
+		diskPool.DiskSize = defaultCPITestDiskSize
+	}
+
+	agentID, err := t.uuidGenerator.Generate()
+	if err != nil {
+		return biconformance.Report{}, bosherr.WrapError(err, "Generating agent ID")
+	}
+
+	var extractedStemcell bistemcell.ExtractedStemcell
+
+	err = stage.PerformComplex("preparing stemcell", func(stage biui.Stage) error {
+		extractedStemcell, err = t.stemcellFetcher.GetStemcell(deploymentManifest, stage)
+		return err
+	})
+	if err != nil {
+		return biconformance.Report{}, err
+	}
+
+	defer func() {
+		err := extractedStemcell.Cleanup()
+		if err != nil {
+			t.logger.Warn(t.logTag, "Failed to delete extracted stemcell: %s", err.Error())
+		}
+	}()
+
+	config := biconformance.Config{
+		AgentID: agentID,
+
+		StemcellImagePath:       filepath.Join(extractedStemcell.GetExtractedPath(), "image"),
+		StemcellCloudProperties: extractedStemcell.Manifest().CloudProperties,
+
+		VMCloudProperties: resourcePool.CloudProperties,
+		VMEnv:             resourcePool.Env,
+		Networks:          networks,
+
+		DiskSize:            diskPool.DiskSize,
+		DiskCloudProperties: diskPool.CloudProperties,
+	}
+
+	// the CPI is told the API version of the tested stemcell as create-env does
+	stemcellApiVersion := extractedStemcell.Manifest().ApiVersion
+	if stemcellApiVersion == 0 {
+		stemcellApiVersion = 1
+	}
+
+	var report biconformance.Report
+
+	err = t.cloudProvider.WithStemcellCloud(stage, stemcellApiVersion, func(cloud bicloud.Cloud) error {
+		return stage.PerformComplex("running CPI conformance tests", func(stage biui.Stage) error {
+			report = t.suite.Run(cloud, config, stage)
+			return nil
+		})
+	})
+	if err != nil {
+		return biconformance.Report{}, err
+	}
+
+	return report, nil
+}
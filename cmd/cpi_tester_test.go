@@ -0,0 +1,153 @@
+package cmd_test
+
+import (
+	"path/filepath"
+
+	"code.cloudfoundry.org/clock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	"github.com/cppforlife/go-patch/patch"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	fakebicloud "github.com/cloudfoundry/bosh-cli/v7/cloud/fakes"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/cmdfakes"
+	biconformance "github.com/cloudfoundry/bosh-cli/v7/cpi/conformance"
+	bideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest"
+	fakebideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest/manifestfakes"
+	bidepltpl "github.com/cloudfoundry/bosh-cli/v7/deployment/template"
+	fakebidepltpl "github.com/cloudfoundry/bosh-cli/v7/deployment/template/templatefakes"
+	boshtpl "github.com/cloudfoundry/bosh-cli/v7/director/template"
+	biinstall "github.com/cloudfoundry/bosh-cli/v7/installation"
+	fakebiinstallmanifest "github.com/cloudfoundry/bosh-cli/v7/installation/manifest/fakes"
+	bitarball "github.com/cloudfoundry/bosh-cli/v7/installation/tarball"
+	fakebirelsetmanifest "github.com/cloudfoundry/bosh-cli/v7/release/set/manifest/fakes"
+	bistemcell "github.com/cloudfoundry/bosh-cli/v7/stemcell"
+	fakebistemcell "github.com/cloudfoundry/bosh-cli/v7/stemcell/stemcellfakes"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("CPITester", func() {
+	var (
+		fs                    *fakesys.FakeFileSystem
+		fakeStemcellExtractor *fakebistemcell.FakeExtractor
+		fakeCPICmdRunner      *fakebicloud.FakeCPICmdRunner
+		cloudProvider         *cmdfakes.FakeEnvCloudProvider
+		stage                 *fakeui.FakeStage
+		tester                cmd.CPITester
+	)
+
+	manifestPath := "/deployment-dir/manifest.yml"
+	stemcellTarballPath := filepath.Join("/", "stemcell", "tarball", "path")
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+
+		err := fs.WriteFileString(manifestPath, "")
+		Expect(err).ToNot(HaveOccurred())
+		err = fs.WriteFileString(stemcellTarballPath, "")
+		Expect(err).ToNot(HaveOccurred())
+
+		fakeDeploymentParser := &fakebideplmanifest.FakeParser{}
+		fakeDeploymentParser.ParseReturns(bideplmanifest.Manifest{
+			Name: "fake-deployment-name",
+			Jobs: []bideplmanifest.Job{{Name: "fake-job-name"}},
+			ResourcePools: []bideplmanifest.ResourcePool{
+				{Stemcell: bideplmanifest.StemcellRef{URL: "file://" + stemcellTarballPath}},
+			},
+		}, nil)
+
+		fakeDeploymentValidator := fakebideplmanifest.NewFakeValidator()
+		fakeDeploymentValidator.SetValidateBehavior([]fakebideplmanifest.ValidateOutput{{Err: nil}})
+
+		fakeDeploymentTemplateFactory := &fakebidepltpl.FakeDeploymentTemplateFactory{}
+		fakeDeploymentTemplateFactory.NewDeploymentTemplateFromPathReturns(bidepltpl.NewDeploymentTemplate([]byte("")), nil)
+
+		fakeStemcellExtractor = fakebistemcell.NewFakeExtractor()
+
+		tarballProvider := bitarball.NewProvider(bitarball.NewCache("fake-base-path", fs, logger), fs, nil, 1, 0, nil, logger)
+
+		fakeCPICmdRunner = fakebicloud.NewFakeCPICmdRunner()
+
+		cloudProvider = &cmdfakes.FakeEnvCloudProvider{}
+		cloudProvider.WithStemcellCloudStub = func(_ boshui.Stage, stemcellApiVersion int, fn func(bicloud.Cloud) error) error {
+			return fn(bicloud.NewCloud(fakeCPICmdRunner, "fake-director-id", stemcellApiVersion, logger))
+		}
+
+		stage = fakeui.NewFakeStage()
+
+		tester = cmd.NewCPITester(
+			"CPITester",
+			logger,
+			cloudProvider,
+			cmd.ReleaseSetAndInstallationManifestParser{
+				ReleaseSetParser:   fakebirelsetmanifest.NewFakeParser(),
+				InstallationParser: fakebiinstallmanifest.NewFakeParser(),
+			},
+			cmd.NewDeploymentManifestParser(
+				fakeDeploymentParser,
+				fakeDeploymentValidator,
+				biinstall.NewReleaseManager(logger),
+				fakeDeploymentTemplateFactory,
+			),
+			bistemcell.Fetcher{
+				TarballProvider:   tarballProvider,
+				StemcellExtractor: fakeStemcellExtractor,
+			},
+			biconformance.NewSuite(clock.NewClock(), logger),
+			&fakeuuid.FakeGenerator{GeneratedUUID: "fake-agent-id"},
+			manifestPath,
+			boshtpl.StaticVariables{},
+			patch.Ops{},
+		)
+	})
+
+	extractStemcell := func(stemcellApiVersion int) {
+		extractedStemcell := bistemcell.NewExtractedStemcell(
+			bistemcell.Manifest{Name: "fake-stemcell-name", Version: "fake-stemcell-version", ApiVersion: stemcellApiVersion},
+			"fake-extracted-path",
+			nil,
+			fs,
+		)
+		fakeStemcellExtractor.SetExtractBehavior(stemcellTarballPath, extractedStemcell, nil)
+	}
+
+	It("tells the CPI the API version of the tested stemcell", func() {
+		extractStemcell(2)
+
+		_, err := tester.Test(stage)
+		Expect(err).ToNot(HaveOccurred())
+
+		_, stemcellApiVersion, _ := cloudProvider.WithStemcellCloudArgsForCall(0)
+		Expect(stemcellApiVersion).To(Equal(2))
+
+		Expect(fakeCPICmdRunner.CurrentRunInput).ToNot(BeEmpty())
+		for _, runInput := range fakeCPICmdRunner.CurrentRunInput {
+			Expect(runInput.Context.Vm.Stemcell.ApiVersion).To(Equal(2))
+		}
+	})
+
+	It("tells the CPI API version 1 for stemcells without an API version", func() {
+		extractStemcell(0)
+
+		_, err := tester.Test(stage)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(fakeCPICmdRunner.CurrentRunInput).ToNot(BeEmpty())
+		Expect(fakeCPICmdRunner.CurrentRunInput[0].Context.Vm.Stemcell.ApiVersion).To(Equal(1))
+	})
+
+	It("does not use the API version of the current stemcell of the environment", func() {
+		extractStemcell(2)
+
+		_, err := tester.Test(stage)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cloudProvider.WithCloudCallCount()).To(Equal(0))
+	})
+})
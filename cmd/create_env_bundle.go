@@ -0,0 +1,28 @@
+package cmd
+
+import (
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+type CreateEnvBundleCmd struct {
+	ui      boshui.UI
+	bundler EnvBundler
+}
+
+func NewCreateEnvBundleCmd(ui boshui.UI, bundler EnvBundler) CreateEnvBundleCmd {
+	return CreateEnvBundleCmd{ui: ui, bundler: bundler}
+}
+
+func (c CreateEnvBundleCmd) Run(stage boshui.Stage, opts CreateEnvBundleOpts) error {
+	c.ui.BeginLinef("Deployment manifest: '%s'\n", opts.Args.Manifest.Path)
+
+	err := c.bundler.Bundle(opts.Output.ExpandedPath, stage)
+	if err != nil {
+		return err
+	}
+
+	c.ui.PrintLinef("Created bundle '%s'", opts.Output.ExpandedPath)
+
+	return nil
+}
@@ -0,0 +1,58 @@
+package cmd_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/cmdfakes"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	fakebiui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("CreateEnvBundleCmd", func() {
+	var (
+		ui         *fakebiui.FakeUI
+		stage      *fakebiui.FakeStage
+		bundler    *cmdfakes.FakeEnvBundler
+		command    cmd.CreateEnvBundleCmd
+		bundleOpts opts.CreateEnvBundleOpts
+	)
+
+	BeforeEach(func() {
+		ui = &fakebiui.FakeUI{}
+		stage = fakebiui.NewFakeStage()
+		bundler = &cmdfakes.FakeEnvBundler{}
+		command = cmd.NewCreateEnvBundleCmd(ui, bundler)
+
+		bundleOpts = opts.CreateEnvBundleOpts{
+			Args: opts.CreateEnvBundleArgs{
+				Manifest: opts.FileBytesWithPathArg{Path: "/manifest.yml"},
+			},
+			Output: opts.FileArg{ExpandedPath: "/bundle.tgz"},
+		}
+	})
+
+	It("writes the bundle to the output path", func() {
+		err := command.Run(stage, bundleOpts)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(bundler.BundleCallCount()).To(Equal(1))
+
+		path, bundleStage := bundler.BundleArgsForCall(0)
+		Expect(path).To(Equal("/bundle.tgz"))
+		Expect(bundleStage).To(Equal(stage))
+
+		Expect(ui.Said).To(ContainElement("Created bundle '/bundle.tgz'"))
+	})
+
+	It("returns an error if bundling fails", func() {
+		bundler.BundleReturns(errors.New("fake-err"))
+
+		err := command.Run(stage, bundleOpts)
+		Expect(err).To(MatchError("fake-err"))
+		Expect(ui.Said).ToNot(ContainElement("Created bundle '/bundle.tgz'"))
+	})
+})
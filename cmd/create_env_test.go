@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"regexp"
 
+	"github.com/cloudfoundry/bosh-agent/agentclient"
 	mockhttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http/mocks"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
@@ -28,6 +29,10 @@ import (
 	mockconfig "github.com/cloudfoundry/bosh-cli/v7/config/mocks"
 	bicpirel "github.com/cloudfoundry/bosh-cli/v7/cpi/release"
 	"github.com/cloudfoundry/bosh-cli/v7/deployment"
+	bidisk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk"
+	"github.com/cloudfoundry/bosh-cli/v7/deployment/dryrun"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
+	fakebihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook/fakes"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest"
 	fakebideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest/manifestfakes"
 	fakebideplval "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest/manifestfakes"
@@ -102,6 +107,9 @@ var _ = Describe("CreateEnvCmd", func() {
 			mockBlobstoreFactory *mockblobstore.MockFactory
 			mockBlobstore        *mockblobstore.MockBlobstore
 
+			fakeHookRunner *fakebihook.FakeRunner
+			agentState     agentclient.AgentState
+
 			mockVMManagerFactory       *mockvm.MockManagerFactory
 			fakeVMManager              *fakebivm.FakeManager
 			fakeStemcellExtractor      *fakebistemcell.FakeExtractor
@@ -114,6 +122,7 @@ var _ = Describe("CreateEnvCmd", func() {
 			fakeDeploymentTemplateFactory     *fakebidepltpl.FakeDeploymentTemplateFactory
 			mockLegacyDeploymentStateMigrator *mockconfig.MockLegacyDeploymentStateMigrator
 			setupDeploymentStateService       biconfig.DeploymentStateService
+			dryRunRecorder                    *dryrun.Recorder
 			fakeDeploymentValidator           *fakebideplval.FakeValidator
 
 			fakeUUIDGenerator   *fakeuuid.FakeGenerator
@@ -180,12 +189,19 @@ var _ = Describe("CreateEnvCmd", func() {
 			mockAgentClient = mockagentclient.NewMockAgentClient(mockCtrl)
 			mockAgentClientFactory.EXPECT().NewAgentClient(gomock.Any(), gomock.Any(), gomock.Any()).Return(mockAgentClient, nil).AnyTimes()
 
+			agentState = agentclient.AgentState{}
+			mockAgentClient.EXPECT().GetState().DoAndReturn(func() (agentclient.AgentState, error) {
+				return agentState, nil
+			}).AnyTimes()
+
 			mockCloudFactory = mockcloud.NewMockFactory(mockCtrl)
 
 			mockBlobstoreFactory = mockblobstore.NewMockFactory(mockCtrl)
 			mockBlobstore = mockblobstore.NewMockBlobstore(mockCtrl)
 			mockBlobstoreFactory.EXPECT().Create(mbusURL, gomock.Any()).Return(mockBlobstore, nil).AnyTimes()
 
+			fakeHookRunner = fakebihook.NewFakeRunner()
+
 			mockVMManagerFactory = mockvm.NewMockManagerFactory(mockCtrl)
 			fakeVMManager = fakebivm.NewFakeManager()
 			mockVMManagerFactory.EXPECT().NewManager(gomock.Any(), mockAgentClient).Return(fakeVMManager).AnyTimes()
@@ -203,6 +219,7 @@ var _ = Describe("CreateEnvCmd", func() {
 
 			configUUIDGenerator = &fakeuuid.FakeGenerator{}
 			configUUIDGenerator.GeneratedUUID = directorID
+			dryRunRecorder = nil
 			setupDeploymentStateService = biconfig.NewFileSystemDeploymentStateService(fs, configUUIDGenerator, logger, biconfig.DeploymentStatePath(deploymentManifestPath, ""))
 
 			fakeDeploymentValidator = fakebideplval.NewFakeValidator()
@@ -311,13 +328,17 @@ var _ = Describe("CreateEnvCmd", func() {
 		JustBeforeEach(func() {
 			doGet := func(deploymentManifestPath string, statePath string, deploymentVars boshtpl.Variables, deploymentOp patch.Op) cmd.DeploymentPreparer {
 				deploymentStateService := biconfig.NewFileSystemDeploymentStateService(fs, configUUIDGenerator, logger, biconfig.DeploymentStatePath(deploymentManifestPath, statePath))
+				if dryRunRecorder != nil {
+					deploymentStateService = biconfig.NewDryRunDeploymentStateService(deploymentStateService, configUUIDGenerator)
+				}
 				deploymentRepo := biconfig.NewDeploymentRepo(deploymentStateService)
 				releaseRepo := biconfig.NewReleaseRepo(deploymentStateService, fakeUUIDGenerator)
 				stemcellRepo := biconfig.NewStemcellRepo(deploymentStateService, fakeUUIDGenerator)
 				deploymentRecord := deployment.NewRecord(deploymentRepo, releaseRepo, stemcellRepo)
+				diskManagerFactory := bidisk.NewManagerFactory(biconfig.NewDiskRepo(deploymentStateService, fakeUUIDGenerator), logger)
 
 				tarballCache := bitarball.NewCache("fake-base-path", fs, logger)
-				tarballProvider := bitarball.NewProvider(tarballCache, fs, nil, 1, 0, logger)
+				tarballProvider := bitarball.NewProvider(tarballCache, fs, nil, 1, 0, nil, logger)
 
 				cpiInstaller := bicpirel.CpiInstaller{
 					ReleaseManager:   releaseManager,
@@ -347,6 +368,7 @@ var _ = Describe("CreateEnvCmd", func() {
 					deploymentStateService,
 					fakeInstallationUUIDGenerator,
 					filepath.Join("fake-install-dir"),
+					fs,
 				)
 				tempRootConfigurator := cmd.NewTempRootConfigurator(fs)
 
@@ -362,18 +384,24 @@ var _ = Describe("CreateEnvCmd", func() {
 					fakeStemcellManagerFactory,
 					mockAgentClientFactory,
 					mockVMManagerFactory,
+					diskManagerFactory,
+					biconfig.NewKeptDisksRepo(fs, biconfig.KeptDisksPath(deploymentManifestPath, statePath)),
+					biconfig.NewManifestRepo(deploymentStateService),
+					fakeHookRunner,
 					mockBlobstoreFactory,
 					mockDeployer,
 					deploymentManifestPath,
 					deploymentVars,
 					deploymentOp,
 					cpiInstaller,
+					tarballProvider,
 					releaseFetcher,
 					stemcellFetcher,
 					releaseSetAndInstallationManifestParser,
 					deploymentManifestParser,
 					tempRootConfigurator,
 					targetProvider,
+					dryRunRecorder,
 				)
 			}
 
@@ -648,6 +676,52 @@ var _ = Describe("CreateEnvCmd", func() {
 			}))
 		})
 
+		It("stores the deployed manifest with redacted variable values", func() {
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			deploymentState, err := setupDeploymentStateService.Load()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(deploymentState.CurrentManifest).To(Equal(&biconfig.ManifestRecord{Redacted: "test: true\n"}))
+		})
+
+		It("also stores the deployed manifest encrypted when a manifest key is given", func() {
+			defaultCreateEnvOpts.ManifestKey = "fake-manifest-key"
+
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			deploymentState, err := setupDeploymentStateService.Load()
+			Expect(err).ToNot(HaveOccurred())
+
+			manifest, err := deploymentState.CurrentManifest.Decrypt("fake-manifest-key")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(manifest)).To(Equal("test: true\n"))
+		})
+
+		It("runs post-deploy hooks with the IPs reported by the agent", func() {
+			agentState = agentclient.AgentState{
+				NetworkSpecs: map[string]agentclient.NetworkSpec{"network-1": {IP: "10.0.0.5"}},
+			}
+
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeHookRunner.RunInputs).To(HaveLen(1))
+			Expect(fakeHookRunner.RunInputs[0].Point).To(Equal(bihook.PostDeploy))
+			Expect(fakeHookRunner.RunInputs[0].Context.IPs).To(Equal([]string{"10.0.0.5"}))
+			Expect(fakeHookRunner.RunInputs[0].Context.StemcellCID).To(Equal("fake-stemcell-cid"))
+		})
+
+		It("returns an error when a post-deploy hook fails", func() {
+			fakeHookRunner.RunErrs[bihook.PostDeploy] = errors.New("fake-hook-error")
+
+			err := command.Run(fakeStage, defaultCreateEnvOpts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-hook-error"))
+		})
+
 		It("deletes unused stemcells", func() {
 			expectStemcellDeleteUnused.Times(1)
 
@@ -701,6 +775,17 @@ var _ = Describe("CreateEnvCmd", func() {
 				Expect(stdOut).To(gbytes.Say("No deployment, stemcell or release changes. Skipping deploy."))
 			})
 
+			It("stores the deployed manifest when it was not stored yet", func() {
+				expectDeploy.Times(0)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+
+				deploymentState, err := setupDeploymentStateService.Load()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deploymentState.CurrentManifest).To(Equal(&biconfig.ManifestRecord{Redacted: "test: true\n"}))
+			})
+
 			It("deploys if `recreate` flag is specified", func() {
 				expectDeploy.Times(1)
 
@@ -718,6 +803,165 @@ var _ = Describe("CreateEnvCmd", func() {
 				err := command.Run(fakeStage, defaultCreateEnvOpts)
 				Expect(err).NotTo(HaveOccurred())
 			})
+
+			It("returns an error if `recreate-persistent-disks` flag is specified with multiple persistent disks", func() {
+				expectDeploy.Times(0)
+
+				boshDeploymentManifest.Jobs[0].PersistentDisks = []bideplmanifest.PersistentDisk{
+					{Name: "data", DiskPool: "fake-disk-pool"},
+					{Name: "logs", DiskPool: "fake-disk-pool"},
+				}
+				boshDeploymentManifest.DiskPools = []bideplmanifest.DiskPool{
+					{Name: "fake-disk-pool", DiskSize: 1024, CloudProperties: biproperty.Map{}},
+				}
+				fakeDeploymentParser.ParseReturns(boshDeploymentManifest, nil)
+
+				defaultCreateEnvOpts.RecreatePersistentDisks = true
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(MatchError("Recreating persistent disks is only supported with a single persistent disk"))
+			})
+		})
+
+		Context("when AdoptDisk is specified", func() {
+			BeforeEach(func() {
+				boshDeploymentManifest.Jobs[0].PersistentDisk = 1024
+				fakeDeploymentParser.ParseReturns(boshDeploymentManifest, nil)
+
+				defaultCreateEnvOpts.AdoptDisks = []string{"fake-disk-cid"}
+			})
+
+			It("makes the disk the current disk before deploying", func() {
+				expectDeploy.Times(1)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeStage.PerformCalls[2].Name).To(Equal("Adopting disk 'fake-disk-cid'"))
+
+				deploymentState, err := setupDeploymentStateService.Load()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deploymentState.Disks).To(HaveLen(1))
+				Expect(deploymentState.Disks[0].CID).To(Equal("fake-disk-cid"))
+				Expect(deploymentState.Disks[0].Size).To(Equal(1024))
+				Expect(deploymentState.CurrentDiskID).To(Equal(deploymentState.Disks[0].ID))
+			})
+
+			It("uses the size of a disk kept by delete-env and forgets it", func() {
+				keptDisksPath := deploymentStatePath + ".kept-disks.json"
+				err := fs.WriteFileString(keptDisksPath, `{"disks":[{"cid":"fake-disk-cid","size":2048,"cloud_properties":{}}]}`)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).NotTo(HaveOccurred())
+
+				deploymentState, err := setupDeploymentStateService.Load()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deploymentState.Disks[0].Size).To(Equal(2048))
+				Expect(fs.FileExists(keptDisksPath)).To(BeFalse())
+			})
+
+			It("returns an error when the manifest does not specify a persistent disk", func() {
+				boshDeploymentManifest.Jobs[0].PersistentDisk = 0
+				fakeDeploymentParser.ParseReturns(boshDeploymentManifest, nil)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(MatchError("Cannot adopt disk 'fake-disk-cid': the deployment manifest does not specify a persistent disk"))
+			})
+
+			Context("when the manifest specifies multiple persistent disks", func() {
+				BeforeEach(func() {
+					boshDeploymentManifest.Jobs[0].PersistentDisk = 0
+					boshDeploymentManifest.Jobs[0].PersistentDisks = []bideplmanifest.PersistentDisk{
+						{Name: "data", DiskPool: "fake-disk-pool"},
+						{Name: "logs", DiskPool: "fake-disk-pool"},
+					}
+					boshDeploymentManifest.DiskPools = []bideplmanifest.DiskPool{
+						{Name: "fake-disk-pool", DiskSize: 1024, CloudProperties: biproperty.Map{}},
+					}
+					fakeDeploymentParser.ParseReturns(boshDeploymentManifest, nil)
+				})
+
+				It("adopts kept disks as the persistent disk with their name", func() {
+					keptDisksPath := deploymentStatePath + ".kept-disks.json"
+					err := fs.WriteFileString(keptDisksPath, `{"disks":[{"name":"logs","cid":"fake-disk-cid","size":2048,"cloud_properties":{}}]}`)
+					Expect(err).ToNot(HaveOccurred())
+
+					err = command.Run(fakeStage, defaultCreateEnvOpts)
+					Expect(err).NotTo(HaveOccurred())
+
+					deploymentState, err := setupDeploymentStateService.Load()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(deploymentState.Disks).To(HaveLen(1))
+					Expect(deploymentState.Disks[0].Name).To(Equal("logs"))
+					Expect(deploymentState.CurrentDiskIDs).To(Equal([]string{deploymentState.Disks[0].ID}))
+				})
+
+				It("returns an error for disks that were not kept", func() {
+					err := command.Run(fakeStage, defaultCreateEnvOpts)
+					Expect(err).To(MatchError("Cannot adopt disk 'fake-disk-cid': it was not kept by delete-env and the deployment manifest specifies multiple persistent disks"))
+				})
+			})
+		})
+
+		Context("when DryRun is specified", func() {
+			BeforeEach(func() {
+				defaultCreateEnvOpts.DryRun = true
+			})
+
+			It("returns an error without a recorder for the skipped calls", func() {
+				expectDeploy.Times(0)
+
+				err := command.Run(fakeStage, defaultCreateEnvOpts)
+				Expect(err).To(MatchError("Dry runs need a recording cloud, agent and blobstore"))
+			})
+
+			Context("with a recorder", func() {
+				BeforeEach(func() {
+					dryRunRecorder = dryrun.NewRecorder()
+				})
+
+				JustBeforeEach(func() {
+					expectDeploy.Times(0)
+					mockDeployer.EXPECT().Deploy(
+						mockCloud,
+						gomock.Any(),
+						cloudStemcell,
+						fakeVMManager,
+						mockBlobstore,
+						expectedSkipDrain,
+						gomock.Any(),
+					).Do(func(_, deploymentManifest interface{}, _, _, _, _, _ interface{}) {
+						Expect(deploymentManifest.(bideplmanifest.Manifest).Update.UpdateWatchTime.Start).To(Equal(0))
+
+						dryRunRecorder.Record(dryrun.ComponentCPI, "create_vm", "dry-run-vm-1")
+						dryRunRecorder.Record(dryrun.ComponentAgent, "apply", "fake-deployment-job-name")
+					}).Return(nil, nil)
+				})
+
+				It("runs the deploy flow and prints the plan of the recorded calls", func() {
+					err := command.Run(fakeStage, defaultCreateEnvOpts)
+					Expect(err).NotTo(HaveOccurred())
+					Expect(stdOut).To(gbytes.Say(`vm\s+-\s+create`))
+					Expect(stdOut).To(gbytes.Say(`release\s+fake-cpi-release-name/1.0\s+install`))
+					Expect(stdOut).To(gbytes.Say(`cpi\s+create_vm\s+dry-run-vm-1`))
+					Expect(stdOut).To(gbytes.Say("Dry run: no changes were made to the environment."))
+				})
+
+				It("does not write the deployment state", func() {
+					err := command.Run(fakeStage, defaultCreateEnvOpts)
+					Expect(err).NotTo(HaveOccurred())
+
+					Expect(fs.FileExists(biconfig.DeploymentStatePath(deploymentManifestPath, ""))).To(BeFalse())
+				})
+
+				It("does not migrate the legacy deployments file", func() {
+					expectLegacyMigrate.Times(0)
+
+					err := command.Run(fakeStage, defaultCreateEnvOpts)
+					Expect(err).NotTo(HaveOccurred())
+				})
+			})
 		})
 
 		Context("when parsing the cpi deployment manifest fails", func() {
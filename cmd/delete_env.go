@@ -23,5 +23,5 @@ func (c *DeleteEnvCmd) Run(stage boshui.Stage, opts DeleteEnvOpts) error {
 	depDeleter := c.envProvider(
 		opts.Args.Manifest.Path, opts.StatePath, opts.VarFlags.AsVariables(), opts.OpsFlags.AsOp())
 
-	return depDeleter.DeleteDeployment(opts.SkipDrain, stage)
+	return depDeleter.DeleteDeployment(opts.SkipDrain, opts.KeepPersistentDisk, stage)
 }
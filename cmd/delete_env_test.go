@@ -67,7 +67,7 @@ var _ = Describe("DeleteEnvCmd", func() {
 		Context("when skip drain is specified", func() {
 			It("gets passed to DeleteDeployment", func() {
 				skipDrain = true
-				mockDeploymentDeleter.EXPECT().DeleteDeployment(skipDrain, fakeStage).Return(nil)
+				mockDeploymentDeleter.EXPECT().DeleteDeployment(skipDrain, false, fakeStage).Return(nil)
 				err := newDeleteEnvCmd().Run(fakeStage, opts.DeleteEnvOpts{
 					Args: opts.DeleteEnvArgs{
 						Manifest: opts.FileBytesWithPathArg{Path: deploymentManifestPath},
@@ -86,9 +86,30 @@ var _ = Describe("DeleteEnvCmd", func() {
 			})
 		})
 
+		Context("when keep persistent disk is specified", func() {
+			It("gets passed to DeleteDeployment", func() {
+				mockDeploymentDeleter.EXPECT().DeleteDeployment(skipDrain, true, fakeStage).Return(nil)
+				err := newDeleteEnvCmd().Run(fakeStage, opts.DeleteEnvOpts{
+					Args: opts.DeleteEnvArgs{
+						Manifest: opts.FileBytesWithPathArg{Path: deploymentManifestPath},
+					},
+					KeepPersistentDisk: true,
+					VarFlags: opts.VarFlags{
+						VarKVs: []boshtpl.VarKV{{Name: "key", Value: "value"}},
+					},
+					OpsFlags: opts.OpsFlags{
+						OpsFiles: []opts.OpsFileArg{
+							{Ops: []patch.Op{patch.ErrOp{}}},
+						},
+					},
+				})
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
 		Context("state path is NOT specified", func() {
 			It("sends the manifest on to the deleter", func() {
-				mockDeploymentDeleter.EXPECT().DeleteDeployment(skipDrain, fakeStage).Return(nil)
+				mockDeploymentDeleter.EXPECT().DeleteDeployment(skipDrain, false, fakeStage).Return(nil)
 				err := newDeleteEnvCmd().Run(fakeStage, opts.DeleteEnvOpts{
 					Args: opts.DeleteEnvArgs{
 						Manifest: opts.FileBytesWithPathArg{Path: deploymentManifestPath},
@@ -111,7 +132,7 @@ var _ = Describe("DeleteEnvCmd", func() {
 
 		Context("state path is specified", func() {
 			It("sends the manifest on to the deleter", func() {
-				mockDeploymentDeleter.EXPECT().DeleteDeployment(skipDrain, fakeStage).Return(nil)
+				mockDeploymentDeleter.EXPECT().DeleteDeployment(skipDrain, false, fakeStage).Return(nil)
 				err := newDeleteEnvCmd().Run(fakeStage, opts.DeleteEnvOpts{
 					StatePath: "/new/state/file/path/state.json",
 					SkipDrain: skipDrain,
@@ -136,7 +157,7 @@ var _ = Describe("DeleteEnvCmd", func() {
 		Context("when the deployment deleter returns an error", func() {
 			It("sends the manifest on to the deleter", func() {
 				err := bosherr.Error("boom")
-				mockDeploymentDeleter.EXPECT().DeleteDeployment(skipDrain, fakeStage).Return(err)
+				mockDeploymentDeleter.EXPECT().DeleteDeployment(skipDrain, false, fakeStage).Return(err)
 				returnedErr := newDeleteEnvCmd().Run(fakeStage, opts.DeleteEnvOpts{
 					Args: opts.DeleteEnvArgs{
 						Manifest: opts.FileBytesWithPathArg{Path: deploymentManifestPath},
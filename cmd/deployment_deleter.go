@@ -21,7 +21,7 @@ import (
 )
 
 type DeploymentDeleter interface {
-	DeleteDeployment(skipDrain bool, stage biui.Stage) (err error)
+	DeleteDeployment(skipDrain bool, keepPersistentDisk bool, stage biui.Stage) (err error)
 }
 
 func NewDeploymentDeleter(
@@ -43,6 +43,7 @@ func NewDeploymentDeleter(
 	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser,
 	tempRootConfigurator TempRootConfigurator,
 	targetProvider biinstall.TargetProvider,
+	keptDisksRepo biconfig.KeptDisksRepo,
 ) DeploymentDeleter {
 	return &deploymentDeleter{
 		ui:                                      ui,
@@ -63,6 +64,7 @@ func NewDeploymentDeleter(
 		releaseSetAndInstallationManifestParser: releaseSetAndInstallationManifestParser,
 		tempRootConfigurator:                    tempRootConfigurator,
 		targetProvider:                          targetProvider,
+		keptDisksRepo:                           keptDisksRepo,
 	}
 }
 
@@ -85,11 +87,23 @@ type deploymentDeleter struct {
 	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser
 	tempRootConfigurator                    TempRootConfigurator
 	targetProvider                          biinstall.TargetProvider
+	keptDisksRepo                           biconfig.KeptDisksRepo
 }
 
-func (c *deploymentDeleter) DeleteDeployment(skipDrain bool, stage biui.Stage) (err error) {
+func (c *deploymentDeleter) DeleteDeployment(skipDrain bool, keepPersistentDisk bool, stage biui.Stage) (err error) {
 	c.ui.BeginLinef("Deployment state: '%s'\n", c.deploymentStateService.Path())
 
+	err = c.deploymentStateService.Lock()
+	if err != nil {
+		return bosherr.WrapError(err, "Locking deployment state")
+	}
+	defer func() {
+		unlockErr := c.deploymentStateService.Unlock()
+		if unlockErr != nil {
+			c.logger.Warn(c.logTag, "Unlocking deployment state: %s", unlockErr.Error())
+		}
+	}()
+
 	if !c.deploymentStateService.Exists() {
 		c.ui.BeginLinef("No deployment state file found.\n")
 		return nil
@@ -146,12 +160,19 @@ func (c *deploymentDeleter) DeleteDeployment(skipDrain bool, stage biui.Stage) (
 	}
 
 	err = c.cpiInstaller.WithInstalledCpiRelease(installationManifest, target, stage, func(localCpiInstallation biinstall.Installation) error {
-		err = c.findAndDeleteDeployment(skipDrain, stage, localCpiInstallation, deploymentState.DirectorID, installationManifest.Mbus, installationManifest.Cert.CA)
+		err = c.findAndDeleteDeployment(skipDrain, keepPersistentDisk, stage, localCpiInstallation, deploymentState.DirectorID, installationManifest.Mbus, installationManifest.Cert.CA)
 
 		if err != nil {
 			return err
 		}
 
+		if keepPersistentDisk {
+			err = c.recordKeptDisk(deploymentState)
+			if err != nil {
+				return err
+			}
+		}
+
 		return stage.Perform("Uninstalling local artifacts for CPI and deployment", func() error {
 			err := c.cpiUninstaller.Uninstall(localCpiInstallation.Target())
 			if err != nil {
@@ -165,13 +186,13 @@ func (c *deploymentDeleter) DeleteDeployment(skipDrain bool, stage biui.Stage) (
 	return err
 }
 
-func (c *deploymentDeleter) findAndDeleteDeployment(skipDrain bool, stage biui.Stage, installation biinstall.Installation, directorID, installationMbus, caCert string) error {
+func (c *deploymentDeleter) findAndDeleteDeployment(skipDrain bool, keepPersistentDisk bool, stage biui.Stage, installation biinstall.Installation, directorID, installationMbus, caCert string) error {
 	deploymentManager, err := c.deploymentManager(installation, directorID, installationMbus, caCert)
 	if err != nil {
 		return err
 	}
 
-	err = c.findCurrentDeploymentAndDelete(skipDrain, stage, deploymentManager)
+	err = c.findCurrentDeploymentAndDelete(skipDrain, keepPersistentDisk, stage, deploymentManager)
 	if err != nil {
 		return bosherr.WrapError(err, "Deleting deployment")
 	}
@@ -179,7 +200,7 @@ func (c *deploymentDeleter) findAndDeleteDeployment(skipDrain bool, stage biui.S
 	return deploymentManager.Cleanup(stage)
 }
 
-func (c *deploymentDeleter) findCurrentDeploymentAndDelete(skipDrain bool, stage biui.Stage, deploymentManager bidepl.Manager) error {
+func (c *deploymentDeleter) findCurrentDeploymentAndDelete(skipDrain bool, keepPersistentDisk bool, stage biui.Stage, deploymentManager bidepl.Manager) error {
 	c.logger.Debug(c.logTag, "Finding current deployment...")
 
 	deployment, found, err := deploymentManager.FindCurrent()
@@ -194,10 +215,43 @@ func (c *deploymentDeleter) findCurrentDeploymentAndDelete(skipDrain bool, stage
 			return nil
 		}
 
+		if keepPersistentDisk {
+			return deployment.DeleteKeepingDisks(skipDrain, deleteStage)
+		}
+
 		return deployment.Delete(skipDrain, deleteStage)
 	})
 }
 
+// recordKeptDisk remembers the current disks since the deployment state is removed afterwards
+func (c *deploymentDeleter) recordKeptDisk(deploymentState biconfig.DeploymentState) error {
+	currentDiskIDs := map[string]bool{}
+	for _, currentDiskID := range deploymentState.CurrentDiskIDs {
+		currentDiskIDs[currentDiskID] = true
+	}
+
+	for _, diskRecord := range deploymentState.Disks {
+		if !currentDiskIDs[diskRecord.ID] {
+			continue
+		}
+
+		err := c.keptDisksRepo.Add(biconfig.KeptDisk{
+			Name:            diskRecord.Name,
+			CID:             diskRecord.CID,
+			Size:            diskRecord.Size,
+			CloudProperties: diskRecord.CloudProperties,
+		})
+		if err != nil {
+			return bosherr.WrapError(err, "Recording kept disk")
+		}
+
+		c.ui.BeginLinef("Kept persistent disk '%s' (recorded in '%s'). Attach it with 'create-env --adopt-disk %s'.\n",
+			diskRecord.CID, c.keptDisksRepo.Path(), diskRecord.CID)
+	}
+
+	return nil
+}
+
 func (c *deploymentDeleter) deploymentManager(installation biinstall.Installation, directorID, installationMbus, caCert string) (bidepl.Manager, error) {
 	c.logger.Debug(c.logTag, "Creating cloud client...")
 
@@ -210,7 +210,7 @@ cloud_provider:
 			installationValidator := biinstallmanifest.NewValidator(logger)
 			installationParser := biinstallmanifest.NewParser(fs, fakeUUIDGenerator, logger, installationValidator)
 			tarballCache := bitarball.NewCache("fake-base-path", fs, logger)
-			tarballProvider := bitarball.NewProvider(tarballCache, fs, nil, 1, 0, logger)
+			tarballProvider := bitarball.NewProvider(tarballCache, fs, nil, 1, 0, nil, logger)
 			deploymentStateService := biconfig.NewFileSystemDeploymentStateService(fs, fakeUUIDGenerator, logger, biconfig.DeploymentStatePath(deploymentManifestPath, ""))
 
 			cpiInstaller := bicpirel.CpiInstaller{
@@ -229,6 +229,7 @@ cloud_provider:
 				deploymentStateService,
 				fakeInstallationUUIDGenerator,
 				filepath.Join("fake-install-dir"),
+				fs,
 			)
 
 			tempRootConfigurator := cmd.NewTempRootConfigurator(fs)
@@ -252,6 +253,7 @@ cloud_provider:
 				releaseSetAndInstallationManifestParser,
 				tempRootConfigurator,
 				targetProvider,
+				biconfig.NewKeptDisksRepo(fs, biconfig.KeptDisksPath(deploymentManifestPath, "")),
 			)
 		}
 
@@ -383,7 +385,7 @@ cloud_provider:
 				})
 
 				It("does not delete anything", func() {
-					err := newDeploymentDeleter().DeleteDeployment(skipDrain, fakeStage)
+					err := newDeploymentDeleter().DeleteDeployment(skipDrain, false, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 
 					Expect(fakeUI.Said).To(Equal([]string{
@@ -421,7 +423,7 @@ cloud_provider:
 
 					It("sets stemcell version for cloud", func() {
 						expectDeleteAndCleanup(true, true)
-						err := newDeploymentDeleter().DeleteDeployment(true, fakeStage)
+						err := newDeploymentDeleter().DeleteDeployment(true, false, fakeStage)
 						Expect(err).ToNot(HaveOccurred())
 					})
 				})
@@ -429,7 +431,7 @@ cloud_provider:
 				Context("when change temp root fails", func() {
 					It("returns an error", func() {
 						fs.ChangeTempRootErr = errors.New("fake ChangeTempRootErr")
-						err := newDeploymentDeleter().DeleteDeployment(skipDrain, fakeStage)
+						err := newDeploymentDeleter().DeleteDeployment(skipDrain, false, fakeStage)
 						Expect(err).To(HaveOccurred())
 						Expect(err.Error()).To(Equal("Setting temp root: fake ChangeTempRootErr"))
 					})
@@ -437,7 +439,7 @@ cloud_provider:
 
 				It("sets the temp root", func() {
 					expectDeleteAndCleanup(skipDrain, true)
-					err := newDeploymentDeleter().DeleteDeployment(skipDrain, fakeStage)
+					err := newDeploymentDeleter().DeleteDeployment(skipDrain, false, fakeStage)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(fs.TempRootPath).To(Equal(filepath.Join("fake-install-dir", "fake-installation-id", "tmp")))
 				})
@@ -450,14 +452,14 @@ cloud_provider:
 						expectNewCloud.Times(1),
 					)
 
-					err := newDeploymentDeleter().DeleteDeployment(skipDrain, fakeStage)
+					err := newDeploymentDeleter().DeleteDeployment(skipDrain, false, fakeStage)
 					Expect(err).NotTo(HaveOccurred())
 				})
 
 				It("deletes the extracted CPI release", func() {
 					expectDeleteAndCleanup(skipDrain, true)
 
-					err := newDeploymentDeleter().DeleteDeployment(skipDrain, fakeStage)
+					err := newDeploymentDeleter().DeleteDeployment(skipDrain, false, fakeStage)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(fs.FileExists("fake-cpi-extracted-dir")).To(BeFalse())
 				})
@@ -465,23 +467,58 @@ cloud_provider:
 				It("deletes the deployment & cleans up orphans", func() {
 					expectDeleteAndCleanup(skipDrain, true)
 
-					err := newDeploymentDeleter().DeleteDeployment(skipDrain, fakeStage)
+					err := newDeploymentDeleter().DeleteDeployment(skipDrain, false, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(fakeUI.Errors).To(BeEmpty())
 				})
 
+				Context("when keeping the persistent disk", func() {
+					BeforeEach(func() {
+						err := setupDeploymentStateService.Save(biconfig.DeploymentState{
+							DirectorID:    directorID,
+							CurrentDiskID: "disk-id",
+							Disks: []biconfig.DiskRecord{
+								{ID: "disk-id", CID: "fake-disk-cid", Size: 1024, CloudProperties: biproperty.Map{"type": "ssd"}},
+							},
+						})
+						Expect(err).ToNot(HaveOccurred())
+					})
+
+					It("deletes the deployment without its disk and records the disk", func() {
+						mockDeploymentManagerFactory.EXPECT().NewManager(mockCloud, mockAgentClient, mockBlobstore).Return(mockDeploymentManager)
+						mockDeploymentManager.EXPECT().FindCurrent().Return(mockDeployment, true, nil)
+						gomock.InOrder(
+							mockDeployment.EXPECT().DeleteKeepingDisks(skipDrain, gomock.Any()),
+							mockDeploymentManager.EXPECT().Cleanup(fakeStage),
+						)
+						mockCpiUninstaller.EXPECT().Uninstall(gomock.Any()).Return(nil)
+
+						err := newDeploymentDeleter().DeleteDeployment(skipDrain, true, fakeStage)
+						Expect(err).ToNot(HaveOccurred())
+
+						keptDisksPath := deploymentStatePath + ".kept-disks.json"
+						keptDisk, found, err := biconfig.NewKeptDisksRepo(fs, keptDisksPath).Find("fake-disk-cid")
+						Expect(err).ToNot(HaveOccurred())
+						Expect(found).To(BeTrue())
+						Expect(keptDisk).To(Equal(biconfig.KeptDisk{CID: "fake-disk-cid", Size: 1024, CloudProperties: biproperty.Map{"type": "ssd"}}))
+
+						Expect(fakeUI.Said).To(ContainElement(
+							"Kept persistent disk 'fake-disk-cid' (recorded in '" + keptDisksPath + "'). Attach it with 'create-env --adopt-disk fake-disk-cid'.\n"))
+					})
+				})
+
 				It("deletes the local CPI installation", func() {
 					expectDeleteAndCleanup(skipDrain, false)
 					mockCpiUninstaller.EXPECT().Uninstall(gomock.Any()).Return(nil)
 
-					err := newDeploymentDeleter().DeleteDeployment(skipDrain, fakeStage)
+					err := newDeploymentDeleter().DeleteDeployment(skipDrain, false, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 				})
 
 				It("logs validating & deleting stages", func() {
 					expectDeleteAndCleanup(skipDrain, true)
 
-					err := newDeploymentDeleter().DeleteDeployment(skipDrain, fakeStage)
+					err := newDeploymentDeleter().DeleteDeployment(skipDrain, false, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 
 					expectValidationInstallationDeletionEvents()
@@ -490,7 +527,7 @@ cloud_provider:
 				It("deletes the local deployment state file", func() {
 					expectDeleteAndCleanup(skipDrain, true)
 
-					err := newDeploymentDeleter().DeleteDeployment(skipDrain, fakeStage)
+					err := newDeploymentDeleter().DeleteDeployment(skipDrain, false, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 
 					Expect(fs.FileExists(deploymentStatePath)).To(BeFalse())
@@ -500,7 +537,7 @@ cloud_provider:
 					skipDrain = true
 					expectDeleteAndCleanup(skipDrain, true)
 
-					err := newDeploymentDeleter().DeleteDeployment(skipDrain, fakeStage)
+					err := newDeploymentDeleter().DeleteDeployment(skipDrain, false, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 				})
 			})
@@ -514,7 +551,7 @@ cloud_provider:
 				It("cleans up orphans, but does not delete any deployment", func() {
 					expectCleanup()
 
-					err := newDeploymentDeleter().DeleteDeployment(skipDrain, fakeStage)
+					err := newDeploymentDeleter().DeleteDeployment(skipDrain, false, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(fakeUI.Errors).To(BeEmpty())
 				})
@@ -558,7 +595,7 @@ cloud_provider:
 
 					mockDeployment.EXPECT().Delete(skipDrain, gomock.Any()).Return(deleteError)
 
-					err := newDeploymentDeleter().DeleteDeployment(skipDrain, fakeStage)
+					err := newDeploymentDeleter().DeleteDeployment(skipDrain, false, fakeStage)
 
 					Expect(err).To(HaveOccurred())
 				})
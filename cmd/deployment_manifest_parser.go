@@ -13,8 +13,11 @@ import (
 )
 
 type DeploymentManifestParser interface {
-	GetDeploymentManifest(path string, vars boshtpl.Variables, op patch.Op, releaseSetManifest birelsetmanifest.Manifest, stage biui.Stage) (bideplmanifest.Manifest, string, error)
 	GetDeploymentManifestUpdate(path string, vars boshtpl.Variables, op patch.Op, releaseSetManifest birelsetmanifest.Manifest, stage biui.Stage) (bideplmanifest.Update, error)
+	GetDeploymentManifestWithoutReleaseJobs(path string, vars boshtpl.Variables, op patch.Op, releaseSetManifest birelsetmanifest.Manifest, stage biui.Stage) (bideplmanifest.Manifest, error)
+	ParseDeploymentManifest(path string, vars boshtpl.Variables, op patch.Op) (bideplmanifest.Manifest, string, error)
+	ValidateDeploymentManifest(deploymentManifest bideplmanifest.Manifest, releaseSetManifest birelsetmanifest.Manifest, stage biui.Stage) error
+	InterpolateDeploymentManifest(path string, vars boshtpl.Variables, op patch.Op) ([]byte, error)
 }
 
 type deploymentManifestParser struct {
@@ -38,18 +41,73 @@ func NewDeploymentManifestParser(
 }
 
 func (y deploymentManifestParser) GetDeploymentManifestUpdate(path string, vars boshtpl.Variables, op patch.Op, releaseSetManifest birelsetmanifest.Manifest, stage biui.Stage) (bideplmanifest.Update, error) {
-	deploymentManifest, _, err := y.getManifest(path, vars, op, releaseSetManifest, stage, true)
+	deploymentManifest, _, err := y.getManifest(path, vars, op, releaseSetManifest, stage)
 	if err != nil {
 		return bideplmanifest.Update{}, err
 	}
 	return deploymentManifest.Update, nil
 }
 
-func (y deploymentManifestParser) GetDeploymentManifest(path string, vars boshtpl.Variables, op patch.Op, releaseSetManifest birelsetmanifest.Manifest, stage biui.Stage) (bideplmanifest.Manifest, string, error) {
-	return y.getManifest(path, vars, op, releaseSetManifest, stage, false)
+// GetDeploymentManifestWithoutReleaseJobs does not require the releases of the jobs to be extracted
+func (y deploymentManifestParser) GetDeploymentManifestWithoutReleaseJobs(path string, vars boshtpl.Variables, op patch.Op, releaseSetManifest birelsetmanifest.Manifest, stage biui.Stage) (bideplmanifest.Manifest, error) {
+	deploymentManifest, _, err := y.getManifest(path, vars, op, releaseSetManifest, stage)
+	return deploymentManifest, err
 }
 
-func (y deploymentManifestParser) getManifest(path string, vars boshtpl.Variables, op patch.Op, releaseSetManifest birelsetmanifest.Manifest, stage biui.Stage, skipReleaseJobsValidation bool) (bideplmanifest.Manifest, string, error) {
+// ParseDeploymentManifest returns the manifest with its SHA without validating it,
+// e.g. to find its tarballs before the releases needed for validation are extracted
+func (y deploymentManifestParser) ParseDeploymentManifest(path string, vars boshtpl.Variables, op patch.Op) (bideplmanifest.Manifest, string, error) {
+	template, err := y.templateFactory.NewDeploymentTemplateFromPath(path)
+	if err != nil {
+		return bideplmanifest.Manifest{}, "", bosherr.WrapErrorf(err, "Evaluating manifest")
+	}
+
+	interpolatedTemplate, err := template.Evaluate(vars, op)
+	if err != nil {
+		return bideplmanifest.Manifest{}, "", bosherr.WrapErrorf(err, "Evaluating manifest '%s'", path)
+	}
+
+	deploymentManifest, err := y.deploymentParser.Parse(interpolatedTemplate, path)
+	if err != nil {
+		return bideplmanifest.Manifest{}, "", bosherr.WrapErrorf(err, "Parsing deployment manifest '%s'", path)
+	}
+
+	return deploymentManifest, interpolatedTemplate.SHA(), nil
+}
+
+// ValidateDeploymentManifest validates a parsed manifest including the jobs of its releases
+func (y deploymentManifestParser) ValidateDeploymentManifest(deploymentManifest bideplmanifest.Manifest, releaseSetManifest birelsetmanifest.Manifest, stage biui.Stage) error {
+	return stage.Perform("Validating deployment manifest", func() error {
+		err := y.deploymentValidator.Validate(deploymentManifest, releaseSetManifest)
+		if err != nil {
+			return bosherr.WrapError(err, "Validating deployment manifest")
+		}
+
+		err = y.deploymentValidator.ValidateReleaseJobs(deploymentManifest, y.releaseManager)
+		if err != nil {
+			return bosherr.WrapError(err, "Validating deployment jobs refer to jobs in release")
+		}
+
+		return nil
+	})
+}
+
+// InterpolateDeploymentManifest returns the manifest as it is deployed, e.g. to store it in the deployment state
+func (y deploymentManifestParser) InterpolateDeploymentManifest(path string, vars boshtpl.Variables, op patch.Op) ([]byte, error) {
+	template, err := y.templateFactory.NewDeploymentTemplateFromPath(path)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Evaluating manifest")
+	}
+
+	interpolatedTemplate, err := template.Evaluate(vars, op)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Evaluating manifest '%s'", path)
+	}
+
+	return interpolatedTemplate.Content(), nil
+}
+
+func (y deploymentManifestParser) getManifest(path string, vars boshtpl.Variables, op patch.Op, releaseSetManifest birelsetmanifest.Manifest, stage biui.Stage) (bideplmanifest.Manifest, string, error) {
 	var deploymentManifest bideplmanifest.Manifest
 	var manifestSHA string
 
@@ -78,13 +136,6 @@ func (y deploymentManifestParser) getManifest(path string, vars boshtpl.Variable
 			return bosherr.WrapError(err, "Validating deployment manifest")
 		}
 
-		if !skipReleaseJobsValidation {
-			err = y.deploymentValidator.ValidateReleaseJobs(deploymentManifest, y.releaseManager)
-			if err != nil {
-				return bosherr.WrapError(err, "Validating deployment jobs refer to jobs in release")
-			}
-		}
-
 		return nil
 	})
 	if err != nil {
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"fmt"
+
+	"github.com/cloudfoundry/bosh-agent/agentclient"
 	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	bihttpclient "github.com/cloudfoundry/bosh-utils/httpclient"
@@ -12,15 +15,20 @@ import (
 	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
 	bicpirel "github.com/cloudfoundry/bosh-cli/v7/cpi/release"
 	bidepl "github.com/cloudfoundry/bosh-cli/v7/deployment"
+	bidisk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk"
+	"github.com/cloudfoundry/bosh-cli/v7/deployment/dryrun"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest"
 	bivm "github.com/cloudfoundry/bosh-cli/v7/deployment/vm"
 	boshtpl "github.com/cloudfoundry/bosh-cli/v7/director/template"
 	biinstall "github.com/cloudfoundry/bosh-cli/v7/installation"
 	boshinst "github.com/cloudfoundry/bosh-cli/v7/installation"
 	biinstallmanifest "github.com/cloudfoundry/bosh-cli/v7/installation/manifest"
+	bitarball "github.com/cloudfoundry/bosh-cli/v7/installation/tarball"
 	birelsetmanifest "github.com/cloudfoundry/bosh-cli/v7/release/set/manifest"
 	bistemcell "github.com/cloudfoundry/bosh-cli/v7/stemcell"
 	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
 )
 
 func NewDeploymentPreparer(
@@ -35,18 +43,24 @@ func NewDeploymentPreparer(
 	stemcellManagerFactory bistemcell.ManagerFactory,
 	agentClientFactory bihttpagent.AgentClientFactory,
 	vmManagerFactory bivm.ManagerFactory,
+	diskManagerFactory bidisk.ManagerFactory,
+	keptDisksRepo biconfig.KeptDisksRepo,
+	manifestRepo biconfig.ManifestRepo,
+	hookRunner bihook.Runner,
 	blobstoreFactory biblobstore.Factory,
 	deployer bidepl.Deployer,
 	deploymentManifestPath string,
 	deploymentVars boshtpl.Variables,
 	deploymentOp patch.Op,
 	cpiInstaller bicpirel.CpiInstaller,
+	tarballProvider bitarball.Provider,
 	releaseFetcher boshinst.ReleaseFetcher,
 	stemcellFetcher bistemcell.Fetcher,
 	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser,
 	deploymentManifestParser DeploymentManifestParser,
 	tempRootConfigurator TempRootConfigurator,
 	targetProvider biinstall.TargetProvider,
+	dryRunRecorder *dryrun.Recorder,
 ) DeploymentPreparer {
 	return DeploymentPreparer{
 		ui:                                      ui,
@@ -60,18 +74,24 @@ func NewDeploymentPreparer(
 		stemcellManagerFactory:                  stemcellManagerFactory,
 		agentClientFactory:                      agentClientFactory,
 		vmManagerFactory:                        vmManagerFactory,
+		diskManagerFactory:                      diskManagerFactory,
+		keptDisksRepo:                           keptDisksRepo,
+		manifestRepo:                            manifestRepo,
+		hookRunner:                              hookRunner,
 		blobstoreFactory:                        blobstoreFactory,
 		deployer:                                deployer,
 		deploymentManifestPath:                  deploymentManifestPath,
 		deploymentVars:                          deploymentVars,
 		deploymentOp:                            deploymentOp,
 		cpiInstaller:                            cpiInstaller,
+		tarballProvider:                         tarballProvider,
 		releaseFetcher:                          releaseFetcher,
 		stemcellFetcher:                         stemcellFetcher,
 		releaseSetAndInstallationManifestParser: releaseSetAndInstallationManifestParser,
 		deploymentManifestParser:                deploymentManifestParser,
 		tempRootConfigurator:                    tempRootConfigurator,
 		targetProvider:                          targetProvider,
+		dryRunRecorder:                          dryRunRecorder,
 	}
 }
 
@@ -87,24 +107,45 @@ type DeploymentPreparer struct {
 	stemcellManagerFactory                  bistemcell.ManagerFactory
 	agentClientFactory                      bihttpagent.AgentClientFactory
 	vmManagerFactory                        bivm.ManagerFactory
+	diskManagerFactory                      bidisk.ManagerFactory
+	keptDisksRepo                           biconfig.KeptDisksRepo
+	manifestRepo                            biconfig.ManifestRepo
+	hookRunner                              bihook.Runner
 	blobstoreFactory                        biblobstore.Factory
 	deployer                                bidepl.Deployer
 	deploymentManifestPath                  string
 	deploymentVars                          boshtpl.Variables
 	deploymentOp                            patch.Op
 	cpiInstaller                            bicpirel.CpiInstaller
+	tarballProvider                         bitarball.Provider
 	releaseFetcher                          boshinst.ReleaseFetcher
 	stemcellFetcher                         bistemcell.Fetcher
 	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser
 	deploymentManifestParser                DeploymentManifestParser
 	tempRootConfigurator                    TempRootConfigurator
 	targetProvider                          biinstall.TargetProvider
+	dryRunRecorder                          *dryrun.Recorder
 }
 
-func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool, recreatePersistentDisks bool, skipDrain bool) (err error) {
+func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool, recreatePersistentDisks bool, skipDrain bool, dryRun bool, adoptDiskCIDs []string, manifestKey string) (err error) {
+	if dryRun && c.dryRunRecorder == nil {
+		return bosherr.Error("Dry runs need a recording cloud, agent and blobstore")
+	}
+
 	c.ui.BeginLinef("Deployment state: '%s'\n", c.deploymentStateService.Path())
 
-	if !c.deploymentStateService.Exists() {
+	err = c.deploymentStateService.Lock()
+	if err != nil {
+		return bosherr.WrapError(err, "Locking deployment state")
+	}
+	defer func() {
+		unlockErr := c.deploymentStateService.Unlock()
+		if unlockErr != nil {
+			c.logger.Warn(c.logTag, "Unlocking deployment state: %s", unlockErr.Error())
+		}
+	}()
+
+	if !dryRun && !c.deploymentStateService.Exists() {
 		migrated, err := c.legacyDeploymentStateMigrator.MigrateIfExists(biconfig.LegacyDeploymentStatePath(c.deploymentManifestPath))
 		if err != nil {
 			return bosherr.WrapError(err, "Migrating legacy deployment state file")
@@ -149,6 +190,16 @@ func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool,
 			return err
 		}
 
+		deploymentManifest, manifestSHA, err = c.deploymentManifestParser.ParseDeploymentManifest(c.deploymentManifestPath, c.deploymentVars, c.deploymentOp)
+		if err != nil {
+			return err
+		}
+
+		err = c.tarballProvider.Prefetch(tarballSources(releaseSetManifest, deploymentManifest), stage)
+		if err != nil {
+			return err
+		}
+
 		for _, releaseRef := range releaseSetManifest.Releases {
 			err = c.releaseFetcher.DownloadAndExtract(releaseRef, stage)
 			if err != nil {
@@ -161,17 +212,34 @@ func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool,
 			return err
 		}
 
-		deploymentManifest, manifestSHA, err = c.deploymentManifestParser.GetDeploymentManifest(c.deploymentManifestPath, c.deploymentVars, c.deploymentOp, releaseSetManifest, stage)
+		err = c.deploymentManifestParser.ValidateDeploymentManifest(deploymentManifest, releaseSetManifest, stage)
 		if err != nil {
 			return err
 		}
 
+		if recreatePersistentDisks {
+			diskPools, err := deploymentManifest.PersistentDiskPools(deploymentManifest.JobName())
+			if err != nil {
+				return err
+			}
+
+			// only the first persistent disk can be migrated to a recreated disk
+			if len(diskPools) > 1 {
+				return bosherr.Error("Recreating persistent disks is only supported with a single persistent disk")
+			}
+		}
+
 		extractedStemcell, err = c.stemcellFetcher.GetStemcell(deploymentManifest, stage)
 		return err
 	})
 	if err != nil {
 		return err
 	}
+	if dryRun {
+		// the recording agent reports running jobs right away
+		deploymentManifest.Update.UpdateWatchTime.Start = 0
+	}
+
 	defer func() {
 		deleteErr := extractedStemcell.Cleanup()
 		if deleteErr != nil {
@@ -184,9 +252,21 @@ func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool,
 		return bosherr.WrapError(err, "Checking if deployment has changed")
 	}
 
-	if isDeployed && !recreate && !recreatePersistentDisks {
+	if isDeployed && !recreate && !recreatePersistentDisks && len(adoptDiskCIDs) == 0 {
+		if dryRun {
+			c.printPlan(deploymentState, extractedStemcell)
+			return nil
+		}
+
 		c.ui.BeginLinef("No deployment, stemcell or release changes. Skipping deploy.\n")
-		return nil
+
+		// environments deployed before the manifest was stored get it on their next create-env
+		_, found, err := c.manifestRepo.FindCurrent()
+		if err != nil || found {
+			return err
+		}
+
+		return c.recordManifest(manifestKey)
 	}
 
 	err = c.cpiInstaller.WithInstalledCpiRelease(installationManifest, target, stage, func(installation biinstall.Installation) error {
@@ -206,6 +286,8 @@ func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool,
 				deploymentManifest,
 				manifestSHA,
 				skipDrain,
+				adoptDiskCIDs,
+				manifestKey,
 				stage,
 				cloud,
 			)
@@ -218,7 +300,13 @@ func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool,
 
 		if stemcellApiVersion >= bicloud.StemcellNoRegistryAsOfVersion &&
 			cpiInfo.ApiVersion == bicloud.MaxCpiApiVersionSupported {
-			return deploy()
+			err = deploy()
+			if err != nil || !dryRun {
+				return err
+			}
+
+			c.printPlan(deploymentState, extractedStemcell)
+			return nil
 		} else {
 			return bosherr.Errorf(
 				"The `bosh` cli requires CPI v2.0 or greater, you are using %d",
@@ -229,6 +317,24 @@ func (c *DeploymentPreparer) PrepareDeployment(stage biui.Stage, recreate bool,
 	return err
 }
 
+// tarballSources returns the releases and stemcell of the manifest to download
+// before the releases are extracted to validate the manifest; a missing stemcell
+// is left to the validation to report
+func tarballSources(releaseSetManifest birelsetmanifest.Manifest, deploymentManifest bideplmanifest.Manifest) []bitarball.Source {
+	var sources []bitarball.Source
+
+	for _, releaseRef := range releaseSetManifest.Releases {
+		sources = append(sources, releaseRef)
+	}
+
+	stemcell, err := deploymentManifest.Stemcell(deploymentManifest.JobName())
+	if err != nil {
+		return sources
+	}
+
+	return append(sources, stemcell)
+}
+
 func (c *DeploymentPreparer) deploy(
 	installation biinstall.Installation,
 	deploymentState biconfig.DeploymentState,
@@ -237,9 +343,18 @@ func (c *DeploymentPreparer) deploy(
 	deploymentManifest bideplmanifest.Manifest,
 	manifestSHA string,
 	skipDrain bool,
+	adoptDiskCIDs []string,
+	manifestKey string,
 	stage biui.Stage,
 	cloud bicloud.Cloud,
 ) (err error) {
+	for _, adoptDiskCID := range adoptDiskCIDs {
+		err = c.adoptDisk(adoptDiskCID, deploymentManifest, cloud, stage)
+		if err != nil {
+			return err
+		}
+	}
+
 	stemcellManager := c.stemcellManagerFactory.NewManager(cloud)
 
 	cloudStemcell, err := stemcellManager.Upload(extractedStemcell, stage)
@@ -282,7 +397,14 @@ func (c *DeploymentPreparer) deploy(
 			return bosherr.WrapError(err, "Updating deployment record")
 		}
 
-		return nil
+		err = c.recordManifest(manifestKey)
+		if err != nil {
+			return err
+		}
+
+		return c.hookRunner.Run(bihook.PostDeploy, func() bihook.Context {
+			return c.postDeployHookContext(agentClient, deploymentManifest, cloudStemcell.CID())
+		}, deployStage)
 	})
 	if err != nil {
 		return err
@@ -298,6 +420,190 @@ func (c *DeploymentPreparer) deploy(
 	return nil
 }
 
+// recordManifest stores the deployed manifest with redacted variable values,
+// and also encrypted with all of its values when a key is given
+func (c *DeploymentPreparer) recordManifest(key string) error {
+	redacted, err := c.deploymentManifestParser.InterpolateDeploymentManifest(c.deploymentManifestPath, boshtpl.NewRedactedVariables(c.deploymentVars), c.deploymentOp)
+	if err != nil {
+		return err
+	}
+
+	var interpolated []byte
+
+	if key != "" {
+		interpolated, err = c.deploymentManifestParser.InterpolateDeploymentManifest(c.deploymentManifestPath, c.deploymentVars, c.deploymentOp)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = c.manifestRepo.UpdateCurrent(redacted, interpolated, key)
+	if err != nil {
+		return bosherr.WrapError(err, "Storing deployment manifest")
+	}
+
+	return nil
+}
+
+// postDeployHookContext describes the deployed VM from the deployment state
+func (c *DeploymentPreparer) postDeployHookContext(agentClient agentclient.AgentClient, deploymentManifest bideplmanifest.Manifest, stemcellCID string) bihook.Context {
+	context := bihook.Context{
+		IPs:         deploymentManifest.StaticIPs(deploymentManifest.JobName()),
+		StemcellCID: stemcellCID,
+	}
+
+	deploymentState, err := c.deploymentStateService.Load()
+	if err != nil {
+		c.logger.Warn(c.logTag, "Loading deployment state for hooks: %s", err.Error())
+		return context
+	}
+
+	context.VMCID = deploymentState.CurrentVMCID
+
+	for _, diskID := range deploymentState.CurrentDiskIDs {
+		for _, diskRecord := range deploymentState.Disks {
+			if diskRecord.ID == diskID {
+				context.DiskCIDs = append(context.DiskCIDs, diskRecord.CID)
+			}
+		}
+	}
+
+	state, err := agentClient.GetState()
+	if err != nil {
+		c.logger.Warn(c.logTag, "Getting IPs of VM '%s' from its agent for hooks: %s", context.VMCID, err.Error())
+		return context
+	}
+
+	if ips := bihook.AgentIPs(state); len(ips) > 0 {
+		context.IPs = ips
+	}
+
+	return context
+}
+
+// adoptDisk makes the disk the current disk so that the deployer attaches it to the new VM.
+// The size of a disk kept by delete-env is known, so a different size in the manifest migrates it.
+func (c *DeploymentPreparer) adoptDisk(cid string, deploymentManifest bideplmanifest.Manifest, cloud bicloud.Cloud, stage biui.Stage) error {
+	diskPools, err := deploymentManifest.PersistentDiskPools(deploymentManifest.JobName())
+	if err != nil {
+		return bosherr.WrapError(err, "Getting disk pool")
+	}
+
+	if len(diskPools) == 0 {
+		return bosherr.Errorf("Cannot adopt disk '%s': the deployment manifest does not specify a persistent disk", cid)
+	}
+
+	keptDisk, kept, err := c.keptDisksRepo.Find(cid)
+	if err != nil {
+		return err
+	}
+
+	diskPool, err := adoptedDiskPool(cid, diskPools, keptDisk, kept)
+	if err != nil {
+		return err
+	}
+
+	stepName := fmt.Sprintf("Adopting disk '%s'", cid)
+	return stage.Perform(stepName, func() error {
+		size, cloudProperties := diskPool.DiskPool.DiskSize, diskPool.DiskPool.CloudProperties
+
+		if kept {
+			size, cloudProperties = keptDisk.Size, keptDisk.CloudProperties
+		}
+
+		_, err = c.diskManagerFactory.NewManager(cloud).Adopt(diskPool.Name, cid, size, cloudProperties)
+		if err != nil {
+			return err
+		}
+
+		return c.keptDisksRepo.Remove(cid)
+	})
+}
+
+// adoptedDiskPool finds the persistent disk a disk is adopted as: kept disks by their name,
+// other disks only when the manifest specifies a single persistent disk
+func adoptedDiskPool(cid string, diskPools []bideplmanifest.NamedDiskPool, keptDisk biconfig.KeptDisk, kept bool) (bideplmanifest.NamedDiskPool, error) {
+	if !kept {
+		if len(diskPools) > 1 {
+			return bideplmanifest.NamedDiskPool{}, bosherr.Errorf("Cannot adopt disk '%s': it was not kept by delete-env and the deployment manifest specifies multiple persistent disks", cid)
+		}
+		return diskPools[0], nil
+	}
+
+	for _, diskPool := range diskPools {
+		if diskPool.Name == keptDisk.Name {
+			return diskPool, nil
+		}
+	}
+
+	// like create-env, the first persistent disk takes the disk of a single persistent disk
+	if keptDisk.Name == "" || diskPools[0].Name == "" {
+		return diskPools[0], nil
+	}
+
+	return bideplmanifest.NamedDiskPool{}, bosherr.Errorf("Cannot adopt disk '%s': the deployment manifest does not specify persistent disk '%s'", cid, keptDisk.Name)
+}
+
+// printPlan shows what the calls a dry run recorded would change, compared to the deployment state before the run
+func (c *DeploymentPreparer) printPlan(deploymentState biconfig.DeploymentState, extractedStemcell bistemcell.ExtractedStemcell) {
+	var releases []string
+
+	for _, release := range c.releaseManager.List() {
+		releases = append(releases, fmt.Sprintf("%s/%s", release.Name(), release.Version()))
+	}
+
+	stemcell := fmt.Sprintf("%s/%s", extractedStemcell.Manifest().Name, extractedStemcell.Manifest().Version)
+
+	plan := dryrun.NewPlan(c.dryRunRecorder.Calls(), deploymentState, stemcell, releases)
+
+	changesTable := boshtbl.Table{
+		Content: "resources",
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Resource"),
+			boshtbl.NewHeader("Name"),
+			boshtbl.NewHeader("Action"),
+			boshtbl.NewHeader("Reason"),
+		},
+	}
+
+	for _, change := range plan.Changes {
+		changesTable.Rows = append(changesTable.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(change.Resource),
+			boshtbl.NewValueString(change.Name),
+			boshtbl.NewValueString(change.Action),
+			boshtbl.NewValueString(change.Reason),
+		})
+	}
+
+	c.ui.PrintTable(changesTable)
+
+	if !plan.HasChanges() {
+		c.ui.BeginLinef("No deployment, stemcell or release changes.\n")
+	} else {
+		callsTable := boshtbl.Table{
+			Title:   "Calls a real run would make, in order",
+			Content: "calls",
+			Header: []boshtbl.Header{
+				boshtbl.NewHeader("Component"),
+				boshtbl.NewHeader("Call"),
+				boshtbl.NewHeader("Subject"),
+			},
+		}
+
+		for _, call := range plan.Calls {
+			callsTable.Rows = append(callsTable.Rows, []boshtbl.Value{
+				boshtbl.NewValueString(call.Component),
+				boshtbl.NewValueString(call.Method),
+				boshtbl.NewValueString(call.Subject),
+			})
+		}
+
+		c.ui.PrintTable(callsTable)
+	}
+
+	c.ui.BeginLinef("Dry run: no changes were made to the environment.\n")
+}
+
 func (c *DeploymentPreparer) stemcellApiVersion(stemcell bistemcell.ExtractedStemcell) int {
 	stemcellApiVersion := stemcell.Manifest().ApiVersion
 	if stemcellApiVersion == 0 {
@@ -95,6 +95,17 @@ func (c *deploymentStateManager) StartDeployment(stage biui.Stage) (err error) {
 func (c *deploymentStateManager) executeStateChange(stage biui.Stage, stateChanger func(biui.Stage, string, biinstallmanifest.Manifest, bideplmanifest.Update) error) (err error) {
 	c.ui.BeginLinef("Deployment state: '%s'\n", c.deploymentStateService.Path())
 
+	err = c.deploymentStateService.Lock()
+	if err != nil {
+		return bosherr.WrapError(err, "Locking deployment state")
+	}
+	defer func() {
+		unlockErr := c.deploymentStateService.Unlock()
+		if unlockErr != nil {
+			c.logger.Warn(c.logTag, "Unlocking deployment state: %s", unlockErr.Error())
+		}
+	}()
+
 	if !c.deploymentStateService.Exists() {
 		c.ui.BeginLinef("No deployment state file found.\n")
 		return nil
@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cppforlife/go-patch/patch"
+
+	bidepltpl "github.com/cloudfoundry/bosh-cli/v7/deployment/template"
+	boshtpl "github.com/cloudfoundry/bosh-cli/v7/director/template"
+	bitarball "github.com/cloudfoundry/bosh-cli/v7/installation/tarball"
+	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+//counterfeiter:generate . EnvBundler
+
+// EnvBundler writes an interpolated create-env manifest and its release and
+// stemcell tarballs into a bundle that create-env can use offline
+type EnvBundler interface {
+	Bundle(destinationPath string, stage biui.Stage) error
+}
+
+type envBundler struct {
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser
+	deploymentManifestParser                DeploymentManifestParser
+	templateFactory                         bidepltpl.DeploymentTemplateFactory
+	bundleWriter                            bitarball.BundleWriter
+	deploymentManifestPath                  string
+	deploymentVars                          boshtpl.Variables
+	deploymentOp                            patch.Op
+}
+
+func NewEnvBundler(
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser,
+	deploymentManifestParser DeploymentManifestParser,
+	templateFactory bidepltpl.DeploymentTemplateFactory,
+	bundleWriter bitarball.BundleWriter,
+	deploymentManifestPath string,
+	deploymentVars boshtpl.Variables,
+	deploymentOp patch.Op,
+) EnvBundler {
+	return envBundler{
+		releaseSetAndInstallationManifestParser: releaseSetAndInstallationManifestParser,
+		deploymentManifestParser:                deploymentManifestParser,
+		templateFactory:                         templateFactory,
+		bundleWriter:                            bundleWriter,
+		deploymentManifestPath:                  deploymentManifestPath,
+		deploymentVars:                          deploymentVars,
+		deploymentOp:                            deploymentOp,
+	}
+}
+
+func (b envBundler) Bundle(destinationPath string, stage biui.Stage) error {
+	template, err := b.templateFactory.NewDeploymentTemplateFromPath(b.deploymentManifestPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading deployment manifest '%s'", b.deploymentManifestPath)
+	}
+
+	interpolatedTemplate, err := template.Evaluate(b.deploymentVars, b.deploymentOp)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Evaluating manifest")
+	}
+
+	releaseSetManifest, _, err := b.releaseSetAndInstallationManifestParser.ReleaseSetAndInstallationManifest(b.deploymentManifestPath, b.deploymentVars, b.deploymentOp)
+	if err != nil {
+		return err
+	}
+
+	deploymentManifest, err := b.deploymentManifestParser.GetDeploymentManifestWithoutReleaseJobs(b.deploymentManifestPath, b.deploymentVars, b.deploymentOp, releaseSetManifest, stage)
+	if err != nil {
+		return err
+	}
+
+	var sources []bitarball.Source
+
+	for _, releaseRef := range releaseSetManifest.Releases {
+		sources = append(sources, releaseRef)
+	}
+
+	stemcell, err := deploymentManifest.Stemcell(deploymentManifest.JobName())
+	if err != nil {
+		return err
+	}
+
+	sources = append(sources, stemcell)
+
+	return b.bundleWriter.Write(interpolatedTemplate.Content(), sources, destinationPath, stage)
+}
@@ -0,0 +1,63 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+type EnvCheckCmd struct {
+	ui      boshui.UI
+	checker EnvChecker
+}
+
+func NewEnvCheckCmd(ui boshui.UI, checker EnvChecker) EnvCheckCmd {
+	return EnvCheckCmd{ui: ui, checker: checker}
+}
+
+// Run fails when any check is unhealthy so that it can be used for monitoring
+func (c EnvCheckCmd) Run(stage boshui.Stage) error {
+	findings, err := c.checker.Check(stage)
+	if err != nil {
+		return err
+	}
+
+	table := boshtbl.Table{
+		Content: "checks",
+
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Resource"),
+			boshtbl.NewHeader("ID"),
+			boshtbl.NewHeader("Status"),
+			boshtbl.NewHeader("Finding"),
+			boshtbl.NewHeader("Remediation"),
+		},
+	}
+
+	problems := 0
+
+	for _, finding := range findings {
+		status := "ok"
+		if !finding.Healthy {
+			status = "drift"
+			problems++
+		}
+
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(finding.Resource),
+			boshtbl.NewValueString(finding.ID),
+			boshtbl.NewValueFmt(boshtbl.NewValueString(status), !finding.Healthy),
+			boshtbl.NewValueString(finding.Description),
+			boshtbl.NewValueString(finding.Remediation),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	if problems > 0 {
+		return bosherr.Errorf("Found %d problem(s) in the environment", problems)
+	}
+
+	return nil
+}
@@ -0,0 +1,77 @@
+package cmd_test
+
+import (
+	"errors"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/cmdfakes"
+	fakebiui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+var _ = Describe("EnvCheckCmd", func() {
+	var (
+		ui      *fakebiui.FakeUI
+		stage   *fakebiui.FakeStage
+		checker *cmdfakes.FakeEnvChecker
+		command cmd.EnvCheckCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakebiui.FakeUI{}
+		stage = fakebiui.NewFakeStage()
+		checker = &cmdfakes.FakeEnvChecker{}
+		command = cmd.NewEnvCheckCmd(ui, checker)
+	})
+
+	It("prints every check and succeeds when all are healthy", func() {
+		checker.CheckReturns([]cmd.EnvFinding{
+			{Resource: "vm", ID: "fake-vm-cid", Healthy: true, Description: "VM exists in the IaaS"},
+		}, nil)
+
+		err := command.Run(stage)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(checker.CheckArgsForCall(0)).To(Equal(stage))
+		Expect(ui.Table.Content).To(Equal("checks"))
+		Expect(ui.Table.Rows).To(Equal([][]boshtbl.Value{
+			{
+				boshtbl.NewValueString("vm"),
+				boshtbl.NewValueString("fake-vm-cid"),
+				boshtbl.NewValueFmt(boshtbl.NewValueString("ok"), false),
+				boshtbl.NewValueString("VM exists in the IaaS"),
+				boshtbl.NewValueString(""),
+			},
+		}))
+	})
+
+	It("prints drift with its remediation and fails", func() {
+		checker.CheckReturns([]cmd.EnvFinding{
+			{Resource: "vm", ID: "fake-vm-cid", Healthy: true, Description: "VM exists in the IaaS"},
+			{Resource: "disk", ID: "fake-disk-cid", Description: "Disk is not attached to the VM", Remediation: "Run 'bosh create-env' to attach the disk"},
+		}, nil)
+
+		err := command.Run(stage)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Found 1 problem(s) in the environment"))
+
+		Expect(ui.Table.Rows[1]).To(Equal([]boshtbl.Value{
+			boshtbl.NewValueString("disk"),
+			boshtbl.NewValueString("fake-disk-cid"),
+			boshtbl.NewValueFmt(boshtbl.NewValueString("drift"), true),
+			boshtbl.NewValueString("Disk is not attached to the VM"),
+			boshtbl.NewValueString("Run 'bosh create-env' to attach the disk"),
+		}))
+	})
+
+	It("returns an error when checking fails", func() {
+		checker.CheckReturns(nil, errors.New("fake-check-error"))
+
+		err := command.Run(stage)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("fake-check-error"))
+	})
+})
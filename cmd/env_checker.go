@@ -0,0 +1,290 @@
+package cmd
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/cloudfoundry/bosh-agent/agentclient"
+	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/cppforlife/go-patch/patch"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	boshtpl "github.com/cloudfoundry/bosh-cli/v7/director/template"
+	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+// EnvFinding is the result of a single health check of a create-env environment
+type EnvFinding struct {
+	Resource    string
+	ID          string
+	Healthy     bool
+	Description string
+	Remediation string
+}
+
+//counterfeiter:generate . EnvChecker
+
+// EnvChecker compares the deployment state of a create-env environment
+// with what the IaaS and the agent on its VM report
+type EnvChecker interface {
+	Check(stage biui.Stage) ([]EnvFinding, error)
+}
+
+type envChecker struct {
+	logTag                                  string
+	logger                                  boshlog.Logger
+	deploymentStateService                  biconfig.DeploymentStateService
+	vmRepo                                  biconfig.VMRepo
+	diskRepo                                biconfig.DiskRepo
+	cloudProvider                           EnvCloudProvider
+	agentClientFactory                      bihttpagent.AgentClientFactory
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser
+	deploymentManifestPath                  string
+	deploymentVars                          boshtpl.Variables
+	deploymentOp                            patch.Op
+}
+
+func NewEnvChecker(
+	logTag string,
+	logger boshlog.Logger,
+	deploymentStateService biconfig.DeploymentStateService,
+	vmRepo biconfig.VMRepo,
+	diskRepo biconfig.DiskRepo,
+	cloudProvider EnvCloudProvider,
+	agentClientFactory bihttpagent.AgentClientFactory,
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser,
+	deploymentManifestPath string,
+	deploymentVars boshtpl.Variables,
+	deploymentOp patch.Op,
+) EnvChecker {
+	return &envChecker{
+		logTag:                                  logTag,
+		logger:                                  logger,
+		deploymentStateService:                  deploymentStateService,
+		vmRepo:                                  vmRepo,
+		diskRepo:                                diskRepo,
+		cloudProvider:                           cloudProvider,
+		agentClientFactory:                      agentClientFactory,
+		releaseSetAndInstallationManifestParser: releaseSetAndInstallationManifestParser,
+		deploymentManifestPath:                  deploymentManifestPath,
+		deploymentVars:                          deploymentVars,
+		deploymentOp:                            deploymentOp,
+	}
+}
+
+func (c *envChecker) Check(stage biui.Stage) ([]EnvFinding, error) {
+	if !c.deploymentStateService.Exists() {
+		return nil, bosherr.Errorf("Expected deployment state '%s' to exist", c.deploymentStateService.Path())
+	}
+
+	deploymentState, err := c.deploymentStateService.Load()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Loading deployment state")
+	}
+
+	vmCID, vmFound, err := c.vmRepo.FindCurrent()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Finding current VM")
+	}
+
+	diskRecords, err := c.diskRepo.FindCurrent()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Finding current disks")
+	}
+
+	findings := []EnvFinding{}
+	vmExists := false
+
+	err = c.cloudProvider.WithCloud(stage, func(cloud bicloud.Cloud) error {
+		return stage.Perform("Checking VM and disks in the IaaS", func() error {
+			vmFinding, exists, err := c.checkVM(cloud, vmCID, vmFound)
+			if err != nil {
+				return err
+			}
+			findings = append(findings, vmFinding)
+			vmExists = exists
+
+			for _, diskRecord := range diskRecords {
+				diskFinding, err := c.checkDisk(cloud, diskRecord)
+				if err != nil {
+					return err
+				}
+				findings = append(findings, diskFinding)
+			}
+
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if !vmExists {
+		return findings, nil
+	}
+
+	_, installationManifest, err := c.releaseSetAndInstallationManifestParser.ReleaseSetAndInstallationManifest(c.deploymentManifestPath, c.deploymentVars, c.deploymentOp)
+	if err != nil {
+		return nil, err
+	}
+
+	agentClient, err := c.agentClientFactory.NewAgentClient(deploymentState.DirectorID, installationManifest.Mbus, installationManifest.Cert.CA)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Creating agent client")
+	}
+
+	err = stage.Perform("Checking agent on VM '"+vmCID+"'", func() error {
+		agentFindings := c.checkAgent(agentClient, vmCID, installationManifest.Mbus, diskRecords)
+		findings = append(findings, agentFindings...)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return findings, nil
+}
+
+func (c *envChecker) checkVM(cloud bicloud.Cloud, vmCID string, found bool) (EnvFinding, bool, error) {
+	if !found {
+		return EnvFinding{
+			Resource:    "vm",
+			Description: "Deployment state does not record a current VM",
+			Remediation: "Run 'bosh create-env' to create the VM",
+		}, false, nil
+	}
+
+	exists, err := cloud.HasVM(vmCID)
+	if err != nil {
+		return EnvFinding{}, false, bosherr.WrapErrorf(err, "Checking existence of VM '%s'", vmCID)
+	}
+
+	if !exists {
+		return EnvFinding{
+			Resource:    "vm",
+			ID:          vmCID,
+			Description: "VM does not exist in the IaaS",
+			Remediation: "Run 'bosh env-state repair' to clear the VM, then 'bosh create-env' to recreate it",
+		}, false, nil
+	}
+
+	return EnvFinding{Resource: "vm", ID: vmCID, Healthy: true, Description: "VM exists in the IaaS"}, true, nil
+}
+
+func (c *envChecker) checkDisk(cloud bicloud.Cloud, diskRecord biconfig.DiskRecord) (EnvFinding, error) {
+	exists, err := cloud.HasDisk(diskRecord.CID)
+	if err != nil {
+		return EnvFinding{}, bosherr.WrapErrorf(err, "Checking existence of disk '%s'", diskRecord.CID)
+	}
+
+	if !exists {
+		return EnvFinding{
+			Resource:    "disk",
+			ID:          diskRecord.CID,
+			Description: "Disk does not exist in the IaaS",
+			Remediation: "Restore the disk from a snapshot, or run 'bosh create-env --recreate-persistent-disks' to replace it without its data",
+		}, nil
+	}
+
+	return EnvFinding{Resource: "disk", ID: diskRecord.CID, Healthy: true, Description: "Disk exists in the IaaS"}, nil
+}
+
+// checkAgent reports an unreachable agent as a single finding
+// because its job state and disks cannot be checked without it
+func (c *envChecker) checkAgent(
+	agentClient agentclient.AgentClient,
+	vmCID string,
+	mbus string,
+	diskRecords []biconfig.DiskRecord,
+) []EnvFinding {
+	_, err := agentClient.Ping()
+	if err != nil {
+		c.logger.Debug(c.logTag, "Pinging agent failed: %s", err.Error())
+
+		return []EnvFinding{{
+			Resource:    "agent",
+			ID:          vmCID,
+			Description: fmt.Sprintf("Agent is not reachable at '%s'", redactedURL(mbus)),
+			Remediation: "Check that the VM is running and that the mbus port is reachable from this machine",
+		}}
+	}
+
+	findings := []EnvFinding{}
+
+	state, err := agentClient.GetState()
+	switch {
+	case err != nil:
+		findings = append(findings, EnvFinding{
+			Resource:    "agent",
+			ID:          vmCID,
+			Description: fmt.Sprintf("Getting agent state failed: %s", err.Error()),
+			Remediation: "Inspect the agent logs on the VM",
+		})
+	case state.JobState != "running":
+		findings = append(findings, EnvFinding{
+			Resource:    "agent",
+			ID:          vmCID,
+			Description: fmt.Sprintf("Agent reports job state '%s'", state.JobState),
+			Remediation: "Run 'bosh start-env' to start the jobs, or inspect the job logs if they keep failing",
+		})
+	default:
+		findings = append(findings, EnvFinding{Resource: "agent", ID: vmCID, Healthy: true, Description: "Agent reports job state 'running'"})
+	}
+
+	attachedDiskCIDs, err := agentClient.ListDisk()
+	if err != nil {
+		return append(findings, EnvFinding{
+			Resource:    "agent",
+			ID:          vmCID,
+			Description: fmt.Sprintf("Listing disks on the agent failed: %s", err.Error()),
+			Remediation: "Inspect the agent logs on the VM",
+		})
+	}
+
+	attached := map[string]bool{}
+	for _, diskCID := range attachedDiskCIDs {
+		attached[diskCID] = true
+	}
+
+	recorded := map[string]bool{}
+	for _, diskRecord := range diskRecords {
+		recorded[diskRecord.CID] = true
+
+		if !attached[diskRecord.CID] {
+			findings = append(findings, EnvFinding{
+				Resource:    "disk",
+				ID:          diskRecord.CID,
+				Description: "Disk is not attached to the VM",
+				Remediation: "Run 'bosh create-env' to attach the disk",
+			})
+		}
+	}
+
+	for _, diskCID := range attachedDiskCIDs {
+		if !recorded[diskCID] {
+			findings = append(findings, EnvFinding{
+				Resource:    "disk",
+				ID:          diskCID,
+				Description: "Disk is attached to the VM but not recorded as a current disk",
+				Remediation: "Adopt the disk with 'bosh create-env --adopt-disk' or detach it with 'bosh cpi-call'",
+			})
+		}
+	}
+
+	return findings
+}
+
+// redactedURL removes credentials so that findings can be shown and logged
+func redactedURL(rawURL string) string {
+	parsedURL, err := url.Parse(rawURL)
+	if err != nil {
+		return "<invalid url>"
+	}
+
+	parsedURL.User = nil
+
+	return parsedURL.String()
+}
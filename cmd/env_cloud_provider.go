@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	"github.com/cppforlife/go-patch/patch"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	bicpirel "github.com/cloudfoundry/bosh-cli/v7/cpi/release"
+	boshtpl "github.com/cloudfoundry/bosh-cli/v7/director/template"
+	biinstall "github.com/cloudfoundry/bosh-cli/v7/installation"
+	biinstallmanifest "github.com/cloudfoundry/bosh-cli/v7/installation/manifest"
+	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+//counterfeiter:generate . EnvCloudProvider
+
+// EnvCloudProvider installs the CPI of a create-env manifest
+// so that commands outside of create-env can talk to the IaaS
+type EnvCloudProvider interface {
+	WithCloud(stage biui.Stage, fn func(bicloud.Cloud) error) error
+	WithStemcellCloud(stage biui.Stage, stemcellApiVersion int, fn func(bicloud.Cloud) error) error
+	WithCPICmdRunner(stage biui.Stage, fn func(bicloud.CPICmdRunner, bicloud.CmdContext) error) error
+}
+
+type envCloudProvider struct {
+	logTag                                  string
+	logger                                  boshlog.Logger
+	deploymentStateService                  biconfig.DeploymentStateService
+	releaseManager                          biinstall.ReleaseManager
+	cloudFactory                            bicloud.Factory
+	deploymentManifestPath                  string
+	deploymentVars                          boshtpl.Variables
+	deploymentOp                            patch.Op
+	cpiInstaller                            bicpirel.CpiInstaller
+	releaseFetcher                          biinstall.ReleaseFetcher
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser
+	tempRootConfigurator                    TempRootConfigurator
+	targetProvider                          biinstall.TargetProvider
+}
+
+func NewEnvCloudProvider(
+	logTag string,
+	logger boshlog.Logger,
+	deploymentStateService biconfig.DeploymentStateService,
+	releaseManager biinstall.ReleaseManager,
+	cloudFactory bicloud.Factory,
+	deploymentManifestPath string,
+	deploymentVars boshtpl.Variables,
+	deploymentOp patch.Op,
+	cpiInstaller bicpirel.CpiInstaller,
+	releaseFetcher biinstall.ReleaseFetcher,
+	releaseSetAndInstallationManifestParser ReleaseSetAndInstallationManifestParser,
+	tempRootConfigurator TempRootConfigurator,
+	targetProvider biinstall.TargetProvider,
+) EnvCloudProvider {
+	return &envCloudProvider{
+		logTag:                                  logTag,
+		logger:                                  logger,
+		deploymentStateService:                  deploymentStateService,
+		releaseManager:                          releaseManager,
+		cloudFactory:                            cloudFactory,
+		deploymentManifestPath:                  deploymentManifestPath,
+		deploymentVars:                          deploymentVars,
+		deploymentOp:                            deploymentOp,
+		cpiInstaller:                            cpiInstaller,
+		releaseFetcher:                          releaseFetcher,
+		releaseSetAndInstallationManifestParser: releaseSetAndInstallationManifestParser,
+		tempRootConfigurator:                    tempRootConfigurator,
+		targetProvider:                          targetProvider,
+	}
+}
+
+func (p *envCloudProvider) WithCloud(stage biui.Stage, fn func(bicloud.Cloud) error) error {
+	return p.withInstallation(stage, func(installation biinstall.Installation, directorID string, stemcellApiVersion int) error {
+		cloud, err := p.cloudFactory.NewCloud(installation, directorID, stemcellApiVersion)
+		if err != nil {
+			return bosherr.WrapError(err, "Creating CPI client from CPI installation")
+		}
+
+		return fn(cloud)
+	})
+}
+
+// WithStemcellCloud tells the CPI the API version of the given stemcell
+// instead of that of the current stemcell of the environment,
+// e.g. for a stemcell that is not deployed yet
+func (p *envCloudProvider) WithStemcellCloud(stage biui.Stage, stemcellApiVersion int, fn func(bicloud.Cloud) error) error {
+	return p.withInstallation(stage, func(installation biinstall.Installation, directorID string, _ int) error {
+		cloud, err := p.cloudFactory.NewCloud(installation, directorID, stemcellApiVersion)
+		if err != nil {
+			return bosherr.WrapError(err, "Creating CPI client from CPI installation")
+		}
+
+		return fn(cloud)
+	})
+}
+
+// WithCPICmdRunner gives fn a runner for the installed CPI and the context
+// that a cloud for the environment would send with its calls
+func (p *envCloudProvider) WithCPICmdRunner(stage biui.Stage, fn func(bicloud.CPICmdRunner, bicloud.CmdContext) error) error {
+	return p.withInstallation(stage, func(installation biinstall.Installation, directorID string, stemcellApiVersion int) error {
+		cpiCmdRunner, err := p.cloudFactory.NewCPICmdRunner(installation)
+		if err != nil {
+			return bosherr.WrapError(err, "Creating CPI runner from CPI installation")
+		}
+
+		return fn(cpiCmdRunner, bicloud.NewCmdContext(directorID, stemcellApiVersion))
+	})
+}
+
+func (p *envCloudProvider) withInstallation(stage biui.Stage, fn func(biinstall.Installation, string, int) error) error {
+	deploymentState, err := p.deploymentStateService.Load()
+	if err != nil {
+		return bosherr.WrapError(err, "Loading deployment state")
+	}
+
+	target, err := p.targetProvider.NewTarget()
+	if err != nil {
+		return bosherr.WrapError(err, "Determining installation target")
+	}
+
+	err = p.tempRootConfigurator.PrepareAndSetTempRoot(target.TmpPath(), p.logger)
+	if err != nil {
+		return bosherr.WrapError(err, "Setting temp root")
+	}
+
+	defer func() {
+		err := p.releaseManager.DeleteAll()
+		if err != nil {
+			p.logger.Warn(p.logTag, "Deleting all extracted releases: %s", err.Error())
+		}
+	}()
+
+	var installationManifest biinstallmanifest.Manifest
+
+	err = stage.PerformComplex("validating", func(stage biui.Stage) error {
+		releaseSetManifest, manifest, err := p.releaseSetAndInstallationManifestParser.ReleaseSetAndInstallationManifest(p.deploymentManifestPath, p.deploymentVars, p.deploymentOp)
+		if err != nil {
+			return err
+		}
+
+		installationManifest = manifest
+
+		cpiReleaseName := installationManifest.Template.Release
+		cpiReleaseRef, found := releaseSetManifest.FindByName(cpiReleaseName)
+		if !found {
+			return bosherr.Errorf("installation release '%s' must refer to a release in releases", cpiReleaseName)
+		}
+
+		err = p.releaseFetcher.DownloadAndExtract(cpiReleaseRef, stage)
+		if err != nil {
+			return err
+		}
+
+		return p.cpiInstaller.ValidateCpiRelease(installationManifest, stage)
+	})
+	if err != nil {
+		return err
+	}
+
+	stemcellApiVersion := 1
+	for _, s := range deploymentState.Stemcells {
+		if deploymentState.CurrentStemcellID == s.ID {
+			stemcellApiVersion = s.ApiVersion
+			break
+		}
+	}
+
+	return p.cpiInstaller.WithInstalledCpiRelease(installationManifest, target, stage, func(installation biinstall.Installation) error {
+		return fn(installation, deploymentState.DirectorID, stemcellApiVersion)
+	})
+}
@@ -12,9 +12,12 @@ import (
 	biblobstore "github.com/cloudfoundry/bosh-cli/v7/blobstore"
 	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
 	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	biconformance "github.com/cloudfoundry/bosh-cli/v7/cpi/conformance"
 	bicpirel "github.com/cloudfoundry/bosh-cli/v7/cpi/release"
 	bidepl "github.com/cloudfoundry/bosh-cli/v7/deployment"
 	bidisk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk"
+	"github.com/cloudfoundry/bosh-cli/v7/deployment/dryrun"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
 	biinstance "github.com/cloudfoundry/bosh-cli/v7/deployment/instance"
 	biinstancestate "github.com/cloudfoundry/bosh-cli/v7/deployment/instance/state"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest"
@@ -33,6 +36,7 @@ import (
 	bistemcell "github.com/cloudfoundry/bosh-cli/v7/stemcell"
 	bitemplate "github.com/cloudfoundry/bosh-cli/v7/templatescompiler"
 	bitemplateerb "github.com/cloudfoundry/bosh-cli/v7/templatescompiler/erbrenderer"
+	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
 )
 
 type envFactory struct {
@@ -42,8 +46,10 @@ type envFactory struct {
 	manifestOp   patch.Op
 
 	deploymentStateService     biconfig.DeploymentStateService
+	keptDisksRepo              biconfig.KeptDisksRepo
 	installationManifestParser ReleaseSetAndInstallationManifestParser
 
+	tarballProvider bitarball.Provider
 	releaseManager  boshinst.ReleaseManager
 	releaseFetcher  boshinst.ReleaseFetcher
 	stemcellFetcher bistemcell.Fetcher
@@ -57,11 +63,61 @@ type envFactory struct {
 	stemcellManagerFactory bistemcell.ManagerFactory
 
 	instanceManagerFactory biinstance.ManagerFactory
+	hookRunner             bihook.Runner
 
 	agentClientFactory bihttpagent.AgentClientFactory
 	blobstoreFactory   biblobstore.Factory
 	deploymentFactory  bidepl.Factory
 	deploymentRecord   bidepl.Record
+	dryRunRecorder     *dryrun.Recorder
+}
+
+// workspaceRootPath keeps the downloaded tarballs and the installations of all environments
+func workspaceRootPath() string {
+	// todo expand path?
+	return filepath.Join(os.Getenv("HOME"), ".bosh")
+}
+
+func tarballCachePath(workspacePath string) string {
+	return filepath.Join(workspacePath, "downloads")
+}
+
+func installationsPath(workspacePath string) string {
+	return filepath.Join(workspacePath, "installations")
+}
+
+// compiledPackageDigestsPath keeps the digests of the compiled packages cache
+// outside of the cache unless the user pins a digests file
+func compiledPackageDigestsPath(workspacePath string, path string) string {
+	if path != "" {
+		return path
+	}
+	return filepath.Join(workspacePath, "compiled_package_digests.json")
+}
+
+// EnvFactoryOpts holds the settings of create-env and delete-env flags;
+// the zero value suits commands that only inspect an environment
+type EnvFactoryOpts struct {
+	RecreatePersistentDisks bool
+
+	CompileWorkers         int
+	CompiledPackagesCache  string
+	CompiledPackageDigests string
+
+	CPICallPolicies  bicloud.CallPolicies
+	CPICallRecording bicloud.CPICallRecording
+	Hooks            []bihook.Hook
+
+	// TarballProvider replaces downloading tarballs, e.g. with a bundle
+	TarballProvider bitarball.Provider
+
+	// DryRun keeps the deployment state in memory and records the CPI, agent,
+	// blobstore and hook calls that change the environment instead of making them
+	DryRun bool
+
+	// ReadOnlyState keeps changes to the deployment state in memory,
+	// e.g. the installation ID of commands that only inspect the environment
+	ReadOnlyState bool
 }
 
 func NewEnvFactory(
@@ -70,7 +126,7 @@ func NewEnvFactory(
 	statePath string,
 	manifestVars boshtpl.Variables,
 	manifestOp patch.Op,
-	recreatePersistentDisks bool,
+	opts EnvFactoryOpts,
 ) *envFactory {
 	f := envFactory{
 		deps:         deps,
@@ -82,15 +138,20 @@ func NewEnvFactory(
 	f.releaseManager = boshinst.NewReleaseManager(deps.Logger)
 	releaseJobResolver := bideplrel.NewJobResolver(f.releaseManager)
 
-	// todo expand path?
-	workspaceRootPath := filepath.Join(os.Getenv("HOME"), ".bosh")
+	workspacePath := workspaceRootPath()
 
 	{
-		tarballCacheBasePath := filepath.Join(workspaceRootPath, "downloads")
-		tarballCache := bitarball.NewCache(tarballCacheBasePath, deps.FS, deps.Logger)
-		httpClient := httpclient.NewHTTPClient(httpclient.CreateExternalDefaultClient(nil), deps.Logger)
-		tarballProvider := bitarball.NewProvider(
-			tarballCache, deps.FS, httpClient, 3, 500*time.Millisecond, deps.Logger)
+		tarballProvider := opts.TarballProvider
+
+		if tarballProvider == nil {
+			tarballCacheBasePath := tarballCachePath(workspacePath)
+			tarballCache := bitarball.NewCache(tarballCacheBasePath, deps.FS, deps.Logger)
+			httpClient := httpclient.NewHTTPClient(httpclient.CreateExternalDefaultClient(nil), deps.Logger)
+			tarballProvider = bitarball.NewProvider(
+				tarballCache, deps.FS, httpClient, 3, 500*time.Millisecond, biui.NewTarballsReporter(biui.NewIndentingUI(deps.UI)), deps.Logger)
+		}
+
+		f.tarballProvider = tarballProvider
 
 		releaseProvider := boshrel.NewProvider(
 			deps.CmdRunner, deps.Compressor, deps.DigestCalculator, deps.FS, deps.Logger)
@@ -110,13 +171,29 @@ func NewEnvFactory(
 		}
 	}
 
-	f.deploymentStateService = biconfig.NewFileSystemDeploymentStateService(
-		deps.FS, deps.UUIDGen, deps.Logger, biconfig.DeploymentStatePath(manifestPath, statePath))
+	f.deploymentStateService = biconfig.NewDeploymentStateService(
+		deps.FS,
+		httpclient.NewHTTPClient(httpclient.CreateExternalDefaultClient(nil), deps.Logger),
+		deps.UUIDGen,
+		deps.Logger,
+		biconfig.DeploymentStatePath(manifestPath, statePath),
+	)
+
+	f.keptDisksRepo = biconfig.NewKeptDisksRepo(deps.FS, biconfig.KeptDisksPath(manifestPath, statePath))
+
+	if opts.DryRun || opts.ReadOnlyState {
+		f.deploymentStateService = biconfig.NewDryRunDeploymentStateService(f.deploymentStateService, deps.UUIDGen)
+	}
+
+	if opts.DryRun {
+		f.dryRunRecorder = dryrun.NewRecorder()
+		f.keptDisksRepo = dryrun.NewKeptDisksRepo(f.keptDisksRepo)
+	}
 
 	{
 		installerFactory := boshinst.NewInstallerFactory(
 			deps.UI, deps.CmdRunner, deps.Compressor, releaseJobResolver,
-			deps.UUIDGen, deps.Logger, deps.FS, deps.DigestCreationAlgorithms)
+			deps.UUIDGen, deps.Logger, deps.FS, deps.DigestCreationAlgorithms, opts.CompileWorkers, opts.CompiledPackagesCache, compiledPackageDigestsPath(workspacePath, opts.CompiledPackageDigests))
 
 		f.cpiInstaller = bicpirel.CpiInstaller{
 			ReleaseManager:   f.releaseManager,
@@ -126,15 +203,16 @@ func NewEnvFactory(
 	}
 
 	f.targetProvider = boshinst.NewTargetProvider(
-		f.deploymentStateService, deps.UUIDGen, filepath.Join(workspaceRootPath, "installations"))
+		f.deploymentStateService, deps.UUIDGen, installationsPath(workspacePath), deps.FS)
+
+	diskRepo := biconfig.NewDiskRepo(f.deploymentStateService, deps.UUIDGen)
 
 	{
-		diskRepo := biconfig.NewDiskRepo(f.deploymentStateService, deps.UUIDGen)
 		stemcellRepo := biconfig.NewStemcellRepo(f.deploymentStateService, deps.UUIDGen)
 		vmRepo := biconfig.NewVMRepo(f.deploymentStateService)
 
 		f.diskManagerFactory = bidisk.NewManagerFactory(diskRepo, deps.Logger)
-		diskDeployer := bivm.NewDiskDeployer(f.diskManagerFactory, diskRepo, deps.Logger, recreatePersistentDisks)
+		diskDeployer := bivm.NewDiskDeployer(f.diskManagerFactory, diskRepo, deps.Logger, opts.RecreatePersistentDisks)
 
 		f.stemcellManagerFactory = bistemcell.NewManagerFactory(stemcellRepo)
 		f.vmManagerFactory = bivm.NewManagerFactory(
@@ -149,7 +227,40 @@ func NewEnvFactory(
 		f.blobstoreFactory = biblobstore.NewBlobstoreFactory(deps.UUIDGen, deps.FS, deps.Logger)
 		f.deploymentFactory = bidepl.NewFactory(10*time.Second, 500*time.Millisecond)
 		f.agentClientFactory = bihttpagent.NewAgentClientFactory(1*time.Second, deps.Logger)
-		f.cloudFactory = bicloud.NewFactory(deps.FS, deps.CmdRunner, deps.Logger)
+		f.cloudFactory = bicloud.NewFactory(deps.FS, deps.CmdRunner, deps.Time, opts.CPICallPolicies, opts.CPICallRecording, NewCPIRetryReporter(deps.UI), deps.Logger)
+
+		if opts.DryRun {
+			f.blobstoreFactory = dryrun.NewBlobstoreFactory(f.dryRunRecorder)
+			f.agentClientFactory = dryrun.NewAgentClientFactory(f.dryRunRecorder)
+			f.cloudFactory = dryrun.NewCloudFactory(f.cloudFactory, f.dryRunRecorder)
+		}
+	}
+
+	{
+		releaseSetValidator := birelsetmanifest.NewValidator(deps.Logger)
+		releaseSetParser := birelsetmanifest.NewParser(deps.FS, deps.Logger, releaseSetValidator)
+
+		installValidator := boshinstmanifest.NewValidator(deps.Logger)
+		installParser := boshinstmanifest.NewParser(deps.FS, deps.UUIDGen, deps.Logger, installValidator)
+
+		f.installationManifestParser = ReleaseSetAndInstallationManifestParser{
+			ReleaseSetParser:   releaseSetParser,
+			InstallationParser: installParser,
+		}
+	}
+
+	hookSource := envHookSource{
+		flagHooks:      opts.Hooks,
+		manifestParser: f.installationManifestParser,
+		manifestPath:   manifestPath,
+		manifestVars:   manifestVars,
+		manifestOp:     manifestOp,
+	}
+
+	if opts.DryRun {
+		f.hookRunner = dryrun.NewHookRunner(hookSource, f.dryRunRecorder)
+	} else {
+		f.hookRunner = bihook.NewRunner(hookSource, deps.CmdRunner, deps.Logger)
 	}
 
 	{
@@ -165,23 +276,10 @@ func NewEnvFactory(
 		)
 
 		sshTunnelFactory := bisshtunnel.NewFactory(deps.Logger)
-		instanceFactory := biinstance.NewFactory(builderFactory)
+		instanceFactory := biinstance.NewFactory(builderFactory, f.hookRunner, diskRepo)
 
 		f.instanceManagerFactory = biinstance.NewManagerFactory(
-			sshTunnelFactory, instanceFactory, deps.Logger)
-	}
-
-	{
-		releaseSetValidator := birelsetmanifest.NewValidator(deps.Logger)
-		releaseSetParser := birelsetmanifest.NewParser(deps.FS, deps.Logger, releaseSetValidator)
-
-		installValidator := boshinstmanifest.NewValidator(deps.Logger)
-		installParser := boshinstmanifest.NewParser(deps.FS, deps.UUIDGen, deps.Logger, installValidator)
-
-		f.installationManifestParser = ReleaseSetAndInstallationManifestParser{
-			ReleaseSetParser:   releaseSetParser,
-			InstallationParser: installParser,
-		}
+			sshTunnelFactory, instanceFactory, f.hookRunner, diskRepo, deps.Logger)
 	}
 
 	return &f
@@ -205,6 +303,10 @@ func (f *envFactory) Preparer() DeploymentPreparer {
 		f.stemcellManagerFactory,
 		f.agentClientFactory,
 		f.vmManagerFactory,
+		f.diskManagerFactory,
+		f.keptDisksRepo,
+		biconfig.NewManifestRepo(f.deploymentStateService),
+		f.hookRunner,
 		f.blobstoreFactory,
 		bidepl.NewDeployer(
 			f.vmManagerFactory,
@@ -216,6 +318,7 @@ func (f *envFactory) Preparer() DeploymentPreparer {
 		f.manifestVars,
 		f.manifestOp,
 		f.cpiInstaller,
+		f.tarballProvider,
 		f.releaseFetcher,
 		f.stemcellFetcher,
 		f.installationManifestParser,
@@ -227,6 +330,7 @@ func (f *envFactory) Preparer() DeploymentPreparer {
 		),
 		NewTempRootConfigurator(f.deps.FS),
 		f.targetProvider,
+		f.dryRunRecorder,
 	)
 }
 
@@ -256,6 +360,7 @@ func (f *envFactory) Deleter() DeploymentDeleter {
 		f.installationManifestParser,
 		NewTempRootConfigurator(f.deps.FS),
 		f.targetProvider,
+		f.keptDisksRepo,
 	)
 }
 
@@ -285,3 +390,81 @@ func (f *envFactory) StateManager() DeploymentStateManager {
 		),
 	)
 }
+
+func (f *envFactory) CPITester() CPITester {
+	return NewCPITester(
+		"CPITester",
+		f.deps.Logger,
+		f.CloudProvider(),
+		f.installationManifestParser,
+		NewDeploymentManifestParser(
+			bideplmanifest.NewParser(f.deps.FS, f.deps.Logger),
+			bideplmanifest.NewValidator(f.deps.Logger),
+			f.releaseManager,
+			bidepltpl.NewDeploymentTemplateFactory(f.deps.FS),
+		),
+		f.stemcellFetcher,
+		biconformance.NewSuite(f.deps.Time, f.deps.Logger),
+		f.deps.UUIDGen,
+		f.manifestPath,
+		f.manifestVars,
+		f.manifestOp,
+	)
+}
+
+func (f *envFactory) EnvChecker() EnvChecker {
+	return NewEnvChecker(
+		"EnvChecker",
+		f.deps.Logger,
+		f.deploymentStateService,
+		biconfig.NewVMRepo(f.deploymentStateService),
+		biconfig.NewDiskRepo(f.deploymentStateService, f.deps.UUIDGen),
+		f.CloudProvider(),
+		f.agentClientFactory,
+		f.installationManifestParser,
+		f.manifestPath,
+		f.manifestVars,
+		f.manifestOp,
+	)
+}
+
+func (f *envFactory) Bundler() EnvBundler {
+	return NewEnvBundler(
+		f.installationManifestParser,
+		NewDeploymentManifestParser(
+			bideplmanifest.NewParser(f.deps.FS, f.deps.Logger),
+			bideplmanifest.NewValidator(f.deps.Logger),
+			f.releaseManager,
+			bidepltpl.NewDeploymentTemplateFactory(f.deps.FS),
+		),
+		bidepltpl.NewDeploymentTemplateFactory(f.deps.FS),
+		bitarball.NewBundleWriter(
+			f.tarballProvider,
+			f.deps.FS,
+			f.deps.Compressor,
+			f.deps.DigestCalculator,
+			f.deps.Logger,
+		),
+		f.manifestPath,
+		f.manifestVars,
+		f.manifestOp,
+	)
+}
+
+func (f *envFactory) CloudProvider() EnvCloudProvider {
+	return NewEnvCloudProvider(
+		"EnvCloudProvider",
+		f.deps.Logger,
+		f.deploymentStateService,
+		f.releaseManager,
+		f.cloudFactory,
+		f.manifestPath,
+		f.manifestVars,
+		f.manifestOp,
+		f.cpiInstaller,
+		f.releaseFetcher,
+		f.installationManifestParser,
+		NewTempRootConfigurator(f.deps.FS),
+		f.targetProvider,
+	)
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"github.com/cppforlife/go-patch/patch"
+
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
+	boshtpl "github.com/cloudfoundry/bosh-cli/v7/director/template"
+)
+
+// envHookSource parses the installation manifest only when hooks are needed
+// because the manifest is not known yet when the env factory is built
+type envHookSource struct {
+	flagHooks      []bihook.Hook
+	manifestParser ReleaseSetAndInstallationManifestParser
+	manifestPath   string
+	manifestVars   boshtpl.Variables
+	manifestOp     patch.Op
+}
+
+// Hooks returns hooks from the manifest before hooks given with --hook
+func (s envHookSource) Hooks() ([]bihook.Hook, error) {
+	_, installationManifest, err := s.manifestParser.ReleaseSetAndInstallationManifest(s.manifestPath, s.manifestVars, s.manifestOp)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks := append([]bihook.Hook{}, installationManifest.Hooks...)
+
+	return append(hooks, s.flagHooks...), nil
+}
@@ -0,0 +1,65 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+type EnvManifestCmd struct {
+	ui                     boshui.UI
+	deploymentStateService biconfig.DeploymentStateService
+	manifestRepo           biconfig.ManifestRepo
+}
+
+func NewEnvManifestCmd(
+	ui boshui.UI,
+	deploymentStateService biconfig.DeploymentStateService,
+	manifestRepo biconfig.ManifestRepo,
+) EnvManifestCmd {
+	return EnvManifestCmd{ui: ui, deploymentStateService: deploymentStateService, manifestRepo: manifestRepo}
+}
+
+func (c EnvManifestCmd) Run(opts EnvManifestOpts) error {
+	manifest, err := storedEnvManifest(c.deploymentStateService, c.manifestRepo, opts.NoRedact, opts.ManifestKey)
+	if err != nil {
+		return err
+	}
+
+	c.ui.PrintBlock(manifest)
+
+	return nil
+}
+
+// storedEnvManifest returns the redacted manifest unless noRedact asks for the encrypted one
+func storedEnvManifest(
+	deploymentStateService biconfig.DeploymentStateService,
+	manifestRepo biconfig.ManifestRepo,
+	noRedact bool,
+	key string,
+) ([]byte, error) {
+	if !deploymentStateService.Exists() {
+		return nil, bosherr.Errorf("Expected deployment state '%s' to exist", deploymentStateService.Path())
+	}
+
+	if noRedact && key == "" {
+		return nil, bosherr.Error("Expected a manifest key to show variable values")
+	}
+
+	record, found, err := manifestRepo.FindCurrent()
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, bosherr.Errorf("Expected deployment state '%s' to contain a manifest; run create-env to store it", deploymentStateService.Path())
+	}
+
+	if noRedact {
+		return record.Decrypt(key)
+	}
+
+	return []byte(record.Redacted), nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	bidepltpl "github.com/cloudfoundry/bosh-cli/v7/deployment/template"
+	boshtpl "github.com/cloudfoundry/bosh-cli/v7/director/template"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+type EnvManifestDiffCmd struct {
+	ui                     boshui.UI
+	deploymentStateService biconfig.DeploymentStateService
+	manifestRepo           biconfig.ManifestRepo
+}
+
+func NewEnvManifestDiffCmd(
+	ui boshui.UI,
+	deploymentStateService biconfig.DeploymentStateService,
+	manifestRepo biconfig.ManifestRepo,
+) EnvManifestDiffCmd {
+	return EnvManifestDiffCmd{ui: ui, deploymentStateService: deploymentStateService, manifestRepo: manifestRepo}
+}
+
+// Run compares redacted manifests unless opts.NoRedact is set,
+// so that only changes to the structure of the manifest are shown by default
+func (c EnvManifestDiffCmd) Run(opts EnvManifestDiffOpts) error {
+	stored, err := storedEnvManifest(c.deploymentStateService, c.manifestRepo, opts.NoRedact, opts.ManifestKey)
+	if err != nil {
+		return err
+	}
+
+	var vars boshtpl.Variables = opts.VarFlags.AsVariables()
+	if !opts.NoRedact {
+		vars = boshtpl.NewRedactedVariables(vars)
+	}
+
+	proposed, err := bidepltpl.NewDeploymentTemplate(opts.Args.Manifest.Bytes).Evaluate(vars, opts.OpsFlags.AsOp())
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Evaluating manifest '%s'", opts.Args.Manifest.Path)
+	}
+
+	diff, err := NewManifestDiff(stored, proposed.Content())
+	if err != nil {
+		return err
+	}
+
+	if !diff.HasChanges() {
+		c.ui.PrintLinef("No differences")
+		return nil
+	}
+
+	diff.Print(c.ui)
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package cmd_test
+
+import (
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	boshtpl "github.com/cloudfoundry/bosh-cli/v7/director/template"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("EnvManifestDiffCmd", func() {
+	var (
+		ui      *fakeui.FakeUI
+		command cmd.EnvManifestDiffCmd
+	)
+
+	diffOpts := func(manifest string, vars ...boshtpl.VarKV) opts.EnvManifestDiffOpts {
+		return opts.EnvManifestDiffOpts{
+			Args:     opts.EnvManifestDiffArgs{Manifest: opts.FileBytesWithPathArg{Path: "/manifest.yml", Bytes: []byte(manifest)}},
+			VarFlags: opts.VarFlags{VarKVs: vars},
+		}
+	}
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		fs := fakesys.NewFakeFileSystem()
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		deploymentStateService := biconfig.NewFileSystemDeploymentStateService(fs, fakeuuid.NewFakeGenerator(), logger, "/state.json")
+		manifestRepo := biconfig.NewManifestRepo(deploymentStateService)
+		command = cmd.NewEnvManifestDiffCmd(ui, deploymentStateService, manifestRepo)
+
+		err := deploymentStateService.Save(biconfig.DeploymentState{DirectorID: "fake-director-id"})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = manifestRepo.UpdateCurrent(
+			[]byte("name: director\npassword: <redacted>\n"),
+			[]byte("name: director\npassword: secret\n"),
+			"fake-key",
+		)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("Run", func() {
+		It("shows differences with the redacted proposed manifest", func() {
+			err := command.Run(diffOpts("name: new-director\npassword: ((password))\n", boshtpl.VarKV{Name: "password", Value: "other-secret"}))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Said).To(Equal([]string{
+				"- name: director\n",
+				"+ name: new-director\n",
+			}))
+		})
+
+		It("does not show changed variable values by default", func() {
+			err := command.Run(diffOpts("name: director\npassword: ((password))\n", boshtpl.VarKV{Name: "password", Value: "other-secret"}))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Said).To(Equal([]string{"No differences"}))
+		})
+
+		It("shows changed variable values given the key", func() {
+			opts := diffOpts("name: director\npassword: ((password))\n", boshtpl.VarKV{Name: "password", Value: "other-secret"})
+			opts.NoRedact = true
+			opts.ManifestKey = "fake-key"
+
+			err := command.Run(opts)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Said).To(Equal([]string{
+				"- password: secret\n",
+				"+ password: other-secret\n",
+			}))
+		})
+
+		It("returns an error when the proposed manifest cannot be interpolated", func() {
+			err := command.Run(diffOpts("password: ((password))\n"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Evaluating manifest '/manifest.yml'"))
+		})
+	})
+})
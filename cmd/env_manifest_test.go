@@ -0,0 +1,84 @@
+package cmd_test
+
+import (
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("EnvManifestCmd", func() {
+	var (
+		ui                     *fakeui.FakeUI
+		deploymentStateService biconfig.DeploymentStateService
+		manifestRepo           biconfig.ManifestRepo
+		command                cmd.EnvManifestCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		fs := fakesys.NewFakeFileSystem()
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		deploymentStateService = biconfig.NewFileSystemDeploymentStateService(fs, fakeuuid.NewFakeGenerator(), logger, "/state.json")
+		manifestRepo = biconfig.NewManifestRepo(deploymentStateService)
+		command = cmd.NewEnvManifestCmd(ui, deploymentStateService, manifestRepo)
+
+		err := deploymentStateService.Save(biconfig.DeploymentState{DirectorID: "fake-director-id"})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("Run", func() {
+		Context("when create-env stored the manifest", func() {
+			BeforeEach(func() {
+				err := manifestRepo.UpdateCurrent([]byte("password: <redacted>\n"), []byte("password: secret\n"), "fake-key")
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("shows the redacted manifest", func() {
+				err := command.Run(opts.EnvManifestOpts{StatePath: "/state.json"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(ui.Blocks).To(Equal([]string{"password: <redacted>\n"}))
+			})
+
+			It("shows the manifest with variable values given the key", func() {
+				err := command.Run(opts.EnvManifestOpts{StatePath: "/state.json", NoRedact: true, ManifestKey: "fake-key"})
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(ui.Blocks).To(Equal([]string{"password: secret\n"}))
+			})
+
+			It("requires a key to show variable values", func() {
+				err := command.Run(opts.EnvManifestOpts{StatePath: "/state.json", NoRedact: true})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Expected a manifest key to show variable values"))
+			})
+
+			It("returns an error for a wrong key", func() {
+				err := command.Run(opts.EnvManifestOpts{StatePath: "/state.json", NoRedact: true, ManifestKey: "other-key"})
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Decrypting manifest failed; check the manifest key"))
+			})
+		})
+
+		It("returns an error when no manifest is stored", func() {
+			err := command.Run(opts.EnvManifestOpts{StatePath: "/state.json"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected deployment state '/state.json' to contain a manifest; run create-env to store it"))
+		})
+
+		It("returns an error when the state does not exist", func() {
+			command = cmd.NewEnvManifestCmd(ui, biconfig.NewFileSystemDeploymentStateService(fakesys.NewFakeFileSystem(), fakeuuid.NewFakeGenerator(), boshlog.NewLogger(boshlog.LevelNone), "/other.json"), manifestRepo)
+
+			err := command.Run(opts.EnvManifestOpts{StatePath: "/other.json"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected deployment state '/other.json' to exist"))
+		})
+	})
+})
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+type EnvStateDiffCmd struct {
+	ui      boshui.UI
+	fs      boshsys.FileSystem
+	history biconfig.DeploymentStateHistory
+}
+
+func NewEnvStateDiffCmd(ui boshui.UI, fs boshsys.FileSystem, history biconfig.DeploymentStateHistory) EnvStateDiffCmd {
+	return EnvStateDiffCmd{ui: ui, fs: fs, history: history}
+}
+
+func (c EnvStateDiffCmd) Run(opts EnvStateDiffOpts) error {
+	err := checkLocalEnvState(opts.StatePath)
+	if err != nil {
+		return err
+	}
+
+	fromContents, err := c.versionContents(opts.Args.From)
+	if err != nil {
+		return err
+	}
+
+	var toContents []byte
+
+	if opts.Args.To == 0 {
+		toContents, err = c.fs.ReadFile(opts.StatePath)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Reading deployment state file '%s'", opts.StatePath)
+		}
+	} else {
+		toContents, err = c.versionContents(opts.Args.To)
+		if err != nil {
+			return err
+		}
+	}
+
+	diff := NewLineDiff(string(fromContents), string(toContents))
+
+	if !diff.HasChanges() {
+		c.ui.PrintLinef("No differences")
+		return nil
+	}
+
+	diff.Print(c.ui)
+
+	return nil
+}
+
+func (c EnvStateDiffCmd) versionContents(versionNum int) ([]byte, error) {
+	version, found, err := c.history.Find(versionNum)
+	if err != nil {
+		return nil, err
+	}
+
+	if !found {
+		return nil, bosherr.Errorf("Expected to find version %d of the environment state", versionNum)
+	}
+
+	contents, _, err := c.history.Read(version)
+
+	return contents, err
+}
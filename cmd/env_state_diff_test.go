@@ -0,0 +1,86 @@
+package cmd_test
+
+import (
+	"time"
+
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("EnvStateDiffCmd", func() {
+	var (
+		ui      *fakeui.FakeUI
+		fs      *fakesys.FakeFileSystem
+		history biconfig.DeploymentStateHistory
+		command cmd.EnvStateDiffCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		fs = fakesys.NewFakeFileSystem()
+		history = biconfig.NewDeploymentStateHistory(fs, "/state.json", biconfig.DeploymentStateHistoryLimit)
+		command = cmd.NewEnvStateDiffCmd(ui, fs, history)
+
+		err := history.Add([]byte("{\n  \"current_vm_cid\": \"vm-cid-1\",\n  \"director_id\": \"dir-id\"\n}\n"), time.Now())
+		Expect(err).ToNot(HaveOccurred())
+
+		err = history.Add([]byte("{\n  \"current_vm_cid\": \"vm-cid-2\",\n  \"director_id\": \"dir-id\"\n}\n"), time.Now())
+		Expect(err).ToNot(HaveOccurred())
+
+		err = fs.WriteFileString("/state.json", "{\n  \"current_vm_cid\": \"vm-cid-3\",\n  \"director_id\": \"dir-id\"\n}\n")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("Run", func() {
+		It("shows differences between two versions", func() {
+			err := command.Run(opts.EnvStateDiffOpts{
+				Args:      opts.EnvStateDiffArgs{From: 1, To: 2},
+				StatePath: "/state.json",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Said).To(Equal([]string{
+				"  {\n",
+				"-   \"current_vm_cid\": \"vm-cid-1\",\n",
+				"+   \"current_vm_cid\": \"vm-cid-2\",\n",
+				"    \"director_id\": \"dir-id\"\n",
+				"  }\n",
+			}))
+		})
+
+		It("compares with the current state when no second version is given", func() {
+			err := command.Run(opts.EnvStateDiffOpts{
+				Args:      opts.EnvStateDiffArgs{From: 2},
+				StatePath: "/state.json",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Said).To(ContainElement("+   \"current_vm_cid\": \"vm-cid-3\",\n"))
+		})
+
+		It("says when there are no differences", func() {
+			err := command.Run(opts.EnvStateDiffOpts{
+				Args:      opts.EnvStateDiffArgs{From: 1, To: 1},
+				StatePath: "/state.json",
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Said).To(Equal([]string{"No differences"}))
+		})
+
+		It("returns an error for unknown versions", func() {
+			err := command.Run(opts.EnvStateDiffOpts{
+				Args:      opts.EnvStateDiffArgs{From: 42},
+				StatePath: "/state.json",
+			})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected to find version 42"))
+		})
+	})
+})
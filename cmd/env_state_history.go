@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+type EnvStateHistoryCmd struct {
+	ui      boshui.UI
+	history biconfig.DeploymentStateHistory
+}
+
+func NewEnvStateHistoryCmd(ui boshui.UI, history biconfig.DeploymentStateHistory) EnvStateHistoryCmd {
+	return EnvStateHistoryCmd{ui: ui, history: history}
+}
+
+func (c EnvStateHistoryCmd) Run(opts EnvStateHistoryOpts) error {
+	err := checkLocalEnvState(opts.StatePath)
+	if err != nil {
+		return err
+	}
+
+	versions, err := c.history.List()
+	if err != nil {
+		return err
+	}
+
+	table := boshtbl.Table{
+		Content: "versions",
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Version"),
+			boshtbl.NewHeader("Saved at"),
+			boshtbl.NewHeader("VM CID"),
+			boshtbl.NewHeader("Disk CIDs"),
+			boshtbl.NewHeader("Stemcell CIDs"),
+		},
+		SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: false}},
+	}
+
+	for _, version := range versions {
+		_, deploymentState, err := c.history.Read(version)
+		if err != nil {
+			return err
+		}
+
+		var diskCIDs, stemcellCIDs []string
+
+		for _, disk := range deploymentState.Disks {
+			diskCIDs = append(diskCIDs, disk.CID)
+		}
+
+		for _, stemcell := range deploymentState.Stemcells {
+			stemcellCIDs = append(stemcellCIDs, stemcell.CID)
+		}
+
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueInt(version.Version),
+			boshtbl.NewValueTime(version.SavedAt),
+			boshtbl.NewValueString(deploymentState.CurrentVMCID),
+			boshtbl.NewValueStrings(diskCIDs),
+			boshtbl.NewValueStrings(stemcellCIDs),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	return nil
+}
+
+// checkLocalEnvState fails for remote state since versions are only kept next to local state files
+func checkLocalEnvState(statePath string) error {
+	if biconfig.IsRemoteDeploymentStatePath(statePath) {
+		return bosherr.Error("Versions of the environment state are only kept for local state files")
+	}
+
+	return nil
+}
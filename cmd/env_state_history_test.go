@@ -0,0 +1,70 @@
+package cmd_test
+
+import (
+	"time"
+
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+var _ = Describe("EnvStateHistoryCmd", func() {
+	var (
+		ui      *fakeui.FakeUI
+		fs      *fakesys.FakeFileSystem
+		history biconfig.DeploymentStateHistory
+		command cmd.EnvStateHistoryCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		fs = fakesys.NewFakeFileSystem()
+		history = biconfig.NewDeploymentStateHistory(fs, "/state.json", biconfig.DeploymentStateHistoryLimit)
+		command = cmd.NewEnvStateHistoryCmd(ui, history)
+	})
+
+	Describe("Run", func() {
+		act := func() error { return command.Run(opts.EnvStateHistoryOpts{StatePath: "/state.json"}) }
+
+		It("lists saved versions with their VM, disk and stemcell CIDs", func() {
+			err := history.Add([]byte(`{"current_vm_cid": "vm-cid-1", "disks": [{"cid": "disk-cid-1"}], "stemcells": [{"cid": "stemcell-cid-1"}]}`), time.Date(2024, time.March, 1, 10, 30, 0, 0, time.UTC))
+			Expect(err).ToNot(HaveOccurred())
+
+			err = act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Table).To(Equal(boshtbl.Table{
+				Content: "versions",
+				Header: []boshtbl.Header{
+					boshtbl.NewHeader("Version"),
+					boshtbl.NewHeader("Saved at"),
+					boshtbl.NewHeader("VM CID"),
+					boshtbl.NewHeader("Disk CIDs"),
+					boshtbl.NewHeader("Stemcell CIDs"),
+				},
+				SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: false}},
+				Rows: [][]boshtbl.Value{
+					{
+						boshtbl.NewValueInt(1),
+						boshtbl.NewValueTime(time.Date(2024, time.March, 1, 10, 30, 0, 0, time.UTC)),
+						boshtbl.NewValueString("vm-cid-1"),
+						boshtbl.NewValueStrings([]string{"disk-cid-1"}),
+						boshtbl.NewValueStrings([]string{"stemcell-cid-1"}),
+					},
+				},
+			}))
+		})
+
+		It("returns an error for remote state", func() {
+			err := command.Run(opts.EnvStateHistoryOpts{StatePath: "https://example.com/state.json"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("only kept for local state files"))
+		})
+	})
+})
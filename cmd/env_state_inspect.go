@@ -0,0 +1,196 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+type EnvStateInspectCmd struct {
+	ui                     boshui.UI
+	deploymentStateService biconfig.DeploymentStateService
+	stemcellRepo           biconfig.StemcellRepo
+	diskRepo               biconfig.DiskRepo
+	vmRepo                 biconfig.VMRepo
+	releaseRepo            biconfig.ReleaseRepo
+}
+
+func NewEnvStateInspectCmd(
+	ui boshui.UI,
+	deploymentStateService biconfig.DeploymentStateService,
+	stemcellRepo biconfig.StemcellRepo,
+	diskRepo biconfig.DiskRepo,
+	vmRepo biconfig.VMRepo,
+	releaseRepo biconfig.ReleaseRepo,
+) EnvStateInspectCmd {
+	return EnvStateInspectCmd{
+		ui:                     ui,
+		deploymentStateService: deploymentStateService,
+		stemcellRepo:           stemcellRepo,
+		diskRepo:               diskRepo,
+		vmRepo:                 vmRepo,
+		releaseRepo:            releaseRepo,
+	}
+}
+
+func (c EnvStateInspectCmd) Run() error {
+	if !c.deploymentStateService.Exists() {
+		return bosherr.Errorf("Expected deployment state '%s' to exist", c.deploymentStateService.Path())
+	}
+
+	deploymentState, err := c.deploymentStateService.Load()
+	if err != nil {
+		return bosherr.WrapError(err, "Loading deployment state")
+	}
+
+	vmCID, _, err := c.vmRepo.FindCurrent()
+	if err != nil {
+		return err
+	}
+
+	c.ui.PrintTable(boshtbl.Table{
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Director ID"),
+			boshtbl.NewHeader("Installation ID"),
+			boshtbl.NewHeader("Current VM CID"),
+			boshtbl.NewHeader("Current manifest SHA"),
+		},
+		Rows: [][]boshtbl.Value{
+			{
+				boshtbl.NewValueString(deploymentState.DirectorID),
+				boshtbl.NewValueString(deploymentState.InstallationID),
+				boshtbl.NewValueString(vmCID),
+				boshtbl.NewValueString(deploymentState.CurrentManifestSHA),
+			},
+		},
+		Transpose: true,
+	})
+
+	err = c.printDisks()
+	if err != nil {
+		return err
+	}
+
+	err = c.printStemcells()
+	if err != nil {
+		return err
+	}
+
+	return c.printReleases(deploymentState.CurrentReleaseIDs)
+}
+
+func (c EnvStateInspectCmd) printDisks() error {
+	disks, err := c.diskRepo.All()
+	if err != nil {
+		return err
+	}
+
+	currentDisks, err := c.diskRepo.FindCurrent()
+	if err != nil {
+		return err
+	}
+
+	currentDiskIDs := map[string]bool{}
+	for _, currentDisk := range currentDisks {
+		currentDiskIDs[currentDisk.ID] = true
+	}
+
+	table := boshtbl.Table{
+		Content: "disks",
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("ID"),
+			boshtbl.NewHeader("Name"),
+			boshtbl.NewHeader("CID"),
+			boshtbl.NewHeader("Size"),
+			boshtbl.NewHeader("Current"),
+		},
+	}
+
+	for _, disk := range disks {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(disk.ID),
+			boshtbl.NewValueString(disk.Name),
+			boshtbl.NewValueString(disk.CID),
+			boshtbl.NewValueMegaBytes(uint64(disk.Size)),
+			boshtbl.NewValueBool(currentDiskIDs[disk.ID]),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	return nil
+}
+
+func (c EnvStateInspectCmd) printStemcells() error {
+	stemcells, err := c.stemcellRepo.All()
+	if err != nil {
+		return err
+	}
+
+	currentStemcell, _, err := c.stemcellRepo.FindCurrent()
+	if err != nil {
+		return err
+	}
+
+	table := boshtbl.Table{
+		Content: "stemcells",
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("ID"),
+			boshtbl.NewHeader("Name"),
+			boshtbl.NewHeader("Version"),
+			boshtbl.NewHeader("CID"),
+			boshtbl.NewHeader("Current"),
+		},
+	}
+
+	for _, stemcell := range stemcells {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(stemcell.ID),
+			boshtbl.NewValueString(stemcell.Name),
+			boshtbl.NewValueString(stemcell.Version),
+			boshtbl.NewValueString(stemcell.CID),
+			boshtbl.NewValueBool(stemcell.ID == currentStemcell.ID),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	return nil
+}
+
+func (c EnvStateInspectCmd) printReleases(currentReleaseIDs []string) error {
+	releases, err := c.releaseRepo.List()
+	if err != nil {
+		return err
+	}
+
+	current := map[string]bool{}
+	for _, id := range currentReleaseIDs {
+		current[id] = true
+	}
+
+	table := boshtbl.Table{
+		Content: "releases",
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("ID"),
+			boshtbl.NewHeader("Name"),
+			boshtbl.NewHeader("Version"),
+			boshtbl.NewHeader("Current"),
+		},
+	}
+
+	for _, release := range releases {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(release.ID),
+			boshtbl.NewValueString(release.Name),
+			boshtbl.NewValueString(release.Version),
+			boshtbl.NewValueBool(current[release.ID]),
+		})
+	}
+
+	c.ui.PrintTable(table)
+
+	return nil
+}
@@ -0,0 +1,99 @@
+package cmd_test
+
+import (
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+var _ = Describe("EnvStateInspectCmd", func() {
+	var (
+		ui                     *fakeui.FakeUI
+		deploymentStateService biconfig.DeploymentStateService
+		command                cmd.EnvStateInspectCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		fs := fakesys.NewFakeFileSystem()
+		uuidGenerator := fakeuuid.NewFakeGenerator()
+		deploymentStateService = biconfig.NewFileSystemDeploymentStateService(fs, uuidGenerator, boshlog.NewLogger(boshlog.LevelNone), "/state.json")
+
+		command = cmd.NewEnvStateInspectCmd(
+			ui,
+			deploymentStateService,
+			biconfig.NewStemcellRepo(deploymentStateService, uuidGenerator),
+			biconfig.NewDiskRepo(deploymentStateService, uuidGenerator),
+			biconfig.NewVMRepo(deploymentStateService),
+			biconfig.NewReleaseRepo(deploymentStateService, uuidGenerator),
+		)
+	})
+
+	Describe("Run", func() {
+		It("prints the state and its records", func() {
+			err := deploymentStateService.Save(biconfig.DeploymentState{
+				DirectorID:        "fake-director-id",
+				InstallationID:    "fake-installation-id",
+				CurrentVMCID:      "fake-vm-cid",
+				CurrentDiskIDs:    []string{"fake-disk-id"},
+				CurrentStemcellID: "fake-stemcell-id",
+				CurrentReleaseIDs: []string{"fake-release-id"},
+				Disks: []biconfig.DiskRecord{
+					{ID: "fake-disk-id", Name: "data", CID: "fake-disk-cid", Size: 1024},
+					{ID: "old-disk-id", CID: "old-disk-cid", Size: 1024},
+				},
+				Stemcells: []biconfig.StemcellRecord{
+					{ID: "old-stemcell-id", Name: "fake-stemcell", Version: "1", CID: "old-stemcell-cid"},
+					{ID: "fake-stemcell-id", Name: "fake-stemcell", Version: "2", CID: "fake-stemcell-cid"},
+				},
+				Releases: []biconfig.ReleaseRecord{{ID: "fake-release-id", Name: "fake-release", Version: "1"}},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = command.Run()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Tables).To(HaveLen(4))
+			Expect(ui.Tables[0].Rows[0][2]).To(Equal(boshtbl.NewValueString("fake-vm-cid")))
+
+			Expect(ui.Tables[1].Content).To(Equal("disks"))
+			Expect(ui.Tables[1].Rows).To(Equal([][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("fake-disk-id"),
+					boshtbl.NewValueString("data"),
+					boshtbl.NewValueString("fake-disk-cid"),
+					boshtbl.NewValueMegaBytes(1024),
+					boshtbl.NewValueBool(true),
+				},
+				{
+					boshtbl.NewValueString("old-disk-id"),
+					boshtbl.NewValueString(""),
+					boshtbl.NewValueString("old-disk-cid"),
+					boshtbl.NewValueMegaBytes(1024),
+					boshtbl.NewValueBool(false),
+				},
+			}))
+
+			Expect(ui.Tables[2].Content).To(Equal("stemcells"))
+			Expect(ui.Tables[2].Rows).To(HaveLen(2))
+			Expect(ui.Tables[2].Rows[0][4]).To(Equal(boshtbl.NewValueBool(false)))
+			Expect(ui.Tables[2].Rows[1][4]).To(Equal(boshtbl.NewValueBool(true)))
+
+			Expect(ui.Tables[3].Content).To(Equal("releases"))
+			Expect(ui.Tables[3].Rows[0][3]).To(Equal(boshtbl.NewValueBool(true)))
+		})
+
+		It("returns an error when the state does not exist", func() {
+			err := command.Run()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected deployment state '/state.json' to exist"))
+		})
+	})
+})
@@ -0,0 +1,84 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	bidepl "github.com/cloudfoundry/bosh-cli/v7/deployment"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+type EnvStateRepairCmd struct {
+	ui                     boshui.UI
+	deploymentStateService biconfig.DeploymentStateService
+	stateChecker           bidepl.StateChecker
+	cloudProvider          EnvCloudProvider
+}
+
+func NewEnvStateRepairCmd(
+	ui boshui.UI,
+	deploymentStateService biconfig.DeploymentStateService,
+	stateChecker bidepl.StateChecker,
+	cloudProvider EnvCloudProvider,
+) EnvStateRepairCmd {
+	return EnvStateRepairCmd{
+		ui:                     ui,
+		deploymentStateService: deploymentStateService,
+		stateChecker:           stateChecker,
+		cloudProvider:          cloudProvider,
+	}
+}
+
+func (c EnvStateRepairCmd) Run(stage boshui.Stage) (err error) {
+	err = c.deploymentStateService.Lock()
+	if err != nil {
+		return bosherr.WrapError(err, "Locking deployment state")
+	}
+	defer func() {
+		unlockErr := c.deploymentStateService.Unlock()
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	problems, err := checkEnvState(stage, c.deploymentStateService, c.stateChecker, c.cloudProvider)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		c.ui.PrintLinef("No problems found")
+		return nil
+	}
+
+	printEnvStateProblems(c.ui, problems)
+
+	var repairable []bidepl.StateProblem
+	for _, problem := range problems {
+		if problem.Repairable() {
+			repairable = append(repairable, problem)
+		}
+	}
+
+	if len(repairable) == 0 {
+		return bosherr.Errorf("Found %d problem(s) that cannot be repaired automatically", len(problems))
+	}
+
+	err = c.ui.AskForConfirmation()
+	if err != nil {
+		return err
+	}
+
+	err = c.stateChecker.Repair(repairable)
+	if err != nil {
+		return err
+	}
+
+	c.ui.PrintLinef("Repaired %d problem(s)", len(repairable))
+
+	if len(repairable) < len(problems) {
+		return bosherr.Errorf("Found %d problem(s) that cannot be repaired automatically", len(problems)-len(repairable))
+	}
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package cmd_test
+
+import (
+	"errors"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	bidepl "github.com/cloudfoundry/bosh-cli/v7/deployment"
+	fakebiui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("EnvStateRepairCmd", func() {
+	var (
+		ui                     *fakebiui.FakeUI
+		stage                  *fakebiui.FakeStage
+		deploymentStateService biconfig.DeploymentStateService
+		command                cmd.EnvStateRepairCmd
+	)
+
+	BeforeEach(func() {
+		ui = &fakebiui.FakeUI{}
+		stage = fakebiui.NewFakeStage()
+		fs := fakesys.NewFakeFileSystem()
+		uuidGenerator := fakeuuid.NewFakeGenerator()
+		deploymentStateService = biconfig.NewFileSystemDeploymentStateService(fs, uuidGenerator, boshlog.NewLogger(boshlog.LevelNone), "/state.json")
+
+		stateChecker := bidepl.NewStateChecker(
+			deploymentStateService,
+			biconfig.NewStemcellRepo(deploymentStateService, uuidGenerator),
+			biconfig.NewDiskRepo(deploymentStateService, uuidGenerator),
+			biconfig.NewVMRepo(deploymentStateService),
+			biconfig.NewReleaseRepo(deploymentStateService, uuidGenerator),
+		)
+
+		command = cmd.NewEnvStateRepairCmd(ui, deploymentStateService, stateChecker, nil)
+
+		err := deploymentStateService.Save(biconfig.DeploymentState{
+			DirectorID:        "fake-director-id",
+			CurrentDiskID:     "missing-disk-id",
+			CurrentStemcellID: "fake-stemcell-id",
+			Stemcells: []biconfig.StemcellRecord{
+				{ID: "fake-stemcell-id", Name: "fake-stemcell", Version: "1", CID: "fake-stemcell-cid"},
+			},
+		})
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	Describe("Run", func() {
+		It("repairs problems after confirmation", func() {
+			err := command.Run(stage)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Said).To(ContainElement("Repaired 1 problem(s)"))
+
+			deploymentState, err := deploymentStateService.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.CurrentDiskID).To(BeEmpty())
+			Expect(deploymentState.CurrentStemcellID).To(Equal("fake-stemcell-id"))
+		})
+
+		It("does not repair if confirmation is rejected", func() {
+			ui.AskedConfirmationErr = errors.New("stop")
+
+			err := command.Run(stage)
+			Expect(err).To(HaveOccurred())
+
+			deploymentState, err := deploymentStateService.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.CurrentDiskID).To(Equal("missing-disk-id"))
+		})
+
+		It("fails for problems that cannot be repaired", func() {
+			err := deploymentStateService.Save(biconfig.DeploymentState{
+				DirectorID:        "fake-director-id",
+				CurrentReleaseIDs: []string{"missing-release-id"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = command.Run(stage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("cannot be repaired automatically"))
+			Expect(ui.AskedConfirmationCalled).To(BeFalse())
+		})
+	})
+})
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	boshuifmt "github.com/cloudfoundry/bosh-cli/v7/ui/fmt"
+)
+
+type EnvStateRestoreCmd struct {
+	ui                     boshui.UI
+	history                biconfig.DeploymentStateHistory
+	deploymentStateService biconfig.DeploymentStateService
+}
+
+func NewEnvStateRestoreCmd(
+	ui boshui.UI,
+	history biconfig.DeploymentStateHistory,
+	deploymentStateService biconfig.DeploymentStateService,
+) EnvStateRestoreCmd {
+	return EnvStateRestoreCmd{
+		ui:                     ui,
+		history:                history,
+		deploymentStateService: deploymentStateService,
+	}
+}
+
+func (c EnvStateRestoreCmd) Run(opts EnvStateRestoreOpts) (err error) {
+	err = checkLocalEnvState(opts.StatePath)
+	if err != nil {
+		return err
+	}
+
+	err = c.deploymentStateService.Lock()
+	if err != nil {
+		return bosherr.WrapError(err, "Locking deployment state")
+	}
+	defer func() {
+		unlockErr := c.deploymentStateService.Unlock()
+		if err == nil {
+			err = unlockErr
+		}
+	}()
+
+	version, found, err := c.history.Find(opts.Args.Version)
+	if err != nil {
+		return err
+	}
+
+	if !found {
+		return bosherr.Errorf("Expected to find version %d of the environment state", opts.Args.Version)
+	}
+
+	_, deploymentState, err := c.history.Read(version)
+	if err != nil {
+		return err
+	}
+
+	c.ui.PrintLinef("Restoring version %d saved at %s to '%s'", version.Version, version.SavedAt.Format(boshuifmt.TimeFullFmt), c.deploymentStateService.Path())
+	c.ui.PrintLinef("The current state will be kept as a new version")
+
+	err = c.ui.AskForConfirmation()
+	if err != nil {
+		return err
+	}
+
+	err = c.deploymentStateService.Save(deploymentState)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Restoring version %d of the environment state", version.Version)
+	}
+
+	return nil
+}
@@ -0,0 +1,86 @@
+package cmd_test
+
+import (
+	"errors"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("EnvStateRestoreCmd", func() {
+	var (
+		ui                     *fakeui.FakeUI
+		fs                     *fakesys.FakeFileSystem
+		history                biconfig.DeploymentStateHistory
+		deploymentStateService biconfig.DeploymentStateService
+		command                cmd.EnvStateRestoreCmd
+		restoreOpts            opts.EnvStateRestoreOpts
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		fs = fakesys.NewFakeFileSystem()
+		history = biconfig.NewDeploymentStateHistory(fs, "/state.json", biconfig.DeploymentStateHistoryLimit)
+
+		// each run of a command keeps at most one version
+		for _, vmCID := range []string{"good-vm-cid", "broken-vm-cid"} {
+			previousRunService := biconfig.NewFileSystemDeploymentStateService(fs, fakeuuid.NewFakeGenerator(), boshlog.NewLogger(boshlog.LevelNone), "/state.json")
+			err := previousRunService.Save(biconfig.DeploymentState{DirectorID: "dir-id", CurrentVMCID: vmCID})
+			Expect(err).ToNot(HaveOccurred())
+		}
+
+		deploymentStateService = biconfig.NewFileSystemDeploymentStateService(fs, fakeuuid.NewFakeGenerator(), boshlog.NewLogger(boshlog.LevelNone), "/state.json")
+		command = cmd.NewEnvStateRestoreCmd(ui, history, deploymentStateService)
+
+		restoreOpts = opts.EnvStateRestoreOpts{
+			Args:      opts.EnvStateRestoreArgs{Version: 1},
+			StatePath: "/state.json",
+		}
+	})
+
+	Describe("Run", func() {
+		It("restores the version and keeps the replaced state as a new version", func() {
+			err := command.Run(restoreOpts)
+			Expect(err).ToNot(HaveOccurred())
+
+			deploymentState, err := deploymentStateService.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.CurrentVMCID).To(Equal("good-vm-cid"))
+
+			version, found, err := history.Find(2)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			_, replacedState, err := history.Read(version)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(replacedState.CurrentVMCID).To(Equal("broken-vm-cid"))
+		})
+
+		It("does not restore if confirmation is rejected", func() {
+			ui.AskedConfirmationErr = errors.New("stop")
+
+			err := command.Run(restoreOpts)
+			Expect(err).To(HaveOccurred())
+
+			deploymentState, err := deploymentStateService.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.CurrentVMCID).To(Equal("broken-vm-cid"))
+		})
+
+		It("returns an error for unknown versions", func() {
+			restoreOpts.Args.Version = 42
+
+			err := command.Run(restoreOpts)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected to find version 42"))
+		})
+	})
+})
@@ -0,0 +1,38 @@
+package cmd
+
+import (
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	boshuifmt "github.com/cloudfoundry/bosh-cli/v7/ui/fmt"
+)
+
+type EnvStateUnlockCmd struct {
+	ui                     boshui.UI
+	deploymentStateService biconfig.DeploymentStateService
+}
+
+// NewEnvStateUnlockCmd removes a lock left behind by a command
+// that did not finish, e.g. because it was killed
+func NewEnvStateUnlockCmd(ui boshui.UI, deploymentStateService biconfig.DeploymentStateService) EnvStateUnlockCmd {
+	return EnvStateUnlockCmd{ui: ui, deploymentStateService: deploymentStateService}
+}
+
+func (c EnvStateUnlockCmd) Run(opts EnvStateUnlockOpts) error {
+	lock, removed, err := c.deploymentStateService.BreakLock(opts.Force)
+	if err != nil {
+		return err
+	}
+
+	if !removed {
+		c.ui.PrintLinef("Deployment state '%s' is not locked", c.deploymentStateService.Path())
+		return nil
+	}
+
+	c.ui.PrintLinef(
+		"Removed lock '%s' of '%s' taken at %s",
+		lock.ID, lock.Owner, lock.CreatedAt.Format(boshuifmt.TimeFullFmt),
+	)
+
+	return nil
+}
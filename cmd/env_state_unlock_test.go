@@ -0,0 +1,115 @@
+package cmd_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	"github.com/cloudfoundry/bosh-utils/httpclient"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+	"github.com/onsi/gomega/ghttp"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	fakebiui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("EnvStateUnlockCmd", func() {
+	var (
+		server  *ghttp.Server
+		ui      *fakebiui.FakeUI
+		command cmd.EnvStateUnlockCmd
+	)
+
+	BeforeEach(func() {
+		server = ghttp.NewServer()
+		ui = &fakebiui.FakeUI{}
+
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		httpClient := httpclient.NewHTTPClient(http.DefaultClient, logger)
+		deploymentStateService := biconfig.NewHTTPDeploymentStateService(httpClient, fakeuuid.NewFakeGenerator(), clock.NewClock(), logger, server.URL()+"/state.json")
+
+		command = cmd.NewEnvStateUnlockCmd(ui, deploymentStateService)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	lockJSON := func(expiresAt time.Time) []byte {
+		lock, err := json.Marshal(biconfig.DeploymentStateLock{
+			ID:        "fake-lock-id",
+			Owner:     "fake-user@fake-host (pid 1)",
+			CreatedAt: time.Date(2026, time.January, 2, 3, 4, 5, 0, time.UTC),
+			ExpiresAt: expiresAt,
+		})
+		Expect(err).ToNot(HaveOccurred())
+		return lock
+	}
+
+	It("removes an expired lock", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/state.json.lock"),
+				ghttp.RespondWith(http.StatusOK, lockJSON(time.Now().Add(-time.Minute))),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("DELETE", "/state.json.lock"),
+				ghttp.RespondWith(http.StatusNoContent, nil),
+			),
+		)
+
+		err := command.Run(opts.EnvStateUnlockOpts{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ui.Said).To(ContainElement("Removed lock 'fake-lock-id' of 'fake-user@fake-host (pid 1)' taken at Fri Jan  2 03:04:05 UTC 2026"))
+	})
+
+	It("refuses to remove an unexpired lock without --force", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/state.json.lock"),
+				ghttp.RespondWith(http.StatusOK, lockJSON(time.Now().Add(time.Hour))),
+			),
+		)
+
+		err := command.Run(opts.EnvStateUnlockOpts{})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Use --force"))
+		Expect(server.ReceivedRequests()).To(HaveLen(1))
+	})
+
+	It("removes an unexpired lock with --force", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/state.json.lock"),
+				ghttp.RespondWith(http.StatusOK, lockJSON(time.Now().Add(time.Hour))),
+			),
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("DELETE", "/state.json.lock"),
+				ghttp.RespondWith(http.StatusNoContent, nil),
+			),
+		)
+
+		err := command.Run(opts.EnvStateUnlockOpts{Force: true})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ui.Said).To(ContainElement(ContainSubstring("Removed lock 'fake-lock-id'")))
+	})
+
+	It("reports that the state is not locked", func() {
+		server.AppendHandlers(
+			ghttp.CombineHandlers(
+				ghttp.VerifyRequest("GET", "/state.json.lock"),
+				ghttp.RespondWith(http.StatusNotFound, nil),
+			),
+		)
+
+		err := command.Run(opts.EnvStateUnlockOpts{})
+		Expect(err).ToNot(HaveOccurred())
+		Expect(ui.Said).To(ContainElement("Deployment state '" + server.URL() + "/state.json' is not locked"))
+	})
+})
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	bidepl "github.com/cloudfoundry/bosh-cli/v7/deployment"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+type EnvStateValidateCmd struct {
+	ui                     boshui.UI
+	deploymentStateService biconfig.DeploymentStateService
+	stateChecker           bidepl.StateChecker
+	cloudProvider          EnvCloudProvider
+}
+
+// NewEnvStateValidateCmd takes an optional cloud provider
+// which is used to check the existence of the current VM and the recorded disks
+func NewEnvStateValidateCmd(
+	ui boshui.UI,
+	deploymentStateService biconfig.DeploymentStateService,
+	stateChecker bidepl.StateChecker,
+	cloudProvider EnvCloudProvider,
+) EnvStateValidateCmd {
+	return EnvStateValidateCmd{
+		ui:                     ui,
+		deploymentStateService: deploymentStateService,
+		stateChecker:           stateChecker,
+		cloudProvider:          cloudProvider,
+	}
+}
+
+func (c EnvStateValidateCmd) Run(stage boshui.Stage) error {
+	problems, err := checkEnvState(stage, c.deploymentStateService, c.stateChecker, c.cloudProvider)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) == 0 {
+		c.ui.PrintLinef("No problems found")
+		return nil
+	}
+
+	printEnvStateProblems(c.ui, problems)
+
+	return bosherr.Errorf("Found %d problem(s) in deployment state '%s'", len(problems), c.deploymentStateService.Path())
+}
+
+func checkEnvState(
+	stage boshui.Stage,
+	deploymentStateService biconfig.DeploymentStateService,
+	stateChecker bidepl.StateChecker,
+	cloudProvider EnvCloudProvider,
+) ([]bidepl.StateProblem, error) {
+	if !deploymentStateService.Exists() {
+		return nil, bosherr.Errorf("Expected deployment state '%s' to exist", deploymentStateService.Path())
+	}
+
+	if cloudProvider == nil {
+		return stateChecker.Check(nil)
+	}
+
+	var problems []bidepl.StateProblem
+
+	err := cloudProvider.WithCloud(stage, func(cloud bicloud.Cloud) error {
+		var err error
+		problems, err = stateChecker.Check(bicloud.NewReadOnlyCloud(cloud))
+		return err
+	})
+
+	return problems, err
+}
+
+func printEnvStateProblems(ui boshui.UI, problems []bidepl.StateProblem) {
+	table := boshtbl.Table{
+		Content: "problems",
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Resource"),
+			boshtbl.NewHeader("ID"),
+			boshtbl.NewHeader("Problem"),
+			boshtbl.NewHeader("Repair"),
+		},
+	}
+
+	for _, problem := range problems {
+		table.Rows = append(table.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(problem.Resource),
+			boshtbl.NewValueString(problem.ID),
+			boshtbl.NewValueString(problem.Description),
+			boshtbl.NewValueString(problem.Repair),
+		})
+	}
+
+	ui.PrintTable(table)
+}
@@ -0,0 +1,119 @@
+package cmd_test
+
+import (
+	"errors"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	fakebicloud "github.com/cloudfoundry/bosh-cli/v7/cloud/fakes"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/cmdfakes"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	bidepl "github.com/cloudfoundry/bosh-cli/v7/deployment"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	fakebiui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+var _ = Describe("EnvStateValidateCmd", func() {
+	var (
+		ui                     *fakebiui.FakeUI
+		stage                  *fakebiui.FakeStage
+		deploymentStateService biconfig.DeploymentStateService
+		stateChecker           bidepl.StateChecker
+	)
+
+	BeforeEach(func() {
+		ui = &fakebiui.FakeUI{}
+		stage = fakebiui.NewFakeStage()
+		fs := fakesys.NewFakeFileSystem()
+		uuidGenerator := fakeuuid.NewFakeGenerator()
+		deploymentStateService = biconfig.NewFileSystemDeploymentStateService(fs, uuidGenerator, boshlog.NewLogger(boshlog.LevelNone), "/state.json")
+
+		stateChecker = bidepl.NewStateChecker(
+			deploymentStateService,
+			biconfig.NewStemcellRepo(deploymentStateService, uuidGenerator),
+			biconfig.NewDiskRepo(deploymentStateService, uuidGenerator),
+			biconfig.NewVMRepo(deploymentStateService),
+			biconfig.NewReleaseRepo(deploymentStateService, uuidGenerator),
+		)
+	})
+
+	Describe("Run", func() {
+		It("says when there are no problems", func() {
+			err := deploymentStateService.Save(biconfig.DeploymentState{DirectorID: "fake-director-id"})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = cmd.NewEnvStateValidateCmd(ui, deploymentStateService, stateChecker, nil).Run(stage)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(ui.Said).To(Equal([]string{"No problems found"}))
+		})
+
+		It("prints problems and fails", func() {
+			err := deploymentStateService.Save(biconfig.DeploymentState{DirectorID: "fake-director-id", CurrentDiskID: "missing-disk-id"})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = cmd.NewEnvStateValidateCmd(ui, deploymentStateService, stateChecker, nil).Run(stage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Found 1 problem(s)"))
+
+			Expect(ui.Table.Content).To(Equal("problems"))
+			Expect(ui.Table.Rows).To(Equal([][]boshtbl.Value{
+				{
+					boshtbl.NewValueString("disk"),
+					boshtbl.NewValueString("missing-disk-id"),
+					boshtbl.NewValueString("Current disk does not reference a disk record"),
+					boshtbl.NewValueString("Clear current disk"),
+				},
+			}))
+		})
+
+		Context("when a cloud provider is given", func() {
+			var (
+				cloudProvider *cmdfakes.FakeEnvCloudProvider
+				fakeCloud     *fakebicloud.FakeCloud
+			)
+
+			BeforeEach(func() {
+				err := deploymentStateService.Save(biconfig.DeploymentState{DirectorID: "fake-director-id", CurrentVMCID: "fake-vm-cid"})
+				Expect(err).ToNot(HaveOccurred())
+
+				fakeCloud = fakebicloud.NewFakeCloud()
+				cloudProvider = &cmdfakes.FakeEnvCloudProvider{}
+				cloudProvider.WithCloudStub = func(_ boshui.Stage, fn func(bicloud.Cloud) error) error {
+					return fn(fakeCloud)
+				}
+			})
+
+			It("asks the cloud whether the current VM exists", func() {
+				fakeCloud.HasVMFound = false
+
+				err := cmd.NewEnvStateValidateCmd(ui, deploymentStateService, stateChecker, cloudProvider).Run(stage)
+				Expect(err).To(HaveOccurred())
+
+				Expect(fakeCloud.HasVMInput).To(Equal(fakebicloud.HasVMInput{VMCID: "fake-vm-cid"}))
+				Expect(ui.Table.Rows[0][2]).To(Equal(boshtbl.NewValueString("Current VM does not exist in the IaaS")))
+			})
+
+			It("returns an error when the CPI cannot be installed", func() {
+				cloudProvider.WithCloudReturns(errors.New("fake-install-error"))
+				cloudProvider.WithCloudStub = nil
+
+				err := cmd.NewEnvStateValidateCmd(ui, deploymentStateService, stateChecker, cloudProvider).Run(stage)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-install-error"))
+			})
+		})
+
+		It("returns an error when the state does not exist", func() {
+			err := cmd.NewEnvStateValidateCmd(ui, deploymentStateService, stateChecker, nil).Run(stage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("to exist"))
+		})
+	})
+})
@@ -245,6 +245,32 @@ var _ = Describe("Factory", func() {
 		})
 	})
 
+	Describe("env-state command", func() {
+		It("dispatches to its subcommands", func() {
+			cmd, err := factory.New([]string{"env-state", "diff", "1", "2", "--state", "/state.json"})
+			Expect(err).ToNot(HaveOccurred())
+
+			diffOpts := cmd.Opts.(*opts.EnvStateDiffOpts)
+			Expect(diffOpts.Args).To(Equal(opts.EnvStateDiffArgs{From: 1, To: 2}))
+			Expect(diffOpts.StatePath).To(Equal("/state.json"))
+		})
+
+		It("parses unlock --force", func() {
+			cmd, err := factory.New([]string{"env-state", "unlock", "--state", "https://bucket/state.json", "--force"})
+			Expect(err).ToNot(HaveOccurred())
+
+			unlockOpts := cmd.Opts.(*opts.EnvStateUnlockOpts)
+			Expect(unlockOpts.StatePath).To(Equal("https://bucket/state.json"))
+			Expect(unlockOpts.Force).To(BeTrue())
+		})
+
+		It("requires --state", func() {
+			_, err := factory.New([]string{"env-state", "history"})
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("--state"))
+		})
+	})
+
 	Describe("alias-env command", func() {
 		It("is passed global environment URL", func() {
 			cmd, err := factory.New([]string{"alias-env", "-e", "env", "alias"})
@@ -382,6 +408,7 @@ var _ = Describe("Factory", func() {
 			boshOpts.RemoveBlob = opts.RemoveBlobOpts{}
 			boshOpts.SyncBlobs = opts.SyncBlobsOpts{}
 			boshOpts.UploadBlobs = opts.UploadBlobsOpts{}
+			boshOpts.CreateEnv = opts.CreateEnvOpts{}
 			boshOpts.Pcap = opts.PcapOpts{}
 			boshOpts.SSH = opts.SSHOpts{}
 			boshOpts.SCP = opts.SCPOpts{}
@@ -394,6 +421,8 @@ var _ = Describe("Factory", func() {
 			boshOpts.UpdateConfig = opts.UpdateConfigOpts{}
 			boshOpts.DeleteConfig = opts.DeleteConfigOpts{}
 			boshOpts.Curl = opts.CurlOpts{}
+			boshOpts.Task = opts.TaskOpts{}
+			boshOpts.Tasks = opts.TasksOpts{}
 			return boshOpts
 		}
 
@@ -403,8 +432,9 @@ var _ = Describe("Factory", func() {
 
 			// Check against entire BoshOpts to avoid future missing assertions
 			Expect(clearNonGlobalOpts(cmd.BoshOpts)).To(Equal(opts.BoshOpts{
-				ConfigPathOpt: "~/.bosh/config",
-				Parallel:      5,
+				ConfigPathOpt:      "~/.bosh/config",
+				Parallel:           5,
+				OnTaskInterruptOpt: "detach",
 			}))
 		})
 
@@ -417,10 +447,13 @@ var _ = Describe("Factory", func() {
 				"--client-secret", "client-secret",
 				"--deployment", "dep",
 				"--json",
+				"--ndjson",
 				"--tty",
 				"--no-color",
 				"--non-interactive",
 				"--parallel", "123",
+				"--no-track",
+				"--on-task-interrupt", "cancel",
 				"locks",
 			}
 
@@ -428,17 +461,20 @@ var _ = Describe("Factory", func() {
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(clearNonGlobalOpts(cmd.BoshOpts)).To(Equal(opts.BoshOpts{
-				ConfigPathOpt:     "config",
-				EnvironmentOpt:    "env",
-				CACertOpt:         opts.CACertArg{Content: "BEGIN ca-cert"},
-				ClientOpt:         "client",
-				ClientSecretOpt:   "client-secret",
-				DeploymentOpt:     "dep",
-				JSONOpt:           true,
-				TTYOpt:            true,
-				NoColorOpt:        true,
-				NonInteractiveOpt: true,
-				Parallel:          123,
+				ConfigPathOpt:      "config",
+				EnvironmentOpt:     "env",
+				CACertOpt:          opts.CACertArg{Content: "BEGIN ca-cert"},
+				ClientOpt:          "client",
+				ClientSecretOpt:    "client-secret",
+				DeploymentOpt:      "dep",
+				JSONOpt:            true,
+				NDJSONOpt:          true,
+				TTYOpt:             true,
+				NoColorOpt:         true,
+				NonInteractiveOpt:  true,
+				Parallel:           123,
+				NoTrackOpt:         true,
+				OnTaskInterruptOpt: "cancel",
 			}))
 		})
 
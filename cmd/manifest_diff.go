@@ -0,0 +1,305 @@
+package cmd
+
+import (
+	"bytes"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"gopkg.in/yaml.v3"
+)
+
+// NewManifestDiff compares two YAML manifests by structure like the director
+// does for 'bosh deploy': only changed sections are shown, below the keys and
+// named array entries that lead to them
+func NewManifestDiff(from, to []byte) (Diff, error) {
+	fromNode, err := manifestDiffRoot(from)
+	if err != nil {
+		return Diff{}, bosherr.WrapError(err, "Parsing previous manifest")
+	}
+
+	toNode, err := manifestDiffRoot(to)
+	if err != nil {
+		return Diff{}, bosherr.WrapError(err, "Parsing proposed manifest")
+	}
+
+	d := &manifestDiff{}
+
+	err = d.node(fromNode, toNode, "")
+	if err != nil {
+		return Diff{}, err
+	}
+
+	return NewDiff(d.lines), nil
+}
+
+type manifestDiff struct {
+	lines [][]interface{}
+}
+
+func manifestDiffRoot(contents []byte) (*yaml.Node, error) {
+	var doc yaml.Node
+
+	err := yaml.Unmarshal(contents, &doc)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(doc.Content) == 0 {
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}, nil
+	}
+
+	return doc.Content[0], nil
+}
+
+func (d *manifestDiff) node(from, to *yaml.Node, indent string) error {
+	switch {
+	case from.Kind == yaml.MappingNode && to.Kind == yaml.MappingNode:
+		return d.mapping(from, to, indent)
+	case from.Kind == yaml.SequenceNode && to.Kind == yaml.SequenceNode:
+		return d.sequence(from, to, indent)
+	default:
+		err := d.block(nil, from, indent, "removed")
+		if err != nil {
+			return err
+		}
+		return d.block(nil, to, indent, "added")
+	}
+}
+
+func (d *manifestDiff) mapping(from, to *yaml.Node, indent string) error {
+	for i := 0; i+1 < len(to.Content); i += 2 {
+		key, toValue := to.Content[i], to.Content[i+1]
+
+		fromValue := mappingValue(from, key.Value)
+		if fromValue == nil {
+			err := d.block(key, toValue, indent, "added")
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		if manifestNodesEqual(fromValue, toValue) {
+			continue
+		}
+
+		if fromValue.Kind == toValue.Kind && (toValue.Kind == yaml.MappingNode || toValue.Kind == yaml.SequenceNode) {
+			d.line(indent+key.Value+":", nil)
+
+			err := d.node(fromValue, toValue, indent+"  ")
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		err := d.block(key, fromValue, indent, "removed")
+		if err != nil {
+			return err
+		}
+
+		err = d.block(key, toValue, indent, "added")
+		if err != nil {
+			return err
+		}
+	}
+
+	for i := 0; i+1 < len(from.Content); i += 2 {
+		key := from.Content[i]
+
+		if mappingValue(to, key.Value) == nil {
+			err := d.block(key, from.Content[i+1], indent, "removed")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// sequence matches entries that have a name by their name
+// and all other entries by their contents
+func (d *manifestDiff) sequence(from, to *yaml.Node, indent string) error {
+	matched := map[*yaml.Node]bool{}
+
+	for _, toEntry := range to.Content {
+		fromEntry := matchingEntry(from, toEntry)
+		if fromEntry == nil {
+			err := d.entry(toEntry, indent, "added")
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		matched[fromEntry] = true
+
+		if manifestNodesEqual(fromEntry, toEntry) {
+			continue
+		}
+
+		d.line(indent+"- name: "+mappingValue(toEntry, "name").Value, nil)
+
+		err := d.mapping(withoutName(fromEntry), withoutName(toEntry), indent+"  ")
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, fromEntry := range from.Content {
+		if !matched[fromEntry] && matchingEntry(to, fromEntry) == nil {
+			err := d.entry(fromEntry, indent, "removed")
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+func (d *manifestDiff) block(key, value *yaml.Node, indent string, mod string) error {
+	node := value
+	if key != nil {
+		node = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map", Content: []*yaml.Node{key, value}}
+	}
+
+	return d.render(node, indent, mod)
+}
+
+func (d *manifestDiff) entry(entry *yaml.Node, indent string, mod string) error {
+	return d.render(&yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq", Content: []*yaml.Node{entry}}, indent, mod)
+}
+
+func (d *manifestDiff) render(node *yaml.Node, indent string, mod string) error {
+	var buf bytes.Buffer
+
+	node = withBlockStyle(node)
+
+	encoder := yaml.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	err := encoder.Encode(node)
+	if err != nil {
+		return bosherr.WrapError(err, "Rendering manifest diff")
+	}
+
+	err = encoder.Close()
+	if err != nil {
+		return bosherr.WrapError(err, "Rendering manifest diff")
+	}
+
+	for _, line := range splitDiffLines(buf.String()) {
+		d.line(indent+line, mod)
+	}
+
+	return nil
+}
+
+func (d *manifestDiff) line(text string, mod interface{}) {
+	d.lines = append(d.lines, []interface{}{text, mod})
+}
+
+// withBlockStyle copies the node so that sections written
+// in flow style (e.g. '{a: 1}') are shown line by line
+func withBlockStyle(node *yaml.Node) *yaml.Node {
+	result := *node
+	result.Style &^= yaml.FlowStyle
+	result.Content = nil
+
+	for _, child := range node.Content {
+		result.Content = append(result.Content, withBlockStyle(child))
+	}
+
+	return &result
+}
+
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+
+	return nil
+}
+
+// matchingEntry returns the entry of the sequence with the same name
+// or, for entries without a name, the same contents
+func matchingEntry(sequence, entry *yaml.Node) *yaml.Node {
+	name := entryName(entry)
+
+	for _, candidate := range sequence.Content {
+		if len(name) > 0 {
+			if entryName(candidate) == name {
+				return candidate
+			}
+		} else if manifestNodesEqual(candidate, entry) {
+			return candidate
+		}
+	}
+
+	return nil
+}
+
+func entryName(entry *yaml.Node) string {
+	name := mappingValue(entry, "name")
+	if name == nil || name.Kind != yaml.ScalarNode {
+		return ""
+	}
+
+	return name.Value
+}
+
+func withoutName(entry *yaml.Node) *yaml.Node {
+	result := &yaml.Node{Kind: yaml.MappingNode, Tag: entry.Tag}
+
+	for i := 0; i+1 < len(entry.Content); i += 2 {
+		if entry.Content[i].Value != "name" {
+			result.Content = append(result.Content, entry.Content[i], entry.Content[i+1])
+		}
+	}
+
+	return result
+}
+
+func manifestNodesEqual(a, b *yaml.Node) bool {
+	if a.Kind == yaml.AliasNode {
+		return manifestNodesEqual(a.Alias, b)
+	}
+
+	if b.Kind == yaml.AliasNode {
+		return manifestNodesEqual(a, b.Alias)
+	}
+
+	if a.Kind != b.Kind || a.ShortTag() != b.ShortTag() || len(a.Content) != len(b.Content) {
+		return false
+	}
+
+	switch a.Kind {
+	case yaml.ScalarNode:
+		return a.Value == b.Value
+
+	case yaml.MappingNode:
+		// Keys may be reordered without changing the manifest
+		for i := 0; i+1 < len(a.Content); i += 2 {
+			value := mappingValue(b, a.Content[i].Value)
+			if value == nil || !manifestNodesEqual(a.Content[i+1], value) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for i := range a.Content {
+		if !manifestNodesEqual(a.Content[i], b.Content[i]) {
+			return false
+		}
+	}
+
+	return true
+}
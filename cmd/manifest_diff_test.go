@@ -0,0 +1,105 @@
+package cmd_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd"
+)
+
+var _ = Describe("NewManifestDiff", func() {
+	diff := func(from, to string) string {
+		d, err := NewManifestDiff([]byte(from), []byte(to))
+		Expect(err).ToNot(HaveOccurred())
+		return d.String()
+	}
+
+	It("shows no changes for manifests that only order keys differently", func() {
+		d, err := NewManifestDiff([]byte("a: 1\nb: {c: 2, d: 3}\n"), []byte("b:\n  d: 3\n  c: 2\na: 1\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(d.HasChanges()).To(BeFalse())
+	})
+
+	It("shows changed values below the keys that lead to them", func() {
+		Expect(diff(`
+name: director
+cloud_provider:
+  mbus: https://mbus
+  properties:
+    agent: {mbus: https://mbus}
+    ntp: [0.pool.ntp.org]
+`, `
+name: director
+cloud_provider:
+  mbus: https://mbus
+  properties:
+    agent: {mbus: https://other-mbus}
+    ntp: [0.pool.ntp.org]
+`)).To(Equal(`  cloud_provider:
+    properties:
+      agent:
+-       mbus: https://mbus
++       mbus: https://other-mbus
+`))
+	})
+
+	It("matches array entries by name", func() {
+		Expect(diff(`
+instance_groups:
+- name: bosh
+  instances: 1
+  jobs: [{name: director}]
+- name: removed
+  instances: 1
+`, `
+instance_groups:
+- name: added
+  instances: 1
+- name: bosh
+  instances: 2
+  jobs: [{name: director}]
+`)).To(Equal(`  instance_groups:
++   - name: added
++     instances: 1
+    - name: bosh
+-     instances: 1
++     instances: 2
+-   - name: removed
+-     instances: 1
+`))
+	})
+
+	It("shows added and removed entries of arrays without names", func() {
+		Expect(diff("ntp: [a, b]\n", "ntp: [b, c]\n")).To(Equal(`  ntp:
++   - c
+-   - a
+`))
+	})
+
+	It("shows added and removed sections as a whole", func() {
+		Expect(diff("name: director\nold: {a: 1}\n", "name: director\nnew: {b: [1, 2]}\n")).To(Equal(`+ new:
++   b:
++     - 1
++     - 2
+- old:
+-   a: 1
+`))
+	})
+
+	It("shows values whose type changed as removed and added", func() {
+		Expect(diff("disk: 1024\n", "disk: {size: 1024}\n")).To(Equal(`- disk: 1024
++ disk:
++   size: 1024
+`))
+	})
+
+	It("shows everything as added compared with an empty manifest", func() {
+		Expect(diff("", "name: director\n")).To(Equal("+ name: director\n"))
+	})
+
+	It("returns an error when a manifest cannot be parsed", func() {
+		_, err := NewManifestDiff([]byte("name: director\n"), []byte("name: [\n"))
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Parsing proposed manifest"))
+	})
+})
@@ -35,17 +35,17 @@ func (m *MockDeploymentDeleter) EXPECT() *MockDeploymentDeleterMockRecorder {
 }
 
 // DeleteDeployment mocks base method.
-func (m *MockDeploymentDeleter) DeleteDeployment(arg0 bool, arg1 ui.Stage) error {
+func (m *MockDeploymentDeleter) DeleteDeployment(arg0, arg1 bool, arg2 ui.Stage) error {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "DeleteDeployment", arg0, arg1)
+	ret := m.ctrl.Call(m, "DeleteDeployment", arg0, arg1, arg2)
 	ret0, _ := ret[0].(error)
 	return ret0
 }
 
 // DeleteDeployment indicates an expected call of DeleteDeployment.
-func (mr *MockDeploymentDeleterMockRecorder) DeleteDeployment(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockDeploymentDeleterMockRecorder) DeleteDeployment(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeployment", reflect.TypeOf((*MockDeploymentDeleter)(nil).DeleteDeployment), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteDeployment", reflect.TypeOf((*MockDeploymentDeleter)(nil).DeleteDeployment), arg0, arg1, arg2)
 }
 
 // MockDeploymentStateManager is a mock of DeploymentStateManager interface.
@@ -0,0 +1,96 @@
+package opts
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+)
+
+// Shared
+type CPICallFlags struct {
+	CPITimeouts []CPIMethodDurationArg `long:"cpi-timeout" value-name:"[METHOD=]DURATION" description:"Terminate CPI calls that run longer (e.g. '30m', 'create_vm=1h' or 'create_vm=0' for no timeout)"`
+	CPIRetries  []CPIMethodCountArg    `long:"cpi-retries" value-name:"[METHOD=]COUNT"    description:"Retry CPI calls that fail with retryable errors (e.g. '3' or 'attach_disk=5')"`
+
+	RecordCPICalls string `long:"record-cpi-calls" value-name:"PATH" description:"Append every CPI call and its response to this JSON Lines file (defaults to $BOSH_CPI_RECORD_PATH)"`
+	ReplayCPICalls string `long:"replay-cpi-calls" value-name:"PATH" description:"Answer CPI calls from this recording instead of running the CPI (defaults to $BOSH_CPI_REPLAY_PATH)"`
+}
+
+// AsCallPolicies returns the policies set by the flags;
+// they override the cpi_calls of the installation manifest
+func (f CPICallFlags) AsCallPolicies() bicloud.CallPolicies {
+	policies := bicloud.CallPolicies{}
+
+	for _, arg := range f.CPITimeouts {
+		duration := arg.Duration
+		policy := policies[arg.Method]
+		policy.Timeout = &duration
+		policies[arg.Method] = policy
+	}
+
+	for _, arg := range f.CPIRetries {
+		count := arg.Count
+		policy := policies[arg.Method]
+		policy.Retries = &count
+		policies[arg.Method] = policy
+	}
+
+	return policies
+}
+
+// AsCallRecording returns the recording files set by the flags
+func (f CPICallFlags) AsCallRecording() bicloud.CPICallRecording {
+	return bicloud.CPICallRecording{RecordPath: f.RecordCPICalls, ReplayPath: f.ReplayCPICalls}
+}
+
+type CPIMethodDurationArg struct {
+	Method   string
+	Duration time.Duration
+}
+
+func (a *CPIMethodDurationArg) UnmarshalFlag(data string) error {
+	method, value := splitCPIMethodArg(data)
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Expected '%s' to be in format '[method=]duration'", data)
+	}
+
+	if duration < 0 {
+		return bosherr.Errorf("Expected '%s' to not specify a negative duration", data)
+	}
+
+	*a = CPIMethodDurationArg{Method: method, Duration: duration}
+
+	return nil
+}
+
+type CPIMethodCountArg struct {
+	Method string
+	Count  int
+}
+
+func (a *CPIMethodCountArg) UnmarshalFlag(data string) error {
+	method, value := splitCPIMethodArg(data)
+
+	count, err := strconv.Atoi(value)
+	if err != nil || count < 0 {
+		return bosherr.Errorf("Expected '%s' to be in format '[method=]count'", data)
+	}
+
+	*a = CPIMethodCountArg{Method: method, Count: count}
+
+	return nil
+}
+
+func splitCPIMethodArg(data string) (string, string) {
+	pieces := strings.SplitN(data, "=", 2)
+	if len(pieces) != 2 || pieces[0] == "" {
+		return bicloud.DefaultCallPolicyKey, data
+	}
+
+	return pieces[0], pieces[1]
+}
@@ -0,0 +1,119 @@
+package opts_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+)
+
+var _ = Describe("CPICallFlags", func() {
+	Describe("AsCallPolicies", func() {
+		It("combines timeouts and retries by method", func() {
+			flags := CPICallFlags{
+				CPITimeouts: []CPIMethodDurationArg{
+					{Method: "default", Duration: 30 * time.Minute},
+					{Method: "create_vm", Duration: time.Hour},
+				},
+				CPIRetries: []CPIMethodCountArg{
+					{Method: "create_vm", Count: 3},
+				},
+			}
+
+			thirtyMinutes, oneHour, three := 30*time.Minute, time.Hour, 3
+
+			Expect(flags.AsCallPolicies()).To(Equal(bicloud.CallPolicies{
+				"default":   {Timeout: &thirtyMinutes},
+				"create_vm": {Timeout: &oneHour, Retries: &three},
+			}))
+		})
+
+		It("sets zero retries so that they override other policies", func() {
+			flags := CPICallFlags{CPIRetries: []CPIMethodCountArg{{Method: "create_vm", Count: 0}}}
+
+			policies := bicloud.CallPolicies{"create_vm": {Retries: new(int)}}
+			Expect(flags.AsCallPolicies()).To(Equal(policies))
+		})
+	})
+
+	Describe("AsCallRecording", func() {
+		It("returns the record and replay paths", func() {
+			flags := CPICallFlags{RecordCPICalls: "/record.jsonl", ReplayCPICalls: "/replay.jsonl"}
+
+			Expect(flags.AsCallRecording()).To(Equal(bicloud.CPICallRecording{
+				RecordPath: "/record.jsonl",
+				ReplayPath: "/replay.jsonl",
+			}))
+		})
+	})
+})
+
+var _ = Describe("CPIMethodDurationArg", func() {
+	Describe("UnmarshalFlag", func() {
+		var (
+			arg *CPIMethodDurationArg
+		)
+
+		BeforeEach(func() {
+			arg = &CPIMethodDurationArg{}
+		})
+
+		It("applies a duration without a method to all methods", func() {
+			Expect(arg.UnmarshalFlag("30m")).To(Succeed())
+			Expect(*arg).To(Equal(CPIMethodDurationArg{Method: "default", Duration: 30 * time.Minute}))
+		})
+
+		It("applies a duration with a method to that method", func() {
+			Expect(arg.UnmarshalFlag("create_vm=1h")).To(Succeed())
+			Expect(*arg).To(Equal(CPIMethodDurationArg{Method: "create_vm", Duration: time.Hour}))
+		})
+
+		It("returns an error when the duration cannot be parsed", func() {
+			err := arg.UnmarshalFlag("create_vm=forever")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected 'create_vm=forever' to be in format '[method=]duration'"))
+		})
+
+		It("accepts a zero duration to turn off the timeout", func() {
+			Expect(arg.UnmarshalFlag("create_vm=0")).To(Succeed())
+			Expect(*arg).To(Equal(CPIMethodDurationArg{Method: "create_vm", Duration: 0}))
+		})
+
+		It("returns an error when the duration is negative", func() {
+			err := arg.UnmarshalFlag("-1s")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected '-1s' to not specify a negative duration"))
+		})
+	})
+})
+
+var _ = Describe("CPIMethodCountArg", func() {
+	Describe("UnmarshalFlag", func() {
+		var (
+			arg *CPIMethodCountArg
+		)
+
+		BeforeEach(func() {
+			arg = &CPIMethodCountArg{}
+		})
+
+		It("applies a count without a method to all methods", func() {
+			Expect(arg.UnmarshalFlag("3")).To(Succeed())
+			Expect(*arg).To(Equal(CPIMethodCountArg{Method: "default", Count: 3}))
+		})
+
+		It("applies a count with a method to that method", func() {
+			Expect(arg.UnmarshalFlag("attach_disk=5")).To(Succeed())
+			Expect(*arg).To(Equal(CPIMethodCountArg{Method: "attach_disk", Count: 5}))
+		})
+
+		It("returns an error when the count is not a non-negative number", func() {
+			err := arg.UnmarshalFlag("attach_disk=-1")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected 'attach_disk=-1' to be in format '[method=]count'"))
+		})
+	})
+})
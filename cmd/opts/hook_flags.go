@@ -0,0 +1,47 @@
+package opts
+
+import (
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
+)
+
+// Shared
+type HookFlags struct {
+	Hooks []HookArg `long:"hook" value-name:"POINT=PATH" description:"Run an executable at a point of the deployment: pre-vm-delete, post-vm-create, post-agent-ready or post-deploy (multiple)"`
+}
+
+// AsHooks returns the hooks set by the flags;
+// they run after the hooks of the installation manifest
+func (f HookFlags) AsHooks() []bihook.Hook {
+	var hooks []bihook.Hook
+
+	for _, arg := range f.Hooks {
+		hooks = append(hooks, bihook.Hook(arg))
+	}
+
+	return hooks
+}
+
+type HookArg struct {
+	Point bihook.Point
+	Path  string
+}
+
+func (a *HookArg) UnmarshalFlag(data string) error {
+	pieces := strings.SplitN(data, "=", 2)
+	if len(pieces) != 2 || pieces[1] == "" {
+		return bosherr.Errorf("Expected '%s' to be in format 'point=path'", data)
+	}
+
+	point, err := bihook.ParsePoint(pieces[0])
+	if err != nil {
+		return err
+	}
+
+	*a = HookArg{Point: point, Path: pieces[1]}
+
+	return nil
+}
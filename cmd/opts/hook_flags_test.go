@@ -0,0 +1,56 @@
+package opts_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
+)
+
+var _ = Describe("HookFlags", func() {
+	Describe("AsHooks", func() {
+		It("returns the hooks in order", func() {
+			flags := HookFlags{
+				Hooks: []HookArg{
+					{Point: bihook.PostDeploy, Path: "/hooks/notify"},
+					{Point: bihook.PreVMDelete, Path: "/hooks/deregister"},
+				},
+			}
+
+			Expect(flags.AsHooks()).To(Equal([]bihook.Hook{
+				{Point: bihook.PostDeploy, Path: "/hooks/notify"},
+				{Point: bihook.PreVMDelete, Path: "/hooks/deregister"},
+			}))
+		})
+	})
+})
+
+var _ = Describe("HookArg", func() {
+	Describe("UnmarshalFlag", func() {
+		var (
+			arg *HookArg
+		)
+
+		BeforeEach(func() {
+			arg = &HookArg{}
+		})
+
+		It("sets the point and the path", func() {
+			Expect(arg.UnmarshalFlag("post-vm-create=./hooks/register-dns")).To(Succeed())
+			Expect(*arg).To(Equal(HookArg{Point: bihook.PostVMCreate, Path: "./hooks/register-dns"}))
+		})
+
+		It("returns an error without a path", func() {
+			err := arg.UnmarshalFlag("post-vm-create")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected 'post-vm-create' to be in format 'point=path'"))
+		})
+
+		It("returns an error for unknown points", func() {
+			err := arg.UnmarshalFlag("post-lunch=/hooks/nap")
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Expected hook point 'post-lunch' to be one of"))
+		})
+	})
+})
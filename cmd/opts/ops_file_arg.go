@@ -13,7 +13,8 @@ import (
 type OpsFileArg struct {
 	FS boshsys.FileSystem
 
-	Ops patch.Ops
+	Ops  patch.Ops
+	Path string
 }
 
 func (a *OpsFileArg) UnmarshalFlag(filePath string) error {
@@ -48,6 +49,7 @@ func (a *OpsFileArg) UnmarshalFlag(filePath string) error {
 	}
 
 	(*a).Ops = ops
+	(*a).Path = filePath
 
 	return nil
 }
@@ -49,6 +49,7 @@ var _ = Describe("OpsFileArg", func() {
 					ErrorMsg: "operation [1] in /some/path failed",
 				},
 			}))
+			Expect(arg.Path).To(Equal("/some/path"))
 		})
 
 		It("returns an error if operations are not valid", func() {
@@ -31,24 +31,37 @@ type BoshOpts struct {
 	// Output formatting
 	ColumnOpt         []ColumnOpt `long:"column"                    description:"Filter to show only given column(s), use the --column flag for each column you wish to include"`
 	JSONOpt           bool        `long:"json"                      description:"Output as JSON"`
+	NDJSONOpt         bool        `long:"ndjson"                    description:"Stream task events and task state changes as newline-delimited JSON to stdout and all other output to stderr"`
 	TTYOpt            bool        `long:"tty"                       description:"Force TTY-like output"`
 	NoColorOpt        bool        `long:"no-color"                  description:"Toggle colorized output"`
 	NonInteractiveOpt bool        `long:"non-interactive" short:"n" description:"Don't ask for user input" env:"BOSH_NON_INTERACTIVE"`
 
+	// Task tracking
+	NoTrackOpt         bool   `long:"no-track" description:"Return as soon as a task that changes the environment is queued instead of tracking it" env:"BOSH_NO_TRACK"`
+	OnTaskInterruptOpt string `long:"on-task-interrupt" value-name:"ACTION" description:"Cancel, detach from or watch a tracked task when interrupted without asking" env:"BOSH_ON_TASK_INTERRUPT" choice:"cancel" choice:"detach" choice:"watch" default:"detach"`
+
 	Help       HelpOpts `command:"help" description:"Show this help message"`
 	Completion NoOpts   `command:"completion" description:"Generate the autocompletion script for bosh for the specified shell."`
 
 	// -----> Director management
 
 	// Environments
-	Environment  EnvironmentOpts  `command:"environment"  alias:"env"  description:"Show environment"`
-	Environments EnvironmentsOpts `command:"environments" alias:"envs" description:"List environments"`
-	CreateEnv    CreateEnvOpts    `command:"create-env"                description:"Create or update BOSH environment"`
-	DeleteEnv    DeleteEnvOpts    `command:"delete-env"                description:"Delete BOSH environment"`
-	StopEnv      StopEnvOpts      `command:"stop-env"                  description:"Stop BOSH environment"`
-	StartEnv     StartEnvOpts     `command:"start-env"                 description:"Start BOSH environment"`
-	AliasEnv     AliasEnvOpts     `command:"alias-env"                 description:"Alias environment to save URL and CA certificate"`
-	UnaliasEnv   UnaliasEnvOpts   `command:"unalias-env"               description:"Remove an aliased environment"`
+	Environment     EnvironmentOpts     `command:"environment"  alias:"env"  description:"Show environment"`
+	Environments    EnvironmentsOpts    `command:"environments" alias:"envs" description:"List environments"`
+	CreateEnv       CreateEnvOpts       `command:"create-env"                description:"Create or update BOSH environment"`
+	CreateEnvBundle CreateEnvBundleOpts `command:"create-env-bundle"         description:"Bundle an interpolated manifest with its release and stemcell tarballs for create-env without network access"`
+	DeleteEnv       DeleteEnvOpts       `command:"delete-env"                description:"Delete BOSH environment"`
+	StopEnv         StopEnvOpts         `command:"stop-env"                  description:"Stop BOSH environment"`
+	StartEnv        StartEnvOpts        `command:"start-env"                 description:"Start BOSH environment"`
+	EnvState        EnvStateOpts        `command:"env-state"                 description:"Inspect, validate, repair, unlock and restore saved versions of BOSH environment state"`
+	EnvCheck        EnvCheckOpts        `command:"env-check"                 description:"Check the VM, disks and agent of a BOSH environment against its state"`
+	EnvManifest     EnvManifestOpts     `command:"env-manifest"              description:"Show the manifest last deployed with create-env"`
+	EnvManifestDiff EnvManifestDiffOpts `command:"env-manifest-diff"         description:"Show differences between the manifest last deployed with create-env and a manifest"`
+	CPICall         CPICallOpts         `command:"cpi-call"                  description:"Call a CPI method with the CPI installed for a BOSH environment"`
+	CPITest         CPITestOpts         `command:"cpi-test"                  description:"Test that a CPI release conforms to the CPI contract used by create-env"`
+	Cache           CacheOpts           `command:"cache"                     description:"Inspect and prune cached tarballs, installations and compiled packages"`
+	AliasEnv        AliasEnvOpts        `command:"alias-env"                 description:"Alias environment to save URL and CA certificate"`
+	UnaliasEnv      UnaliasEnvOpts      `command:"unalias-env"               description:"Remove an aliased environment"`
 
 	// Authentication
 	LogIn  LogInOpts  `command:"log-in"  alias:"l" alias:"login"  description:"Log in"` //nolint:staticcheck
@@ -193,10 +206,19 @@ type CreateEnvOpts struct {
 	Args CreateEnvArgs `positional-args:"true" required:"true"`
 	VarFlags
 	OpsFlags
-	SkipDrain               bool   `long:"skip-drain" description:"Skip running drain and pre-stop scripts"`
-	StatePath               string `long:"state" value-name:"PATH" description:"State file path"`
-	Recreate                bool   `long:"recreate" description:"Recreate VM in deployment"`
-	RecreatePersistentDisks bool   `long:"recreate-persistent-disks" description:"Recreate persistent disks in the deployment"`
+	CPICallFlags
+	HookFlags
+	SkipDrain               bool     `long:"skip-drain" description:"Skip running drain and pre-stop scripts"`
+	StatePath               string   `long:"state" value-name:"PATH" description:"State file path or http(s) URL"`
+	Recreate                bool     `long:"recreate" description:"Recreate VM in deployment"`
+	RecreatePersistentDisks bool     `long:"recreate-persistent-disks" description:"Recreate persistent disks in the deployment"`
+	DryRun                  bool     `long:"dry-run" description:"Show the changes that would be made to the environment without making them"`
+	CompileWorkers          int      `long:"compile-workers" value-name:"NUM" description:"Number of installation packages to compile in parallel" default:"1"`
+	CompiledPackagesCache   string   `long:"compiled-packages-cache" value-name:"DIR" description:"Directory of compiled installation packages to use before compiling and to add newly compiled packages to; packages are only shared between installations at the same path"`
+	CompiledPackageDigests  string   `long:"compiled-package-digests" value-name:"PATH" description:"File of trusted digests of the compiled packages cache; only packages listed in it are used, so machines sharing the cache must share this file (default: ~/.bosh/compiled_package_digests.json)"`
+	Bundle                  string   `long:"bundle" value-name:"PATH" description:"Use release and stemcell tarballs from a bundle created with create-env-bundle instead of downloading them; the manifest must interpolate to the manifest of the bundle"`
+	AdoptDisks              []string `long:"adopt-disk" value-name:"CID" description:"Attach an existing persistent disk (e.g. one kept by delete-env --keep-persistent-disk) instead of creating one. Can be used multiple times."`
+	ManifestKey             string   `long:"manifest-key" value-name:"KEY" env:"BOSH_MANIFEST_KEY" description:"Also store the deployed manifest encrypted with this key (variable values are otherwise redacted)"`
 	cmd
 }
 
@@ -204,12 +226,27 @@ type CreateEnvArgs struct {
 	Manifest FileBytesWithPathArg `positional-arg-name:"PATH" description:"Path to a manifest file"`
 }
 
+type CreateEnvBundleOpts struct {
+	Args CreateEnvBundleArgs `positional-args:"true" required:"true"`
+	VarFlags
+	OpsFlags
+	Output FileArg `long:"output" value-name:"PATH" description:"Path of the bundle to create (e.g. /tmp/bundle.tgz)" required:"true"`
+	cmd
+}
+
+type CreateEnvBundleArgs struct {
+	Manifest FileBytesWithPathArg `positional-arg-name:"PATH" description:"Path to a manifest file"`
+}
+
 type DeleteEnvOpts struct {
 	Args DeleteEnvArgs `positional-args:"true" required:"true"`
 	VarFlags
 	OpsFlags
-	SkipDrain bool   `long:"skip-drain" description:"Skip running drain and pre-stop scripts"`
-	StatePath string `long:"state" value-name:"PATH" description:"State file path"`
+	CPICallFlags
+	HookFlags
+	SkipDrain          bool   `long:"skip-drain" description:"Skip running drain and pre-stop scripts"`
+	StatePath          string `long:"state" value-name:"PATH" description:"State file path or http(s) URL"`
+	KeepPersistentDisk bool   `long:"keep-persistent-disk" description:"Leave the persistent disk in the IaaS so that it can be attached with create-env --adopt-disk"`
 	cmd
 }
 
@@ -218,7 +255,7 @@ type StopEnvOpts struct {
 	VarFlags
 	OpsFlags
 	SkipDrain bool   `long:"skip-drain" description:"Skip running drain and pre-stop scripts"`
-	StatePath string `long:"state" value-name:"PATH" description:"State file path"`
+	StatePath string `long:"state" value-name:"PATH" description:"State file path or http(s) URL"`
 	cmd
 }
 
@@ -226,10 +263,170 @@ type StartEnvOpts struct {
 	Args StartStopEnvArgs `positional-args:"true" required:"true"`
 	VarFlags
 	OpsFlags
-	StatePath string `long:"state" value-name:"PATH" description:"State file path"`
+	StatePath string `long:"state" value-name:"PATH" description:"State file path or http(s) URL"`
+	cmd
+}
+
+type EnvStateOpts struct {
+	History EnvStateHistoryOpts `command:"history" description:"List saved versions of the environment state"`
+	Diff    EnvStateDiffOpts    `command:"diff"    description:"Show differences between two versions of the environment state"`
+	Restore EnvStateRestoreOpts `command:"restore" description:"Restore a saved version of the environment state"`
+
+	Inspect  EnvStateInspectOpts  `command:"inspect"  description:"Show the records of the environment state"`
+	Validate EnvStateValidateOpts `command:"validate" description:"Check the environment state for inconsistent records"`
+	Repair   EnvStateRepairOpts   `command:"repair"   description:"Repair inconsistent records of the environment state"`
+
+	Unlock EnvStateUnlockOpts `command:"unlock" description:"Remove the lock of a remote environment state"`
+	cmd
+}
+
+type EnvStateInspectOpts struct {
+	StatePath string `long:"state" value-name:"PATH" description:"State file path or http(s) URL" required:"true"`
+	cmd
+}
+
+type EnvStateValidateOpts struct {
+	StatePath     string `long:"state"          value-name:"PATH" description:"State file path or http(s) URL" required:"true"`
+	CloudManifest string `long:"cloud-manifest" value-name:"PATH" description:"Ask the CPI of this create-env manifest whether the current VM and the recorded disks still exist"`
+	VarFlags
+	OpsFlags
+	cmd
+}
+
+type EnvStateRepairOpts struct {
+	StatePath     string `long:"state"          value-name:"PATH" description:"State file path or http(s) URL" required:"true"`
+	CloudManifest string `long:"cloud-manifest" value-name:"PATH" description:"Ask the CPI of this create-env manifest whether the current VM and the recorded disks still exist"`
+	VarFlags
+	OpsFlags
+	cmd
+}
+
+type EnvStateUnlockOpts struct {
+	StatePath string `long:"state" value-name:"PATH" description:"State file path or http(s) URL" required:"true"`
+	Force     bool   `long:"force"                   description:"Remove the lock even though its lease has not expired"`
+	cmd
+}
+
+type EnvStateHistoryOpts struct {
+	StatePath string `long:"state" value-name:"PATH" description:"Local state file path (versions are not kept for remote state)" required:"true"`
+	cmd
+}
+
+type EnvStateDiffOpts struct {
+	Args      EnvStateDiffArgs `positional-args:"true"`
+	StatePath string           `long:"state" value-name:"PATH" description:"Local state file path (versions are not kept for remote state)" required:"true"`
+	cmd
+}
+
+type EnvStateDiffArgs struct {
+	From int `positional-arg-name:"FROM" description:"Version to compare from" required:"true"`
+	To   int `positional-arg-name:"TO"   description:"Version to compare to (defaults to current state)"`
+}
+
+type EnvStateRestoreOpts struct {
+	Args      EnvStateRestoreArgs `positional-args:"true" required:"true"`
+	StatePath string              `long:"state" value-name:"PATH" description:"Local state file path (versions are not kept for remote state)" required:"true"`
 	cmd
 }
 
+type EnvStateRestoreArgs struct {
+	Version int `positional-arg-name:"VERSION" description:"Version to restore"`
+}
+
+type CacheOpts struct {
+	List  CacheListOpts  `command:"list"  description:"List cached tarballs, installations and compiled packages"`
+	Du    CacheDuOpts    `command:"du"    description:"Show disk usage of cached tarballs, installations and compiled packages"`
+	Prune CachePruneOpts `command:"prune" description:"Remove cache entries by age and least recent use"`
+	cmd
+}
+
+type CacheFlags struct {
+	CompiledPackagesCache  string `long:"compiled-packages-cache"  value-name:"DIR"  description:"Include compiled packages of this directory"`
+	CompiledPackageDigests string `long:"compiled-package-digests" value-name:"PATH" description:"File of trusted digests of the compiled packages (default: ~/.bosh/compiled_package_digests.json)"`
+}
+
+type CacheListOpts struct {
+	CacheFlags
+	cmd
+}
+
+type CacheDuOpts struct {
+	CacheFlags
+	cmd
+}
+
+type CachePruneOpts struct {
+	CacheFlags
+	OlderThan  time.Duration `long:"older-than" value-name:"DURATION" description:"Remove entries not used for longer (e.g. '720h')"`
+	MaxSize    string        `long:"max-size"   value-name:"SIZE"     description:"Remove least recently used entries until the entries not used by the given environments fit (e.g. '20GB')"`
+	StatePaths []string      `long:"state"      value-name:"PATH"     description:"Never remove the installation of this state file or http(s) URL (multiple)"`
+	DryRun     bool          `long:"dry-run"                          description:"Show the entries that would be removed without removing them"`
+	cmd
+}
+
+type EnvCheckOpts struct {
+	Args EnvCheckArgs `positional-args:"true" required:"true"`
+	VarFlags
+	OpsFlags
+	StatePath string `long:"state" value-name:"PATH" description:"State file path or http(s) URL"`
+	cmd
+}
+
+type EnvCheckArgs struct {
+	Manifest FileBytesWithPathArg `positional-arg-name:"PATH" description:"Path to a manifest file"`
+}
+
+type EnvManifestOpts struct {
+	StatePath   string `long:"state"        value-name:"PATH" description:"State file path or http(s) URL" required:"true"`
+	NoRedact    bool   `long:"no-redact"                      description:"Show variable values (requires --manifest-key)"`
+	ManifestKey string `long:"manifest-key" value-name:"KEY"  description:"Key the manifest was stored with by create-env" env:"BOSH_MANIFEST_KEY"`
+	cmd
+}
+
+type EnvManifestDiffOpts struct {
+	Args EnvManifestDiffArgs `positional-args:"true" required:"true"`
+	VarFlags
+	OpsFlags
+	StatePath   string `long:"state"        value-name:"PATH" description:"State file path or http(s) URL"`
+	NoRedact    bool   `long:"no-redact"                      description:"Compare variable values (requires --manifest-key)"`
+	ManifestKey string `long:"manifest-key" value-name:"KEY"  description:"Key the manifest was stored with by create-env" env:"BOSH_MANIFEST_KEY"`
+	cmd
+}
+
+type EnvManifestDiffArgs struct {
+	Manifest FileBytesWithPathArg `positional-arg-name:"PATH" description:"Path to a manifest file"`
+}
+
+type CPICallOpts struct {
+	Args CPICallArgs `positional-args:"true" required:"true"`
+	VarFlags
+	OpsFlags
+	StatePath  string `long:"state"       value-name:"PATH" description:"State file path or http(s) URL"`
+	Arguments  string `long:"arguments"   value-name:"JSON" description:"JSON array of method arguments (e.g. '[\"vm-cid\"]')"`
+	Context    string `long:"context"     value-name:"JSON" description:"JSON object replacing values of the call context (e.g. '{\"director_uuid\":\"id\"}')"`
+	ApiVersion int    `long:"api-version" value-name:"NUM"  description:"CPI API version sent with the call (default: 1)"`
+	cmd
+}
+
+type CPICallArgs struct {
+	Manifest FileBytesWithPathArg `positional-arg-name:"PATH"   description:"Path to a manifest file"`
+	Method   string               `positional-arg-name:"METHOD" description:"CPI method (e.g. has_vm)"`
+}
+
+type CPITestOpts struct {
+	Args CPITestArgs `positional-args:"true" required:"true"`
+	VarFlags
+	OpsFlags
+	CPICallFlags
+	JUnitReport string `long:"junit-report" value-name:"PATH" description:"Write test results as JUnit XML"`
+	JSONReport  string `long:"json-report"  value-name:"PATH" description:"Write test results as JSON"`
+	cmd
+}
+
+type CPITestArgs struct {
+	Manifest FileBytesWithPathArg `positional-arg-name:"PATH" description:"Path to a manifest file with the CPI release, stemcell, resource pool, networks and disk pool to test with"`
+}
+
 type DeleteEnvArgs struct {
 	Manifest FileBytesWithPathArg `positional-arg-name:"PATH" description:"Path to a manifest file"`
 }
@@ -290,6 +487,11 @@ type TaskOpts struct {
 	Debug  bool `long:"debug"  description:"Track debug log"`
 	Result bool `long:"result" description:"Track result log"`
 
+	Attach bool `long:"attach" description:"Resume tracking event log where the last attach stopped"`
+
+	Timeline       bool   `long:"timeline"        description:"Show stages, their steps, the critical path and idle gaps of event log as a timeline"`
+	TimelineFormat string `long:"timeline-format" description:"Timeline output format" value-name:"FORMAT" choice:"text" choice:"csv" choice:"json" default:"text"`
+
 	All        bool `long:"all" short:"a" description:"Include all task types (ssh, logs, vms, etc)"`
 	Deployment string
 
@@ -301,10 +503,15 @@ type TaskArgs struct {
 }
 
 type TasksOpts struct {
-	Recent     *int `long:"recent" short:"r" description:"Show 30 recent tasks. Use '=' to specify the number of tasks to show" optional:"true" optional-value:"30"`
-	All        bool `long:"all" short:"a" description:"Include all task types (ssh, logs, vms, etc)"`
+	Recent     *int     `long:"recent" short:"r" description:"Show 30 recent tasks. Use '=' to specify the number of tasks to show" optional:"true" optional-value:"30"`
+	All        bool     `long:"all" short:"a" description:"Include all task types (ssh, logs, vms, etc)"`
+	Types      []string `long:"type"  short:"t" description:"Task types to show (update_deployment, vms, etc) (default is all types)"`
+	States     []string `long:"state" short:"s" description:"Task states to show (queued, processing, cancelling, done, error, etc) (default is current states)"`
 	Deployment string
 
+	Watch    bool          `long:"watch"    description:"Keep refreshing current tasks and highlight new tasks and state changes"`
+	Interval time.Duration `long:"interval" description:"Time between refreshes when watching" value-name:"DURATION" default:"2s"`
+
 	cmd
 }
 
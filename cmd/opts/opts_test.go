@@ -177,6 +177,14 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("NDJSONOpt", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("NDJSONOpt", opts)).To(Equal(
+					`long:"ndjson" description:"Stream task events and task state changes as newline-delimited JSON to stdout and all other output to stderr"`,
+				))
+			})
+		})
+
 		Describe("TTYOpt", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("TTYOpt", opts)).To(Equal(
@@ -201,6 +209,22 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("NoTrackOpt", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("NoTrackOpt", opts)).To(Equal(
+					`long:"no-track" description:"Return as soon as a task that changes the environment is queued instead of tracking it" env:"BOSH_NO_TRACK"`,
+				))
+			})
+		})
+
+		Describe("OnTaskInterruptOpt", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("OnTaskInterruptOpt", opts)).To(Equal(
+					`long:"on-task-interrupt" value-name:"ACTION" description:"Cancel, detach from or watch a tracked task when interrupted without asking" env:"BOSH_ON_TASK_INTERRUPT" choice:"cancel" choice:"detach" choice:"watch" default:"detach"`,
+				))
+			})
+		})
+
 		Describe("CreateEnv", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("CreateEnv", opts)).To(Equal(
@@ -209,6 +233,14 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("CreateEnvBundle", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("CreateEnvBundle", opts)).To(Equal(
+					`command:"create-env-bundle" description:"Bundle an interpolated manifest with its release and stemcell tarballs for create-env without network access"`,
+				))
+			})
+		})
+
 		Describe("DeleteEnv", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("DeleteEnv", opts)).To(Equal(
@@ -233,6 +265,62 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("EnvState", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("EnvState", opts)).To(Equal(
+					`command:"env-state" description:"Inspect, validate, repair, unlock and restore saved versions of BOSH environment state"`,
+				))
+			})
+		})
+
+		Describe("EnvCheck", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("EnvCheck", opts)).To(Equal(
+					`command:"env-check" description:"Check the VM, disks and agent of a BOSH environment against its state"`,
+				))
+			})
+		})
+
+		Describe("EnvManifest", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("EnvManifest", opts)).To(Equal(
+					`command:"env-manifest" description:"Show the manifest last deployed with create-env"`,
+				))
+			})
+		})
+
+		Describe("EnvManifestDiff", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("EnvManifestDiff", opts)).To(Equal(
+					`command:"env-manifest-diff" description:"Show differences between the manifest last deployed with create-env and a manifest"`,
+				))
+			})
+		})
+
+		Describe("Cache", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Cache", opts)).To(Equal(
+					`command:"cache" description:"Inspect and prune cached tarballs, installations and compiled packages"`,
+				))
+			})
+		})
+
+		Describe("CPICall", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("CPICall", opts)).To(Equal(
+					`command:"cpi-call" description:"Call a CPI method with the CPI installed for a BOSH environment"`,
+				))
+			})
+		})
+
+		Describe("CPITest", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("CPITest", opts)).To(Equal(
+					`command:"cpi-test" description:"Test that a CPI release conforms to the CPI contract used by create-env"`,
+				))
+			})
+		})
+
 		Describe("Environment", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("Environment", opts)).To(Equal(
@@ -841,7 +929,7 @@ var _ = Describe("Opts", func() {
 
 		It("has --state", func() {
 			Expect(getStructTagForName("StatePath", opts)).To(Equal(
-				`long:"state" value-name:"PATH" description:"State file path"`,
+				`long:"state" value-name:"PATH" description:"State file path or http(s) URL"`,
 			))
 		})
 
@@ -862,6 +950,72 @@ var _ = Describe("Opts", func() {
 				`long:"skip-drain" description:"Skip running drain and pre-stop scripts"`,
 			))
 		})
+
+		It("has --adopt-disk", func() {
+			Expect(getStructTagForName("AdoptDisks", opts)).To(Equal(
+				`long:"adopt-disk" value-name:"CID" description:"Attach an existing persistent disk (e.g. one kept by delete-env --keep-persistent-disk) instead of creating one. Can be used multiple times."`,
+			))
+		})
+
+		It("has --manifest-key", func() {
+			Expect(getStructTagForName("ManifestKey", opts)).To(Equal(
+				`long:"manifest-key" value-name:"KEY" env:"BOSH_MANIFEST_KEY" description:"Also store the deployed manifest encrypted with this key (variable values are otherwise redacted)"`,
+			))
+		})
+
+		It("has --dry-run", func() {
+			Expect(getStructTagForName("DryRun", opts)).To(Equal(
+				`long:"dry-run" description:"Show the changes that would be made to the environment without making them"`,
+			))
+		})
+
+		It("has --compile-workers", func() {
+			Expect(getStructTagForName("CompileWorkers", opts)).To(Equal(
+				`long:"compile-workers" value-name:"NUM" description:"Number of installation packages to compile in parallel" default:"1"`,
+			))
+		})
+
+		It("has --compiled-packages-cache", func() {
+			Expect(getStructTagForName("CompiledPackagesCache", opts)).To(Equal(
+				`long:"compiled-packages-cache" value-name:"DIR" description:"Directory of compiled installation packages to use before compiling and to add newly compiled packages to; packages are only shared between installations at the same path"`,
+			))
+		})
+
+		It("has --compiled-package-digests", func() {
+			Expect(getStructTagForName("CompiledPackageDigests", opts)).To(Equal(
+				`long:"compiled-package-digests" value-name:"PATH" description:"File of trusted digests of the compiled packages cache; only packages listed in it are used, so machines sharing the cache must share this file (default: ~/.bosh/compiled_package_digests.json)"`,
+			))
+		})
+
+		It("has --bundle", func() {
+			Expect(getStructTagForName("Bundle", opts)).To(Equal(
+				`long:"bundle" value-name:"PATH" description:"Use release and stemcell tarballs from a bundle created with create-env-bundle instead of downloading them; the manifest must interpolate to the manifest of the bundle"`,
+			))
+		})
+
+		It("has --cpi-timeout", func() {
+			Expect(getStructTagForName("CPITimeouts", opts)).To(Equal(
+				`long:"cpi-timeout" value-name:"[METHOD=]DURATION" description:"Terminate CPI calls that run longer (e.g. '30m', 'create_vm=1h' or 'create_vm=0' for no timeout)"`,
+			))
+		})
+
+		It("has --cpi-retries", func() {
+			Expect(getStructTagForName("CPIRetries", opts)).To(Equal(
+				`long:"cpi-retries" value-name:"[METHOD=]COUNT" description:"Retry CPI calls that fail with retryable errors (e.g. '3' or 'attach_disk=5')"`,
+			))
+		})
+
+		It("has --record-cpi-calls", func() {
+			Expect(getStructTagForName("RecordCPICalls", opts)).To(Equal(
+				`long:"record-cpi-calls" value-name:"PATH" description:"Append every CPI call and its response to this JSON Lines file (defaults to $BOSH_CPI_RECORD_PATH)"`,
+			))
+		})
+
+		It("has --replay-cpi-calls", func() {
+			Expect(getStructTagForName("ReplayCPICalls", opts)).To(Equal(
+				`long:"replay-cpi-calls" value-name:"PATH" description:"Answer CPI calls from this recording instead of running the CPI (defaults to $BOSH_CPI_REPLAY_PATH)"`,
+			))
+		})
 	})
 
 	Describe("CreateEnvArgs", func() {
@@ -895,7 +1049,7 @@ var _ = Describe("Opts", func() {
 
 		It("has --state", func() {
 			Expect(getStructTagForName("StatePath", opts)).To(Equal(
-				`long:"state" value-name:"PATH" description:"State file path"`,
+				`long:"state" value-name:"PATH" description:"State file path or http(s) URL"`,
 			))
 		})
 
@@ -904,6 +1058,24 @@ var _ = Describe("Opts", func() {
 				`long:"skip-drain" description:"Skip running drain and pre-stop scripts"`,
 			))
 		})
+
+		It("has --keep-persistent-disk", func() {
+			Expect(getStructTagForName("KeepPersistentDisk", opts)).To(Equal(
+				`long:"keep-persistent-disk" description:"Leave the persistent disk in the IaaS so that it can be attached with create-env --adopt-disk"`,
+			))
+		})
+
+		It("has --cpi-timeout", func() {
+			Expect(getStructTagForName("CPITimeouts", opts)).To(Equal(
+				`long:"cpi-timeout" value-name:"[METHOD=]DURATION" description:"Terminate CPI calls that run longer (e.g. '30m', 'create_vm=1h' or 'create_vm=0' for no timeout)"`,
+			))
+		})
+
+		It("has --cpi-retries", func() {
+			Expect(getStructTagForName("CPIRetries", opts)).To(Equal(
+				`long:"cpi-retries" value-name:"[METHOD=]COUNT" description:"Retry CPI calls that fail with retryable errors (e.g. '3' or 'attach_disk=5')"`,
+			))
+		})
 	})
 
 	Describe("DeleteEnvArgs", func() {
@@ -937,7 +1109,7 @@ var _ = Describe("Opts", func() {
 
 		It("has --state", func() {
 			Expect(getStructTagForName("StatePath", opts)).To(Equal(
-				`long:"state" value-name:"PATH" description:"State file path"`,
+				`long:"state" value-name:"PATH" description:"State file path or http(s) URL"`,
 			))
 		})
 
@@ -963,10 +1135,523 @@ var _ = Describe("Opts", func() {
 
 		It("has --state", func() {
 			Expect(getStructTagForName("StatePath", opts)).To(Equal(
-				`long:"state" value-name:"PATH" description:"State file path"`,
+				`long:"state" value-name:"PATH" description:"State file path or http(s) URL"`,
+			))
+		})
+
+	})
+
+	Describe("EnvStateOpts", func() {
+		var opts *EnvStateOpts
+
+		BeforeEach(func() {
+			opts = &EnvStateOpts{}
+		})
+
+		It("has history, diff and restore subcommands", func() {
+			Expect(getStructTagForName("History", opts)).To(Equal(
+				`command:"history" description:"List saved versions of the environment state"`,
+			))
+			Expect(getStructTagForName("Diff", opts)).To(Equal(
+				`command:"diff" description:"Show differences between two versions of the environment state"`,
+			))
+			Expect(getStructTagForName("Restore", opts)).To(Equal(
+				`command:"restore" description:"Restore a saved version of the environment state"`,
 			))
 		})
 
+		It("has inspect, validate and repair subcommands", func() {
+			Expect(getStructTagForName("Inspect", opts)).To(Equal(
+				`command:"inspect" description:"Show the records of the environment state"`,
+			))
+			Expect(getStructTagForName("Validate", opts)).To(Equal(
+				`command:"validate" description:"Check the environment state for inconsistent records"`,
+			))
+			Expect(getStructTagForName("Repair", opts)).To(Equal(
+				`command:"repair" description:"Repair inconsistent records of the environment state"`,
+			))
+		})
+
+		It("has unlock subcommand", func() {
+			Expect(getStructTagForName("Unlock", opts)).To(Equal(
+				`command:"unlock" description:"Remove the lock of a remote environment state"`,
+			))
+		})
+	})
+
+	Describe("EnvStateInspectOpts", func() {
+		var opts *EnvStateInspectOpts
+
+		BeforeEach(func() {
+			opts = &EnvStateInspectOpts{}
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"State file path or http(s) URL" required:"true"`,
+			))
+		})
+	})
+
+	Describe("EnvStateValidateOpts", func() {
+		var opts *EnvStateValidateOpts
+
+		BeforeEach(func() {
+			opts = &EnvStateValidateOpts{}
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"State file path or http(s) URL" required:"true"`,
+			))
+		})
+
+		It("has --cloud-manifest", func() {
+			Expect(getStructTagForName("CloudManifest", opts)).To(Equal(
+				`long:"cloud-manifest" value-name:"PATH" description:"Ask the CPI of this create-env manifest whether the current VM and the recorded disks still exist"`,
+			))
+		})
+	})
+
+	Describe("EnvStateRepairOpts", func() {
+		var opts *EnvStateRepairOpts
+
+		BeforeEach(func() {
+			opts = &EnvStateRepairOpts{}
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"State file path or http(s) URL" required:"true"`,
+			))
+		})
+
+		It("has --cloud-manifest", func() {
+			Expect(getStructTagForName("CloudManifest", opts)).To(Equal(
+				`long:"cloud-manifest" value-name:"PATH" description:"Ask the CPI of this create-env manifest whether the current VM and the recorded disks still exist"`,
+			))
+		})
+	})
+
+	Describe("EnvStateUnlockOpts", func() {
+		var opts *EnvStateUnlockOpts
+
+		BeforeEach(func() {
+			opts = &EnvStateUnlockOpts{}
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"State file path or http(s) URL" required:"true"`,
+			))
+		})
+
+		It("has --force", func() {
+			Expect(getStructTagForName("Force", opts)).To(Equal(
+				`long:"force" description:"Remove the lock even though its lease has not expired"`,
+			))
+		})
+	})
+
+	Describe("EnvStateHistoryOpts", func() {
+		var opts *EnvStateHistoryOpts
+
+		BeforeEach(func() {
+			opts = &EnvStateHistoryOpts{}
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"Local state file path (versions are not kept for remote state)" required:"true"`,
+			))
+		})
+	})
+
+	Describe("EnvStateDiffOpts", func() {
+		var opts *EnvStateDiffOpts
+
+		BeforeEach(func() {
+			opts = &EnvStateDiffOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true"`))
+			})
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"Local state file path (versions are not kept for remote state)" required:"true"`,
+			))
+		})
+	})
+
+	Describe("EnvStateDiffArgs", func() {
+		var args *EnvStateDiffArgs
+
+		BeforeEach(func() {
+			args = &EnvStateDiffArgs{}
+		})
+
+		It("contains desired values", func() {
+			Expect(getStructTagForName("From", args)).To(Equal(
+				`positional-arg-name:"FROM" description:"Version to compare from" required:"true"`,
+			))
+			Expect(getStructTagForName("To", args)).To(Equal(
+				`positional-arg-name:"TO" description:"Version to compare to (defaults to current state)"`,
+			))
+		})
+	})
+
+	Describe("EnvStateRestoreOpts", func() {
+		var opts *EnvStateRestoreOpts
+
+		BeforeEach(func() {
+			opts = &EnvStateRestoreOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"Local state file path (versions are not kept for remote state)" required:"true"`,
+			))
+		})
+	})
+
+	Describe("EnvStateRestoreArgs", func() {
+		var args *EnvStateRestoreArgs
+
+		BeforeEach(func() {
+			args = &EnvStateRestoreArgs{}
+		})
+
+		It("contains desired values", func() {
+			Expect(getStructTagForName("Version", args)).To(Equal(
+				`positional-arg-name:"VERSION" description:"Version to restore"`,
+			))
+		})
+	})
+
+	Describe("EnvCheckOpts", func() {
+		var opts *EnvCheckOpts
+
+		BeforeEach(func() {
+			opts = &EnvCheckOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"State file path or http(s) URL"`,
+			))
+		})
+	})
+
+	Describe("EnvCheckArgs", func() {
+		var args *EnvCheckArgs
+
+		BeforeEach(func() {
+			args = &EnvCheckArgs{}
+		})
+
+		It("has Manifest", func() {
+			Expect(getStructTagForName("Manifest", args)).To(Equal(
+				`positional-arg-name:"PATH" description:"Path to a manifest file"`,
+			))
+		})
+	})
+
+	Describe("EnvManifestOpts", func() {
+		var opts *EnvManifestOpts
+
+		BeforeEach(func() {
+			opts = &EnvManifestOpts{}
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"State file path or http(s) URL" required:"true"`,
+			))
+		})
+
+		It("has --no-redact", func() {
+			Expect(getStructTagForName("NoRedact", opts)).To(Equal(
+				`long:"no-redact" description:"Show variable values (requires --manifest-key)"`,
+			))
+		})
+
+		It("has --manifest-key", func() {
+			Expect(getStructTagForName("ManifestKey", opts)).To(Equal(
+				`long:"manifest-key" value-name:"KEY" description:"Key the manifest was stored with by create-env" env:"BOSH_MANIFEST_KEY"`,
+			))
+		})
+	})
+
+	Describe("EnvManifestDiffOpts", func() {
+		var opts *EnvManifestDiffOpts
+
+		BeforeEach(func() {
+			opts = &EnvManifestDiffOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"State file path or http(s) URL"`,
+			))
+		})
+
+		It("has --no-redact", func() {
+			Expect(getStructTagForName("NoRedact", opts)).To(Equal(
+				`long:"no-redact" description:"Compare variable values (requires --manifest-key)"`,
+			))
+		})
+
+		It("has --manifest-key", func() {
+			Expect(getStructTagForName("ManifestKey", opts)).To(Equal(
+				`long:"manifest-key" value-name:"KEY" description:"Key the manifest was stored with by create-env" env:"BOSH_MANIFEST_KEY"`,
+			))
+		})
+	})
+
+	Describe("EnvManifestDiffArgs", func() {
+		var args *EnvManifestDiffArgs
+
+		BeforeEach(func() {
+			args = &EnvManifestDiffArgs{}
+		})
+
+		It("has Manifest", func() {
+			Expect(getStructTagForName("Manifest", args)).To(Equal(
+				`positional-arg-name:"PATH" description:"Path to a manifest file"`,
+			))
+		})
+	})
+
+	Describe("CPICallOpts", func() {
+		var opts *CPICallOpts
+
+		BeforeEach(func() {
+			opts = &CPICallOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePath", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"State file path or http(s) URL"`,
+			))
+		})
+
+		It("has --arguments", func() {
+			Expect(getStructTagForName("Arguments", opts)).To(Equal(
+				`long:"arguments" value-name:"JSON" description:"JSON array of method arguments (e.g. '[\"vm-cid\"]')"`,
+			))
+		})
+
+		It("has --context", func() {
+			Expect(getStructTagForName("Context", opts)).To(Equal(
+				`long:"context" value-name:"JSON" description:"JSON object replacing values of the call context (e.g. '{\"director_uuid\":\"id\"}')"`,
+			))
+		})
+
+		It("has --api-version", func() {
+			Expect(getStructTagForName("ApiVersion", opts)).To(Equal(
+				`long:"api-version" value-name:"NUM" description:"CPI API version sent with the call (default: 1)"`,
+			))
+		})
+	})
+
+	Describe("CPICallArgs", func() {
+		var args *CPICallArgs
+
+		BeforeEach(func() {
+			args = &CPICallArgs{}
+		})
+
+		It("has Manifest", func() {
+			Expect(getStructTagForName("Manifest", args)).To(Equal(
+				`positional-arg-name:"PATH" description:"Path to a manifest file"`,
+			))
+		})
+
+		It("has Method", func() {
+			Expect(getStructTagForName("Method", args)).To(Equal(
+				`positional-arg-name:"METHOD" description:"CPI method (e.g. has_vm)"`,
+			))
+		})
+	})
+
+	Describe("CreateEnvBundleOpts", func() {
+		var opts *CreateEnvBundleOpts
+
+		BeforeEach(func() {
+			opts = &CreateEnvBundleOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		It("has --output", func() {
+			Expect(getStructTagForName("Output", opts)).To(Equal(
+				`long:"output" value-name:"PATH" description:"Path of the bundle to create (e.g. /tmp/bundle.tgz)" required:"true"`,
+			))
+		})
+	})
+
+	Describe("CreateEnvBundleArgs", func() {
+		var args *CreateEnvBundleArgs
+
+		BeforeEach(func() {
+			args = &CreateEnvBundleArgs{}
+		})
+
+		It("has Manifest", func() {
+			Expect(getStructTagForName("Manifest", args)).To(Equal(
+				`positional-arg-name:"PATH" description:"Path to a manifest file"`,
+			))
+		})
+	})
+
+	Describe("CPITestOpts", func() {
+		var opts *CPITestOpts
+
+		BeforeEach(func() {
+			opts = &CPITestOpts{}
+		})
+
+		Describe("Args", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Args", opts)).To(Equal(`positional-args:"true" required:"true"`))
+			})
+		})
+
+		It("has --junit-report", func() {
+			Expect(getStructTagForName("JUnitReport", opts)).To(Equal(
+				`long:"junit-report" value-name:"PATH" description:"Write test results as JUnit XML"`,
+			))
+		})
+
+		It("has --json-report", func() {
+			Expect(getStructTagForName("JSONReport", opts)).To(Equal(
+				`long:"json-report" value-name:"PATH" description:"Write test results as JSON"`,
+			))
+		})
+
+		It("has --cpi-timeout", func() {
+			Expect(getStructTagForName("CPITimeouts", opts)).To(Equal(
+				`long:"cpi-timeout" value-name:"[METHOD=]DURATION" description:"Terminate CPI calls that run longer (e.g. '30m', 'create_vm=1h' or 'create_vm=0' for no timeout)"`,
+			))
+		})
+	})
+
+	Describe("CPITestArgs", func() {
+		var args *CPITestArgs
+
+		BeforeEach(func() {
+			args = &CPITestArgs{}
+		})
+
+		It("has Manifest", func() {
+			Expect(getStructTagForName("Manifest", args)).To(Equal(
+				`positional-arg-name:"PATH" description:"Path to a manifest file with the CPI release, stemcell, resource pool, networks and disk pool to test with"`,
+			))
+		})
+	})
+
+	Describe("CacheOpts", func() {
+		var opts *CacheOpts
+
+		BeforeEach(func() {
+			opts = &CacheOpts{}
+		})
+
+		It("has list, du and prune subcommands", func() {
+			Expect(getStructTagForName("List", opts)).To(Equal(
+				`command:"list" description:"List cached tarballs, installations and compiled packages"`,
+			))
+			Expect(getStructTagForName("Du", opts)).To(Equal(
+				`command:"du" description:"Show disk usage of cached tarballs, installations and compiled packages"`,
+			))
+			Expect(getStructTagForName("Prune", opts)).To(Equal(
+				`command:"prune" description:"Remove cache entries by age and least recent use"`,
+			))
+		})
+	})
+
+	Describe("CacheFlags", func() {
+		var opts *CacheFlags
+
+		BeforeEach(func() {
+			opts = &CacheFlags{}
+		})
+
+		It("has --compiled-packages-cache", func() {
+			Expect(getStructTagForName("CompiledPackagesCache", opts)).To(Equal(
+				`long:"compiled-packages-cache" value-name:"DIR" description:"Include compiled packages of this directory"`,
+			))
+		})
+
+		It("has --compiled-package-digests", func() {
+			Expect(getStructTagForName("CompiledPackageDigests", opts)).To(Equal(
+				`long:"compiled-package-digests" value-name:"PATH" description:"File of trusted digests of the compiled packages (default: ~/.bosh/compiled_package_digests.json)"`,
+			))
+		})
+	})
+
+	Describe("CachePruneOpts", func() {
+		var opts *CachePruneOpts
+
+		BeforeEach(func() {
+			opts = &CachePruneOpts{}
+		})
+
+		It("has --older-than", func() {
+			Expect(getStructTagForName("OlderThan", opts)).To(Equal(
+				`long:"older-than" value-name:"DURATION" description:"Remove entries not used for longer (e.g. '720h')"`,
+			))
+		})
+
+		It("has --max-size", func() {
+			Expect(getStructTagForName("MaxSize", opts)).To(Equal(
+				`long:"max-size" value-name:"SIZE" description:"Remove least recently used entries until the entries not used by the given environments fit (e.g. '20GB')"`,
+			))
+		})
+
+		It("has --state", func() {
+			Expect(getStructTagForName("StatePaths", opts)).To(Equal(
+				`long:"state" value-name:"PATH" description:"Never remove the installation of this state file or http(s) URL (multiple)"`,
+			))
+		})
+
+		It("has --dry-run", func() {
+			Expect(getStructTagForName("DryRun", opts)).To(Equal(
+				`long:"dry-run" description:"Show the entries that would be removed without removing them"`,
+			))
+		})
 	})
 
 	Describe("SartStopEnvArgs", func() {
@@ -1263,6 +1948,30 @@ var _ = Describe("Opts", func() {
 			})
 		})
 
+		Describe("Attach", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Attach", opts)).To(Equal(
+					`long:"attach" description:"Resume tracking event log where the last attach stopped"`,
+				))
+			})
+		})
+
+		Describe("Timeline", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Timeline", opts)).To(Equal(
+					`long:"timeline" description:"Show stages, their steps, the critical path and idle gaps of event log as a timeline"`,
+				))
+			})
+		})
+
+		Describe("TimelineFormat", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("TimelineFormat", opts)).To(Equal(
+					`long:"timeline-format" description:"Timeline output format" value-name:"FORMAT" choice:"text" choice:"csv" choice:"json" default:"text"`,
+				))
+			})
+		})
+
 		Describe("All", func() {
 			It("contains desired values", func() {
 				Expect(getStructTagForName("All", opts)).To(Equal(
@@ -1310,6 +2019,38 @@ var _ = Describe("Opts", func() {
 				))
 			})
 		})
+
+		Describe("Types", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Types", opts)).To(Equal(
+					`long:"type" short:"t" description:"Task types to show (update_deployment, vms, etc) (default is all types)"`,
+				))
+			})
+		})
+
+		Describe("States", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("States", opts)).To(Equal(
+					`long:"state" short:"s" description:"Task states to show (queued, processing, cancelling, done, error, etc) (default is current states)"`,
+				))
+			})
+		})
+
+		Describe("Watch", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Watch", opts)).To(Equal(
+					`long:"watch" description:"Keep refreshing current tasks and highlight new tasks and state changes"`,
+				))
+			})
+		})
+
+		Describe("Interval", func() {
+			It("contains desired values", func() {
+				Expect(getStructTagForName("Interval", opts)).To(Equal(
+					`long:"interval" description:"Time between refreshes when watching" value-name:"DURATION" default:"2s"`,
+				))
+			})
+		})
 	})
 
 	Describe("CancelTaskOpts", func() {
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"fmt"
+	"strings"
 
 	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
 )
@@ -45,3 +46,75 @@ func (d Diff) String() string {
 	}
 	return result
 }
+
+// NewLineDiff builds a Diff of two texts line by line, marking lines only
+// present in 'from' as removed and lines only present in 'to' as added
+func NewLineDiff(from, to string) Diff {
+	fromLines := splitDiffLines(from)
+	toLines := splitDiffLines(to)
+
+	// common[i][j] is the length of the longest common subsequence of fromLines[i:] and toLines[j:]
+	common := make([][]int, len(fromLines)+1)
+	for i := range common {
+		common[i] = make([]int, len(toLines)+1)
+	}
+
+	for i := len(fromLines) - 1; i >= 0; i-- {
+		for j := len(toLines) - 1; j >= 0; j-- {
+			if fromLines[i] == toLines[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	var lines [][]interface{}
+
+	i, j := 0, 0
+	for i < len(fromLines) && j < len(toLines) {
+		switch {
+		case fromLines[i] == toLines[j]:
+			lines = append(lines, []interface{}{fromLines[i], nil})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, []interface{}{fromLines[i], "removed"})
+			i++
+		default:
+			lines = append(lines, []interface{}{toLines[j], "added"})
+			j++
+		}
+	}
+
+	for ; i < len(fromLines); i++ {
+		lines = append(lines, []interface{}{fromLines[i], "removed"})
+	}
+
+	for ; j < len(toLines); j++ {
+		lines = append(lines, []interface{}{toLines[j], "added"})
+	}
+
+	return NewDiff(lines)
+}
+
+// HasChanges returns true if any line was added or removed
+func (d Diff) HasChanges() bool {
+	for _, line := range d.lines {
+		if lineMod, _ := line[1].(string); lineMod == "added" || lineMod == "removed" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitDiffLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+}
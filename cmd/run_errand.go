@@ -92,18 +92,25 @@ func (c RunErrandCmd) summarize(errandName string, results []boshdir.ErrandResul
 			boshtbl.NewValueString(result.Stderr),
 		})
 
-		prefix := fmt.Sprintf("Errand '%s'", errandName)
-		suffix := fmt.Sprintf("(exit code %d)", result.ExitCode)
-
-		switch {
-		case result.ExitCode == 0:
-		case result.ExitCode > 128:
-			errandErr = bosherr.Errorf("%s was canceled %s", prefix, suffix)
-		default:
-			errandErr = bosherr.Errorf("%s completed with error %s", prefix, suffix)
+		if err := errandExitCodeErr(errandName, result.ExitCode); err != nil {
+			errandErr = err
 		}
 	}
 	c.ui.PrintTable(table)
 
 	return errandErr
 }
+
+func errandExitCodeErr(errandName string, exitCode int) error {
+	prefix := fmt.Sprintf("Errand '%s'", errandName)
+	suffix := fmt.Sprintf("(exit code %d)", exitCode)
+
+	switch {
+	case exitCode == 0:
+		return nil
+	case exitCode > 128:
+		return bosherr.Errorf("%s was canceled %s", prefix, suffix)
+	default:
+		return bosherr.Errorf("%s completed with error %s", prefix, suffix)
+	}
+}
@@ -1,6 +1,9 @@
 package cmd
 
 import (
+	"os/signal"
+
+	"code.cloudfoundry.org/clock"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 
@@ -118,7 +121,14 @@ func (c *SessionImpl) Director() (boshdir.Director, error) {
 		c.ui.PrintLinef("Using environment '%s' as %s", c.Environment(), creds.Description())
 	}
 
-	taskReporter := boshuit.NewReporter(c.ui, true)
+	dirConfig.TaskInterruptHandler = NewTaskInterruptHandler(c.ui, c.context.TaskInterruptAction(), signal.Notify, signal.Stop)
+	dirConfig.NoTrack = c.context.NoTrack()
+
+	var taskReporter boshdir.TaskReporter = boshuit.NewReporter(c.ui, true)
+	if c.context.NDJSON() {
+		taskReporter = boshuit.NewNDJSONReporter(boshui.NDJSONWriter(c.ui), clock.NewClock())
+	}
+
 	fileReporter := boshui.NewFileReporter(c.ui)
 
 	director, err := boshdir.NewFactory(c.logger).New(dirConfig, taskReporter, fileReporter)
@@ -5,6 +5,7 @@ import (
 
 	cmdconf "github.com/cloudfoundry/bosh-cli/v7/cmd/config"
 	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	boshdir "github.com/cloudfoundry/bosh-cli/v7/director"
 )
 
 // SessionContextImpl prefers options over config values
@@ -53,3 +54,15 @@ func (c SessionContextImpl) CACert() string {
 func (c SessionContextImpl) Deployment() string {
 	return c.opts.DeploymentOpt
 }
+
+func (c SessionContextImpl) TaskInterruptAction() boshdir.TaskInterruptAction {
+	return boshdir.TaskInterruptAction(c.opts.OnTaskInterruptOpt)
+}
+
+func (c SessionContextImpl) NoTrack() bool {
+	return c.opts.NoTrackOpt
+}
+
+func (c SessionContextImpl) NDJSON() bool {
+	return c.opts.NDJSONOpt
+}
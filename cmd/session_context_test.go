@@ -9,6 +9,7 @@ import (
 	cmdconf "github.com/cloudfoundry/bosh-cli/v7/cmd/config"
 	fakeconf "github.com/cloudfoundry/bosh-cli/v7/cmd/config/configfakes"
 	"github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	boshdir "github.com/cloudfoundry/bosh-cli/v7/director"
 )
 
 var _ = Describe("SessionContextImpl", func() {
@@ -119,4 +120,29 @@ var _ = Describe("SessionContextImpl", func() {
 			Expect(build().Deployment()).To(Equal(""))
 		})
 	})
+
+	Describe("TaskInterruptAction", func() {
+		It("returns global option", func() {
+			boshOpts.OnTaskInterruptOpt = "cancel"
+			Expect(build().TaskInterruptAction()).To(Equal(boshdir.TaskInterruptCancel))
+		})
+	})
+
+	Describe("NDJSON", func() {
+		It("returns global option", func() {
+			Expect(build().NDJSON()).To(BeFalse())
+
+			boshOpts.NDJSONOpt = true
+			Expect(build().NDJSON()).To(BeTrue())
+		})
+	})
+
+	Describe("NoTrack", func() {
+		It("returns global option", func() {
+			Expect(build().NoTrack()).To(BeFalse())
+
+			boshOpts.NoTrackOpt = true
+			Expect(build().NoTrack()).To(BeTrue())
+		})
+	})
 })
@@ -15,6 +15,10 @@ type SessionContext interface {
 	Credentials() cmdconf.Creds
 
 	Deployment() string
+
+	TaskInterruptAction() boshdir.TaskInterruptAction
+	NoTrack() bool
+	NDJSON() bool
 }
 
 //counterfeiter:generate . Session
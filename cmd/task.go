@@ -1,32 +1,53 @@
 package cmd
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
+	"io"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 
 	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
 	boshdir "github.com/cloudfoundry/bosh-cli/v7/director"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
 	boshuit "github.com/cloudfoundry/bosh-cli/v7/ui/task"
 )
 
 type TaskCmd struct {
+	ui                 boshui.UI
 	eventsTaskReporter boshuit.Reporter
 	plainTaskReporter  boshuit.Reporter
 	director           boshdir.Director
+	offsets            TaskOffsets
 }
 
 func NewTaskCmd(
+	ui boshui.UI,
 	eventsTaskReporter boshuit.Reporter,
 	plainTaskReporter boshuit.Reporter,
 	director boshdir.Director,
+	offsets TaskOffsets,
 ) TaskCmd {
 	return TaskCmd{
+		ui:                 ui,
 		eventsTaskReporter: eventsTaskReporter,
 		plainTaskReporter:  plainTaskReporter,
 		director:           director,
+		offsets:            offsets,
 	}
 }
 
 func (c TaskCmd) Run(opts TaskOpts) error {
+	if opts.Attach && (opts.Event || opts.CPI || opts.Debug || opts.Result) {
+		return errors.New("Expected --attach to be used without --event, --cpi, --debug or --result")
+	}
+
+	if opts.Timeline && (opts.Attach || opts.Event || opts.CPI || opts.Debug || opts.Result) {
+		return errors.New("Expected --timeline to be used without --attach, --event, --cpi, --debug or --result")
+	}
+
 	var task boshdir.Task
 
 	var err error
@@ -54,6 +75,10 @@ func (c TaskCmd) Run(opts TaskOpts) error {
 	}
 
 	switch {
+	case opts.Attach:
+		err = c.attach(task)
+	case opts.Timeline:
+		err = c.timeline(task, opts.TimelineFormat)
 	case opts.Event:
 		err = task.EventOutput(c.plainTaskReporter)
 	case opts.CPI:
@@ -68,3 +93,85 @@ func (c TaskCmd) Run(opts TaskOpts) error {
 
 	return err
 }
+
+func (c TaskCmd) attach(task boshdir.Task) error {
+	offset, err := c.offsets.Offset(task.ID())
+	if err != nil {
+		return err
+	}
+
+	reporter := newTaskOffsetReporter(c.eventsTaskReporter, c.offsets, offset)
+
+	err = task.EventOutputFrom(offset, reporter)
+	if err != nil {
+		return err
+	}
+
+	if reporter.err != nil {
+		return reporter.err
+	}
+
+	return c.errandResult(task)
+}
+
+// errandResult fails like run-errand does when an attached errand
+// finished with a non-zero exit code on any of its instances
+func (c TaskCmd) errandResult(task boshdir.Task) error {
+	errandName, found := strings.CutPrefix(task.Description(), "run errand ")
+	if !found {
+		return nil
+	}
+
+	errandName, _, _ = strings.Cut(errandName, " from deployment ")
+
+	result := &taskResultCollector{}
+
+	err := task.ResultOutput(result)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading result of errand '%s'", errandName)
+	}
+
+	dec := json.NewDecoder(&result.Buffer)
+
+	for {
+		var errandRunResp boshdir.ErrandRunResp
+		if err := dec.Decode(&errandRunResp); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return bosherr.WrapErrorf(err, "Unmarshaling result of errand '%s'", errandName)
+		}
+
+		err = errandExitCodeErr(errandName, errandRunResp.ExitCode)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+type taskResultCollector struct {
+	bytes.Buffer
+}
+
+func (c *taskResultCollector) TaskStarted(int)                     {}
+func (c *taskResultCollector) TaskFinished(int, string)            {}
+func (c *taskResultCollector) TaskOutputChunk(_ int, chunk []byte) { c.Write(chunk) } //nolint:errcheck
+
+// timeline is also shown for failed tasks before returning their error
+func (c TaskCmd) timeline(task boshdir.Task, format string) error {
+	reporter := boshuit.NewTimelineReporter()
+
+	taskErr := task.EventOutput(reporter)
+
+	timeline := reporter.Timeline(task.ID())
+
+	if len(timeline.Stages) == 0 && taskErr != nil {
+		return taskErr
+	}
+
+	err := NewTaskTimelinePrinter(c.ui).Print(task.ID(), timeline, format)
+	if err != nil {
+		return err
+	}
+
+	return taskErr
+}
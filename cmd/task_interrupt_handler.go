@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"syscall"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/v7/director"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+type TaskInterruptHandler struct {
+	ui            boshui.UI
+	defaultAction boshdir.TaskInterruptAction
+
+	signalNotifyFunc func(chan<- os.Signal, ...os.Signal)
+	signalStopFunc   func(chan<- os.Signal)
+}
+
+var _ boshdir.TaskInterruptHandler = TaskInterruptHandler{}
+
+// NewTaskInterruptHandler asks what to do with an interrupted task
+// unless the UI is non-interactive, in which case the default action is taken
+func NewTaskInterruptHandler(
+	ui boshui.UI,
+	defaultAction boshdir.TaskInterruptAction,
+	signalNotifyFunc func(chan<- os.Signal, ...os.Signal),
+	signalStopFunc func(chan<- os.Signal),
+) TaskInterruptHandler {
+	return TaskInterruptHandler{
+		ui:            ui,
+		defaultAction: defaultAction,
+
+		signalNotifyFunc: signalNotifyFunc,
+		signalStopFunc:   signalStopFunc,
+	}
+}
+
+func (h TaskInterruptHandler) Notify(ch chan<- os.Signal) {
+	h.signalNotifyFunc(ch, os.Interrupt, syscall.SIGTERM)
+}
+
+func (h TaskInterruptHandler) Stop(ch chan<- os.Signal) {
+	h.signalStopFunc(ch)
+}
+
+func (h TaskInterruptHandler) TaskInterrupted(id int) boshdir.TaskInterruptAction {
+	if !h.ui.IsInteractive() {
+		h.ui.PrintLinef("\nReceived a signal, using '%s' for task '%d' (see --on-task-interrupt)", h.defaultAction, id)
+		return h.defaultAction
+	}
+
+	actions := []boshdir.TaskInterruptAction{
+		boshdir.TaskInterruptCancel,
+		boshdir.TaskInterruptDetach,
+		boshdir.TaskInterruptWatch,
+	}
+
+	options := []string{
+		"Cancel the task",
+		"Stop tracking the task and leave it running",
+		"Keep tracking the task",
+	}
+
+	h.ui.PrintLinef("")
+
+	choice, err := h.ui.AskForChoice(fmt.Sprintf("Task '%d' is still running on the Director", id), options)
+	if err != nil {
+		return h.defaultAction
+	}
+
+	return actions[choice]
+}
@@ -0,0 +1,79 @@
+package cmd_test
+
+import (
+	"errors"
+	"os"
+	"syscall"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	boshdir "github.com/cloudfoundry/bosh-cli/v7/director"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("TaskInterruptHandler", func() {
+	var (
+		ui       *fakeui.FakeUI
+		notified []os.Signal
+		stopped  bool
+		handler  cmd.TaskInterruptHandler
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{Interactive: true}
+		notified = nil
+		stopped = false
+
+		signalNotifyFunc := func(_ chan<- os.Signal, sigs ...os.Signal) { notified = sigs }
+		signalStopFunc := func(chan<- os.Signal) { stopped = true }
+
+		handler = cmd.NewTaskInterruptHandler(ui, boshdir.TaskInterruptDetach, signalNotifyFunc, signalStopFunc)
+	})
+
+	It("notifies about interrupts and terminations until stopped", func() {
+		ch := make(chan os.Signal, 1)
+
+		handler.Notify(ch)
+		Expect(notified).To(Equal([]os.Signal{os.Interrupt, syscall.SIGTERM}))
+
+		handler.Stop(ch)
+		Expect(stopped).To(BeTrue())
+	})
+
+	It("asks whether to cancel, detach from or watch the task", func() {
+		ui.AskedChoiceChosens = []int{0}
+		ui.AskedChoiceErrs = []error{nil}
+
+		Expect(handler.TaskInterrupted(123)).To(Equal(boshdir.TaskInterruptCancel))
+		Expect(ui.AskedChoiceLabel).To(Equal("Task '123' is still running on the Director"))
+		Expect(ui.AskedChoiceOptions).To(Equal([]string{
+			"Cancel the task",
+			"Stop tracking the task and leave it running",
+			"Keep tracking the task",
+		}))
+	})
+
+	It("returns the chosen action", func() {
+		ui.AskedChoiceChosens = []int{2}
+		ui.AskedChoiceErrs = []error{nil}
+
+		Expect(handler.TaskInterrupted(123)).To(Equal(boshdir.TaskInterruptWatch))
+	})
+
+	It("returns the default action when asking fails", func() {
+		ui.AskedChoiceChosens = []int{0}
+		ui.AskedChoiceErrs = []error{errors.New("fake-err")}
+
+		Expect(handler.TaskInterrupted(123)).To(Equal(boshdir.TaskInterruptDetach))
+	})
+
+	It("returns the default action without asking when non-interactive", func() {
+		ui.Interactive = false
+
+		Expect(handler.TaskInterrupted(123)).To(Equal(boshdir.TaskInterruptDetach))
+		Expect(ui.AskedChoiceCalled).To(BeFalse())
+		Expect(ui.Said).To(ContainElement("\nReceived a signal, using 'detach' for task '123' (see --on-task-interrupt)"))
+	})
+})
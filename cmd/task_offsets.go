@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strconv"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	boshdir "github.com/cloudfoundry/bosh-cli/v7/director"
+)
+
+// TaskOffsets remembers how much of a task's event log was already shown
+// so that 'bosh task ID --attach' can resume where it stopped
+type TaskOffsets interface {
+	Offset(id int) (int, error)
+	Save(id, offset int) error
+	Forget(id int) error
+}
+
+type FSTaskOffsets struct {
+	path        string
+	environment string
+	fs          boshsys.FileSystem
+}
+
+// fsTaskOffsetsSchema maps environment URLs to task IDs to event log offsets
+type fsTaskOffsetsSchema map[string]map[string]int
+
+// NewFSTaskOffsets keeps offsets next to the CLI config file
+func NewFSTaskOffsets(configPath, environment string, fs boshsys.FileSystem) (FSTaskOffsets, error) {
+	absPath, err := fs.ExpandPath(configPath)
+	if err != nil {
+		return FSTaskOffsets{}, err
+	}
+
+	path := filepath.Join(filepath.Dir(absPath), "task_offsets.json")
+
+	return FSTaskOffsets{path: path, environment: environment, fs: fs}, nil
+}
+
+func (o FSTaskOffsets) Offset(id int) (int, error) {
+	schema, err := o.read()
+	if err != nil {
+		return 0, err
+	}
+
+	return schema[o.environment][strconv.Itoa(id)], nil
+}
+
+func (o FSTaskOffsets) Save(id, offset int) error {
+	schema, err := o.read()
+	if err != nil {
+		return err
+	}
+
+	if schema[o.environment] == nil {
+		schema[o.environment] = map[string]int{}
+	}
+
+	schema[o.environment][strconv.Itoa(id)] = offset
+
+	return o.write(schema)
+}
+
+func (o FSTaskOffsets) Forget(id int) error {
+	schema, err := o.read()
+	if err != nil {
+		return err
+	}
+
+	if _, found := schema[o.environment][strconv.Itoa(id)]; !found {
+		return nil
+	}
+
+	delete(schema[o.environment], strconv.Itoa(id))
+
+	if len(schema[o.environment]) == 0 {
+		delete(schema, o.environment)
+	}
+
+	return o.write(schema)
+}
+
+func (o FSTaskOffsets) read() (fsTaskOffsetsSchema, error) {
+	schema := fsTaskOffsetsSchema{}
+
+	if !o.fs.FileExists(o.path) {
+		return schema, nil
+	}
+
+	bytes, err := o.fs.ReadFile(o.path)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Reading task offsets '%s'", o.path)
+	}
+
+	err = json.Unmarshal(bytes, &schema)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Unmarshalling task offsets '%s'", o.path)
+	}
+
+	return schema, nil
+}
+
+func (o FSTaskOffsets) write(schema fsTaskOffsetsSchema) error {
+	bytes, err := json.Marshal(schema)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling task offsets")
+	}
+
+	err = o.fs.WriteFile(o.path, bytes)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing task offsets '%s'", o.path)
+	}
+
+	return nil
+}
+
+// taskOffsetReporter saves the offset of the last complete event line
+// so that a resumed attach never starts in the middle of an event
+type taskOffsetReporter struct {
+	reporter boshdir.TaskReporter
+	offsets  TaskOffsets
+
+	offset int
+	err    error
+}
+
+func newTaskOffsetReporter(reporter boshdir.TaskReporter, offsets TaskOffsets, offset int) *taskOffsetReporter {
+	return &taskOffsetReporter{reporter: reporter, offsets: offsets, offset: offset}
+}
+
+func (r *taskOffsetReporter) TaskStarted(id int) {
+	r.reporter.TaskStarted(id)
+}
+
+func (r *taskOffsetReporter) TaskStateChanged(id int, state string) {
+	if stateReporter, ok := r.reporter.(boshdir.TaskStateReporter); ok {
+		stateReporter.TaskStateChanged(id, state)
+	}
+}
+
+func (r *taskOffsetReporter) TaskFinished(id int, state string) {
+	r.reporter.TaskFinished(id, state)
+
+	switch state {
+	case "", "queued", "processing", "cancelling":
+		return
+	}
+
+	r.keepErr(r.offsets.Forget(id))
+}
+
+func (r *taskOffsetReporter) TaskOutputChunk(id int, chunk []byte) {
+	r.reporter.TaskOutputChunk(id, chunk)
+
+	if len(chunk) == 0 {
+		return
+	}
+
+	if idx := bytes.LastIndexByte(chunk, '\n'); idx >= 0 {
+		r.keepErr(r.offsets.Save(id, r.offset+idx+1))
+	}
+
+	r.offset += len(chunk)
+}
+
+func (r *taskOffsetReporter) keepErr(err error) {
+	if r.err == nil {
+		r.err = err
+	}
+}
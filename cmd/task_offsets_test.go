@@ -0,0 +1,77 @@
+package cmd_test
+
+import (
+	"errors"
+
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+)
+
+var _ = Describe("FSTaskOffsets", func() {
+	var (
+		fs      *fakesys.FakeFileSystem
+		offsets cmd.FSTaskOffsets
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+
+		var err error
+		offsets, err = cmd.NewFSTaskOffsets("/dir/config", "env-url", fs)
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("returns zero offset for unknown tasks", func() {
+		Expect(offsets.Offset(1)).To(Equal(0))
+	})
+
+	It("saves offsets per environment next to the config file", func() {
+		Expect(offsets.Save(1, 10)).To(Succeed())
+
+		otherOffsets, err := cmd.NewFSTaskOffsets("/dir/config", "other-env-url", fs)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(otherOffsets.Save(1, 20)).To(Succeed())
+
+		Expect(offsets.Offset(1)).To(Equal(10))
+		Expect(otherOffsets.Offset(1)).To(Equal(20))
+
+		Expect(fs.ReadFileString("/dir/task_offsets.json")).To(MatchJSON(
+			`{"env-url":{"1":10},"other-env-url":{"1":20}}`))
+	})
+
+	It("forgets offsets", func() {
+		Expect(offsets.Save(1, 10)).To(Succeed())
+		Expect(offsets.Save(2, 20)).To(Succeed())
+		Expect(offsets.Forget(1)).To(Succeed())
+
+		Expect(offsets.Offset(1)).To(Equal(0))
+		Expect(offsets.Offset(2)).To(Equal(20))
+
+		Expect(offsets.Forget(2)).To(Succeed())
+		Expect(fs.ReadFileString("/dir/task_offsets.json")).To(MatchJSON(`{}`))
+	})
+
+	It("does not write anything when forgetting unknown tasks", func() {
+		Expect(offsets.Forget(1)).To(Succeed())
+		Expect(fs.FileExists("/dir/task_offsets.json")).To(BeFalse())
+	})
+
+	It("returns error if offsets cannot be read", func() {
+		Expect(fs.WriteFileString("/dir/task_offsets.json", "-")).To(Succeed())
+
+		_, err := offsets.Offset(1)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Unmarshalling task offsets '/dir/task_offsets.json'"))
+	})
+
+	It("returns error if offsets cannot be written", func() {
+		fs.WriteFileError = errors.New("fake-err")
+
+		err := offsets.Save(1, 10)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("fake-err"))
+	})
+})
@@ -3,6 +3,7 @@ package cmd_test
 import (
 	"errors"
 
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
@@ -10,21 +11,33 @@ import (
 	"github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
 	boshdir "github.com/cloudfoundry/bosh-cli/v7/director"
 	fakedir "github.com/cloudfoundry/bosh-cli/v7/director/directorfakes"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
 )
 
 var _ = Describe("TaskCmd", func() {
 	var (
+		ui        *fakeui.FakeUI
 		eventsRep *fakedir.FakeTaskReporter
 		plainRep  *fakedir.FakeTaskReporter
 		director  *fakedir.FakeDirector
+		fs        *fakesys.FakeFileSystem
+		offsets   cmd.FSTaskOffsets
 		command   cmd.TaskCmd
 	)
 
 	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
 		eventsRep = &fakedir.FakeTaskReporter{}
 		plainRep = &fakedir.FakeTaskReporter{}
 		director = &fakedir.FakeDirector{}
-		command = cmd.NewTaskCmd(eventsRep, plainRep, director)
+		fs = fakesys.NewFakeFileSystem()
+
+		var err error
+		offsets, err = cmd.NewFSTaskOffsets("/config", "env-url", fs)
+		Expect(err).ToNot(HaveOccurred())
+
+		command = cmd.NewTaskCmd(ui, eventsRep, plainRep, director, offsets)
 	})
 
 	Describe("Run", func() {
@@ -122,6 +135,251 @@ var _ = Describe("TaskCmd", func() {
 				Expect(err.Error()).To(ContainSubstring("fake-err"))
 			})
 
+			Context("when attaching", func() {
+				BeforeEach(func() {
+					taskOpts.Attach = true
+					task.IDStub = func() int { return 123 }
+				})
+
+				It("shows task's 'event' output from the beginning the first time", func() {
+					err := act()
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(task.EventOutputFromCallCount()).To(Equal(1))
+
+					offset, _ := task.EventOutputFromArgsForCall(0)
+					Expect(offset).To(Equal(0))
+				})
+
+				It("resumes from the end of the last complete line shown before", func() {
+					task.EventOutputFromStub = func(offset int, rep boshdir.TaskReporter) error {
+						rep.TaskStarted(123)
+						rep.TaskOutputChunk(123, []byte("line1\nline2\npart"))
+						rep.TaskFinished(123, "processing")
+						return errors.New("fake-detached-err")
+					}
+
+					err := act()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("fake-detached-err"))
+
+					Expect(eventsRep.TaskOutputChunkCallCount()).To(Equal(1))
+
+					task.EventOutputFromStub = nil
+
+					err = act()
+					Expect(err).ToNot(HaveOccurred())
+
+					offset, _ := task.EventOutputFromArgsForCall(1)
+					Expect(offset).To(Equal(12))
+				})
+
+				It("forgets the offset once the task finishes", func() {
+					task.EventOutputFromStub = func(offset int, rep boshdir.TaskReporter) error {
+						rep.TaskOutputChunk(123, []byte("line1\n"))
+						rep.TaskFinished(123, "done")
+						return nil
+					}
+
+					err := act()
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(offsets.Offset(123)).To(Equal(0))
+				})
+
+				Context("when the task runs an errand", func() {
+					BeforeEach(func() {
+						task.DescriptionReturns("run errand smoke-tests from deployment dep")
+					})
+
+					It("succeeds when all instances exited with 0", func() {
+						task.ResultOutputStub = func(rep boshdir.TaskReporter) error {
+							rep.TaskOutputChunk(123, []byte(`{"exit_code":0}`+"\n"+`{"exit_code":0}`+"\n"))
+							return nil
+						}
+
+						err := act()
+						Expect(err).ToNot(HaveOccurred())
+						Expect(task.ResultOutputCallCount()).To(Equal(1))
+					})
+
+					It("returns error when an instance exited with a non-zero exit code", func() {
+						task.ResultOutputStub = func(rep boshdir.TaskReporter) error {
+							rep.TaskOutputChunk(123, []byte(`{"exit_code":0}`+"\n"+`{"exit_code":1}`+"\n"))
+							return nil
+						}
+
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(Equal("Errand 'smoke-tests' completed with error (exit code 1)"))
+					})
+
+					It("returns error if the result cannot be read", func() {
+						task.ResultOutputReturns(errors.New("fake-err"))
+
+						err := act()
+						Expect(err).To(HaveOccurred())
+						Expect(err.Error()).To(ContainSubstring("fake-err"))
+					})
+				})
+
+				It("does not read the result of other tasks", func() {
+					task.DescriptionReturns("create deployment")
+
+					err := act()
+					Expect(err).ToNot(HaveOccurred())
+					Expect(task.ResultOutputCallCount()).To(Equal(0))
+				})
+
+				It("returns error if offset cannot be saved", func() {
+					fs.WriteFileError = errors.New("fake-err")
+
+					task.EventOutputFromStub = func(offset int, rep boshdir.TaskReporter) error {
+						rep.TaskOutputChunk(123, []byte("line1\n"))
+						return nil
+					}
+
+					err := act()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(ContainSubstring("fake-err"))
+				})
+
+				It("returns error if combined with other output types", func() {
+					taskOpts.Debug = true
+
+					err := act()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("Expected --attach to be used without --event, --cpi, --debug or --result"))
+
+					Expect(director.FindTaskCallCount()).To(Equal(0))
+				})
+			})
+
+			Context("when showing timeline", func() {
+				BeforeEach(func() {
+					taskOpts.Timeline = true
+					task.IDStub = func() int { return 123 }
+					task.EventOutputStub = func(rep boshdir.TaskReporter) error {
+						rep.TaskStarted(123)
+						rep.TaskOutputChunk(123, []byte(
+							`{"time":1452320600,"stage":"Updating instance","tags":["api"],"task":"api/0","index":1,"total":2,"state":"started"}`+"\n"+
+								`{"time":1452320610,"stage":"Updating instance","tags":["api"],"task":"api/0","index":1,"total":2,"state":"finished"}`+"\n"+
+								`{"time":1452320620,"stage":"Updating instance","tags":["api"],"task":"api/1","index":2,"total":2,"state":"started"}`+"\n"+
+								`{"time":1452320625,"stage":"Updating instance","tags":["api"],"task":"api/1","index":2,"total":2,"state":"failed"}`+"\n"))
+						rep.TaskFinished(123, "error")
+						return errors.New("fake-task-err")
+					}
+				})
+
+				It("shows steps as a text Gantt chart with critical path and idle gaps", func() {
+					err := act()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("fake-task-err"))
+
+					Expect(ui.Tables).To(HaveLen(3))
+
+					Expect(ui.Tables[0].Content).To(Equal("timeline steps"))
+					Expect(ui.Tables[0].Notes).To(Equal([]string{
+						"Task took 00:00:25, steps marked with '#' are on the critical path",
+					}))
+					Expect(ui.Tables[0].Rows).To(Equal([][]boshtbl.Value{
+						{
+							boshtbl.NewValueString("Updating instance api"),
+							boshtbl.NewValueString("api/0"),
+							boshtbl.NewValueString("+00:00:00"),
+							boshtbl.NewValueString("00:00:10"),
+							boshtbl.ValueFmt{V: boshtbl.NewValueString("finished")},
+							boshtbl.NewValueString("####################.............................."),
+						},
+						{
+							boshtbl.NewValueString("Updating instance api"),
+							boshtbl.NewValueString("api/1"),
+							boshtbl.NewValueString("+00:00:20"),
+							boshtbl.NewValueString("00:00:05"),
+							boshtbl.ValueFmt{V: boshtbl.NewValueString("failed"), Error: true},
+							boshtbl.NewValueString("........................................##########"),
+						},
+					}))
+
+					Expect(ui.Tables[1].Title).To(Equal("Critical path"))
+					Expect(ui.Tables[1].Rows).To(HaveLen(2))
+
+					Expect(ui.Tables[2].Title).To(Equal("Idle gaps"))
+					Expect(ui.Tables[2].Rows).To(Equal([][]boshtbl.Value{
+						{boshtbl.NewValueString("+00:00:10"), boshtbl.NewValueString("00:00:10")},
+					}))
+				})
+
+				It("shows steps as CSV if requested", func() {
+					taskOpts.TimelineFormat = "csv"
+
+					err := act()
+					Expect(err).To(HaveOccurred())
+
+					Expect(ui.Blocks).To(Equal([]string{
+						"stage,tags,task,index,total,state,started_at,finished_at,duration_seconds,critical\n" +
+							"Updating instance,api,api/0,1,2,finished,2016-01-09T06:23:20Z,2016-01-09T06:23:30Z,10,true\n" +
+							"Updating instance,api,api/1,2,2,failed,2016-01-09T06:23:40Z,2016-01-09T06:23:45Z,5,true\n",
+					}))
+				})
+
+				It("shows timeline as JSON if requested", func() {
+					taskOpts.TimelineFormat = "json"
+
+					err := act()
+					Expect(err).To(HaveOccurred())
+
+					Expect(ui.Blocks).To(HaveLen(1))
+					Expect(ui.Blocks[0]).To(MatchJSON(`{
+						"task_id": 123,
+						"started_at": "2016-01-09T06:23:20Z",
+						"finished_at": "2016-01-09T06:23:45Z",
+						"duration_seconds": 25,
+						"stages": [{
+							"name": "Updating instance",
+							"tags": ["api"],
+							"started_at": "2016-01-09T06:23:20Z",
+							"finished_at": "2016-01-09T06:23:45Z",
+							"duration_seconds": 25,
+							"steps": [
+								{"stage": "Updating instance", "tags": ["api"], "task": "api/0", "index": 1, "total": 2, "state": "finished",
+								 "started_at": "2016-01-09T06:23:20Z", "finished_at": "2016-01-09T06:23:30Z", "duration_seconds": 10, "critical": true},
+								{"stage": "Updating instance", "tags": ["api"], "task": "api/1", "index": 2, "total": 2, "state": "failed",
+								 "started_at": "2016-01-09T06:23:40Z", "finished_at": "2016-01-09T06:23:45Z", "duration_seconds": 5, "critical": true}
+							]
+						}],
+						"critical_path": [
+							{"stage": "Updating instance", "tags": ["api"], "task": "api/0", "index": 1, "total": 2, "state": "finished",
+							 "started_at": "2016-01-09T06:23:20Z", "finished_at": "2016-01-09T06:23:30Z", "duration_seconds": 10, "critical": true},
+							{"stage": "Updating instance", "tags": ["api"], "task": "api/1", "index": 2, "total": 2, "state": "failed",
+							 "started_at": "2016-01-09T06:23:40Z", "finished_at": "2016-01-09T06:23:45Z", "duration_seconds": 5, "critical": true}
+						],
+						"idle_gaps": [{
+							"started_at": "2016-01-09T06:23:30Z",
+							"finished_at": "2016-01-09T06:23:40Z",
+							"duration_seconds": 10
+						}]
+					}`))
+				})
+
+				It("returns task error without showing timeline if there are no events", func() {
+					task.EventOutputStub = func(boshdir.TaskReporter) error { return errors.New("fake-err") }
+
+					err := act()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("fake-err"))
+					Expect(ui.Tables).To(BeEmpty())
+				})
+
+				It("returns error if combined with other output types", func() {
+					taskOpts.Attach = true
+
+					err := act()
+					Expect(err).To(HaveOccurred())
+					Expect(err.Error()).To(Equal("Expected --timeline to be used without --attach, --event, --cpi, --debug or --result"))
+				})
+			})
+
 			It("returns error if task cannot be retrieved", func() {
 				director.FindTaskReturns(nil, errors.New("fake-err"))
 
@@ -0,0 +1,318 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	boshuifmt "github.com/cloudfoundry/bosh-cli/v7/ui/fmt"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+	boshuit "github.com/cloudfoundry/bosh-cli/v7/ui/task"
+)
+
+const taskTimelineBarWidth = 50
+
+type TaskTimelinePrinter struct {
+	ui boshui.UI
+}
+
+type taskTimelineJSON struct {
+	TaskID          int     `json:"task_id"`
+	StartedAt       string  `json:"started_at"`
+	FinishedAt      string  `json:"finished_at"`
+	DurationSeconds float64 `json:"duration_seconds"`
+
+	Stages       []taskTimelineStageJSON `json:"stages"`
+	CriticalPath []taskTimelineStepJSON  `json:"critical_path"`
+	IdleGaps     []taskTimelineGapJSON   `json:"idle_gaps"`
+}
+
+type taskTimelineStageJSON struct {
+	Name            string                 `json:"name"`
+	Tags            []string               `json:"tags"`
+	StartedAt       string                 `json:"started_at"`
+	FinishedAt      string                 `json:"finished_at"`
+	DurationSeconds float64                `json:"duration_seconds"`
+	Steps           []taskTimelineStepJSON `json:"steps"`
+}
+
+type taskTimelineStepJSON struct {
+	Stage           string   `json:"stage"`
+	Tags            []string `json:"tags"`
+	Task            string   `json:"task"`
+	Index           int      `json:"index"`
+	Total           int      `json:"total"`
+	State           string   `json:"state"`
+	StartedAt       string   `json:"started_at"`
+	FinishedAt      string   `json:"finished_at"`
+	DurationSeconds float64  `json:"duration_seconds"`
+	Critical        bool     `json:"critical"`
+}
+
+type taskTimelineGapJSON struct {
+	StartedAt       string  `json:"started_at"`
+	FinishedAt      string  `json:"finished_at"`
+	DurationSeconds float64 `json:"duration_seconds"`
+}
+
+func NewTaskTimelinePrinter(ui boshui.UI) TaskTimelinePrinter {
+	return TaskTimelinePrinter{ui: ui}
+}
+
+func (p TaskTimelinePrinter) Print(id int, timeline boshuit.Timeline, format string) error {
+	switch format {
+	case "", "text":
+		p.printText(timeline)
+		return nil
+	case "csv":
+		return p.printCSV(timeline)
+	case "json":
+		return p.printJSON(id, timeline)
+	default:
+		return bosherr.Errorf("Unknown timeline format '%s'", format)
+	}
+}
+
+func (p TaskTimelinePrinter) printText(timeline boshuit.Timeline) {
+	critical := p.criticalSteps(timeline)
+
+	stepsTable := boshtbl.Table{
+		Content: "timeline steps",
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Stage"),
+			boshtbl.NewHeader("Task"),
+			boshtbl.NewHeader("Start"),
+			boshtbl.NewHeader("Duration"),
+			boshtbl.NewHeader("State"),
+			boshtbl.NewHeader("Timeline"),
+		},
+		SortBy: []boshtbl.ColumnSort{{Column: 2, Asc: true}, {Column: 0, Asc: true}},
+		Notes: []string{
+			fmt.Sprintf("Task took %s, steps marked with '#' are on the critical path", boshuifmt.Duration(timeline.Duration())),
+		},
+	}
+
+	for _, step := range timeline.Steps() {
+		stepsTable.Rows = append(stepsTable.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(p.stageDesc(step.Stage, step.Tags)),
+			boshtbl.NewValueString(step.Task),
+			boshtbl.NewValueString(p.offset(timeline, step.Started)),
+			boshtbl.NewValueString(boshuifmt.Duration(step.Duration())),
+			boshtbl.ValueFmt{
+				V:     boshtbl.NewValueString(step.State),
+				Error: step.State == boshuit.EventStateFailed,
+			},
+			boshtbl.NewValueString(p.bar(timeline, step, critical[step])),
+		})
+	}
+
+	p.ui.PrintTable(stepsTable)
+
+	criticalTable := boshtbl.Table{
+		Title:   "Critical path",
+		Content: "critical path steps",
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Stage"),
+			boshtbl.NewHeader("Task"),
+			boshtbl.NewHeader("Start"),
+			boshtbl.NewHeader("Duration"),
+		},
+		SortBy: []boshtbl.ColumnSort{{Column: 2, Asc: true}},
+	}
+
+	for _, step := range timeline.CriticalPath() {
+		criticalTable.Rows = append(criticalTable.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(p.stageDesc(step.Stage, step.Tags)),
+			boshtbl.NewValueString(step.Task),
+			boshtbl.NewValueString(p.offset(timeline, step.Started)),
+			boshtbl.NewValueString(boshuifmt.Duration(step.Duration())),
+		})
+	}
+
+	p.ui.PrintTable(criticalTable)
+
+	gapsTable := boshtbl.Table{
+		Title:   "Idle gaps",
+		Content: "idle gaps",
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("Start"),
+			boshtbl.NewHeader("Duration"),
+		},
+		SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+	}
+
+	for _, gap := range timeline.IdleGaps() {
+		gapsTable.Rows = append(gapsTable.Rows, []boshtbl.Value{
+			boshtbl.NewValueString(p.offset(timeline, gap.Started)),
+			boshtbl.NewValueString(boshuifmt.Duration(gap.Duration())),
+		})
+	}
+
+	p.ui.PrintTable(gapsTable)
+}
+
+func (p TaskTimelinePrinter) printCSV(timeline boshuit.Timeline) error {
+	critical := p.criticalSteps(timeline)
+
+	buf := bytes.NewBuffer(nil)
+	writer := csv.NewWriter(buf)
+
+	records := [][]string{{
+		"stage", "tags", "task", "index", "total", "state",
+		"started_at", "finished_at", "duration_seconds", "critical",
+	}}
+
+	for _, step := range timeline.Steps() {
+		records = append(records, []string{
+			step.Stage,
+			strings.Join(step.Tags, ","),
+			step.Task,
+			strconv.Itoa(step.Index),
+			strconv.Itoa(step.Total),
+			step.State,
+			p.time(step.Started),
+			p.time(step.Finished),
+			strconv.FormatFloat(step.Duration().Seconds(), 'f', -1, 64),
+			strconv.FormatBool(critical[step]),
+		})
+	}
+
+	err := writer.WriteAll(records)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing timeline as CSV")
+	}
+
+	p.ui.PrintBlock(buf.Bytes())
+
+	return nil
+}
+
+func (p TaskTimelinePrinter) printJSON(id int, timeline boshuit.Timeline) error {
+	critical := p.criticalSteps(timeline)
+
+	result := taskTimelineJSON{
+		TaskID:          id,
+		StartedAt:       p.time(timeline.Started),
+		FinishedAt:      p.time(timeline.Finished),
+		DurationSeconds: timeline.Duration().Seconds(),
+
+		Stages:       []taskTimelineStageJSON{},
+		CriticalPath: []taskTimelineStepJSON{},
+		IdleGaps:     []taskTimelineGapJSON{},
+	}
+
+	for _, stage := range timeline.Stages {
+		stageJSON := taskTimelineStageJSON{
+			Name:            stage.Name,
+			Tags:            stage.Tags,
+			StartedAt:       p.time(stage.Started),
+			FinishedAt:      p.time(stage.Finished),
+			DurationSeconds: stage.Duration().Seconds(),
+			Steps:           []taskTimelineStepJSON{},
+		}
+
+		for _, step := range stage.Steps {
+			stageJSON.Steps = append(stageJSON.Steps, p.stepJSON(step, critical[step]))
+		}
+
+		result.Stages = append(result.Stages, stageJSON)
+	}
+
+	for _, step := range timeline.CriticalPath() {
+		result.CriticalPath = append(result.CriticalPath, p.stepJSON(step, true))
+	}
+
+	for _, gap := range timeline.IdleGaps() {
+		result.IdleGaps = append(result.IdleGaps, taskTimelineGapJSON{
+			StartedAt:       p.time(gap.Started),
+			FinishedAt:      p.time(gap.Finished),
+			DurationSeconds: gap.Duration().Seconds(),
+		})
+	}
+
+	bytes, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling timeline")
+	}
+
+	p.ui.PrintBlock(append(bytes, '\n'))
+
+	return nil
+}
+
+func (p TaskTimelinePrinter) stepJSON(step *boshuit.TimelineStep, critical bool) taskTimelineStepJSON {
+	return taskTimelineStepJSON{
+		Stage:           step.Stage,
+		Tags:            step.Tags,
+		Task:            step.Task,
+		Index:           step.Index,
+		Total:           step.Total,
+		State:           step.State,
+		StartedAt:       p.time(step.Started),
+		FinishedAt:      p.time(step.Finished),
+		DurationSeconds: step.Duration().Seconds(),
+		Critical:        critical,
+	}
+}
+
+func (p TaskTimelinePrinter) criticalSteps(timeline boshuit.Timeline) map[*boshuit.TimelineStep]bool {
+	critical := map[*boshuit.TimelineStep]bool{}
+
+	for _, step := range timeline.CriticalPath() {
+		critical[step] = true
+	}
+
+	return critical
+}
+
+// bar draws a step relative to the whole timeline for a text Gantt chart
+func (p TaskTimelinePrinter) bar(timeline boshuit.Timeline, step *boshuit.TimelineStep, critical bool) string {
+	start, length := 0, 1
+
+	if total := timeline.Duration(); total > 0 {
+		start = int(float64(step.Started.Sub(timeline.Started)) / float64(total) * taskTimelineBarWidth)
+		length = int(math.Ceil(float64(step.Duration()) / float64(total) * taskTimelineBarWidth))
+	}
+
+	if start >= taskTimelineBarWidth {
+		start = taskTimelineBarWidth - 1
+	}
+
+	if length < 1 {
+		length = 1
+	}
+
+	if start+length > taskTimelineBarWidth {
+		length = taskTimelineBarWidth - start
+	}
+
+	char := "="
+	if critical {
+		char = "#"
+	}
+
+	return strings.Repeat(".", start) + strings.Repeat(char, length) + strings.Repeat(".", taskTimelineBarWidth-start-length)
+}
+
+func (p TaskTimelinePrinter) stageDesc(stage string, tags []string) string {
+	if len(tags) > 0 {
+		return stage + " " + strings.Join(tags, ", ")
+	}
+	return stage
+}
+
+func (p TaskTimelinePrinter) offset(timeline boshuit.Timeline, t time.Time) string {
+	return "+" + boshuifmt.Duration(t.Sub(timeline.Started))
+}
+
+func (p TaskTimelinePrinter) time(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
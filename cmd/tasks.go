@@ -20,6 +20,8 @@ func (c TasksCmd) Run(opts TasksOpts) error {
 	filter := boshdir.TasksFilter{
 		All:        opts.All,
 		Deployment: opts.Deployment,
+		Types:      opts.Types,
+		States:     opts.States,
 	}
 
 	if opts.Recent != nil {
@@ -136,12 +136,16 @@ var _ = Describe("TasksCmd", func() {
 
 				tasksOpts.All = true
 				tasksOpts.Deployment = "deployment"
+				tasksOpts.Types = []string{"vms"}
+				tasksOpts.States = []string{"queued"}
 
 				err = act()
 				Expect(err).ToNot(HaveOccurred())
 				Expect(director.CurrentTasksArgsForCall(1)).To(Equal(boshdir.TasksFilter{
 					All:        true,
 					Deployment: "deployment",
+					Types:      []string{"vms"},
+					States:     []string{"queued"},
 				}))
 			})
 
@@ -252,12 +256,14 @@ var _ = Describe("TasksCmd", func() {
 
 				tasksOpts.All = true
 				tasksOpts.Deployment = "deployment"
+				tasksOpts.States = []string{"error"}
 
 				Expect(act()).ToNot(HaveOccurred())
 				_, filter = director.RecentTasksArgsForCall(1)
 				Expect(filter).To(Equal(boshdir.TasksFilter{
 					All:        true,
 					Deployment: "deployment",
+					States:     []string{"error"},
 				}))
 			})
 
@@ -0,0 +1,224 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	boshdir "github.com/cloudfoundry/bosh-cli/v7/director"
+	boshui "github.com/cloudfoundry/bosh-cli/v7/ui"
+	boshuifmt "github.com/cloudfoundry/bosh-cli/v7/ui/fmt"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+// Moves the cursor home and clears the screen before each refresh on a TTY
+const tasksWatchClearScreen = "\033[H\033[2J"
+
+// tasksWatchLimit bounds the tasks fetched on each refresh when states are
+// given without --recent since finished states would match every past task
+const tasksWatchLimit = 30
+
+type TasksWatchCmd struct {
+	ui          boshui.UI
+	director    boshdir.Director
+	timeService clock.Clock
+	isTTY       bool
+
+	signalNotifyFunc func(chan<- os.Signal, ...os.Signal)
+	signalStopFunc   func(chan<- os.Signal)
+}
+
+// NewTasksWatchCmd redraws current tasks on a TTY
+// and otherwise prints them again after each refresh
+func NewTasksWatchCmd(
+	ui boshui.UI,
+	director boshdir.Director,
+	timeService clock.Clock,
+	isTTY bool,
+	signalNotifyFunc func(chan<- os.Signal, ...os.Signal),
+	signalStopFunc func(chan<- os.Signal),
+) TasksWatchCmd {
+	return TasksWatchCmd{
+		ui:          ui,
+		director:    director,
+		timeService: timeService,
+		isTTY:       isTTY,
+
+		signalNotifyFunc: signalNotifyFunc,
+		signalStopFunc:   signalStopFunc,
+	}
+}
+
+func (c TasksWatchCmd) Run(opts TasksOpts) error {
+	if opts.Interval <= 0 {
+		return errors.New("Expected --interval to be greater than zero")
+	}
+
+	filter := boshdir.TasksFilter{
+		All:        opts.All,
+		Deployment: opts.Deployment,
+		Types:      opts.Types,
+		States:     opts.States,
+	}
+
+	interruptCh := make(chan os.Signal, 1)
+
+	c.signalNotifyFunc(interruptCh, os.Interrupt, syscall.SIGTERM)
+	defer c.signalStopFunc(interruptCh)
+
+	var lastStates map[int]string
+
+	for {
+		states, err := c.refresh(filter, opts.Recent, opts.Interval, lastStates)
+		if err != nil {
+			return err
+		}
+
+		lastStates = states
+
+		select {
+		case <-interruptCh:
+			return nil
+		case <-c.timeService.After(opts.Interval):
+		}
+	}
+}
+
+// refresh marks tasks that were not seen before as new and shows
+// the final state of tasks that are no longer current
+func (c TasksWatchCmd) refresh(filter boshdir.TasksFilter, recent *int, interval time.Duration, lastStates map[int]string) (map[int]string, error) {
+	tasks, err := c.tasks(filter, recent)
+	if err != nil {
+		return nil, err
+	}
+
+	locks, err := c.director.Locks()
+	if err != nil {
+		return nil, err
+	}
+
+	now := c.timeService.Now()
+
+	table := boshtbl.Table{
+		Title:   fmt.Sprintf("Every %s, last refreshed at %s", interval, now.Format(boshuifmt.TimeFullFmt)),
+		Content: "tasks",
+		Header: []boshtbl.Header{
+			boshtbl.NewHeader("ID"),
+			boshtbl.NewHeader("State"),
+			boshtbl.NewHeader("Change"),
+			boshtbl.NewHeader("Elapsed"),
+			boshtbl.NewHeader("Lock"),
+			boshtbl.NewHeader("User"),
+			boshtbl.NewHeader("Deployment"),
+			boshtbl.NewHeader("Description"),
+		},
+		SortBy: []boshtbl.ColumnSort{{Column: 0, Asc: true}},
+	}
+
+	states := map[int]string{}
+
+	for _, t := range tasks {
+		states[t.ID()] = t.State()
+
+		var change string
+
+		if lastState, found := lastStates[t.ID()]; !found && lastStates != nil {
+			change = "new"
+		} else if found && lastState != t.State() {
+			change = lastState + " -> " + t.State()
+		}
+
+		table.Rows = append(table.Rows, c.row(t, change, now, locks))
+	}
+
+	for id, lastState := range lastStates {
+		if _, found := states[id]; found {
+			continue
+		}
+
+		t, err := c.director.FindTask(id)
+		if err != nil {
+			return nil, err
+		}
+
+		// tasks also drop out of a limited list without changing their state
+		if t.State() == lastState {
+			continue
+		}
+
+		table.Rows = append(table.Rows, c.row(t, lastState+" -> "+t.State(), t.FinishedAt(), locks))
+	}
+
+	if c.isTTY {
+		c.ui.PrintBlock([]byte(tasksWatchClearScreen))
+	}
+
+	c.ui.PrintTable(table)
+
+	return states, nil
+}
+
+func (c TasksWatchCmd) tasks(filter boshdir.TasksFilter, recent *int) ([]boshdir.Task, error) {
+	if recent != nil {
+		return c.director.RecentTasks(*recent, filter)
+	}
+
+	if len(filter.States) > 0 {
+		return c.director.RecentTasks(tasksWatchLimit, filter)
+	}
+
+	return c.director.CurrentTasks(filter)
+}
+
+func (c TasksWatchCmd) row(t boshdir.Task, change string, until time.Time, locks []boshdir.Lock) []boshtbl.Value {
+	return []boshtbl.Value{
+		boshtbl.NewValueInt(t.ID()),
+		boshtbl.ValueFmt{
+			V:     boshtbl.NewValueString(t.State()),
+			Error: t.IsError(),
+		},
+		c.change(t, change),
+		c.elapsed(t, until),
+		boshtbl.NewValueString(c.taskLocks(t.ID(), locks)),
+		boshtbl.NewValueString(t.User()),
+		boshtbl.NewValueString(t.DeploymentName()),
+		boshtbl.NewValueString(t.Description()),
+	}
+}
+
+func (c TasksWatchCmd) change(t boshdir.Task, change string) boshtbl.Value {
+	switch change {
+	case "":
+		return boshtbl.NewValueString(change)
+	case "new":
+		return boshtbl.ValueFmt{V: boshtbl.NewValueString(change), Bold: true}
+	default:
+		return boshtbl.ValueFmt{V: boshtbl.NewValueString(change), Error: t.IsError()}
+	}
+}
+
+func (c TasksWatchCmd) elapsed(t boshdir.Task, until time.Time) boshtbl.Value {
+	if t.StartedAt().Unix() <= 0 {
+		return boshtbl.NewValueString("-")
+	}
+	return boshtbl.NewValueString(boshuifmt.Duration(until.Sub(t.StartedAt())))
+}
+
+func (c TasksWatchCmd) taskLocks(id int, locks []boshdir.Lock) string {
+	var descs []string
+
+	for _, l := range locks {
+		if l.TaskID == strconv.Itoa(id) {
+			descs = append(descs, strings.Join(append([]string{l.Type}, l.Resource...), ":"))
+		}
+	}
+
+	return strings.Join(descs, ", ")
+}
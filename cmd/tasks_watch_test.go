@@ -0,0 +1,316 @@
+package cmd_test
+
+import (
+	"errors"
+	"os"
+	"syscall"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	"github.com/cloudfoundry/bosh-cli/v7/cmd"
+	"github.com/cloudfoundry/bosh-cli/v7/cmd/opts"
+	boshdir "github.com/cloudfoundry/bosh-cli/v7/director"
+	fakedir "github.com/cloudfoundry/bosh-cli/v7/director/directorfakes"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+	boshtbl "github.com/cloudfoundry/bosh-cli/v7/ui/table"
+)
+
+var _ = Describe("TasksWatchCmd", func() {
+	var (
+		ui          *fakeui.FakeUI
+		director    *fakedir.FakeDirector
+		timeService *fakeclock.FakeClock
+		isTTY       bool
+
+		interruptCh     chan<- os.Signal
+		notifiedSignals []os.Signal
+		stoppedCh       chan<- os.Signal
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		director = &fakedir.FakeDirector{}
+		timeService = fakeclock.NewFakeClock(time.Date(2016, time.January, 9, 6, 23, 25, 0, time.UTC))
+		isTTY = false
+
+		interruptCh = nil
+		notifiedSignals = nil
+		stoppedCh = nil
+	})
+
+	Describe("Run", func() {
+		var (
+			tasksOpts opts.TasksOpts
+		)
+
+		BeforeEach(func() {
+			tasksOpts = opts.TasksOpts{Interval: 2 * time.Second}
+		})
+
+		act := func() error {
+			command := cmd.NewTasksWatchCmd(ui, director, timeService, isTTY,
+				func(ch chan<- os.Signal, sigs ...os.Signal) { interruptCh = ch; notifiedSignals = sigs },
+				func(ch chan<- os.Signal) { stoppedCh = ch },
+			)
+			return command.Run(tasksOpts)
+		}
+
+		newTask := func(id int, state string, startedAt time.Time) *fakedir.FakeTask {
+			return &fakedir.FakeTask{
+				IDStub:             func() int { return id },
+				StateStub:          func() string { return state },
+				IsErrorStub:        func() bool { return state == "error" },
+				StartedAtStub:      func() time.Time { return startedAt },
+				FinishedAtStub:     func() time.Time { return time.Unix(0, 0) },
+				UserStub:           func() string { return "user" },
+				DeploymentNameStub: func() string { return "dep" },
+				DescriptionStub:    func() string { return "desc" },
+			}
+		}
+
+		// refreshes returns given tasks on each refresh and interrupts after the last one
+		refreshes := func(tasksPerRefresh ...[]boshdir.Task) {
+			call := -1
+
+			refresh := func() ([]boshdir.Task, error) {
+				call++
+
+				if call == len(tasksPerRefresh)-1 {
+					interruptCh <- os.Interrupt
+				} else {
+					go timeService.WaitForWatcherAndIncrement(tasksOpts.Interval)
+				}
+
+				return tasksPerRefresh[call], nil
+			}
+
+			director.CurrentTasksStub = func(boshdir.TasksFilter) ([]boshdir.Task, error) { return refresh() }
+			director.RecentTasksStub = func(int, boshdir.TasksFilter) ([]boshdir.Task, error) { return refresh() }
+		}
+
+		It("refreshes current tasks until interrupted, highlighting new tasks and state changes", func() {
+			started := timeService.Now().Add(-90 * time.Second)
+
+			finishedTask := newTask(3, "done", started)
+			finishedTask.FinishedAtStub = func() time.Time { return started.Add(100 * time.Second) }
+			director.FindTaskReturns(finishedTask, nil)
+
+			director.LocksReturns([]boshdir.Lock{
+				{Type: "deployment", Resource: []string{"dep"}, TaskID: "4"},
+				{Type: "release", Resource: []string{"rel"}, TaskID: "5"},
+			}, nil)
+
+			refreshes(
+				[]boshdir.Task{
+					newTask(3, "processing", started),
+					newTask(4, "queued", time.Unix(0, 0)),
+				},
+				[]boshdir.Task{
+					newTask(4, "processing", started),
+					newTask(5, "queued", time.Unix(0, 0)),
+				},
+			)
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(notifiedSignals).To(Equal([]os.Signal{os.Interrupt, syscall.SIGTERM}))
+			Expect(stoppedCh).To(Equal(interruptCh))
+
+			Expect(ui.Blocks).To(BeEmpty())
+			Expect(ui.Tables).To(HaveLen(2))
+
+			Expect(ui.Tables[0].Title).To(Equal("Every 2s, last refreshed at Sat Jan  9 06:23:25 UTC 2016"))
+			Expect(ui.Tables[0].Rows).To(Equal([][]boshtbl.Value{
+				{
+					boshtbl.NewValueInt(3),
+					boshtbl.ValueFmt{V: boshtbl.NewValueString("processing")},
+					boshtbl.NewValueString(""),
+					boshtbl.NewValueString("00:01:30"),
+					boshtbl.NewValueString(""),
+					boshtbl.NewValueString("user"),
+					boshtbl.NewValueString("dep"),
+					boshtbl.NewValueString("desc"),
+				},
+				{
+					boshtbl.NewValueInt(4),
+					boshtbl.ValueFmt{V: boshtbl.NewValueString("queued")},
+					boshtbl.NewValueString(""),
+					boshtbl.NewValueString("-"),
+					boshtbl.NewValueString("deployment:dep"),
+					boshtbl.NewValueString("user"),
+					boshtbl.NewValueString("dep"),
+					boshtbl.NewValueString("desc"),
+				},
+			}))
+
+			Expect(ui.Tables[1].Title).To(Equal("Every 2s, last refreshed at Sat Jan  9 06:23:27 UTC 2016"))
+			Expect(ui.Tables[1].Rows).To(Equal([][]boshtbl.Value{
+				{
+					boshtbl.NewValueInt(4),
+					boshtbl.ValueFmt{V: boshtbl.NewValueString("processing")},
+					boshtbl.ValueFmt{V: boshtbl.NewValueString("queued -> processing")},
+					boshtbl.NewValueString("00:01:32"),
+					boshtbl.NewValueString("deployment:dep"),
+					boshtbl.NewValueString("user"),
+					boshtbl.NewValueString("dep"),
+					boshtbl.NewValueString("desc"),
+				},
+				{
+					boshtbl.NewValueInt(5),
+					boshtbl.ValueFmt{V: boshtbl.NewValueString("queued")},
+					boshtbl.ValueFmt{V: boshtbl.NewValueString("new"), Bold: true},
+					boshtbl.NewValueString("-"),
+					boshtbl.NewValueString("release:rel"),
+					boshtbl.NewValueString("user"),
+					boshtbl.NewValueString("dep"),
+					boshtbl.NewValueString("desc"),
+				},
+				{
+					boshtbl.NewValueInt(3),
+					boshtbl.ValueFmt{V: boshtbl.NewValueString("done")},
+					boshtbl.ValueFmt{V: boshtbl.NewValueString("processing -> done")},
+					boshtbl.NewValueString("00:01:40"),
+					boshtbl.NewValueString(""),
+					boshtbl.NewValueString("user"),
+					boshtbl.NewValueString("dep"),
+					boshtbl.NewValueString("desc"),
+				},
+			}))
+
+			Expect(director.FindTaskCallCount()).To(Equal(1))
+			Expect(director.FindTaskArgsForCall(0)).To(Equal(3))
+		})
+
+		It("clears the screen before each refresh on a TTY", func() {
+			isTTY = true
+
+			refreshes([]boshdir.Task{}, []boshdir.Task{})
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Blocks).To(Equal([]string{"\033[H\033[2J", "\033[H\033[2J"}))
+			Expect(ui.Tables).To(HaveLen(2))
+		})
+
+		It("filters tasks based on options", func() {
+			tasksOpts.All = true
+			tasksOpts.Deployment = "dep"
+			tasksOpts.Types = []string{"vms"}
+
+			refreshes([]boshdir.Task{})
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(director.CurrentTasksArgsForCall(0)).To(Equal(boshdir.TasksFilter{
+				All:        true,
+				Deployment: "dep",
+				Types:      []string{"vms"},
+			}))
+		})
+
+		It("limits the tasks of given states to the most recent ones", func() {
+			tasksOpts.States = []string{"done"}
+
+			refreshes([]boshdir.Task{})
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(director.CurrentTasksCallCount()).To(Equal(0))
+
+			limit, filter := director.RecentTasksArgsForCall(0)
+			Expect(limit).To(Equal(30))
+			Expect(filter).To(Equal(boshdir.TasksFilter{States: []string{"done"}}))
+		})
+
+		It("limits tasks to the number of recent tasks if given", func() {
+			recent := 5
+			tasksOpts.Recent = &recent
+			tasksOpts.States = []string{"done"}
+
+			refreshes([]boshdir.Task{})
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			limit, _ := director.RecentTasksArgsForCall(0)
+			Expect(limit).To(Equal(5))
+		})
+
+		It("does not show tasks that dropped out of the limited tasks without changing their state", func() {
+			tasksOpts.States = []string{"done"}
+
+			director.FindTaskReturns(newTask(3, "done", time.Unix(0, 0)), nil)
+
+			refreshes(
+				[]boshdir.Task{newTask(3, "done", time.Unix(0, 0))},
+				[]boshdir.Task{newTask(4, "done", time.Unix(0, 0))},
+			)
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Tables).To(HaveLen(2))
+			Expect(ui.Tables[1].Rows).To(HaveLen(1))
+			Expect(ui.Tables[1].Rows[0][0]).To(Equal(boshtbl.NewValueInt(4)))
+		})
+
+		It("shows changes of tasks that failed as errors", func() {
+			refreshes(
+				[]boshdir.Task{newTask(3, "processing", time.Unix(0, 0))},
+				[]boshdir.Task{newTask(3, "error", time.Unix(0, 0))},
+			)
+
+			err := act()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(ui.Tables).To(HaveLen(2))
+			Expect(ui.Tables[1].Rows[0][2]).To(Equal(
+				boshtbl.ValueFmt{V: boshtbl.NewValueString("processing -> error"), Error: true}))
+		})
+
+		It("returns error if interval is not positive", func() {
+			tasksOpts.Interval = 0
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Expected --interval to be greater than zero"))
+
+			Expect(director.CurrentTasksCallCount()).To(Equal(0))
+		})
+
+		It("returns error if current tasks cannot be retrieved", func() {
+			director.CurrentTasksReturns(nil, errors.New("fake-err"))
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+			Expect(stoppedCh).ToNot(BeNil())
+		})
+
+		It("returns error if locks cannot be retrieved", func() {
+			director.CurrentTasksReturns(nil, nil)
+			director.LocksReturns(nil, errors.New("fake-err"))
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+		})
+
+		It("returns error if finished task cannot be retrieved", func() {
+			director.FindTaskReturns(nil, errors.New("fake-err"))
+
+			refreshes([]boshdir.Task{newTask(3, "processing", time.Unix(0, 0))}, []boshdir.Task{})
+
+			err := act()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-err"))
+		})
+	})
+})
@@ -0,0 +1,166 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// DeploymentStateHistoryLimit is the number of prior deployment state versions kept;
+// at most one version is added per command run
+const DeploymentStateHistoryLimit = 10
+
+const deploymentStateHistoryTimeFormat = "20060102T150405Z"
+
+type DeploymentStateVersion struct {
+	Version int
+	SavedAt time.Time
+	Path    string
+}
+
+// DeploymentStateHistory keeps numbered copies of prior deployment states
+// in a '<state path>.history' directory next to the state file
+type DeploymentStateHistory interface {
+	// Add keeps the contents of a state file that was saved at savedAt
+	Add(contents []byte, savedAt time.Time) error
+	List() ([]DeploymentStateVersion, error)
+	Find(version int) (DeploymentStateVersion, bool, error)
+	Read(version DeploymentStateVersion) ([]byte, DeploymentState, error)
+}
+
+type deploymentStateHistory struct {
+	dirPath string
+	limit   int
+	fs      boshsys.FileSystem
+}
+
+func NewDeploymentStateHistory(fs boshsys.FileSystem, deploymentStatePath string, limit int) DeploymentStateHistory {
+	return &deploymentStateHistory{
+		dirPath: DeploymentStateHistoryPath(deploymentStatePath),
+		limit:   limit,
+		fs:      fs,
+	}
+}
+
+func DeploymentStateHistoryPath(deploymentStatePath string) string {
+	return deploymentStatePath + ".history"
+}
+
+func (h *deploymentStateHistory) Add(contents []byte, savedAt time.Time) error {
+	versions, err := h.List()
+	if err != nil {
+		return err
+	}
+
+	nextVersion := 1
+	if len(versions) > 0 {
+		nextVersion = versions[len(versions)-1].Version + 1
+	}
+
+	err = h.fs.MkdirAll(h.dirPath, 0700)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Creating deployment state history directory '%s'", h.dirPath)
+	}
+
+	fileName := fmt.Sprintf("%d-%s.json", nextVersion, savedAt.UTC().Format(deploymentStateHistoryTimeFormat))
+
+	err = h.fs.WriteFile(filepath.Join(h.dirPath, fileName), contents)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing deployment state version %d", nextVersion)
+	}
+
+	// the version just written is not part of the listed versions
+	for i := 0; i < len(versions)+1-h.limit; i++ {
+		err = h.fs.RemoveAll(versions[i].Path)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Removing deployment state version %d", versions[i].Version)
+		}
+	}
+
+	return nil
+}
+
+func (h *deploymentStateHistory) List() ([]DeploymentStateVersion, error) {
+	versions := []DeploymentStateVersion{}
+
+	if !h.fs.FileExists(h.dirPath) {
+		return versions, nil
+	}
+
+	err := h.fs.Walk(h.dirPath, func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if info.IsDir() || filepath.Dir(path) != h.dirPath {
+			return nil
+		}
+
+		var (
+			version   int
+			timestamp string
+		)
+
+		_, err = fmt.Sscanf(filepath.Base(path), "%d-%16s", &version, &timestamp)
+		if err != nil {
+			return nil
+		}
+
+		savedAt, err := time.Parse(deploymentStateHistoryTimeFormat, timestamp)
+		if err != nil {
+			return nil
+		}
+
+		versions = append(versions, DeploymentStateVersion{
+			Version: version,
+			SavedAt: savedAt,
+			Path:    path,
+		})
+
+		return nil
+	})
+	if err != nil {
+		return versions, bosherr.WrapErrorf(err, "Listing deployment state history '%s'", h.dirPath)
+	}
+
+	sort.Slice(versions, func(i, j int) bool { return versions[i].Version < versions[j].Version })
+
+	return versions, nil
+}
+
+func (h *deploymentStateHistory) Find(version int) (DeploymentStateVersion, bool, error) {
+	versions, err := h.List()
+	if err != nil {
+		return DeploymentStateVersion{}, false, err
+	}
+
+	for _, v := range versions {
+		if v.Version == version {
+			return v, true, nil
+		}
+	}
+
+	return DeploymentStateVersion{}, false, nil
+}
+
+func (h *deploymentStateHistory) Read(version DeploymentStateVersion) ([]byte, DeploymentState, error) {
+	var deploymentState DeploymentState
+
+	contents, err := h.fs.ReadFile(version.Path)
+	if err != nil {
+		return nil, deploymentState, bosherr.WrapErrorf(err, "Reading deployment state version %d", version.Version)
+	}
+
+	err = json.Unmarshal(contents, &deploymentState)
+	if err != nil {
+		return nil, deploymentState, bosherr.WrapErrorf(err, "Unmarshalling deployment state version %d", version.Version)
+	}
+
+	return contents, deploymentState, nil
+}
@@ -0,0 +1,105 @@
+package config_test
+
+import (
+	"errors"
+	"time"
+
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/config"
+)
+
+var _ = Describe("DeploymentStateHistory", func() {
+	var (
+		fakeFs  *fakesys.FakeFileSystem
+		savedAt time.Time
+		history DeploymentStateHistory
+	)
+
+	BeforeEach(func() {
+		fakeFs = fakesys.NewFakeFileSystem()
+		savedAt = time.Date(2024, time.March, 1, 10, 30, 0, 0, time.UTC)
+		history = NewDeploymentStateHistory(fakeFs, "/some/state.json", 3)
+	})
+
+	Describe("Add", func() {
+		It("writes numbered, timestamped versions next to the state file", func() {
+			err := history.Add([]byte(`{"current_vm_cid": "vm-1"}`), savedAt)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = history.Add([]byte(`{"current_vm_cid": "vm-2"}`), savedAt.Add(time.Hour))
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeFs.FileExists("/some/state.json.history/1-20240301T103000Z.json")).To(BeTrue())
+			Expect(fakeFs.FileExists("/some/state.json.history/2-20240301T113000Z.json")).To(BeTrue())
+		})
+
+		It("removes the oldest versions beyond the limit", func() {
+			for i := 0; i < 5; i++ {
+				err := history.Add([]byte(`{}`), savedAt)
+				Expect(err).ToNot(HaveOccurred())
+			}
+
+			versions, err := history.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(HaveLen(3))
+			Expect(versions[0].Version).To(Equal(3))
+			Expect(versions[2].Version).To(Equal(5))
+		})
+
+		It("returns an error when the version cannot be written", func() {
+			fakeFs.WriteFileError = errors.New("fake-write-error")
+
+			err := history.Add([]byte(`{}`), savedAt)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Writing deployment state version 1"))
+		})
+	})
+
+	Describe("List", func() {
+		It("returns no versions when there is no history", func() {
+			versions, err := history.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(BeEmpty())
+		})
+
+		It("returns versions in order with their save time", func() {
+			err := history.Add([]byte(`{}`), savedAt)
+			Expect(err).ToNot(HaveOccurred())
+
+			versions, err := history.List()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(versions).To(Equal([]DeploymentStateVersion{
+				{
+					Version: 1,
+					SavedAt: time.Date(2024, time.March, 1, 10, 30, 0, 0, time.UTC),
+					Path:    "/some/state.json.history/1-20240301T103000Z.json",
+				},
+			}))
+		})
+	})
+
+	Describe("Find and Read", func() {
+		It("reads the state of a version", func() {
+			err := history.Add([]byte(`{"current_vm_cid": "vm-1"}`), savedAt)
+			Expect(err).ToNot(HaveOccurred())
+
+			version, found, err := history.Find(1)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+
+			contents, deploymentState, err := history.Read(version)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(string(contents)).To(Equal(`{"current_vm_cid": "vm-1"}`))
+			Expect(deploymentState.CurrentVMCID).To(Equal("vm-1"))
+		})
+
+		It("does not find unknown versions", func() {
+			_, found, err := history.Find(42)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeFalse())
+		})
+	})
+})
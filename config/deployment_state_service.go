@@ -10,8 +10,10 @@ type DeploymentState struct {
 	CurrentVMCID       string           `json:"current_vm_cid"`
 	CurrentStemcellID  string           `json:"current_stemcell_id"`
 	CurrentDiskID      string           `json:"current_disk_id"`
+	CurrentDiskIDs     []string         `json:"current_disk_ids,omitempty"`
 	CurrentReleaseIDs  []string         `json:"current_release_ids"`
 	CurrentManifestSHA string           `json:"current_manifest_sha"`
+	CurrentManifest    *ManifestRecord  `json:"current_manifest,omitempty"`
 	Disks              []DiskRecord     `json:"disks"`
 	Stemcells          []StemcellRecord `json:"stemcells"`
 	Releases           []ReleaseRecord  `json:"releases"`
@@ -27,21 +29,57 @@ type StemcellRecord struct {
 
 type DiskRecord struct {
 	ID              string         `json:"id"`
+	Name            string         `json:"name,omitempty"`
 	CID             string         `json:"cid"`
 	Size            int            `json:"size"`
 	CloudProperties biproperty.Map `json:"cloud_properties"`
 }
 
+// ManifestRecord keeps the interpolated manifest of the last create-env with
+// variable values redacted, and optionally encrypted with all of its values
+type ManifestRecord struct {
+	Redacted  string `json:"redacted"`
+	Encrypted string `json:"encrypted,omitempty"`
+}
+
 type ReleaseRecord struct {
 	ID      string `json:"id"`
 	Name    string `json:"name"`
 	Version string `json:"version"`
 }
 
+// migrateCurrentDisks moves the single current disk of state files written before
+// multiple persistent disks were supported into the ordered list of current disks
+func migrateCurrentDisks(deploymentState *DeploymentState) {
+	if len(deploymentState.CurrentDiskIDs) == 0 && deploymentState.CurrentDiskID != "" {
+		deploymentState.CurrentDiskIDs = []string{deploymentState.CurrentDiskID}
+	}
+}
+
+// setCurrentDisks keeps current_disk_id pointing at the first current disk so that
+// older CLIs still find the disk of single disk deployments
+func setCurrentDisks(deploymentState *DeploymentState, diskIDs []string) {
+	deploymentState.CurrentDiskIDs = diskIDs
+	deploymentState.CurrentDiskID = ""
+
+	if len(diskIDs) > 0 {
+		deploymentState.CurrentDiskID = diskIDs[0]
+	}
+}
+
 type DeploymentStateService interface {
 	Path() string
 	Exists() bool
 	Load() (DeploymentState, error)
 	Save(DeploymentState) error
 	Cleanup() error
+
+	// Lock takes exclusive ownership of the deployment state
+	// and fails if another process already holds it
+	Lock() error
+	// Unlock gives up ownership, but only of a lock taken by Lock
+	Unlock() error
+	// BreakLock removes the lock of another process once its lease
+	// expired, or right away when forced. It returns the removed lock.
+	BreakLock(force bool) (DeploymentStateLock, bool, error)
 }
@@ -7,10 +7,11 @@ import (
 )
 
 type DiskRepo interface {
-	UpdateCurrent(diskID string) error
-	FindCurrent() (DiskRecord, bool, error)
+	// UpdateCurrent replaces the current disks, in the order of the persistent disks of the manifest
+	UpdateCurrent(diskIDs []string) error
+	FindCurrent() ([]DiskRecord, error)
 	ClearCurrent() error
-	Save(cid string, size int, cloudProperties biproperty.Map) (DiskRecord, error)
+	Save(name string, cid string, size int, cloudProperties biproperty.Map) (DiskRecord, error)
 	Find(cid string) (DiskRecord, bool, error)
 	All() ([]DiskRecord, error)
 	Delete(DiskRecord) error
@@ -28,7 +29,7 @@ func NewDiskRepo(deploymentStateService DeploymentStateService, uuidGenerator bo
 	}
 }
 
-func (r diskRepo) Save(cid string, size int, cloudProperties biproperty.Map) (DiskRecord, error) {
+func (r diskRepo) Save(name string, cid string, size int, cloudProperties biproperty.Map) (DiskRecord, error) {
 	config, records, err := r.load()
 	if err != nil {
 		return DiskRecord{}, err
@@ -40,6 +41,7 @@ func (r diskRepo) Save(cid string, size int, cloudProperties biproperty.Map) (Di
 	}
 
 	newRecord := DiskRecord{
+		Name:            name,
 		CID:             cid,
 		Size:            size,
 		CloudProperties: cloudProperties,
@@ -59,43 +61,44 @@ func (r diskRepo) Save(cid string, size int, cloudProperties biproperty.Map) (Di
 	return newRecord, nil
 }
 
-func (r diskRepo) FindCurrent() (DiskRecord, bool, error) {
+func (r diskRepo) FindCurrent() ([]DiskRecord, error) {
 	deploymentState, err := r.deploymentStateService.Load()
 	if err != nil {
-		return DiskRecord{}, false, bosherr.WrapError(err, "Loading existing config")
+		return []DiskRecord{}, bosherr.WrapError(err, "Loading existing config")
 	}
 
-	currentDiskID := deploymentState.CurrentDiskID
-	if currentDiskID == "" {
-		return DiskRecord{}, false, nil
-	}
+	records := []DiskRecord{}
 
-	for _, oldRecord := range deploymentState.Disks {
-		if oldRecord.ID == currentDiskID {
-			return oldRecord, true, nil
+	for _, currentDiskID := range deploymentState.CurrentDiskIDs {
+		for _, oldRecord := range deploymentState.Disks {
+			if oldRecord.ID == currentDiskID {
+				records = append(records, oldRecord)
+			}
 		}
 	}
 
-	return DiskRecord{}, false, nil
+	return records, nil
 }
 
-func (r diskRepo) UpdateCurrent(diskID string) error {
+func (r diskRepo) UpdateCurrent(diskIDs []string) error {
 	deploymentState, err := r.deploymentStateService.Load()
 	if err != nil {
 		return bosherr.WrapError(err, "Loading existing config")
 	}
 
-	found := false
-	for _, oldRecord := range deploymentState.Disks {
-		if oldRecord.ID == diskID {
-			found = true
+	for _, diskID := range diskIDs {
+		found := false
+		for _, oldRecord := range deploymentState.Disks {
+			if oldRecord.ID == diskID {
+				found = true
+			}
+		}
+		if !found {
+			return bosherr.Errorf("Verifying disk record exists with id '%s'", diskID)
 		}
-	}
-	if !found {
-		return bosherr.Errorf("Verifying disk record exists with id '%s'", diskID)
 	}
 
-	deploymentState.CurrentDiskID = diskID
+	setCurrentDisks(&deploymentState, diskIDs)
 
 	err = r.deploymentStateService.Save(deploymentState)
 	if err != nil {
@@ -138,9 +141,13 @@ func (r diskRepo) Delete(diskRecord DiskRecord) error {
 
 	config.Disks = newRecords
 
-	if config.CurrentDiskID == diskRecord.ID {
-		config.CurrentDiskID = ""
+	currentDiskIDs := []string{}
+	for _, currentDiskID := range config.CurrentDiskIDs {
+		if currentDiskID != diskRecord.ID {
+			currentDiskIDs = append(currentDiskIDs, currentDiskID)
+		}
 	}
+	setCurrentDisks(&config, currentDiskIDs)
 
 	err = r.deploymentStateService.Save(config)
 	if err != nil {
@@ -156,7 +163,7 @@ func (r diskRepo) ClearCurrent() error {
 		return bosherr.WrapError(err, "Loading existing config")
 	}
 
-	deploymentState.CurrentDiskID = ""
+	setCurrentDisks(&deploymentState, nil)
 
 	err = r.deploymentStateService.Save(deploymentState)
 	if err != nil {
@@ -32,8 +32,18 @@ var _ = Describe("DiskRepo", func() {
 	})
 
 	Describe("Save", func() {
+		It("records the name of the disk", func() {
+			record, err := repo.Save("blobstore", "fake-cid", 1024, cloudProperties)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(record.Name).To(Equal("blobstore"))
+
+			foundRecord, _, err := repo.Find("fake-cid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(foundRecord.Name).To(Equal("blobstore"))
+		})
+
 		It("saves the disk record using the config service", func() {
-			record, err := repo.Save("fake-cid", 1024, cloudProperties)
+			record, err := repo.Save("", "fake-cid", 1024, cloudProperties)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(record).To(Equal(DiskRecord{
 				ID:              "fake-uuid-1",
@@ -62,7 +72,7 @@ var _ = Describe("DiskRepo", func() {
 
 	Describe("Find", func() {
 		It("finds existing disk records", func() {
-			savedRecord, err := repo.Save("fake-cid", 1024, cloudProperties)
+			savedRecord, err := repo.Save("", "fake-cid", 1024, cloudProperties)
 			Expect(err).ToNot(HaveOccurred())
 
 			foundRecord, found, err := repo.Find("fake-cid")
@@ -72,7 +82,7 @@ var _ = Describe("DiskRepo", func() {
 		})
 
 		It("when the disk is not in the records, returns not found", func() {
-			_, err := repo.Save("other-cid", 1024, cloudProperties)
+			_, err := repo.Save("", "other-cid", 1024, cloudProperties)
 			Expect(err).ToNot(HaveOccurred())
 
 			_, found, err := repo.Find("fake-cid")
@@ -88,30 +98,31 @@ var _ = Describe("DiskRepo", func() {
 			)
 
 			BeforeEach(func() {
-				record, err := repo.Save("fake-cid", 1024, cloudProperties)
+				record, err := repo.Save("", "fake-cid", 1024, cloudProperties)
 				Expect(err).ToNot(HaveOccurred())
 				recordID = record.ID
 			})
 
-			It("saves the disk record as current stemcell", func() {
-				err := repo.UpdateCurrent(recordID)
+			It("saves the disk records as current disks", func() {
+				err := repo.UpdateCurrent([]string{recordID})
 				Expect(err).ToNot(HaveOccurred())
 
 				deploymentState, err := deploymentStateService.Load()
 				Expect(err).ToNot(HaveOccurred())
 
+				Expect(deploymentState.CurrentDiskIDs).To(Equal([]string{recordID}))
 				Expect(deploymentState.CurrentDiskID).To(Equal(recordID))
 			})
 		})
 
 		Context("when a disk record does not exists with the same ID", func() {
 			BeforeEach(func() {
-				_, err := repo.Save("fake-cid", 1024, cloudProperties)
+				_, err := repo.Save("", "fake-cid", 1024, cloudProperties)
 				Expect(err).ToNot(HaveOccurred())
 			})
 
 			It("returns an error", func() {
-				err := repo.UpdateCurrent("fake-unknown-id")
+				err := repo.UpdateCurrent([]string{"fake-unknown-id"})
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("Verifying disk record exists with id 'fake-unknown-id'"))
 			})
@@ -124,48 +135,79 @@ var _ = Describe("DiskRepo", func() {
 				diskID2 string
 			)
 			BeforeEach(func() {
-				_, err := repo.Save("fake-cid-1", 1024, cloudProperties)
+				_, err := repo.Save("", "fake-cid-1", 1024, cloudProperties)
 				Expect(err).ToNot(HaveOccurred())
 
-				record, err := repo.Save("fake-cid-2", 1024, cloudProperties)
+				record, err := repo.Save("", "fake-cid-2", 1024, cloudProperties)
 				Expect(err).ToNot(HaveOccurred())
 				diskID2 = record.ID
 
-				err = repo.UpdateCurrent(record.ID)
+				err = repo.UpdateCurrent([]string{record.ID})
 				Expect(err).ToNot(HaveOccurred())
 			})
 
 			It("returns existing disk", func() {
-				record, found, err := repo.FindCurrent()
+				records, err := repo.FindCurrent()
 				Expect(err).ToNot(HaveOccurred())
-				Expect(found).To(BeTrue())
-				Expect(record).To(Equal(DiskRecord{
+				Expect(records).To(Equal([]DiskRecord{{
 					ID:              diskID2,
 					CID:             "fake-cid-2",
 					Size:            1024,
 					CloudProperties: cloudProperties,
-				}))
+				}}))
+			})
+		})
+
+		Context("when there are multiple current disks", func() {
+			It("returns them in order", func() {
+				first, err := repo.Save("blobstore", "fake-cid-1", 1024, cloudProperties)
+				Expect(err).ToNot(HaveOccurred())
+
+				second, err := repo.Save("database", "fake-cid-2", 2048, cloudProperties)
+				Expect(err).ToNot(HaveOccurred())
+
+				err = repo.UpdateCurrent([]string{second.ID, first.ID})
+				Expect(err).ToNot(HaveOccurred())
+
+				records, err := repo.FindCurrent()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(records).To(Equal([]DiskRecord{second, first}))
+			})
+		})
+
+		Context("when the state was written with a single current disk", func() {
+			It("returns that disk", func() {
+				err := deploymentStateService.Save(DeploymentState{
+					DirectorID:    "fake-director-id",
+					CurrentDiskID: "fake-disk-id",
+					Disks:         []DiskRecord{{ID: "fake-disk-id", CID: "fake-cid"}},
+				})
+				Expect(err).ToNot(HaveOccurred())
+
+				records, err := repo.FindCurrent()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(records).To(Equal([]DiskRecord{{ID: "fake-disk-id", CID: "fake-cid"}}))
 			})
 		})
 
 		Context("when current disk does not exist", func() {
 			BeforeEach(func() {
-				_, err := repo.Save("fake-cid", 1024, cloudProperties)
+				_, err := repo.Save("", "fake-cid", 1024, cloudProperties)
 				Expect(err).ToNot(HaveOccurred())
 			})
 
-			It("returns not found", func() {
-				_, found, err := repo.FindCurrent()
+			It("returns no disks", func() {
+				records, err := repo.FindCurrent()
 				Expect(err).ToNot(HaveOccurred())
-				Expect(found).To(BeFalse())
+				Expect(records).To(BeEmpty())
 			})
 		})
 
 		Context("when there are no disks", func() {
-			It("returns not found", func() {
-				_, found, err := repo.FindCurrent()
+			It("returns no disks", func() {
+				records, err := repo.FindCurrent()
 				Expect(err).ToNot(HaveOccurred())
-				Expect(found).To(BeFalse())
+				Expect(records).To(BeEmpty())
 			})
 		})
 	})
@@ -178,10 +220,10 @@ var _ = Describe("DiskRepo", func() {
 
 		BeforeEach(func() {
 			var err error
-			firstDisk, err = repo.Save("fake-cid-1", 1024, cloudProperties)
+			firstDisk, err = repo.Save("", "fake-cid-1", 1024, cloudProperties)
 			Expect(err).ToNot(HaveOccurred())
 
-			secondDisk, err = repo.Save("fake-cid-2", 2048, cloudProperties)
+			secondDisk, err = repo.Save("", "fake-cid-2", 2048, cloudProperties)
 			Expect(err).ToNot(HaveOccurred())
 		})
 
@@ -204,10 +246,10 @@ var _ = Describe("DiskRepo", func() {
 		BeforeEach(func() {
 			var err error
 
-			firstDisk, err = repo.Save("fake-cid-1", 1024, cloudProperties)
+			firstDisk, err = repo.Save("", "fake-cid-1", 1024, cloudProperties)
 			Expect(err).ToNot(HaveOccurred())
 
-			secondDisk, err = repo.Save("fake-cid-2", 2048, cloudProperties)
+			secondDisk, err = repo.Save("", "fake-cid-2", 2048, cloudProperties)
 			Expect(err).ToNot(HaveOccurred())
 		})
 
@@ -224,11 +266,11 @@ var _ = Describe("DiskRepo", func() {
 
 		Context("when the disk to be deleted is also the current disk", func() {
 			BeforeEach(func() {
-				err := repo.UpdateCurrent(firstDisk.ID)
+				err := repo.UpdateCurrent([]string{firstDisk.ID, secondDisk.ID})
 				Expect(err).ToNot(HaveOccurred())
 			})
 
-			It("clears the current disk", func() {
+			It("removes it from the current disks", func() {
 				err := repo.Delete(firstDisk)
 				Expect(err).ToNot(HaveOccurred())
 
@@ -238,9 +280,9 @@ var _ = Describe("DiskRepo", func() {
 					secondDisk,
 				}))
 
-				_, found, err := repo.FindCurrent()
+				records, err := repo.FindCurrent()
 				Expect(err).ToNot(HaveOccurred())
-				Expect(found).To(BeFalse())
+				Expect(records).To(Equal([]DiskRecord{secondDisk}))
 			})
 		})
 	})
@@ -259,9 +301,9 @@ var _ = Describe("DiskRepo", func() {
 			}
 			Expect(deploymentState).To(Equal(expectedConfig))
 
-			_, found, err := repo.FindCurrent()
+			records, err := repo.FindCurrent()
 			Expect(err).ToNot(HaveOccurred())
-			Expect(found).To(BeFalse())
+			Expect(records).To(BeEmpty())
 		})
 	})
 })
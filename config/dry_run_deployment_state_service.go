@@ -0,0 +1,111 @@
+package config
+
+import (
+	"encoding/json"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
+)
+
+// deploymentStateReader reads the deployment state without saving defaults
+type deploymentStateReader interface {
+	read() (DeploymentState, error)
+}
+
+// dryRunDeploymentStateService reads the deployment state once and keeps
+// every later change in memory so that a dry run never writes the state
+type dryRunDeploymentStateService struct {
+	source        DeploymentStateService
+	uuidGenerator boshuuid.Generator
+
+	// the state is kept marshalled so that callers cannot share its slices
+	state []byte
+}
+
+func NewDryRunDeploymentStateService(source DeploymentStateService, uuidGenerator boshuuid.Generator) DeploymentStateService {
+	return &dryRunDeploymentStateService{
+		source:        source,
+		uuidGenerator: uuidGenerator,
+	}
+}
+
+func (s *dryRunDeploymentStateService) Path() string {
+	return s.source.Path()
+}
+
+func (s *dryRunDeploymentStateService) Exists() bool {
+	return s.source.Exists()
+}
+
+func (s *dryRunDeploymentStateService) Load() (DeploymentState, error) {
+	if s.state == nil {
+		deploymentState, err := s.readSource()
+		if err != nil {
+			return DeploymentState{}, err
+		}
+
+		err = s.Save(deploymentState)
+		if err != nil {
+			return DeploymentState{}, err
+		}
+	}
+
+	var deploymentState DeploymentState
+
+	err := json.Unmarshal(s.state, &deploymentState)
+	if err != nil {
+		return DeploymentState{}, bosherr.WrapError(err, "Unmarshalling deployment state")
+	}
+
+	return deploymentState, nil
+}
+
+func (s *dryRunDeploymentStateService) readSource() (DeploymentState, error) {
+	reader, ok := s.source.(deploymentStateReader)
+	if !ok {
+		return DeploymentState{}, bosherr.Errorf("Deployment state '%s' cannot be read without writing it", s.source.Path())
+	}
+
+	deploymentState, err := reader.read()
+	if err != nil {
+		return DeploymentState{}, err
+	}
+
+	if deploymentState.DirectorID == "" {
+		deploymentState.DirectorID, err = s.uuidGenerator.Generate()
+		if err != nil {
+			return DeploymentState{}, bosherr.WrapError(err, "Generating DirectorID")
+		}
+	}
+
+	return deploymentState, nil
+}
+
+func (s *dryRunDeploymentStateService) Save(deploymentState DeploymentState) error {
+	state, err := json.Marshal(deploymentState)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling deployment state into JSON")
+	}
+
+	s.state = state
+
+	return nil
+}
+
+func (s *dryRunDeploymentStateService) Cleanup() error {
+	s.state = nil
+	return nil
+}
+
+// Lock is a no-op since a dry run never writes the deployment state
+func (s *dryRunDeploymentStateService) Lock() error {
+	return nil
+}
+
+func (s *dryRunDeploymentStateService) Unlock() error {
+	return nil
+}
+
+func (s *dryRunDeploymentStateService) BreakLock(force bool) (DeploymentStateLock, bool, error) {
+	return DeploymentStateLock{}, false, nil
+}
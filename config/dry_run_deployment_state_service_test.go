@@ -0,0 +1,103 @@
+package config_test
+
+import (
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/config"
+)
+
+var _ = Describe("dryRunDeploymentStateService", func() {
+	var (
+		service             DeploymentStateService
+		deploymentStatePath string
+		fakeFs              *fakesys.FakeFileSystem
+		fakeUUIDGenerator   *fakeuuid.FakeGenerator
+	)
+
+	BeforeEach(func() {
+		fakeFs = fakesys.NewFakeFileSystem()
+		deploymentStatePath = "/some/deployment.json"
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		fakeUUIDGenerator = fakeuuid.NewFakeGenerator()
+		fakeUUIDGenerator.GeneratedUUID = "fake-uuid"
+
+		source := NewFileSystemDeploymentStateService(fakeFs, fakeUUIDGenerator, logger, deploymentStatePath)
+		service = NewDryRunDeploymentStateService(source, fakeUUIDGenerator)
+	})
+
+	Describe("Load", func() {
+		It("reads the state of the source", func() {
+			err := fakeFs.WriteFileString(deploymentStatePath, `{"director_id":"fake-director-id","current_vm_cid":"fake-vm-cid"}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			deploymentState, err := service.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.DirectorID).To(Equal("fake-director-id"))
+			Expect(deploymentState.CurrentVMCID).To(Equal("fake-vm-cid"))
+		})
+
+		It("generates a director id without writing the state when there is none", func() {
+			deploymentState, err := service.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.DirectorID).To(Equal("fake-uuid"))
+			Expect(fakeFs.FileExists(deploymentStatePath)).To(BeFalse())
+		})
+
+		It("keeps the director id it generated", func() {
+			_, err := service.Load()
+			Expect(err).ToNot(HaveOccurred())
+
+			fakeUUIDGenerator.GeneratedUUID = "other-uuid"
+
+			deploymentState, err := service.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.DirectorID).To(Equal("fake-uuid"))
+		})
+	})
+
+	Describe("Save", func() {
+		It("keeps the state in memory", func() {
+			err := fakeFs.WriteFileString(deploymentStatePath, `{"director_id":"fake-director-id"}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = service.Save(DeploymentState{DirectorID: "fake-director-id", CurrentVMCID: "new-vm-cid"})
+			Expect(err).ToNot(HaveOccurred())
+
+			deploymentState, err := service.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.CurrentVMCID).To(Equal("new-vm-cid"))
+
+			contents, err := fakeFs.ReadFileString(deploymentStatePath)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(contents).To(Equal(`{"director_id":"fake-director-id"}`))
+		})
+
+		It("does not share slices between loads", func() {
+			err := service.Save(DeploymentState{DirectorID: "fake-director-id", Disks: []DiskRecord{{ID: "disk-1"}}})
+			Expect(err).ToNot(HaveOccurred())
+
+			deploymentState, err := service.Load()
+			Expect(err).ToNot(HaveOccurred())
+			deploymentState.Disks[0].ID = "changed"
+
+			deploymentState, err = service.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.Disks[0].ID).To(Equal("disk-1"))
+		})
+	})
+
+	Describe("Cleanup", func() {
+		It("does not delete the state of the source", func() {
+			err := fakeFs.WriteFileString(deploymentStatePath, `{"director_id":"fake-director-id"}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = service.Cleanup()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fakeFs.FileExists(deploymentStatePath)).To(BeTrue())
+		})
+	})
+})
@@ -24,16 +24,16 @@ type FakeDiskRepo struct {
 }
 
 type DiskRepoUpdateCurrentInput struct {
-	DiskID string
+	DiskIDs []string
 }
 
 type diskRepoFindCurrentOutput struct {
-	diskRecord biconfig.DiskRecord
-	found      bool
-	err        error
+	diskRecords []biconfig.DiskRecord
+	err         error
 }
 
 type DiskRepoSaveInput struct {
+	Name            string
 	CID             string
 	Size            int
 	CloudProperties biproperty.Map
@@ -68,23 +68,24 @@ func NewFakeDiskRepo() *FakeDiskRepo {
 	}
 }
 
-func (r *FakeDiskRepo) UpdateCurrent(diskID string) error {
+func (r *FakeDiskRepo) UpdateCurrent(diskIDs []string) error {
 	r.UpdateCurrentInputs = append(r.UpdateCurrentInputs, DiskRepoUpdateCurrentInput{
-		DiskID: diskID,
+		DiskIDs: diskIDs,
 	})
 	return r.updateErr
 }
 
-func (r *FakeDiskRepo) FindCurrent() (biconfig.DiskRecord, bool, error) {
-	return r.findCurrentOutput.diskRecord, r.findCurrentOutput.found, r.findCurrentOutput.err
+func (r *FakeDiskRepo) FindCurrent() ([]biconfig.DiskRecord, error) {
+	return r.findCurrentOutput.diskRecords, r.findCurrentOutput.err
 }
 
 func (r *FakeDiskRepo) ClearCurrent() error {
 	return nil
 }
 
-func (r *FakeDiskRepo) Save(cid string, size int, cloudProperties biproperty.Map) (biconfig.DiskRecord, error) {
+func (r *FakeDiskRepo) Save(name string, cid string, size int, cloudProperties biproperty.Map) (biconfig.DiskRecord, error) {
 	r.SaveInputs = append(r.SaveInputs, DiskRepoSaveInput{
+		Name:            name,
 		CID:             cid,
 		Size:            size,
 		CloudProperties: cloudProperties,
@@ -113,11 +114,10 @@ func (r *FakeDiskRepo) SetUpdateBehavior(err error) {
 	r.updateErr = err
 }
 
-func (r *FakeDiskRepo) SetFindCurrentBehavior(diskRecord biconfig.DiskRecord, found bool, err error) {
+func (r *FakeDiskRepo) SetFindCurrentBehavior(diskRecords []biconfig.DiskRecord, err error) {
 	r.findCurrentOutput = diskRepoFindCurrentOutput{
-		diskRecord: diskRecord,
-		found:      found,
-		err:        err,
+		diskRecords: diskRecords,
+		err:         err,
 	}
 }
 
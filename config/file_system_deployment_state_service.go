@@ -1,12 +1,15 @@
 package config
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"path/filepath"
 	"strings"
 
+	"code.cloudfoundry.org/clock"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cloudfoundry/bosh-utils/httpclient"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
@@ -15,15 +18,28 @@ import (
 type fileSystemDeploymentStateService struct {
 	configPath    string
 	fs            boshsys.FileSystem
+	history       DeploymentStateHistory
+	versionKept   bool
 	uuidGenerator boshuuid.Generator
 	logger        boshlog.Logger
 	logTag        string
 }
 
+// NewDeploymentStateService returns a remote deployment state service for
+// http(s) URLs and a local file based one for everything else
+func NewDeploymentStateService(fs boshsys.FileSystem, httpClient *httpclient.HTTPClient, uuidGenerator boshuuid.Generator, logger boshlog.Logger, deploymentStatePath string) DeploymentStateService {
+	if IsRemoteDeploymentStatePath(deploymentStatePath) {
+		return NewHTTPDeploymentStateService(httpClient, uuidGenerator, clock.NewClock(), logger, deploymentStatePath)
+	}
+
+	return NewFileSystemDeploymentStateService(fs, uuidGenerator, logger, deploymentStatePath)
+}
+
 func NewFileSystemDeploymentStateService(fs boshsys.FileSystem, uuidGenerator boshuuid.Generator, logger boshlog.Logger, deploymentStatePath string) DeploymentStateService {
 	return &fileSystemDeploymentStateService{
 		configPath:    deploymentStatePath,
 		fs:            fs,
+		history:       NewDeploymentStateHistory(fs, deploymentStatePath, DeploymentStateHistoryLimit),
 		uuidGenerator: uuidGenerator,
 		logger:        logger,
 		logTag:        "config",
@@ -48,13 +64,27 @@ func (s *fileSystemDeploymentStateService) Exists() bool {
 }
 
 func (s *fileSystemDeploymentStateService) Load() (DeploymentState, error) {
+	deploymentState, err := s.read()
+	if err != nil {
+		return DeploymentState{}, err
+	}
+
+	err = s.initDefaults(&deploymentState)
+	if err != nil {
+		return DeploymentState{}, bosherr.WrapErrorf(err, "Initializing deployment state defaults")
+	}
+
+	return deploymentState, nil
+}
+
+func (s *fileSystemDeploymentStateService) read() (DeploymentState, error) {
 	if s.configPath == "" {
 		panic("configPath not yet set!")
 	}
 
 	s.logger.Debug(s.logTag, "Loading deployment state: %s", s.configPath)
 
-	deploymentState := &DeploymentState{}
+	deploymentState := DeploymentState{}
 
 	if s.fs.FileExists(s.configPath) {
 		deploymentStateFileContents, err := s.fs.ReadFile(s.configPath)
@@ -63,18 +93,15 @@ func (s *fileSystemDeploymentStateService) Load() (DeploymentState, error) {
 		}
 		s.logger.Debug(s.logTag, "Deployment File Contents %#s", deploymentStateFileContents)
 
-		err = json.Unmarshal(deploymentStateFileContents, deploymentState)
+		err = json.Unmarshal(deploymentStateFileContents, &deploymentState)
 		if err != nil {
 			return DeploymentState{}, bosherr.WrapErrorf(err, "Unmarshalling deployment state file '%s'", s.configPath)
 		}
-	}
 
-	err := s.initDefaults(deploymentState)
-	if err != nil {
-		return DeploymentState{}, bosherr.WrapErrorf(err, "Initializing deployment state defaults")
+		migrateCurrentDisks(&deploymentState)
 	}
 
-	return *deploymentState, nil
+	return deploymentState, nil
 }
 
 func (s *fileSystemDeploymentStateService) Save(deploymentState DeploymentState) error {
@@ -89,6 +116,11 @@ func (s *fileSystemDeploymentStateService) Save(deploymentState DeploymentState)
 		return bosherr.WrapError(err, "Marshalling deployment state into JSON")
 	}
 
+	err = s.keepPreviousVersion(jsonContent)
+	if err != nil {
+		return err
+	}
+
 	err = s.fs.WriteFile(s.configPath, jsonContent)
 	if err != nil {
 		return bosherr.WrapErrorf(err, "Writing deployment state file '%s'", s.configPath)
@@ -97,6 +129,38 @@ func (s *fileSystemDeploymentStateService) Save(deploymentState DeploymentState)
 	return nil
 }
 
+// keepPreviousVersion copies the state file into the history before it is
+// first overwritten with different contents. A command saves the state many
+// times, so only the state from before the command is kept.
+func (s *fileSystemDeploymentStateService) keepPreviousVersion(newContents []byte) error {
+	if s.versionKept || !s.fs.FileExists(s.configPath) {
+		return nil
+	}
+
+	previousContents, err := s.fs.ReadFile(s.configPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading deployment state file '%s'", s.configPath)
+	}
+
+	if len(previousContents) == 0 || bytes.Equal(previousContents, newContents) {
+		return nil
+	}
+
+	stat, err := s.fs.Stat(s.configPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Checking deployment state file '%s'", s.configPath)
+	}
+
+	err = s.history.Add(previousContents, stat.ModTime())
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Keeping previous version of deployment state file '%s'", s.configPath)
+	}
+
+	s.versionKept = true
+
+	return nil
+}
+
 func (s *fileSystemDeploymentStateService) initDefaults(deploymentState *DeploymentState) error {
 	if deploymentState.DirectorID == "" {
 		uuid, err := s.uuidGenerator.Generate()
@@ -114,10 +178,31 @@ func (s *fileSystemDeploymentStateService) initDefaults(deploymentState *Deploym
 	return nil
 }
 
+// Cleanup keeps the deleted state in the history unless the state from before
+// the command already is, so that an environment deleted by mistake can still be restored
 func (s *fileSystemDeploymentStateService) Cleanup() error {
-	err := s.fs.RemoveAll(s.configPath)
+	err := s.keepPreviousVersion(nil)
+	if err != nil {
+		return err
+	}
+
+	err = s.fs.RemoveAll(s.configPath)
 	if err != nil {
 		return bosherr.WrapErrorf(err, "Could not delete deployment state file %s", s.configPath)
 	}
+
+	return nil
+}
+
+// Lock is a no-op for local state files
+func (s *fileSystemDeploymentStateService) Lock() error {
+	return nil
+}
+
+func (s *fileSystemDeploymentStateService) Unlock() error {
 	return nil
 }
+
+func (s *fileSystemDeploymentStateService) BreakLock(force bool) (DeploymentStateLock, bool, error) {
+	return DeploymentStateLock{}, false, nil
+}
@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"errors"
 	"path/filepath"
+	"time"
 
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
@@ -106,6 +107,15 @@ var _ = Describe("fileSystemDeploymentStateService", func() {
 			Expect(deploymentState.Disks).To(Equal(disks))
 		})
 
+		It("migrates the current disk of state files written by older versions", func() {
+			err := fakeFs.WriteFileString(deploymentStatePath, `{"current_disk_id": "fake-disk-id"}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			deploymentState, err := service.Load()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploymentState.CurrentDiskIDs).To(Equal([]string{"fake-disk-id"}))
+		})
+
 		Context("when the config does not exist", func() {
 			It("returns a new DeploymentState with generated defaults", func() {
 				deploymentState, err := service.Load()
@@ -197,6 +207,32 @@ var _ = Describe("fileSystemDeploymentStateService", func() {
 			Expect(deploymentStateFileContents).To(Equal(string(expectedDeploymentStateFileContents)))
 		})
 
+		It("keeps the deployment state from before the run in the history once it changes", func() {
+			err := service.Save(DeploymentState{DirectorID: "deadbeef", CurrentVMCID: "old-vm-cid"})
+			Expect(err).NotTo(HaveOccurred())
+
+			oldSavedAt := time.Date(2024, time.March, 1, 10, 30, 0, 0, time.UTC)
+			fakeFs.GetFileTestStat(deploymentStatePath).ModTime = oldSavedAt
+
+			err = service.Save(DeploymentState{DirectorID: "deadbeef", CurrentVMCID: "old-vm-cid"})
+			Expect(err).NotTo(HaveOccurred())
+
+			for _, vmCID := range []string{"new-vm-cid", "newer-vm-cid"} {
+				err = service.Save(DeploymentState{DirectorID: "deadbeef", CurrentVMCID: vmCID})
+				Expect(err).NotTo(HaveOccurred())
+			}
+
+			history := NewDeploymentStateHistory(fakeFs, deploymentStatePath, DeploymentStateHistoryLimit)
+			versions, err := history.List()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(versions).To(HaveLen(1))
+			Expect(versions[0].SavedAt).To(Equal(oldSavedAt))
+
+			_, deploymentState, err := history.Read(versions[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploymentState.CurrentVMCID).To(Equal("old-vm-cid"))
+		})
+
 		Context("when the deployment file cannot be written", func() {
 			BeforeEach(func() {
 				fakeFs.WriteFileError = errors.New("")
@@ -227,6 +263,44 @@ var _ = Describe("fileSystemDeploymentStateService", func() {
 
 		})
 
+		It("keeps the deleted deployment state in the history", func() {
+			err := service.Save(DeploymentState{DirectorID: "deadbeef", CurrentVMCID: "last-vm-cid"})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = service.Cleanup()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(service.Exists()).To(BeFalse())
+
+			history := NewDeploymentStateHistory(fakeFs, deploymentStatePath, DeploymentStateHistoryLimit)
+			versions, err := history.List()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(versions).To(HaveLen(1))
+
+			_, deploymentState, err := history.Read(versions[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploymentState.CurrentVMCID).To(Equal("last-vm-cid"))
+		})
+
+		It("keeps only the deployment state from before the run when the run changed it", func() {
+			err := service.Save(DeploymentState{DirectorID: "deadbeef", CurrentVMCID: "old-vm-cid"})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = service.Save(DeploymentState{DirectorID: "deadbeef", CurrentVMCID: "last-vm-cid"})
+			Expect(err).NotTo(HaveOccurred())
+
+			err = service.Cleanup()
+			Expect(err).NotTo(HaveOccurred())
+
+			history := NewDeploymentStateHistory(fakeFs, deploymentStatePath, DeploymentStateHistoryLimit)
+			versions, err := history.List()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(versions).To(HaveLen(1))
+
+			_, deploymentState, err := history.Read(versions[0])
+			Expect(err).NotTo(HaveOccurred())
+			Expect(deploymentState.CurrentVMCID).To(Equal("old-vm-cid"))
+		})
+
 		It("returns error if delete opertation fails to remove file", func() {
 			fakeFs.RemoveAllStub = func(_ string) error {
 				return errors.New("could not do that Dave")
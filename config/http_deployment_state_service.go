@@ -0,0 +1,507 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"os/user"
+	"strings"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"github.com/cloudfoundry/bosh-utils/httpclient"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
+)
+
+// DeploymentStateLockLease is how long a lock is valid without being renewed.
+// The lock is renewed periodically while it is held and on each save of the
+// state, and other processes may take over a lock once it expired.
+const DeploymentStateLockLease = 2 * time.Hour
+
+// DeploymentStateLockRenewalInterval leaves time for several renewals to fail
+// before the lease runs out
+const DeploymentStateLockRenewalInterval = DeploymentStateLockLease / 4
+
+// DeploymentStateLock is stored next to a remote deployment state
+// while a command holds the state exclusively
+type DeploymentStateLock struct {
+	ID        string    `json:"id"`
+	Owner     string    `json:"owner"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// Expired reports whether the lease ran out. Locks without an expiry never expire.
+func (l DeploymentStateLock) Expired(now time.Time) bool {
+	return !l.ExpiresAt.IsZero() && now.After(l.ExpiresAt)
+}
+
+// httpDeploymentStateService keeps the deployment state on an HTTP server.
+// The state is read with GET and written with PUT. Requests are not signed,
+// so object stores such as S3 are only supported through URLs that grant
+// access without signing, e.g. behind a proxy or with basic auth credentials.
+// The lock is a separate '<url>.lock' object created with 'If-None-Match: *'
+// so that only one writer can create it. It is removed with 'If-Match' when
+// the server sends ETags so that a lock taken over in between stays in place.
+// Unlike local state files, no prior versions of the state are kept, so the
+// env-state history, diff and restore commands do not support remote state.
+type httpDeploymentStateService struct {
+	stateURL      string
+	httpClient    *httpclient.HTTPClient
+	uuidGenerator boshuuid.Generator
+	logger        boshlog.Logger
+	timeService   clock.Clock
+	logTag        string
+
+	// lockMutex guards lockID, which is renewed in the background
+	lockMutex       sync.Mutex
+	lockID          string
+	stopLockRenewal chan struct{}
+	lockRenewalDone chan struct{}
+}
+
+func NewHTTPDeploymentStateService(httpClient *httpclient.HTTPClient, uuidGenerator boshuuid.Generator, timeService clock.Clock, logger boshlog.Logger, stateURL string) DeploymentStateService {
+	return &httpDeploymentStateService{
+		stateURL:      stateURL,
+		httpClient:    httpClient,
+		uuidGenerator: uuidGenerator,
+		logger:        logger,
+		timeService:   timeService,
+		logTag:        "httpDeploymentStateService",
+	}
+}
+
+func IsRemoteDeploymentStatePath(deploymentStatePath string) bool {
+	return strings.HasPrefix(deploymentStatePath, "http://") || strings.HasPrefix(deploymentStatePath, "https://")
+}
+
+func (s *httpDeploymentStateService) Path() string {
+	parsedURL, err := url.Parse(s.stateURL)
+	if err != nil {
+		return s.stateURL
+	}
+
+	return parsedURL.Redacted()
+}
+
+func (s *httpDeploymentStateService) Exists() bool {
+	resp, err := s.httpClient.Get(s.stateURL)
+	if err != nil {
+		s.logger.Debug(s.logTag, "Checking deployment state existence: %s", err.Error())
+		return false
+	}
+	defer resp.Body.Close()
+
+	return resp.StatusCode == http.StatusOK
+}
+
+func (s *httpDeploymentStateService) Load() (DeploymentState, error) {
+	deploymentState, err := s.read()
+	if err != nil {
+		return DeploymentState{}, err
+	}
+
+	if deploymentState.DirectorID == "" {
+		deploymentState.DirectorID, err = s.uuidGenerator.Generate()
+		if err != nil {
+			return DeploymentState{}, bosherr.WrapError(err, "Generating DirectorID")
+		}
+
+		err = s.Save(deploymentState)
+		if err != nil {
+			return DeploymentState{}, bosherr.WrapError(err, "Saving deployment state")
+		}
+	}
+
+	return deploymentState, nil
+}
+
+func (s *httpDeploymentStateService) read() (DeploymentState, error) {
+	s.logger.Debug(s.logTag, "Loading deployment state: %s", s.Path())
+
+	deploymentState := DeploymentState{}
+
+	resp, err := s.httpClient.Get(s.stateURL)
+	if err != nil {
+		return DeploymentState{}, bosherr.WrapErrorf(err, "Reading deployment state '%s'", s.Path())
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return DeploymentState{}, bosherr.WrapErrorf(err, "Reading deployment state '%s'", s.Path())
+		}
+
+		err = json.Unmarshal(body, &deploymentState)
+		if err != nil {
+			return DeploymentState{}, bosherr.WrapErrorf(err, "Unmarshalling deployment state '%s'", s.Path())
+		}
+
+		migrateCurrentDisks(&deploymentState)
+	case http.StatusNotFound:
+		// no state has been saved yet
+	default:
+		return DeploymentState{}, bosherr.Errorf("Reading deployment state '%s': unexpected response status %d", s.Path(), resp.StatusCode)
+	}
+
+	return deploymentState, nil
+}
+
+func (s *httpDeploymentStateService) Save(deploymentState DeploymentState) error {
+	s.logger.Debug(s.logTag, "Saving deployment state %#v", deploymentState)
+
+	err := s.renewHeldLock()
+	if err != nil {
+		return err
+	}
+
+	jsonContent, err := json.MarshalIndent(deploymentState, "", "    ")
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling deployment state into JSON")
+	}
+
+	resp, err := s.httpClient.PutCustomized(s.stateURL, jsonContent, func(req *http.Request) {
+		req.Header.Set("Content-Type", "application/json")
+	})
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing deployment state '%s'", s.Path())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return bosherr.Errorf("Writing deployment state '%s': unexpected response status %d", s.Path(), resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (s *httpDeploymentStateService) Cleanup() error {
+	resp, err := s.httpClient.Delete(s.stateURL)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Could not delete deployment state %s", s.Path())
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound && (resp.StatusCode < 200 || resp.StatusCode >= 300) {
+		return bosherr.Errorf("Could not delete deployment state %s: unexpected response status %d", s.Path(), resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (s *httpDeploymentStateService) Lock() error {
+	s.lockMutex.Lock()
+	defer s.lockMutex.Unlock()
+
+	created, err := s.createLock()
+	if err != nil {
+		return err
+	}
+
+	if created {
+		s.startLockRenewal()
+		return nil
+	}
+
+	lock, etag, found, err := s.readLock()
+	if err != nil {
+		return err
+	}
+
+	if found && !lock.Expired(s.timeService.Now()) {
+		return bosherr.Errorf(
+			"Deployment state '%s' is locked by '%s' since %s until %s (lock id '%s'). Run 'bosh env-state unlock --force' if that command is no longer running",
+			s.Path(), lock.Owner, lock.CreatedAt.Format(time.RFC3339), lock.ExpiresAt.Format(time.RFC3339), lock.ID,
+		)
+	}
+
+	if found {
+		s.logger.Warn(s.logTag, "Taking over lock '%s' of '%s' which expired at %s", lock.ID, lock.Owner, lock.ExpiresAt.Format(time.RFC3339))
+
+		err = s.deleteLock(etag)
+		if err != nil {
+			return err
+		}
+	}
+
+	created, err = s.createLock()
+	if err != nil {
+		return err
+	}
+
+	if !created {
+		return bosherr.Errorf("Deployment state '%s' was locked by another process while taking over an expired lock", s.Path())
+	}
+
+	s.startLockRenewal()
+
+	return nil
+}
+
+func (s *httpDeploymentStateService) Unlock() error {
+	s.endLockRenewal()
+
+	s.lockMutex.Lock()
+	defer s.lockMutex.Unlock()
+
+	if s.lockID == "" {
+		return nil
+	}
+
+	lockID := s.lockID
+	s.lockID = ""
+
+	lock, etag, found, err := s.readLock()
+	if err != nil {
+		return err
+	}
+
+	if !found || lock.ID != lockID {
+		return bosherr.Errorf("Deployment state '%s' is no longer locked by this command (lock id '%s'), leaving the current lock in place", s.Path(), lockID)
+	}
+
+	return s.deleteLock(etag)
+}
+
+func (s *httpDeploymentStateService) BreakLock(force bool) (DeploymentStateLock, bool, error) {
+	lock, etag, found, err := s.readLock()
+	if err != nil || !found {
+		return DeploymentStateLock{}, false, err
+	}
+
+	if !force && !lock.Expired(s.timeService.Now()) {
+		return lock, false, bosherr.Errorf(
+			"Deployment state '%s' is locked by '%s' until %s (lock id '%s'). Use --force to remove the lock before it expires",
+			s.Path(), lock.Owner, lock.ExpiresAt.Format(time.RFC3339), lock.ID,
+		)
+	}
+
+	err = s.deleteLock(etag)
+	if err != nil {
+		return lock, false, err
+	}
+
+	return lock, true, nil
+}
+
+// createLock returns false without an error when the lock already exists
+func (s *httpDeploymentStateService) createLock() (bool, error) {
+	lockID, err := s.uuidGenerator.Generate()
+	if err != nil {
+		return false, bosherr.WrapError(err, "Generating lock id")
+	}
+
+	now := s.timeService.Now().UTC()
+
+	lock := DeploymentStateLock{
+		ID:        lockID,
+		Owner:     deploymentStateLockOwner(),
+		CreatedAt: now,
+		ExpiresAt: now.Add(DeploymentStateLockLease),
+	}
+
+	resp, err := s.writeLock(lock, func(req *http.Request) { req.Header.Set("If-None-Match", "*") })
+	if err != nil {
+		return false, err
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed || resp.StatusCode == http.StatusConflict:
+		return false, nil
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return false, bosherr.Errorf("Locking deployment state '%s': unexpected response status %d", s.Path(), resp.StatusCode)
+	}
+
+	s.lockID = lockID
+
+	return true, nil
+}
+
+// startLockRenewal renews the lease of the lock until Unlock so that the lock
+// does not expire while the command runs without saving the state,
+// e.g. while compiling packages or creating the VM
+func (s *httpDeploymentStateService) startLockRenewal() {
+	stop := make(chan struct{})
+	done := make(chan struct{})
+
+	s.stopLockRenewal = stop
+	s.lockRenewalDone = done
+
+	ticker := s.timeService.NewTicker(DeploymentStateLockRenewalInterval)
+
+	go func() {
+		defer close(done)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C():
+				err := s.renewHeldLock()
+				if err != nil {
+					s.logger.Error(s.logTag, "Renewing lock of deployment state '%s': %s", s.Path(), err.Error())
+				}
+			}
+		}
+	}()
+}
+
+func (s *httpDeploymentStateService) endLockRenewal() {
+	s.lockMutex.Lock()
+	stop, done := s.stopLockRenewal, s.lockRenewalDone
+	s.stopLockRenewal, s.lockRenewalDone = nil, nil
+	s.lockMutex.Unlock()
+
+	if stop == nil {
+		return
+	}
+
+	close(stop)
+	<-done
+}
+
+// renewHeldLock renews the lock when this command holds one
+func (s *httpDeploymentStateService) renewHeldLock() error {
+	s.lockMutex.Lock()
+	defer s.lockMutex.Unlock()
+
+	if s.lockID == "" {
+		return nil
+	}
+
+	return s.renewLock()
+}
+
+// renewLock extends the lease of the lock held by this command
+// and fails if another process took the lock over in the meantime
+func (s *httpDeploymentStateService) renewLock() error {
+	lock, etag, found, err := s.readLock()
+	if err != nil {
+		return err
+	}
+
+	if !found || lock.ID != s.lockID {
+		return bosherr.Errorf("Deployment state '%s' is no longer locked by this command (lock id '%s')", s.Path(), s.lockID)
+	}
+
+	lock.ExpiresAt = s.timeService.Now().UTC().Add(DeploymentStateLockLease)
+
+	resp, err := s.writeLock(lock, func(req *http.Request) {
+		if etag != "" {
+			req.Header.Set("If-Match", etag)
+		}
+	})
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return bosherr.Errorf("Renewing lock of deployment state '%s': unexpected response status %d", s.Path(), resp.StatusCode)
+	}
+
+	return nil
+}
+
+func (s *httpDeploymentStateService) writeLock(lock DeploymentStateLock, f func(*http.Request)) (*http.Response, error) {
+	jsonContent, err := json.Marshal(lock)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Marshalling deployment state lock into JSON")
+	}
+
+	resp, err := s.httpClient.PutCustomized(s.lockURL(), jsonContent, func(req *http.Request) {
+		req.Header.Set("Content-Type", "application/json")
+		f(req)
+	})
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Locking deployment state '%s'", s.Path())
+	}
+
+	return resp, nil
+}
+
+// readLock returns the current lock with its ETag, if the server sends one
+func (s *httpDeploymentStateService) readLock() (DeploymentStateLock, string, bool, error) {
+	resp, err := s.httpClient.Get(s.lockURL())
+	if err != nil {
+		return DeploymentStateLock{}, "", false, bosherr.WrapErrorf(err, "Reading lock of deployment state '%s'", s.Path())
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotFound:
+		return DeploymentStateLock{}, "", false, nil
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		return DeploymentStateLock{}, "", false, bosherr.Errorf("Reading lock of deployment state '%s': unexpected response status %d", s.Path(), resp.StatusCode)
+	}
+
+	var lock DeploymentStateLock
+
+	body, err := io.ReadAll(resp.Body)
+	if err == nil {
+		err = json.Unmarshal(body, &lock)
+	}
+	if err != nil {
+		return DeploymentStateLock{}, "", false, bosherr.WrapErrorf(err, "Reading lock of deployment state '%s'", s.Path())
+	}
+
+	return lock, resp.Header.Get("ETag"), true, nil
+}
+
+// deleteLock only deletes the lock that was read when given its ETag
+func (s *httpDeploymentStateService) deleteLock(etag string) error {
+	resp, err := s.httpClient.DeleteCustomized(s.lockURL(), func(req *http.Request) {
+		if etag != "" {
+			req.Header.Set("If-Match", etag)
+		}
+	})
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Unlocking deployment state '%s'", s.Path())
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusPreconditionFailed:
+		return bosherr.Errorf("Unlocking deployment state '%s': the lock changed while removing it", s.Path())
+	case resp.StatusCode != http.StatusNotFound && (resp.StatusCode < 200 || resp.StatusCode >= 300):
+		return bosherr.Errorf("Unlocking deployment state '%s': unexpected response status %d", s.Path(), resp.StatusCode)
+	}
+
+	return nil
+}
+
+func deploymentStateLockOwner() string {
+	username := "unknown"
+	if currentUser, err := user.Current(); err == nil {
+		username = currentUser.Username
+	}
+
+	hostname, err := os.Hostname()
+	if err != nil {
+		hostname = "unknown"
+	}
+
+	return fmt.Sprintf("%s@%s (pid %d)", username, hostname, os.Getpid())
+}
+
+func (s *httpDeploymentStateService) lockURL() string {
+	parsedURL, err := url.Parse(s.stateURL)
+	if err != nil {
+		return s.stateURL + ".lock"
+	}
+
+	parsedURL.Path += ".lock"
+	parsedURL.RawPath = ""
+
+	return parsedURL.String()
+}
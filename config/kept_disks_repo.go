@@ -0,0 +1,145 @@
+package config
+
+import (
+	"encoding/json"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// KeptDisk is a persistent disk left in the IaaS by delete-env --keep-persistent-disk
+type KeptDisk struct {
+	Name            string         `json:"name,omitempty"`
+	CID             string         `json:"cid"`
+	Size            int            `json:"size"`
+	CloudProperties biproperty.Map `json:"cloud_properties"`
+}
+
+type keptDisks struct {
+	Disks []KeptDisk `json:"disks"`
+}
+
+// KeptDisksRepo records kept disks in a '<state path>.kept-disks.json' file
+// next to the state file so that create-env --adopt-disk can find their size
+type KeptDisksRepo interface {
+	Path() string
+	Add(KeptDisk) error
+	Find(cid string) (KeptDisk, bool, error)
+	Remove(cid string) error
+}
+
+type keptDisksRepo struct {
+	path string
+	fs   boshsys.FileSystem
+}
+
+func NewKeptDisksRepo(fs boshsys.FileSystem, path string) KeptDisksRepo {
+	return keptDisksRepo{path: path, fs: fs}
+}
+
+// KeptDisksPath falls back to a path next to the deployment manifest for remote state
+func KeptDisksPath(deploymentManifestPath string, deploymentStatePath string) string {
+	if IsRemoteDeploymentStatePath(deploymentStatePath) {
+		deploymentStatePath = ""
+	}
+
+	return DeploymentStatePath(deploymentManifestPath, deploymentStatePath) + ".kept-disks.json"
+}
+
+func (r keptDisksRepo) Path() string {
+	return r.path
+}
+
+func (r keptDisksRepo) Add(disk KeptDisk) error {
+	records, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	newDisks := []KeptDisk{}
+	for _, record := range records.Disks {
+		if record.CID != disk.CID {
+			newDisks = append(newDisks, record)
+		}
+	}
+	records.Disks = append(newDisks, disk)
+
+	return r.save(records)
+}
+
+func (r keptDisksRepo) Find(cid string) (KeptDisk, bool, error) {
+	records, err := r.load()
+	if err != nil {
+		return KeptDisk{}, false, err
+	}
+
+	for _, record := range records.Disks {
+		if record.CID == cid {
+			return record, true, nil
+		}
+	}
+
+	return KeptDisk{}, false, nil
+}
+
+// Remove deletes the file once no kept disks are left
+func (r keptDisksRepo) Remove(cid string) error {
+	records, err := r.load()
+	if err != nil {
+		return err
+	}
+
+	newDisks := []KeptDisk{}
+	for _, record := range records.Disks {
+		if record.CID != cid {
+			newDisks = append(newDisks, record)
+		}
+	}
+
+	if len(newDisks) == 0 {
+		err := r.fs.RemoveAll(r.path)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Deleting kept disks file '%s'", r.path)
+		}
+		return nil
+	}
+
+	records.Disks = newDisks
+
+	return r.save(records)
+}
+
+func (r keptDisksRepo) load() (keptDisks, error) {
+	var records keptDisks
+
+	if !r.fs.FileExists(r.path) {
+		return records, nil
+	}
+
+	contents, err := r.fs.ReadFile(r.path)
+	if err != nil {
+		return records, bosherr.WrapErrorf(err, "Reading kept disks file '%s'", r.path)
+	}
+
+	err = json.Unmarshal(contents, &records)
+	if err != nil {
+		return records, bosherr.WrapErrorf(err, "Unmarshalling kept disks file '%s'", r.path)
+	}
+
+	return records, nil
+}
+
+func (r keptDisksRepo) save(records keptDisks) error {
+	contents, err := json.MarshalIndent(records, "", "    ")
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling kept disks")
+	}
+
+	err = r.fs.WriteFile(r.path, contents)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing kept disks file '%s'", r.path)
+	}
+
+	return nil
+}
@@ -0,0 +1,80 @@
+package config_test
+
+import (
+	"errors"
+
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/config"
+)
+
+var _ = Describe("KeptDisksRepo", func() {
+	var (
+		fakeFs *fakesys.FakeFileSystem
+		repo   KeptDisksRepo
+	)
+
+	BeforeEach(func() {
+		fakeFs = fakesys.NewFakeFileSystem()
+		repo = NewKeptDisksRepo(fakeFs, "/some/state.json.kept-disks.json")
+	})
+
+	It("finds added disks", func() {
+		err := repo.Add(KeptDisk{CID: "disk-1", Size: 1024, CloudProperties: biproperty.Map{"type": "ssd"}})
+		Expect(err).ToNot(HaveOccurred())
+
+		disk, found, err := repo.Find("disk-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(disk).To(Equal(KeptDisk{CID: "disk-1", Size: 1024, CloudProperties: biproperty.Map{"type": "ssd"}}))
+
+		_, found, err = repo.Find("disk-2")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+
+	It("replaces disks with the same cid", func() {
+		Expect(repo.Add(KeptDisk{CID: "disk-1", Size: 1024})).To(Succeed())
+		Expect(repo.Add(KeptDisk{CID: "disk-1", Size: 2048})).To(Succeed())
+
+		disk, _, err := repo.Find("disk-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(disk.Size).To(Equal(2048))
+	})
+
+	It("deletes the file once the last disk is removed", func() {
+		Expect(repo.Add(KeptDisk{CID: "disk-1", Size: 1024})).To(Succeed())
+		Expect(repo.Add(KeptDisk{CID: "disk-2", Size: 1024})).To(Succeed())
+
+		Expect(repo.Remove("disk-1")).To(Succeed())
+		Expect(fakeFs.FileExists("/some/state.json.kept-disks.json")).To(BeTrue())
+
+		_, found, err := repo.Find("disk-1")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeFalse())
+
+		Expect(repo.Remove("disk-2")).To(Succeed())
+		Expect(fakeFs.FileExists("/some/state.json.kept-disks.json")).To(BeFalse())
+	})
+
+	It("returns an error when the file cannot be written", func() {
+		fakeFs.WriteFileError = errors.New("fake-write-error")
+
+		err := repo.Add(KeptDisk{CID: "disk-1"})
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Writing kept disks file '/some/state.json.kept-disks.json'"))
+	})
+
+	Describe("KeptDisksPath", func() {
+		It("is next to the state file", func() {
+			Expect(KeptDisksPath("/some/manifest.yml", "/other/state.json")).To(Equal("/other/state.json.kept-disks.json"))
+		})
+
+		It("is next to the manifest when the state is remote", func() {
+			Expect(KeptDisksPath("/some/manifest.yml", "https://example.com/state.json")).To(Equal("/some/manifest-state.json.kept-disks.json"))
+		})
+	})
+})
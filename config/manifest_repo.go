@@ -0,0 +1,131 @@
+package config
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"io"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/hkdf"
+)
+
+const manifestSaltSize = 16
+
+type ManifestRepo interface {
+	// UpdateCurrent keeps the encrypted manifest only when a key is given
+	UpdateCurrent(redacted []byte, interpolated []byte, key string) error
+	FindCurrent() (ManifestRecord, bool, error)
+}
+
+type manifestRepo struct {
+	deploymentStateService DeploymentStateService
+}
+
+func NewManifestRepo(deploymentStateService DeploymentStateService) ManifestRepo {
+	return manifestRepo{deploymentStateService: deploymentStateService}
+}
+
+func (r manifestRepo) FindCurrent() (ManifestRecord, bool, error) {
+	deploymentState, err := r.deploymentStateService.Load()
+	if err != nil {
+		return ManifestRecord{}, false, bosherr.WrapError(err, "Loading existing config")
+	}
+
+	if deploymentState.CurrentManifest == nil {
+		return ManifestRecord{}, false, nil
+	}
+
+	return *deploymentState.CurrentManifest, true, nil
+}
+
+func (r manifestRepo) UpdateCurrent(redacted []byte, interpolated []byte, key string) error {
+	record := ManifestRecord{Redacted: string(redacted)}
+
+	if key != "" {
+		encrypted, err := encryptManifest(interpolated, key)
+		if err != nil {
+			return bosherr.WrapError(err, "Encrypting manifest")
+		}
+		record.Encrypted = encrypted
+	}
+
+	deploymentState, err := r.deploymentStateService.Load()
+	if err != nil {
+		return bosherr.WrapError(err, "Loading existing config")
+	}
+
+	deploymentState.CurrentManifest = &record
+
+	err = r.deploymentStateService.Save(deploymentState)
+	if err != nil {
+		return bosherr.WrapError(err, "Saving new config")
+	}
+
+	return nil
+}
+
+// Decrypt returns the manifest with all of its values
+func (r ManifestRecord) Decrypt(key string) ([]byte, error) {
+	if r.Encrypted == "" {
+		return nil, bosherr.Error("Expected the manifest to be stored encrypted; run create-env with a manifest key to store it")
+	}
+
+	data, err := base64.StdEncoding.DecodeString(r.Encrypted)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Decoding encrypted manifest")
+	}
+
+	if len(data) < manifestSaltSize+chacha20poly1305.NonceSizeX {
+		return nil, bosherr.Error("Encrypted manifest is too short")
+	}
+
+	salt, data := data[:manifestSaltSize], data[manifestSaltSize:]
+	nonce, ciphertext := data[:chacha20poly1305.NonceSizeX], data[chacha20poly1305.NonceSizeX:]
+
+	aead, err := manifestCipher(key, salt)
+	if err != nil {
+		return nil, err
+	}
+
+	content, err := aead.Open(nil, nonce, ciphertext, nil)
+	if err != nil {
+		return nil, bosherr.Error("Decrypting manifest failed; check the manifest key")
+	}
+
+	return content, nil
+}
+
+func encryptManifest(content []byte, key string) (string, error) {
+	salt := make([]byte, manifestSaltSize)
+	nonce := make([]byte, chacha20poly1305.NonceSizeX)
+
+	for _, buf := range [][]byte{salt, nonce} {
+		_, err := io.ReadFull(rand.Reader, buf)
+		if err != nil {
+			return "", bosherr.WrapError(err, "Generating random bytes")
+		}
+	}
+
+	aead, err := manifestCipher(key, salt)
+	if err != nil {
+		return "", err
+	}
+
+	data := append(append(salt, nonce...), aead.Seal(nil, nonce, content, nil)...)
+
+	return base64.StdEncoding.EncodeToString(data), nil
+}
+
+func manifestCipher(key string, salt []byte) (cipher.AEAD, error) {
+	derivedKey := make([]byte, chacha20poly1305.KeySize)
+
+	_, err := io.ReadFull(hkdf.New(sha256.New, []byte(key), salt, []byte("bosh-cli env manifest")), derivedKey)
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Deriving manifest key")
+	}
+
+	return chacha20poly1305.NewX(derivedKey)
+}
@@ -0,0 +1,65 @@
+package config_test
+
+import (
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+)
+
+var _ = Describe("ManifestRepo", func() {
+	var (
+		repo                   biconfig.ManifestRepo
+		deploymentStateService biconfig.DeploymentStateService
+	)
+
+	BeforeEach(func() {
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		fs := fakesys.NewFakeFileSystem()
+		deploymentStateService = biconfig.NewFileSystemDeploymentStateService(fs, fakeuuid.NewFakeGenerator(), logger, "/fake/path")
+		repo = biconfig.NewManifestRepo(deploymentStateService)
+	})
+
+	It("does not find a manifest before one is stored", func() {
+		_, found, err := repo.FindCurrent()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+
+	It("stores only the redacted manifest without a key", func() {
+		err := repo.UpdateCurrent([]byte("password: <redacted>\n"), []byte("password: secret\n"), "")
+		Expect(err).ToNot(HaveOccurred())
+
+		record, found, err := repo.FindCurrent()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(record).To(Equal(biconfig.ManifestRecord{Redacted: "password: <redacted>\n"}))
+
+		_, err = record.Decrypt("fake-key")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("Expected the manifest to be stored encrypted"))
+	})
+
+	It("stores the interpolated manifest encrypted with the key", func() {
+		err := repo.UpdateCurrent([]byte("password: <redacted>\n"), []byte("password: secret\n"), "fake-key")
+		Expect(err).ToNot(HaveOccurred())
+
+		deploymentState, err := deploymentStateService.Load()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(deploymentState.CurrentManifest.Encrypted).ToNot(ContainSubstring("secret"))
+
+		record, _, err := repo.FindCurrent()
+		Expect(err).ToNot(HaveOccurred())
+
+		content, err := record.Decrypt("fake-key")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(string(content)).To(Equal("password: secret\n"))
+
+		_, err = record.Decrypt("wrong-key")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Decrypting manifest failed; check the manifest key"))
+	})
+})
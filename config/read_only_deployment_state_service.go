@@ -0,0 +1,57 @@
+package config
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// readOnlyDeploymentStateService reads the deployment state without saving
+// defaults and refuses every change, e.g. for commands that only inspect it
+type readOnlyDeploymentStateService struct {
+	source DeploymentStateService
+}
+
+func NewReadOnlyDeploymentStateService(source DeploymentStateService) DeploymentStateService {
+	return &readOnlyDeploymentStateService{source: source}
+}
+
+func (s *readOnlyDeploymentStateService) Path() string {
+	return s.source.Path()
+}
+
+func (s *readOnlyDeploymentStateService) Exists() bool {
+	return s.source.Exists()
+}
+
+func (s *readOnlyDeploymentStateService) Load() (DeploymentState, error) {
+	reader, ok := s.source.(deploymentStateReader)
+	if !ok {
+		return DeploymentState{}, bosherr.Errorf("Deployment state '%s' cannot be read without writing it", s.source.Path())
+	}
+
+	return reader.read()
+}
+
+func (s *readOnlyDeploymentStateService) Save(DeploymentState) error {
+	return s.readOnlyError()
+}
+
+func (s *readOnlyDeploymentStateService) Cleanup() error {
+	return s.readOnlyError()
+}
+
+// Lock is a no-op since the deployment state is never written
+func (s *readOnlyDeploymentStateService) Lock() error {
+	return nil
+}
+
+func (s *readOnlyDeploymentStateService) Unlock() error {
+	return nil
+}
+
+func (s *readOnlyDeploymentStateService) BreakLock(force bool) (DeploymentStateLock, bool, error) {
+	return DeploymentStateLock{}, false, s.readOnlyError()
+}
+
+func (s *readOnlyDeploymentStateService) readOnlyError() error {
+	return bosherr.Errorf("Deployment state '%s' is opened read-only", s.source.Path())
+}
@@ -0,0 +1,71 @@
+package config_test
+
+import (
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/config"
+)
+
+var _ = Describe("readOnlyDeploymentStateService", func() {
+	var (
+		service             DeploymentStateService
+		deploymentStatePath string
+		fakeFs              *fakesys.FakeFileSystem
+	)
+
+	BeforeEach(func() {
+		fakeFs = fakesys.NewFakeFileSystem()
+		deploymentStatePath = "/some/deployment.json"
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		fakeUUIDGenerator := fakeuuid.NewFakeGenerator()
+		fakeUUIDGenerator.GeneratedUUID = "fake-uuid"
+
+		source := NewFileSystemDeploymentStateService(fakeFs, fakeUUIDGenerator, logger, deploymentStatePath)
+		service = NewReadOnlyDeploymentStateService(source)
+	})
+
+	Describe("Load", func() {
+		It("reads the state of the source", func() {
+			err := fakeFs.WriteFileString(deploymentStatePath, `{"director_id":"fake-director-id","current_vm_cid":"fake-vm-cid"}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			deploymentState, err := service.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.DirectorID).To(Equal("fake-director-id"))
+			Expect(deploymentState.CurrentVMCID).To(Equal("fake-vm-cid"))
+		})
+
+		It("does not generate a director id or write the state when there is none", func() {
+			err := fakeFs.WriteFileString(deploymentStatePath, `{"current_vm_cid":"fake-vm-cid"}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			deploymentState, err := service.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.DirectorID).To(BeEmpty())
+			Expect(fakeFs.ReadFileString(deploymentStatePath)).To(Equal(`{"current_vm_cid":"fake-vm-cid"}`))
+		})
+	})
+
+	Describe("Save", func() {
+		It("refuses to write the state", func() {
+			err := service.Save(DeploymentState{CurrentVMCID: "fake-vm-cid"})
+			Expect(err).To(MatchError("Deployment state '/some/deployment.json' is opened read-only"))
+			Expect(fakeFs.FileExists(deploymentStatePath)).To(BeFalse())
+		})
+	})
+
+	Describe("Cleanup", func() {
+		It("refuses to delete the state", func() {
+			err := fakeFs.WriteFileString(deploymentStatePath, `{}`)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = service.Cleanup()
+			Expect(err).To(HaveOccurred())
+			Expect(fakeFs.FileExists(deploymentStatePath)).To(BeTrue())
+		})
+	})
+})
@@ -0,0 +1,152 @@
+package conformance
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+type StepStatus string
+
+const (
+	StepPassed  StepStatus = "passed"
+	StepFailed  StepStatus = "failed"
+	StepSkipped StepStatus = "skipped"
+)
+
+type StepResult struct {
+	Name     string
+	Status   StepStatus
+	Duration time.Duration
+	Error    string
+}
+
+type Report struct {
+	Steps []StepResult
+}
+
+func (r Report) Count(status StepStatus) int {
+	var count int
+
+	for _, step := range r.Steps {
+		if step.Status == status {
+			count++
+		}
+	}
+
+	return count
+}
+
+func (r Report) Passed() bool {
+	return r.Count(StepFailed) == 0 && r.Count(StepSkipped) == 0
+}
+
+type jsonReport struct {
+	Passed bool             `json:"passed"`
+	Steps  []jsonStepResult `json:"steps"`
+}
+
+type jsonStepResult struct {
+	Name            string     `json:"name"`
+	Status          StepStatus `json:"status"`
+	DurationSeconds float64    `json:"duration_seconds"`
+	Error           string     `json:"error,omitempty"`
+}
+
+func (r Report) WriteJSON(w io.Writer) error {
+	report := jsonReport{Passed: r.Passed(), Steps: []jsonStepResult{}}
+
+	for _, step := range r.Steps {
+		report.Steps = append(report.Steps, jsonStepResult{
+			Name:            step.Name,
+			Status:          step.Status,
+			DurationSeconds: step.Duration.Seconds(),
+			Error:           step.Error,
+		})
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+
+	err := encoder.Encode(report)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing JSON report")
+	}
+
+	return nil
+}
+
+type junitTestSuite struct {
+	XMLName  xml.Name        `xml:"testsuite"`
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Skipped  int             `xml:"skipped,attr"`
+	Time     string          `xml:"time,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *struct{}     `xml:"skipped,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+func (r Report) WriteJUnit(w io.Writer) error {
+	suite := junitTestSuite{
+		Name:     "cpi-test",
+		Tests:    len(r.Steps),
+		Failures: r.Count(StepFailed),
+		Skipped:  r.Count(StepSkipped),
+	}
+
+	var total time.Duration
+
+	for _, step := range r.Steps {
+		total += step.Duration
+
+		testCase := junitTestCase{
+			Name:      step.Name,
+			ClassName: "cpi-test",
+			Time:      junitSeconds(step.Duration),
+		}
+
+		switch step.Status {
+		case StepFailed:
+			testCase.Failure = &junitFailure{Message: step.Error, Content: step.Error}
+		case StepSkipped:
+			testCase.Skipped = &struct{}{}
+		}
+
+		suite.Cases = append(suite.Cases, testCase)
+	}
+
+	suite.Time = junitSeconds(total)
+
+	bytes, err := xml.MarshalIndent(suite, "", "  ")
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling JUnit report")
+	}
+
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, bytes)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing JUnit report")
+	}
+
+	return nil
+}
+
+func junitSeconds(duration time.Duration) string {
+	return fmt.Sprintf("%.3f", duration.Seconds())
+}
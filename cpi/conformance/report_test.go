@@ -0,0 +1,69 @@
+package conformance_test
+
+import (
+	"bytes"
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	biconformance "github.com/cloudfoundry/bosh-cli/v7/cpi/conformance"
+)
+
+var _ = Describe("Report", func() {
+	var report biconformance.Report
+
+	BeforeEach(func() {
+		report = biconformance.Report{
+			Steps: []biconformance.StepResult{
+				{Name: "info", Status: biconformance.StepPassed, Duration: 1500 * time.Millisecond},
+				{Name: "create_stemcell", Status: biconformance.StepFailed, Duration: 2 * time.Second, Error: "fake-error"},
+				{Name: "create_vm", Status: biconformance.StepSkipped},
+			},
+		}
+	})
+
+	It("passes only without failed or skipped steps", func() {
+		Expect(report.Passed()).To(BeFalse())
+		Expect(biconformance.Report{Steps: report.Steps[:1]}.Passed()).To(BeTrue())
+	})
+
+	Describe("WriteJSON", func() {
+		It("writes every step with its status", func() {
+			buffer := &bytes.Buffer{}
+
+			err := report.WriteJSON(buffer)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(buffer.String()).To(MatchJSON(`{
+				"passed": false,
+				"steps": [
+					{"name": "info", "status": "passed", "duration_seconds": 1.5},
+					{"name": "create_stemcell", "status": "failed", "duration_seconds": 2, "error": "fake-error"},
+					{"name": "create_vm", "status": "skipped", "duration_seconds": 0}
+				]
+			}`))
+		})
+	})
+
+	Describe("WriteJUnit", func() {
+		It("writes a test case for every step", func() {
+			buffer := &bytes.Buffer{}
+
+			err := report.WriteJUnit(buffer)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(buffer.String()).To(Equal(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuite name="cpi-test" tests="3" failures="1" skipped="1" time="3.500">
+  <testcase name="info" classname="cpi-test" time="1.500"></testcase>
+  <testcase name="create_stemcell" classname="cpi-test" time="2.000">
+    <failure message="fake-error">fake-error</failure>
+  </testcase>
+  <testcase name="create_vm" classname="cpi-test" time="0.000">
+    <skipped></skipped>
+  </testcase>
+</testsuite>
+`))
+		})
+	})
+})
@@ -0,0 +1,226 @@
+package conformance
+
+import (
+	"code.cloudfoundry.org/clock"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+// Config holds the values the suite passes to the CPI
+type Config struct {
+	AgentID string
+
+	StemcellImagePath       string
+	StemcellCloudProperties biproperty.Map
+
+	VMCloudProperties biproperty.Map
+	VMEnv             biproperty.Map
+	Networks          map[string]biproperty.Map
+
+	DiskSize            int
+	DiskCloudProperties biproperty.Map
+}
+
+// Suite runs the CPI methods used by create-env and delete-env in lifecycle order
+// and checks their responses against what the CLI expects for the CPI API version
+type Suite interface {
+	Run(cloud bicloud.Cloud, config Config, stage biui.Stage) Report
+}
+
+type suite struct {
+	timeService clock.Clock
+	logger      boshlog.Logger
+	logTag      string
+}
+
+func NewSuite(timeService clock.Clock, logger boshlog.Logger) Suite {
+	return suite{
+		timeService: timeService,
+		logger:      logger,
+		logTag:      "cpiConformanceSuite",
+	}
+}
+
+func (s suite) Run(cloud bicloud.Cloud, config Config, stage biui.Stage) Report {
+	var (
+		report   Report
+		cpiInfo  bicloud.CpiInfo
+		attached bool
+
+		stemcellCID string
+		vmCID       string
+		diskCID     string
+	)
+
+	// step runs fn when all of its resources exist; steps that delete
+	// resources therefore still run after steps in between have failed
+	step := func(name string, ready bool, fn func() error) {
+		if !ready {
+			report.Steps = append(report.Steps, StepResult{Name: name, Status: StepSkipped})
+			return
+		}
+
+		startTime := s.timeService.Now()
+		err := stage.Perform(name, fn)
+
+		result := StepResult{
+			Name:     name,
+			Status:   StepPassed,
+			Duration: s.timeService.Since(startTime),
+		}
+
+		if err != nil {
+			s.logger.Error(s.logTag, "Step '%s' failed: %s", name, err.Error())
+			result.Status = StepFailed
+			result.Error = err.Error()
+		}
+
+		report.Steps = append(report.Steps, result)
+	}
+
+	step("info", true, func() error {
+		var err error
+
+		cpiInfo, err = cloud.Info()
+		if err != nil {
+			return err
+		}
+
+		if len(cpiInfo.StemcellFormats) == 0 {
+			return bosherr.Error("Expected 'info' to return at least one stemcell format")
+		}
+
+		return nil
+	})
+
+	step("create_stemcell", true, func() error {
+		cid, err := cloud.CreateStemcell(config.StemcellImagePath, config.StemcellCloudProperties)
+		if err != nil {
+			return err
+		}
+
+		if cid == "" {
+			return bosherr.Error("Expected 'create_stemcell' to return a stemcell CID")
+		}
+
+		stemcellCID = cid
+
+		return nil
+	})
+
+	step("create_vm", stemcellCID != "", func() error {
+		cid, err := cloud.CreateVM(config.AgentID, stemcellCID, config.VMCloudProperties, config.Networks, config.VMEnv)
+		if err != nil {
+			return err
+		}
+
+		if cid == "" {
+			return bosherr.Error("Expected 'create_vm' to return a VM CID")
+		}
+
+		vmCID = cid
+
+		return nil
+	})
+
+	step("has_vm", vmCID != "", func() error {
+		return s.expectHasVM(cloud, vmCID, true)
+	})
+
+	step("set_vm_metadata", vmCID != "", func() error {
+		return cloud.SetVMMetadata(vmCID, bicloud.VMMetadata{
+			"director":   "bosh-init",
+			"deployment": "cpi-test",
+			"job":        "cpi-test",
+			"index":      "0",
+			"name":       "cpi-test/0",
+		})
+	})
+
+	step("create_disk", vmCID != "", func() error {
+		cid, err := cloud.CreateDisk(config.DiskSize, config.DiskCloudProperties, vmCID)
+		if err != nil {
+			return err
+		}
+
+		if cid == "" {
+			return bosherr.Error("Expected 'create_disk' to return a disk CID")
+		}
+
+		diskCID = cid
+
+		return nil
+	})
+
+	step("attach_disk", vmCID != "" && diskCID != "", func() error {
+		diskHint, err := cloud.AttachDisk(vmCID, diskCID)
+		if err != nil {
+			return err
+		}
+
+		attached = true
+
+		if cpiInfo.ApiVersion >= bicloud.MaxCpiApiVersionSupported && diskHint == nil {
+			return bosherr.Errorf("Expected 'attach_disk' to return a disk hint for CPI API version %d", cpiInfo.ApiVersion)
+		}
+
+		return nil
+	})
+
+	step("set_disk_metadata", diskCID != "", func() error {
+		return cloud.SetDiskMetadata(diskCID, bicloud.DiskMetadata{
+			"director":      "bosh-init",
+			"deployment":    "cpi-test",
+			"instance_id":   "cpi-test",
+			"instance_name": "cpi-test/0",
+		})
+	})
+
+	step("detach_disk", attached, func() error {
+		return cloud.DetachDisk(vmCID, diskCID)
+	})
+
+	step("delete_disk", diskCID != "", func() error {
+		return cloud.DeleteDisk(diskCID)
+	})
+
+	deletedVM := false
+
+	step("delete_vm", vmCID != "", func() error {
+		err := cloud.DeleteVM(vmCID)
+		if err != nil {
+			return err
+		}
+
+		deletedVM = true
+
+		return nil
+	})
+
+	step("has_vm (deleted)", deletedVM, func() error {
+		return s.expectHasVM(cloud, vmCID, false)
+	})
+
+	step("delete_stemcell", stemcellCID != "", func() error {
+		return cloud.DeleteStemcell(stemcellCID)
+	})
+
+	return report
+}
+
+func (s suite) expectHasVM(cloud bicloud.Cloud, vmCID string, expected bool) error {
+	found, err := cloud.HasVM(vmCID)
+	if err != nil {
+		return err
+	}
+
+	if found != expected {
+		return bosherr.Errorf("Expected 'has_vm' to return '%t' for VM '%s'", expected, vmCID)
+	}
+
+	return nil
+}
@@ -0,0 +1,128 @@
+package conformance_test
+
+import (
+	"os"
+	"path/filepath"
+
+	"code.cloudfoundry.org/clock"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	biconformance "github.com/cloudfoundry/bosh-cli/v7/cpi/conformance"
+	fakebiui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("Suite", func() {
+	var (
+		jobsDir string
+		cloud   bicloud.Cloud
+		stage   *fakebiui.FakeStage
+		config  biconformance.Config
+		suite   biconformance.Suite
+	)
+
+	BeforeEach(func() {
+		jobsDir = GinkgoT().TempDir()
+
+		jobPath, err := filepath.Abs(filepath.Join("assets", "fake-cpi"))
+		Expect(err).ToNot(HaveOccurred())
+
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		cpi := bicloud.CPI{JobPath: jobPath, JobsDir: jobsDir, PackagesDir: jobsDir}
+		cloud = bicloud.NewCloud(bicloud.NewCPICmdRunner(boshsys.NewExecCmdRunner(logger), cpi, logger), "fake-director-id", 1, logger)
+
+		stage = fakebiui.NewFakeStage()
+		config = biconformance.Config{
+			AgentID:           "fake-agent-id",
+			StemcellImagePath: "/fake-stemcell/image",
+			VMCloudProperties: biproperty.Map{"instance_type": "fake"},
+			Networks:          map[string]biproperty.Map{"default": {"type": "dynamic"}},
+			DiskSize:          1024,
+		}
+		suite = biconformance.NewSuite(clock.NewClock(), logger)
+	})
+
+	failCPIMethods := func(methods string) {
+		err := os.MkdirAll(filepath.Join(jobsDir, "fake-cpi"), os.ModePerm)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = os.WriteFile(filepath.Join(jobsDir, "fake-cpi", "fail"), []byte(methods), 0644)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	statuses := func(report biconformance.Report) map[string]biconformance.StepStatus {
+		result := map[string]biconformance.StepStatus{}
+		for _, step := range report.Steps {
+			result[step.Name] = step.Status
+		}
+		return result
+	}
+
+	It("passes every lifecycle step against a conforming CPI", func() {
+		report := suite.Run(cloud, config, stage)
+
+		Expect(report.Passed()).To(BeTrue())
+
+		var names []string
+		for _, step := range report.Steps {
+			names = append(names, step.Name)
+		}
+
+		Expect(names).To(Equal([]string{
+			"info",
+			"create_stemcell",
+			"create_vm",
+			"has_vm",
+			"set_vm_metadata",
+			"create_disk",
+			"attach_disk",
+			"set_disk_metadata",
+			"detach_disk",
+			"delete_disk",
+			"delete_vm",
+			"has_vm (deleted)",
+			"delete_stemcell",
+		}))
+
+		Expect(stage.PerformCalls).To(HaveLen(13))
+	})
+
+	It("skips steps that need a resource that could not be created and still deletes the others", func() {
+		failCPIMethods("create_disk\n")
+
+		report := suite.Run(cloud, config, stage)
+
+		Expect(report.Passed()).To(BeFalse())
+		Expect(statuses(report)).To(Equal(map[string]biconformance.StepStatus{
+			"info":              biconformance.StepPassed,
+			"create_stemcell":   biconformance.StepPassed,
+			"create_vm":         biconformance.StepPassed,
+			"has_vm":            biconformance.StepPassed,
+			"set_vm_metadata":   biconformance.StepPassed,
+			"create_disk":       biconformance.StepFailed,
+			"attach_disk":       biconformance.StepSkipped,
+			"set_disk_metadata": biconformance.StepSkipped,
+			"detach_disk":       biconformance.StepSkipped,
+			"delete_disk":       biconformance.StepSkipped,
+			"delete_vm":         biconformance.StepPassed,
+			"has_vm (deleted)":  biconformance.StepPassed,
+			"delete_stemcell":   biconformance.StepPassed,
+		}))
+
+		Expect(report.Steps[5].Error).To(ContainSubstring("fake-cpi failed 'create_disk'"))
+	})
+
+	It("fails has_vm when the CPI still reports a deleted VM", func() {
+		failCPIMethods("delete_vm\n")
+
+		report := suite.Run(cloud, config, stage)
+
+		Expect(statuses(report)["delete_vm"]).To(Equal(biconformance.StepFailed))
+		Expect(statuses(report)["has_vm (deleted)"]).To(Equal(biconformance.StepSkipped))
+		Expect(statuses(report)["delete_stemcell"]).To(Equal(biconformance.StepPassed))
+	})
+})
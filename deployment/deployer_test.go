@@ -9,6 +9,7 @@ import (
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -19,6 +20,7 @@ import (
 	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
 	fakebiconfig "github.com/cloudfoundry/bosh-cli/v7/config/fakes"
 	. "github.com/cloudfoundry/bosh-cli/v7/deployment"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
 	biinstance "github.com/cloudfoundry/bosh-cli/v7/deployment/instance"
 	mock_instance_state "github.com/cloudfoundry/bosh-cli/v7/deployment/instance/state/mocks"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest"
@@ -133,8 +135,10 @@ var _ = Describe("Deployer", func() {
 		mockStateBuilder = mock_instance_state.NewMockBuilder(mockCtrl)
 		mockState = mock_instance_state.NewMockState(mockCtrl)
 
-		instanceFactory := biinstance.NewFactory(mockStateBuilderFactory)
-		instanceManagerFactory := biinstance.NewManagerFactory(fakeSSHTunnelFactory, instanceFactory, logger)
+		hookRunner := bihook.NewRunner(bihook.StaticSource{}, fakesys.NewFakeCmdRunner(), logger)
+		fakeDiskRepo := fakebiconfig.NewFakeDiskRepo()
+		instanceFactory := biinstance.NewFactory(mockStateBuilderFactory, hookRunner, fakeDiskRepo)
+		instanceManagerFactory := biinstance.NewManagerFactory(fakeSSHTunnelFactory, instanceFactory, hookRunner, fakeDiskRepo, logger)
 
 		mockBlobstore = mock_blobstore.NewMockBlobstore(mockCtrl)
 
@@ -14,6 +14,7 @@ import (
 
 type Deployment interface {
 	Delete(bool, biui.Stage) error
+	DeleteKeepingDisks(bool, biui.Stage) error
 	Stop(bool, biui.Stage) error
 	Start(biui.Stage, bideplmanifest.Update) error
 }
@@ -43,6 +44,15 @@ func NewDeployment(
 }
 
 func (d *deployment) Delete(skipDrain bool, deleteStage biui.Stage) error {
+	return d.delete(skipDrain, false, deleteStage)
+}
+
+// DeleteKeepingDisks deletes the instances and stemcells but leaves the disks in the IaaS
+func (d *deployment) DeleteKeepingDisks(skipDrain bool, deleteStage biui.Stage) error {
+	return d.delete(skipDrain, true, deleteStage)
+}
+
+func (d *deployment) delete(skipDrain bool, keepDisks bool, deleteStage biui.Stage) error {
 	// le sigh... consuming from an array sucks without generics
 	for len(d.instances) > 0 {
 		lastIdx := len(d.instances) - 1
@@ -55,7 +65,7 @@ func (d *deployment) Delete(skipDrain bool, deleteStage biui.Stage) error {
 		d.instances = d.instances[:lastIdx]
 	}
 
-	for len(d.disks) > 0 {
+	for len(d.disks) > 0 && !keepDisks {
 		lastIdx := len(d.disks) - 1
 		disk := d.disks[lastIdx]
 
@@ -21,6 +21,7 @@ import (
 	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
 	. "github.com/cloudfoundry/bosh-cli/v7/deployment"
 	bidisk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
 	biinstance "github.com/cloudfoundry/bosh-cli/v7/deployment/instance"
 	mockinstancestate "github.com/cloudfoundry/bosh-cli/v7/deployment/instance/state/mocks"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest"
@@ -126,8 +127,9 @@ var _ = Describe("Deployment", func() {
 		mockStateBuilder = mockinstancestate.NewMockBuilder(mockCtrl)
 		mockState = mockinstancestate.NewMockState(mockCtrl)
 
-		instanceFactory := biinstance.NewFactory(mockStateBuilderFactory)
-		instanceManagerFactory := biinstance.NewManagerFactory(sshTunnelFactory, instanceFactory, logger)
+		hookRunner := bihook.NewRunner(bihook.StaticSource{}, fakesys.NewFakeCmdRunner(), logger)
+		instanceFactory := biinstance.NewFactory(mockStateBuilderFactory, hookRunner, diskRepo)
+		instanceManagerFactory := biinstance.NewManagerFactory(sshTunnelFactory, instanceFactory, hookRunner, diskRepo, logger)
 		stemcellManagerFactory := bistemcell.NewManagerFactory(stemcellRepo)
 
 		mockBlobstore = mockblobstore.NewMockBlobstore(mockCtrl)
@@ -221,6 +223,29 @@ var _ = Describe("Deployment", func() {
 				Expect(err).ToNot(HaveOccurred())
 			})
 
+			It("keeps the disk and its record when deleting while keeping disks", func() {
+				gomock.InOrder(
+					mockCloud.EXPECT().HasVM("fake-vm-cid").Return(true, nil),
+					mockAgentClient.EXPECT().Ping().Return("any-state", nil),
+					mockAgentClient.EXPECT().RunScript("pre-stop", map[string]interface{}{}),
+					mockAgentClient.EXPECT().Drain("shutdown"),
+					mockAgentClient.EXPECT().Stop(),
+					mockAgentClient.EXPECT().RunScript("post-stop", map[string]interface{}{}),
+					mockAgentClient.EXPECT().ListDisk().Return([]string{"fake-disk-cid"}, nil),
+					mockAgentClient.EXPECT().UnmountDisk("fake-disk-cid"),
+					mockCloud.EXPECT().DeleteVM("fake-vm-cid"),
+					mockCloud.EXPECT().DeleteStemcell("fake-stemcell-cid"),
+				)
+
+				err := deployment.DeleteKeepingDisks(skipDrain, fakeStage)
+				Expect(err).ToNot(HaveOccurred())
+
+				diskRecords, err := diskRepo.FindCurrent()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(diskRecords).To(HaveLen(1), "should still have a current disk")
+				Expect(diskRecords[0].CID).To(Equal("fake-disk-cid"))
+			})
+
 			It("logs validation stages", func() {
 				expectNormalFlow()
 
@@ -250,9 +275,9 @@ var _ = Describe("Deployment", func() {
 				Expect(err).ToNot(HaveOccurred())
 				Expect(found).To(BeFalse(), "should be no current VM")
 
-				_, found, err = diskRepo.FindCurrent()
+				currentDiskRecords, err := diskRepo.FindCurrent()
 				Expect(err).ToNot(HaveOccurred())
-				Expect(found).To(BeFalse(), "should be no current disk")
+				Expect(currentDiskRecords).To(BeEmpty(), "should be no current disk")
 
 				diskRecords, err := diskRepo.All()
 				Expect(err).ToNot(HaveOccurred())
@@ -388,9 +413,9 @@ var _ = Describe("Deployment", func() {
 			BeforeEach(func() {
 				err := deploymentStateService.Save(biconfig.DeploymentState{})
 				Expect(err).ToNot(HaveOccurred())
-				diskRecord, err := diskRepo.Save("fake-disk-cid", 100, nil)
+				diskRecord, err := diskRepo.Save("", "fake-disk-cid", 100, nil)
 				Expect(err).ToNot(HaveOccurred())
-				err = diskRepo.UpdateCurrent(diskRecord.ID)
+				err = diskRepo.UpdateCurrent([]string{diskRecord.ID})
 				Expect(err).ToNot(HaveOccurred())
 			})
 
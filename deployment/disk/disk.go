@@ -11,12 +11,15 @@ import (
 )
 
 type Disk interface {
+	// Name is the name of the persistent disk in the manifest, empty for the single disk of persistent_disk
+	Name() string
 	CID() string
 	NeedsMigration(newSize int, newCloudProperties biproperty.Map) (bool, error)
 	Delete() error
 }
 
 type disk struct {
+	name            string
 	cid             string
 	size            int
 	cloudProperties biproperty.Map
@@ -31,6 +34,7 @@ func NewDisk(
 	repo biconfig.DiskRepo,
 ) Disk {
 	return &disk{
+		name:            diskRecord.Name,
 		cid:             diskRecord.CID,
 		size:            diskRecord.Size,
 		cloudProperties: diskRecord.CloudProperties,
@@ -39,6 +43,41 @@ func NewDisk(
 	}
 }
 
+// MatchCurrentDisk finds the current disk with the given name. The first disk pool also takes
+// the disk of a job that switches between persistent_disk and persistent_disks. It also
+// returns the current disks that are left.
+func MatchCurrentDisk(currentDisks []Disk, name string, first bool) (Disk, []Disk) {
+	matchIdx := -1
+
+	for idx, disk := range currentDisks {
+		if disk.Name() == name {
+			matchIdx = idx
+			break
+		}
+	}
+
+	if matchIdx == -1 && first {
+		for idx, disk := range currentDisks {
+			if disk.Name() == "" || name == "" {
+				matchIdx = idx
+				break
+			}
+		}
+	}
+
+	if matchIdx == -1 {
+		return nil, currentDisks
+	}
+
+	remainingDisks := append(append([]Disk{}, currentDisks[:matchIdx]...), currentDisks[matchIdx+1:]...)
+
+	return currentDisks[matchIdx], remainingDisks
+}
+
+func (d *disk) Name() string {
+	return d.name
+}
+
 func (d *disk) CID() string {
 	return d.cid
 }
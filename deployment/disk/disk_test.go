@@ -102,7 +102,7 @@ var _ = Describe("Disk", func() {
 		})
 
 		It("deletes disk from repo", func() {
-			_, err := diskRepo.Save("fake-disk-cid", 1024, diskCloudProperties)
+			_, err := diskRepo.Save("", "fake-disk-cid", 1024, diskCloudProperties)
 			Expect(err).ToNot(HaveOccurred())
 
 			err = disk.Delete()
@@ -114,10 +114,10 @@ var _ = Describe("Disk", func() {
 
 		Context("when deleted disk is the current disk", func() {
 			BeforeEach(func() {
-				diskRecord, err := diskRepo.Save("fake-disk-cid", 1024, diskCloudProperties)
+				diskRecord, err := diskRepo.Save("", "fake-disk-cid", 1024, diskCloudProperties)
 				Expect(err).ToNot(HaveOccurred())
 
-				err = diskRepo.UpdateCurrent(diskRecord.ID)
+				err = diskRepo.UpdateCurrent([]string{diskRecord.ID})
 				Expect(err).ToNot(HaveOccurred())
 			})
 
@@ -125,9 +125,9 @@ var _ = Describe("Disk", func() {
 				err := disk.Delete()
 				Expect(err).ToNot(HaveOccurred())
 
-				_, found, err := diskRepo.FindCurrent()
+				currentRecords, err := diskRepo.FindCurrent()
 				Expect(err).ToNot(HaveOccurred())
-				Expect(found).To(BeFalse())
+				Expect(currentRecords).To(BeEmpty())
 			})
 		})
 
@@ -150,10 +150,10 @@ var _ = Describe("Disk", func() {
 			})
 
 			BeforeEach(func() {
-				diskRecord, err := diskRepo.Save("fake-disk-cid", 1024, diskCloudProperties)
+				diskRecord, err := diskRepo.Save("", "fake-disk-cid", 1024, diskCloudProperties)
 				Expect(err).ToNot(HaveOccurred())
 
-				err = diskRepo.UpdateCurrent(diskRecord.ID)
+				err = diskRepo.UpdateCurrent([]string{diskRecord.ID})
 				Expect(err).ToNot(HaveOccurred())
 
 				fakeCloud.DeleteDiskErr = deleteErr
@@ -186,9 +186,9 @@ var _ = Describe("Disk", func() {
 				Expect(err).To(HaveOccurred())
 				Expect(err).To(Equal(deleteErr))
 
-				_, found, err := diskRepo.FindCurrent()
+				currentRecords, err := diskRepo.FindCurrent()
 				Expect(err).ToNot(HaveOccurred())
-				Expect(found).To(BeFalse())
+				Expect(currentRecords).To(BeEmpty())
 			})
 		})
 	})
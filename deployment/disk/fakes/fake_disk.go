@@ -5,7 +5,8 @@ import (
 )
 
 type FakeDisk struct {
-	cid string
+	name string
+	cid  string
 
 	NeedsMigrationInputs []NeedsMigrationInput
 	needsMigrationOutput needsMigrationOutput
@@ -30,6 +31,17 @@ func NewFakeDisk(cid string) *FakeDisk {
 	}
 }
 
+// NewFakeNamedDisk returns a fake of a named persistent disk
+func NewFakeNamedDisk(name string, cid string) *FakeDisk {
+	disk := NewFakeDisk(cid)
+	disk.name = name
+	return disk
+}
+
+func (d *FakeDisk) Name() string {
+	return d.name
+}
+
 func (d *FakeDisk) CID() string {
 	return d.cid
 }
@@ -1,6 +1,8 @@
 package fakes
 
 import (
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+
 	bidisk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest"
 	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
@@ -11,6 +13,10 @@ type FakeManager struct {
 	CreateDisk   bidisk.Disk
 	CreateErr    error
 
+	AdoptInputs []AdoptInput
+	AdoptDisk   bidisk.Disk
+	AdoptErr    error
+
 	findCurrentOutput findCurrentOutput
 
 	DeleteUnusedCalledTimes int
@@ -20,10 +26,18 @@ type FakeManager struct {
 }
 
 type CreateInput struct {
+	Name       string
 	DiskPool   bideplmanifest.DiskPool
 	InstanceID string
 }
 
+type AdoptInput struct {
+	Name            string
+	CID             string
+	Size            int
+	CloudProperties biproperty.Map
+}
+
 type findCurrentOutput struct {
 	Disks []bidisk.Disk
 	Err   error
@@ -38,8 +52,9 @@ func NewFakeManager() *FakeManager {
 	return &FakeManager{}
 }
 
-func (m *FakeManager) Create(diskPool bideplmanifest.DiskPool, instanceID string) (bidisk.Disk, error) {
+func (m *FakeManager) Create(name string, diskPool bideplmanifest.DiskPool, instanceID string) (bidisk.Disk, error) {
 	input := CreateInput{
+		Name:       name,
 		DiskPool:   diskPool,
 		InstanceID: instanceID,
 	}
@@ -48,6 +63,18 @@ func (m *FakeManager) Create(diskPool bideplmanifest.DiskPool, instanceID string
 	return m.CreateDisk, m.CreateErr
 }
 
+func (m *FakeManager) Adopt(name string, cid string, size int, cloudProperties biproperty.Map) (bidisk.Disk, error) {
+	input := AdoptInput{
+		Name:            name,
+		CID:             cid,
+		Size:            size,
+		CloudProperties: cloudProperties,
+	}
+	m.AdoptInputs = append(m.AdoptInputs, input)
+
+	return m.AdoptDisk, m.AdoptErr
+}
+
 func (m *FakeManager) FindCurrent() ([]bidisk.Disk, error) {
 	return m.findCurrentOutput.Disks, m.findCurrentOutput.Err
 }
@@ -5,6 +5,7 @@ import (
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
 
 	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
 	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
@@ -14,7 +15,8 @@ import (
 
 type Manager interface {
 	FindCurrent() ([]Disk, error)
-	Create(bideplmanifest.DiskPool, string) (Disk, error)
+	Create(name string, diskPool bideplmanifest.DiskPool, vmCID string) (Disk, error)
+	Adopt(name string, cid string, size int, cloudProperties biproperty.Map) (Disk, error)
 	FindUnused() ([]Disk, error)
 	DeleteUnused(biui.Stage) error
 }
@@ -42,20 +44,19 @@ type manager struct {
 func (m *manager) FindCurrent() ([]Disk, error) {
 	disks := []Disk{}
 
-	diskRecord, found, err := m.diskRepo.FindCurrent()
+	diskRecords, err := m.diskRepo.FindCurrent()
 	if err != nil {
 		return disks, bosherr.WrapError(err, "Reading disk record")
 	}
 
-	if found {
-		disk := NewDisk(diskRecord, m.cloud, m.diskRepo)
-		disks = append(disks, disk)
+	for _, diskRecord := range diskRecords {
+		disks = append(disks, NewDisk(diskRecord, m.cloud, m.diskRepo))
 	}
 
 	return disks, nil
 }
 
-func (m *manager) Create(diskPool bideplmanifest.DiskPool, vmCID string) (Disk, error) {
+func (m *manager) Create(name string, diskPool bideplmanifest.DiskPool, vmCID string) (Disk, error) {
 	diskCloudProperties := diskPool.CloudProperties
 
 	m.logger.Debug(m.logTag, "Creating disk")
@@ -68,7 +69,7 @@ func (m *manager) Create(diskPool bideplmanifest.DiskPool, vmCID string) (Disk,
 			)
 	}
 
-	diskRecord, err := m.diskRepo.Save(cid, diskPool.DiskSize, diskCloudProperties)
+	diskRecord, err := m.diskRepo.Save(name, cid, diskPool.DiskSize, diskCloudProperties)
 	if err != nil {
 		return nil, bosherr.WrapError(err, "Saving deployment disk record")
 	}
@@ -78,6 +79,47 @@ func (m *manager) Create(diskPool bideplmanifest.DiskPool, vmCID string) (Disk,
 	return disk, nil
 }
 
+// Adopt makes an existing disk in the IaaS the current disk with the given name so that it gets attached on deploy
+func (m *manager) Adopt(name string, cid string, size int, cloudProperties biproperty.Map) (Disk, error) {
+	currentDiskRecords, err := m.diskRepo.FindCurrent()
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Finding current disk record")
+	}
+
+	currentDiskIDs := []string{}
+
+	for _, currentDiskRecord := range currentDiskRecords {
+		if currentDiskRecord.CID == cid {
+			return NewDisk(currentDiskRecord, m.cloud, m.diskRepo), nil
+		}
+
+		if currentDiskRecord.Name == name {
+			return nil, bosherr.Errorf("Deployment already has disk '%s'", currentDiskRecord.CID)
+		}
+
+		currentDiskIDs = append(currentDiskIDs, currentDiskRecord.ID)
+	}
+
+	diskRecord, found, err := m.diskRepo.Find(cid)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Finding disk record (cid=%s)", cid)
+	}
+
+	if !found {
+		diskRecord, err = m.diskRepo.Save(name, cid, size, cloudProperties)
+		if err != nil {
+			return nil, bosherr.WrapError(err, "Saving deployment disk record")
+		}
+	}
+
+	err = m.diskRepo.UpdateCurrent(append(currentDiskIDs, diskRecord.ID))
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Updating current disk record")
+	}
+
+	return NewDisk(diskRecord, m.cloud, m.diskRepo), nil
+}
+
 func (m *manager) FindUnused() ([]Disk, error) {
 	disks := []Disk{}
 
@@ -86,13 +128,18 @@ func (m *manager) FindUnused() ([]Disk, error) {
 		return disks, bosherr.WrapError(err, "Getting all disk records")
 	}
 
-	currentDiskRecord, found, err := m.diskRepo.FindCurrent()
+	currentDiskRecords, err := m.diskRepo.FindCurrent()
 	if err != nil {
 		return disks, bosherr.WrapError(err, "Finding current disk record")
 	}
 
+	currentDiskIDs := map[string]bool{}
+	for _, currentDiskRecord := range currentDiskRecords {
+		currentDiskIDs[currentDiskRecord.ID] = true
+	}
+
 	for _, diskRecord := range diskRecords {
-		if !found || diskRecord.ID != currentDiskRecord.ID {
+		if !currentDiskIDs[diskRecord.ID] {
 			disks = append(disks, NewDisk(diskRecord, m.cloud, m.diskRepo))
 		}
 	}
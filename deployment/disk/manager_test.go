@@ -61,13 +61,13 @@ var _ = Describe("Manager", func() {
 			})
 
 			It("returns a disk", func() {
-				disk, err := manager.Create(diskPool, "fake-vm-cid")
+				disk, err := manager.Create("", diskPool, "fake-vm-cid")
 				Expect(err).ToNot(HaveOccurred())
 				Expect(disk.CID()).To(Equal("fake-disk-cid"))
 			})
 
 			It("saves the disk record", func() {
-				_, err := manager.Create(diskPool, "fake-vm-cid")
+				_, err := manager.Create("", diskPool, "fake-vm-cid")
 				Expect(err).ToNot(HaveOccurred())
 
 				diskRecord, found, err := diskRepo.Find("fake-disk-cid")
@@ -85,13 +85,25 @@ var _ = Describe("Manager", func() {
 			})
 		})
 
+		It("records the name of the persistent disk", func() {
+			fakeCloud.CreateDiskCID = "fake-disk-cid"
+
+			disk, err := manager.Create("data", diskPool, "fake-vm-cid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(disk.Name()).To(Equal("data"))
+
+			diskRecord, _, err := diskRepo.Find("fake-disk-cid")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskRecord.Name).To(Equal("data"))
+		})
+
 		Context("when creating disk fails", func() {
 			BeforeEach(func() {
 				fakeCloud.CreateDiskErr = errors.New("fake-create-error")
 			})
 
 			It("returns an error", func() {
-				_, err := manager.Create(diskPool, "fake-vm-cid")
+				_, err := manager.Create("", diskPool, "fake-vm-cid")
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-create-error"))
 			})
@@ -103,7 +115,7 @@ var _ = Describe("Manager", func() {
 			})
 
 			It("returns an error", func() {
-				_, err := manager.Create(diskPool, "fake-vm-cid")
+				_, err := manager.Create("", diskPool, "fake-vm-cid")
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-write-error"))
 			})
@@ -113,10 +125,10 @@ var _ = Describe("Manager", func() {
 	Describe("FindCurrent", func() {
 		Context("when disk already exists in disk repo", func() {
 			BeforeEach(func() {
-				diskRecord, err := diskRepo.Save("fake-existing-disk-cid", 1024, biproperty.Map{})
+				diskRecord, err := diskRepo.Save("", "fake-existing-disk-cid", 1024, biproperty.Map{})
 				Expect(err).ToNot(HaveOccurred())
 
-				err = diskRepo.UpdateCurrent(diskRecord.ID)
+				err = diskRepo.UpdateCurrent([]string{diskRecord.ID})
 				Expect(err).ToNot(HaveOccurred())
 			})
 
@@ -151,6 +163,63 @@ var _ = Describe("Manager", func() {
 		})
 	})
 
+	Describe("Adopt", func() {
+		It("saves the disk record and makes it the current disk", func() {
+			disk, err := manager.Adopt("", "fake-disk-cid", 2048, biproperty.Map{"type": "ssd"})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(disk.CID()).To(Equal("fake-disk-cid"))
+
+			diskRecords, err := diskRepo.FindCurrent()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskRecords).To(Equal([]biconfig.DiskRecord{
+				{
+					ID:              "fake-uuid",
+					CID:             "fake-disk-cid",
+					Size:            2048,
+					CloudProperties: biproperty.Map{"type": "ssd"},
+				},
+			}))
+		})
+
+		It("does nothing when the disk is already the current disk", func() {
+			_, err := manager.Adopt("", "fake-disk-cid", 2048, biproperty.Map{})
+			Expect(err).ToNot(HaveOccurred())
+
+			disk, err := manager.Adopt("", "fake-disk-cid", 4096, biproperty.Map{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(disk.CID()).To(Equal("fake-disk-cid"))
+
+			diskRecords, err := diskRepo.All()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskRecords).To(HaveLen(1))
+		})
+
+		It("returns an error when the deployment already has another disk", func() {
+			_, err := manager.Adopt("", "fake-disk-cid", 2048, biproperty.Map{})
+			Expect(err).ToNot(HaveOccurred())
+
+			_, err = manager.Adopt("", "other-disk-cid", 2048, biproperty.Map{})
+			Expect(err).To(MatchError("Deployment already has disk 'fake-disk-cid'"))
+		})
+
+		It("adds the disk to the current disks when the deployment has disks with other names", func() {
+			fakeUUIDGenerator.GeneratedUUID = "fake-guid-1"
+			_, err := manager.Adopt("data", "fake-disk-cid-1", 2048, biproperty.Map{})
+			Expect(err).ToNot(HaveOccurred())
+
+			fakeUUIDGenerator.GeneratedUUID = "fake-guid-2"
+			disk, err := manager.Adopt("logs", "fake-disk-cid-2", 1024, biproperty.Map{})
+			Expect(err).ToNot(HaveOccurred())
+			Expect(disk.Name()).To(Equal("logs"))
+
+			diskRecords, err := diskRepo.FindCurrent()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskRecords).To(HaveLen(2))
+			Expect(diskRecords[0].CID).To(Equal("fake-disk-cid-1"))
+			Expect(diskRecords[1].CID).To(Equal("fake-disk-cid-2"))
+		})
+	})
+
 	Describe("FindUnused", func() {
 		var (
 			firstDisk bidisk.Disk
@@ -159,18 +228,18 @@ var _ = Describe("Manager", func() {
 
 		BeforeEach(func() {
 			fakeUUIDGenerator.GeneratedUUID = "fake-guid-1"
-			firstDiskRecord, err := diskRepo.Save("fake-disk-cid-1", 1024, biproperty.Map{})
+			firstDiskRecord, err := diskRepo.Save("", "fake-disk-cid-1", 1024, biproperty.Map{})
 			Expect(err).ToNot(HaveOccurred())
 			firstDisk = NewDisk(firstDiskRecord, fakeCloud, diskRepo)
 
 			fakeUUIDGenerator.GeneratedUUID = "fake-guid-2"
-			_, err = diskRepo.Save("fake-disk-cid-2", 1024, biproperty.Map{})
+			_, err = diskRepo.Save("", "fake-disk-cid-2", 1024, biproperty.Map{})
 			Expect(err).ToNot(HaveOccurred())
-			err = diskRepo.UpdateCurrent("fake-guid-2")
+			err = diskRepo.UpdateCurrent([]string{"fake-guid-2"})
 			Expect(err).ToNot(HaveOccurred())
 
 			fakeUUIDGenerator.GeneratedUUID = "fake-guid-3"
-			thirdDiskRecord, err := diskRepo.Save("fake-disk-cid-3", 1024, biproperty.Map{})
+			thirdDiskRecord, err := diskRepo.Save("", "fake-disk-cid-3", 1024, biproperty.Map{})
 			Expect(err).ToNot(HaveOccurred())
 			thirdDisk = NewDisk(thirdDiskRecord, fakeCloud, diskRepo)
 		})
@@ -184,6 +253,18 @@ var _ = Describe("Manager", func() {
 				thirdDisk,
 			}))
 		})
+
+		It("does not return any of the current disks", func() {
+			err := diskRepo.UpdateCurrent([]string{"fake-guid-2", "fake-guid-3"})
+			Expect(err).ToNot(HaveOccurred())
+
+			disks, err := manager.FindUnused()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(disks).To(Equal([]bidisk.Disk{
+				firstDisk,
+			}))
+		})
 	})
 
 	Describe("DeleteUnused", func() {
@@ -195,17 +276,17 @@ var _ = Describe("Manager", func() {
 			fakeStage = fakebiui.NewFakeStage()
 
 			fakeUUIDGenerator.GeneratedUUID = "fake-disk-id-1"
-			_, err := diskRepo.Save("fake-disk-cid-1", 100, nil)
+			_, err := diskRepo.Save("", "fake-disk-cid-1", 100, nil)
 			Expect(err).ToNot(HaveOccurred())
 
 			fakeUUIDGenerator.GeneratedUUID = "fake-disk-id-2"
-			secondDiskRecord, err = diskRepo.Save("fake-disk-cid-2", 100, nil)
+			secondDiskRecord, err = diskRepo.Save("", "fake-disk-cid-2", 100, nil)
 			Expect(err).ToNot(HaveOccurred())
-			err = diskRepo.UpdateCurrent(secondDiskRecord.ID)
+			err = diskRepo.UpdateCurrent([]string{secondDiskRecord.ID})
 			Expect(err).ToNot(HaveOccurred())
 
 			fakeUUIDGenerator.GeneratedUUID = "fake-disk-id-3"
-			_, err = diskRepo.Save("fake-disk-cid-3", 100, nil)
+			_, err = diskRepo.Save("", "fake-disk-cid-3", 100, nil)
 			Expect(err).ToNot(HaveOccurred())
 		})
 
@@ -223,10 +304,9 @@ var _ = Describe("Manager", func() {
 				{Name: "Deleting unused disk 'fake-disk-cid-3'"},
 			}))
 
-			currentRecord, found, err := diskRepo.FindCurrent()
+			currentRecords, err := diskRepo.FindCurrent()
 			Expect(err).ToNot(HaveOccurred())
-			Expect(found).To(BeTrue())
-			Expect(currentRecord).To(Equal(secondDiskRecord))
+			Expect(currentRecords).To(Equal([]biconfig.DiskRecord{secondDiskRecord}))
 
 			records, err := diskRepo.All()
 			Expect(err).ToNot(HaveOccurred())
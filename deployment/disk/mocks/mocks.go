@@ -65,6 +65,20 @@ func (mr *MockDiskMockRecorder) Delete() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDisk)(nil).Delete))
 }
 
+// Name mocks base method.
+func (m *MockDisk) Name() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Name")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// Name indicates an expected call of Name.
+func (mr *MockDiskMockRecorder) Name() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Name", reflect.TypeOf((*MockDisk)(nil).Name))
+}
+
 // NeedsMigration mocks base method.
 func (m *MockDisk) NeedsMigration(arg0 int, arg1 property.Map) (bool, error) {
 	m.ctrl.T.Helper()
@@ -103,19 +117,34 @@ func (m *MockManager) EXPECT() *MockManagerMockRecorder {
 	return m.recorder
 }
 
+// Adopt mocks base method.
+func (m *MockManager) Adopt(arg0, arg1 string, arg2 int, arg3 property.Map) (disk.Disk, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Adopt", arg0, arg1, arg2, arg3)
+	ret0, _ := ret[0].(disk.Disk)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Adopt indicates an expected call of Adopt.
+func (mr *MockManagerMockRecorder) Adopt(arg0, arg1, arg2, arg3 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Adopt", reflect.TypeOf((*MockManager)(nil).Adopt), arg0, arg1, arg2, arg3)
+}
+
 // Create mocks base method.
-func (m *MockManager) Create(arg0 manifest.DiskPool, arg1 string) (disk.Disk, error) {
+func (m *MockManager) Create(arg0 string, arg1 manifest.DiskPool, arg2 string) (disk.Disk, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Create", arg0, arg1)
+	ret := m.ctrl.Call(m, "Create", arg0, arg1, arg2)
 	ret0, _ := ret[0].(disk.Disk)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Create indicates an expected call of Create.
-func (mr *MockManagerMockRecorder) Create(arg0, arg1 interface{}) *gomock.Call {
+func (mr *MockManagerMockRecorder) Create(arg0, arg1, arg2 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockManager)(nil).Create), arg0, arg1)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Create", reflect.TypeOf((*MockManager)(nil).Create), arg0, arg1, arg2)
 }
 
 // DeleteUnused mocks base method.
@@ -0,0 +1,155 @@
+package dryrun
+
+import (
+	"sync"
+
+	"github.com/cloudfoundry/bosh-agent/agentclient"
+	"github.com/cloudfoundry/bosh-agent/agentclient/applyspec"
+	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
+)
+
+// agentClient answers like an agent whose jobs are running
+// and records every message that would change the VM
+type agentClient struct {
+	recorder *Recorder
+
+	mountedDisks []string
+	mutex        sync.Mutex
+}
+
+func NewAgentClient(recorder *Recorder) agentclient.AgentClient {
+	return &agentClient{recorder: recorder}
+}
+
+func (c *agentClient) Ping() (string, error) {
+	return "pong", nil
+}
+
+func (c *agentClient) Stop() error {
+	c.recorder.Record(ComponentAgent, "stop", "")
+	return nil
+}
+
+func (c *agentClient) Drain(drainType string) (int64, error) {
+	c.recorder.Record(ComponentAgent, "drain", drainType)
+	return 0, nil
+}
+
+func (c *agentClient) Apply(spec applyspec.ApplySpec) error {
+	c.recorder.Record(ComponentAgent, "apply", spec.Job.Name)
+	return nil
+}
+
+func (c *agentClient) Start() error {
+	c.recorder.Record(ComponentAgent, "start", "")
+	return nil
+}
+
+func (c *agentClient) GetState() (agentclient.AgentState, error) {
+	return agentclient.AgentState{JobState: "running"}, nil
+}
+
+func (c *agentClient) AddPersistentDisk(diskCID string, diskHints interface{}) error {
+	c.recorder.Record(ComponentAgent, "add_persistent_disk", diskCID)
+	return nil
+}
+
+func (c *agentClient) RemovePersistentDisk(diskCID string) error {
+	c.recorder.Record(ComponentAgent, "remove_persistent_disk", diskCID)
+	return nil
+}
+
+func (c *agentClient) MountDisk(diskCID string) error {
+	c.recorder.Record(ComponentAgent, "mount_disk", diskCID)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.mountedDisks = append(c.mountedDisks, diskCID)
+
+	return nil
+}
+
+func (c *agentClient) UnmountDisk(diskCID string) error {
+	c.recorder.Record(ComponentAgent, "unmount_disk", diskCID)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	for i, mountedDisk := range c.mountedDisks {
+		if mountedDisk == diskCID {
+			c.mountedDisks = append(c.mountedDisks[:i], c.mountedDisks[i+1:]...)
+			break
+		}
+	}
+
+	return nil
+}
+
+// ListDisk only knows the disks mounted during the dry run
+func (c *agentClient) ListDisk() ([]string, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return append([]string{}, c.mountedDisks...), nil
+}
+
+func (c *agentClient) MigrateDisk() error {
+	c.recorder.Record(ComponentAgent, "migrate_disk", "")
+	return nil
+}
+
+func (c *agentClient) CompilePackage(packageSource agentclient.BlobRef, compiledPackageDependencies []agentclient.BlobRef) (agentclient.BlobRef, error) {
+	c.recorder.Record(ComponentAgent, "compile_package", packageSource.Name)
+
+	return agentclient.BlobRef{
+		Name:        packageSource.Name,
+		Version:     packageSource.Version,
+		BlobstoreID: "dry-run-compiled-" + packageSource.Name,
+		SHA1:        packageSource.SHA1,
+	}, nil
+}
+
+func (c *agentClient) DeleteARPEntries(ips []string) error {
+	c.recorder.Record(ComponentAgent, "delete_arp_entries", "")
+	return nil
+}
+
+func (c *agentClient) SyncDNS(blobID, sha1 string, version uint64) (string, error) {
+	c.recorder.Record(ComponentAgent, "sync_dns", "")
+	return "synced", nil
+}
+
+func (c *agentClient) RunScript(scriptName string, options map[string]interface{}) error {
+	c.recorder.Record(ComponentAgent, "run_script", scriptName)
+	return nil
+}
+
+func (c *agentClient) SetUpSSH(username string, publicKey string) (agentclient.SSHResult, error) {
+	return agentclient.SSHResult{}, errRefused("setting up SSH")
+}
+
+func (c *agentClient) CleanUpSSH(username string) (agentclient.SSHResult, error) {
+	return agentclient.SSHResult{}, errRefused("cleaning up SSH")
+}
+
+func (c *agentClient) BundleLogs(owningUser string, logType string, filters []string) (agentclient.BundleLogsResult, error) {
+	return agentclient.BundleLogsResult{}, errRefused("bundling logs")
+}
+
+func (c *agentClient) RemoveFile(path string) error {
+	c.recorder.Record(ComponentAgent, "remove_file", path)
+	return nil
+}
+
+type agentClientFactory struct {
+	recorder *Recorder
+}
+
+func NewAgentClientFactory(recorder *Recorder) bihttpagent.AgentClientFactory {
+	return agentClientFactory{recorder: recorder}
+}
+
+func (f agentClientFactory) NewAgentClient(directorID, mbusURL, caCert string) (agentclient.AgentClient, error) {
+	return NewAgentClient(f.recorder), nil
+}
@@ -0,0 +1,50 @@
+package dryrun_test
+
+import (
+	"github.com/cloudfoundry/bosh-agent/agentclient"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/deployment/dryrun"
+)
+
+var _ = Describe("AgentClient", func() {
+	var (
+		recorder    *Recorder
+		agentClient agentclient.AgentClient
+	)
+
+	BeforeEach(func() {
+		recorder = NewRecorder()
+		agentClient = NewAgentClient(recorder)
+	})
+
+	It("answers like a running agent without recording", func() {
+		Expect(agentClient.Ping()).To(Equal("pong"))
+
+		state, err := agentClient.GetState()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(state.JobState).To(Equal("running"))
+
+		Expect(recorder.Calls()).To(BeEmpty())
+	})
+
+	It("lists the disks it pretended to mount", func() {
+		Expect(agentClient.MountDisk("fake-disk-cid-1")).To(Succeed())
+		Expect(agentClient.MountDisk("fake-disk-cid-2")).To(Succeed())
+		Expect(agentClient.UnmountDisk("fake-disk-cid-1")).To(Succeed())
+
+		Expect(agentClient.ListDisk()).To(Equal([]string{"fake-disk-cid-2"}))
+
+		Expect(recorder.Calls()).To(Equal([]Call{
+			{Component: ComponentAgent, Method: "mount_disk", Subject: "fake-disk-cid-1"},
+			{Component: ComponentAgent, Method: "mount_disk", Subject: "fake-disk-cid-2"},
+			{Component: ComponentAgent, Method: "unmount_disk", Subject: "fake-disk-cid-1"},
+		}))
+	})
+
+	It("refuses to open SSH sessions", func() {
+		_, err := agentClient.SetUpSSH("fake-user", "fake-public-key")
+		Expect(err).To(MatchError("Refusing setting up SSH in a dry run"))
+	})
+})
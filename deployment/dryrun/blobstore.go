@@ -0,0 +1,49 @@
+package dryrun
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sync"
+
+	biblobstore "github.com/cloudfoundry/bosh-cli/v7/blobstore"
+)
+
+// blobstore records the blobs a real run would upload to the agent
+type blobstore struct {
+	recorder *Recorder
+
+	lastID int
+	mutex  sync.Mutex
+}
+
+func NewBlobstore(recorder *Recorder) biblobstore.Blobstore {
+	return &blobstore{recorder: recorder}
+}
+
+func (b *blobstore) Get(blobID string) (biblobstore.LocalBlob, error) {
+	return nil, errRefused("downloading blobs")
+}
+
+func (b *blobstore) Add(sourcePath string) (string, error) {
+	b.recorder.Record(ComponentBlobstore, "add", filepath.Base(sourcePath))
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.lastID++
+
+	return fmt.Sprintf("dry-run-blob-%d", b.lastID), nil
+}
+
+type blobstoreFactory struct {
+	recorder *Recorder
+}
+
+func NewBlobstoreFactory(recorder *Recorder) biblobstore.Factory {
+	return blobstoreFactory{recorder: recorder}
+}
+
+func (f blobstoreFactory) Create(blobstoreURL string, httpClient *http.Client) (biblobstore.Blobstore, error) {
+	return NewBlobstore(f.recorder), nil
+}
@@ -0,0 +1,147 @@
+package dryrun
+
+import (
+	"fmt"
+	"sync"
+
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	biinstall "github.com/cloudfoundry/bosh-cli/v7/installation"
+)
+
+type cloud struct {
+	cloud    bicloud.Cloud
+	recorder *Recorder
+
+	created map[string]bool
+	lastID  int
+	mutex   sync.Mutex
+}
+
+// NewCloud delegates the CPI methods which inspect the IaaS and records every other call.
+// Resources it pretends to create get placeholder CIDs and are reported to exist.
+func NewCloud(delegate bicloud.Cloud, recorder *Recorder) bicloud.Cloud {
+	return &cloud{
+		cloud:    delegate,
+		recorder: recorder,
+		created:  map[string]bool{},
+	}
+}
+
+func (c *cloud) CreateStemcell(imagePath string, cloudProperties biproperty.Map) (string, error) {
+	return c.create("create_stemcell", "stemcell"), nil
+}
+
+func (c *cloud) DeleteStemcell(stemcellCID string) error {
+	c.recorder.Record(ComponentCPI, "delete_stemcell", stemcellCID)
+	return nil
+}
+
+func (c *cloud) HasVM(vmCID string) (bool, error) {
+	if c.isCreated(vmCID) {
+		return true, nil
+	}
+	return c.cloud.HasVM(vmCID)
+}
+
+func (c *cloud) HasDisk(diskCID string) (bool, error) {
+	if c.isCreated(diskCID) {
+		return true, nil
+	}
+	return c.cloud.HasDisk(diskCID)
+}
+
+func (c *cloud) CreateVM(
+	agentID string,
+	stemcellCID string,
+	cloudProperties biproperty.Map,
+	networksInterfaces map[string]biproperty.Map,
+	env biproperty.Map,
+) (string, error) {
+	return c.create("create_vm", "vm"), nil
+}
+
+func (c *cloud) SetVMMetadata(vmCID string, metadata bicloud.VMMetadata) error {
+	c.recorder.Record(ComponentCPI, "set_vm_metadata", vmCID)
+	return nil
+}
+
+func (c *cloud) SetDiskMetadata(diskCID string, metadata bicloud.DiskMetadata) error {
+	c.recorder.Record(ComponentCPI, "set_disk_metadata", diskCID)
+	return nil
+}
+
+func (c *cloud) DeleteVM(vmCID string) error {
+	c.recorder.Record(ComponentCPI, "delete_vm", vmCID)
+	return nil
+}
+
+func (c *cloud) CreateDisk(size int, cloudProperties biproperty.Map, vmCID string) (string, error) {
+	return c.create("create_disk", "disk"), nil
+}
+
+func (c *cloud) AttachDisk(vmCID, diskCID string) (interface{}, error) {
+	c.recorder.Record(ComponentCPI, "attach_disk", diskCID)
+	return nil, nil
+}
+
+func (c *cloud) DetachDisk(vmCID, diskCID string) error {
+	c.recorder.Record(ComponentCPI, "detach_disk", diskCID)
+	return nil
+}
+
+func (c *cloud) DeleteDisk(diskCID string) error {
+	c.recorder.Record(ComponentCPI, "delete_disk", diskCID)
+	return nil
+}
+
+func (c *cloud) Info() (bicloud.CpiInfo, error) {
+	return c.cloud.Info()
+}
+
+func (c *cloud) String() string {
+	return c.cloud.String()
+}
+
+func (c *cloud) create(method, kind string) string {
+	c.mutex.Lock()
+	c.lastID++
+	cid := fmt.Sprintf("dry-run-%s-%d", kind, c.lastID)
+	c.created[cid] = true
+	c.mutex.Unlock()
+
+	c.recorder.Record(ComponentCPI, method, cid)
+
+	return cid
+}
+
+func (c *cloud) isCreated(cid string) bool {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	return c.created[cid]
+}
+
+type cloudFactory struct {
+	factory  bicloud.Factory
+	recorder *Recorder
+}
+
+func NewCloudFactory(factory bicloud.Factory, recorder *Recorder) bicloud.Factory {
+	return cloudFactory{factory: factory, recorder: recorder}
+}
+
+func (f cloudFactory) NewCloud(installation biinstall.Installation, directorID string, stemcellApiVersion int) (bicloud.Cloud, error) {
+	delegate, err := f.factory.NewCloud(installation, directorID, stemcellApiVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return NewCloud(delegate, f.recorder), nil
+}
+
+// NewCPICmdRunner is refused since a CPI command runner can make any call
+func (f cloudFactory) NewCPICmdRunner(installation biinstall.Installation) (bicloud.CPICmdRunner, error) {
+	return nil, errRefused("running CPI commands")
+}
@@ -0,0 +1,70 @@
+package dryrun_test
+
+import (
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	mockcloud "github.com/cloudfoundry/bosh-cli/v7/cloud/mocks"
+	. "github.com/cloudfoundry/bosh-cli/v7/deployment/dryrun"
+)
+
+var _ = Describe("Cloud", func() {
+	var (
+		mockCtrl      *gomock.Controller
+		delegateCloud *mockcloud.MockCloud
+		recorder      *Recorder
+		cloud         bicloud.Cloud
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		delegateCloud = mockcloud.NewMockCloud(mockCtrl)
+		recorder = NewRecorder()
+		cloud = NewCloud(delegateCloud, recorder)
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("asks the CPI whether existing VMs and disks exist", func() {
+		delegateCloud.EXPECT().HasVM("fake-vm-cid").Return(false, nil)
+		delegateCloud.EXPECT().HasDisk("fake-disk-cid").Return(true, nil)
+
+		Expect(cloud.HasVM("fake-vm-cid")).To(BeFalse())
+		Expect(cloud.HasDisk("fake-disk-cid")).To(BeTrue())
+		Expect(recorder.Calls()).To(BeEmpty())
+	})
+
+	It("records creating resources and reports them to exist without asking the CPI", func() {
+		vmCID, err := cloud.CreateVM("fake-agent-id", "fake-stemcell-cid", nil, nil, nil)
+		Expect(err).ToNot(HaveOccurred())
+
+		diskCID, err := cloud.CreateDisk(1024, nil, vmCID)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cloud.HasVM(vmCID)).To(BeTrue())
+		Expect(cloud.HasDisk(diskCID)).To(BeTrue())
+
+		Expect(recorder.Calls()).To(Equal([]Call{
+			{Component: ComponentCPI, Method: "create_vm", Subject: "dry-run-vm-1"},
+			{Component: ComponentCPI, Method: "create_disk", Subject: "dry-run-disk-2"},
+		}))
+	})
+
+	It("records changes of existing resources without making them", func() {
+		Expect(cloud.DeleteVM("fake-vm-cid")).To(Succeed())
+		Expect(cloud.DetachDisk("fake-vm-cid", "fake-disk-cid")).To(Succeed())
+		Expect(cloud.DeleteDisk("fake-disk-cid")).To(Succeed())
+		Expect(cloud.DeleteStemcell("fake-stemcell-cid")).To(Succeed())
+
+		Expect(recorder.Calls()).To(Equal([]Call{
+			{Component: ComponentCPI, Method: "delete_vm", Subject: "fake-vm-cid"},
+			{Component: ComponentCPI, Method: "detach_disk", Subject: "fake-disk-cid"},
+			{Component: ComponentCPI, Method: "delete_disk", Subject: "fake-disk-cid"},
+			{Component: ComponentCPI, Method: "delete_stemcell", Subject: "fake-stemcell-cid"},
+		}))
+	})
+})
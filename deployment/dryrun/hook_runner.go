@@ -0,0 +1,33 @@
+package dryrun
+
+import (
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
+	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+// hookRunner records the hooks a real run would run without running them
+type hookRunner struct {
+	source   bihook.Source
+	recorder *Recorder
+}
+
+func NewHookRunner(source bihook.Source, recorder *Recorder) bihook.Runner {
+	return hookRunner{source: source, recorder: recorder}
+}
+
+func (r hookRunner) Run(point bihook.Point, context func() bihook.Context, stage biui.Stage) error {
+	hooks, err := r.source.Hooks()
+	if err != nil {
+		return bosherr.WrapError(err, "Finding hooks")
+	}
+
+	for _, hook := range hooks {
+		if hook.Point == point {
+			r.recorder.Record(ComponentHook, string(point), hook.Path)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,22 @@
+package dryrun
+
+import (
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+)
+
+// keptDisksRepo reads the disks kept by delete-env but never changes the file
+type keptDisksRepo struct {
+	biconfig.KeptDisksRepo
+}
+
+func NewKeptDisksRepo(repo biconfig.KeptDisksRepo) biconfig.KeptDisksRepo {
+	return keptDisksRepo{KeptDisksRepo: repo}
+}
+
+func (r keptDisksRepo) Add(disk biconfig.KeptDisk) error {
+	return nil
+}
+
+func (r keptDisksRepo) Remove(cid string) error {
+	return nil
+}
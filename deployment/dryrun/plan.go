@@ -0,0 +1,174 @@
+package dryrun
+
+import (
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+)
+
+const (
+	ActionKeep     = "keep"
+	ActionUpload   = "upload"
+	ActionCreate   = "create"
+	ActionRecreate = "recreate"
+	ActionMigrate  = "migrate"
+	ActionAdopt    = "adopt"
+	ActionInstall  = "install"
+	ActionDelete   = "delete"
+)
+
+// Change summarizes what a run would do to a stemcell, VM, disk or release
+type Change struct {
+	Resource string
+	Name     string
+	Action   string
+	Reason   string
+}
+
+type Plan struct {
+	Changes []Change
+	Calls   []Call
+}
+
+func (p Plan) HasChanges() bool {
+	for _, change := range p.Changes {
+		if change.Action != ActionKeep {
+			return true
+		}
+	}
+	return false
+}
+
+// NewPlan summarizes the calls a dry run skipped. Resources of the deployment state
+// that none of the calls are about are kept.
+func NewPlan(calls []Call, deploymentState biconfig.DeploymentState, stemcell string, releases []string) Plan {
+	stemcellChange := Change{Resource: "stemcell", Name: stemcell, Action: ActionKeep, Reason: "Stemcell is already uploaded"}
+	vmChange := Change{Resource: "vm", Name: deploymentState.CurrentVMCID, Action: ActionKeep, Reason: "No deployment, stemcell or release changes"}
+
+	var (
+		diskChanges     []Change
+		unusedStemcells []Change
+
+		deletedVM   string
+		jobsApplied bool
+
+		createdDisk   = -1
+		migratingDisk = -1
+		createdDisks  = map[string]bool{}
+		migratedDisks = map[string]bool{}
+		touchedDisks  = map[string]bool{}
+		attachedDisks = map[string]bool{}
+		adoptedDisks  []string
+	)
+
+	currentDisks := map[string]bool{}
+	for _, diskCID := range currentDiskCIDs(deploymentState) {
+		currentDisks[diskCID] = true
+	}
+
+	for _, call := range calls {
+		switch call.Component + " " + call.Method {
+		case "cpi create_stemcell":
+			stemcellChange.Action = ActionUpload
+			stemcellChange.Reason = "Stemcell is not uploaded yet"
+
+		case "cpi delete_stemcell":
+			unusedStemcells = append(unusedStemcells, Change{Resource: "stemcell", Name: call.Subject, Action: ActionDelete, Reason: "Stemcell is no longer used"})
+
+		case "cpi delete_vm":
+			deletedVM = call.Subject
+
+		case "cpi create_vm":
+			if deletedVM != "" {
+				vmChange = Change{Resource: "vm", Name: deletedVM, Action: ActionRecreate, Reason: "VM is replaced with a new VM"}
+			} else {
+				vmChange = Change{Resource: "vm", Action: ActionCreate, Reason: "No VM is deployed"}
+			}
+
+		case "cpi create_disk":
+			createdDisk = len(diskChanges)
+			createdDisks[call.Subject] = true
+			diskChanges = append(diskChanges, Change{Resource: "disk", Name: call.Subject, Action: ActionCreate, Reason: "Persistent disk is requested"})
+
+		case "agent migrate_disk":
+			if createdDisk >= 0 {
+				migratingDisk = createdDisk
+				diskChanges[migratingDisk].Action = ActionMigrate
+				diskChanges[migratingDisk].Reason = "Disk contents are migrated to a new disk"
+			}
+
+		case "cpi attach_disk":
+			if !currentDisks[call.Subject] && !createdDisks[call.Subject] && !attachedDisks[call.Subject] {
+				adoptedDisks = append(adoptedDisks, call.Subject)
+			}
+			attachedDisks[call.Subject] = true
+
+		case "cpi detach_disk":
+			touchedDisks[call.Subject] = true
+
+			if migratingDisk >= 0 {
+				diskChanges[migratingDisk].Name = call.Subject
+				migratedDisks[call.Subject] = true
+				migratingDisk = -1
+			}
+
+		case "cpi delete_disk":
+			touchedDisks[call.Subject] = true
+
+			if !migratedDisks[call.Subject] {
+				diskChanges = append(diskChanges, Change{Resource: "disk", Name: call.Subject, Action: ActionDelete, Reason: "Persistent disk is no longer requested"})
+			}
+
+		case "agent apply":
+			jobsApplied = true
+		}
+	}
+
+	changes := []Change{stemcellChange, vmChange}
+
+	for _, diskCID := range currentDiskCIDs(deploymentState) {
+		if touchedDisks[diskCID] {
+			continue
+		}
+
+		change := Change{Resource: "disk", Name: diskCID, Action: ActionKeep, Reason: "Disk size and cloud properties are unchanged"}
+		if attachedDisks[diskCID] {
+			change.Reason = "Disk is attached to the new VM"
+		}
+
+		changes = append(changes, change)
+	}
+
+	for _, diskCID := range adoptedDisks {
+		changes = append(changes, Change{Resource: "disk", Name: diskCID, Action: ActionAdopt, Reason: "Disk is adopted with --adopt-disk"})
+	}
+
+	changes = append(changes, diskChanges...)
+
+	for _, release := range releases {
+		change := Change{Resource: "release", Name: release, Action: ActionKeep, Reason: "Release is already deployed"}
+		if jobsApplied {
+			change.Action = ActionInstall
+			change.Reason = "Jobs are applied to the new VM"
+		}
+
+		changes = append(changes, change)
+	}
+
+	return Plan{
+		Changes: append(changes, unusedStemcells...),
+		Calls:   calls,
+	}
+}
+
+func currentDiskCIDs(deploymentState biconfig.DeploymentState) []string {
+	cids := []string{}
+
+	for _, diskID := range deploymentState.CurrentDiskIDs {
+		for _, disk := range deploymentState.Disks {
+			if disk.ID == diskID {
+				cids = append(cids, disk.CID)
+			}
+		}
+	}
+
+	return cids
+}
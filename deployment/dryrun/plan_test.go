@@ -0,0 +1,107 @@
+package dryrun_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	. "github.com/cloudfoundry/bosh-cli/v7/deployment/dryrun"
+)
+
+var _ = Describe("NewPlan", func() {
+	var (
+		deploymentState biconfig.DeploymentState
+		releases        []string
+	)
+
+	BeforeEach(func() {
+		deploymentState = biconfig.DeploymentState{
+			CurrentVMCID:   "fake-vm-cid",
+			CurrentDiskIDs: []string{"fake-disk-id"},
+			Disks:          []biconfig.DiskRecord{{ID: "fake-disk-id", CID: "fake-disk-cid"}},
+		}
+		releases = []string{"fake-release/1"}
+	})
+
+	It("keeps everything when no calls were recorded", func() {
+		plan := NewPlan(nil, deploymentState, "fake-stemcell/1", releases)
+
+		Expect(plan.HasChanges()).To(BeFalse())
+		Expect(plan.Changes).To(Equal([]Change{
+			{Resource: "stemcell", Name: "fake-stemcell/1", Action: "keep", Reason: "Stemcell is already uploaded"},
+			{Resource: "vm", Name: "fake-vm-cid", Action: "keep", Reason: "No deployment, stemcell or release changes"},
+			{Resource: "disk", Name: "fake-disk-cid", Action: "keep", Reason: "Disk size and cloud properties are unchanged"},
+			{Resource: "release", Name: "fake-release/1", Action: "keep", Reason: "Release is already deployed"},
+		}))
+	})
+
+	It("plans a new environment from the create calls", func() {
+		calls := []Call{
+			{Component: ComponentCPI, Method: "create_stemcell", Subject: "dry-run-stemcell-1"},
+			{Component: ComponentCPI, Method: "create_vm", Subject: "dry-run-vm-2"},
+			{Component: ComponentCPI, Method: "create_disk", Subject: "dry-run-disk-3"},
+			{Component: ComponentCPI, Method: "attach_disk", Subject: "dry-run-disk-3"},
+			{Component: ComponentAgent, Method: "apply", Subject: "fake-job"},
+		}
+
+		plan := NewPlan(calls, biconfig.DeploymentState{}, "fake-stemcell/1", releases)
+
+		Expect(plan.HasChanges()).To(BeTrue())
+		Expect(plan.Calls).To(Equal(calls))
+		Expect(plan.Changes).To(Equal([]Change{
+			{Resource: "stemcell", Name: "fake-stemcell/1", Action: "upload", Reason: "Stemcell is not uploaded yet"},
+			{Resource: "vm", Name: "", Action: "create", Reason: "No VM is deployed"},
+			{Resource: "disk", Name: "dry-run-disk-3", Action: "create", Reason: "Persistent disk is requested"},
+			{Resource: "release", Name: "fake-release/1", Action: "install", Reason: "Jobs are applied to the new VM"},
+		}))
+	})
+
+	It("plans a disk migration without deleting the migrated disk separately", func() {
+		calls := []Call{
+			{Component: ComponentCPI, Method: "delete_vm", Subject: "fake-vm-cid"},
+			{Component: ComponentCPI, Method: "create_vm", Subject: "dry-run-vm-1"},
+			{Component: ComponentCPI, Method: "attach_disk", Subject: "fake-disk-cid"},
+			{Component: ComponentCPI, Method: "create_disk", Subject: "dry-run-disk-2"},
+			{Component: ComponentCPI, Method: "attach_disk", Subject: "dry-run-disk-2"},
+			{Component: ComponentAgent, Method: "migrate_disk"},
+			{Component: ComponentCPI, Method: "detach_disk", Subject: "fake-disk-cid"},
+			{Component: ComponentCPI, Method: "delete_disk", Subject: "fake-disk-cid"},
+			{Component: ComponentCPI, Method: "delete_stemcell", Subject: "fake-old-stemcell-cid"},
+		}
+
+		plan := NewPlan(calls, deploymentState, "fake-stemcell/1", nil)
+
+		Expect(plan.Changes).To(Equal([]Change{
+			{Resource: "stemcell", Name: "fake-stemcell/1", Action: "keep", Reason: "Stemcell is already uploaded"},
+			{Resource: "vm", Name: "fake-vm-cid", Action: "recreate", Reason: "VM is replaced with a new VM"},
+			{Resource: "disk", Name: "fake-disk-cid", Action: "migrate", Reason: "Disk contents are migrated to a new disk"},
+			{Resource: "stemcell", Name: "fake-old-stemcell-cid", Action: "delete", Reason: "Stemcell is no longer used"},
+		}))
+	})
+
+	It("plans attaching current and adopted disks to a recreated VM", func() {
+		calls := []Call{
+			{Component: ComponentCPI, Method: "delete_vm", Subject: "fake-vm-cid"},
+			{Component: ComponentCPI, Method: "create_vm", Subject: "dry-run-vm-1"},
+			{Component: ComponentCPI, Method: "attach_disk", Subject: "fake-disk-cid"},
+			{Component: ComponentCPI, Method: "attach_disk", Subject: "fake-adopted-disk-cid"},
+		}
+
+		plan := NewPlan(calls, deploymentState, "fake-stemcell/1", nil)
+
+		Expect(plan.Changes).To(ContainElement(Change{Resource: "disk", Name: "fake-disk-cid", Action: "keep", Reason: "Disk is attached to the new VM"}))
+		Expect(plan.Changes).To(ContainElement(Change{Resource: "disk", Name: "fake-adopted-disk-cid", Action: "adopt", Reason: "Disk is adopted with --adopt-disk"}))
+	})
+
+	It("plans deleting disks that are no longer requested", func() {
+		calls := []Call{
+			{Component: ComponentCPI, Method: "detach_disk", Subject: "fake-disk-cid"},
+			{Component: ComponentCPI, Method: "delete_disk", Subject: "fake-disk-cid"},
+		}
+
+		plan := NewPlan(calls, deploymentState, "fake-stemcell/1", nil)
+
+		Expect(plan.Changes).To(ContainElement(Change{Resource: "disk", Name: "fake-disk-cid", Action: "delete", Reason: "Persistent disk is no longer requested"}))
+		Expect(plan.Changes).ToNot(ContainElement(And(HaveField("Resource", "disk"), HaveField("Action", "keep"))))
+	})
+})
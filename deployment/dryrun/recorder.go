@@ -0,0 +1,51 @@
+package dryrun
+
+import (
+	"sync"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+const (
+	ComponentCPI       = "cpi"
+	ComponentAgent     = "agent"
+	ComponentBlobstore = "blobstore"
+	ComponentHook      = "hook"
+)
+
+// Call is a CPI, agent, blobstore or hook call that a dry run did not make
+type Call struct {
+	Component string
+	Method    string
+
+	// Subject is what the call is about, e.g. the CID of a VM or disk
+	Subject string
+}
+
+// Recorder keeps the calls a dry run skipped in the order a real run would make them
+type Recorder struct {
+	calls []Call
+	mutex sync.Mutex
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{}
+}
+
+func (r *Recorder) Record(component, method, subject string) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.calls = append(r.calls, Call{Component: component, Method: method, Subject: subject})
+}
+
+func (r *Recorder) Calls() []Call {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return append([]Call{}, r.calls...)
+}
+
+func errRefused(action string) error {
+	return bosherr.Errorf("Refusing %s in a dry run", action)
+}
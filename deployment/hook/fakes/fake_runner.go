@@ -0,0 +1,28 @@
+package fakes
+
+import (
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
+	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+type RunInput struct {
+	Point   bihook.Point
+	Context bihook.Context
+	Stage   biui.Stage
+}
+
+type FakeRunner struct {
+	RunInputs []RunInput
+	RunErrs   map[bihook.Point]error
+}
+
+func NewFakeRunner() *FakeRunner {
+	return &FakeRunner{RunErrs: map[bihook.Point]error{}}
+}
+
+// Run builds the context like a hook runs at every point
+func (r *FakeRunner) Run(point bihook.Point, context func() bihook.Context, stage biui.Stage) error {
+	r.RunInputs = append(r.RunInputs, RunInput{Point: point, Context: context(), Stage: stage})
+
+	return r.RunErrs[point]
+}
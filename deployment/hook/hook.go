@@ -0,0 +1,82 @@
+package hook
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/cloudfoundry/bosh-agent/agentclient"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// Point is a well-defined point of create-env and delete-env at which hooks run
+type Point string
+
+const (
+	PreVMDelete    Point = "pre-vm-delete"
+	PostVMCreate   Point = "post-vm-create"
+	PostAgentReady Point = "post-agent-ready"
+	PostDeploy     Point = "post-deploy"
+)
+
+var Points = []Point{PreVMDelete, PostVMCreate, PostAgentReady, PostDeploy}
+
+func ParsePoint(name string) (Point, error) {
+	for _, point := range Points {
+		if string(point) == name {
+			return point, nil
+		}
+	}
+
+	names := []string{}
+	for _, point := range Points {
+		names = append(names, string(point))
+	}
+
+	return "", bosherr.Errorf("Expected hook point '%s' to be one of '%s'", name, strings.Join(names, "', '"))
+}
+
+// Hook is an executable that runs at a point
+type Hook struct {
+	Point Point
+	Path  string
+}
+
+// Context is given to hooks as JSON on stdin
+type Context struct {
+	Hook        Point    `json:"hook"`
+	VMCID       string   `json:"vm_cid"`
+	IPs         []string `json:"ips"`
+	StemcellCID string   `json:"stemcell_cid,omitempty"`
+	DiskCIDs    []string `json:"disk_cids"`
+}
+
+// Source returns the hooks to run; hooks of the installation manifest
+// are only known once the manifest is parsed
+type Source interface {
+	Hooks() ([]Hook, error)
+}
+
+// StaticSource returns the same hooks every time, e.g. the hooks given with flags
+type StaticSource []Hook
+
+func (s StaticSource) Hooks() ([]Hook, error) {
+	return s, nil
+}
+
+// AgentIPs returns the IPs the agent reports for its networks, ordered by network name
+func AgentIPs(state agentclient.AgentState) []string {
+	names := []string{}
+	for name := range state.NetworkSpecs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	ips := []string{}
+	for _, name := range names {
+		if ip := state.NetworkSpecs[name].IP; ip != "" {
+			ips = append(ips, ip)
+		}
+	}
+
+	return ips
+}
@@ -0,0 +1,104 @@
+package hook
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+type Runner interface {
+	// Run runs the hooks of the point in order; the first hook that
+	// exits non-zero fails its stage step and stops the stage.
+	// The context is only built when a hook runs at the point
+	// so that no agent is asked for it otherwise.
+	Run(point Point, context func() Context, stage biui.Stage) error
+}
+
+type runner struct {
+	source    Source
+	cmdRunner boshsys.CmdRunner
+	logger    boshlog.Logger
+	logTag    string
+}
+
+func NewRunner(source Source, cmdRunner boshsys.CmdRunner, logger boshlog.Logger) Runner {
+	return runner{
+		source:    source,
+		cmdRunner: cmdRunner,
+		logger:    logger,
+		logTag:    "hookRunner",
+	}
+}
+
+func (r runner) Run(point Point, contextFunc func() Context, stage biui.Stage) error {
+	allHooks, err := r.source.Hooks()
+	if err != nil {
+		return bosherr.WrapError(err, "Finding hooks")
+	}
+
+	hooks := []Hook{}
+	for _, hook := range allHooks {
+		if hook.Point == point {
+			hooks = append(hooks, hook)
+		}
+	}
+
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	context := contextFunc()
+	context.Hook = point
+
+	if context.IPs == nil {
+		context.IPs = []string{}
+	}
+
+	if context.DiskCIDs == nil {
+		context.DiskCIDs = []string{}
+	}
+
+	contextJSON, err := json.Marshal(context)
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling hook context")
+	}
+
+	for _, hook := range hooks {
+		stepName := fmt.Sprintf("Running %s hook '%s'", point, hook.Path)
+		err = stage.Perform(stepName, func() error {
+			return r.runHook(hook, contextJSON)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r runner) runHook(hook Hook, contextJSON []byte) error {
+	stdout, stderr, exitStatus, err := r.cmdRunner.RunComplexCommand(boshsys.Command{
+		Name:  hook.Path,
+		Stdin: bytes.NewReader(contextJSON),
+		Quiet: true,
+	})
+
+	r.logger.Debug(r.logTag, "Hook '%s' exited with %d, stdout: '%s', stderr: '%s'", hook.Path, exitStatus, stdout, stderr)
+
+	if exitStatus > 0 {
+		return bosherr.Errorf("Hook '%s' exited with %d: %s", hook.Path, exitStatus, strings.TrimSpace(stderr))
+	}
+
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Running hook '%s'", hook.Path)
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package hook_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/cloudfoundry/bosh-agent/agentclient"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
+	fakebiui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("Runner", func() {
+	var (
+		cmdRunner *fakesys.FakeCmdRunner
+		stage     *fakebiui.FakeStage
+		runner    bihook.Runner
+	)
+
+	BeforeEach(func() {
+		cmdRunner = fakesys.NewFakeCmdRunner()
+		stage = fakebiui.NewFakeStage()
+		runner = bihook.NewRunner(bihook.StaticSource{
+			{Point: bihook.PostVMCreate, Path: "/hooks/register-dns"},
+			{Point: bihook.PostDeploy, Path: "/hooks/notify"},
+			{Point: bihook.PostVMCreate, Path: "/hooks/update-lb"},
+		}, cmdRunner, boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	It("runs the hooks of the point in order with the context as JSON on stdin", func() {
+		err := runner.Run(bihook.PostVMCreate, contextOf(bihook.Context{VMCID: "fake-vm-cid", IPs: []string{"10.0.0.6"}, StemcellCID: "fake-stemcell-cid"}), stage)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cmdRunner.RunComplexCommands).To(HaveLen(2))
+		Expect(cmdRunner.RunComplexCommands[0].Name).To(Equal("/hooks/register-dns"))
+		Expect(cmdRunner.RunComplexCommands[1].Name).To(Equal("/hooks/update-lb"))
+
+		stdin, err := io.ReadAll(cmdRunner.RunComplexCommands[0].Stdin)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(json.Valid(stdin)).To(BeTrue())
+		Expect(string(stdin)).To(Equal(`{"hook":"post-vm-create","vm_cid":"fake-vm-cid","ips":["10.0.0.6"],"stemcell_cid":"fake-stemcell-cid","disk_cids":[]}`))
+
+		Expect(stage.PerformCalls).To(Equal([]*fakebiui.PerformCall{
+			{Name: "Running post-vm-create hook '/hooks/register-dns'"},
+			{Name: "Running post-vm-create hook '/hooks/update-lb'"},
+		}))
+	})
+
+	It("does nothing when no hook runs at the point", func() {
+		err := runner.Run(bihook.PreVMDelete, func() bihook.Context {
+			Fail("Expected the context not to be built")
+			return bihook.Context{}
+		}, stage)
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(cmdRunner.RunComplexCommands).To(BeEmpty())
+		Expect(stage.PerformCalls).To(BeEmpty())
+	})
+
+	It("stops at a hook that exits non-zero and fails its step", func() {
+		cmdRunner.AddCmdResult("/hooks/register-dns", fakesys.FakeCmdResult{
+			Stderr:     "zone not found\n",
+			ExitStatus: 2,
+			Error:      errors.New("fake-exit-error"),
+		})
+
+		err := runner.Run(bihook.PostVMCreate, contextOf(bihook.Context{VMCID: "fake-vm-cid"}), stage)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Hook '/hooks/register-dns' exited with 2: zone not found"))
+
+		Expect(cmdRunner.RunComplexCommands).To(HaveLen(1))
+		Expect(stage.PerformCalls[0].Error).To(Equal(err))
+	})
+
+	It("returns an error when a hook cannot be run", func() {
+		cmdRunner.AddCmdResult("/hooks/notify", fakesys.FakeCmdResult{ExitStatus: -1, Error: errors.New("fake-start-error")})
+
+		err := runner.Run(bihook.PostDeploy, contextOf(bihook.Context{}), stage)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Running hook '/hooks/notify': fake-start-error"))
+	})
+
+	It("returns an error when the hooks cannot be found", func() {
+		runner = bihook.NewRunner(erroringSource{}, cmdRunner, boshlog.NewLogger(boshlog.LevelNone))
+
+		err := runner.Run(bihook.PostDeploy, contextOf(bihook.Context{}), stage)
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Finding hooks: fake-source-error"))
+	})
+})
+
+var _ = Describe("ParsePoint", func() {
+	It("parses known points", func() {
+		Expect(bihook.ParsePoint("post-agent-ready")).To(Equal(bihook.PostAgentReady))
+	})
+
+	It("returns an error for unknown points", func() {
+		_, err := bihook.ParsePoint("post-lunch")
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(Equal("Expected hook point 'post-lunch' to be one of 'pre-vm-delete', 'post-vm-create', 'post-agent-ready', 'post-deploy'"))
+	})
+})
+
+var _ = Describe("AgentIPs", func() {
+	It("returns the IPs of the networks ordered by network name", func() {
+		Expect(bihook.AgentIPs(agentclient.AgentState{
+			NetworkSpecs: map[string]agentclient.NetworkSpec{
+				"public":  {IP: "203.0.113.4"},
+				"default": {IP: "10.0.0.6"},
+				"vip":     {},
+			},
+		})).To(Equal([]string{"10.0.0.6", "203.0.113.4"}))
+	})
+})
+
+func contextOf(context bihook.Context) func() bihook.Context {
+	return func() bihook.Context { return context }
+}
+
+type erroringSource struct{}
+
+func (erroringSource) Hooks() ([]bihook.Hook, error) {
+	return nil, errors.New("fake-source-error")
+}
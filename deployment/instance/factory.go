@@ -4,6 +4,8 @@ import (
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 
 	biblobstore "github.com/cloudfoundry/bosh-cli/v7/blobstore"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
 	biinstancestate "github.com/cloudfoundry/bosh-cli/v7/deployment/instance/state"
 	bisshtunnel "github.com/cloudfoundry/bosh-cli/v7/deployment/sshtunnel"
 	bivm "github.com/cloudfoundry/bosh-cli/v7/deployment/vm"
@@ -23,13 +25,19 @@ type Factory interface {
 
 type factory struct {
 	stateBuilderFactory biinstancestate.BuilderFactory
+	hookRunner          bihook.Runner
+	diskRepo            biconfig.DiskRepo
 }
 
 func NewFactory(
 	stateBuilderFactory biinstancestate.BuilderFactory,
+	hookRunner bihook.Runner,
+	diskRepo biconfig.DiskRepo,
 ) Factory {
 	return &factory{
 		stateBuilderFactory: stateBuilderFactory,
+		hookRunner:          hookRunner,
+		diskRepo:            diskRepo,
 	}
 }
 
@@ -51,6 +59,8 @@ func (f *factory) NewInstance(
 		vmManager,
 		sshTunnelFactory,
 		stateBuilder,
+		f.hookRunner,
+		f.diskRepo,
 		logger,
 	)
 }
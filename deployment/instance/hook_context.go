@@ -0,0 +1,42 @@
+package instance
+
+import (
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
+	bivm "github.com/cloudfoundry/bosh-cli/v7/deployment/vm"
+)
+
+// hookContext takes the disks from the deployment state
+// since the agent may not be ready to list them yet
+func hookContext(vm bivm.VM, diskRepo biconfig.DiskRepo, ips []string, logger boshlog.Logger, logTag string) bihook.Context {
+	context := bihook.Context{VMCID: vm.CID(), IPs: ips}
+
+	diskRecords, err := diskRepo.FindCurrent()
+	if err != nil {
+		logger.Warn(logTag, "Finding current disks for hooks: %s", err.Error())
+	}
+
+	for _, diskRecord := range diskRecords {
+		context.DiskCIDs = append(context.DiskCIDs, diskRecord.CID)
+	}
+
+	return context
+}
+
+// agentIPs prefers the IPs the agent reports, which include dynamic IPs,
+// to the given static IPs of the manifest
+func agentIPs(vm bivm.VM, staticIPs []string, logger boshlog.Logger, logTag string) []string {
+	state, err := vm.GetState()
+	if err != nil {
+		logger.Warn(logTag, "Getting IPs of VM '%s' from its agent for hooks: %s", vm.CID(), err.Error())
+		return staticIPs
+	}
+
+	if ips := bihook.AgentIPs(state); len(ips) > 0 {
+		return ips
+	}
+
+	return staticIPs
+}
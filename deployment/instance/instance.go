@@ -8,7 +8,9 @@ import (
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 
 	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
 	bidisk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
 	biinstancestate "github.com/cloudfoundry/bosh-cli/v7/deployment/instance/state"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest"
 	bisshtunnel "github.com/cloudfoundry/bosh-cli/v7/deployment/sshtunnel"
@@ -50,6 +52,8 @@ type instance struct {
 	vmManager        bivm.Manager
 	sshTunnelFactory bisshtunnel.Factory
 	stateBuilder     biinstancestate.Builder
+	hookRunner       bihook.Runner
+	diskRepo         biconfig.DiskRepo
 	logger           boshlog.Logger
 	logTag           string
 }
@@ -61,6 +65,8 @@ func NewInstance(
 	vmManager bivm.Manager,
 	sshTunnelFactory bisshtunnel.Factory,
 	stateBuilder biinstancestate.Builder,
+	hookRunner bihook.Runner,
+	diskRepo biconfig.DiskRepo,
 	logger boshlog.Logger,
 ) Instance {
 	return &instance{
@@ -70,6 +76,8 @@ func NewInstance(
 		vmManager:        vmManager,
 		sshTunnelFactory: sshTunnelFactory,
 		stateBuilder:     stateBuilder,
+		hookRunner:       hookRunner,
+		diskRepo:         diskRepo,
 		logger:           logger,
 		logTag:           "instance",
 	}
@@ -104,12 +112,12 @@ func (i *instance) WaitUntilReady(
 }
 
 func (i *instance) UpdateDisks(deploymentManifest bideplmanifest.Manifest, stage biui.Stage) ([]bidisk.Disk, error) {
-	diskPool, err := deploymentManifest.DiskPool(i.jobName)
+	diskPools, err := deploymentManifest.PersistentDiskPools(i.jobName)
 	if err != nil {
 		return []bidisk.Disk{}, bosherr.WrapError(err, "Getting disk pool")
 	}
 
-	disks, err := i.vm.UpdateDisks(diskPool, stage)
+	disks, err := i.vm.UpdateDisks(diskPools, stage)
 	if err != nil {
 		return disks, bosherr.WrapError(err, "Updating disks")
 	}
@@ -191,6 +199,17 @@ func (i *instance) Delete(
 		return bosherr.WrapErrorf(err, "Checking existence of vm for instance '%s/%d'", i.jobName, i.id)
 	}
 
+	err = i.hookRunner.Run(bihook.PreVMDelete, func() bihook.Context {
+		var ips []string
+		if vmExists {
+			ips = agentIPs(i.vm, nil, i.logger, i.logTag)
+		}
+		return hookContext(i.vm, i.diskRepo, ips, i.logger, i.logTag)
+	}, stage)
+	if err != nil {
+		return err
+	}
+
 	if vmExists {
 		if err = i.shutdown(pingTimeout, pingDelay, skipDrain, false, stage); err != nil {
 			return err
@@ -12,8 +12,12 @@ import (
 	. "github.com/onsi/gomega"
 
 	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	fakebiconfig "github.com/cloudfoundry/bosh-cli/v7/config/fakes"
 	bidisk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk"
 	fakebidisk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk/fakes"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
+	fakebihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook/fakes"
 	. "github.com/cloudfoundry/bosh-cli/v7/deployment/instance"
 	mock_instance_state "github.com/cloudfoundry/bosh-cli/v7/deployment/instance/state/mocks"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest"
@@ -43,6 +47,8 @@ var _ = Describe("Instance", func() {
 		fakeSSHTunnelFactory *fakebisshtunnel.FakeFactory
 		fakeSSHTunnel        *fakebisshtunnel.FakeTunnel
 		fakeStage            *fakebiui.FakeStage
+		fakeHookRunner       *fakebihook.FakeRunner
+		fakeDiskRepo         *fakebiconfig.FakeDiskRepo
 		logger               *loggerfakes.FakeLogger
 
 		instance Instance
@@ -69,6 +75,8 @@ var _ = Describe("Instance", func() {
 		mockState = mock_instance_state.NewMockState(mockCtrl)
 
 		logger = &loggerfakes.FakeLogger{}
+		fakeHookRunner = fakebihook.NewFakeRunner()
+		fakeDiskRepo = fakebiconfig.NewFakeDiskRepo()
 
 		skipDrain = false
 
@@ -79,6 +87,8 @@ var _ = Describe("Instance", func() {
 			fakeVMManager,
 			fakeSSHTunnelFactory,
 			mockStateBuilder,
+			fakeHookRunner,
+			fakeDiskRepo,
 			logger,
 		)
 
@@ -117,6 +127,39 @@ var _ = Describe("Instance", func() {
 			}))
 		})
 
+		It("runs pre-vm-delete hooks with the IPs reported by the agent before stopping the vm", func() {
+			fakeVM.GetStateResult = agentclient.AgentState{
+				NetworkSpecs: map[string]agentclient.NetworkSpec{"network-1": {IP: "10.0.0.5"}},
+			}
+			fakeDiskRepo.SetFindCurrentBehavior([]biconfig.DiskRecord{{ID: "fake-disk-id", CID: "fake-disk-1"}}, nil)
+
+			err := instance.Delete(pingTimeout, pingDelay, skipDrain, fakeStage)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeHookRunner.RunInputs).To(Equal([]fakebihook.RunInput{
+				{
+					Point: bihook.PreVMDelete,
+					Context: bihook.Context{
+						VMCID:    "fake-vm-cid",
+						IPs:      []string{"10.0.0.5"},
+						DiskCIDs: []string{"fake-disk-1"},
+					},
+					Stage: fakeStage,
+				},
+			}))
+		})
+
+		It("does not stop or delete the vm when a pre-vm-delete hook fails", func() {
+			fakeHookRunner.RunErrs[bihook.PreVMDelete] = bosherr.Error("fake-hook-error")
+
+			err := instance.Delete(pingTimeout, pingDelay, skipDrain, fakeStage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("fake-hook-error"))
+
+			Expect(fakeVM.StopCalled).To(Equal(0))
+			Expect(fakeVM.DeleteCalled).To(Equal(0))
+		})
+
 		Context("when agent is responsive", func() {
 			It("logs waiting for the agent event", func() {
 				err := instance.Delete(pingTimeout, pingDelay, skipDrain, fakeStage)
@@ -279,6 +322,14 @@ var _ = Describe("Instance", func() {
 				Expect(fakeVM.UnmountDiskInputs).To(HaveLen(0))
 			})
 
+			It("runs pre-vm-delete hooks without asking the agent for IPs", func() {
+				err := instance.Delete(pingTimeout, pingDelay, skipDrain, fakeStage)
+				Expect(err).NotTo(HaveOccurred())
+
+				Expect(fakeHookRunner.RunInputs[0].Context).To(Equal(bihook.Context{VMCID: "fake-vm-cid"}))
+				Expect(fakeVM.GetStateCalled).To(Equal(0))
+			})
+
 			It("logs vm delete as skipped", func() {
 				err := instance.Delete(pingTimeout, pingDelay, skipDrain, fakeStage)
 				Expect(err).NotTo(HaveOccurred())
@@ -9,7 +9,9 @@ import (
 
 	biblobstore "github.com/cloudfoundry/bosh-cli/v7/blobstore"
 	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
 	bidisk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest"
 	bisshtunnel "github.com/cloudfoundry/bosh-cli/v7/deployment/sshtunnel"
 	bivm "github.com/cloudfoundry/bosh-cli/v7/deployment/vm"
@@ -40,6 +42,8 @@ type manager struct {
 	blobstore        biblobstore.Blobstore
 	sshTunnelFactory bisshtunnel.Factory
 	instanceFactory  Factory
+	hookRunner       bihook.Runner
+	diskRepo         biconfig.DiskRepo
 	logger           boshlog.Logger
 	logTag           string
 }
@@ -50,6 +54,8 @@ func NewManager(
 	blobstore biblobstore.Blobstore,
 	sshTunnelFactory bisshtunnel.Factory,
 	instanceFactory Factory,
+	hookRunner bihook.Runner,
+	diskRepo biconfig.DiskRepo,
 	logger boshlog.Logger,
 ) Manager {
 	return &manager{
@@ -58,6 +64,8 @@ func NewManager(
 		blobstore:        blobstore,
 		sshTunnelFactory: sshTunnelFactory,
 		instanceFactory:  instanceFactory,
+		hookRunner:       hookRunner,
+		diskRepo:         diskRepo,
 		logger:           logger,
 		logTag:           "vmDeployer",
 	}
@@ -118,12 +126,32 @@ func (m *manager) Create(
 		return nil, []bidisk.Disk{}, err
 	}
 
+	staticIPs := deploymentManifest.StaticIPs(jobName)
+
+	err = m.hookRunner.Run(bihook.PostVMCreate, func() bihook.Context {
+		context := hookContext(vm, m.diskRepo, staticIPs, m.logger, m.logTag)
+		context.StemcellCID = cloudStemcell.CID()
+		return context
+	}, eventLoggerStage)
+	if err != nil {
+		return nil, []bidisk.Disk{}, err
+	}
+
 	instance := m.instanceFactory.NewInstance(jobName, id, vm, m.vmManager, m.sshTunnelFactory, m.blobstore, m.logger)
 
 	if err := instance.WaitUntilReady(eventLoggerStage); err != nil {
 		return instance, []bidisk.Disk{}, bosherr.WrapError(err, "Waiting until instance is ready")
 	}
 
+	err = m.hookRunner.Run(bihook.PostAgentReady, func() bihook.Context {
+		context := hookContext(vm, m.diskRepo, agentIPs(vm, staticIPs, m.logger, m.logTag), m.logger, m.logTag)
+		context.StemcellCID = cloudStemcell.CID()
+		return context
+	}, eventLoggerStage)
+	if err != nil {
+		return instance, []bidisk.Disk{}, err
+	}
+
 	disks, err := instance.UpdateDisks(deploymentManifest, eventLoggerStage)
 	if err != nil {
 		return instance, disks, bosherr.WrapError(err, "Updating instance disks")
@@ -5,6 +5,8 @@ import (
 
 	biblobstore "github.com/cloudfoundry/bosh-cli/v7/blobstore"
 	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
 	bisshtunnel "github.com/cloudfoundry/bosh-cli/v7/deployment/sshtunnel"
 	bivm "github.com/cloudfoundry/bosh-cli/v7/deployment/vm"
 )
@@ -16,17 +18,23 @@ type ManagerFactory interface {
 type managerFactory struct {
 	sshTunnelFactory bisshtunnel.Factory
 	instanceFactory  Factory
+	hookRunner       bihook.Runner
+	diskRepo         biconfig.DiskRepo
 	logger           boshlog.Logger
 }
 
 func NewManagerFactory(
 	sshTunnelFactory bisshtunnel.Factory,
 	instanceFactory Factory,
+	hookRunner bihook.Runner,
+	diskRepo biconfig.DiskRepo,
 	logger boshlog.Logger,
 ) ManagerFactory {
 	return &managerFactory{
 		sshTunnelFactory: sshTunnelFactory,
 		instanceFactory:  instanceFactory,
+		hookRunner:       hookRunner,
+		diskRepo:         diskRepo,
 		logger:           logger,
 	}
 }
@@ -38,6 +46,8 @@ func (f *managerFactory) NewManager(cloud bicloud.Cloud, vmManager bivm.Manager,
 		blobstore,
 		f.sshTunnelFactory,
 		f.instanceFactory,
+		f.hookRunner,
+		f.diskRepo,
 		f.logger,
 	)
 }
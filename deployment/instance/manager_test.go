@@ -15,8 +15,12 @@ import (
 	mock_agentclient "github.com/cloudfoundry/bosh-cli/v7/agentclient/mocks"
 	mock_blobstore "github.com/cloudfoundry/bosh-cli/v7/blobstore/mocks"
 	fakebicloud "github.com/cloudfoundry/bosh-cli/v7/cloud/fakes"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	fakebiconfig "github.com/cloudfoundry/bosh-cli/v7/config/fakes"
 	bidisk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk"
 	fakebidisk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk/fakes"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
+	fakebihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook/fakes"
 	. "github.com/cloudfoundry/bosh-cli/v7/deployment/instance"
 	mock_instance_state "github.com/cloudfoundry/bosh-cli/v7/deployment/instance/state/mocks"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest"
@@ -53,6 +57,8 @@ var _ = Describe("Manager", func() {
 		fakeSSHTunnelFactory *fakebisshtunnel.FakeFactory
 		fakeSSHTunnel        *fakebisshtunnel.FakeTunnel
 		instanceFactory      Factory
+		fakeHookRunner       *fakebihook.FakeRunner
+		fakeDiskRepo         *fakebiconfig.FakeDiskRepo
 		logger               boshlog.Logger
 		fakeStage            *fakebiui.FakeStage
 
@@ -73,7 +79,11 @@ var _ = Describe("Manager", func() {
 		mockStateBuilder = mock_instance_state.NewMockBuilder(mockCtrl)
 		mockState = mock_instance_state.NewMockState(mockCtrl)
 
-		instanceFactory = NewFactory(mockStateBuilderFactory)
+		fakeHookRunner = fakebihook.NewFakeRunner()
+
+		fakeDiskRepo = fakebiconfig.NewFakeDiskRepo()
+
+		instanceFactory = NewFactory(mockStateBuilderFactory, fakeHookRunner, fakeDiskRepo)
 
 		mockBlobstore = mock_blobstore.NewMockBlobstore(mockCtrl)
 
@@ -87,6 +97,8 @@ var _ = Describe("Manager", func() {
 			mockBlobstore,
 			fakeSSHTunnelFactory,
 			instanceFactory,
+			fakeHookRunner,
+			fakeDiskRepo,
 			logger,
 		)
 	})
@@ -177,6 +189,8 @@ var _ = Describe("Manager", func() {
 				fakeVMManager,
 				fakeSSHTunnelFactory,
 				mockStateBuilder,
+				fakeHookRunner,
+				fakeDiskRepo,
 				logger,
 			)
 
@@ -256,6 +270,48 @@ var _ = Describe("Manager", func() {
 			}))
 		})
 
+		It("runs post-vm-create and post-agent-ready hooks with the disks of the deployment state", func() {
+			fakeDiskRepo.SetFindCurrentBehavior([]biconfig.DiskRecord{{ID: "fake-disk-id", CID: "fake-disk-cid"}}, nil)
+			fakeVM.ListDisksErr = errors.New("fake-agent-not-ready-error")
+
+			_, _, err := manager.Create(
+				"fake-job-name",
+				0,
+				deploymentManifest,
+				fakeCloudStemcell,
+				fakeStage,
+			)
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(fakeHookRunner.RunInputs).To(Equal([]fakebihook.RunInput{
+				{
+					Point:   bihook.PostVMCreate,
+					Context: bihook.Context{VMCID: "fake-vm-cid", IPs: []string{}, DiskCIDs: []string{"fake-disk-cid"}, StemcellCID: "fake-stemcell-cid"},
+					Stage:   fakeStage,
+				},
+				{
+					Point:   bihook.PostAgentReady,
+					Context: bihook.Context{VMCID: "fake-vm-cid", IPs: []string{}, DiskCIDs: []string{"fake-disk-cid"}, StemcellCID: "fake-stemcell-cid"},
+					Stage:   fakeStage,
+				},
+			}))
+		})
+
+		It("does not wait for the vm when a post-vm-create hook fails", func() {
+			fakeHookRunner.RunErrs[bihook.PostVMCreate] = errors.New("fake-hook-error")
+
+			_, _, err := manager.Create(
+				"fake-job-name",
+				0,
+				deploymentManifest,
+				fakeCloudStemcell,
+				fakeStage,
+			)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("fake-hook-error"))
+			Expect(fakeVM.WaitUntilReadyInputs).To(BeEmpty())
+		})
+
 		It("returns the 'updated' disks", func() {
 			_, disks, err := manager.Create(
 				"fake-job-name",
@@ -269,8 +325,8 @@ var _ = Describe("Manager", func() {
 
 			Expect(fakeVM.UpdateDisksInputs).To(Equal([]fakebivm.UpdateDisksInput{
 				{
-					DiskPool: diskPool,
-					Stage:    fakeStage,
+					DiskPools: []bideplmanifest.NamedDiskPool{{DiskPool: diskPool}},
+					Stage:     fakeStage,
 				},
 			}))
 		})
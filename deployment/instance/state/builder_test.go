@@ -18,6 +18,7 @@ import (
 	. "github.com/cloudfoundry/bosh-cli/v7/release/resource"
 	bistatejob "github.com/cloudfoundry/bosh-cli/v7/state/job"
 	mockstatejob "github.com/cloudfoundry/bosh-cli/v7/state/job/mocks"
+	bitemplate "github.com/cloudfoundry/bosh-cli/v7/templatescompiler"
 	mocktemplate "github.com/cloudfoundry/bosh-cli/v7/templatescompiler/mocks"
 	fakebiui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
 )
@@ -190,6 +191,12 @@ var _ = Describe("Builder", func() {
 								Properties: &biproperty.Map{
 									"fake-template-property": "fake-template-property-value",
 								},
+								Consumes: map[string]bideplmanifest.ConsumedLink{
+									"fake-consumed-link": {From: "fake-provided-link"},
+								},
+								Provides: map[string]bideplmanifest.ProvidedLink{
+									"fake-blocked-link": {Blocked: true},
+								},
 							},
 						},
 						Properties: biproperty.Map{
@@ -287,7 +294,18 @@ var _ = Describe("Builder", func() {
 				"fake-job-property": "fake-global-property-value",
 			}
 
-			mockJobListRenderer.EXPECT().Render(releaseJobs, releaseJobProperties, jobProperties, globalProperties, "fake-deployment-name", expectedIP).Return(mockRenderedJobList, nil)
+			releaseJobLinks := map[string]bitemplate.JobLinks{
+				"job-name": {
+					Consumes: map[string]bitemplate.ConsumedLink{
+						"fake-consumed-link": {From: "fake-provided-link"},
+					},
+					Provides: map[string]bitemplate.ProvidedLink{
+						"fake-blocked-link": {Blocked: true},
+					},
+				},
+			}
+
+			mockJobListRenderer.EXPECT().Render(releaseJobs, releaseJobProperties, releaseJobLinks, jobProperties, globalProperties, "fake-deployment-name", expectedIP).Return(mockRenderedJobList, nil)
 
 			mockRenderedJobList.EXPECT().DeleteSilently()
 
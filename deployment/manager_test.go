@@ -17,6 +17,7 @@ import (
 	. "github.com/cloudfoundry/bosh-cli/v7/deployment"
 	bidisk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk"
 	mock_disk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk/mocks"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
 	biinstance "github.com/cloudfoundry/bosh-cli/v7/deployment/instance"
 	mock_instance "github.com/cloudfoundry/bosh-cli/v7/deployment/instance/mocks"
 	mock_instance_state "github.com/cloudfoundry/bosh-cli/v7/deployment/instance/state/mocks"
@@ -191,8 +192,9 @@ var _ = Describe("Manager", func() {
 
 			mockStateBuilderFactory = mock_instance_state.NewMockBuilderFactory(mockCtrl)
 
-			instanceFactory := biinstance.NewFactory(mockStateBuilderFactory)
-			instanceManagerFactory := biinstance.NewManagerFactory(sshTunnelFactory, instanceFactory, logger)
+			hookRunner := bihook.NewRunner(bihook.StaticSource{}, fakesys.NewFakeCmdRunner(), logger)
+			instanceFactory := biinstance.NewFactory(mockStateBuilderFactory, hookRunner, diskRepo)
+			instanceManagerFactory := biinstance.NewManagerFactory(sshTunnelFactory, instanceFactory, hookRunner, diskRepo, logger)
 			stemcellManagerFactory := bistemcell.NewManagerFactory(stemcellRepo)
 
 			mockBlobstore = mock_blobstore.NewMockBlobstore(mockCtrl)
@@ -209,9 +211,9 @@ var _ = Describe("Manager", func() {
 
 			BeforeEach(func() {
 				var err error
-				currentDiskRecord, err = diskRepo.Save("fake-disk-cid", 100, nil)
+				currentDiskRecord, err = diskRepo.Save("", "fake-disk-cid", 100, nil)
 				Expect(err).ToNot(HaveOccurred())
-				err = diskRepo.UpdateCurrent(currentDiskRecord.ID)
+				err = diskRepo.UpdateCurrent([]string{currentDiskRecord.ID})
 				Expect(err).ToNot(HaveOccurred())
 
 				currentStemcellRecord, err = stemcellRepo.Save("fake-stemcell-name", "fake-stemcell-version", "fake-stemcell-cid", stemcellApiVersion)
@@ -224,10 +226,9 @@ var _ = Describe("Manager", func() {
 				err := deploymentManager.Cleanup(fakeStage)
 				Expect(err).ToNot(HaveOccurred())
 
-				diskRecord, found, err := diskRepo.FindCurrent()
+				diskRecords, err := diskRepo.FindCurrent()
 				Expect(err).ToNot(HaveOccurred())
-				Expect(found).To(BeTrue())
-				Expect(diskRecord).To(Equal(currentDiskRecord))
+				Expect(diskRecords).To(Equal([]biconfig.DiskRecord{currentDiskRecord}))
 
 				stemcellRecord, found, err := stemcellRepo.FindCurrent()
 				Expect(err).ToNot(HaveOccurred())
@@ -245,7 +246,7 @@ var _ = Describe("Manager", func() {
 
 		Context("orphan disk records exist", func() {
 			BeforeEach(func() {
-				_, err := diskRepo.Save("orphan-disk-cid", 100, nil)
+				_, err := diskRepo.Save("", "orphan-disk-cid", 100, nil)
 				Expect(err).ToNot(HaveOccurred())
 			})
 
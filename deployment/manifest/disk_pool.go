@@ -9,3 +9,10 @@ type DiskPool struct {
 	DiskSize        int
 	CloudProperties biproperty.Map
 }
+
+// NamedDiskPool is the disk pool of a persistent disk of a job. Name is empty
+// for the single disk of persistent_disk and persistent_disk_pool.
+type NamedDiskPool struct {
+	Name     string
+	DiskPool DiskPool
+}
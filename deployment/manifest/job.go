@@ -12,10 +12,18 @@ type Job struct {
 	Networks           []JobNetwork
 	PersistentDisk     int
 	PersistentDiskPool string
+	PersistentDisks    []PersistentDisk
 	ResourcePool       string
 	Properties         biproperty.Map
 }
 
+// PersistentDisk is one of the named persistent disks of an instance group,
+// attached in the order they are listed
+type PersistentDisk struct {
+	Name     string
+	DiskPool string
+}
+
 type JobLifecycle string
 
 const (
@@ -27,6 +35,30 @@ type ReleaseJobRef struct {
 	Name       string
 	Release    string
 	Properties *biproperty.Map
+
+	// Consumes and Provides configure links between the jobs of the instance group by link name
+	Consumes map[string]ConsumedLink
+	Provides map[string]ProvidedLink
+}
+
+// ConsumedLink comes from the job providing a link named From,
+// or from Instances, Properties and Address for a manually defined link
+type ConsumedLink struct {
+	// Blocked links are not consumed ('nil' in the manifest)
+	Blocked    bool
+	From       string
+	Deployment string
+
+	InstanceAddresses []string
+	Properties        *biproperty.Map
+	Address           string
+}
+
+type ProvidedLink struct {
+	// Blocked links are not provided ('nil' in the manifest)
+	Blocked bool
+	As      string
+	Shared  bool
 }
 
 type JobNetwork struct {
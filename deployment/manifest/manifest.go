@@ -102,6 +102,50 @@ func (d Manifest) DiskPool(jobName string) (DiskPool, error) {
 	return DiskPool{}, nil
 }
 
+// PersistentDiskPools returns the disk pools of the persistent disks of the job in order,
+// with a single unnamed disk pool for persistent_disk and persistent_disk_pool
+func (d Manifest) PersistentDiskPools(jobName string) ([]NamedDiskPool, error) {
+	job, found := d.FindJobByName(jobName)
+	if !found {
+		return []NamedDiskPool{}, bosherr.Errorf("Could not find job with name: %s", jobName)
+	}
+
+	namedDiskPools := []NamedDiskPool{}
+
+	if len(job.PersistentDisks) == 0 {
+		diskPool, err := d.DiskPool(jobName)
+		if err != nil {
+			return namedDiskPools, err
+		}
+
+		if diskPool.DiskSize > 0 {
+			namedDiskPools = append(namedDiskPools, NamedDiskPool{DiskPool: diskPool})
+		}
+
+		return namedDiskPools, nil
+	}
+
+	for _, persistentDisk := range job.PersistentDisks {
+		diskPool, found := d.findDiskPool(persistentDisk.DiskPool)
+		if !found {
+			return namedDiskPools, bosherr.Errorf("Could not find disk type '%s' for persistent disk '%s' of job '%s'", persistentDisk.DiskPool, persistentDisk.Name, jobName)
+		}
+
+		namedDiskPools = append(namedDiskPools, NamedDiskPool{Name: persistentDisk.Name, DiskPool: diskPool})
+	}
+
+	return namedDiskPools, nil
+}
+
+func (d Manifest) findDiskPool(name string) (DiskPool, bool) {
+	for _, diskPool := range d.DiskPools {
+		if diskPool.Name == name {
+			return diskPool, true
+		}
+	}
+	return DiskPool{}, false
+}
+
 func (d Manifest) networkMap() map[string]Network {
 	result := map[string]Network{}
 	for _, network := range d.Networks {
@@ -110,6 +154,22 @@ func (d Manifest) networkMap() map[string]Network {
 	return result
 }
 
+// StaticIPs returns the static IPs of the networks of the job in order
+func (d Manifest) StaticIPs(jobName string) []string {
+	ips := []string{}
+
+	job, found := d.FindJobByName(jobName)
+	if !found {
+		return ips
+	}
+
+	for _, jobNetwork := range job.Networks {
+		ips = append(ips, jobNetwork.StaticIPs...)
+	}
+
+	return ips
+}
+
 func (d Manifest) FindJobByName(jobName string) (Job, bool) {
 	for _, job := range d.Jobs {
 		if job.Name == jobName {
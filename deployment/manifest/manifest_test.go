@@ -369,6 +369,80 @@ var _ = Describe("Manifest", func() {
 		})
 	})
 
+	Describe("StaticIPs", func() {
+		It("returns the static IPs of all networks of the job", func() {
+			deploymentManifest = Manifest{
+				Jobs: []Job{
+					{
+						Name: "fake-job-name",
+						Networks: []JobNetwork{
+							{Name: "private", StaticIPs: []string{"10.0.0.6"}},
+							{Name: "dynamic"},
+							{Name: "public", StaticIPs: []string{"203.0.113.4"}},
+						},
+					},
+				},
+			}
+
+			Expect(deploymentManifest.StaticIPs("fake-job-name")).To(Equal([]string{"10.0.0.6", "203.0.113.4"}))
+			Expect(deploymentManifest.StaticIPs("other-job-name")).To(BeEmpty())
+		})
+	})
+
+	Describe("PersistentDiskPools", func() {
+		BeforeEach(func() {
+			deploymentManifest = Manifest{
+				DiskPools: []DiskPool{
+					{Name: "fast", DiskSize: 1024, CloudProperties: biproperty.Map{"type": "ssd"}},
+					{Name: "large", DiskSize: 4096, CloudProperties: biproperty.Map{}},
+				},
+				Jobs: []Job{
+					{
+						Name: "fake-job-name",
+						PersistentDisks: []PersistentDisk{
+							{Name: "data", DiskPool: "large"},
+							{Name: "logs", DiskPool: "fast"},
+						},
+					},
+				},
+			}
+		})
+
+		It("returns the disk pools of the persistent disks in order", func() {
+			diskPools, err := deploymentManifest.PersistentDiskPools("fake-job-name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskPools).To(Equal([]NamedDiskPool{
+				{Name: "data", DiskPool: DiskPool{Name: "large", DiskSize: 4096, CloudProperties: biproperty.Map{}}},
+				{Name: "logs", DiskPool: DiskPool{Name: "fast", DiskSize: 1024, CloudProperties: biproperty.Map{"type": "ssd"}}},
+			}))
+		})
+
+		It("returns an error when a disk type does not exist", func() {
+			deploymentManifest.Jobs[0].PersistentDisks[1].DiskPool = "missing"
+
+			_, err := deploymentManifest.PersistentDiskPools("fake-job-name")
+			Expect(err).To(MatchError("Could not find disk type 'missing' for persistent disk 'logs' of job 'fake-job-name'"))
+		})
+
+		It("returns a single unnamed disk pool for persistent_disk", func() {
+			deploymentManifest.Jobs[0] = Job{Name: "fake-job-name", PersistentDisk: 1024}
+
+			diskPools, err := deploymentManifest.PersistentDiskPools("fake-job-name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskPools).To(Equal([]NamedDiskPool{
+				{DiskPool: DiskPool{DiskSize: 1024, CloudProperties: biproperty.Map{}}},
+			}))
+		})
+
+		It("returns no disk pools when the job has no persistent disk", func() {
+			deploymentManifest.Jobs[0] = Job{Name: "fake-job-name"}
+
+			diskPools, err := deploymentManifest.PersistentDiskPools("fake-job-name")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(diskPools).To(BeEmpty())
+		})
+	})
+
 	Describe("Tags", func() {
 		It("can be referenced", func() {
 			deploymentManifest = Manifest{
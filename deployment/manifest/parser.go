@@ -31,6 +31,12 @@ type manifest struct {
 	InstanceGroups []job `yaml:"instance_groups"`
 	Properties     map[interface{}]interface{}
 	Tags           map[string]string
+
+	// Director's v2 schema, mapped onto resource pools and disk pools
+	VMTypes   []vmType   `yaml:"vm_types"`
+	DiskTypes []diskPool `yaml:"disk_types"`
+	AZs       []az       `yaml:"azs"`
+	Stemcells []stemcell `yaml:"stemcells"`
 }
 
 type UpdateSpec struct {
@@ -53,6 +59,8 @@ type subnet struct {
 	Gateway         string                      `yaml:"gateway"`
 	DNS             []string                    `yaml:"dns"`
 	CloudProperties map[interface{}]interface{} `yaml:"cloud_properties"`
+	AZ              string                      `yaml:"az"`
+	AZs             []string                    `yaml:"azs"`
 }
 
 type resourcePool struct {
@@ -69,6 +77,24 @@ type diskPool struct {
 	CloudProperties map[interface{}]interface{} `yaml:"cloud_properties"`
 }
 
+type vmType struct {
+	Name            string                      `yaml:"name"`
+	CloudProperties map[interface{}]interface{} `yaml:"cloud_properties"`
+}
+
+type az struct {
+	Name            string                      `yaml:"name"`
+	CloudProperties map[interface{}]interface{} `yaml:"cloud_properties"`
+}
+
+type stemcell struct {
+	Alias   string `yaml:"alias"`
+	OS      string `yaml:"os"`
+	Version string `yaml:"version"`
+	URL     string `yaml:"url"`
+	SHA1    string `yaml:"sha1"`
+}
+
 type job struct {
 	Name               string
 	Instances          int
@@ -80,6 +106,19 @@ type job struct {
 	PersistentDiskPool string `yaml:"persistent_disk_pool"`
 	ResourcePool       string `yaml:"resource_pool"`
 	Properties         map[interface{}]interface{}
+
+	// Director's v2 schema
+	VMType             string                      `yaml:"vm_type"`
+	Stemcell           string                      `yaml:"stemcell"`
+	AZs                []string                    `yaml:"azs"`
+	Env                map[interface{}]interface{} `yaml:"env"`
+	PersistentDiskType string                      `yaml:"persistent_disk_type"`
+	PersistentDisks    []persistentDisk            `yaml:"persistent_disks"`
+}
+
+type persistentDisk struct {
+	Name string
+	Type string
 }
 
 type releaseJobRef struct {
@@ -89,6 +128,66 @@ type releaseJobRef struct {
 	// This is a pointer so we can differentiate between `properties: {}`
 	// and not specifying the key at all.
 	Properties *map[interface{}]interface{}
+
+	// Links are nil pointers when they are blocked with `~`
+	Consumes map[string]*consumedLink
+	Provides map[string]*providedLink
+}
+
+type consumedLink struct {
+	blocked    bool
+	From       string
+	Deployment string
+	Instances  []manualLinkInstance
+	Properties *map[interface{}]interface{}
+	Address    string
+}
+
+type manualLinkInstance struct {
+	Address string
+}
+
+type providedLink struct {
+	blocked bool
+	As      string
+	Shared  bool
+}
+
+// UnmarshalYAML accepts 'nil' which blocks the link like in director manifests
+func (l *consumedLink) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	blocked, err := unmarshalBlockedLink(unmarshal)
+	if blocked || err != nil {
+		l.blocked = blocked
+		return err
+	}
+
+	type plain consumedLink
+	return unmarshal((*plain)(l))
+}
+
+func (l *providedLink) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	blocked, err := unmarshalBlockedLink(unmarshal)
+	if blocked || err != nil {
+		l.blocked = blocked
+		return err
+	}
+
+	type plain providedLink
+	return unmarshal((*plain)(l))
+}
+
+func unmarshalBlockedLink(unmarshal func(interface{}) error) (bool, error) {
+	var value string
+
+	if unmarshal(&value) != nil {
+		return false, nil
+	}
+
+	if value != "nil" {
+		return false, bosherr.Errorf("Expected link to be 'nil' or a hash but was '%s'", value)
+	}
+
+	return true, nil
 }
 
 type stemcellRef struct {
@@ -144,6 +243,20 @@ func (p *parser) parseDeploymentManifest(depManifest manifest, path string) (Man
 	deployment.Name = depManifest.Name
 	deployment.Tags = depManifest.Tags
 
+	if len(depManifest.Jobs) > 0 && len(depManifest.InstanceGroups) > 0 {
+		return Manifest{}, bosherr.Error("Deployment specifies both jobs and instance_groups keys, only one is allowed")
+	}
+
+	rawJobs := depManifest.Jobs
+	if len(depManifest.InstanceGroups) > 0 {
+		rawJobs = depManifest.InstanceGroups
+	}
+
+	err := p.mapInstanceGroupTypes(&depManifest, rawJobs)
+	if err != nil {
+		return Manifest{}, err
+	}
+
 	networks, err := p.parseNetworkManifests(depManifest.Networks)
 	if err != nil {
 		return Manifest{}, bosherr.WrapErrorf(err, "Parsing networks: %#v", depManifest.Networks)
@@ -163,14 +276,6 @@ func (p *parser) parseDeploymentManifest(depManifest manifest, path string) (Man
 	}
 	deployment.DiskPools = diskPools
 
-	if len(depManifest.Jobs) > 0 && len(depManifest.InstanceGroups) > 0 {
-		return Manifest{}, bosherr.Error("Deployment specifies both jobs and instance_groups keys, only one is allowed")
-	}
-
-	rawJobs := depManifest.Jobs
-	if len(depManifest.InstanceGroups) > 0 {
-		rawJobs = depManifest.InstanceGroups
-	}
 	jobs, err := p.parseJobManifests(rawJobs)
 	if err != nil {
 		return Manifest{}, bosherr.WrapErrorf(err, "Parsing jobs: %#v", depManifest.Jobs)
@@ -197,6 +302,148 @@ func (p *parser) parseDeploymentManifest(depManifest manifest, path string) (Man
 	return deployment, nil
 }
 
+// mapInstanceGroupTypes turns the vm_types, disk_types, azs and stemcells referenced by
+// instance groups into the resource pools and disk pools of the legacy schema
+func (p *parser) mapInstanceGroupTypes(depManifest *manifest, rawJobs []job) error {
+	if len(depManifest.ResourcePools) > 0 && len(depManifest.VMTypes) > 0 {
+		return bosherr.Error("Deployment specifies both resource_pools and vm_types keys, only one is allowed")
+	}
+
+	if len(depManifest.DiskPools) > 0 && len(depManifest.DiskTypes) > 0 {
+		return bosherr.Error("Deployment specifies both disk_pools and disk_types keys, only one is allowed")
+	}
+
+	depManifest.DiskPools = append(depManifest.DiskPools, depManifest.DiskTypes...)
+
+	for i, rawJob := range rawJobs {
+		if rawJob.PersistentDiskType != "" {
+			if rawJob.PersistentDiskPool != "" {
+				return bosherr.Errorf("Instance group '%s' specifies both persistent_disk_pool and persistent_disk_type, only one is allowed", rawJob.Name)
+			}
+			rawJobs[i].PersistentDiskPool = rawJob.PersistentDiskType
+		}
+
+		if rawJob.VMType == "" {
+			continue
+		}
+
+		if rawJob.ResourcePool != "" {
+			return bosherr.Errorf("Instance group '%s' specifies both resource_pool and vm_type, only one is allowed", rawJob.Name)
+		}
+
+		resourcePool, err := p.resourcePoolForInstanceGroup(*depManifest, rawJob)
+		if err != nil {
+			return err
+		}
+
+		depManifest.ResourcePools = append(depManifest.ResourcePools, resourcePool)
+		rawJobs[i].ResourcePool = resourcePool.Name
+
+		if len(rawJob.AZs) == 1 {
+			depManifest.Networks = p.networksInAZ(depManifest.Networks, rawJob.AZs[0])
+		}
+	}
+
+	return nil
+}
+
+func (p *parser) resourcePoolForInstanceGroup(depManifest manifest, rawJob job) (resourcePool, error) {
+	var (
+		foundVMType   *vmType
+		foundStemcell *stemcell
+	)
+
+	for i, rawVMType := range depManifest.VMTypes {
+		if rawVMType.Name == rawJob.VMType {
+			foundVMType = &depManifest.VMTypes[i]
+		}
+	}
+	if foundVMType == nil {
+		return resourcePool{}, bosherr.Errorf("Could not find vm type '%s' for instance group '%s'", rawJob.VMType, rawJob.Name)
+	}
+
+	for i, rawStemcell := range depManifest.Stemcells {
+		if rawStemcell.Alias == rawJob.Stemcell {
+			foundStemcell = &depManifest.Stemcells[i]
+		}
+	}
+	if foundStemcell == nil {
+		return resourcePool{}, bosherr.Errorf("Could not find stemcell '%s' for instance group '%s'", rawJob.Stemcell, rawJob.Name)
+	}
+
+	// cloud properties of the vm type take precedence over the ones of its az
+	cloudProperties := map[interface{}]interface{}{}
+
+	if len(rawJob.AZs) > 1 {
+		return resourcePool{}, bosherr.Errorf("Instance group '%s' must not specify more than one az", rawJob.Name)
+	}
+
+	if len(rawJob.AZs) == 1 {
+		found := false
+
+		for _, rawAZ := range depManifest.AZs {
+			if rawAZ.Name == rawJob.AZs[0] {
+				found = true
+
+				for key, value := range rawAZ.CloudProperties {
+					cloudProperties[key] = value
+				}
+			}
+		}
+
+		if !found {
+			return resourcePool{}, bosherr.Errorf("Could not find az '%s' for instance group '%s'", rawJob.AZs[0], rawJob.Name)
+		}
+	}
+
+	for key, value := range foundVMType.CloudProperties {
+		cloudProperties[key] = value
+	}
+
+	rawResourcePool := resourcePool{
+		Name:            foundVMType.Name,
+		CloudProperties: cloudProperties,
+		Env:             rawJob.Env,
+		Stemcell:        stemcellRef{URL: foundStemcell.URL, SHA1: foundStemcell.SHA1},
+	}
+
+	if len(rawJob.Networks) > 0 {
+		rawResourcePool.Network = rawJob.Networks[0].Name
+	}
+
+	return rawResourcePool, nil
+}
+
+// networksInAZ keeps the subnets without azs and the ones in the az of the instance
+func (p *parser) networksInAZ(rawNetworks []network, azName string) []network {
+	result := make([]network, len(rawNetworks))
+
+	for i, rawNetwork := range rawNetworks {
+		result[i] = rawNetwork
+		result[i].Subnets = nil
+
+		for _, rawSubnet := range rawNetwork.Subnets {
+			azs := append([]string{}, rawSubnet.AZs...)
+			if rawSubnet.AZ != "" {
+				azs = append(azs, rawSubnet.AZ)
+			}
+
+			inAZ := len(azs) == 0
+			for _, subnetAZ := range azs {
+				if subnetAZ == azName {
+					inAZ = true
+				}
+			}
+
+			if inAZ {
+				result[i].Subnets = append(result[i].Subnets, rawSubnet)
+			}
+		}
+	}
+
+	return result
+}
+
 func (p *parser) parseJobManifests(rawJobs []job) ([]Job, error) {
 	jobs := make([]Job, len(rawJobs))
 	for i, rawJob := range rawJobs {
@@ -209,6 +456,13 @@ func (p *parser) parseJobManifests(rawJobs []job) ([]Job, error) {
 			ResourcePool:       rawJob.ResourcePool,
 		}
 
+		for _, rawPersistentDisk := range rawJob.PersistentDisks {
+			job.PersistentDisks = append(job.PersistentDisks, PersistentDisk{
+				Name:     rawPersistentDisk.Name,
+				DiskPool: rawPersistentDisk.Type,
+			})
+		}
+
 		if len(rawJob.Templates) > 0 && len(rawJob.Jobs) > 0 {
 			return jobs, bosherr.Error("Deployment specifies both templates and jobs keys for instance_group " + job.Name + ", only one is allowed")
 		}
@@ -235,6 +489,11 @@ func (p *parser) parseJobManifests(rawJobs []job) ([]Job, error) {
 					ref.Properties = &properties
 				}
 
+				err := p.parseLinks(rawJobRef, &ref)
+				if err != nil {
+					return []Job{}, bosherr.WrapErrorf(err, "Parsing links of release job '%s'", rawJobRef.Name)
+				}
+
 				releaseJobRefs[i] = ref
 			}
 			job.Templates = releaseJobRefs
@@ -275,6 +534,55 @@ func (p *parser) parseJobManifests(rawJobs []job) ([]Job, error) {
 	return jobs, nil
 }
 
+func (p *parser) parseLinks(rawJobRef releaseJobRef, ref *ReleaseJobRef) error {
+	if rawJobRef.Consumes != nil {
+		ref.Consumes = map[string]ConsumedLink{}
+	}
+
+	for name, rawLink := range rawJobRef.Consumes {
+		if rawLink == nil || rawLink.blocked {
+			ref.Consumes[name] = ConsumedLink{Blocked: true}
+			continue
+		}
+
+		link := ConsumedLink{
+			From:       rawLink.From,
+			Deployment: rawLink.Deployment,
+			Address:    rawLink.Address,
+		}
+
+		for _, instance := range rawLink.Instances {
+			link.InstanceAddresses = append(link.InstanceAddresses, instance.Address)
+		}
+
+		if rawLink.Properties != nil {
+			properties, err := biproperty.BuildMap(*rawLink.Properties)
+			if err != nil {
+				return bosherr.WrapErrorf(err, "Parsing properties of link '%s'", name)
+			}
+
+			link.Properties = &properties
+		}
+
+		ref.Consumes[name] = link
+	}
+
+	if rawJobRef.Provides != nil {
+		ref.Provides = map[string]ProvidedLink{}
+	}
+
+	for name, rawLink := range rawJobRef.Provides {
+		if rawLink == nil || rawLink.blocked {
+			ref.Provides[name] = ProvidedLink{Blocked: true}
+			continue
+		}
+
+		ref.Provides[name] = ProvidedLink{As: rawLink.As, Shared: rawLink.Shared}
+	}
+
+	return nil
+}
+
 func (p *parser) parseNetworkManifests(rawNetworks []network) ([]Network, error) {
 	networks := make([]Network, len(rawNetworks))
 	for i, rawNetwork := range rawNetworks {
@@ -9,6 +9,8 @@ import (
 
 	. "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest"
 	bidepltpl "github.com/cloudfoundry/bosh-cli/v7/deployment/template"
+	birelmanifest "github.com/cloudfoundry/bosh-cli/v7/release/manifest"
+	birelsetmanifest "github.com/cloudfoundry/bosh-cli/v7/release/set/manifest"
 )
 
 var _ = Describe("Parser", func() {
@@ -550,6 +552,309 @@ instance_groups:
 			})
 		})
 
+		Context("when job is defined inside an instance_group with links", func() {
+			BeforeEach(func() {
+				contents := `
+---
+instance_groups:
+- name: jobby
+  jobs:
+  - name: job1
+    consumes:
+      db: {from: primary-db}
+      blocked-db: nil
+      other-blocked-db: ~
+      external-db:
+        instances: [{address: db.example.com}]
+        properties: {port: 5432}
+        address: db.example.com
+    provides:
+      db: {as: primary-db, shared: true}
+      blocked-db: nil
+`
+				interpolatedTemplate = bidepltpl.NewInterpolatedTemplate([]byte(contents), "fake-sha")
+			})
+
+			It("parses the links", func() {
+				deploymentManifest, err := parser.Parse(interpolatedTemplate, manifestPath)
+				Expect(err).ToNot(HaveOccurred())
+
+				releaseJobRef := deploymentManifest.Jobs[0].Templates[0]
+				Expect(releaseJobRef.Consumes).To(Equal(map[string]ConsumedLink{
+					"db":               {From: "primary-db"},
+					"blocked-db":       {Blocked: true},
+					"other-blocked-db": {Blocked: true},
+					"external-db": {
+						InstanceAddresses: []string{"db.example.com"},
+						Properties:        &biproperty.Map{"port": 5432},
+						Address:           "db.example.com",
+					},
+				}))
+				Expect(releaseJobRef.Provides).To(Equal(map[string]ProvidedLink{
+					"db":         {As: "primary-db", Shared: true},
+					"blocked-db": {Blocked: true},
+				}))
+			})
+		})
+
+		Context("when job is defined inside an instance_group with an invalid link", func() {
+			BeforeEach(func() {
+				contents := `
+---
+instance_groups:
+- name: jobby
+  jobs:
+  - name: job1
+    consumes:
+      db: primary-db
+`
+				interpolatedTemplate = bidepltpl.NewInterpolatedTemplate([]byte(contents), "fake-sha")
+			})
+
+			It("returns an error", func() {
+				_, err := parser.Parse(interpolatedTemplate, manifestPath)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Expected link to be 'nil' or a hash but was 'primary-db'"))
+			})
+		})
+
+		Context("when the manifest uses vm_types, disk_types, azs and stemcells", func() {
+			BeforeEach(func() {
+				contents := `
+---
+name: fake-deployment-name
+azs:
+- name: z1
+  cloud_properties: {zone: zone-1, instance_type: small}
+- name: z2
+  cloud_properties: {zone: zone-2}
+vm_types:
+- name: default
+  cloud_properties: {instance_type: large}
+disk_types:
+- name: disks
+  disk_size: 4096
+  cloud_properties: {type: ssd}
+stemcells:
+- alias: default
+  os: ubuntu-jammy
+  version: latest
+  url: https://fake-stemcell-url
+  sha1: fake-stemcell-sha1
+networks:
+- name: default
+  type: manual
+  subnets:
+  - range: 10.0.0.0/24
+    gateway: 10.0.0.1
+    az: z1
+  - range: 10.0.1.0/24
+    gateway: 10.0.1.1
+    azs: [z2]
+instance_groups:
+- name: bosh
+  instances: 1
+  azs: [z1]
+  vm_type: default
+  stemcell: default
+  persistent_disk_type: disks
+  env:
+    bosh: {password: secret}
+  networks:
+  - name: default
+    static_ips: [10.0.0.6]
+  jobs:
+  - name: director
+    release: bosh
+`
+				interpolatedTemplate = bidepltpl.NewInterpolatedTemplate([]byte(contents), "fake-sha")
+			})
+
+			It("maps them onto resource pools and disk pools", func() {
+				deploymentManifest, err := parser.Parse(interpolatedTemplate, manifestPath)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(deploymentManifest.ResourcePools).To(Equal([]ResourcePool{
+					{
+						Name:    "default",
+						Network: "default",
+						CloudProperties: biproperty.Map{
+							"zone":          "zone-1",
+							"instance_type": "large",
+						},
+						Env: biproperty.Map{
+							"bosh": biproperty.Map{"password": "secret"},
+						},
+						Stemcell: StemcellRef{
+							URL:  "https://fake-stemcell-url",
+							SHA1: "fake-stemcell-sha1",
+						},
+					},
+				}))
+
+				Expect(deploymentManifest.DiskPools).To(Equal([]DiskPool{
+					{
+						Name:            "disks",
+						DiskSize:        4096,
+						CloudProperties: biproperty.Map{"type": "ssd"},
+					},
+				}))
+
+				job := deploymentManifest.Jobs[0]
+				Expect(job.ResourcePool).To(Equal("default"))
+				Expect(job.PersistentDiskPool).To(Equal("disks"))
+				Expect(job.Instances).To(Equal(1))
+			})
+
+			It("keeps only the subnets in the az of the instance", func() {
+				deploymentManifest, err := parser.Parse(interpolatedTemplate, manifestPath)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(deploymentManifest.Networks[0].Subnets).To(Equal([]Subnet{
+					{
+						Range:           "10.0.0.0/24",
+						Gateway:         "10.0.0.1",
+						CloudProperties: biproperty.Map{},
+					},
+				}))
+			})
+
+			It("passes validation", func() {
+				deploymentManifest, err := parser.Parse(interpolatedTemplate, manifestPath)
+				Expect(err).ToNot(HaveOccurred())
+
+				releaseSetManifest := birelsetmanifest.Manifest{
+					Releases: []birelmanifest.ReleaseRef{{Name: "bosh"}},
+				}
+
+				err = NewValidator(boshlog.NewLogger(boshlog.LevelNone)).Validate(deploymentManifest, releaseSetManifest)
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
+		Context("when an instance group has persistent_disks", func() {
+			It("parses them in order", func() {
+				interpolatedTemplate = bidepltpl.NewInterpolatedTemplate([]byte(`
+---
+instance_groups:
+- name: bosh
+  persistent_disks:
+  - name: data
+    type: large
+  - name: logs
+    type: small
+`), "fake-sha")
+
+				deploymentManifest, err := parser.Parse(interpolatedTemplate, manifestPath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deploymentManifest.Jobs[0].PersistentDisks).To(Equal([]PersistentDisk{
+					{Name: "data", DiskPool: "large"},
+					{Name: "logs", DiskPool: "small"},
+				}))
+			})
+		})
+
+		Context("when an instance group refers to types that are not defined", func() {
+			It("returns an error for an unknown vm_type", func() {
+				interpolatedTemplate = bidepltpl.NewInterpolatedTemplate([]byte(`
+---
+stemcells: [{alias: default, url: file:///stemcell.tgz}]
+instance_groups:
+- name: bosh
+  vm_type: missing
+  stemcell: default
+`), "fake-sha")
+
+				_, err := parser.Parse(interpolatedTemplate, manifestPath)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Could not find vm type 'missing' for instance group 'bosh'"))
+			})
+
+			It("returns an error for an unknown stemcell", func() {
+				interpolatedTemplate = bidepltpl.NewInterpolatedTemplate([]byte(`
+---
+vm_types: [{name: default}]
+instance_groups:
+- name: bosh
+  vm_type: default
+  stemcell: missing
+`), "fake-sha")
+
+				_, err := parser.Parse(interpolatedTemplate, manifestPath)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Could not find stemcell 'missing' for instance group 'bosh'"))
+			})
+
+			It("returns an error for an unknown az", func() {
+				interpolatedTemplate = bidepltpl.NewInterpolatedTemplate([]byte(`
+---
+vm_types: [{name: default}]
+stemcells: [{alias: default, url: file:///stemcell.tgz}]
+instance_groups:
+- name: bosh
+  vm_type: default
+  stemcell: default
+  azs: [missing]
+`), "fake-sha")
+
+				_, err := parser.Parse(interpolatedTemplate, manifestPath)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Could not find az 'missing' for instance group 'bosh'"))
+			})
+
+			It("returns an error for more than one az", func() {
+				interpolatedTemplate = bidepltpl.NewInterpolatedTemplate([]byte(`
+---
+vm_types: [{name: default}]
+stemcells: [{alias: default, url: file:///stemcell.tgz}]
+azs: [{name: z1}, {name: z2}]
+instance_groups:
+- name: bosh
+  vm_type: default
+  stemcell: default
+  azs: [z1, z2]
+`), "fake-sha")
+
+				_, err := parser.Parse(interpolatedTemplate, manifestPath)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Instance group 'bosh' must not specify more than one az"))
+			})
+		})
+
+		Context("when both resource_pools and vm_types are present in deployment manifest", func() {
+			BeforeEach(func() {
+				contents := `
+---
+resource_pools: [{name: default}]
+vm_types: [{name: default}]
+`
+				interpolatedTemplate = bidepltpl.NewInterpolatedTemplate([]byte(contents), "fake-sha")
+			})
+
+			It("throws an error", func() {
+				_, err := parser.Parse(interpolatedTemplate, manifestPath)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Deployment specifies both resource_pools and vm_types keys, only one is allowed"))
+			})
+		})
+
+		Context("when both disk_pools and disk_types are present in deployment manifest", func() {
+			BeforeEach(func() {
+				contents := `
+---
+disk_pools: [{name: default}]
+disk_types: [{name: default}]
+`
+				interpolatedTemplate = bidepltpl.NewInterpolatedTemplate([]byte(contents), "fake-sha")
+			})
+
+			It("throws an error", func() {
+				_, err := parser.Parse(interpolatedTemplate, manifestPath)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Deployment specifies both disk_pools and disk_types keys, only one is allowed"))
+			})
+		})
+
 		Context("when both instance_groups and jobs are present at root level in deployment manifest", func() {
 			BeforeEach(func() {
 				contents := `
@@ -87,6 +87,22 @@ func (v *validator) Validate(deploymentManifest Manifest, releaseSetManifest bir
 				errs = append(errs, bosherr.Errorf("jobs[%d].persistent_disk_pool must be the name of a disk pool", idx))
 			}
 		}
+		if len(job.PersistentDisks) > 0 && (job.PersistentDisk > 0 || job.PersistentDiskPool != "") {
+			errs = append(errs, bosherr.Errorf("jobs[%d].persistent_disks cannot be combined with persistent_disk or persistent_disk_pool", idx))
+		}
+		persistentDiskNames := map[string]struct{}{}
+		for diskIdx, persistentDisk := range job.PersistentDisks {
+			if v.isBlank(persistentDisk.Name) {
+				errs = append(errs, bosherr.Errorf("jobs[%d].persistent_disks[%d].name must be provided", idx, diskIdx))
+			} else if _, found := persistentDiskNames[persistentDisk.Name]; found {
+				errs = append(errs, bosherr.Errorf("jobs[%d].persistent_disks[%d].name '%s' must be unique", idx, diskIdx, persistentDisk.Name))
+			}
+			persistentDiskNames[persistentDisk.Name] = struct{}{}
+
+			if _, ok := v.diskPoolNames(deploymentManifest)[persistentDisk.DiskPool]; !ok {
+				errs = append(errs, bosherr.Errorf("jobs[%d].persistent_disks[%d].type must be the name of a disk type", idx, diskIdx))
+			}
+		}
 		if job.Instances < 0 {
 			errs = append(errs, bosherr.Errorf("jobs[%d].instances must be >= 0", idx))
 		}
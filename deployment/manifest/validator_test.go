@@ -647,6 +647,33 @@ var _ = Describe("Validator", func() {
 			Expect(err.Error()).To(ContainSubstring("jobs[0].persistent_disk_pool must be the name of a disk pool"))
 		})
 
+		It("validates job persistent_disks", func() {
+			deploymentManifest := Manifest{
+				Jobs: []Job{
+					{
+						PersistentDisk: 1024,
+						PersistentDisks: []PersistentDisk{
+							{Name: "data", DiskPool: "fake-disk-pool"},
+							{Name: "data", DiskPool: "non-existent-disk-pool"},
+							{DiskPool: "fake-disk-pool"},
+						},
+					},
+				},
+				DiskPools: []DiskPool{
+					{
+						Name: "fake-disk-pool",
+					},
+				},
+			}
+
+			err := validator.Validate(deploymentManifest, validReleaseSetManifest)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("jobs[0].persistent_disks cannot be combined with persistent_disk or persistent_disk_pool"))
+			Expect(err.Error()).To(ContainSubstring("jobs[0].persistent_disks[1].name 'data' must be unique"))
+			Expect(err.Error()).To(ContainSubstring("jobs[0].persistent_disks[1].type must be the name of a disk type"))
+			Expect(err.Error()).To(ContainSubstring("jobs[0].persistent_disks[2].name must be provided"))
+		})
+
 		It("validates job resource pool is provided", func() {
 			deploymentManifest := Manifest{
 				Jobs: []Job{{}},
@@ -57,6 +57,20 @@ func (mr *MockDeploymentMockRecorder) Delete(arg0, arg1 interface{}) *gomock.Cal
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockDeployment)(nil).Delete), arg0, arg1)
 }
 
+// DeleteKeepingDisks mocks base method.
+func (m *MockDeployment) DeleteKeepingDisks(arg0 bool, arg1 ui.Stage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteKeepingDisks", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteKeepingDisks indicates an expected call of DeleteKeepingDisks.
+func (mr *MockDeploymentMockRecorder) DeleteKeepingDisks(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteKeepingDisks", reflect.TypeOf((*MockDeployment)(nil).DeleteKeepingDisks), arg0, arg1)
+}
+
 // Start mocks base method.
 func (m *MockDeployment) Start(arg0 ui.Stage, arg1 manifest.Update) error {
 	m.ctrl.T.Helper()
@@ -0,0 +1,379 @@
+package deployment
+
+import (
+	"fmt"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+
+	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+)
+
+const (
+	StateProblemMissingCurrentDisk     = "missing-current-disk"
+	StateProblemMissingCurrentStemcell = "missing-current-stemcell"
+	StateProblemMissingCurrentRelease  = "missing-current-release"
+	StateProblemDuplicateDisk          = "duplicate-disk"
+	StateProblemDuplicateStemcell      = "duplicate-stemcell"
+	StateProblemMissingVM              = "missing-vm"
+	StateProblemMissingDisk            = "missing-disk"
+)
+
+type StateProblem struct {
+	Kind        string
+	Resource    string
+	ID          string
+	Description string
+	Repair      string
+
+	diskRecord     biconfig.DiskRecord
+	stemcellRecord biconfig.StemcellRecord
+}
+
+func (p StateProblem) Repairable() bool {
+	return p.Repair != ""
+}
+
+// StateChecker finds records in the deployment state that violate its invariants
+// and repairs them through the same repos that create-env uses
+type StateChecker interface {
+	// Check validates the deployment state. The cloud is optional; when given
+	// it is asked whether the current VM and the recorded disks still exist.
+	Check(cloud bicloud.Cloud) ([]StateProblem, error)
+	Repair(problems []StateProblem) error
+}
+
+type stateChecker struct {
+	deploymentStateService biconfig.DeploymentStateService
+	stemcellRepo           biconfig.StemcellRepo
+	diskRepo               biconfig.DiskRepo
+	vmRepo                 biconfig.VMRepo
+	releaseRepo            biconfig.ReleaseRepo
+}
+
+func NewStateChecker(
+	deploymentStateService biconfig.DeploymentStateService,
+	stemcellRepo biconfig.StemcellRepo,
+	diskRepo biconfig.DiskRepo,
+	vmRepo biconfig.VMRepo,
+	releaseRepo biconfig.ReleaseRepo,
+) StateChecker {
+	return &stateChecker{
+		deploymentStateService: deploymentStateService,
+		stemcellRepo:           stemcellRepo,
+		diskRepo:               diskRepo,
+		vmRepo:                 vmRepo,
+		releaseRepo:            releaseRepo,
+	}
+}
+
+func (c *stateChecker) Check(cloud bicloud.Cloud) ([]StateProblem, error) {
+	problems := []StateProblem{}
+
+	deploymentState, err := c.deploymentStateService.Load()
+	if err != nil {
+		return problems, bosherr.WrapError(err, "Loading deployment state")
+	}
+
+	diskProblems, err := c.checkDisks(deploymentState.CurrentDiskIDs)
+	if err != nil {
+		return problems, err
+	}
+	problems = append(problems, diskProblems...)
+
+	stemcellProblems, err := c.checkStemcells(deploymentState.CurrentStemcellID)
+	if err != nil {
+		return problems, err
+	}
+	problems = append(problems, stemcellProblems...)
+
+	releaseProblems, err := c.checkReleases(deploymentState.CurrentReleaseIDs)
+	if err != nil {
+		return problems, err
+	}
+	problems = append(problems, releaseProblems...)
+
+	if cloud != nil {
+		vmProblems, err := c.checkVM(cloud)
+		if err != nil {
+			return problems, err
+		}
+		problems = append(problems, vmProblems...)
+
+		cloudDiskProblems, err := c.checkCloudDisks(cloud)
+		if err != nil {
+			return problems, err
+		}
+		problems = append(problems, cloudDiskProblems...)
+	}
+
+	return problems, nil
+}
+
+func (c *stateChecker) checkDisks(currentDiskIDs []string) ([]StateProblem, error) {
+	var problems []StateProblem
+
+	records, err := c.diskRepo.All()
+	if err != nil {
+		return problems, bosherr.WrapError(err, "Finding disk records")
+	}
+
+	currentIDs := map[string]bool{}
+
+	for _, currentDiskID := range currentDiskIDs {
+		currentIDs[currentDiskID] = true
+
+		found := false
+		for _, record := range records {
+			if record.ID == currentDiskID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			problems = append(problems, StateProblem{
+				Kind:        StateProblemMissingCurrentDisk,
+				Resource:    "disk",
+				ID:          currentDiskID,
+				Description: "Current disk does not reference a disk record",
+				Repair:      "Clear current disk",
+			})
+		}
+	}
+
+	// keep the current record, otherwise the first one recorded
+	keptIDs := map[string]string{}
+	for _, record := range records {
+		if _, found := keptIDs[record.CID]; !found || currentIDs[record.ID] {
+			keptIDs[record.CID] = record.ID
+		}
+	}
+
+	kept := map[string]bool{}
+
+	for _, record := range records {
+		if record.ID == keptIDs[record.CID] && !kept[record.CID] {
+			kept[record.CID] = true
+			continue
+		}
+
+		problem := StateProblem{
+			Kind:        StateProblemDuplicateDisk,
+			Resource:    "disk",
+			ID:          record.ID,
+			Description: fmt.Sprintf("Disk CID '%s' is recorded more than once", record.CID),
+			diskRecord:  record,
+		}
+
+		// records are deleted by id which would also delete the kept record
+		if record.ID != keptIDs[record.CID] {
+			problem.Repair = "Delete duplicate disk record"
+		}
+
+		problems = append(problems, problem)
+	}
+
+	return problems, nil
+}
+
+func (c *stateChecker) checkStemcells(currentStemcellID string) ([]StateProblem, error) {
+	var problems []StateProblem
+
+	records, err := c.stemcellRepo.All()
+	if err != nil {
+		return problems, bosherr.WrapError(err, "Finding stemcell records")
+	}
+
+	if currentStemcellID != "" {
+		_, found, err := c.stemcellRepo.FindCurrent()
+		if err != nil {
+			return problems, bosherr.WrapError(err, "Finding current stemcell record")
+		}
+
+		if !found {
+			problems = append(problems, StateProblem{
+				Kind:        StateProblemMissingCurrentStemcell,
+				Resource:    "stemcell",
+				ID:          currentStemcellID,
+				Description: "Current stemcell does not reference a stemcell record",
+				Repair:      "Clear current stemcell",
+			})
+		}
+	}
+
+	keptIDs := map[string]string{}
+	for _, record := range records {
+		key := record.Name + "/" + record.Version
+		if _, found := keptIDs[key]; !found || record.ID == currentStemcellID {
+			keptIDs[key] = record.ID
+		}
+	}
+
+	kept := map[string]bool{}
+
+	for _, record := range records {
+		key := record.Name + "/" + record.Version
+
+		if record.ID == keptIDs[key] && !kept[key] {
+			kept[key] = true
+			continue
+		}
+
+		problem := StateProblem{
+			Kind:           StateProblemDuplicateStemcell,
+			Resource:       "stemcell",
+			ID:             record.ID,
+			Description:    fmt.Sprintf("Stemcell '%s' is recorded more than once (CID '%s')", key, record.CID),
+			stemcellRecord: record,
+		}
+
+		if record.ID != keptIDs[key] {
+			problem.Repair = "Delete duplicate stemcell record"
+		}
+
+		problems = append(problems, problem)
+	}
+
+	return problems, nil
+}
+
+func (c *stateChecker) checkReleases(currentReleaseIDs []string) ([]StateProblem, error) {
+	var problems []StateProblem
+
+	records, err := c.releaseRepo.List()
+	if err != nil {
+		return problems, bosherr.WrapError(err, "Finding release records")
+	}
+
+	for _, releaseID := range currentReleaseIDs {
+		found := false
+		for _, record := range records {
+			if record.ID == releaseID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			// releases are only ever replaced as a whole by create-env
+			problems = append(problems, StateProblem{
+				Kind:        StateProblemMissingCurrentRelease,
+				Resource:    "release",
+				ID:          releaseID,
+				Description: "Current release does not reference a release record; run create-env to record releases again",
+			})
+		}
+	}
+
+	return problems, nil
+}
+
+func (c *stateChecker) checkVM(cloud bicloud.Cloud) ([]StateProblem, error) {
+	var problems []StateProblem
+
+	vmCID, found, err := c.vmRepo.FindCurrent()
+	if err != nil {
+		return problems, bosherr.WrapError(err, "Finding current VM")
+	}
+
+	if !found {
+		return problems, nil
+	}
+
+	exists, err := cloud.HasVM(vmCID)
+	if err != nil {
+		return problems, bosherr.WrapErrorf(err, "Checking existence of VM '%s'", vmCID)
+	}
+
+	if !exists {
+		problems = append(problems, StateProblem{
+			Kind:        StateProblemMissingVM,
+			Resource:    "vm",
+			ID:          vmCID,
+			Description: "Current VM does not exist in the IaaS",
+			Repair:      "Clear current VM",
+		})
+	}
+
+	return problems, nil
+}
+
+func (c *stateChecker) checkCloudDisks(cloud bicloud.Cloud) ([]StateProblem, error) {
+	var problems []StateProblem
+
+	records, err := c.diskRepo.All()
+	if err != nil {
+		return problems, bosherr.WrapError(err, "Finding disk records")
+	}
+
+	// duplicate records share the disk so it is only asked for once
+	existingCIDs := map[string]bool{}
+
+	for _, record := range records {
+		exists, checked := existingCIDs[record.CID]
+		if !checked {
+			exists, err = cloud.HasDisk(record.CID)
+			if err != nil {
+				return problems, bosherr.WrapErrorf(err, "Checking existence of disk '%s'", record.CID)
+			}
+
+			existingCIDs[record.CID] = exists
+		}
+
+		if !exists {
+			problems = append(problems, StateProblem{
+				Kind:        StateProblemMissingDisk,
+				Resource:    "disk",
+				ID:          record.ID,
+				Description: fmt.Sprintf("Disk CID '%s' does not exist in the IaaS", record.CID),
+				Repair:      "Delete disk record",
+				diskRecord:  record,
+			})
+		}
+	}
+
+	return problems, nil
+}
+
+func (c *stateChecker) Repair(problems []StateProblem) error {
+	for _, problem := range problems {
+		var err error
+
+		switch problem.Kind {
+		case StateProblemMissingCurrentDisk:
+			err = c.clearMissingCurrentDisks()
+		case StateProblemMissingCurrentStemcell:
+			err = c.stemcellRepo.ClearCurrent()
+		case StateProblemDuplicateDisk, StateProblemMissingDisk:
+			err = c.diskRepo.Delete(problem.diskRecord)
+		case StateProblemDuplicateStemcell:
+			err = c.stemcellRepo.Delete(problem.stemcellRecord)
+		case StateProblemMissingVM:
+			err = c.vmRepo.ClearCurrent()
+		default:
+			continue
+		}
+
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Repairing %s '%s'", problem.Resource, problem.ID)
+		}
+	}
+
+	return nil
+}
+
+// clearMissingCurrentDisks keeps the current disks that still reference a disk record
+func (c *stateChecker) clearMissingCurrentDisks() error {
+	records, err := c.diskRepo.FindCurrent()
+	if err != nil {
+		return err
+	}
+
+	diskIDs := []string{}
+	for _, record := range records {
+		diskIDs = append(diskIDs, record.ID)
+	}
+
+	return c.diskRepo.UpdateCurrent(diskIDs)
+}
@@ -0,0 +1,237 @@
+package deployment_test
+
+import (
+	"errors"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	fakeuuid "github.com/cloudfoundry/bosh-utils/uuid/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	fakebicloud "github.com/cloudfoundry/bosh-cli/v7/cloud/fakes"
+	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
+	. "github.com/cloudfoundry/bosh-cli/v7/deployment"
+)
+
+var _ = Describe("StateChecker", func() {
+	var (
+		fakeCloud              *fakebicloud.FakeCloud
+		deploymentStateService biconfig.DeploymentStateService
+		checker                StateChecker
+	)
+
+	BeforeEach(func() {
+		fs := fakesys.NewFakeFileSystem()
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		uuidGenerator := fakeuuid.NewFakeGenerator()
+		deploymentStateService = biconfig.NewFileSystemDeploymentStateService(fs, uuidGenerator, logger, "/fake-state.json")
+
+		checker = NewStateChecker(
+			deploymentStateService,
+			biconfig.NewStemcellRepo(deploymentStateService, uuidGenerator),
+			biconfig.NewDiskRepo(deploymentStateService, uuidGenerator),
+			biconfig.NewVMRepo(deploymentStateService),
+			biconfig.NewReleaseRepo(deploymentStateService, uuidGenerator),
+		)
+
+		fakeCloud = fakebicloud.NewFakeCloud()
+	})
+
+	saveState := func(deploymentState biconfig.DeploymentState) {
+		deploymentState.DirectorID = "fake-director-id"
+		err := deploymentStateService.Save(deploymentState)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	kinds := func(problems []StateProblem) []string {
+		var result []string
+		for _, problem := range problems {
+			result = append(result, problem.Kind)
+		}
+		return result
+	}
+
+	Describe("Check", func() {
+		It("finds no problems in a consistent state", func() {
+			saveState(biconfig.DeploymentState{
+				CurrentVMCID:      "fake-vm-cid",
+				CurrentDiskID:     "fake-disk-id",
+				CurrentStemcellID: "fake-stemcell-id",
+				CurrentReleaseIDs: []string{"fake-release-id"},
+				Disks:             []biconfig.DiskRecord{{ID: "fake-disk-id", CID: "fake-disk-cid"}},
+				Stemcells:         []biconfig.StemcellRecord{{ID: "fake-stemcell-id", Name: "fake-name", Version: "1", CID: "fake-stemcell-cid"}},
+				Releases:          []biconfig.ReleaseRecord{{ID: "fake-release-id", Name: "fake-release", Version: "1"}},
+			})
+
+			problems, err := checker.Check(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(problems).To(BeEmpty())
+		})
+
+		It("finds current pointers that do not reference a record", func() {
+			saveState(biconfig.DeploymentState{
+				CurrentDiskID:     "missing-disk-id",
+				CurrentStemcellID: "missing-stemcell-id",
+				CurrentReleaseIDs: []string{"missing-release-id"},
+			})
+
+			problems, err := checker.Check(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(kinds(problems)).To(Equal([]string{
+				StateProblemMissingCurrentDisk,
+				StateProblemMissingCurrentStemcell,
+				StateProblemMissingCurrentRelease,
+			}))
+			Expect(problems[2].Repairable()).To(BeFalse())
+		})
+
+		It("finds duplicate records and keeps the current one", func() {
+			saveState(biconfig.DeploymentState{
+				CurrentStemcellID: "second-stemcell-id",
+				Stemcells: []biconfig.StemcellRecord{
+					{ID: "first-stemcell-id", Name: "fake-name", Version: "1", CID: "first-cid"},
+					{ID: "second-stemcell-id", Name: "fake-name", Version: "1", CID: "second-cid"},
+				},
+				Disks: []biconfig.DiskRecord{
+					{ID: "first-disk-id", CID: "fake-disk-cid"},
+					{ID: "second-disk-id", CID: "fake-disk-cid"},
+				},
+			})
+
+			problems, err := checker.Check(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(kinds(problems)).To(Equal([]string{StateProblemDuplicateDisk, StateProblemDuplicateStemcell}))
+			Expect(problems[0].ID).To(Equal("second-disk-id"))
+			Expect(problems[1].ID).To(Equal("first-stemcell-id"))
+		})
+
+		Context("when a cloud is given", func() {
+			BeforeEach(func() {
+				saveState(biconfig.DeploymentState{CurrentVMCID: "fake-vm-cid"})
+			})
+
+			It("finds a current VM that no longer exists", func() {
+				fakeCloud.HasVMFound = false
+
+				problems, err := checker.Check(fakeCloud)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(kinds(problems)).To(Equal([]string{StateProblemMissingVM}))
+				Expect(fakeCloud.HasVMInput).To(Equal(fakebicloud.HasVMInput{VMCID: "fake-vm-cid"}))
+			})
+
+			It("returns an error when the cloud cannot be asked", func() {
+				fakeCloud.HasVMErr = errors.New("fake-has-vm-error")
+
+				_, err := checker.Check(fakeCloud)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-has-vm-error"))
+			})
+
+			It("finds disk records whose disk no longer exists", func() {
+				fakeCloud.HasVMFound = true
+				fakeCloud.HasDiskFound = false
+				saveState(biconfig.DeploymentState{
+					CurrentVMCID:   "fake-vm-cid",
+					CurrentDiskIDs: []string{"fake-disk-id"},
+					Disks:          []biconfig.DiskRecord{{ID: "fake-disk-id", CID: "fake-disk-cid"}},
+				})
+
+				problems, err := checker.Check(fakeCloud)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(kinds(problems)).To(Equal([]string{StateProblemMissingDisk}))
+				Expect(problems[0].ID).To(Equal("fake-disk-id"))
+				Expect(fakeCloud.HasDiskInput).To(Equal(fakebicloud.HasDiskInput{DiskCID: "fake-disk-cid"}))
+
+				err = checker.Repair(problems)
+				Expect(err).ToNot(HaveOccurred())
+
+				deploymentState, err := deploymentStateService.Load()
+				Expect(err).ToNot(HaveOccurred())
+				Expect(deploymentState.Disks).To(BeEmpty())
+				Expect(deploymentState.CurrentDiskIDs).To(BeEmpty())
+			})
+
+			It("finds no problems when the disks exist", func() {
+				fakeCloud.HasVMFound = true
+				fakeCloud.HasDiskFound = true
+				saveState(biconfig.DeploymentState{
+					CurrentVMCID:   "fake-vm-cid",
+					CurrentDiskIDs: []string{"fake-disk-id"},
+					Disks:          []biconfig.DiskRecord{{ID: "fake-disk-id", CID: "fake-disk-cid"}},
+				})
+
+				problems, err := checker.Check(fakeCloud)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(problems).To(BeEmpty())
+			})
+
+			It("returns an error when the cloud cannot be asked for a disk", func() {
+				fakeCloud.HasVMFound = true
+				fakeCloud.HasDiskErr = errors.New("fake-has-disk-error")
+				saveState(biconfig.DeploymentState{
+					CurrentVMCID: "fake-vm-cid",
+					Disks:        []biconfig.DiskRecord{{ID: "fake-disk-id", CID: "fake-disk-cid"}},
+				})
+
+				_, err := checker.Check(fakeCloud)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("fake-has-disk-error"))
+			})
+		})
+	})
+
+	Describe("Repair", func() {
+		It("repairs problems through the repos", func() {
+			saveState(biconfig.DeploymentState{
+				CurrentVMCID:      "fake-vm-cid",
+				CurrentDiskID:     "missing-disk-id",
+				CurrentStemcellID: "second-stemcell-id",
+				Stemcells: []biconfig.StemcellRecord{
+					{ID: "first-stemcell-id", Name: "fake-name", Version: "1", CID: "first-cid"},
+					{ID: "second-stemcell-id", Name: "fake-name", Version: "1", CID: "second-cid"},
+				},
+			})
+
+			problems, err := checker.Check(fakeCloud)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(problems).To(HaveLen(3))
+
+			err = checker.Repair(problems)
+			Expect(err).ToNot(HaveOccurred())
+
+			deploymentState, err := deploymentStateService.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.CurrentVMCID).To(BeEmpty())
+			Expect(deploymentState.CurrentDiskID).To(BeEmpty())
+			Expect(deploymentState.CurrentStemcellID).To(Equal("second-stemcell-id"))
+			Expect(deploymentState.Stemcells).To(Equal([]biconfig.StemcellRecord{
+				{ID: "second-stemcell-id", Name: "fake-name", Version: "1", CID: "second-cid"},
+			}))
+
+			problems, err = checker.Check(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(problems).To(BeEmpty())
+		})
+
+		It("keeps the current disks that reference a record", func() {
+			saveState(biconfig.DeploymentState{
+				CurrentDiskIDs: []string{"missing-disk-id", "fake-disk-id"},
+				Disks:          []biconfig.DiskRecord{{ID: "fake-disk-id", CID: "fake-disk-cid", Name: "data"}},
+			})
+
+			problems, err := checker.Check(nil)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(kinds(problems)).To(Equal([]string{StateProblemMissingCurrentDisk}))
+			Expect(problems[0].ID).To(Equal("missing-disk-id"))
+
+			err = checker.Repair(problems)
+			Expect(err).ToNot(HaveOccurred())
+
+			deploymentState, err := deploymentStateService.Load()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(deploymentState.CurrentDiskIDs).To(Equal([]string{"fake-disk-id"}))
+			Expect(deploymentState.CurrentDiskID).To(Equal("fake-disk-id"))
+		})
+	})
+})
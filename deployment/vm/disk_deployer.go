@@ -15,7 +15,7 @@ import (
 
 // DiskDeployer is in the vm package to avoid a [disk -> vm -> disk] dependency cycle
 type DiskDeployer interface {
-	Deploy(diskPool bideplmanifest.DiskPool, cloud bicloud.Cloud, vm VM, eventLoggerStage biui.Stage) ([]bidisk.Disk, error)
+	Deploy(diskPools []bideplmanifest.NamedDiskPool, cloud bicloud.Cloud, vm VM, eventLoggerStage biui.Stage) ([]bidisk.Disk, error)
 }
 
 type diskDeployer struct {
@@ -37,28 +37,78 @@ func NewDiskDeployer(diskManagerFactory bidisk.ManagerFactory, diskRepo biconfig
 	}
 }
 
-func (d *diskDeployer) Deploy(diskPool bideplmanifest.DiskPool, cloud bicloud.Cloud, vm VM, stage biui.Stage) ([]bidisk.Disk, error) {
-	if diskPool.DiskSize == 0 {
-		return []bidisk.Disk{}, nil
+// Deploy attaches, migrates or creates a disk for each disk pool in order.
+// Only the disk of the first disk pool is mounted and migrated by the agent;
+// the other disks are attached for the jobs to mount. Since their contents
+// cannot be migrated, changing their disk pool or recreating them fails
+// before any disk is changed.
+// Current disks that no longer match a disk pool are detached and deleted.
+func (d *diskDeployer) Deploy(diskPools []bideplmanifest.NamedDiskPool, cloud bicloud.Cloud, vm VM, stage biui.Stage) ([]bidisk.Disk, error) {
+	disks := []bidisk.Disk{}
+
+	if len(diskPools) == 0 {
+		return disks, nil
+	}
+
+	if d.recreatePersistentDisk && len(diskPools) > 1 {
+		return disks, bosherr.Error("Recreating persistent disks is only supported with a single persistent disk")
 	}
 
 	d.diskManager = d.diskManagerFactory.NewManager(cloud)
-	disks, err := d.diskManager.FindCurrent()
+	unmatchedDisks, err := d.diskManager.FindCurrent()
 	if err != nil {
 		return disks, bosherr.WrapError(err, "Finding existing disk")
 	}
 
-	if len(disks) > 1 {
-		return disks, bosherr.WrapError(err, "Multiple current disks not supported")
+	// all disk pools are checked before any disk is changed
+	matchedDisks := make([]bidisk.Disk, len(diskPools))
+	migrateFirstDisk := false
+
+	for i, diskPool := range diskPools {
+		var disk bidisk.Disk
+		disk, unmatchedDisks = bidisk.MatchCurrentDisk(unmatchedDisks, diskPool.Name, i == 0)
+		if disk == nil {
+			continue
+		}
+
+		diskNeedsMigration, err := disk.NeedsMigration(diskPool.DiskPool.DiskSize, diskPool.DiskPool.CloudProperties)
+		if err != nil {
+			return disks, err
+		}
+
+		if i == 0 {
+			migrateFirstDisk = d.recreatePersistentDisk || diskNeedsMigration
+		} else if diskNeedsMigration {
+			return disks, bosherr.Errorf(
+				"Changing the size or cloud properties of persistent disk '%s' (disk '%s') is not supported because the agent only migrates the first persistent disk",
+				diskPool.Name, disk.CID(),
+			)
+		}
+
+		matchedDisks[i] = disk
+	}
+
+	for i, diskPool := range diskPools {
+		disk := matchedDisks[i]
+		mount := i == 0
 
-	} else if len(disks) == 1 {
-		disks, err = d.deployExistingDisk(disks[0], diskPool, vm, stage)
+		// disks of later disk pools and unmatched disks stay current until they are deployed or orphaned
+		pendingDisks := pendingDisks(matchedDisks[i+1:], unmatchedDisks)
+
+		if disk != nil {
+			disk, err = d.deployExistingDisk(disk, diskPool, mount, mount && migrateFirstDisk, disks, pendingDisks, vm, stage)
+		} else {
+			disk, err = d.deployNewDisk(diskPool, mount, disks, pendingDisks, vm, stage)
+		}
 		if err != nil {
 			return disks, err
 		}
 
-	} else {
-		disks, err = d.deployNewDisk(diskPool, vm, stage)
+		disks = append(disks, disk)
+	}
+
+	if len(unmatchedDisks) > 0 {
+		err = d.orphanDisks(unmatchedDisks, disks, vm, stage)
 		if err != nil {
 			return disks, err
 		}
@@ -72,69 +122,54 @@ func (d *diskDeployer) Deploy(diskPool bideplmanifest.DiskPool, cloud bicloud.Cl
 	return disks, nil
 }
 
-func (d *diskDeployer) deployExistingDisk(disk bidisk.Disk, diskPool bideplmanifest.DiskPool, vm VM, stage biui.Stage) ([]bidisk.Disk, error) {
-	disks := []bidisk.Disk{}
-
+func (d *diskDeployer) deployExistingDisk(disk bidisk.Disk, diskPool bideplmanifest.NamedDiskPool, mount bool, migrate bool, deployedDisks []bidisk.Disk, pendingDisks []bidisk.Disk, vm VM, stage biui.Stage) (bidisk.Disk, error) {
 	// the disk is already part of the deployment, and should already be attached
-	disks = append(disks, disk)
-
 	// attach is idempotent
-	err := d.attachDisk(disk, vm, stage)
+	err := d.attachDisk(disk, mount, vm, stage)
 	if err != nil {
-		return disks, err
+		return disk, err
 	}
 
-	diskNeedsMigration, err := disk.NeedsMigration(diskPool.DiskSize, diskPool.CloudProperties)
-	if err != nil {
-		return disks, err
-	}
-	if d.recreatePersistentDisk || diskNeedsMigration {
-		disk, err = d.migrateDisk(disk, diskPool, vm, stage)
-		if err != nil {
-			return disks, err
-		}
-
+	if migrate {
 		// after migration, only the new disk is part of the deployment
-		disks[0] = disk
+		return d.migrateDisk(disk, diskPool, deployedDisks, pendingDisks, vm, stage)
 	}
 
-	return disks, nil
+	return disk, nil
 }
 
-func (d *diskDeployer) deployNewDisk(diskPool bideplmanifest.DiskPool, vm VM, stage biui.Stage) ([]bidisk.Disk, error) {
-	disks := []bidisk.Disk{}
-
+func (d *diskDeployer) deployNewDisk(diskPool bideplmanifest.NamedDiskPool, mount bool, deployedDisks []bidisk.Disk, pendingDisks []bidisk.Disk, vm VM, stage biui.Stage) (bidisk.Disk, error) {
 	disk, err := d.createDisk(diskPool, vm, stage)
 	if err != nil {
-		return disks, err
+		return nil, err
 	}
 
-	err = d.attachDisk(disk, vm, stage)
+	err = d.attachDisk(disk, mount, vm, stage)
 	if err != nil {
-		return disks, err
+		return nil, err
 	}
 
 	// once attached, the disk is part of the deployment
-	disks = append(disks, disk)
-
-	err = d.updateCurrentDiskRecord(disk)
+	err = d.updateCurrentDiskRecords(currentDisks(deployedDisks, disk, pendingDisks))
 	if err != nil {
-		return disks, err
+		return disk, err
 	}
 
-	return disks, nil
+	return disk, nil
 }
 
 func (d *diskDeployer) migrateDisk(
 	originalDisk bidisk.Disk,
-	diskPool bideplmanifest.DiskPool,
+	diskPool bideplmanifest.NamedDiskPool,
+	deployedDisks []bidisk.Disk,
+	pendingDisks []bidisk.Disk,
 	vm VM,
 	stage biui.Stage,
 ) (newDisk bidisk.Disk, err error) {
 	d.logger.Debug(d.logTag, "Migrating disk '%s'", originalDisk.CID())
 
 	err = stage.Perform("Creating disk", func() error {
-		newDisk, err = d.diskManager.Create(diskPool, vm.CID())
+		newDisk, err = d.diskManager.Create(diskPool.Name, diskPool.DiskPool, vm.CID())
 		return err
 	})
 	if err != nil {
@@ -157,7 +192,7 @@ func (d *diskDeployer) migrateDisk(
 		return newDisk, err
 	}
 
-	err = d.updateCurrentDiskRecord(newDisk)
+	err = d.updateCurrentDiskRecords(currentDisks(deployedDisks, newDisk, pendingDisks))
 	if err != nil {
 		return newDisk, err
 	}
@@ -181,17 +216,56 @@ func (d *diskDeployer) migrateDisk(
 	return newDisk, nil
 }
 
-func (d *diskDeployer) updateCurrentDiskRecord(disk bidisk.Disk) error {
-	savedDiskRecord, found, err := d.diskRepo.Find(disk.CID())
-	if err != nil {
-		return bosherr.WrapError(err, "Finding disk record")
+// orphanDisks detaches the disks of persistent disks removed from the manifest
+// so that they get deleted with the other unused disks
+func (d *diskDeployer) orphanDisks(orphanedDisks []bidisk.Disk, disks []bidisk.Disk, vm VM, stage biui.Stage) error {
+	for _, disk := range orphanedDisks {
+		stageName := fmt.Sprintf("Detaching disk '%s'", disk.CID())
+		err := stage.Perform(stageName, func() error {
+			return vm.DetachDisk(disk)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return d.updateCurrentDiskRecords(disks)
+}
+
+func pendingDisks(laterMatchedDisks []bidisk.Disk, unmatchedDisks []bidisk.Disk) []bidisk.Disk {
+	disks := []bidisk.Disk{}
+	for _, disk := range laterMatchedDisks {
+		if disk != nil {
+			disks = append(disks, disk)
+		}
 	}
+	return append(disks, unmatchedDisks...)
+}
+
+// currentDisks keeps the disks that are not deployed yet current until they are migrated or orphaned
+func currentDisks(deployedDisks []bidisk.Disk, disk bidisk.Disk, pendingDisks []bidisk.Disk) []bidisk.Disk {
+	disks := append([]bidisk.Disk{}, deployedDisks...)
+	disks = append(disks, disk)
+	return append(disks, pendingDisks...)
+}
 
-	if !found {
-		return bosherr.Error("Failed to find disk record for new disk")
+func (d *diskDeployer) updateCurrentDiskRecords(disks []bidisk.Disk) error {
+	diskIDs := []string{}
+
+	for _, disk := range disks {
+		savedDiskRecord, found, err := d.diskRepo.Find(disk.CID())
+		if err != nil {
+			return bosherr.WrapError(err, "Finding disk record")
+		}
+
+		if !found {
+			return bosherr.Errorf("Failed to find disk record for disk '%s'", disk.CID())
+		}
+
+		diskIDs = append(diskIDs, savedDiskRecord.ID)
 	}
 
-	err = d.diskRepo.UpdateCurrent(savedDiskRecord.ID)
+	err := d.diskRepo.UpdateCurrent(diskIDs)
 	if err != nil {
 		return bosherr.WrapError(err, "Updating current disk record")
 	}
@@ -199,18 +273,21 @@ func (d *diskDeployer) updateCurrentDiskRecord(disk bidisk.Disk) error {
 	return nil
 }
 
-func (d *diskDeployer) createDisk(diskPool bideplmanifest.DiskPool, vm VM, stage biui.Stage) (disk bidisk.Disk, err error) {
+func (d *diskDeployer) createDisk(diskPool bideplmanifest.NamedDiskPool, vm VM, stage biui.Stage) (disk bidisk.Disk, err error) {
 	err = stage.Perform("Creating disk", func() error {
-		disk, err = d.diskManager.Create(diskPool, vm.CID())
+		disk, err = d.diskManager.Create(diskPool.Name, diskPool.DiskPool, vm.CID())
 		return err
 	})
 
 	return disk, err
 }
 
-func (d *diskDeployer) attachDisk(disk bidisk.Disk, vm VM, stage biui.Stage) error {
+func (d *diskDeployer) attachDisk(disk bidisk.Disk, mount bool, vm VM, stage biui.Stage) error {
 	stageName := fmt.Sprintf("Attaching disk '%s' to VM '%s'", disk.CID(), vm.CID())
 	err := stage.Perform(stageName, func() error {
+		if !mount {
+			return vm.AttachDiskWithoutMounting(disk)
+		}
 		return vm.AttachDisk(disk)
 	})
 
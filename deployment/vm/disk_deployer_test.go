@@ -85,7 +85,7 @@ var _ = Describe("DiskDeployer", func() {
 			})
 
 			It("does not create primary disk", func() {
-				disks, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+				disks, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(fakeDiskManager.CreateInputs).To(BeEmpty())
@@ -98,7 +98,7 @@ var _ = Describe("DiskDeployer", func() {
 				})
 
 				It("does not log the create disk event", func() {
-					disks, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+					disks, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(disks).To(Equal([]bidisk.Disk{existingDisk}))
 
@@ -130,7 +130,7 @@ var _ = Describe("DiskDeployer", func() {
 				})
 
 				It("creates secondary disk", func() {
-					disks, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+					disks, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(disks).To(Equal([]bidisk.Disk{secondaryDisk}))
 
@@ -147,7 +147,7 @@ var _ = Describe("DiskDeployer", func() {
 				})
 
 				It("attaches secondary disk", func() {
-					_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+					_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(fakeVM.AttachDiskInputs).To(Equal([]fakebivm.AttachDiskInput{
 						{Disk: existingDisk},
@@ -160,7 +160,7 @@ var _ = Describe("DiskDeployer", func() {
 				})
 
 				It("migrates from primary to secondary disk", func() {
-					_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+					_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(fakeVM.MigrateDiskCalledTimes).To(Equal(1))
 
@@ -170,7 +170,7 @@ var _ = Describe("DiskDeployer", func() {
 				})
 
 				It("detaches primary disk", func() {
-					_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+					_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(fakeVM.DetachDiskInputs).To(Equal([]fakebivm.DetachDiskInput{
 						{Disk: existingDisk},
@@ -182,13 +182,12 @@ var _ = Describe("DiskDeployer", func() {
 				})
 
 				It("promotes secondary disk as primary", func() {
-					_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+					_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 					Expect(err).NotTo(HaveOccurred())
 
 					// existing disk must be current until after migration
 					Expect(fakeDiskRepo.UpdateCurrentInputs).To(Equal([]fakebiconfig.DiskRepoUpdateCurrentInput{
-						//						{ DiskID: "fake-existing-disk-id" },
-						{DiskID: "fake-secondary-disk-id"},
+						{DiskIDs: []string{"fake-secondary-disk-id"}},
 					}))
 				})
 
@@ -198,7 +197,7 @@ var _ = Describe("DiskDeployer", func() {
 					})
 
 					It("returns error and leaves the existing disk attached", func() {
-						_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+						_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 						Expect(err).To(HaveOccurred())
 						Expect(err.Error()).To(ContainSubstring("fake-create-disk-error"))
 						Expect(fakeVM.DetachDiskInputs).To(Equal([]fakebivm.DetachDiskInput{}))
@@ -215,7 +214,7 @@ var _ = Describe("DiskDeployer", func() {
 					})
 
 					It("returns error and leaves the existing disk attached", func() {
-						_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+						_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 						Expect(err).To(HaveOccurred())
 						Expect(err.Error()).To(ContainSubstring("fake-attach-disk-error"))
 						Expect(fakeVM.DetachDiskInputs).To(Equal([]fakebivm.DetachDiskInput{}))
@@ -241,7 +240,7 @@ var _ = Describe("DiskDeployer", func() {
 					})
 
 					It("returns error", func() {
-						_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+						_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 						Expect(err).To(HaveOccurred())
 						Expect(err.Error()).To(ContainSubstring("fake-detach-disk-error"))
 
@@ -268,7 +267,7 @@ var _ = Describe("DiskDeployer", func() {
 					})
 
 					It("returns error and leaves the existing disk attached", func() {
-						_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+						_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 						Expect(err).To(HaveOccurred())
 						Expect(err.Error()).To(ContainSubstring("fake-migrate-disk-error"))
 						Expect(fakeVM.DetachDiskInputs).To(Equal([]fakebivm.DetachDiskInput{}))
@@ -302,7 +301,7 @@ var _ = Describe("DiskDeployer", func() {
 				})
 
 				It("creates secondary disk", func() {
-					disks, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+					disks, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(disks).To(Equal([]bidisk.Disk{secondaryDisk}))
 
@@ -319,7 +318,7 @@ var _ = Describe("DiskDeployer", func() {
 				})
 
 				It("attaches secondary disk", func() {
-					_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+					_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(fakeVM.AttachDiskInputs).To(Equal([]fakebivm.AttachDiskInput{
 						{Disk: existingDisk},
@@ -332,7 +331,7 @@ var _ = Describe("DiskDeployer", func() {
 				})
 
 				It("migrates from primary to secondary disk", func() {
-					_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+					_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 					Expect(err).ToNot(HaveOccurred())
 					Expect(fakeVM.MigrateDiskCalledTimes).To(Equal(1))
 
@@ -342,7 +341,7 @@ var _ = Describe("DiskDeployer", func() {
 				})
 
 				It("detaches primary disk", func() {
-					_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+					_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 					Expect(err).NotTo(HaveOccurred())
 					Expect(fakeVM.DetachDiskInputs).To(Equal([]fakebivm.DetachDiskInput{
 						{Disk: existingDisk},
@@ -354,13 +353,12 @@ var _ = Describe("DiskDeployer", func() {
 				})
 
 				It("promotes secondary disk as primary", func() {
-					_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+					_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 					Expect(err).NotTo(HaveOccurred())
 
 					// existing disk must be current until after migration
 					Expect(fakeDiskRepo.UpdateCurrentInputs).To(Equal([]fakebiconfig.DiskRepoUpdateCurrentInput{
-						//						{ DiskID: "fake-existing-disk-id" },
-						{DiskID: "fake-secondary-disk-id"},
+						{DiskIDs: []string{"fake-secondary-disk-id"}},
 					}))
 				})
 
@@ -370,7 +368,7 @@ var _ = Describe("DiskDeployer", func() {
 					})
 
 					It("returns error and leaves the existing disk attached", func() {
-						_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+						_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 						Expect(err).To(HaveOccurred())
 						Expect(err.Error()).To(ContainSubstring("fake-create-disk-error"))
 						Expect(fakeVM.DetachDiskInputs).To(Equal([]fakebivm.DetachDiskInput{}))
@@ -387,7 +385,7 @@ var _ = Describe("DiskDeployer", func() {
 					})
 
 					It("returns error and leaves the existing disk attached", func() {
-						_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+						_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 						Expect(err).To(HaveOccurred())
 						Expect(err.Error()).To(ContainSubstring("fake-attach-disk-error"))
 						Expect(fakeVM.DetachDiskInputs).To(Equal([]fakebivm.DetachDiskInput{}))
@@ -413,7 +411,7 @@ var _ = Describe("DiskDeployer", func() {
 					})
 
 					It("returns error", func() {
-						_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+						_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 						Expect(err).To(HaveOccurred())
 						Expect(err.Error()).To(ContainSubstring("fake-detach-disk-error"))
 
@@ -440,7 +438,7 @@ var _ = Describe("DiskDeployer", func() {
 					})
 
 					It("returns error and leaves the existing disk attached", func() {
-						_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+						_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 						Expect(err).To(HaveOccurred())
 						Expect(err.Error()).To(ContainSubstring("fake-migrate-disk-error"))
 						Expect(fakeVM.DetachDiskInputs).To(Equal([]fakebivm.DetachDiskInput{}))
@@ -461,7 +459,7 @@ var _ = Describe("DiskDeployer", func() {
 
 		Context("when disk does not exist", func() {
 			It("creates a persistent disk", func() {
-				disks, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+				disks, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 				Expect(err).NotTo(HaveOccurred())
 				Expect(disks).To(Equal([]bidisk.Disk{fakeDisk}))
 
@@ -474,16 +472,16 @@ var _ = Describe("DiskDeployer", func() {
 			})
 
 			It("sets the new disk as current", func() {
-				_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+				_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 				Expect(err).NotTo(HaveOccurred())
 
 				Expect(fakeDiskRepo.UpdateCurrentInputs).To(Equal([]fakebiconfig.DiskRepoUpdateCurrentInput{
-					{DiskID: "fake-new-disk-id"},
+					{DiskIDs: []string{"fake-new-disk-id"}},
 				}))
 			})
 
 			It("logs the create disk event", func() {
-				_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+				_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 				Expect(err).ToNot(HaveOccurred())
 
 				Expect(fakeStage.PerformCalls[0]).To(Equal(&fakebiui.PerformCall{
@@ -493,7 +491,7 @@ var _ = Describe("DiskDeployer", func() {
 		})
 
 		It("attaches the primary disk", func() {
-			_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+			_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(fakeVM.AttachDiskInputs).To(Equal([]fakebivm.AttachDiskInput{
 				{
@@ -503,7 +501,7 @@ var _ = Describe("DiskDeployer", func() {
 		})
 
 		It("logs attaching primary disk event", func() {
-			_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+			_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(fakeStage.PerformCalls).To(Equal([]*fakebiui.PerformCall{
@@ -513,7 +511,7 @@ var _ = Describe("DiskDeployer", func() {
 		})
 
 		It("removes unused disks", func() {
-			_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+			_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 			Expect(err).ToNot(HaveOccurred())
 
 			Expect(fakeDiskManager.DeleteUnusedCalledTimes).To(Equal(1))
@@ -525,7 +523,7 @@ var _ = Describe("DiskDeployer", func() {
 			})
 
 			It("returns an error", func() {
-				_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+				_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-delete-error"))
 			})
@@ -541,13 +539,13 @@ var _ = Describe("DiskDeployer", func() {
 			})
 
 			It("return an error", func() {
-				_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+				_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-create-disk-error"))
 			})
 
 			It("logs start and stop events to the eventLogger", func() {
-				_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+				_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 				Expect(err).To(HaveOccurred())
 
 				Expect(fakeStage.PerformCalls).To(Equal([]*fakebiui.PerformCall{
@@ -569,13 +567,13 @@ var _ = Describe("DiskDeployer", func() {
 			})
 
 			It("return an error", func() {
-				_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+				_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-attach-disk-error"))
 			})
 
 			It("logs start and failed events to the eventLogger", func() {
-				_, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+				_, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{{DiskPool: diskPool}}, cloud, fakeVM, fakeStage)
 				Expect(err).To(HaveOccurred())
 
 				Expect(fakeStage.PerformCalls).To(Equal([]*fakebiui.PerformCall{
@@ -589,13 +587,181 @@ var _ = Describe("DiskDeployer", func() {
 		})
 	})
 
-	Context("when the disk pool size is 0", func() {
+	Context("when there are multiple named disk pools", func() {
+		var (
+			diskPools []bideplmanifest.NamedDiskPool
+			dataDisk  *fakebidisk.FakeDisk
+		)
+
 		BeforeEach(func() {
-			diskPool = bideplmanifest.DiskPool{}
+			diskPools = []bideplmanifest.NamedDiskPool{
+				{Name: "data", DiskPool: bideplmanifest.DiskPool{Name: "large", DiskSize: 4096}},
+				{Name: "logs", DiskPool: bideplmanifest.DiskPool{Name: "small", DiskSize: 1024}},
+			}
+
+			dataDisk = fakebidisk.NewFakeNamedDisk("data", "fake-data-disk-cid")
+			fakeDiskRepo.SetFindBehavior("fake-data-disk-cid", biconfig.DiskRecord{ID: "fake-data-disk-id"}, true, nil)
+			fakeVM.SetAttachDiskBehavior(dataDisk, nil)
+		})
+
+		It("keeps the existing disks and creates the missing ones in order", func() {
+			fakeDiskManager.SetFindCurrentBehavior([]bidisk.Disk{dataDisk}, nil)
+
+			disks, err := diskDeployer.Deploy(diskPools, cloud, fakeVM, fakeStage)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(disks).To(Equal([]bidisk.Disk{dataDisk, fakeDisk}))
+
+			Expect(fakeDiskManager.CreateInputs).To(Equal([]fakebidisk.CreateInput{
+				{Name: "logs", DiskPool: diskPools[1].DiskPool, InstanceID: "fake-vm-cid"},
+			}))
+			Expect(fakeDiskRepo.UpdateCurrentInputs).To(Equal([]fakebiconfig.DiskRepoUpdateCurrentInput{
+				{DiskIDs: []string{"fake-data-disk-id", "fake-new-disk-id"}},
+			}))
+		})
+
+		It("mounts only the disk of the first disk pool", func() {
+			fakeDiskManager.SetFindCurrentBehavior([]bidisk.Disk{dataDisk}, nil)
+
+			_, err := diskDeployer.Deploy(diskPools, cloud, fakeVM, fakeStage)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeVM.AttachDiskInputs).To(Equal([]fakebivm.AttachDiskInput{
+				{Disk: dataDisk},
+				{Disk: fakeDisk, WithoutMounting: true},
+			}))
+			Expect(fakeVM.MigrateDiskCalledTimes).To(Equal(0))
+		})
+
+		Context("when the disk of the first disk pool needs migration", func() {
+			var logsDisk *fakebidisk.FakeDisk
+
+			BeforeEach(func() {
+				dataDisk.SetNeedsMigrationBehavior(true)
+
+				logsDisk = fakebidisk.NewFakeNamedDisk("logs", "fake-logs-disk-cid")
+				fakeDiskRepo.SetFindBehavior("fake-logs-disk-cid", biconfig.DiskRecord{ID: "fake-logs-disk-id"}, true, nil)
+				fakeVM.SetAttachDiskBehavior(logsDisk, nil)
+				fakeDiskManager.SetFindCurrentBehavior([]bidisk.Disk{dataDisk, logsDisk}, nil)
+			})
+
+			It("keeps the disk of the other disk pool current", func() {
+				disks, err := diskDeployer.Deploy(diskPools, cloud, fakeVM, fakeStage)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(disks).To(Equal([]bidisk.Disk{fakeDisk, logsDisk}))
+
+				Expect(fakeVM.MigrateDiskCalledTimes).To(Equal(1))
+				Expect(fakeDiskRepo.UpdateCurrentInputs).To(Equal([]fakebiconfig.DiskRepoUpdateCurrentInput{
+					{DiskIDs: []string{"fake-new-disk-id", "fake-logs-disk-id"}},
+				}))
+				Expect(fakeVM.DetachDiskInputs).To(Equal([]fakebivm.DetachDiskInput{
+					{Disk: dataDisk},
+				}))
+				Expect(logsDisk.DeleteCalledTimes).To(Equal(0))
+				Expect(fakeDiskManager.DeleteUnusedCalledTimes).To(Equal(1))
+			})
+		})
+
+		It("keeps the disk of another disk pool current when the disk of the first disk pool is created", func() {
+			logsDisk := fakebidisk.NewFakeNamedDisk("logs", "fake-logs-disk-cid")
+			fakeDiskRepo.SetFindBehavior("fake-logs-disk-cid", biconfig.DiskRecord{ID: "fake-logs-disk-id"}, true, nil)
+			fakeDiskManager.SetFindCurrentBehavior([]bidisk.Disk{logsDisk}, nil)
+
+			disks, err := diskDeployer.Deploy(diskPools, cloud, fakeVM, fakeStage)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(disks).To(Equal([]bidisk.Disk{fakeDisk, logsDisk}))
+
+			Expect(fakeDiskRepo.UpdateCurrentInputs).To(Equal([]fakebiconfig.DiskRepoUpdateCurrentInput{
+				{DiskIDs: []string{"fake-new-disk-id", "fake-logs-disk-id"}},
+			}))
 		})
 
+		Context("when the disk of another disk pool needs migration", func() {
+			var logsDisk *fakebidisk.FakeDisk
+
+			BeforeEach(func() {
+				logsDisk = fakebidisk.NewFakeNamedDisk("logs", "fake-logs-disk-cid")
+				logsDisk.SetNeedsMigrationBehavior(true)
+				fakeDiskRepo.SetFindBehavior("fake-logs-disk-cid", biconfig.DiskRecord{ID: "fake-logs-disk-id"}, true, nil)
+				fakeVM.SetAttachDiskBehavior(logsDisk, nil)
+				fakeDiskManager.SetFindCurrentBehavior([]bidisk.Disk{dataDisk, logsDisk}, nil)
+			})
+
+			It("returns an error before changing any disk", func() {
+				dataDisk.SetNeedsMigrationBehavior(true)
+
+				_, err := diskDeployer.Deploy(diskPools, cloud, fakeVM, fakeStage)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Changing the size or cloud properties of persistent disk 'logs' (disk 'fake-logs-disk-cid') is not supported because the agent only migrates the first persistent disk"))
+
+				Expect(fakeStage.PerformCalls).To(BeEmpty())
+				Expect(fakeDiskManager.CreateInputs).To(BeEmpty())
+				Expect(fakeVM.MigrateDiskCalledTimes).To(Equal(0))
+				Expect(fakeVM.DetachDiskInputs).To(BeEmpty())
+				Expect(fakeDiskRepo.UpdateCurrentInputs).To(BeEmpty())
+				Expect(fakeDiskManager.DeleteUnusedCalledTimes).To(Equal(0))
+			})
+		})
+
+		It("returns an error before changing any disk when disks are forced to be recreated", func() {
+			diskDeployer = NewDiskDeployer(fakeDiskManagerFactory, fakeDiskRepo, logger, true)
+
+			logsDisk := fakebidisk.NewFakeNamedDisk("logs", "fake-logs-disk-cid")
+			fakeDiskManager.SetFindCurrentBehavior([]bidisk.Disk{dataDisk, logsDisk}, nil)
+
+			_, err := diskDeployer.Deploy(diskPools, cloud, fakeVM, fakeStage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(Equal("Recreating persistent disks is only supported with a single persistent disk"))
+
+			Expect(fakeStage.PerformCalls).To(BeEmpty())
+			Expect(fakeDiskManager.CreateInputs).To(BeEmpty())
+			Expect(fakeVM.DetachDiskInputs).To(BeEmpty())
+			Expect(fakeDiskManager.DeleteUnusedCalledTimes).To(Equal(0))
+		})
+
+		It("uses the disk of a single persistent disk for the first disk pool", func() {
+			legacyDisk := fakebidisk.NewFakeDisk("fake-legacy-disk-cid")
+			fakeDiskRepo.SetFindBehavior("fake-legacy-disk-cid", biconfig.DiskRecord{ID: "fake-legacy-disk-id"}, true, nil)
+			fakeVM.SetAttachDiskBehavior(legacyDisk, nil)
+			fakeDiskManager.SetFindCurrentBehavior([]bidisk.Disk{legacyDisk}, nil)
+
+			disks, err := diskDeployer.Deploy(diskPools, cloud, fakeVM, fakeStage)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(disks).To(Equal([]bidisk.Disk{legacyDisk, fakeDisk}))
+
+			Expect(fakeDiskManager.CreateInputs).To(HaveLen(1))
+			Expect(fakeDiskManager.CreateInputs[0].Name).To(Equal("logs"))
+		})
+
+		It("detaches disks that are no longer in the manifest so that they are deleted", func() {
+			oldDisk := fakebidisk.NewFakeNamedDisk("old", "fake-old-disk-cid")
+			fakeDiskRepo.SetFindBehavior("fake-old-disk-cid", biconfig.DiskRecord{ID: "fake-old-disk-id"}, true, nil)
+			fakeDiskManager.SetFindCurrentBehavior([]bidisk.Disk{oldDisk, dataDisk}, nil)
+
+			disks, err := diskDeployer.Deploy(diskPools, cloud, fakeVM, fakeStage)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(disks).To(Equal([]bidisk.Disk{dataDisk, fakeDisk}))
+
+			Expect(fakeVM.DetachDiskInputs).To(Equal([]fakebivm.DetachDiskInput{
+				{Disk: oldDisk},
+			}))
+			Expect(fakeDiskRepo.UpdateCurrentInputs).To(Equal([]fakebiconfig.DiskRepoUpdateCurrentInput{
+				{DiskIDs: []string{"fake-data-disk-id", "fake-new-disk-id", "fake-old-disk-id"}},
+				{DiskIDs: []string{"fake-data-disk-id", "fake-new-disk-id"}},
+			}))
+			Expect(fakeDiskManager.DeleteUnusedCalledTimes).To(Equal(1))
+
+			Expect(fakeStage.PerformCalls).To(Equal([]*fakebiui.PerformCall{
+				{Name: "Attaching disk 'fake-data-disk-cid' to VM 'fake-vm-cid'"},
+				{Name: "Creating disk"},
+				{Name: "Attaching disk 'fake-new-disk-cid' to VM 'fake-vm-cid'"},
+				{Name: "Detaching disk 'fake-old-disk-cid'"},
+			}))
+		})
+	})
+
+	Context("when there are no disk pools", func() {
 		It("does not create a persistent disk", func() {
-			disks, err := diskDeployer.Deploy(diskPool, cloud, fakeVM, fakeStage)
+			disks, err := diskDeployer.Deploy([]bideplmanifest.NamedDiskPool{}, cloud, fakeVM, fakeStage)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(disks).To(Equal([]bidisk.Disk{}))
 
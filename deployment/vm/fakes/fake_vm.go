@@ -69,8 +69,8 @@ type FakeVM struct {
 }
 
 type UpdateDisksInput struct {
-	DiskPool bideplmanifest.DiskPool
-	Stage    biui.Stage
+	DiskPools []bideplmanifest.NamedDiskPool
+	Stage     biui.Stage
 }
 
 type ApplyInput struct {
@@ -88,7 +88,8 @@ type WaitInput struct {
 }
 
 type AttachDiskInput struct {
-	Disk bidisk.Disk
+	Disk            bidisk.Disk
+	WithoutMounting bool
 }
 
 type DetachDiskInput struct {
@@ -136,10 +137,10 @@ func (vm *FakeVM) WaitUntilReady(timeout time.Duration, delay time.Duration) err
 	return vm.WaitUntilReadyErr
 }
 
-func (vm *FakeVM) UpdateDisks(diskPool bideplmanifest.DiskPool, eventLoggerStage biui.Stage) ([]bidisk.Disk, error) {
+func (vm *FakeVM) UpdateDisks(diskPools []bideplmanifest.NamedDiskPool, eventLoggerStage biui.Stage) ([]bidisk.Disk, error) {
 	vm.UpdateDisksInputs = append(vm.UpdateDisksInputs, UpdateDisksInput{
-		DiskPool: diskPool,
-		Stage:    eventLoggerStage,
+		DiskPools: diskPools,
+		Stage:     eventLoggerStage,
 	})
 	return vm.UpdateDisksDisks, vm.UpdateDisksErr
 }
@@ -178,6 +179,15 @@ func (vm *FakeVM) AttachDisk(disk bidisk.Disk) error {
 	return vm.attachDiskBehavior[disk.CID()]
 }
 
+func (vm *FakeVM) AttachDiskWithoutMounting(disk bidisk.Disk) error {
+	vm.AttachDiskInputs = append(vm.AttachDiskInputs, AttachDiskInput{
+		Disk:            disk,
+		WithoutMounting: true,
+	})
+
+	return vm.attachDiskBehavior[disk.CID()]
+}
+
 func (vm *FakeVM) DetachDisk(disk bidisk.Disk) error {
 	vm.DetachDiskInputs = append(vm.DetachDiskInputs, DetachDiskInput{
 		Disk: disk,
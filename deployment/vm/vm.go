@@ -35,9 +35,10 @@ type VM interface {
 	Stop() error
 	Drain() error
 	Apply(bias.ApplySpec) error
-	UpdateDisks(bideplmanifest.DiskPool, biui.Stage) ([]bidisk.Disk, error)
+	UpdateDisks([]bideplmanifest.NamedDiskPool, biui.Stage) ([]bidisk.Disk, error)
 	WaitToBeRunning(maxAttempts int, delay time.Duration) error
 	AttachDisk(bidisk.Disk) error
+	AttachDiskWithoutMounting(bidisk.Disk) error
 	DetachDisk(bidisk.Disk) error
 	Disks() ([]bidisk.Disk, error)
 	UnmountDisk(bidisk.Disk) error
@@ -184,8 +185,8 @@ func (vm *vm) Apply(newState bias.ApplySpec) error {
 	return nil
 }
 
-func (vm *vm) UpdateDisks(diskPool bideplmanifest.DiskPool, eventLoggerStage biui.Stage) ([]bidisk.Disk, error) {
-	disks, err := vm.diskDeployer.Deploy(diskPool, vm.cloud, vm, eventLoggerStage)
+func (vm *vm) UpdateDisks(diskPools []bideplmanifest.NamedDiskPool, eventLoggerStage biui.Stage) ([]bidisk.Disk, error) {
+	disks, err := vm.diskDeployer.Deploy(diskPools, vm.cloud, vm, eventLoggerStage)
 	if err != nil {
 		return disks, bosherr.WrapError(err, "Deploying disk")
 	}
@@ -199,6 +200,16 @@ func (vm *vm) WaitToBeRunning(maxAttempts int, delay time.Duration) error {
 }
 
 func (vm *vm) AttachDisk(disk bidisk.Disk) error {
+	return vm.attachDisk(disk, true)
+}
+
+// AttachDiskWithoutMounting makes a disk known to the agent without mounting it.
+// The agent mounts a single disk as the persistent disk of the jobs and only migrates that disk.
+func (vm *vm) AttachDiskWithoutMounting(disk bidisk.Disk) error {
+	return vm.attachDisk(disk, false)
+}
+
+func (vm *vm) attachDisk(disk bidisk.Disk, mount bool) error {
 	diskHints, err := vm.cloud.AttachDisk(vm.cid, disk.CID())
 	if err != nil {
 		return bosherr.WrapError(err, "Attaching disk in the cloud")
@@ -226,6 +237,10 @@ func (vm *vm) AttachDisk(disk bidisk.Disk) error {
 		}
 	}
 
+	if !mount {
+		return nil
+	}
+
 	err = vm.agentClient.MountDisk(disk.CID())
 	if err != nil {
 		return bosherr.WrapError(err, "Mounting disk")
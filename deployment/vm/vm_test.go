@@ -34,7 +34,7 @@ var _ = Describe("VM", func() {
 		fakeAgentClient  *fakebiagentclient.FakeAgentClient
 		fakeCloud        *fakebicloud.FakeCloud
 		applySpec        bias.ApplySpec
-		diskPool         bideplmanifest.DiskPool
+		diskPools        []bideplmanifest.NamedDiskPool
 		timeService      *FakeClock
 		fs               *fakesys.FakeFileSystem
 		logger           *loggerfakes.FakeLogger
@@ -49,11 +49,16 @@ var _ = Describe("VM", func() {
 			Deployment: "fake-deployment-name",
 		}
 
-		diskPool = bideplmanifest.DiskPool{
-			Name:     "fake-persistent-disk-pool-name",
-			DiskSize: 1024,
-			CloudProperties: biproperty.Map{
-				"fake-disk-pool-cloud-property-key": "fake-disk-pool-cloud-property-value",
+		diskPools = []bideplmanifest.NamedDiskPool{
+			{
+				Name: "data",
+				DiskPool: bideplmanifest.DiskPool{
+					Name:     "fake-persistent-disk-pool-name",
+					DiskSize: 1024,
+					CloudProperties: biproperty.Map{
+						"fake-disk-pool-cloud-property-key": "fake-disk-pool-cloud-property-value",
+					},
+				},
 			},
 		}
 
@@ -114,13 +119,13 @@ var _ = Describe("VM", func() {
 		It("delegates to DiskDeployer.Deploy", func() {
 			fakeStage := fakebiui.NewFakeStage()
 
-			disks, err := vm.UpdateDisks(diskPool, fakeStage)
+			disks, err := vm.UpdateDisks(diskPools, fakeStage)
 			Expect(err).NotTo(HaveOccurred())
 			Expect(disks).To(Equal(expectedDisks))
 
 			Expect(fakeDiskDeployer.DeployInputs).To(Equal([]fakebivm.DeployInput{
 				{
-					DiskPool:         diskPool,
+					DiskPools:        diskPools,
 					Cloud:            fakeCloud,
 					VM:               vm,
 					EventLoggerStage: fakeStage,
@@ -336,6 +341,20 @@ var _ = Describe("VM", func() {
 			Expect(fakeAgentClient.MountDiskArgsForCall(0)).To(Equal("fake-disk-cid"))
 		})
 
+		It("adds but does not mount a disk attached without mounting", func() {
+			fakeCloud.AttachDiskHints = "/dev/sdc"
+
+			err := vm.AttachDiskWithoutMounting(disk)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fakeCloud.AttachDiskInput.DiskCID).To(Equal("fake-disk-cid"))
+			Expect(fakeAgentClient.AddPersistentDiskCallCount()).To(Equal(1))
+			diskCid, diskHints := fakeAgentClient.AddPersistentDiskArgsForCall(0)
+			Expect(diskCid).To(Equal("fake-disk-cid"))
+			Expect(diskHints).To(Equal("/dev/sdc"))
+			Expect(fakeAgentClient.MountDiskCallCount()).To(Equal(0))
+		})
+
 		Context("when metadata is set", func() {
 			It("sets the metadata to the disk", func() {
 				expectedDiskMetadata := bicloud.DiskMetadata{
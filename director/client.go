@@ -24,6 +24,18 @@ func NewClient(
 	return Client{clientRequest, taskClientRequest}
 }
 
+func (c Client) WithTaskInterruptHandler(interruptHandler TaskInterruptHandler) Client {
+	return Client{c.clientRequest, c.taskClientRequest.WithInterruptHandler(interruptHandler)}
+}
+
+func (c Client) WithoutTaskTracking() Client {
+	return Client{c.clientRequest, c.taskClientRequest.WithoutTracking()}
+}
+
+func (c Client) WithTaskTracking() Client {
+	return Client{c.clientRequest, c.taskClientRequest.WithTracking()}
+}
+
 func (c Client) WithContext(contextId string) Client {
 	clientRequest := c.clientRequest.WithContext(contextId)
 
@@ -278,7 +278,7 @@ func (c Client) FetchLogs(deploymentName, instance, indexOrID string, filters []
 	path := fmt.Sprintf("/deployments/%s/jobs/%s/%s/logs?%s",
 		deploymentName, instance, indexOrID, query.Encode())
 
-	taskID, _, err := c.taskClientRequest.GetResult(path)
+	taskID, _, err := c.taskClientRequest.WithTracking().GetResult(path)
 	if err != nil {
 		return "", "", bosherr.WrapErrorf(err, "Fetching logs")
 	}
@@ -501,7 +501,7 @@ func (c Client) ExportRelease(deploymentName string, release ReleaseSlug, os OSV
 		req.Header.Add("Content-Type", "application/json")
 	}
 
-	resultBytes, err := c.taskClientRequest.PostResult(path, reqBody, setHeaders)
+	resultBytes, err := c.taskClientRequest.WithTracking().PostResult(path, reqBody, setHeaders)
 	if err != nil {
 		return resp, bosherr.WrapErrorf(err, "Exporting release")
 	}
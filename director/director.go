@@ -26,6 +26,10 @@ func (d DirectorImpl) WithContext(id string) Director {
 	return DirectorImpl{client: d.client.WithContext(id)}
 }
 
+func (d DirectorImpl) WithTaskTracking() Director {
+	return DirectorImpl{client: d.client.WithTaskTracking()}
+}
+
 func (c Client) OrphanedVMs() ([]OrphanedVM, error) {
 	var resps []OrphanedVMResponse
 
@@ -721,6 +721,16 @@ type FakeDirector struct {
 	withContextReturnsOnCall map[int]struct {
 		result1 director.Director
 	}
+	WithTaskTrackingStub        func() director.Director
+	withTaskTrackingMutex       sync.RWMutex
+	withTaskTrackingArgsForCall []struct {
+	}
+	withTaskTrackingReturns struct {
+		result1 director.Director
+	}
+	withTaskTrackingReturnsOnCall map[int]struct {
+		result1 director.Director
+	}
 	invocations      map[string][][]interface{}
 	invocationsMutex sync.RWMutex
 }
@@ -4196,6 +4206,59 @@ func (fake *FakeDirector) WithContextReturnsOnCall(i int, result1 director.Direc
 	}{result1}
 }
 
+func (fake *FakeDirector) WithTaskTracking() director.Director {
+	fake.withTaskTrackingMutex.Lock()
+	ret, specificReturn := fake.withTaskTrackingReturnsOnCall[len(fake.withTaskTrackingArgsForCall)]
+	fake.withTaskTrackingArgsForCall = append(fake.withTaskTrackingArgsForCall, struct {
+	}{})
+	stub := fake.WithTaskTrackingStub
+	fakeReturns := fake.withTaskTrackingReturns
+	fake.recordInvocation("WithTaskTracking", []interface{}{})
+	fake.withTaskTrackingMutex.Unlock()
+	if stub != nil {
+		return stub()
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeDirector) WithTaskTrackingCallCount() int {
+	fake.withTaskTrackingMutex.RLock()
+	defer fake.withTaskTrackingMutex.RUnlock()
+	return len(fake.withTaskTrackingArgsForCall)
+}
+
+func (fake *FakeDirector) WithTaskTrackingCalls(stub func() director.Director) {
+	fake.withTaskTrackingMutex.Lock()
+	defer fake.withTaskTrackingMutex.Unlock()
+	fake.WithTaskTrackingStub = stub
+}
+
+func (fake *FakeDirector) WithTaskTrackingReturns(result1 director.Director) {
+	fake.withTaskTrackingMutex.Lock()
+	defer fake.withTaskTrackingMutex.Unlock()
+	fake.WithTaskTrackingStub = nil
+	fake.withTaskTrackingReturns = struct {
+		result1 director.Director
+	}{result1}
+}
+
+func (fake *FakeDirector) WithTaskTrackingReturnsOnCall(i int, result1 director.Director) {
+	fake.withTaskTrackingMutex.Lock()
+	defer fake.withTaskTrackingMutex.Unlock()
+	fake.WithTaskTrackingStub = nil
+	if fake.withTaskTrackingReturnsOnCall == nil {
+		fake.withTaskTrackingReturnsOnCall = make(map[int]struct {
+			result1 director.Director
+		})
+	}
+	fake.withTaskTrackingReturnsOnCall[i] = struct {
+		result1 director.Director
+	}{result1}
+}
+
 func (fake *FakeDirector) Invocations() map[string][][]interface{} {
 	fake.invocationsMutex.RLock()
 	defer fake.invocationsMutex.RUnlock()
@@ -4309,6 +4372,8 @@ func (fake *FakeDirector) Invocations() map[string][][]interface{} {
 	defer fake.uploadStemcellURLMutex.RUnlock()
 	fake.withContextMutex.RLock()
 	defer fake.withContextMutex.RUnlock()
+	fake.withTaskTrackingMutex.RLock()
+	defer fake.withTaskTrackingMutex.RUnlock()
 	copiedInvocations := map[string][][]interface{}{}
 	for key, value := range fake.invocations {
 		copiedInvocations[key] = value
@@ -82,6 +82,18 @@ type FakeTask struct {
 	eventOutputReturnsOnCall map[int]struct {
 		result1 error
 	}
+	EventOutputFromStub        func(int, director.TaskReporter) error
+	eventOutputFromMutex       sync.RWMutex
+	eventOutputFromArgsForCall []struct {
+		arg1 int
+		arg2 director.TaskReporter
+	}
+	eventOutputFromReturns struct {
+		result1 error
+	}
+	eventOutputFromReturnsOnCall map[int]struct {
+		result1 error
+	}
 	FinishedAtStub        func() time.Time
 	finishedAtMutex       sync.RWMutex
 	finishedAtArgsForCall []struct {
@@ -562,6 +574,68 @@ func (fake *FakeTask) EventOutputReturnsOnCall(i int, result1 error) {
 	}{result1}
 }
 
+func (fake *FakeTask) EventOutputFrom(arg1 int, arg2 director.TaskReporter) error {
+	fake.eventOutputFromMutex.Lock()
+	ret, specificReturn := fake.eventOutputFromReturnsOnCall[len(fake.eventOutputFromArgsForCall)]
+	fake.eventOutputFromArgsForCall = append(fake.eventOutputFromArgsForCall, struct {
+		arg1 int
+		arg2 director.TaskReporter
+	}{arg1, arg2})
+	stub := fake.EventOutputFromStub
+	fakeReturns := fake.eventOutputFromReturns
+	fake.recordInvocation("EventOutputFrom", []interface{}{arg1, arg2})
+	fake.eventOutputFromMutex.Unlock()
+	if stub != nil {
+		return stub(arg1, arg2)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTask) EventOutputFromCallCount() int {
+	fake.eventOutputFromMutex.RLock()
+	defer fake.eventOutputFromMutex.RUnlock()
+	return len(fake.eventOutputFromArgsForCall)
+}
+
+func (fake *FakeTask) EventOutputFromCalls(stub func(int, director.TaskReporter) error) {
+	fake.eventOutputFromMutex.Lock()
+	defer fake.eventOutputFromMutex.Unlock()
+	fake.EventOutputFromStub = stub
+}
+
+func (fake *FakeTask) EventOutputFromArgsForCall(i int) (int, director.TaskReporter) {
+	fake.eventOutputFromMutex.RLock()
+	defer fake.eventOutputFromMutex.RUnlock()
+	argsForCall := fake.eventOutputFromArgsForCall[i]
+	return argsForCall.arg1, argsForCall.arg2
+}
+
+func (fake *FakeTask) EventOutputFromReturns(result1 error) {
+	fake.eventOutputFromMutex.Lock()
+	defer fake.eventOutputFromMutex.Unlock()
+	fake.EventOutputFromStub = nil
+	fake.eventOutputFromReturns = struct {
+		result1 error
+	}{result1}
+}
+
+func (fake *FakeTask) EventOutputFromReturnsOnCall(i int, result1 error) {
+	fake.eventOutputFromMutex.Lock()
+	defer fake.eventOutputFromMutex.Unlock()
+	fake.EventOutputFromStub = nil
+	if fake.eventOutputFromReturnsOnCall == nil {
+		fake.eventOutputFromReturnsOnCall = make(map[int]struct {
+			result1 error
+		})
+	}
+	fake.eventOutputFromReturnsOnCall[i] = struct {
+		result1 error
+	}{result1}
+}
+
 func (fake *FakeTask) FinishedAt() time.Time {
 	fake.finishedAtMutex.Lock()
 	ret, specificReturn := fake.finishedAtReturnsOnCall[len(fake.finishedAtArgsForCall)]
@@ -1011,6 +1085,8 @@ func (fake *FakeTask) Invocations() map[string][][]interface{} {
 	defer fake.descriptionMutex.RUnlock()
 	fake.eventOutputMutex.RLock()
 	defer fake.eventOutputMutex.RUnlock()
+	fake.eventOutputFromMutex.RLock()
+	defer fake.eventOutputFromMutex.RUnlock()
 	fake.finishedAtMutex.RLock()
 	defer fake.finishedAtMutex.RUnlock()
 	fake.iDMutex.RLock()
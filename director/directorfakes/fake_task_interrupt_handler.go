@@ -0,0 +1,190 @@
+// Code generated by counterfeiter. DO NOT EDIT.
+package directorfakes
+
+import (
+	"os"
+	"sync"
+
+	"github.com/cloudfoundry/bosh-cli/v7/director"
+)
+
+type FakeTaskInterruptHandler struct {
+	NotifyStub        func(chan<- os.Signal)
+	notifyMutex       sync.RWMutex
+	notifyArgsForCall []struct {
+		arg1 chan<- os.Signal
+	}
+	StopStub        func(chan<- os.Signal)
+	stopMutex       sync.RWMutex
+	stopArgsForCall []struct {
+		arg1 chan<- os.Signal
+	}
+	TaskInterruptedStub        func(int) director.TaskInterruptAction
+	taskInterruptedMutex       sync.RWMutex
+	taskInterruptedArgsForCall []struct {
+		arg1 int
+	}
+	taskInterruptedReturns struct {
+		result1 director.TaskInterruptAction
+	}
+	taskInterruptedReturnsOnCall map[int]struct {
+		result1 director.TaskInterruptAction
+	}
+	invocations      map[string][][]interface{}
+	invocationsMutex sync.RWMutex
+}
+
+func (fake *FakeTaskInterruptHandler) Notify(arg1 chan<- os.Signal) {
+	fake.notifyMutex.Lock()
+	fake.notifyArgsForCall = append(fake.notifyArgsForCall, struct {
+		arg1 chan<- os.Signal
+	}{arg1})
+	stub := fake.NotifyStub
+	fake.recordInvocation("Notify", []interface{}{arg1})
+	fake.notifyMutex.Unlock()
+	if stub != nil {
+		fake.NotifyStub(arg1)
+	}
+}
+
+func (fake *FakeTaskInterruptHandler) NotifyCallCount() int {
+	fake.notifyMutex.RLock()
+	defer fake.notifyMutex.RUnlock()
+	return len(fake.notifyArgsForCall)
+}
+
+func (fake *FakeTaskInterruptHandler) NotifyCalls(stub func(chan<- os.Signal)) {
+	fake.notifyMutex.Lock()
+	defer fake.notifyMutex.Unlock()
+	fake.NotifyStub = stub
+}
+
+func (fake *FakeTaskInterruptHandler) NotifyArgsForCall(i int) chan<- os.Signal {
+	fake.notifyMutex.RLock()
+	defer fake.notifyMutex.RUnlock()
+	argsForCall := fake.notifyArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeTaskInterruptHandler) Stop(arg1 chan<- os.Signal) {
+	fake.stopMutex.Lock()
+	fake.stopArgsForCall = append(fake.stopArgsForCall, struct {
+		arg1 chan<- os.Signal
+	}{arg1})
+	stub := fake.StopStub
+	fake.recordInvocation("Stop", []interface{}{arg1})
+	fake.stopMutex.Unlock()
+	if stub != nil {
+		fake.StopStub(arg1)
+	}
+}
+
+func (fake *FakeTaskInterruptHandler) StopCallCount() int {
+	fake.stopMutex.RLock()
+	defer fake.stopMutex.RUnlock()
+	return len(fake.stopArgsForCall)
+}
+
+func (fake *FakeTaskInterruptHandler) StopCalls(stub func(chan<- os.Signal)) {
+	fake.stopMutex.Lock()
+	defer fake.stopMutex.Unlock()
+	fake.StopStub = stub
+}
+
+func (fake *FakeTaskInterruptHandler) StopArgsForCall(i int) chan<- os.Signal {
+	fake.stopMutex.RLock()
+	defer fake.stopMutex.RUnlock()
+	argsForCall := fake.stopArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeTaskInterruptHandler) TaskInterrupted(arg1 int) director.TaskInterruptAction {
+	fake.taskInterruptedMutex.Lock()
+	ret, specificReturn := fake.taskInterruptedReturnsOnCall[len(fake.taskInterruptedArgsForCall)]
+	fake.taskInterruptedArgsForCall = append(fake.taskInterruptedArgsForCall, struct {
+		arg1 int
+	}{arg1})
+	stub := fake.TaskInterruptedStub
+	fakeReturns := fake.taskInterruptedReturns
+	fake.recordInvocation("TaskInterrupted", []interface{}{arg1})
+	fake.taskInterruptedMutex.Unlock()
+	if stub != nil {
+		return stub(arg1)
+	}
+	if specificReturn {
+		return ret.result1
+	}
+	return fakeReturns.result1
+}
+
+func (fake *FakeTaskInterruptHandler) TaskInterruptedCallCount() int {
+	fake.taskInterruptedMutex.RLock()
+	defer fake.taskInterruptedMutex.RUnlock()
+	return len(fake.taskInterruptedArgsForCall)
+}
+
+func (fake *FakeTaskInterruptHandler) TaskInterruptedCalls(stub func(int) director.TaskInterruptAction) {
+	fake.taskInterruptedMutex.Lock()
+	defer fake.taskInterruptedMutex.Unlock()
+	fake.TaskInterruptedStub = stub
+}
+
+func (fake *FakeTaskInterruptHandler) TaskInterruptedArgsForCall(i int) int {
+	fake.taskInterruptedMutex.RLock()
+	defer fake.taskInterruptedMutex.RUnlock()
+	argsForCall := fake.taskInterruptedArgsForCall[i]
+	return argsForCall.arg1
+}
+
+func (fake *FakeTaskInterruptHandler) TaskInterruptedReturns(result1 director.TaskInterruptAction) {
+	fake.taskInterruptedMutex.Lock()
+	defer fake.taskInterruptedMutex.Unlock()
+	fake.TaskInterruptedStub = nil
+	fake.taskInterruptedReturns = struct {
+		result1 director.TaskInterruptAction
+	}{result1}
+}
+
+func (fake *FakeTaskInterruptHandler) TaskInterruptedReturnsOnCall(i int, result1 director.TaskInterruptAction) {
+	fake.taskInterruptedMutex.Lock()
+	defer fake.taskInterruptedMutex.Unlock()
+	fake.TaskInterruptedStub = nil
+	if fake.taskInterruptedReturnsOnCall == nil {
+		fake.taskInterruptedReturnsOnCall = make(map[int]struct {
+			result1 director.TaskInterruptAction
+		})
+	}
+	fake.taskInterruptedReturnsOnCall[i] = struct {
+		result1 director.TaskInterruptAction
+	}{result1}
+}
+
+func (fake *FakeTaskInterruptHandler) Invocations() map[string][][]interface{} {
+	fake.invocationsMutex.RLock()
+	defer fake.invocationsMutex.RUnlock()
+	fake.notifyMutex.RLock()
+	defer fake.notifyMutex.RUnlock()
+	fake.stopMutex.RLock()
+	defer fake.stopMutex.RUnlock()
+	fake.taskInterruptedMutex.RLock()
+	defer fake.taskInterruptedMutex.RUnlock()
+	copiedInvocations := map[string][][]interface{}{}
+	for key, value := range fake.invocations {
+		copiedInvocations[key] = value
+	}
+	return copiedInvocations
+}
+
+func (fake *FakeTaskInterruptHandler) recordInvocation(key string, args []interface{}) {
+	fake.invocationsMutex.Lock()
+	defer fake.invocationsMutex.Unlock()
+	if fake.invocations == nil {
+		fake.invocations = map[string][][]interface{}{}
+	}
+	if fake.invocations[key] == nil {
+		fake.invocations[key] = [][]interface{}{}
+	}
+	fake.invocations[key] = append(fake.invocations[key], args)
+}
+
+var _ director.TaskInterruptHandler = new(FakeTaskInterruptHandler)
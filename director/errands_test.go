@@ -76,6 +76,40 @@ var _ = Describe("Director", func() {
 	})
 
 	Describe("RunErrand", func() {
+		Context("when tasks are not tracked", func() {
+			BeforeEach(func() {
+				server.Close()
+				director, server = BuildUntrackedServer()
+
+				var err error
+
+				deployment, err = director.FindDeployment("dep1")
+				Expect(err).ToNot(HaveOccurred())
+			})
+
+			It("returns as soon as the errand task is queued", func() {
+				redirectHeader := http.Header{}
+				redirectHeader.Add("Location", "/tasks/123")
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/deployments/dep1/errands/errand1/runs"),
+						ghttp.RespondWith(http.StatusFound, nil, redirectHeader),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/tasks/123"),
+						ghttp.RespondWith(http.StatusOK, `{"id":123, "state":"queued"}`),
+					),
+				)
+
+				_, err := deployment.RunErrand("errand1", false, false, []InstanceGroupOrInstanceSlug{})
+				notTrackedErr, found := AsTaskNotTrackedError(err)
+				Expect(found).To(BeTrue())
+				Expect(notTrackedErr.ID).To(Equal(123))
+				Expect(server.ReceivedRequests()).To(HaveLen(2))
+			})
+		})
+
 		It("runs errand and returns result", func() {
 			respBody := `{ "exit_code":1, "stdout":"stdout", "stderr":"stderr", "logs": { "blobstore_id": "logs-blob-id", "sha1": "logs-sha1" } }`
 			ConfigureTaskResult(
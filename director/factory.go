@@ -90,7 +90,17 @@ func (f Factory) httpClient(factoryConfig FactoryConfig, taskReporter TaskReport
 		Host:   net.JoinHostPort(factoryConfig.Host, fmt.Sprintf("%d", factoryConfig.Port)),
 	}
 
-	return NewClient(endpoint.String(), httpClient, taskReporter, fileReporter, f.logger), nil
+	client := NewClient(endpoint.String(), httpClient, taskReporter, fileReporter, f.logger)
+
+	if factoryConfig.TaskInterruptHandler != nil {
+		client = client.WithTaskInterruptHandler(factoryConfig.TaskInterruptHandler)
+	}
+
+	if factoryConfig.NoTrack {
+		client = client.WithoutTaskTracking()
+	}
+
+	return client, nil
 }
 
 func clearBody(req *http.Request) {
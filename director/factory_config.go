@@ -22,6 +22,12 @@ type FactoryConfig struct {
 	ClientSecret string
 
 	TokenFunc func(bool) (string, error)
+
+	// Interrupts during task tracking are not handled when nil
+	TaskInterruptHandler TaskInterruptHandler
+
+	// Task-creating requests return TaskNotTrackedError once their task is queued
+	NoTrack bool
 }
 
 func NewConfigFromURL(url string) (FactoryConfig, error) {
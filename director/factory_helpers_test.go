@@ -11,6 +11,14 @@ import (
 )
 
 func BuildServer() (Director, *ghttp.Server) {
+	return buildServer(false)
+}
+
+func BuildUntrackedServer() (Director, *ghttp.Server) {
+	return buildServer(true)
+}
+
+func buildServer(noTrack bool) (Director, *ghttp.Server) {
 	server := ghttp.NewUnstartedServer()
 
 	server.HTTPTestServer.TLS = &tls.Config{
@@ -25,6 +33,7 @@ func BuildServer() (Director, *ghttp.Server) {
 	factoryConfig.Client = "username"
 	factoryConfig.ClientSecret = "password"
 	factoryConfig.CACert = validCACert
+	factoryConfig.NoTrack = noTrack
 
 	logger := boshlog.NewLogger(boshlog.LevelNone)
 	taskReporter := NewNoopTaskReporter()
@@ -19,6 +19,7 @@ import (
 type Director interface {
 	IsAuthenticated() (bool, error)
 	WithContext(id string) Director
+	WithTaskTracking() Director
 	Info() (Info, error)
 
 	Locks() ([]Lock, error)
@@ -289,6 +290,7 @@ type Task interface {
 	Result() string
 
 	EventOutput(TaskReporter) error
+	EventOutputFrom(int, TaskReporter) error
 	CPIOutput(TaskReporter) error
 	DebugOutput(TaskReporter) error
 	ResultOutput(TaskReporter) error
@@ -304,6 +306,12 @@ type TaskReporter interface {
 	TaskOutputChunk(int, []byte)
 }
 
+// TaskStateReporter may be implemented by a TaskReporter
+// to also receive every task state seen while tracking a task
+type TaskStateReporter interface {
+	TaskStateChanged(int, string)
+}
+
 //counterfeiter:generate . OrphanDisk
 
 type OrphanDisk interface {
@@ -62,7 +62,7 @@ func (c Client) ScanForProblems(deploymentName string) error {
 		req.Header.Add("Content-Type", "application/json")
 	}
 
-	_, err := c.taskClientRequest.PostResult(path, nil, setHeaders)
+	_, err := c.taskClientRequest.WithTracking().PostResult(path, nil, setHeaders)
 	if err != nil {
 		return bosherr.WrapErrorf(
 			err, "Performing a scan on deployment '%s'", deploymentName)
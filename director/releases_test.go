@@ -537,6 +537,42 @@ var _ = Describe("Director", func() {
 			Expect(err.Error()).To(ContainSubstring(
 				"Uploading remote release 'url': Unmarshaling Director response"))
 		})
+
+		Context("when tasks are not tracked", func() {
+			BeforeEach(func() {
+				server.Close()
+				director, server = BuildUntrackedServer()
+			})
+
+			It("uploads releases with tracking before an untracked deploy", func() {
+				ConfigureTaskResult(ghttp.VerifyRequest("POST", "/releases"), "", server)
+
+				redirectHeader := http.Header{}
+				redirectHeader.Add("Location", "/tasks/124")
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("POST", "/deployments"),
+						ghttp.RespondWith(http.StatusFound, nil, redirectHeader),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/tasks/124"),
+						ghttp.RespondWith(http.StatusOK, `{"id":124, "state":"queued"}`),
+					),
+				)
+
+				err := director.WithTaskTracking().UploadReleaseURL("url", "", false, false)
+				Expect(err).ToNot(HaveOccurred())
+
+				deployment, err := director.FindDeployment("dep1")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = deployment.Update([]byte("manifest"), UpdateOpts{})
+				notTrackedErr, found := AsTaskNotTrackedError(err)
+				Expect(found).To(BeTrue())
+				Expect(notTrackedErr.ID).To(Equal(124))
+			})
+		})
 	})
 
 	Describe("UploadReleaseFile", func() {
@@ -118,7 +118,7 @@ func (c Client) SetUpSSH(deploymentName, jobName, indexOrID string, opts SSHOpts
 		req.Header.Add("Content-Type", "application/json")
 	}
 
-	resultBytes, err := c.taskClientRequest.PostResult(path, reqBody, setHeaders)
+	resultBytes, err := c.taskClientRequest.WithTracking().PostResult(path, reqBody, setHeaders)
 	if err != nil {
 		return resps, bosherr.WrapErrorf(err, "Setting up SSH in deployment '%s'", deploymentName)
 	}
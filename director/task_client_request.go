@@ -3,6 +3,7 @@ package director
 import (
 	"fmt"
 	"net/http"
+	"os"
 	"time"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
@@ -12,6 +13,8 @@ type TaskClientRequest struct {
 	clientRequest         ClientRequest
 	taskReporter          TaskReporter
 	taskCheckStepDuration time.Duration
+	interruptHandler      TaskInterruptHandler
+	noTrack               bool
 }
 
 func NewTaskClientRequest(
@@ -26,6 +29,27 @@ func NewTaskClientRequest(
 	}
 }
 
+// WithInterruptHandler lets the handler decide what happens
+// to tracked tasks when the CLI is interrupted
+func (r TaskClientRequest) WithInterruptHandler(interruptHandler TaskInterruptHandler) TaskClientRequest {
+	r.interruptHandler = interruptHandler
+	return r
+}
+
+// WithoutTracking makes task-creating requests return TaskNotTrackedError
+// as soon as their task is queued instead of waiting for its result
+func (r TaskClientRequest) WithoutTracking() TaskClientRequest {
+	r.noTrack = true
+	return r
+}
+
+// WithTracking waits for the task even when tasks are otherwise not tracked.
+// Requests whose commands show the task result, e.g. listing VMs, use it.
+func (r TaskClientRequest) WithTracking() TaskClientRequest {
+	r.noTrack = false
+	return r
+}
+
 type taskShortResp struct {
 	ID    int    // 165
 	State string // e.g. "queued", "processing", "done", "error", "cancelled"
@@ -86,15 +110,30 @@ func (r TaskClientRequest) DeleteResult(path string) ([]byte, error) {
 }
 
 func (r TaskClientRequest) WaitForCompletion(id int, type_ string, taskReporter TaskReporter) error {
+	return r.WaitForCompletionFrom(id, 0, type_, taskReporter)
+}
+
+// WaitForCompletionFrom reports task output starting at the given byte offset
+func (r TaskClientRequest) WaitForCompletionFrom(id int, offset int, type_ string, taskReporter TaskReporter) error {
 	taskReporter.TaskStarted(id)
 
 	var taskResp taskShortResp
-	var outputOffset int
+	var outputOffset = offset
+	var lastState string
+
+	stateReporter, _ := taskReporter.(TaskStateReporter)
 
 	defer func() {
 		taskReporter.TaskFinished(id, taskResp.State)
 	}()
 
+	interruptCh := make(chan os.Signal, 1)
+
+	if r.interruptHandler != nil {
+		r.interruptHandler.Notify(interruptCh)
+		defer r.interruptHandler.Stop(interruptCh)
+	}
+
 	taskPath := fmt.Sprintf("/tasks/%d", id)
 
 	for {
@@ -103,6 +142,11 @@ func (r TaskClientRequest) WaitForCompletion(id int, type_ string, taskReporter
 			return bosherr.WrapError(err, "Getting task state")
 		}
 
+		if stateReporter != nil && taskResp.State != lastState {
+			stateReporter.TaskStateChanged(taskResp.ID, taskResp.State)
+			lastState = taskResp.State
+		}
+
 		// retrieve output *after* getting state to make sure
 		// it's complete in case of task being finished
 		outputOffset, err = r.reportOutputChunk(taskResp.ID, outputOffset, type_, taskReporter)
@@ -111,7 +155,20 @@ func (r TaskClientRequest) WaitForCompletion(id int, type_ string, taskReporter
 		}
 
 		if taskResp.IsRunning() {
-			time.Sleep(r.taskCheckStepDuration)
+			select {
+			case <-interruptCh:
+				detached, err := r.handleInterrupt(taskResp.ID, interruptCh)
+				if err != nil {
+					return err
+				}
+
+				if detached {
+					msgFmt := "Stopped tracking task '%d' in state '%s', it continues to run on the Director"
+					return bosherr.Errorf(msgFmt, taskResp.ID, taskResp.State)
+				}
+			case <-time.After(r.taskCheckStepDuration):
+			}
+
 			continue
 		}
 
@@ -125,7 +182,30 @@ func (r TaskClientRequest) WaitForCompletion(id int, type_ string, taskReporter
 	}
 }
 
+// handleInterrupt keeps tracking a cancelled task so that its final state is reported.
+// Signals are not captured while the handler decides so that another interrupt exits the CLI.
+func (r TaskClientRequest) handleInterrupt(id int, interruptCh chan os.Signal) (bool, error) {
+	r.interruptHandler.Stop(interruptCh)
+	action := r.interruptHandler.TaskInterrupted(id)
+	r.interruptHandler.Notify(interruptCh)
+
+	switch action {
+	case TaskInterruptCancel:
+		return false, cancelTask(r.clientRequest, id)
+
+	case TaskInterruptDetach:
+		return true, nil
+
+	default:
+		return false, nil
+	}
+}
+
 func (r TaskClientRequest) waitForResult(taskResp taskShortResp) ([]byte, error) {
+	if r.noTrack {
+		return nil, TaskNotTrackedError{ID: taskResp.ID}
+	}
+
 	err := r.WaitForCompletion(taskResp.ID, "event", r.taskReporter)
 	if err != nil {
 		return nil, err
@@ -3,6 +3,7 @@ package director_test
 import (
 	"crypto/tls"
 	"net/http"
+	"os"
 	"time"
 
 	boshhttp "github.com/cloudfoundry/bosh-utils/httpclient"
@@ -19,14 +20,14 @@ var _ = Describe("TaskClientRequest", func() {
 	var (
 		server *ghttp.Server
 
-		buildReq func(TaskReporter) TaskClientRequest
+		buildReq func(TaskReporter, time.Duration) TaskClientRequest
 		req      TaskClientRequest
 	)
 
 	BeforeEach(func() {
 		_, server = BuildServer()
 
-		buildReq = func(taskReporter TaskReporter) TaskClientRequest {
+		buildReq = func(taskReporter TaskReporter, taskCheckStepDuration time.Duration) TaskClientRequest {
 			httpTransport := &http.Transport{
 				TLSClientConfig:     &tls.Config{InsecureSkipVerify: true},
 				TLSHandshakeTimeout: 10 * time.Second,
@@ -37,10 +38,10 @@ var _ = Describe("TaskClientRequest", func() {
 			httpClient := boshhttp.NewHTTPClient(rawClient, logger)
 			fileReporter := NewNoopFileReporter()
 			clientReq := NewClientRequest(server.URL(), httpClient, fileReporter, logger)
-			return NewTaskClientRequest(clientReq, taskReporter, 0*time.Second)
+			return NewTaskClientRequest(clientReq, taskReporter, taskCheckStepDuration)
 		}
 
-		req = buildReq(NewNoopTaskReporter())
+		req = buildReq(NewNoopTaskReporter(), 0*time.Second)
 	})
 
 	AfterEach(func() {
@@ -147,6 +148,67 @@ var _ = Describe("TaskClientRequest", func() {
 		})
 	})
 
+	Context("when tasks are not tracked", func() {
+		BeforeEach(func() {
+			req = req.WithoutTracking()
+		})
+
+		It("returns as soon as the task is queued", func() {
+			redirectHeader := http.Header{}
+			redirectHeader.Add("Location", "/tasks/123")
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("POST", "/path"),
+					ghttp.RespondWith(http.StatusFound, nil, redirectHeader),
+				),
+				// followed redirect
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123"),
+					ghttp.RespondWith(http.StatusOK, `{"id":123, "state":"queued"}`),
+				),
+			)
+
+			_, err := req.PostResult("/path", []byte("req-body"), nil)
+			Expect(err).To(Equal(TaskNotTrackedError{ID: 123}))
+			Expect(server.ReceivedRequests()).To(HaveLen(2))
+		})
+
+		It("still waits for the result of requests made with tracking", func() {
+			redirectHeader := http.Header{}
+			redirectHeader.Add("Location", "/tasks/123")
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/path"),
+					ghttp.RespondWith(http.StatusFound, nil, redirectHeader),
+				),
+				// followed redirect
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123"),
+					ghttp.RespondWith(http.StatusOK, `{"id":123, "state":"queued"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123"),
+					ghttp.RespondWith(http.StatusOK, `{"id":123, "state":"done"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123/output", "type=event"),
+					ghttp.RespondWith(http.StatusOK, ""),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123/output", "type=result"),
+					ghttp.RespondWith(http.StatusOK, "task-result"),
+				),
+			)
+
+			id, resp, err := req.WithTracking().GetResult("/path")
+			Expect(err).ToNot(HaveOccurred())
+			Expect(id).To(Equal(123))
+			Expect(resp).To(Equal([]byte("task-result")))
+		})
+	})
+
 	Describe("PutResult", func() {
 		act := func() ([]byte, error) {
 			setHeaders := func(req *http.Request) {
@@ -302,6 +364,51 @@ var _ = Describe("TaskClientRequest", func() {
 			Expect(taskReporter.TaskFinishedCallCount()).To(Equal(1))
 		})
 
+		It("notifies task reporter about task state changes if it is interested", func() {
+			stateReporter := &stateTrackingTaskReporter{FakeTaskReporter: taskReporter}
+
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123"),
+					ghttp.RespondWith(http.StatusOK, `{"id":123, "state":"queued"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123/output", "type=event"),
+					ghttp.RespondWith(http.StatusOK, ""),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123"),
+					ghttp.RespondWith(http.StatusOK, `{"id":123, "state":"processing"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123/output", "type=event"),
+					ghttp.RespondWith(http.StatusOK, ""),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123"),
+					ghttp.RespondWith(http.StatusOK, `{"id":123, "state":"processing"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123/output", "type=event"),
+					ghttp.RespondWith(http.StatusOK, ""),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123"),
+					ghttp.RespondWith(http.StatusOK, `{"id":123, "state":"done"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123/output", "type=event"),
+					ghttp.RespondWith(http.StatusOK, ""),
+				),
+			)
+
+			err := req.WaitForCompletion(123, "event", stateReporter)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(stateReporter.states).To(Equal([]string{"queued", "processing", "done"}))
+			Expect(taskReporter.TaskFinishedCallCount()).To(Equal(1))
+		})
+
 		It("returns an error if task state is finished executing", func() {
 			server.AppendHandlers(
 				ghttp.CombineHandlers(
@@ -454,5 +561,136 @@ var _ = Describe("TaskClientRequest", func() {
 			Expect(taskReporter.TaskStartedCallCount()).To(Equal(1))
 			Expect(taskReporter.TaskFinishedCallCount()).To(Equal(1))
 		})
+
+		Context("when interrupted while the task is running", func() {
+			var (
+				interruptHandler *fakedir.FakeTaskInterruptHandler
+			)
+
+			BeforeEach(func() {
+				interruptHandler = &fakedir.FakeTaskInterruptHandler{}
+				interruptHandler.NotifyStub = func(ch chan<- os.Signal) {
+					if interruptHandler.NotifyCallCount() == 1 {
+						ch <- os.Interrupt
+					}
+				}
+
+				// Long check step makes sure that tracking continues right after handling the interrupt
+				req = buildReq(NewNoopTaskReporter(), time.Hour).WithInterruptHandler(interruptHandler)
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/tasks/123"),
+						ghttp.RespondWith(http.StatusOK, `{"id":123, "state":"processing"}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/tasks/123/output", "type=event"),
+						ghttp.RespondWith(http.StatusOK, ""),
+					),
+				)
+			})
+
+			It("cancels the task and reports its final state", func() {
+				interruptHandler.TaskInterruptedReturns(TaskInterruptCancel)
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("DELETE", "/task/123"),
+						ghttp.RespondWith(http.StatusOK, ""),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/tasks/123"),
+						ghttp.RespondWith(http.StatusOK, `{"id":123, "state":"cancelled"}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/tasks/123/output", "type=event"),
+						ghttp.RespondWith(http.StatusOK, ""),
+					),
+				)
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Expected task '123' to succeed but state is 'cancelled'"))
+
+				Expect(interruptHandler.TaskInterruptedArgsForCall(0)).To(Equal(123))
+
+				id, state := taskReporter.TaskFinishedArgsForCall(0)
+				Expect(id).To(Equal(123))
+				Expect(state).To(Equal("cancelled"))
+
+				Expect(interruptHandler.StopCallCount()).To(Equal(2))
+				Expect(interruptHandler.StopArgsForCall(1)).To(Equal(interruptHandler.NotifyArgsForCall(0)))
+			})
+
+			It("does not capture signals while deciding what to do so that another interrupt exits", func() {
+				interruptHandler.TaskInterruptedStub = func(int) TaskInterruptAction {
+					Expect(interruptHandler.StopCallCount()).To(Equal(1))
+					Expect(interruptHandler.StopArgsForCall(0)).To(Equal(interruptHandler.NotifyArgsForCall(0)))
+					return TaskInterruptWatch
+				}
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/tasks/123"),
+						ghttp.RespondWith(http.StatusOK, `{"id":123, "state":"done"}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/tasks/123/output", "type=event"),
+						ghttp.RespondWith(http.StatusOK, ""),
+					),
+				)
+
+				Expect(act()).ToNot(HaveOccurred())
+				Expect(interruptHandler.TaskInterruptedCallCount()).To(Equal(1))
+				Expect(interruptHandler.NotifyCallCount()).To(Equal(2))
+			})
+
+			It("stops tracking the task when detaching", func() {
+				interruptHandler.TaskInterruptedReturns(TaskInterruptDetach)
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(Equal("Stopped tracking task '123' in state 'processing', it continues to run on the Director"))
+
+				_, state := taskReporter.TaskFinishedArgsForCall(0)
+				Expect(state).To(Equal("processing"))
+			})
+
+			It("keeps tracking the task when watching", func() {
+				interruptHandler.TaskInterruptedReturns(TaskInterruptWatch)
+
+				server.AppendHandlers(
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/tasks/123"),
+						ghttp.RespondWith(http.StatusOK, `{"id":123, "state":"done"}`),
+					),
+					ghttp.CombineHandlers(
+						ghttp.VerifyRequest("GET", "/tasks/123/output", "type=event"),
+						ghttp.RespondWith(http.StatusOK, ""),
+					),
+				)
+
+				Expect(act()).ToNot(HaveOccurred())
+			})
+
+			It("returns an error when cancelling the task fails", func() {
+				interruptHandler.TaskInterruptedReturns(TaskInterruptCancel)
+
+				AppendBadRequest(ghttp.VerifyRequest("DELETE", "/task/123"), server)
+
+				err := act()
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Cancelling task '123'"))
+			})
+		})
 	})
 })
+
+type stateTrackingTaskReporter struct {
+	*fakedir.FakeTaskReporter
+	states []string
+}
+
+func (r *stateTrackingTaskReporter) TaskStateChanged(id int, state string) {
+	r.states = append(r.states, state)
+}
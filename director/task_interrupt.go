@@ -0,0 +1,25 @@
+package director
+
+import (
+	"os"
+)
+
+// TaskInterruptAction is what happens to a tracked task when the CLI is interrupted
+type TaskInterruptAction string
+
+const (
+	TaskInterruptCancel TaskInterruptAction = "cancel"
+	TaskInterruptDetach TaskInterruptAction = "detach"
+	TaskInterruptWatch  TaskInterruptAction = "watch"
+)
+
+//counterfeiter:generate . TaskInterruptHandler
+
+// TaskInterruptHandler receives interrupts while a task is tracked
+// and decides whether to cancel the task, stop tracking it or keep tracking it
+type TaskInterruptHandler interface {
+	Notify(chan<- os.Signal)
+	Stop(chan<- os.Signal)
+
+	TaskInterrupted(id int) TaskInterruptAction
+}
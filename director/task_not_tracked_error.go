@@ -0,0 +1,33 @@
+package director
+
+import (
+	"errors"
+	"fmt"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+)
+
+// TaskNotTrackedError is returned instead of a task's result when tasks are not tracked
+type TaskNotTrackedError struct {
+	ID int
+}
+
+func (e TaskNotTrackedError) Error() string {
+	return fmt.Sprintf("Task '%d' was queued but is not tracked", e.ID)
+}
+
+// AsTaskNotTrackedError finds the error among the causes of wrapped errors
+func AsTaskNotTrackedError(err error) (TaskNotTrackedError, bool) {
+	for err != nil {
+		switch typedErr := err.(type) {
+		case TaskNotTrackedError:
+			return typedErr, true
+		case bosherr.ComplexError:
+			err = typedErr.Cause
+		default:
+			err = errors.Unwrap(err)
+		}
+	}
+
+	return TaskNotTrackedError{}, false
+}
@@ -0,0 +1,31 @@
+package director_test
+
+import (
+	"errors"
+	"fmt"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/director"
+)
+
+var _ = Describe("AsTaskNotTrackedError", func() {
+	It("finds the error among the causes of wrapped errors", func() {
+		err := bosherr.WrapError(fmt.Errorf("wrapped: %w", TaskNotTrackedError{ID: 123}), "Deploying")
+
+		notTrackedErr, found := AsTaskNotTrackedError(err)
+		Expect(found).To(BeTrue())
+		Expect(notTrackedErr.ID).To(Equal(123))
+		Expect(err.Error()).To(Equal("Deploying: wrapped: Task '123' was queued but is not tracked"))
+	})
+
+	It("does not find other errors", func() {
+		_, found := AsTaskNotTrackedError(bosherr.WrapError(errors.New("fake-err"), "Deploying"))
+		Expect(found).To(BeFalse())
+
+		_, found = AsTaskNotTrackedError(nil)
+		Expect(found).To(BeFalse())
+	})
+})
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/http"
 	gourl "net/url"
+	"strings"
 	"time"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
@@ -141,6 +142,10 @@ func (t TaskImpl) EventOutput(taskReporter TaskReporter) error {
 	return t.client.TaskOutput(t.id, "event", taskReporter)
 }
 
+func (t TaskImpl) EventOutputFrom(offset int, taskReporter TaskReporter) error {
+	return t.client.TaskOutputFrom(t.id, offset, "event", taskReporter)
+}
+
 func (t TaskImpl) CPIOutput(taskReporter TaskReporter) error {
 	return t.client.TaskOutput(t.id, "cpi", taskReporter)
 }
@@ -158,13 +163,22 @@ func (c Client) CurrentTasks(filter TasksFilter) ([]TaskResp, error) {
 
 	query := gourl.Values{}
 
-	query.Add("state", "processing,cancelling,queued")
+	if len(filter.States) > 0 {
+		query.Add("state", strings.Join(filter.States, ","))
+	} else {
+		query.Add("state", "processing,cancelling,queued")
+	}
+
 	query.Add("verbose", c.taskVerbosity(filter.All))
 
 	if len(filter.Deployment) > 0 {
 		query.Add("deployment", filter.Deployment)
 	}
 
+	if len(filter.Types) > 0 {
+		query.Add("type", strings.Join(filter.Types, ","))
+	}
+
 	path := fmt.Sprintf("/tasks?%s", query.Encode())
 
 	err := c.clientRequest.Get(path, &tasks)
@@ -187,6 +201,14 @@ func (c Client) RecentTasks(limit int, filter TasksFilter) ([]TaskResp, error) {
 		query.Add("deployment", filter.Deployment)
 	}
 
+	if len(filter.States) > 0 {
+		query.Add("state", strings.Join(filter.States, ","))
+	}
+
+	if len(filter.Types) > 0 {
+		query.Add("type", strings.Join(filter.Types, ","))
+	}
+
 	path := fmt.Sprintf("/tasks?%s", query.Encode())
 
 	err := c.clientRequest.Get(path, &tasks)
@@ -233,7 +255,11 @@ func (c Client) Task(id int) (TaskResp, error) {
 }
 
 func (c Client) TaskOutput(id int, type_ string, taskReporter TaskReporter) error {
-	err := c.taskClientRequest.WaitForCompletion(id, type_, taskReporter)
+	return c.TaskOutputFrom(id, 0, type_, taskReporter)
+}
+
+func (c Client) TaskOutputFrom(id int, offset int, type_ string, taskReporter TaskReporter) error {
+	err := c.taskClientRequest.WaitForCompletionFrom(id, offset, type_, taskReporter)
 	if err != nil {
 		return bosherr.WrapErrorf(err, "Capturing task '%d' output", id)
 	}
@@ -242,9 +268,13 @@ func (c Client) TaskOutput(id int, type_ string, taskReporter TaskReporter) erro
 }
 
 func (c Client) CancelTask(id int) error {
+	return cancelTask(c.clientRequest, id)
+}
+
+func cancelTask(clientRequest ClientRequest, id int) error {
 	path := fmt.Sprintf("/task/%d", id)
 
-	_, _, err := c.clientRequest.RawDelete(path)
+	_, _, err := clientRequest.RawDelete(path)
 	if err != nil {
 		return bosherr.WrapErrorf(err, "Cancelling task '%d'", id)
 	}
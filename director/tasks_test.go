@@ -107,6 +107,22 @@ var _ = Describe("Director", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("includes tasks with specific states and types when requested", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks", "state=queued,done&verbose=1&type=update_deployment,vms"),
+					ghttp.VerifyBasicAuth("username", "password"),
+					ghttp.RespondWith(http.StatusOK, "[]"),
+				),
+			)
+
+			_, err := director.CurrentTasks(TasksFilter{
+				States: []string{"queued", "done"},
+				Types:  []string{"update_deployment", "vms"},
+			})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
 		It("returns error if response is non-200", func() {
 			AppendBadRequest(ghttp.VerifyRequest("GET", "/tasks"), server)
 
@@ -211,6 +227,19 @@ var _ = Describe("Director", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		It("includes tasks with specific states and types when requested", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks", "limit=10&verbose=1&state=error&type=vms"),
+					ghttp.VerifyBasicAuth("username", "password"),
+					ghttp.RespondWith(http.StatusOK, "[]"),
+				),
+			)
+
+			_, err := director.RecentTasks(10, TasksFilter{States: []string{"error"}, Types: []string{"vms"}})
+			Expect(err).ToNot(HaveOccurred())
+		})
+
 		It("returns error if response is non-200", func() {
 			AppendBadRequest(ghttp.VerifyRequest("GET", "/tasks"), server)
 
@@ -490,6 +519,25 @@ var _ = Describe("Task", func() {
 				Expect(err.Error()).To(ContainSubstring("Capturing task '123' output"))
 			})
 		}
+
+		It("reports task 'event' output starting at the given offset", func() {
+			server.AppendHandlers(
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123"),
+					ghttp.RespondWith(http.StatusOK, `{"id":123, "state":"done"}`),
+				),
+				ghttp.CombineHandlers(
+					ghttp.VerifyRequest("GET", "/tasks/123/output", "type=event"),
+					ghttp.VerifyHeader(http.Header{"Range": []string{"bytes=456-"}}),
+					ghttp.RespondWith(http.StatusOK, "chunk"),
+				),
+			)
+
+			Expect(task.EventOutputFrom(456, reporter)).ToNot(HaveOccurred())
+
+			_, chunk := reporter.TaskOutputChunkArgsForCall(0)
+			Expect(chunk).To(Equal([]byte("chunk")))
+		})
 	})
 
 	Describe("Cancel", func() {
@@ -0,0 +1,56 @@
+package template
+
+const RedactedValue = "<redacted>"
+
+// RedactedVariables finds the same variables as the wrapped variables
+// but replaces every value with RedactedValue. Map and list values keep
+// their structure so that ((var.key)) references still resolve.
+type RedactedVariables struct {
+	vars Variables
+}
+
+func NewRedactedVariables(vars Variables) RedactedVariables {
+	return RedactedVariables{vars: vars}
+}
+
+var _ Variables = RedactedVariables{}
+
+func (v RedactedVariables) Get(varDef VariableDefinition) (interface{}, bool, error) {
+	val, found, err := v.vars.Get(varDef)
+	if !found || err != nil {
+		return nil, found, err
+	}
+
+	return redact(val), true, nil
+}
+
+func (v RedactedVariables) List() ([]VariableDefinition, error) {
+	return v.vars.List()
+}
+
+func redact(val interface{}) interface{} {
+	switch typedVal := val.(type) {
+	case map[interface{}]interface{}:
+		redacted := map[interface{}]interface{}{}
+		for k, v := range typedVal {
+			redacted[k] = redact(v)
+		}
+		return redacted
+
+	case map[string]interface{}:
+		redacted := map[string]interface{}{}
+		for k, v := range typedVal {
+			redacted[k] = redact(v)
+		}
+		return redacted
+
+	case []interface{}:
+		redacted := []interface{}{}
+		for _, v := range typedVal {
+			redacted = append(redacted, redact(v))
+		}
+		return redacted
+	}
+
+	return RedactedValue
+}
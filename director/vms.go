@@ -146,7 +146,7 @@ func (c Client) deploymentResourceInfos(deploymentName string, resourceType stri
 
 	path := fmt.Sprintf("/deployments/%s/%s?format=full", deploymentName, resourceType)
 
-	_, resultBytes, err := c.taskClientRequest.GetResult(path)
+	_, resultBytes, err := c.taskClientRequest.WithTracking().GetResult(path)
 	if err != nil {
 		return nil, bosherr.WrapErrorf(
 			err, "Listing deployment '%s' %s infos", deploymentName, resourceType)
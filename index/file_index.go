@@ -3,6 +3,7 @@ package index
 import (
 	"encoding/json"
 	"reflect"
+	"sort"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshsys "github.com/cloudfoundry/bosh-utils/system"
@@ -79,6 +80,13 @@ func (ri FileIndex) Save(key interface{}, value interface{}) error {
 			Key:   rawKey,
 			Value: rawValue,
 		})
+
+		// entries may be saved in any order (e.g. by parallel compilation)
+		// so keep the file independent of it
+		err = ri.sortRawEntries(rawEntries)
+		if err != nil {
+			return err
+		}
 	}
 
 	err = ri.writeRawEntries(rawEntries)
@@ -89,6 +97,34 @@ func (ri FileIndex) Save(key interface{}, value interface{}) error {
 	return nil
 }
 
+func (ri FileIndex) sortRawEntries(entries []indexEntry) error {
+	type sortableEntry struct {
+		key   string
+		entry indexEntry
+	}
+
+	sortable := make([]sortableEntry, len(entries))
+
+	for i, entry := range entries {
+		bytes, err := json.Marshal(entry.Key)
+		if err != nil {
+			return bosherr.WrapError(err, "Marshalling index entry key")
+		}
+
+		sortable[i] = sortableEntry{key: string(bytes), entry: entry}
+	}
+
+	sort.SliceStable(sortable, func(i, j int) bool {
+		return sortable[i].key < sortable[j].key
+	})
+
+	for i, s := range sortable {
+		entries[i] = s.entry
+	}
+
+	return nil
+}
+
 func (ri FileIndex) readRawEntries() ([]indexEntry, error) {
 	var entries []indexEntry
 
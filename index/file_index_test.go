@@ -149,6 +149,28 @@ var _ = Describe("FileIndex", func() {
 			})
 		})
 
+		It("writes the same file regardless of the order in which items are saved", func() {
+			err := index.Save(Key{Key: "key-2"}, Value{Name: "value-2"})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = index.Save(Key{Key: "key-1"}, Value{Name: "value-1"})
+			Expect(err).ToNot(HaveOccurred())
+
+			contents, err := fs.ReadFileString(indexFilePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.RemoveAll(indexFilePath)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = index.Save(Key{Key: "key-1"}, Value{Name: "value-1"})
+			Expect(err).ToNot(HaveOccurred())
+
+			err = index.Save(Key{Key: "key-2"}, Value{Name: "value-2"})
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(fs.ReadFileString(indexFilePath)).To(Equal(contents))
+		})
+
 		Context("when a new FileIndex is constructed backed by the same file", func() {
 			var (
 				index2 FileIndex
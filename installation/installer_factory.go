@@ -33,6 +33,9 @@ type installerFactory struct {
 	logTag                 string
 	fs                     boshsys.FileSystem
 	digestCreateAlgorithms []boshcrypto.Algorithm
+	compileWorkers         int
+	compiledPackagesCache  string
+	compiledPackageDigests string
 }
 
 func NewInstallerFactory(
@@ -44,6 +47,9 @@ func NewInstallerFactory(
 	logger boshlog.Logger,
 	fs boshsys.FileSystem,
 	digestCreateAlgorithms []boshcrypto.Algorithm,
+	compileWorkers int,
+	compiledPackagesCache string,
+	compiledPackageDigests string,
 ) InstallerFactory {
 	return &installerFactory{
 		ui:                     ui,
@@ -55,6 +61,9 @@ func NewInstallerFactory(
 		logTag:                 "installer",
 		fs:                     fs,
 		digestCreateAlgorithms: digestCreateAlgorithms,
+		compileWorkers:         compileWorkers,
+		compiledPackagesCache:  compiledPackagesCache,
+		compiledPackageDigests: compiledPackageDigests,
 	}
 }
 
@@ -68,6 +77,9 @@ func (f *installerFactory) NewInstaller(target Target) Installer {
 		releaseJobResolver:     f.releaseJobResolver,
 		fs:                     f.fs,
 		digestCreateAlgorithms: f.digestCreateAlgorithms,
+		compileWorkers:         f.compileWorkers,
+		compiledPackagesCache:  f.compiledPackagesCache,
+		compiledPackageDigests: f.compiledPackageDigests,
 	}
 
 	return NewInstaller(
@@ -95,6 +107,9 @@ type installerFactoryContext struct {
 	blobExtractor          blobextract.Extractor
 	compiledPackageRepo    bistatepkg.CompiledPackageRepo
 	digestCreateAlgorithms []boshcrypto.Algorithm
+	compileWorkers         int
+	compiledPackagesCache  string
+	compiledPackageDigests string
 }
 
 func (c *installerFactoryContext) JobRenderer() JobRenderer {
@@ -126,8 +141,9 @@ func (c *installerFactoryContext) JobDependencyCompiler() bistatejob.DependencyC
 		return c.jobDependencyCompiler
 	}
 
-	c.jobDependencyCompiler = bistatejob.NewDependencyCompiler(
+	c.jobDependencyCompiler = bistatejob.NewParallelDependencyCompiler(
 		c.InstallationStatePackageCompiler(),
+		c.compileWorkers,
 		c.logger,
 	)
 
@@ -150,6 +166,16 @@ func (c *installerFactoryContext) InstallationStatePackageCompiler() bistatepkg.
 		c.logger,
 	)
 
+	if c.compiledPackagesCache != "" {
+		c.packageCompiler = biinstallpkg.NewCachingPackageCompiler(
+			c.packageCompiler,
+			bistatepkg.NewCompiledPackageCache(c.compiledPackagesCache, c.target.PackagesPath(), c.compiledPackageDigests, c.fs, c.digestCreateAlgorithms, c.logger),
+			c.Blobstore(),
+			c.CompiledPackageRepo(),
+			c.logger,
+		)
+	}
+
 	return c.packageCompiler
 }
 
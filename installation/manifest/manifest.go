@@ -1,7 +1,11 @@
 package manifest
 
 import (
+	"time"
+
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
+
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
 )
 
 type Manifest struct {
@@ -10,6 +14,19 @@ type Manifest struct {
 	Properties biproperty.Map
 	Mbus       string
 	Cert       Certificate
+
+	// CPICalls are keyed by CPI method name or 'default'
+	CPICalls map[string]CPICallPolicy
+
+	// Hooks are ordered by point and then as listed in the manifest
+	Hooks []bihook.Hook
+}
+
+// CPICallPolicy values are nil when cpi_calls does not set them
+type CPICallPolicy struct {
+	Timeout    *time.Duration
+	Retries    *int
+	RetryDelay *time.Duration
 }
 
 type Certificate struct {
@@ -2,6 +2,7 @@ package manifest_test
 
 import (
 	"errors"
+	"time"
 
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	biproperty "github.com/cloudfoundry/bosh-utils/property"
@@ -11,6 +12,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
 	boshtpl "github.com/cloudfoundry/bosh-cli/v7/director/template"
 	"github.com/cloudfoundry/bosh-cli/v7/installation/manifest"
 	"github.com/cloudfoundry/bosh-cli/v7/installation/manifest/fakes"
@@ -119,6 +121,97 @@ cloud_provider:
 			})
 		})
 
+		Context("when cpi_calls are present", func() {
+			It("parses timeouts and retries per CPI method", func() {
+				err := fakeFs.WriteFileString(comboManifestPath, fixtures.validManifest+`
+  cpi_calls:
+    default:
+      timeout: 30m
+    create_vm:
+      timeout: 1h
+      retries: 3
+      retry_delay: 10s
+`)
+				Expect(err).ToNot(HaveOccurred())
+
+				installationManifest, err := parser.Parse(comboManifestPath, boshtpl.StaticVariables{}, patch.Ops{}, releaseSetManifest)
+				Expect(err).ToNot(HaveOccurred())
+
+				thirtyMinutes, oneHour, three, tenSeconds := 30*time.Minute, time.Hour, 3, 10*time.Second
+
+				Expect(installationManifest.CPICalls).To(Equal(map[string]manifest.CPICallPolicy{
+					"default":   {Timeout: &thirtyMinutes},
+					"create_vm": {Timeout: &oneHour, Retries: &three, RetryDelay: &tenSeconds},
+				}))
+			})
+
+			It("keeps retries set to zero apart from unset retries", func() {
+				err := fakeFs.WriteFileString(comboManifestPath, fixtures.validManifest+`
+  cpi_calls:
+    default:
+      retries: 2
+    create_vm:
+      retries: 0
+`)
+				Expect(err).ToNot(HaveOccurred())
+
+				installationManifest, err := parser.Parse(comboManifestPath, boshtpl.StaticVariables{}, patch.Ops{}, releaseSetManifest)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(*installationManifest.CPICalls["create_vm"].Retries).To(Equal(0))
+				Expect(installationManifest.CPICalls["create_vm"].Timeout).To(BeNil())
+			})
+
+			It("returns an error when a duration cannot be parsed", func() {
+				err := fakeFs.WriteFileString(comboManifestPath, fixtures.validManifest+`
+  cpi_calls:
+    create_vm:
+      timeout: forever
+`)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = parser.Parse(comboManifestPath, boshtpl.StaticVariables{}, patch.Ops{}, releaseSetManifest)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Parsing cloud_provider.cpi_calls.create_vm.timeout"))
+			})
+		})
+
+		Context("when hooks are present", func() {
+			It("parses the hooks by point with paths relative to the manifest", func() {
+				err := fakeFs.WriteFileString(comboManifestPath, fixtures.validManifest+`
+  hooks:
+    post-deploy:
+    - /usr/local/bin/notify-chat
+    post-vm-create:
+    - hooks/register-dns
+    - hooks/update-lb
+`)
+				Expect(err).ToNot(HaveOccurred())
+
+				installationManifest, err := parser.Parse(comboManifestPath, boshtpl.StaticVariables{}, patch.Ops{}, releaseSetManifest)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(installationManifest.Hooks).To(Equal([]bihook.Hook{
+					{Point: bihook.PostVMCreate, Path: "/path/to/hooks/register-dns"},
+					{Point: bihook.PostVMCreate, Path: "/path/to/hooks/update-lb"},
+					{Point: bihook.PostDeploy, Path: "/usr/local/bin/notify-chat"},
+				}))
+			})
+
+			It("returns an error for unknown hook points", func() {
+				err := fakeFs.WriteFileString(comboManifestPath, fixtures.validManifest+`
+  hooks:
+    post-lunch:
+    - /usr/local/bin/nap
+`)
+				Expect(err).ToNot(HaveOccurred())
+
+				_, err = parser.Parse(comboManifestPath, boshtpl.StaticVariables{}, patch.Ops{}, releaseSetManifest)
+				Expect(err).To(HaveOccurred())
+				Expect(err.Error()).To(ContainSubstring("Parsing cloud_provider.hooks: Expected hook point 'post-lunch' to be one of"))
+			})
+		})
+
 		Context("when ssh tunnel config is present", func() {
 			Context("with raw private key", func() {
 				Context("that is valid", func() {
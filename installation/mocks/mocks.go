@@ -51,6 +51,20 @@ func (mr *MockInstallationMockRecorder) Job() *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Job", reflect.TypeOf((*MockInstallation)(nil).Job))
 }
 
+// Manifest mocks base method.
+func (m *MockInstallation) Manifest() manifest.Manifest {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Manifest")
+	ret0, _ := ret[0].(manifest.Manifest)
+	return ret0
+}
+
+// Manifest indicates an expected call of Manifest.
+func (mr *MockInstallationMockRecorder) Manifest() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Manifest", reflect.TypeOf((*MockInstallation)(nil).Manifest))
+}
+
 // Target mocks base method.
 func (m *MockInstallation) Target() installation.Target {
 	m.ctrl.T.Helper()
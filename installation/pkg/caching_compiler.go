@@ -0,0 +1,115 @@
+package pkg
+
+import (
+	boshblob "github.com/cloudfoundry/bosh-utils/blobstore"
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+
+	birelpkg "github.com/cloudfoundry/bosh-cli/v7/release/pkg"
+	bistatepkg "github.com/cloudfoundry/bosh-cli/v7/state/pkg"
+)
+
+type cachingCompiler struct {
+	compiler            bistatepkg.Compiler
+	cache               bistatepkg.CompiledPackageCache
+	blobstore           boshblob.DigestBlobstore
+	compiledPackageRepo bistatepkg.CompiledPackageRepo
+	logger              boshlog.Logger
+	logTag              string
+}
+
+// NewCachingPackageCompiler consults the cache before compiling packages from source
+// and adds packages that were compiled from source to it
+func NewCachingPackageCompiler(
+	compiler bistatepkg.Compiler,
+	cache bistatepkg.CompiledPackageCache,
+	blobstore boshblob.DigestBlobstore,
+	compiledPackageRepo bistatepkg.CompiledPackageRepo,
+	logger boshlog.Logger,
+) bistatepkg.Compiler {
+	return &cachingCompiler{
+		compiler:            compiler,
+		cache:               cache,
+		blobstore:           blobstore,
+		compiledPackageRepo: compiledPackageRepo,
+		logger:              logger,
+		logTag:              "cachingPackageCompiler",
+	}
+}
+
+func (c *cachingCompiler) Compile(pkg birelpkg.Compilable) (bistatepkg.CompiledPackageRecord, bool, error) {
+	if _, isSourcePackage := pkg.(*birelpkg.Package); !isSourcePackage {
+		return c.compiler.Compile(pkg)
+	}
+
+	record, found, err := c.compiledPackageRepo.Find(pkg)
+	if err != nil {
+		return record, false, bosherr.WrapErrorf(err, "Attempting to find compiled package '%s'", pkg.Name())
+	}
+
+	if !found {
+		tarballPath, found, err := c.cache.Find(pkg)
+		if err != nil {
+			return record, false, bosherr.WrapErrorf(err, "Attempting to find cached compiled package '%s'", pkg.Name())
+		}
+
+		if found {
+			c.logger.Debug(c.logTag, "Using cached compiled package '%s/%s'", pkg.Name(), pkg.Fingerprint())
+
+			blobID, digest, err := c.blobstore.Create(tarballPath)
+			if err != nil {
+				return record, false, bosherr.WrapError(err, "Creating blob")
+			}
+
+			record = bistatepkg.CompiledPackageRecord{
+				BlobID:   blobID,
+				BlobSHA1: digest.String(),
+			}
+
+			err = c.compiledPackageRepo.Save(pkg, record)
+			if err != nil {
+				return record, false, bosherr.WrapError(err, "Saving compiled package")
+			}
+
+			return record, true, nil
+		}
+	}
+
+	record, isCompiledPackage, err := c.compiler.Compile(pkg)
+	if err != nil {
+		return record, isCompiledPackage, err
+	}
+
+	err = c.addToCache(pkg, record)
+	if err != nil {
+		// the cache only saves time so it should not fail the installation
+		c.logger.Warn(c.logTag, "Failed to add compiled package '%s/%s' to cache: %s", pkg.Name(), pkg.Fingerprint(), err.Error())
+	}
+
+	return record, isCompiledPackage, nil
+}
+
+func (c *cachingCompiler) addToCache(pkg birelpkg.Compilable, record bistatepkg.CompiledPackageRecord) error {
+	if c.cache.Has(pkg) {
+		return nil
+	}
+
+	digest, err := boshcrypto.ParseMultipleDigest(record.BlobSHA1)
+	if err != nil {
+		return bosherr.WrapError(err, "Parsing compiled package digest")
+	}
+
+	tarballPath, err := c.blobstore.Get(record.BlobID, digest)
+	if err != nil {
+		return bosherr.WrapError(err, "Getting compiled package from blobstore")
+	}
+
+	defer func() {
+		if err := c.blobstore.CleanUp(tarballPath); err != nil {
+			c.logger.Warn(c.logTag, "Failed to clean up blobstore file: %s", err.Error())
+		}
+	}()
+
+	return c.cache.Save(pkg, tarballPath)
+}
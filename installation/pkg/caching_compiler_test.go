@@ -0,0 +1,126 @@
+package pkg_test
+
+import (
+	fakeblobstore "github.com/cloudfoundry/bosh-utils/blobstore/fakes"
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	biindex "github.com/cloudfoundry/bosh-cli/v7/index"
+	. "github.com/cloudfoundry/bosh-cli/v7/installation/pkg"
+	birelpkg "github.com/cloudfoundry/bosh-cli/v7/release/pkg"
+	. "github.com/cloudfoundry/bosh-cli/v7/release/resource"
+	bistatepkg "github.com/cloudfoundry/bosh-cli/v7/state/pkg"
+	mockstatepackage "github.com/cloudfoundry/bosh-cli/v7/state/pkg/mocks"
+)
+
+var _ = Describe("CachingPackageCompiler", func() {
+	var (
+		mockCtrl         *gomock.Controller
+		mockCompiler     *mockstatepackage.MockCompiler
+		fs               *fakesys.FakeFileSystem
+		blobstore        *fakeblobstore.FakeDigestBlobstore
+		cache            bistatepkg.CompiledPackageCache
+		compiledPkgRepo  bistatepkg.CompiledPackageRepo
+		pkg              *birelpkg.Package
+		compiler         bistatepkg.Compiler
+		compiledDigest   boshcrypto.MultipleDigest
+		compiledContents = "compiled-contents"
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockCompiler = mockstatepackage.NewMockCompiler(mockCtrl)
+
+		fs = fakesys.NewFakeFileSystem()
+		blobstore = &fakeblobstore.FakeDigestBlobstore{}
+		algorithms := []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA1}
+		cache = bistatepkg.NewCompiledPackageCache("/cache", "/installations/fake-id/packages", "/digests.json", fs, algorithms, boshlog.NewLogger(boshlog.LevelNone))
+		compiledPkgRepo = bistatepkg.NewCompiledPackageRepo(biindex.NewFileIndex("/index.json", fs))
+
+		pkg = birelpkg.NewPackage(NewResource("pkg-name", "pkg-fp", nil), nil)
+
+		err := fs.WriteFileString("/compiled.tgz", compiledContents)
+		Expect(err).ToNot(HaveOccurred())
+
+		compiledDigest, err = boshcrypto.NewMultipleDigestFromPath("/compiled.tgz", fs, algorithms)
+		Expect(err).ToNot(HaveOccurred())
+
+		blobstore.GetReturns("/compiled.tgz", nil)
+		blobstore.CreateReturns("fake-blob-id", compiledDigest, nil)
+
+		compiler = NewCachingPackageCompiler(mockCompiler, cache, blobstore, compiledPkgRepo, boshlog.NewLogger(boshlog.LevelNone))
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	It("adds packages compiled from source to the cache", func() {
+		record := bistatepkg.CompiledPackageRecord{BlobID: "fake-blob-id", BlobSHA1: compiledDigest.String()}
+		mockCompiler.EXPECT().Compile(pkg).Return(record, false, nil)
+
+		result, isAlreadyCompiled, err := compiler.Compile(pkg)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(result).To(Equal(record))
+		Expect(isAlreadyCompiled).To(BeFalse())
+
+		Expect(cache.Has(pkg)).To(BeTrue())
+		blobID, _ := blobstore.GetArgsForCall(0)
+		Expect(blobID).To(Equal("fake-blob-id"))
+		Expect(blobstore.CleanUpArgsForCall(0)).To(Equal("/compiled.tgz"))
+	})
+
+	Context("when the package is cached", func() {
+		BeforeEach(func() {
+			err := cache.Save(pkg, "/compiled.tgz")
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("uses the cached package instead of compiling it", func() {
+			mockCompiler.EXPECT().Compile(gomock.Any()).Times(0)
+
+			result, isAlreadyCompiled, err := compiler.Compile(pkg)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(isAlreadyCompiled).To(BeTrue())
+			Expect(result).To(Equal(bistatepkg.CompiledPackageRecord{BlobID: "fake-blob-id", BlobSHA1: compiledDigest.String()}))
+
+			savedRecord, found, err := compiledPkgRepo.Find(pkg)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(found).To(BeTrue())
+			Expect(savedRecord).To(Equal(result))
+
+			cachedPath, _, err := cache.Find(pkg)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(blobstore.CreateArgsForCall(0)).To(Equal(cachedPath))
+		})
+
+		It("compiles the package again without replacing a cached package that was tampered with", func() {
+			cachedPath, _, err := cache.Find(pkg)
+			Expect(err).ToNot(HaveOccurred())
+
+			// the fake file system shares contents between copies
+			err = fs.RemoveAll(cachedPath)
+			Expect(err).ToNot(HaveOccurred())
+
+			err = fs.WriteFileString(cachedPath, "poisoned-contents")
+			Expect(err).ToNot(HaveOccurred())
+
+			record := bistatepkg.CompiledPackageRecord{BlobID: "fake-blob-id", BlobSHA1: compiledDigest.String()}
+			mockCompiler.EXPECT().Compile(pkg).Return(record, false, nil)
+
+			result, isAlreadyCompiled, err := compiler.Compile(pkg)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(result).To(Equal(record))
+			Expect(isAlreadyCompiled).To(BeFalse())
+			Expect(blobstore.CreateCallCount()).To(Equal(0))
+
+			// other machines may trust the cached package, so it is only removed by pruning the cache
+			Expect(cache.Has(pkg)).To(BeTrue())
+			Expect(fs.ReadFileString(cachedPath)).To(Equal("poisoned-contents"))
+		})
+	})
+})
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"sync"
 
 	boshblob "github.com/cloudfoundry/bosh-utils/blobstore"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
@@ -26,6 +27,12 @@ type compiler struct {
 	blobExtractor       blobextract.Extractor
 	logger              boshlog.Logger
 	logTag              string
+
+	// packages are compiled concurrently in the same packages dir,
+	// so that they are built for the path they are installed at
+	packagesDirLock   sync.Mutex
+	compilations      int
+	installedPackages map[string]int
 }
 
 func NewPackageCompiler(
@@ -48,6 +55,7 @@ func NewPackageCompiler(
 		blobExtractor:       blobExtractor,
 		logger:              logger,
 		logTag:              "packageCompiler",
+		installedPackages:   map[string]int{},
 	}
 }
 
@@ -65,17 +73,15 @@ func (c *compiler) Compile(pkg birelpkg.Compilable) (bistatepkg.CompiledPackageR
 
 	c.logger.Debug(c.logTag, "Installing dependencies of package '%s/%s'", pkg.Name(), pkg.Fingerprint())
 
-	err = c.installPackages(pkg.Deps())
+	c.beginCompilation()
+	defer c.endCompilation()
+
+	installedDeps, err := c.installPackages(pkg.Deps())
+	defer c.uninstallPackages(installedDeps)
 	if err != nil {
 		return record, isCompiledPackage, bosherr.WrapErrorf(err, "Installing dependencies of package '%s'", pkg.Name())
 	}
 
-	defer func() {
-		if err = c.fileSystem.RemoveAll(c.packagesDir); err != nil {
-			c.logger.Warn(c.logTag, "Failed to remove packages dir: %s", err.Error())
-		}
-	}()
-
 	var tarball string
 	switch v := pkg.(type) {
 	case *birelpkg.Package:
@@ -88,6 +94,8 @@ func (c *compiler) Compile(pkg birelpkg.Compilable) (bistatepkg.CompiledPackageR
 			return record, isCompiledPackage, bosherr.WrapError(err, "Creating package install dir")
 		}
 
+		defer c.removeInstallDir(pkg)
+
 		packageSrcDir := pkg.(*birelpkg.Package).ExtractedPath()
 
 		if !c.fileSystem.FileExists(filepath.Join(packageSrcDir, "packaging")) {
@@ -151,24 +159,96 @@ func (c *compiler) Compile(pkg birelpkg.Compilable) (bistatepkg.CompiledPackageR
 	return record, isCompiledPackage, nil
 }
 
-func (c *compiler) installPackages(packages []birelpkg.Compilable) error {
+func (c *compiler) beginCompilation() {
+	c.packagesDirLock.Lock()
+	defer c.packagesDirLock.Unlock()
+
+	c.compilations++
+}
+
+// endCompilation removes the packages dir once no package is being compiled
+func (c *compiler) endCompilation() {
+	c.packagesDirLock.Lock()
+	defer c.packagesDirLock.Unlock()
+
+	c.compilations--
+	if c.compilations > 0 {
+		return
+	}
+
+	if err := c.fileSystem.RemoveAll(c.packagesDir); err != nil {
+		c.logger.Warn(c.logTag, "Failed to remove packages dir: %s", err.Error())
+	}
+}
+
+// installPackages installs the packages into the packages dir unless another
+// compilation already installed them, and returns the packages it installed
+// or found installed so that they are uninstalled once they are not needed
+func (c *compiler) installPackages(packages []birelpkg.Compilable) ([]birelpkg.Compilable, error) {
+	c.packagesDirLock.Lock()
+	defer c.packagesDirLock.Unlock()
+
+	installed := []birelpkg.Compilable{}
+
 	for _, pkg := range packages {
+		if c.installedPackages[pkg.Name()] > 0 {
+			c.installedPackages[pkg.Name()]++
+			installed = append(installed, pkg)
+			continue
+		}
+
 		c.logger.Debug(c.logTag, "Checking for compiled package '%s/%s'", pkg.Name(), pkg.Fingerprint())
 
 		record, found, err := c.compiledPackageRepo.Find(pkg)
 		if err != nil {
-			return bosherr.WrapErrorf(err, "Attempting to find compiled package '%s'", pkg.Name())
+			return installed, bosherr.WrapErrorf(err, "Attempting to find compiled package '%s'", pkg.Name())
 		} else if !found {
-			return bosherr.Errorf("Finding compiled package '%s'", pkg.Name())
+			return installed, bosherr.Errorf("Finding compiled package '%s'", pkg.Name())
 		}
 
 		c.logger.Debug(c.logTag, "Installing package '%s/%s'", pkg.Name(), pkg.Fingerprint())
 
 		err = c.blobExtractor.Extract(record.BlobID, record.BlobSHA1, filepath.Join(c.packagesDir, pkg.Name()))
 		if err != nil {
-			return bosherr.WrapErrorf(err, "Installing package '%s' into '%s'", pkg.Name(), c.packagesDir)
+			return installed, bosherr.WrapErrorf(err, "Installing package '%s' into '%s'", pkg.Name(), c.packagesDir)
 		}
+
+		c.installedPackages[pkg.Name()]++
+		installed = append(installed, pkg)
 	}
 
-	return nil
+	return installed, nil
+}
+
+func (c *compiler) uninstallPackages(packages []birelpkg.Compilable) {
+	c.packagesDirLock.Lock()
+	defer c.packagesDirLock.Unlock()
+
+	for _, pkg := range packages {
+		c.installedPackages[pkg.Name()]--
+		if c.installedPackages[pkg.Name()] > 0 {
+			continue
+		}
+
+		delete(c.installedPackages, pkg.Name())
+
+		if err := c.fileSystem.RemoveAll(filepath.Join(c.packagesDir, pkg.Name())); err != nil {
+			c.logger.Warn(c.logTag, "Failed to remove installed package '%s': %s", pkg.Name(), err.Error())
+		}
+	}
+}
+
+// removeInstallDir removes the compiled files of the package; packages are only
+// compiled after their dependencies, so no other compilation has it installed
+func (c *compiler) removeInstallDir(pkg birelpkg.Compilable) {
+	c.packagesDirLock.Lock()
+	defer c.packagesDirLock.Unlock()
+
+	if c.installedPackages[pkg.Name()] > 0 {
+		return
+	}
+
+	if err := c.fileSystem.RemoveAll(filepath.Join(c.packagesDir, pkg.Name())); err != nil {
+		c.logger.Warn(c.logTag, "Failed to remove install dir of package '%s': %s", pkg.Name(), err.Error())
+	}
 }
@@ -231,6 +231,56 @@ var _ = Describe("PackageCompiler", func() {
 				Expect(fs.FileExists(packagesDir)).To(BeFalse())
 			})
 
+			Context("when another package is compiled at the same time", func() {
+				var (
+					otherPkg            *birelpkg.Package
+					otherCompileErr     error
+					dep1InstalledDuring bool
+				)
+
+				JustBeforeEach(func() {
+					otherPkg = birelpkg.NewExtractedPackage(NewResource("pkg2-name", "", nil), []string{"pkg-dep1-name"}, "/pkg-dir", fs)
+					err := otherPkg.AttachDependencies([]*birelpkg.Package{dependency1})
+					Expect(err).ToNot(HaveOccurred())
+
+					mockCompiledPackageRepo.EXPECT().Find(otherPkg).Return(bistatepkg.CompiledPackageRecord{}, false, nil)
+					mockCompiledPackageRepo.EXPECT().Save(otherPkg, gomock.Any())
+
+					fakeExtractor.ExtractStub = func(_, _, path string) error {
+						return fs.MkdirAll(path, os.ModePerm)
+					}
+
+					blobstore.CreateStub = func(string) (string, boshcrypto.MultipleDigest, error) {
+						if blobstore.CreateCallCount() == 1 {
+							_, _, otherCompileErr = compiler.Compile(otherPkg)
+							dep1InstalledDuring = fs.FileExists(filepath.Join(packagesDir, "pkg-dep1-name"))
+						}
+						return "fake-blob-id", boshcrypto.MustParseMultipleDigest("fakefingerprint"), nil
+					}
+				})
+
+				It("compiles both packages with their final install path as install target", func() {
+					_, _, err := compiler.Compile(pkg)
+					Expect(err).ToNot(HaveOccurred())
+					Expect(otherCompileErr).ToNot(HaveOccurred())
+
+					Expect(runner.RunComplexCommands).To(HaveLen(2))
+					Expect(runner.RunComplexCommands[0].Env["BOSH_INSTALL_TARGET"]).To(Equal(installPath))
+					Expect(runner.RunComplexCommands[0].Env["BOSH_PACKAGES_DIR"]).To(Equal(packagesDir))
+					Expect(runner.RunComplexCommands[1].Env["BOSH_INSTALL_TARGET"]).To(Equal(filepath.Join(packagesDir, "pkg2-name")))
+					Expect(runner.RunComplexCommands[1].Env["BOSH_PACKAGES_DIR"]).To(Equal(packagesDir))
+				})
+
+				It("keeps shared dependencies installed until no compilation uses them", func() {
+					_, _, err := compiler.Compile(pkg)
+					Expect(err).ToNot(HaveOccurred())
+
+					Expect(fakeExtractor.ExtractCallCount()).To(Equal(2))
+					Expect(dep1InstalledDuring).To(BeTrue())
+					Expect(fs.FileExists(packagesDir)).To(BeFalse())
+				})
+			})
+
 			Context("when dependency installation fails", func() {
 				JustBeforeEach(func() {
 					fakeExtractor.ExtractReturns(errors.New("fake-install-error"))
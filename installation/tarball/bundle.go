@@ -0,0 +1,398 @@
+package tarball
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshfu "github.com/cloudfoundry/bosh-utils/fileutil"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	urlhelper "github.com/cloudfoundry/bosh-cli/v7/common/util"
+	bicrypto "github.com/cloudfoundry/bosh-cli/v7/crypto"
+	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
+)
+
+const (
+	BundleIndexName    = "index.json"
+	BundleManifestName = "manifest.yml"
+	bundleTarballsDir  = "tarballs"
+)
+
+// BundleIndex lists the interpolated manifest of a bundle and its tarballs
+// with the sources they satisfy. Its digests only detect corruption of the
+// bundle in transport since whoever can change a tarball can change the index
+// too; tarballs are verified against the sha1 of their source when it has one.
+type BundleIndex struct {
+	// Manifest is relative to the root of the bundle
+	Manifest       string `json:"manifest"`
+	ManifestDigest string `json:"manifest_digest"`
+
+	Tarballs []BundleEntry `json:"tarballs"`
+}
+
+type BundleEntry struct {
+	URL         string `json:"url"`
+	SHA1        string `json:"sha1"`
+	Description string `json:"description"`
+
+	// Path is relative to the root of the bundle
+	Path   string `json:"path"`
+	Digest string `json:"digest"`
+}
+
+// BundleWriter resolves tarball sources and writes them with the interpolated
+// manifest into one archive. The manifest contains the values of its variables,
+// so bundles need to be protected like the variables themselves.
+type BundleWriter interface {
+	Write(manifest []byte, sources []Source, destinationPath string, stage biui.Stage) error
+}
+
+type bundleWriter struct {
+	provider         Provider
+	fs               boshsys.FileSystem
+	compressor       boshfu.Compressor
+	digestCalculator bicrypto.DigestCalculator
+	logger           boshlog.Logger
+	logTag           string
+}
+
+func NewBundleWriter(
+	provider Provider,
+	fs boshsys.FileSystem,
+	compressor boshfu.Compressor,
+	digestCalculator bicrypto.DigestCalculator,
+	logger boshlog.Logger,
+) BundleWriter {
+	return bundleWriter{
+		provider:         provider,
+		fs:               fs,
+		compressor:       compressor,
+		digestCalculator: digestCalculator,
+		logger:           logger,
+		logTag:           "bundleWriter",
+	}
+}
+
+func (w bundleWriter) Write(manifest []byte, sources []Source, destinationPath string, stage biui.Stage) error {
+	bundleDir, err := w.fs.TempDir("bosh-bundle")
+	if err != nil {
+		return bosherr.WrapError(err, "Creating bundle directory")
+	}
+
+	defer func() {
+		if err := w.fs.RemoveAll(bundleDir); err != nil {
+			w.logger.Warn(w.logTag, "Failed to remove bundle directory: %s", err.Error())
+		}
+	}()
+
+	err = w.fs.MkdirAll(filepath.Join(bundleDir, bundleTarballsDir), os.ModePerm)
+	if err != nil {
+		return bosherr.WrapError(err, "Creating bundle tarballs directory")
+	}
+
+	index := BundleIndex{Manifest: BundleManifestName}
+
+	manifestPath := filepath.Join(bundleDir, BundleManifestName)
+
+	err = w.fs.WriteFile(manifestPath, manifest)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing bundle manifest")
+	}
+
+	index.ManifestDigest, err = w.digestCalculator.Calculate(manifestPath)
+	if err != nil {
+		return bosherr.WrapError(err, "Calculating digest of bundle manifest")
+	}
+
+	err = w.provider.Prefetch(sources, stage)
+	if err != nil {
+		return err
+	}
+
+	added := map[string]bool{}
+
+	for _, source := range sources {
+		if added[source.GetURL()] {
+			continue
+		}
+
+		tarballPath, err := w.provider.Get(source, stage)
+		if err != nil {
+			return err
+		}
+
+		entry := BundleEntry{
+			URL:         source.GetURL(),
+			SHA1:        source.GetSHA1(),
+			Description: source.Description(),
+			Path:        path.Join(bundleTarballsDir, fmt.Sprintf("%d.tgz", len(index.Tarballs))),
+		}
+
+		err = stage.Perform(fmt.Sprintf("Adding %s to bundle", source.Description()), func() error {
+			if len(source.GetSHA1()) > 0 {
+				digest, err := boshcrypto.ParseMultipleDigest(source.GetSHA1())
+				if err != nil {
+					return bosherr.WrapErrorf(err, "Parsing digest of %s", source.Description())
+				}
+
+				err = digest.VerifyFilePath(tarballPath, w.fs)
+				if err != nil {
+					return bosherr.WrapErrorf(err, "Verifying digest of %s", source.Description())
+				}
+			}
+
+			entryPath := filepath.Join(bundleDir, filepath.FromSlash(entry.Path))
+
+			err := w.fs.CopyFile(tarballPath, entryPath)
+			if err != nil {
+				return bosherr.WrapErrorf(err, "Copying '%s' into bundle", tarballPath)
+			}
+
+			entry.Digest, err = w.digestCalculator.Calculate(entryPath)
+			if err != nil {
+				return bosherr.WrapErrorf(err, "Calculating digest of '%s'", tarballPath)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		index.Tarballs = append(index.Tarballs, entry)
+		added[source.GetURL()] = true
+	}
+
+	indexBytes, err := json.MarshalIndent(index, "", "  ")
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling bundle index")
+	}
+
+	err = w.fs.WriteFile(filepath.Join(bundleDir, BundleIndexName), indexBytes)
+	if err != nil {
+		return bosherr.WrapError(err, "Writing bundle index")
+	}
+
+	return stage.Perform(fmt.Sprintf("Writing bundle to '%s'", destinationPath), func() error {
+		archivePath, err := w.compressor.CompressFilesInDir(bundleDir)
+		if err != nil {
+			return bosherr.WrapError(err, "Compressing bundle")
+		}
+
+		err = boshfu.NewFileMover(w.fs).Move(archivePath, destinationPath)
+		if err != nil {
+			w.compressor.CleanUp(archivePath) //nolint:errcheck
+			return bosherr.WrapErrorf(err, "Moving bundle to '%s'", destinationPath)
+		}
+
+		return nil
+	})
+}
+
+// Bundle is an extracted bundle; as a Provider it returns its tarballs
+// instead of downloading them
+type Bundle interface {
+	Provider
+
+	// VerifyManifest returns an error unless the interpolated manifest
+	// is the manifest of the bundle
+	VerifyManifest(manifest []byte) error
+
+	Cleanup() error
+}
+
+// BundleReader extracts a bundle and verifies its tarballs against its index
+type BundleReader interface {
+	Read(path string) (Bundle, error)
+}
+
+type bundleReader struct {
+	fs         boshsys.FileSystem
+	compressor boshfu.Compressor
+	logger     boshlog.Logger
+}
+
+func NewBundleReader(fs boshsys.FileSystem, compressor boshfu.Compressor, logger boshlog.Logger) BundleReader {
+	return bundleReader{
+		fs:         fs,
+		compressor: compressor,
+		logger:     logger,
+	}
+}
+
+func (r bundleReader) Read(bundlePath string) (Bundle, error) {
+	bundleDir, err := r.fs.TempDir("bosh-bundle")
+	if err != nil {
+		return nil, bosherr.WrapError(err, "Creating bundle directory")
+	}
+
+	b := &bundle{
+		path:   bundlePath,
+		dir:    bundleDir,
+		fs:     r.fs,
+		logger: r.logger,
+		logTag: "bundle",
+	}
+
+	err = b.extract(r.compressor)
+	if err != nil {
+		b.Cleanup() //nolint:errcheck
+		return nil, err
+	}
+
+	return b, nil
+}
+
+type bundle struct {
+	path  string
+	dir   string
+	index BundleIndex
+
+	fs     boshsys.FileSystem
+	logger boshlog.Logger
+	logTag string
+}
+
+func (b *bundle) extract(compressor boshfu.Compressor) error {
+	err := compressor.DecompressFileToDir(b.path, b.dir, boshfu.CompressorOptions{})
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Extracting bundle '%s'", b.path)
+	}
+
+	indexBytes, err := b.fs.ReadFile(filepath.Join(b.dir, BundleIndexName))
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading index of bundle '%s'", b.path)
+	}
+
+	err = json.Unmarshal(indexBytes, &b.index)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Unmarshalling index of bundle '%s'", b.path)
+	}
+
+	manifestDigest, err := boshcrypto.ParseMultipleDigest(b.index.ManifestDigest)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Parsing digest of the manifest in bundle '%s'", b.path)
+	}
+
+	err = manifestDigest.VerifyFilePath(b.manifestPath(), b.fs)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Verifying digest of the manifest in bundle '%s'", b.path)
+	}
+
+	for _, entry := range b.index.Tarballs {
+		digest, err := boshcrypto.ParseMultipleDigest(entry.Digest)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Parsing digest of %s in bundle", entry.Description)
+		}
+
+		err = digest.VerifyFilePath(b.entryPath(entry), b.fs)
+		if err != nil {
+			return bosherr.WrapErrorf(err, "Verifying digest of %s in bundle", entry.Description)
+		}
+	}
+
+	return nil
+}
+
+func (b *bundle) VerifyManifest(manifest []byte) error {
+	bundledManifest, err := b.fs.ReadFile(b.manifestPath())
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Reading the manifest of bundle '%s'", b.path)
+	}
+
+	if !bytes.Equal(manifest, bundledManifest) {
+		return bosherr.Errorf("Expected the manifest interpolated with the given variables and ops files to be the manifest of bundle '%s'", b.path)
+	}
+
+	return nil
+}
+
+func (b *bundle) Get(source Source, stage biui.Stage) (string, error) {
+	entry, found := b.find(source)
+	if !found {
+		return "", bosherr.Errorf("Expected bundle '%s' to contain %s from '%s'",
+			b.path, source.Description(), urlhelper.RedactBasicAuth(source.GetURL()))
+	}
+
+	if len(entry.SHA1) > 0 && len(source.GetSHA1()) > 0 && entry.SHA1 != source.GetSHA1() {
+		return "", bosherr.Errorf("Expected %s in bundle '%s' to have sha1 '%s' but it has '%s'",
+			source.Description(), b.path, source.GetSHA1(), entry.SHA1)
+	}
+
+	entryPath := b.entryPath(entry)
+
+	// the digests of the index do not protect against a modified bundle
+	if len(source.GetSHA1()) > 0 {
+		digest, err := boshcrypto.ParseMultipleDigest(source.GetSHA1())
+		if err != nil {
+			return "", bosherr.WrapErrorf(err, "Parsing digest of %s", source.Description())
+		}
+
+		err = digest.VerifyFilePath(entryPath, b.fs)
+		if err != nil {
+			return "", bosherr.WrapErrorf(err, "Verifying digest of %s in bundle '%s'", source.Description(), b.path)
+		}
+	}
+
+	b.logger.Debug(b.logTag, "Using the tarball from bundle: '%s'", entryPath)
+
+	return entryPath, nil
+}
+
+// Prefetch checks that the bundle has every source before any of them is used
+func (b *bundle) Prefetch(sources []Source, stage biui.Stage) error {
+	var errs []error
+
+	for _, source := range sources {
+		if _, found := b.find(source); !found {
+			errs = append(errs, bosherr.Errorf("Expected bundle '%s' to contain %s from '%s'",
+				b.path, source.Description(), urlhelper.RedactBasicAuth(source.GetURL())))
+		}
+	}
+
+	if len(errs) > 0 {
+		return bosherr.NewMultiError(errs...)
+	}
+
+	return nil
+}
+
+// find matches sources by URL first; local file URLs differ between machines
+// so sources are then matched by sha1, which Get verifies. Local tarballs
+// without a sha1 are only matched by their exact URL.
+func (b *bundle) find(source Source) (BundleEntry, bool) {
+	for _, entry := range b.index.Tarballs {
+		if entry.URL == source.GetURL() {
+			return entry, true
+		}
+	}
+
+	if len(source.GetSHA1()) > 0 {
+		for _, entry := range b.index.Tarballs {
+			if entry.SHA1 == source.GetSHA1() || entry.Digest == source.GetSHA1() {
+				return entry, true
+			}
+		}
+	}
+
+	return BundleEntry{}, false
+}
+
+func (b *bundle) manifestPath() string {
+	return filepath.Join(b.dir, filepath.FromSlash(b.index.Manifest))
+}
+
+func (b *bundle) entryPath(entry BundleEntry) string {
+	return filepath.Join(b.dir, filepath.FromSlash(entry.Path))
+}
+
+func (b *bundle) Cleanup() error {
+	return b.fs.RemoveAll(b.dir)
+}
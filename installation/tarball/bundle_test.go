@@ -0,0 +1,273 @@
+package tarball_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	boshfu "github.com/cloudfoundry/bosh-utils/fileutil"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	"github.com/golang/mock/gomock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	bicrypto "github.com/cloudfoundry/bosh-cli/v7/crypto"
+	. "github.com/cloudfoundry/bosh-cli/v7/installation/tarball"
+	mock_tarball "github.com/cloudfoundry/bosh-cli/v7/installation/tarball/mocks"
+	fakebiui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("Bundle", func() {
+	var (
+		mockCtrl     *gomock.Controller
+		mockProvider *mock_tarball.MockProvider
+		fs           boshsys.FileSystem
+		compressor   boshfu.Compressor
+		fakeStage    *fakebiui.FakeStage
+		writer       BundleWriter
+		reader       BundleReader
+		tmpDir       string
+		bundlePath   string
+		manifest     []byte
+
+		releaseSource  *fakeSource
+		stemcellSource *fakeSource
+	)
+
+	BeforeEach(func() {
+		mockCtrl = gomock.NewController(GinkgoT())
+		mockProvider = mock_tarball.NewMockProvider(mockCtrl)
+
+		logger := boshlog.NewLogger(boshlog.LevelNone)
+		fs = boshsys.NewOsFileSystem(logger)
+		compressor = boshfu.NewTarballCompressor(boshsys.NewExecCmdRunner(logger), fs)
+		digestCalculator := bicrypto.NewDigestCalculator(fs, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA1})
+		fakeStage = fakebiui.NewFakeStage()
+
+		writer = NewBundleWriter(mockProvider, fs, compressor, digestCalculator, logger)
+		reader = NewBundleReader(fs, compressor, logger)
+
+		tmpDir = GinkgoT().TempDir()
+		bundlePath = filepath.Join(tmpDir, "bundle.tgz")
+
+		Expect(os.WriteFile(filepath.Join(tmpDir, "release.tgz"), []byte("release-content"), 0644)).To(Succeed())
+		Expect(os.WriteFile(filepath.Join(tmpDir, "stemcell.tgz"), []byte("stemcell-content"), 0644)).To(Succeed())
+
+		manifest = []byte("password: fake-admin-password")
+
+		releaseSource = newFakeSource("file:///path/to/release.tgz", "", "release 'fake-release'")
+		stemcellSource = newFakeSource("https://example.com/stemcell.tgz", sha1Of("stemcell-content"), "stemcell")
+	})
+
+	AfterEach(func() {
+		mockCtrl.Finish()
+	})
+
+	writeBundle := func() {
+		mockProvider.EXPECT().Prefetch([]Source{releaseSource, stemcellSource, releaseSource}, fakeStage).Return(nil)
+		mockProvider.EXPECT().Get(releaseSource, fakeStage).Return(filepath.Join(tmpDir, "release.tgz"), nil)
+		mockProvider.EXPECT().Get(stemcellSource, fakeStage).Return(filepath.Join(tmpDir, "stemcell.tgz"), nil)
+
+		err := writer.Write(manifest, []Source{releaseSource, stemcellSource, releaseSource}, bundlePath, fakeStage)
+		Expect(err).ToNot(HaveOccurred())
+	}
+
+	Describe("Write", func() {
+		It("writes the interpolated manifest", func() {
+			writeBundle()
+
+			extractedDir := GinkgoT().TempDir()
+			Expect(compressor.DecompressFileToDir(bundlePath, extractedDir, boshfu.CompressorOptions{})).To(Succeed())
+
+			Expect(fs.ReadFileString(filepath.Join(extractedDir, BundleManifestName))).To(Equal("password: fake-admin-password"))
+		})
+
+		It("writes each tarball once", func() {
+			writeBundle()
+
+			Expect(fakeStage.PerformCalls).To(Equal([]*fakebiui.PerformCall{
+				{Name: "Adding release 'fake-release' to bundle"},
+				{Name: "Adding stemcell to bundle"},
+				{Name: fmt.Sprintf("Writing bundle to '%s'", bundlePath)},
+			}))
+
+			extractedDir := GinkgoT().TempDir()
+			Expect(compressor.DecompressFileToDir(bundlePath, extractedDir, boshfu.CompressorOptions{})).To(Succeed())
+
+			index, err := fs.ReadFileString(filepath.Join(extractedDir, BundleIndexName))
+			Expect(err).ToNot(HaveOccurred())
+			Expect(index).To(MatchJSON(fmt.Sprintf(`{
+				"manifest": "manifest.yml",
+				"manifest_digest": "%s",
+				"tarballs": [
+					{"url": "file:///path/to/release.tgz", "sha1": "", "description": "release 'fake-release'", "path": "tarballs/0.tgz", "digest": "%s"},
+					{"url": "https://example.com/stemcell.tgz", "sha1": "%s", "description": "stemcell", "path": "tarballs/1.tgz", "digest": "%s"}
+				]
+			}`, sha1Of("password: fake-admin-password"), sha1Of("release-content"), sha1Of("stemcell-content"), sha1Of("stemcell-content"))))
+		})
+
+		It("returns an error when a tarball does not match its sha1", func() {
+			stemcellSource = newFakeSource("https://example.com/stemcell.tgz", sha1Of("other-content"), "stemcell")
+			mockProvider.EXPECT().Prefetch([]Source{stemcellSource}, fakeStage).Return(nil)
+			mockProvider.EXPECT().Get(stemcellSource, fakeStage).Return(filepath.Join(tmpDir, "stemcell.tgz"), nil)
+
+			err := writer.Write(manifest, []Source{stemcellSource}, bundlePath, fakeStage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Verifying digest of stemcell"))
+			Expect(fs.FileExists(bundlePath)).To(BeFalse())
+		})
+
+		It("returns an error when tarballs cannot be downloaded", func() {
+			mockProvider.EXPECT().Prefetch([]Source{stemcellSource}, fakeStage).Return(fmt.Errorf("fake-prefetch-err"))
+
+			err := writer.Write(manifest, []Source{stemcellSource}, bundlePath, fakeStage)
+			Expect(err).To(MatchError("fake-prefetch-err"))
+		})
+
+		It("returns an error when a tarball cannot be resolved", func() {
+			mockProvider.EXPECT().Prefetch([]Source{stemcellSource}, fakeStage).Return(nil)
+			mockProvider.EXPECT().Get(stemcellSource, fakeStage).Return("", fmt.Errorf("fake-get-err"))
+
+			err := writer.Write(manifest, []Source{stemcellSource}, bundlePath, fakeStage)
+			Expect(err).To(MatchError("fake-get-err"))
+		})
+	})
+
+	Describe("Read", func() {
+		var bundle Bundle
+
+		BeforeEach(func() {
+			writeBundle()
+
+			var err error
+			bundle, err = reader.Read(bundlePath)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		AfterEach(func() {
+			Expect(bundle.Cleanup()).To(Succeed())
+		})
+
+		It("provides tarballs by URL", func() {
+			path, err := bundle.Get(stemcellSource, fakeStage)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fs.ReadFileString(path)).To(Equal("stemcell-content"))
+		})
+
+		It("provides tarballs by sha1 when their URL differs", func() {
+			path, err := bundle.Get(newFakeSource("https://mirror.example.com/stemcell.tgz", sha1Of("stemcell-content"), "stemcell"), fakeStage)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fs.ReadFileString(path)).To(Equal("stemcell-content"))
+		})
+
+		It("does not provide local tarballs without a sha1 by file name only", func() {
+			_, err := bundle.Get(newFakeSource("file:///other/path/release.tgz", "", "release 'fake-release'"), fakeStage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("to contain release 'fake-release' from 'file:///other/path/release.tgz'"))
+		})
+
+		It("verifies tarballs without a sha1 in the bundle against the sha1 of the source", func() {
+			path, err := bundle.Get(newFakeSource("file:///other/path/release.tgz", sha1Of("release-content"), "release 'fake-release'"), fakeStage)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(fs.ReadFileString(path)).To(Equal("release-content"))
+
+			_, err = bundle.Get(newFakeSource("file:///path/to/release.tgz", sha1Of("other-content"), "release 'fake-release'"), fakeStage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Verifying digest of release 'fake-release' in bundle"))
+		})
+
+		It("returns an error for tarballs it does not contain", func() {
+			_, err := bundle.Get(newFakeSource("https://example.com/other.tgz", "", "other"), fakeStage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("to contain other from 'https://example.com/other.tgz'"))
+		})
+
+		It("returns an error when the sha1 of the source differs", func() {
+			_, err := bundle.Get(newFakeSource("https://example.com/stemcell.tgz", "other-sha1", "stemcell"), fakeStage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("to have sha1 'other-sha1'"))
+		})
+
+		It("prefetches sources it contains", func() {
+			err := bundle.Prefetch([]Source{stemcellSource, releaseSource}, fakeStage)
+			Expect(err).ToNot(HaveOccurred())
+		})
+
+		It("returns an error when prefetching sources it does not contain", func() {
+			err := bundle.Prefetch([]Source{stemcellSource, newFakeSource("https://example.com/other.tgz", "", "other")}, fakeStage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("to contain other from 'https://example.com/other.tgz'"))
+		})
+
+		It("verifies the interpolated manifest is the manifest of the bundle", func() {
+			Expect(bundle.VerifyManifest([]byte("password: fake-admin-password"))).To(Succeed())
+
+			err := bundle.VerifyManifest([]byte("password: other-admin-password"))
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("to be the manifest of bundle"))
+		})
+
+		It("removes the extracted bundle on cleanup", func() {
+			path, err := bundle.Get(stemcellSource, fakeStage)
+			Expect(err).ToNot(HaveOccurred())
+			Expect(bundle.Cleanup()).To(Succeed())
+			Expect(fs.FileExists(path)).To(BeFalse())
+		})
+	})
+
+	Describe("Read with a modified bundle", func() {
+		compressBundle := func(manifest string, manifestDigest string, tarballs string) string {
+			bundleDir := GinkgoT().TempDir()
+			Expect(fs.MkdirAll(filepath.Join(bundleDir, "tarballs"), os.ModePerm)).To(Succeed())
+			Expect(fs.WriteFileString(filepath.Join(bundleDir, "tarballs", "0.tgz"), "modified-content")).To(Succeed())
+			Expect(fs.WriteFileString(filepath.Join(bundleDir, BundleManifestName), manifest)).To(Succeed())
+			Expect(fs.WriteFileString(filepath.Join(bundleDir, BundleIndexName), fmt.Sprintf(`{
+				"manifest": "manifest.yml",
+				"manifest_digest": "%s",
+				"tarballs": %s
+			}`, manifestDigest, tarballs))).To(Succeed())
+
+			archivePath, err := compressor.CompressFilesInDir(bundleDir)
+			Expect(err).ToNot(HaveOccurred())
+			DeferCleanup(func() { compressor.CleanUp(archivePath) }) //nolint:errcheck
+
+			return archivePath
+		}
+
+		It("returns an error when a tarball was modified", func() {
+			archivePath := compressBundle("manifest", sha1Of("manifest"), fmt.Sprintf(
+				`[{"url": "https://example.com/stemcell.tgz", "description": "stemcell", "path": "tarballs/0.tgz", "digest": "%s"}]`,
+				sha1Of("stemcell-content"),
+			))
+
+			_, err := reader.Read(archivePath)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Verifying digest of stemcell in bundle"))
+		})
+
+		It("returns an error when the manifest was modified", func() {
+			archivePath := compressBundle("modified-manifest", sha1Of("manifest"), "[]")
+
+			_, err := reader.Read(archivePath)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Verifying digest of the manifest in bundle"))
+		})
+
+		It("returns an error on use when the index was modified with the tarball", func() {
+			archivePath := compressBundle("manifest", sha1Of("manifest"), fmt.Sprintf(
+				`[{"url": "https://example.com/stemcell.tgz", "sha1": "%s", "description": "stemcell", "path": "tarballs/0.tgz", "digest": "%s"}]`,
+				sha1Of("stemcell-content"), sha1Of("modified-content"),
+			))
+
+			bundle, err := reader.Read(archivePath)
+			Expect(err).ToNot(HaveOccurred())
+			defer bundle.Cleanup() //nolint:errcheck
+
+			_, err = bundle.Get(stemcellSource, fakeStage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Verifying digest of stemcell in bundle"))
+		})
+	})
+})
@@ -2,9 +2,12 @@ package tarball
 
 import (
 	"crypto/sha1"
+	"encoding/json"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+	"time"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	boshfu "github.com/cloudfoundry/bosh-utils/fileutil"
@@ -15,9 +18,46 @@ import (
 type Cache interface {
 	Get(source Source) (path string, found bool)
 	Path(source Source) (path string)
+	PartialPath(source Source) (path string)
+
+	// PartialValidator is the ETag or Last-Modified date of the response an
+	// unfinished download was started from; only a download with the same
+	// validator can be resumed
+	PartialValidator(source Source) (validator string)
+	SavePartialValidator(source Source, validator string)
 	Save(sourcePath string, source Source) error
+
+	// Entries lists cached tarballs and unfinished downloads
+	Entries() ([]CacheEntry, error)
+	Remove(entry CacheEntry) error
+}
+
+// CacheEntry is a cached tarball; URL and Description are empty for tarballs
+// cached before their source was recorded
+type CacheEntry struct {
+	Path        string
+	URL         string
+	SHA1        string
+	Description string
+	Size        int64
+	LastUsed    time.Time
+	Partial     bool
+}
+
+// cacheMetadata is kept next to each tarball;
+// it is rewritten whenever the tarball is used so that its modification time is the last use
+type cacheMetadata struct {
+	URL         string `json:"url"`
+	SHA1        string `json:"sha1"`
+	Description string `json:"description"`
+	Validator   string `json:"validator,omitempty"`
 }
 
+const (
+	cacheMetadataSuffix = ".json"
+	partialSuffix       = ".partial"
+)
+
 type cache struct {
 	basePath string
 	fs       boshsys.FileSystem
@@ -38,6 +78,7 @@ func (c *cache) Get(source Source) (string, bool) {
 	cachedPath := c.Path(source)
 	if c.fs.FileExists(cachedPath) {
 		c.logger.Debug(c.logTag, "Found cached tarball at: '%s'", cachedPath)
+		c.recordUse(source)
 		return cachedPath, true
 	}
 
@@ -55,7 +96,13 @@ func (c *cache) Save(sourcePath string, source Source) error {
 		return bosherr.WrapErrorf(err, "Failed to save tarball path '%s' in cache", sourcePath)
 	}
 
+	err = c.fs.RemoveAll(c.PartialPath(source) + cacheMetadataSuffix)
+	if err != nil {
+		c.logger.Warn(c.logTag, "Failed to remove metadata of partially downloaded tarball: %s", err.Error())
+	}
+
 	c.logger.Debug(c.logTag, "Saving tarball in cache at: '%s'", c.Path(source))
+	c.recordUse(source)
 	return nil
 }
 
@@ -64,3 +111,151 @@ func (c *cache) Path(source Source) string {
 	filename := fmt.Sprintf("%x-%s", string(urlSHA1[:]), source.GetSHA1())
 	return filepath.Join(c.basePath, filename)
 }
+
+// PartialPath is where an unfinished download of source is kept so that it can be resumed
+func (c *cache) PartialPath(source Source) string {
+	return c.Path(source) + partialSuffix
+}
+
+func (c *cache) PartialValidator(source Source) string {
+	bytes, err := c.fs.ReadFile(c.PartialPath(source) + cacheMetadataSuffix)
+	if err != nil {
+		return ""
+	}
+
+	var metadata cacheMetadata
+
+	err = json.Unmarshal(bytes, &metadata)
+	if err != nil {
+		c.logger.Warn(c.logTag, "Failed to unmarshal metadata of partially downloaded tarball: %s", err.Error())
+		return ""
+	}
+
+	return metadata.Validator
+}
+
+// SavePartialValidator keeps the validator next to the unfinished download;
+// failing to do so only means that the download cannot be resumed
+func (c *cache) SavePartialValidator(source Source, validator string) {
+	bytes, err := json.Marshal(cacheMetadata{
+		URL:         source.GetURL(),
+		SHA1:        source.GetSHA1(),
+		Description: source.Description(),
+		Validator:   validator,
+	})
+	if err != nil {
+		c.logger.Warn(c.logTag, "Failed to marshal metadata of partially downloaded tarball: %s", err.Error())
+		return
+	}
+
+	err = c.fs.WriteFileQuietly(c.PartialPath(source)+cacheMetadataSuffix, bytes)
+	if err != nil {
+		c.logger.Warn(c.logTag, "Failed to save metadata of partially downloaded tarball: %s", err.Error())
+	}
+}
+
+func (c *cache) Entries() ([]CacheEntry, error) {
+	paths, err := c.fs.Glob(filepath.Join(c.basePath, "*"))
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Listing tarball cache '%s'", c.basePath)
+	}
+
+	var entries []CacheEntry
+
+	for _, path := range paths {
+		if strings.HasSuffix(path, cacheMetadataSuffix) {
+			continue
+		}
+
+		entry, err := c.entry(path)
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (c *cache) Remove(entry CacheEntry) error {
+	err := c.fs.RemoveAll(entry.Path)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Removing cached tarball '%s'", entry.Path)
+	}
+
+	err = c.fs.RemoveAll(entry.Path + cacheMetadataSuffix)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Removing metadata of cached tarball '%s'", entry.Path)
+	}
+
+	return nil
+}
+
+func (c *cache) entry(path string) (CacheEntry, error) {
+	stat, err := c.fs.Stat(path)
+	if err != nil {
+		return CacheEntry{}, bosherr.WrapErrorf(err, "Checking cached tarball '%s'", path)
+	}
+
+	entry := CacheEntry{
+		Path:     path,
+		Size:     stat.Size(),
+		LastUsed: stat.ModTime(),
+		Partial:  strings.HasSuffix(path, partialSuffix),
+	}
+
+	// file names are the sha1 of the URL followed by the sha1 of the tarball
+	name := strings.TrimSuffix(filepath.Base(path), partialSuffix)
+	if _, sha1, found := strings.Cut(name, "-"); found {
+		entry.SHA1 = sha1
+	}
+
+	metadataPath := path + cacheMetadataSuffix
+
+	if !c.fs.FileExists(metadataPath) {
+		return entry, nil
+	}
+
+	metadataStat, err := c.fs.Stat(metadataPath)
+	if err != nil {
+		return CacheEntry{}, bosherr.WrapErrorf(err, "Checking metadata of cached tarball '%s'", path)
+	}
+
+	bytes, err := c.fs.ReadFile(metadataPath)
+	if err != nil {
+		return CacheEntry{}, bosherr.WrapErrorf(err, "Reading metadata of cached tarball '%s'", path)
+	}
+
+	var metadata cacheMetadata
+
+	err = json.Unmarshal(bytes, &metadata)
+	if err != nil {
+		return CacheEntry{}, bosherr.WrapErrorf(err, "Unmarshalling metadata of cached tarball '%s'", path)
+	}
+
+	entry.URL = metadata.URL
+	entry.Description = metadata.Description
+	entry.LastUsed = metadataStat.ModTime()
+
+	return entry, nil
+}
+
+// recordUse rewrites the metadata of the cached tarball;
+// failing to do so only makes the tarball look less recently used
+func (c *cache) recordUse(source Source) {
+	bytes, err := json.Marshal(cacheMetadata{
+		URL:         source.GetURL(),
+		SHA1:        source.GetSHA1(),
+		Description: source.Description(),
+	})
+	if err != nil {
+		c.logger.Warn(c.logTag, "Failed to marshal metadata of cached tarball: %s", err.Error())
+		return
+	}
+
+	err = c.fs.WriteFileQuietly(c.Path(source)+cacheMetadataSuffix, bytes)
+	if err != nil {
+		c.logger.Warn(c.logTag, "Failed to record use of cached tarball: %s", err.Error())
+	}
+}
@@ -4,8 +4,10 @@ import (
 	"os"
 	"path/filepath"
 	"syscall"
+	"time"
 
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
@@ -120,4 +122,108 @@ var _ = Describe("Cache", func() {
 		})
 		Expect(err).ToNot(HaveOccurred())
 	})
+
+	Describe("PartialValidator", func() {
+		var source *fakeSource
+
+		BeforeEach(func() {
+			source = &fakeSource{sha1: "fake-sha1", url: "http://foo.bar.com", description: "some tarball"}
+		})
+
+		It("is empty when no validator was saved", func() {
+			Expect(cache.PartialValidator(source)).To(BeEmpty())
+		})
+
+		It("returns the saved validator until the download is saved in the cache", func() {
+			cache.SavePartialValidator(source, `"fake-etag"`)
+			Expect(cache.PartialValidator(source)).To(Equal(`"fake-etag"`))
+
+			Expect(fs.WriteFileString(cache.PartialPath(source), "")).To(Succeed())
+			Expect(cache.Save(cache.PartialPath(source), source)).To(Succeed())
+
+			Expect(cache.PartialValidator(source)).To(BeEmpty())
+		})
+	})
+
+	Describe("Entries", func() {
+		var osFs boshsys.FileSystem
+
+		BeforeEach(func() {
+			logger := boshlog.NewLogger(boshlog.LevelNone)
+			osFs = boshsys.NewOsFileSystem(logger)
+			cache = NewCache(filepath.Join(GinkgoT().TempDir(), "downloads"), osFs, logger)
+		})
+
+		It("lists saved tarballs with their source", func() {
+			source := &fakeSource{sha1: "fake-sha1", url: "http://foo.bar.com", description: "some tarball"}
+
+			sourcePath := filepath.Join(GinkgoT().TempDir(), "source-path")
+			Expect(osFs.WriteFileString(sourcePath, "fake-content")).To(Succeed())
+			Expect(cache.Save(sourcePath, source)).To(Succeed())
+
+			entries, err := cache.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+
+			Expect(entries[0].Path).To(Equal(cache.Path(source)))
+			Expect(entries[0].URL).To(Equal("http://foo.bar.com"))
+			Expect(entries[0].SHA1).To(Equal("fake-sha1"))
+			Expect(entries[0].Description).To(Equal("some tarball"))
+			Expect(entries[0].Size).To(Equal(int64(len("fake-content"))))
+			Expect(entries[0].LastUsed).To(BeTemporally("~", time.Now(), time.Minute))
+			Expect(entries[0].Partial).To(BeFalse())
+		})
+
+		It("lists unfinished downloads and tarballs without a recorded source", func() {
+			source := &fakeSource{sha1: "fake-sha1", url: "http://foo.bar.com", description: "some tarball"}
+
+			Expect(osFs.WriteFileString(cache.PartialPath(source), "fake-")).To(Succeed())
+
+			entries, err := cache.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(HaveLen(1))
+
+			Expect(entries[0].Path).To(Equal(cache.PartialPath(source)))
+			Expect(entries[0].URL).To(BeEmpty())
+			Expect(entries[0].SHA1).To(Equal("fake-sha1"))
+			Expect(entries[0].Partial).To(BeTrue())
+		})
+
+		It("records when a tarball is used", func() {
+			source := &fakeSource{sha1: "fake-sha1", url: "http://foo.bar.com", description: "some tarball"}
+
+			Expect(osFs.WriteFileString(cache.Path(source), "fake-content")).To(Succeed())
+			longAgo := time.Now().Add(-48 * time.Hour)
+			Expect(os.Chtimes(cache.Path(source), longAgo, longAgo)).To(Succeed())
+
+			entries, err := cache.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries[0].LastUsed).To(BeTemporally("~", longAgo, time.Second))
+
+			_, found := cache.Get(source)
+			Expect(found).To(BeTrue())
+
+			entries, err = cache.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries[0].LastUsed).To(BeTemporally("~", time.Now(), time.Minute))
+			Expect(entries[0].URL).To(Equal("http://foo.bar.com"))
+		})
+
+		It("removes tarballs with their source", func() {
+			source := &fakeSource{sha1: "fake-sha1", url: "http://foo.bar.com", description: "some tarball"}
+
+			sourcePath := filepath.Join(GinkgoT().TempDir(), "source-path")
+			Expect(osFs.WriteFileString(sourcePath, "fake-content")).To(Succeed())
+			Expect(cache.Save(sourcePath, source)).To(Succeed())
+
+			entries, err := cache.Entries()
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(cache.Remove(entries[0])).To(Succeed())
+
+			entries, err = cache.Entries()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(entries).To(BeEmpty())
+		})
+	})
 })
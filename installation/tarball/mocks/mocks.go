@@ -49,3 +49,17 @@ func (mr *MockProviderMockRecorder) Get(arg0, arg1 interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Get", reflect.TypeOf((*MockProvider)(nil).Get), arg0, arg1)
 }
+
+// Prefetch mocks base method.
+func (m *MockProvider) Prefetch(arg0 []tarball.Source, arg1 ui.Stage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Prefetch", arg0, arg1)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Prefetch indicates an expected call of Prefetch.
+func (mr *MockProviderMockRecorder) Prefetch(arg0, arg1 interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Prefetch", reflect.TypeOf((*MockProvider)(nil).Prefetch), arg0, arg1)
+}
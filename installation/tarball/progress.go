@@ -0,0 +1,148 @@
+package tarball
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+const progressReportInterval = 5 * time.Second
+
+// ProgressReporter is told about all concurrent downloads of a Prefetch together;
+// totalBytes is negative while the size of any download is unknown
+type ProgressReporter interface {
+	TarballsDownloadProgress(downloadedBytes, totalBytes int64, finished, count int)
+}
+
+type downloadProgress struct {
+	reporter ProgressReporter
+	trackers []*downloadTracker
+	mutex    sync.Mutex
+
+	// reports come from the ticker and from finished downloads
+	reportMutex sync.Mutex
+}
+
+func newDownloadProgress(count int, reporter ProgressReporter) *downloadProgress {
+	p := &downloadProgress{reporter: reporter}
+
+	for i := 0; i < count; i++ {
+		p.trackers = append(p.trackers, &downloadTracker{progress: p, size: -1})
+	}
+
+	return p
+}
+
+func (p *downloadProgress) track(i int) *downloadTracker {
+	return p.trackers[i]
+}
+
+// reportEvery reports until the returned function is called, which reports a last time
+func (p *downloadProgress) reportEvery(interval time.Duration) func() {
+	ticker := time.NewTicker(interval)
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+
+		for {
+			select {
+			case <-ticker.C:
+				p.report()
+			case <-done:
+				return
+			}
+		}
+	}()
+
+	return func() {
+		ticker.Stop()
+		close(done)
+		<-stopped
+		p.report()
+	}
+}
+
+func (p *downloadProgress) report() {
+	if p.reporter == nil {
+		return
+	}
+
+	p.reportMutex.Lock()
+	defer p.reportMutex.Unlock()
+
+	p.mutex.Lock()
+
+	var downloaded, total int64
+	var finished int
+
+	for _, tracker := range p.trackers {
+		downloaded += tracker.received
+
+		if tracker.size < 0 || total < 0 {
+			total = -1
+		} else {
+			total += tracker.size
+		}
+
+		if tracker.finished {
+			finished++
+		}
+	}
+
+	p.mutex.Unlock()
+
+	p.reporter.TarballsDownloadProgress(downloaded, total, finished, len(p.trackers))
+}
+
+type downloadTracker struct {
+	progress *downloadProgress
+
+	received int64
+	size     int64
+	finished bool
+}
+
+// start begins a download attempt that continues at offset;
+// size is negative when the server did not tell it
+func (t *downloadTracker) start(offset, size int64, writer io.Writer) io.Writer {
+	t.progress.mutex.Lock()
+	defer t.progress.mutex.Unlock()
+
+	t.received = offset
+	t.size = size
+
+	return trackingWriter{tracker: t, writer: writer}
+}
+
+func (t *downloadTracker) add(n int) {
+	t.progress.mutex.Lock()
+	defer t.progress.mutex.Unlock()
+
+	t.received += int64(n)
+}
+
+func (t *downloadTracker) finish() {
+	t.progress.mutex.Lock()
+
+	t.finished = true
+	if t.size < 0 {
+		t.size = t.received
+	}
+
+	t.progress.mutex.Unlock()
+
+	t.progress.report()
+}
+
+type trackingWriter struct {
+	tracker *downloadTracker
+	writer  io.Writer
+}
+
+func (w trackingWriter) Write(b []byte) (int, error) {
+	n, err := w.writer.Write(b)
+	w.tracker.add(n)
+	return n, err
+}
@@ -3,8 +3,12 @@ package tarball
 import (
 	"fmt"
 	"io"
+	"net/http"
 	"net/url"
+	"os"
+	"path/filepath"
 	"strings"
+	"sync"
 	"time"
 
 	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
@@ -18,6 +22,9 @@ import (
 	biui "github.com/cloudfoundry/bosh-cli/v7/ui"
 )
 
+// maxConcurrentDownloads bounds how many tarballs Prefetch downloads at once
+const maxConcurrentDownloads = 4
+
 type Source interface {
 	GetURL() string
 	GetSHA1() string
@@ -26,6 +33,10 @@ type Source interface {
 
 type Provider interface {
 	Get(Source, biui.Stage) (path string, err error)
+
+	// Prefetch downloads the sources that are not cached yet concurrently
+	// so that Get finds them in the cache
+	Prefetch([]Source, biui.Stage) error
 }
 
 type provider struct {
@@ -34,6 +45,7 @@ type provider struct {
 	httpClient       *httpclient.HTTPClient
 	downloadAttempts int
 	delayTimeout     time.Duration
+	reporter         ProgressReporter
 	logger           boshlog.Logger
 	logTag           string
 }
@@ -44,6 +56,7 @@ func NewProvider(
 	httpClient *httpclient.HTTPClient,
 	downloadAttempts int,
 	delayTimeout time.Duration,
+	reporter ProgressReporter,
 	logger boshlog.Logger,
 ) Provider {
 	return &provider{
@@ -52,6 +65,7 @@ func NewProvider(
 		httpClient:       httpClient,
 		downloadAttempts: downloadAttempts,
 		delayTimeout:     delayTimeout,
+		reporter:         reporter,
 
 		logTag: "tarballProvider",
 		logger: logger,
@@ -76,12 +90,9 @@ func (p *provider) Get(source Source, stage biui.Stage) (string, error) {
 				return biui.NewSkipStageError(bosherr.Error("Already downloaded"), "Found in local cache")
 			}
 
-			retryStrategy := boshretry.NewAttemptRetryStrategy(
-				p.downloadAttempts, p.delayTimeout, p.downloadRetryable(source), p.logger)
-
-			err := retryStrategy.Try()
+			err := p.download(source, nil)
 			if err != nil {
-				return bosherr.WrapErrorf(err, "Failed to download from '%s'", urlhelper.RedactBasicAuth(source.GetURL()))
+				return err
 			}
 
 			p.logger.Debug(p.logTag, "Using the downloaded tarball: '%s'", cachedPath)
@@ -108,22 +119,118 @@ func (p *provider) Get(source Source, stage biui.Stage) (string, error) {
 	return expandedPath, nil
 }
 
-func (p *provider) downloadRetryable(source Source) boshretry.Retryable {
+func (p *provider) Prefetch(sources []Source, stage biui.Stage) error {
+	var downloads []Source
+
+	queued := map[string]bool{}
+
+	for _, source := range sources {
+		if !strings.HasPrefix(source.GetURL(), "http") || queued[source.GetURL()] {
+			continue
+		}
+
+		if _, found := p.cache.Get(source); found {
+			continue
+		}
+
+		downloads = append(downloads, source)
+		queued[source.GetURL()] = true
+	}
+
+	if len(downloads) == 0 {
+		return nil
+	}
+
+	return stage.PerformComplex("downloading tarballs", func(stage biui.Stage) error {
+		progress := newDownloadProgress(len(downloads), p.reporter)
+
+		stopReporting := progress.reportEvery(progressReportInterval)
+		defer stopReporting()
+
+		errs := make([]error, len(downloads))
+		workers := make(chan struct{}, maxConcurrentDownloads)
+
+		var wg sync.WaitGroup
+
+		for i, source := range downloads {
+			wg.Add(1)
+
+			go func(i int, source Source) {
+				defer wg.Done()
+
+				workers <- struct{}{}
+				defer func() { <-workers }()
+
+				errs[i] = p.download(source, progress.track(i))
+			}(i, source)
+		}
+
+		wg.Wait()
+
+		var failed []error
+
+		for _, err := range errs {
+			if err != nil {
+				failed = append(failed, err)
+			}
+		}
+
+		if len(failed) > 0 {
+			return bosherr.NewMultiError(failed...)
+		}
+
+		return nil
+	})
+}
+
+func (p *provider) download(source Source, tracker *downloadTracker) error {
+	retryStrategy := boshretry.NewAttemptRetryStrategy(
+		p.downloadAttempts, p.delayTimeout, p.downloadRetryable(source, tracker), p.logger)
+
+	err := retryStrategy.Try()
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Failed to download from '%s'", urlhelper.RedactBasicAuth(source.GetURL()))
+	}
+
+	if tracker != nil {
+		tracker.finish()
+	}
+
+	return nil
+}
+
+// downloadRetryable keeps what it downloaded in a partial file of the cache
+// and resumes from its end with a range request when tried again; the range
+// is conditional on the validator of the first response so that a tarball
+// that changed on the server is downloaded from the first byte
+func (p *provider) downloadRetryable(source Source, tracker *downloadTracker) boshretry.Retryable {
 	return boshretry.NewRetryable(func() (bool, error) {
-		downloadedFile, err := p.fs.TempFile("tarballProvider")
+		partialPath := p.cache.PartialPath(source)
+
+		err := p.fs.MkdirAll(filepath.Dir(partialPath), os.FileMode(0766))
 		if err != nil {
-			return true, bosherr.WrapError(err, "Unable to create temporary file")
+			return true, bosherr.WrapErrorf(err, "Failed to create cache directory '%s'", filepath.Dir(partialPath))
 		}
 
-		defer func() {
-			downloadedFile.Close()
+		var offset int64
+
+		validator := p.cache.PartialValidator(source)
 
-			if err = p.fs.RemoveAll(downloadedFile.Name()); err != nil {
-				p.logger.Warn(p.logTag, "Failed to remove downloaded file: %s", err.Error())
+		if len(validator) > 0 && p.fs.FileExists(partialPath) {
+			stat, err := p.fs.Stat(partialPath)
+			if err != nil {
+				return true, bosherr.WrapError(err, "Checking partially downloaded file")
 			}
-		}()
 
-		response, err := p.httpClient.Get(source.GetURL())
+			offset = stat.Size()
+		}
+
+		response, err := p.httpClient.GetCustomized(source.GetURL(), func(request *http.Request) {
+			if offset > 0 {
+				request.Header.Set("Range", fmt.Sprintf("bytes=%d-", offset))
+				request.Header.Set("If-Range", validator)
+			}
+		})
 		if err != nil {
 			return true, bosherr.WrapError(err, "Unable to download")
 		}
@@ -134,24 +241,73 @@ func (p *provider) downloadRetryable(source Source) boshretry.Retryable {
 			}
 		}()
 
-		_, err = io.Copy(downloadedFile, response.Body)
+		flags := os.O_CREATE | os.O_WRONLY
+
+		switch response.StatusCode {
+		case http.StatusPartialContent:
+			p.logger.Debug(p.logTag, "Resuming download of '%s' at byte %d", source.Description(), offset)
+			flags |= os.O_APPEND
+
+		case http.StatusOK:
+			offset = 0
+			flags |= os.O_TRUNC
+
+			p.cache.SavePartialValidator(source, rangeValidator(response))
+
+		case http.StatusRequestedRangeNotSatisfiable:
+			// the partial file already has every byte; its digest decides whether to keep it
+			flags |= os.O_APPEND
+
+		default:
+			return true, bosherr.Errorf("Unexpected response status '%s'", response.Status)
+		}
+
+		downloadedFile, err := p.fs.OpenFile(partialPath, flags, os.FileMode(0644))
 		if err != nil {
-			return true, bosherr.WrapError(err, "Saving downloaded bits to temporary file")
+			return true, bosherr.WrapError(err, "Opening partially downloaded file")
 		}
 
+		defer downloadedFile.Close()
+
+		var writer io.Writer = downloadedFile
+
+		if tracker != nil {
+			size := int64(-1)
+
+			if response.StatusCode == http.StatusRequestedRangeNotSatisfiable {
+				size = offset
+			} else if response.ContentLength >= 0 {
+				size = offset + response.ContentLength
+			}
+
+			writer = tracker.start(offset, size, downloadedFile)
+		}
+
+		if response.StatusCode != http.StatusRequestedRangeNotSatisfiable {
+			_, err = io.Copy(writer, response.Body)
+			if err != nil {
+				return true, bosherr.WrapError(err, "Saving downloaded bits to partially downloaded file")
+			}
+		}
+
+		downloadedFile.Close()
+
 		digest, err := boshcrypto.ParseMultipleDigest(source.GetSHA1())
 		if err != nil {
 			return true, err
 		}
 
-		err = digest.VerifyFilePath(downloadedFile.Name(), p.fs)
+		err = digest.VerifyFilePath(partialPath, p.fs)
 		if err != nil {
+			// the next attempt has to start from the first byte
+			if removeErr := p.fs.RemoveAll(partialPath); removeErr != nil {
+				p.logger.Warn(p.logTag, "Failed to remove partially downloaded file: %s", removeErr.Error())
+			}
+
 			return true, bosherr.WrapError(err, "Verifying digest for downloaded file")
 		}
 
-		downloadedFile.Close()
-
-		err = p.cache.Save(downloadedFile.Name(), source)
+		err = p.cache.Save(partialPath, source)
 		if err != nil {
 			return true, bosherr.WrapError(err, "Saving downloaded file in cache")
 		}
@@ -159,3 +315,14 @@ func (p *provider) downloadRetryable(source Source) boshretry.Retryable {
 		return false, nil
 	})
 }
+
+// rangeValidator returns what If-Range can be conditional on; weak ETags
+// cannot be used for range requests
+func rangeValidator(response *http.Response) string {
+	etag := response.Header.Get("ETag")
+	if len(etag) > 0 && !strings.HasPrefix(etag, "W/") {
+		return etag
+	}
+
+	return response.Header.Get("Last-Modified")
+}
@@ -38,6 +38,11 @@ func (t Target) JobsPath() string {
 	return filepath.Join(t.path, "jobs")
 }
 
+// UsagePath is rewritten whenever the installation is used
+func (t Target) UsagePath() string {
+	return filepath.Join(t.path, "usage.json")
+}
+
 func (t Target) TmpPath() string {
 	return filepath.Join(t.path, "tmp")
 }
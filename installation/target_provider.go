@@ -1,9 +1,11 @@
 package installation
 
 import (
+	"encoding/json"
 	"path/filepath"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
 	boshuuid "github.com/cloudfoundry/bosh-utils/uuid"
 
 	biconfig "github.com/cloudfoundry/bosh-cli/v7/config"
@@ -17,17 +19,20 @@ type targetProvider struct {
 	deploymentStateService biconfig.DeploymentStateService
 	uuidGenerator          boshuuid.Generator
 	installationsRootPath  string
+	fs                     boshsys.FileSystem
 }
 
 func NewTargetProvider(
 	deploymentStateService biconfig.DeploymentStateService,
 	uuidGenerator boshuuid.Generator,
 	installationsRootPath string,
+	fs boshsys.FileSystem,
 ) TargetProvider {
 	return &targetProvider{
 		deploymentStateService: deploymentStateService,
 		uuidGenerator:          uuidGenerator,
 		installationsRootPath:  installationsRootPath,
+		fs:                     fs,
 	}
 }
 
@@ -51,5 +56,22 @@ func (p *targetProvider) NewTarget() (Target, error) {
 		}
 	}
 
-	return NewTarget(filepath.Join(p.installationsRootPath, installationID)), nil
+	target := NewTarget(filepath.Join(p.installationsRootPath, installationID))
+
+	err = p.recordUse(target)
+	if err != nil {
+		return Target{}, bosherr.WrapError(err, "Recording use of installation")
+	}
+
+	return target, nil
+}
+
+// recordUse rewrites the usage file so that its modification time is the last use of the installation
+func (p *targetProvider) recordUse(target Target) error {
+	bytes, err := json.Marshal(TargetUsage{StatePath: p.deploymentStateService.Path()})
+	if err != nil {
+		return err
+	}
+
+	return p.fs.WriteFile(target.UsagePath(), bytes)
 }
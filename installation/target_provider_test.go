@@ -1,6 +1,7 @@
 package installation_test
 
 import (
+	"errors"
 	"path/filepath"
 
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
@@ -36,7 +37,7 @@ var _ = Describe("TargetProvider", func() {
 			logger,
 			configPath,
 		)
-		targetProvider = NewTargetProvider(deploymentStateService, fakeUUIDGenerator, installationsRootPath)
+		targetProvider = NewTargetProvider(deploymentStateService, fakeUUIDGenerator, installationsRootPath, fakeFS)
 	})
 
 	Context("when the installation_id exists in the deployment state", func() {
@@ -51,6 +52,23 @@ var _ = Describe("TargetProvider", func() {
 			Expect(target.Path()).To(Equal(filepath.Join("/", ".bosh", "installations", "12345")))
 		})
 
+		It("records the use of the installation", func() {
+			target, err := targetProvider.NewTarget()
+			Expect(err).ToNot(HaveOccurred())
+
+			usage, err := fakeFS.ReadFileString(target.UsagePath())
+			Expect(err).ToNot(HaveOccurred())
+			Expect(usage).To(MatchJSON(`{"state_path":"/deployment.json"}`))
+		})
+
+		It("returns an error when recording the use fails", func() {
+			fakeFS.WriteFileErrors[filepath.Join(installationsRootPath, "12345", "usage.json")] = errors.New("fake-write-error")
+
+			_, err := targetProvider.NewTarget()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("Recording use of installation"))
+		})
+
 		It("does not change the saved installation_id", func() {
 			_, err := targetProvider.NewTarget()
 			Expect(err).ToNot(HaveOccurred())
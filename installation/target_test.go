@@ -32,6 +32,10 @@ var _ = Describe("Target", func() {
 			Expect(target.PackagesPath()).To(Equal(filepath.Join("/", "home", "fake", "madcow", "packages")))
 		})
 
+		It("returns the usage path", func() {
+			Expect(target.UsagePath()).To(Equal(filepath.Join("/", "home", "fake", "madcow", "usage.json")))
+		})
+
 		It("returns the temp path", func() {
 			Expect(target.TmpPath()).To(Equal(filepath.Join("/", "home", "fake", "madcow", "tmp")))
 		})
@@ -0,0 +1,120 @@
+package installation
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"time"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+)
+
+// TargetUsage is kept in each installation to find out which ones are still in use
+type TargetUsage struct {
+	// StatePath is the deployment state that last used the installation
+	StatePath string `json:"state_path"`
+}
+
+type InstalledTarget struct {
+	Target
+	ID        string
+	StatePath string
+	Size      int64
+	LastUsed  time.Time
+}
+
+type TargetLister interface {
+	List() ([]InstalledTarget, error)
+	Remove(InstalledTarget) error
+}
+
+type targetLister struct {
+	installationsRootPath string
+	fs                    boshsys.FileSystem
+}
+
+func NewTargetLister(installationsRootPath string, fs boshsys.FileSystem) TargetLister {
+	return &targetLister{
+		installationsRootPath: installationsRootPath,
+		fs:                    fs,
+	}
+}
+
+func (l *targetLister) List() ([]InstalledTarget, error) {
+	paths, err := l.fs.Glob(filepath.Join(l.installationsRootPath, "*"))
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Listing installations in '%s'", l.installationsRootPath)
+	}
+
+	var targets []InstalledTarget
+
+	for _, path := range paths {
+		target, err := l.target(path)
+		if err != nil {
+			return nil, err
+		}
+
+		targets = append(targets, target)
+	}
+
+	return targets, nil
+}
+
+func (l *targetLister) Remove(target InstalledTarget) error {
+	err := l.fs.RemoveAll(target.Path())
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Removing installation '%s'", target.ID)
+	}
+
+	return nil
+}
+
+// target falls back to the modification time of the installation
+// for installations used before their use was recorded
+func (l *targetLister) target(path string) (InstalledTarget, error) {
+	target := InstalledTarget{Target: NewTarget(path), ID: filepath.Base(path)}
+
+	usedPath := path
+	if l.fs.FileExists(target.UsagePath()) {
+		usedPath = target.UsagePath()
+
+		bytes, err := l.fs.ReadFile(target.UsagePath())
+		if err != nil {
+			return InstalledTarget{}, bosherr.WrapErrorf(err, "Reading usage of installation '%s'", target.ID)
+		}
+
+		var usage TargetUsage
+
+		err = json.Unmarshal(bytes, &usage)
+		if err != nil {
+			return InstalledTarget{}, bosherr.WrapErrorf(err, "Unmarshalling usage of installation '%s'", target.ID)
+		}
+
+		target.StatePath = usage.StatePath
+	}
+
+	stat, err := l.fs.Stat(usedPath)
+	if err != nil {
+		return InstalledTarget{}, bosherr.WrapErrorf(err, "Checking usage of installation '%s'", target.ID)
+	}
+
+	target.LastUsed = stat.ModTime()
+
+	err = l.fs.Walk(path, func(_ string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+
+		if !info.IsDir() {
+			target.Size += info.Size()
+		}
+
+		return nil
+	})
+	if err != nil {
+		return InstalledTarget{}, bosherr.WrapErrorf(err, "Calculating size of installation '%s'", target.ID)
+	}
+
+	return target, nil
+}
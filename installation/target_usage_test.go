@@ -0,0 +1,71 @@
+package installation_test
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/installation"
+)
+
+var _ = Describe("TargetLister", func() {
+	var (
+		fs                    boshsys.FileSystem
+		installationsRootPath string
+		lister                TargetLister
+	)
+
+	BeforeEach(func() {
+		fs = boshsys.NewOsFileSystem(boshlog.NewLogger(boshlog.LevelNone))
+		installationsRootPath = GinkgoT().TempDir()
+		lister = NewTargetLister(installationsRootPath, fs)
+	})
+
+	It("lists installations with their size and recorded use", func() {
+		target := NewTarget(filepath.Join(installationsRootPath, "fake-installation-id"))
+		Expect(fs.WriteFileString(filepath.Join(target.PackagesPath(), "fake-package"), "fake-content")).To(Succeed())
+		Expect(fs.WriteFileString(target.UsagePath(), `{"state_path":"/state.json"}`)).To(Succeed())
+
+		lastUsed := time.Now().Add(-time.Hour)
+		Expect(os.Chtimes(target.UsagePath(), lastUsed, lastUsed)).To(Succeed())
+
+		targets, err := lister.List()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(targets).To(HaveLen(1))
+
+		Expect(targets[0].ID).To(Equal("fake-installation-id"))
+		Expect(targets[0].Path()).To(Equal(target.Path()))
+		Expect(targets[0].StatePath).To(Equal("/state.json"))
+		Expect(targets[0].Size).To(Equal(int64(len("fake-content") + len(`{"state_path":"/state.json"}`))))
+		Expect(targets[0].LastUsed).To(BeTemporally("~", lastUsed, time.Second))
+	})
+
+	It("uses the modification time of installations whose use was not recorded", func() {
+		target := NewTarget(filepath.Join(installationsRootPath, "fake-installation-id"))
+		Expect(fs.MkdirAll(target.Path(), os.ModePerm)).To(Succeed())
+
+		lastUsed := time.Now().Add(-time.Hour)
+		Expect(os.Chtimes(target.Path(), lastUsed, lastUsed)).To(Succeed())
+
+		targets, err := lister.List()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(targets[0].StatePath).To(BeEmpty())
+		Expect(targets[0].LastUsed).To(BeTemporally("~", lastUsed, time.Second))
+	})
+
+	It("removes installations", func() {
+		target := NewTarget(filepath.Join(installationsRootPath, "fake-installation-id"))
+		Expect(fs.WriteFileString(filepath.Join(target.PackagesPath(), "fake-package"), "fake-content")).To(Succeed())
+
+		targets, err := lister.List()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(lister.Remove(targets[0])).To(Succeed())
+		Expect(fs.FileExists(target.Path())).To(BeFalse())
+	})
+})
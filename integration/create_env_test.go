@@ -9,6 +9,7 @@ import (
 
 	biagentclient "github.com/cloudfoundry/bosh-agent/agentclient"
 	bias "github.com/cloudfoundry/bosh-agent/agentclient/applyspec"
+	bihttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http"
 	mockhttpagent "github.com/cloudfoundry/bosh-agent/agentclient/http/mocks"
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 	"github.com/cloudfoundry/bosh-utils/fileutil/fakes"
@@ -23,6 +24,7 @@ import (
 	"github.com/onsi/gomega/gbytes"
 
 	mockagentclient "github.com/cloudfoundry/bosh-cli/v7/agentclient/mocks"
+	biblobstore "github.com/cloudfoundry/bosh-cli/v7/blobstore"
 	mockblobstore "github.com/cloudfoundry/bosh-cli/v7/blobstore/mocks"
 	bicloud "github.com/cloudfoundry/bosh-cli/v7/cloud"
 	mockcloud "github.com/cloudfoundry/bosh-cli/v7/cloud/mocks"
@@ -33,6 +35,8 @@ import (
 	fakebicrypto "github.com/cloudfoundry/bosh-cli/v7/crypto/fakes"
 	bidepl "github.com/cloudfoundry/bosh-cli/v7/deployment"
 	bidisk "github.com/cloudfoundry/bosh-cli/v7/deployment/disk"
+	"github.com/cloudfoundry/bosh-cli/v7/deployment/dryrun"
+	bihook "github.com/cloudfoundry/bosh-cli/v7/deployment/hook"
 	biinstance "github.com/cloudfoundry/bosh-cli/v7/deployment/instance"
 	mockinstancestate "github.com/cloudfoundry/bosh-cli/v7/deployment/instance/state/mocks"
 	bideplmanifest "github.com/cloudfoundry/bosh-cli/v7/deployment/manifest"
@@ -105,6 +109,8 @@ var _ = Describe("bosh", func() {
 
 			sshTunnelFactory bisshtunnel.Factory
 
+			dryRunRecorder *dryrun.Recorder
+
 			diskManagerFactory bidisk.ManagerFactory
 			diskDeployer       bivm.DiskDeployer
 
@@ -178,6 +184,12 @@ releases:
 networks:
 - name: network-1
   type: dynamic
+{{- if .NamedDisks }}
+
+disk_pools:
+- name: disk-pool-1
+  disk_size: {{ .DiskSize }}
+{{- end }}
 
 resource_pools:
 - name: resource-pool-1
@@ -188,7 +200,13 @@ resource_pools:
 jobs:
 - name: fake-deployment-job-name
   instances: 1
+{{- if .NamedDisks }}
+  persistent_disks:
+  - {name: data, type: disk-pool-1}
+  - {name: logs, type: disk-pool-1}
+{{- else }}
   persistent_disk: {{ .DiskSize }}
+{{- end }}
   resource_pool: resource-pool-1
   networks:
   - name: network-1
@@ -223,6 +241,7 @@ cloud_provider:
 `
 		type manifestContext struct {
 			DiskSize            int
+			NamedDisks          bool
 			SSHTunnelUser       string
 			SSHTunnelPrivateKey string
 		}
@@ -390,9 +409,20 @@ cloud_provider:
 
 			deploymentValidator := bideplmanifest.NewValidator(logger)
 
-			instanceFactory := biinstance.NewFactory(mockStateBuilderFactory)
-			instanceManagerFactory := biinstance.NewManagerFactory(sshTunnelFactory, instanceFactory, logger)
+			hookRunner := bihook.NewRunner(bihook.StaticSource{}, fakesys.NewFakeCmdRunner(), logger)
+
+			var (
+				cloudFactory       bicloud.Factory                = mockCloudFactory
+				agentClientFactory bihttpagent.AgentClientFactory = mockAgentClientFactory
+				blobstoreFactory   biblobstore.Factory            = mockBlobstoreFactory
+			)
 
+			if dryRunRecorder != nil {
+				hookRunner = dryrun.NewHookRunner(bihook.StaticSource{}, dryRunRecorder)
+				cloudFactory = dryrun.NewCloudFactory(mockCloudFactory, dryRunRecorder)
+				agentClientFactory = dryrun.NewAgentClientFactory(dryRunRecorder)
+				blobstoreFactory = dryrun.NewBlobstoreFactory(dryRunRecorder)
+			}
 			pingTimeout := 1 * time.Second
 			pingDelay := 100 * time.Millisecond
 			deploymentFactory := bidepl.NewFactory(pingTimeout, pingDelay)
@@ -401,6 +431,9 @@ cloud_provider:
 			doGet := func(deploymentManifestPath string, statePath string, deploymentVars boshtpl.Variables, deploymentOp patch.Op) cmd.DeploymentPreparer {
 				// todo: figure this out?
 				deploymentStateService = biconfig.NewFileSystemDeploymentStateService(fs, fakeUUIDGenerator, logger, biconfig.DeploymentStatePath(deploymentManifestPath, statePath))
+				if dryRunRecorder != nil {
+					deploymentStateService = biconfig.NewDryRunDeploymentStateService(deploymentStateService, fakeUUIDGenerator)
+				}
 				vmRepo = biconfig.NewVMRepo(deploymentStateService)
 				diskRepo = biconfig.NewDiskRepo(deploymentStateService, fakeRepoUUIDGenerator)
 				stemcellRepo = biconfig.NewStemcellRepo(deploymentStateService, fakeRepoUUIDGenerator)
@@ -413,6 +446,8 @@ cloud_provider:
 				diskManagerFactory = bidisk.NewManagerFactory(diskRepo, logger)
 				diskDeployer = bivm.NewDiskDeployer(diskManagerFactory, diskRepo, logger, false)
 				vmManagerFactory = bivm.NewManagerFactory(vmRepo, stemcellRepo, diskDeployer, fakeAgentIDGenerator, fs, logger)
+				instanceFactory := biinstance.NewFactory(mockStateBuilderFactory, hookRunner, diskRepo)
+				instanceManagerFactory := biinstance.NewManagerFactory(sshTunnelFactory, instanceFactory, hookRunner, diskRepo, logger)
 				deployer := bidepl.NewDeployer(
 					vmManagerFactory,
 					instanceManagerFactory,
@@ -420,7 +455,7 @@ cloud_provider:
 					logger,
 				)
 				tarballCache := bitarball.NewCache("fake-base-path", fs, logger)
-				tarballProvider := bitarball.NewProvider(tarballCache, fs, nil, 1, 0, logger)
+				tarballProvider := bitarball.NewProvider(tarballCache, fs, nil, 1, 0, nil, logger)
 
 				cpiInstaller := bicpirel.CpiInstaller{
 					ReleaseManager:   releaseManager,
@@ -450,6 +485,7 @@ cloud_provider:
 					deploymentStateService,
 					installationUuidGenerator,
 					filepath.Join("fake-install-dir"),
+					fs,
 				)
 
 				tempRootConfigurator := cmd.NewTempRootConfigurator(fs)
@@ -462,22 +498,28 @@ cloud_provider:
 					legacyDeploymentStateMigrator,
 					releaseManager,
 					deploymentRecord,
-					mockCloudFactory,
+					cloudFactory,
 					stemcellManagerFactory,
-					mockAgentClientFactory,
+					agentClientFactory,
 					vmManagerFactory,
-					mockBlobstoreFactory,
+					diskManagerFactory,
+					biconfig.NewKeptDisksRepo(fs, biconfig.KeptDisksPath(deploymentManifestPath, statePath)),
+					biconfig.NewManifestRepo(deploymentStateService),
+					hookRunner,
+					blobstoreFactory,
 					deployer,
 					deploymentManifestPath,
 					deploymentVars,
 					deploymentOp,
 					cpiInstaller,
+					tarballProvider,
 					releaseFetcher,
 					stemcellFetcher,
 					releaseSetAndInstallationManifestParser,
 					deploymentManifestParser,
 					tempRootConfigurator,
 					targetProvider,
+					dryRunRecorder,
 				)
 			}
 
@@ -803,6 +845,8 @@ cloud_provider:
 
 			fakeStemcellExtractor = fakebistemcell.NewFakeExtractor()
 
+			dryRunRecorder = nil
+
 			stdOut = gbytes.NewBuffer()
 			stdErr = gbytes.NewBuffer()
 			fakeStage = fakebiui.NewFakeStage()
@@ -830,6 +874,78 @@ cloud_provider:
 			Expect(err).ToNot(HaveOccurred())
 		})
 
+		Context("when it is a dry run", func() {
+			BeforeEach(func() {
+				dryRunRecorder = dryrun.NewRecorder()
+			})
+
+			It("asks the CPI only for its info and does not change the state", func() {
+				stateBefore, err := fs.ReadFileString(deploymentStatePath)
+				Expect(err).ToNot(HaveOccurred())
+
+				mockCloud.EXPECT().Info().Return(bicloud.CpiInfo{ApiVersion: cpiApiVersion}, nil).AnyTimes()
+				mockStateBuilderFactory.EXPECT().NewBuilder(gomock.Any(), gomock.Any()).Return(mockStateBuilder).AnyTimes()
+
+				deployOpts := newDeployOpts(deploymentManifestPath, "")
+				deployOpts.DryRun = true
+
+				err = newCreateEnvCmd().Run(fakeStage, deployOpts)
+				Expect(err).ToNot(HaveOccurred())
+
+				Expect(stdOut).To(gbytes.Say(`stemcell\s+fake-stemcell-name/fake-stemcell-version\s+upload`))
+				Expect(stdOut).To(gbytes.Say(`vm\s+-\s+create`))
+				Expect(stdOut).To(gbytes.Say(`disk\s+dry-run-disk-\d+\s+create`))
+				Expect(stdOut).To(gbytes.Say(`cpi\s+create_stemcell`))
+				Expect(stdOut).To(gbytes.Say(`apply\s+fake-deployment-job-name`))
+
+				stateAfter, err := fs.ReadFileString(deploymentStatePath)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(stateAfter).To(Equal(stateBefore))
+			})
+		})
+
+		Context("when multiple persistent disks are specified", func() {
+			BeforeEach(func() {
+				updateManifest(manifestContext{DiskSize: 1024, NamedDisks: true})
+			})
+
+			It("adds both disks to the agent but mounts only the first one", func() {
+				gomock.InOrder(
+					mockCloud.EXPECT().Info().Return(bicloud.CpiInfo{ApiVersion: cpiApiVersion}, nil).AnyTimes(),
+					mockCloud.EXPECT().CreateStemcell(gomock.Any(), gomock.Any()).Return(stemcellCID, nil),
+					mockCloud.EXPECT().CreateVM(gomock.Any(), stemcellCID, gomock.Any(), gomock.Any(), gomock.Any()).Return("fake-vm-cid-1", nil),
+					mockCloud.EXPECT().SetVMMetadata("fake-vm-cid-1", gomock.Any()).Return(nil),
+					mockAgentClient.EXPECT().Ping().Return("any-state", nil),
+
+					mockCloud.EXPECT().CreateDisk(1024, diskCloudProperties, "fake-vm-cid-1").Return("fake-data-disk-cid", nil),
+					mockCloud.EXPECT().AttachDisk("fake-vm-cid-1", "fake-data-disk-cid").Return("/dev/sdb", nil),
+					mockCloud.EXPECT().SetDiskMetadata("fake-data-disk-cid", gomock.Any()).Return(nil),
+					mockAgentClient.EXPECT().Ping().Return("any-state", nil),
+					mockAgentClient.EXPECT().AddPersistentDisk("fake-data-disk-cid", "/dev/sdb"),
+					mockAgentClient.EXPECT().MountDisk("fake-data-disk-cid"),
+
+					mockCloud.EXPECT().CreateDisk(1024, diskCloudProperties, "fake-vm-cid-1").Return("fake-logs-disk-cid", nil),
+					mockCloud.EXPECT().AttachDisk("fake-vm-cid-1", "fake-logs-disk-cid").Return("/dev/sdc", nil),
+					mockCloud.EXPECT().SetDiskMetadata("fake-logs-disk-cid", gomock.Any()).Return(nil),
+					mockAgentClient.EXPECT().Ping().Return("any-state", nil),
+					mockAgentClient.EXPECT().AddPersistentDisk("fake-logs-disk-cid", "/dev/sdc"),
+
+					mockAgentClient.EXPECT().Apply(applySpec),
+					mockAgentClient.EXPECT().GetState(),
+					mockAgentClient.EXPECT().Stop(),
+					mockAgentClient.EXPECT().Apply(applySpec),
+					mockAgentClient.EXPECT().RunScript("pre-start", map[string]interface{}{}),
+					mockAgentClient.EXPECT().Start(),
+					mockAgentClient.EXPECT().GetState().Return(agentRunningState, nil),
+					mockAgentClient.EXPECT().RunScript("post-start", map[string]interface{}{}),
+				)
+				mockAgentClient.EXPECT().MountDisk("fake-logs-disk-cid").Times(0)
+
+				err := newCreateEnvCmd().Run(fakeStage, newDeployOpts(deploymentManifestPath, ""))
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
 		Context("when multiple releases are provided", func() {
 			var (
 				otherReleaseTarballPath = filepath.Join("/", "fake-other-release.tgz")
@@ -933,6 +1049,35 @@ cloud_provider:
 					Expect(err).ToNot(HaveOccurred())
 				})
 
+				Context("when it is a dry run", func() {
+					It("plans the disk migration from the calls a real run would make without changing the state", func() {
+						stateBefore, err := fs.ReadFileString(deploymentStatePath)
+						Expect(err).ToNot(HaveOccurred())
+
+						mockCloud.EXPECT().Info().Return(bicloud.CpiInfo{ApiVersion: cpiApiVersion}, nil).AnyTimes()
+						mockStateBuilderFactory.EXPECT().NewBuilder(gomock.Any(), gomock.Any()).Return(mockStateBuilder).AnyTimes()
+						mockStateBuilderFactory.EXPECT().NewBuilder(gomock.Any(), gomock.Any()).Return(mockStateBuilder).AnyTimes()
+						mockCloud.EXPECT().HasVM("fake-vm-cid-1").Return(true, nil)
+
+						dryRunRecorder = dryrun.NewRecorder()
+						deployOpts := newDeployOpts(deploymentManifestPath, "")
+						deployOpts.DryRun = true
+
+						err = newCreateEnvCmd().Run(fakeStage, deployOpts)
+						Expect(err).ToNot(HaveOccurred())
+
+						Expect(stdOut).To(gbytes.Say(`vm\s+fake-vm-cid-1\s+recreate`))
+						Expect(stdOut).To(gbytes.Say(`disk\s+fake-disk-cid-1\s+migrate`))
+						Expect(stdOut).To(gbytes.Say(`cpi\s+delete_vm\s+fake-vm-cid-1`))
+						Expect(stdOut).To(gbytes.Say(`migrate_disk\s+-`))
+						Expect(stdOut).To(gbytes.Say("Dry run: no changes were made to the environment."))
+
+						stateAfter, err := fs.ReadFileString(deploymentStatePath)
+						Expect(err).ToNot(HaveOccurred())
+						Expect(stateAfter).To(Equal(stateBefore))
+					})
+				})
+
 				Context("when current VM has been deleted manually (outside of bosh)", func() {
 					It("migrates the disk content, but does not shutdown the old VM", func() {
 						expectDeployWithDiskMigrationMissingVM()
@@ -987,14 +1132,14 @@ cloud_provider:
 						err := newCreateEnvCmd().Run(fakeStage, newDeployOpts(deploymentManifestPath, ""))
 						Expect(err).ToNot(HaveOccurred())
 
-						diskRecord, found, err := diskRepo.FindCurrent()
+						currentDiskRecords, err := diskRepo.FindCurrent()
 						Expect(err).ToNot(HaveOccurred())
-						Expect(found).To(BeTrue())
-						Expect(diskRecord.CID).To(Equal("fake-disk-cid-3"))
+						Expect(currentDiskRecords).To(HaveLen(1))
+						Expect(currentDiskRecords[0].CID).To(Equal("fake-disk-cid-3"))
 
 						diskRecords, err := diskRepo.All()
 						Expect(err).ToNot(HaveOccurred())
-						Expect(diskRecords).To(Equal([]biconfig.DiskRecord{diskRecord}))
+						Expect(diskRecords).To(Equal(currentDiskRecords))
 					})
 				})
 			})
@@ -80,5 +80,13 @@ func (r ArchiveReaderImpl) Read(ref boshman.JobRef, path string) (*Job, error) {
 
 	job.Properties = properties
 
+	for _, rawLinkDef := range manifest.Consumes {
+		job.Consumes = append(job.Consumes, LinkDefinition(rawLinkDef))
+	}
+
+	for _, rawLinkDef := range manifest.Provides {
+		job.Provides = append(job.Provides, LinkDefinition(rawLinkDef))
+	}
+
 	return job, nil
 }
@@ -77,6 +77,23 @@ properties:
 			Expect(compressor.DecompressFileToDirOptions).To(Equal([]boshcmd.CompressorOptions{{}}))
 		})
 
+		It("returns a job with the links from the manifest", func() {
+			err := fs.WriteFileString("/extracted/job/job.MF", `---
+name: name
+consumes:
+- {name: db, type: postgres, optional: true}
+provides:
+- {name: api, type: http, properties: [port]}
+`)
+			Expect(err).ToNot(HaveOccurred())
+
+			job, err := reader.Read(ref, "archive-path")
+			Expect(err).NotTo(HaveOccurred())
+
+			Expect(job.Consumes).To(Equal([]LinkDefinition{{Name: "db", Type: "postgres", Optional: true}}))
+			Expect(job.Provides).To(Equal([]LinkDefinition{{Name: "api", Type: "http", Properties: []string{"port"}}}))
+		})
+
 		It("returns an error when the job manifest is invalid", func() {
 			err := fs.WriteFileString("/extracted/job/job.MF", "-")
 			Expect(err).ToNot(HaveOccurred())
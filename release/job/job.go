@@ -24,6 +24,8 @@ type Job struct {
 	PackageNames []string
 	Packages     []boshpkg.Compilable
 	Properties   map[string]PropertyDefinition
+	Consumes     []LinkDefinition
+	Provides     []LinkDefinition
 
 	extractedPath string
 	fs            boshsys.FileSystem
@@ -34,6 +36,15 @@ type PropertyDefinition struct {
 	Default     biproperty.Property
 }
 
+type LinkDefinition struct {
+	Name     string
+	Type     string
+	Optional bool
+
+	// Properties are the job properties a provided link exposes
+	Properties []string
+}
+
 func NewJob(resource Resource) *Job {
 	return &Job{resource: resource}
 }
@@ -80,6 +91,8 @@ func (j *Job) RehashWithCalculator(calculator crypto.DigestCalculator, archiveFi
 		PackageNames: j.PackageNames,
 		Packages:     j.Packages,
 		Properties:   j.Properties,
+		Consumes:     j.Consumes,
+		Provides:     j.Provides,
 
 		extractedPath: j.extractedPath,
 		fs:            j.fs,
@@ -11,6 +11,8 @@ type Manifest struct {
 	Templates  map[string]string             `yaml:"templates"`
 	Packages   []string                      `yaml:"packages"`
 	Properties map[string]PropertyDefinition `yaml:"properties"`
+	Consumes   []LinkDefinition              `yaml:"consumes"`
+	Provides   []LinkDefinition              `yaml:"provides"`
 }
 
 type PropertyDefinition struct {
@@ -18,6 +20,15 @@ type PropertyDefinition struct {
 	Default     interface{} `yaml:"default"`
 }
 
+type LinkDefinition struct {
+	Name     string `yaml:"name"`
+	Type     string `yaml:"type"`
+	Optional bool   `yaml:"optional"`
+
+	// Properties are the job properties a provided link exposes
+	Properties []string `yaml:"properties"`
+}
+
 func NewManifestFromPath(path string, fs boshsys.FileSystem) (Manifest, error) {
 	var manifest Manifest
 
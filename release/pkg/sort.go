@@ -12,8 +12,9 @@ func Sort(releasePackages []Compilable) ([]Compilable, error) {
 	incomingEdges, outgoingEdges := getEdgeMaps(releasePackages)
 	noIncomingEdgesSet := []Compilable{}
 
-	for pkg, edgeList := range incomingEdges {
-		if len(edgeList) == 0 {
+	// packages are visited in the given order so that the result is the same on every run
+	for _, pkg := range releasePackages {
+		if len(incomingEdges[pkg]) == 0 {
 			noIncomingEdgesSet = append(noIncomingEdgesSet, pkg)
 		}
 	}
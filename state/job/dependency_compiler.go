@@ -2,6 +2,7 @@ package job
 
 import (
 	"fmt"
+	"sort"
 	"strings"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
@@ -26,14 +27,26 @@ type DependencyCompiler interface {
 
 type dependencyCompiler struct {
 	packageCompiler bistatepkg.Compiler
+	workers         int
 
 	logTag string
 	logger boshlog.Logger
 }
 
 func NewDependencyCompiler(packageCompiler bistatepkg.Compiler, logger boshlog.Logger) DependencyCompiler {
+	return NewParallelDependencyCompiler(packageCompiler, 1, logger)
+}
+
+// NewParallelDependencyCompiler compiles up to 'workers' packages at the same time.
+// A package is only compiled once all of its dependencies have been compiled.
+func NewParallelDependencyCompiler(packageCompiler bistatepkg.Compiler, workers int, logger boshlog.Logger) DependencyCompiler {
+	if workers < 1 {
+		workers = 1
+	}
+
 	return &dependencyCompiler{
 		packageCompiler: packageCompiler,
+		workers:         workers,
 
 		logTag: "dependencyCompiler",
 		logger: logger,
@@ -47,7 +60,13 @@ func (c *dependencyCompiler) Compile(jobs []bireljob.Job, stage biui.Stage) ([]C
 		return nil, bosherr.WrapError(err, "Resolving job package dependencies")
 	}
 
-	compiledPackageRefs, err := c.compilePackages(compileOrderReleasePackages, stage)
+	var compiledPackageRefs []CompiledPackageRef
+
+	if c.workers > 1 && len(compileOrderReleasePackages) > 1 {
+		compiledPackageRefs, err = c.compilePackagesInParallel(compileOrderReleasePackages, stage)
+	} else {
+		compiledPackageRefs, err = c.compilePackages(compileOrderReleasePackages, stage)
+	}
 	if err != nil {
 		return nil, bosherr.WrapError(err, "Compiling job package dependencies")
 	}
@@ -75,6 +94,9 @@ func (c *dependencyCompiler) resolveJobCompilationDependencies(jobs []bireljob.J
 		packages = append(packages, releasePackage)
 	}
 
+	// map iteration order is random; sorting by name keeps the compilation order stable
+	sort.Slice(packages, func(i, j int) bool { return c.pkgKey(packages[i]) < c.pkgKey(packages[j]) })
+
 	// sort in compilation order
 	sortedPackages, err := birelpkg.Sort(packages)
 	if err != nil {
@@ -139,4 +161,157 @@ func (c *dependencyCompiler) compilePackages(requiredPackages []birelpkg.Compila
 	return packageRefs, nil
 }
 
+type compiledPackageResult struct {
+	record            bistatepkg.CompiledPackageRecord
+	isAlreadyCompiled bool
+	err               error
+}
+
+// compilePackagesInParallel compiles independent packages concurrently in the background.
+// Stage steps are still performed one after another in compilation order,
+// each waiting for its package, so that the output and the returned refs do not depend on timing.
+func (c *dependencyCompiler) compilePackagesInParallel(requiredPackages []birelpkg.Compilable, stage biui.Stage) ([]CompiledPackageRef, error) {
+	results := make([]chan compiledPackageResult, len(requiredPackages))
+	for i := range requiredPackages {
+		results[i] = make(chan compiledPackageResult, 1)
+	}
+
+	done := make(chan struct{})
+	schedulerFinished := make(chan struct{})
+
+	go func() {
+		defer close(schedulerFinished)
+		c.schedulePackages(requiredPackages, results, done)
+	}()
+
+	defer func() {
+		close(done)
+		<-schedulerFinished
+	}()
+
+	packageRefs := make([]CompiledPackageRef, 0, len(requiredPackages))
+
+	for i, pkg := range requiredPackages {
+		stepName := fmt.Sprintf("Compiling package '%s/%s'", pkg.Name(), pkg.Fingerprint())
+
+		err := stage.Perform(stepName, func() error {
+			result := <-results[i]
+			if result.err != nil {
+				return result.err
+			}
+
+			packageRefs = append(packageRefs, CompiledPackageRef{
+				Name:        pkg.Name(),
+				Version:     pkg.Fingerprint(),
+				BlobstoreID: result.record.BlobID,
+				SHA1:        result.record.BlobSHA1,
+			})
+
+			if result.isAlreadyCompiled {
+				return biui.NewSkipStageError(bosherr.Error(fmt.Sprintf("Package '%s' is already compiled. Skipped compilation", pkg.Name())), "Package already compiled")
+			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return packageRefs, nil
+}
+
+// schedulePackages hands packages to the workers as soon as their dependencies are compiled.
+// It stops handing out packages after the first failure or when done is closed
+// and returns once all started compilations have finished.
+func (c *dependencyCompiler) schedulePackages(requiredPackages []birelpkg.Compilable, results []chan compiledPackageResult, done <-chan struct{}) {
+	type finishedPackage struct {
+		index  int
+		result compiledPackageResult
+	}
+
+	indexes := map[string]int{}
+	for i, pkg := range requiredPackages {
+		indexes[c.pkgKey(pkg)] = i
+	}
+
+	remainingDeps := make([]int, len(requiredPackages))
+	dependents := make([][]int, len(requiredPackages))
+
+	for i, pkg := range requiredPackages {
+		for _, dep := range pkg.Deps() {
+			if depIndex, found := indexes[c.pkgKey(dep)]; found {
+				remainingDeps[i]++
+				dependents[depIndex] = append(dependents[depIndex], i)
+			}
+		}
+	}
+
+	queue := make(chan int, len(requiredPackages))
+	finished := make(chan finishedPackage)
+
+	for w := 0; w < c.workers; w++ {
+		go func() {
+			for i := range queue {
+				record, isAlreadyCompiled, err := c.packageCompiler.Compile(requiredPackages[i])
+				finished <- finishedPackage{i, compiledPackageResult{record, isAlreadyCompiled, err}}
+			}
+		}()
+	}
+
+	started := make([]bool, len(requiredPackages))
+	inFlight := 0
+
+	for i := range requiredPackages {
+		if remainingDeps[i] == 0 {
+			started[i] = true
+			inFlight++
+			queue <- i
+		}
+	}
+
+	failed := false
+
+	for inFlight > 0 {
+		var f finishedPackage
+
+		select {
+		case f = <-finished:
+		case <-done:
+			failed = true
+			f = <-finished
+		}
+
+		inFlight--
+		results[f.index] <- f.result
+
+		if f.result.err != nil {
+			failed = true
+		}
+
+		if failed {
+			continue
+		}
+
+		for _, dependent := range dependents[f.index] {
+			remainingDeps[dependent]--
+			if remainingDeps[dependent] == 0 {
+				started[dependent] = true
+				inFlight++
+				queue <- dependent
+			}
+		}
+	}
+
+	close(queue)
+
+	for i, pkg := range requiredPackages {
+		if !started[i] {
+			results[i] <- compiledPackageResult{
+				err: bosherr.Errorf("Skipped compiling package '%s' because another package failed to compile", pkg.Name()),
+			}
+		}
+	}
+}
+
 func (c *dependencyCompiler) pkgKey(pkg birelpkg.Compilable) string { return pkg.Name() }
@@ -1,6 +1,10 @@
 package job_test
 
 import (
+	"errors"
+	"sync"
+	"time"
+
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 	"github.com/golang/mock/gomock"
 	. "github.com/onsi/ginkgo/v2"
@@ -224,4 +228,100 @@ var _ = Describe("DependencyCompiler", func() {
 			Expect(err).ToNot(HaveOccurred())
 		})
 	})
+
+	Context("when compiling with multiple workers", func() {
+		var (
+			packageCompiler *concurrentPackageCompiler
+			pkg3            *boshrelpkg.Package
+		)
+
+		BeforeEach(func() {
+			pkg3 = newPkg("pkg3-name", "pkg3-fp", nil)
+
+			job.PackageNames = append(job.PackageNames, pkg3.Name())
+			err := job.AttachPackages([]*boshrelpkg.Package{pkg2, pkg3})
+			Expect(err).ToNot(HaveOccurred())
+			jobs = []boshreljob.Job{*job}
+
+			pkg3Started := make(chan struct{})
+
+			packageCompiler = &concurrentPackageCompiler{
+				CompileStub: func(pkg boshrelpkg.Compilable) error {
+					switch pkg.Name() {
+					case "pkg1-name":
+						select {
+						case <-pkg3Started:
+						case <-time.After(5 * time.Second):
+							return errors.New("pkg3 was not compiled at the same time")
+						}
+					case "pkg3-name":
+						close(pkg3Started)
+					}
+					return nil
+				},
+			}
+
+			dependencyCompiler = NewParallelDependencyCompiler(packageCompiler, 2, logger)
+		})
+
+		It("compiles independent packages at the same time and still returns them in compilation order", func() {
+			compiledPackageRefs, err := dependencyCompiler.Compile(jobs, stage)
+			Expect(err).ToNot(HaveOccurred())
+
+			Expect(compiledPackageRefs).To(HaveLen(3))
+			Expect(packageCompiler.Compiled()).To(ConsistOf("pkg1-name", "pkg2-name", "pkg3-name"))
+
+			for i, ref := range compiledPackageRefs {
+				Expect(ref.BlobstoreID).To(Equal("fake-blob-id-" + ref.Name))
+				Expect(stage.PerformCalls[i].Name).To(Equal("Compiling package '" + ref.Name + "/" + ref.Version + "'"))
+			}
+
+			var names []string
+			for _, ref := range compiledPackageRefs {
+				names = append(names, ref.Name)
+			}
+			Expect(names).To(Equal([]string{"pkg1-name", "pkg3-name", "pkg2-name"}))
+		})
+
+		It("does not compile packages whose dependencies failed to compile", func() {
+			packageCompiler.CompileStub = func(pkg boshrelpkg.Compilable) error {
+				if pkg.Name() == "pkg1-name" {
+					return errors.New("fake-compile-error")
+				}
+				return nil
+			}
+
+			_, err := dependencyCompiler.Compile(jobs, stage)
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("fake-compile-error"))
+			Expect(packageCompiler.Compiled()).ToNot(ContainElement("pkg2-name"))
+		})
+	})
 })
+
+type concurrentPackageCompiler struct {
+	CompileStub func(boshrelpkg.Compilable) error
+
+	compiledLock sync.Mutex
+	compiled     []string
+}
+
+func (c *concurrentPackageCompiler) Compile(pkg boshrelpkg.Compilable) (bistatepkg.CompiledPackageRecord, bool, error) {
+	err := c.CompileStub(pkg)
+	if err != nil {
+		return bistatepkg.CompiledPackageRecord{}, false, err
+	}
+
+	c.compiledLock.Lock()
+	c.compiled = append(c.compiled, pkg.Name())
+	c.compiledLock.Unlock()
+
+	return bistatepkg.CompiledPackageRecord{BlobID: "fake-blob-id-" + pkg.Name()}, false, nil
+}
+
+func (c *concurrentPackageCompiler) Compiled() []string {
+	c.compiledLock.Lock()
+	defer c.compiledLock.Unlock()
+
+	return append([]string{}, c.compiled...)
+}
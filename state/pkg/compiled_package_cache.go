@@ -0,0 +1,350 @@
+package pkg
+
+import (
+	"crypto/sha1"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	boshsys "github.com/cloudfoundry/bosh-utils/system"
+
+	birelpkg "github.com/cloudfoundry/bosh-cli/v7/release/pkg"
+)
+
+// CompiledPackageCache is a directory of compiled package tarballs that can be
+// shared between installations on different machines.
+// Anyone who can write to the directory can replace its packages, so their
+// digests are kept in a separate digests file that is local or pinned by the user.
+// Machines only use packages cached by other machines when they share the digests
+// file, e.g. one that is distributed with the cache through a trusted channel.
+// Compiled packages may embed the path they were compiled for, so packages are
+// keyed by the packages dir they are installed into as well and are only shared
+// between installations at the same path, e.g. CI workers sharing a state file.
+// The cache is shared as a directory; exporting it as a tarball is not supported.
+type CompiledPackageCache interface {
+	// Find returns the path of the cached tarball for the package
+	// after verifying it against the digests file.
+	// Packages without a digest or that fail verification are not found.
+	// They are kept since other machines may trust them with their own digests.
+	Find(birelpkg.Compilable) (string, bool, error)
+
+	Has(birelpkg.Compilable) bool
+
+	// Save adds the tarball to the cache and its digest to the digests file
+	// unless the package is already cached
+	Save(pkg birelpkg.Compilable, tarballPath string) error
+
+	// Entries lists the cached packages of all releases
+	Entries() ([]CachedCompiledPackage, error)
+	Remove(CachedCompiledPackage) error
+}
+
+type CachedCompiledPackage struct {
+	Name        string
+	Fingerprint string
+	Path        string
+	Size        int64
+
+	// CachedAt is when the package was saved;
+	// machines sharing the cache only read it afterwards
+	CachedAt time.Time
+}
+
+type compiledPackageCacheEntry struct {
+	Name          string `json:"name"`
+	Fingerprint   string `json:"fingerprint"`
+	DependencyKey string `json:"dependency_key"`
+	PackagesDir   string `json:"packages_dir"`
+}
+
+type compiledPackageCache struct {
+	dir                    string
+	packagesDir            string
+	digestsPath            string
+	digestsLock            sync.Mutex
+	fs                     boshsys.FileSystem
+	digestCreateAlgorithms []boshcrypto.Algorithm
+	logger                 boshlog.Logger
+	logTag                 string
+}
+
+// NewCompiledPackageCache returns the cache in dir for packages installed into packagesDir
+func NewCompiledPackageCache(dir string, packagesDir string, digestsPath string, fs boshsys.FileSystem, digestCreateAlgorithms []boshcrypto.Algorithm, logger boshlog.Logger) CompiledPackageCache {
+	return &compiledPackageCache{
+		dir:                    dir,
+		packagesDir:            packagesDir,
+		digestsPath:            digestsPath,
+		fs:                     fs,
+		digestCreateAlgorithms: digestCreateAlgorithms,
+		logger:                 logger,
+		logTag:                 "compiledPackageCache",
+	}
+}
+
+func (c *compiledPackageCache) Find(pkg birelpkg.Compilable) (string, bool, error) {
+	entryPath, tarballPath := c.paths(pkg)
+
+	if !c.fs.FileExists(entryPath) {
+		return "", false, nil
+	}
+
+	bytes, err := c.fs.ReadFile(entryPath)
+	if err != nil {
+		return "", false, bosherr.WrapErrorf(err, "Reading compiled package cache entry '%s'", entryPath)
+	}
+
+	var entry compiledPackageCacheEntry
+
+	err = json.Unmarshal(bytes, &entry)
+	if err != nil {
+		return "", false, bosherr.WrapErrorf(err, "Unmarshalling compiled package cache entry '%s'", entryPath)
+	}
+
+	if entry != c.entry(pkg) {
+		c.logger.Warn(c.logTag, "Not using cached compiled package '%s/%s' whose cache entry '%s' belongs to another package", pkg.Name(), pkg.Fingerprint(), entryPath)
+		return "", false, nil
+	}
+
+	digests, err := c.readDigests()
+	if err != nil {
+		return "", false, err
+	}
+
+	digestStr, found := digests[c.digestKey(tarballPath)]
+	if !found {
+		c.logger.Debug(c.logTag, "Not using cached compiled package '%s/%s' without a digest in '%s'; machines sharing the cache also need to share the digests file", pkg.Name(), pkg.Fingerprint(), c.digestsPath)
+		return "", false, nil
+	}
+
+	digest, err := boshcrypto.ParseMultipleDigest(digestStr)
+	if err != nil {
+		return "", false, bosherr.WrapErrorf(err, "Parsing digest of cached compiled package '%s/%s'", pkg.Name(), pkg.Fingerprint())
+	}
+
+	err = digest.VerifyFilePath(tarballPath, c.fs)
+	if err != nil {
+		c.logger.Warn(c.logTag, "Not using cached compiled package '%s/%s' that failed verification against '%s': %s", pkg.Name(), pkg.Fingerprint(), c.digestsPath, err.Error())
+		return "", false, nil
+	}
+
+	return tarballPath, true, nil
+}
+
+func (c *compiledPackageCache) Has(pkg birelpkg.Compilable) bool {
+	entryPath, _ := c.paths(pkg)
+
+	return c.fs.FileExists(entryPath)
+}
+
+func (c *compiledPackageCache) Save(pkg birelpkg.Compilable, tarballPath string) error {
+	if c.Has(pkg) {
+		return nil
+	}
+
+	entryPath, cachedTarballPath := c.paths(pkg)
+
+	digest, err := boshcrypto.NewMultipleDigestFromPath(tarballPath, c.fs, c.digestCreateAlgorithms)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Calculating digest of compiled package '%s'", pkg.Name())
+	}
+
+	bytes, err := json.Marshal(c.entry(pkg))
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling compiled package cache entry")
+	}
+
+	err = c.fs.MkdirAll(filepath.Dir(entryPath), 0755)
+	if err != nil {
+		return bosherr.WrapError(err, "Creating compiled package cache dir")
+	}
+
+	// other machines may be reading the cache so only ever rename complete files into place
+	err = c.copyIntoPlace(tarballPath, cachedTarballPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Caching compiled package '%s'", pkg.Name())
+	}
+
+	err = c.fs.WriteFile(entryPath+".tmp", bytes)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing compiled package cache entry '%s'", entryPath)
+	}
+
+	err = c.fs.Rename(entryPath+".tmp", entryPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing compiled package cache entry '%s'", entryPath)
+	}
+
+	return c.updateDigests(func(digests map[string]string) {
+		digests[c.digestKey(cachedTarballPath)] = digest.String()
+	})
+}
+
+func (c *compiledPackageCache) Entries() ([]CachedCompiledPackage, error) {
+	entryPaths, err := c.fs.Glob(filepath.Join(c.dir, "*", "*.json"))
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Listing compiled package cache '%s'", c.dir)
+	}
+
+	var packages []CachedCompiledPackage
+
+	for _, entryPath := range entryPaths {
+		bytes, err := c.fs.ReadFile(entryPath)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Reading compiled package cache entry '%s'", entryPath)
+		}
+
+		var entry compiledPackageCacheEntry
+
+		err = json.Unmarshal(bytes, &entry)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Unmarshalling compiled package cache entry '%s'", entryPath)
+		}
+
+		tarballPath := strings.TrimSuffix(entryPath, ".json") + ".tgz"
+
+		stat, err := c.fs.Stat(tarballPath)
+		if err != nil {
+			return nil, bosherr.WrapErrorf(err, "Checking cached compiled package '%s'", tarballPath)
+		}
+
+		packages = append(packages, CachedCompiledPackage{
+			Name:        entry.Name,
+			Fingerprint: entry.Fingerprint,
+			Path:        tarballPath,
+			Size:        stat.Size(),
+			CachedAt:    stat.ModTime(),
+		})
+	}
+
+	return packages, nil
+}
+
+// Remove deletes the entry first so that other machines never find a package without its tarball
+func (c *compiledPackageCache) Remove(pkg CachedCompiledPackage) error {
+	entryPath := strings.TrimSuffix(pkg.Path, ".tgz") + ".json"
+
+	err := c.fs.RemoveAll(entryPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Removing compiled package cache entry '%s'", entryPath)
+	}
+
+	err = c.fs.RemoveAll(pkg.Path)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Removing cached compiled package '%s'", pkg.Path)
+	}
+
+	// a digest without its package is never used, e.g. when the digests file is read-only
+	err = c.updateDigests(func(digests map[string]string) {
+		delete(digests, c.digestKey(pkg.Path))
+	})
+	if err != nil {
+		c.logger.Warn(c.logTag, "Failed to remove digest of cached compiled package '%s': %s", pkg.Path, err.Error())
+	}
+
+	return nil
+}
+
+func (c *compiledPackageCache) readDigests() (map[string]string, error) {
+	digests := map[string]string{}
+
+	if c.digestsPath == "" || !c.fs.FileExists(c.digestsPath) {
+		return digests, nil
+	}
+
+	bytes, err := c.fs.ReadFile(c.digestsPath)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Reading compiled package digests '%s'", c.digestsPath)
+	}
+
+	err = json.Unmarshal(bytes, &digests)
+	if err != nil {
+		return nil, bosherr.WrapErrorf(err, "Unmarshalling compiled package digests '%s'", c.digestsPath)
+	}
+
+	return digests, nil
+}
+
+// updateDigests rewrites the digests file; packages may be saved in parallel
+func (c *compiledPackageCache) updateDigests(update func(map[string]string)) error {
+	if c.digestsPath == "" {
+		return nil
+	}
+
+	c.digestsLock.Lock()
+	defer c.digestsLock.Unlock()
+
+	digests, err := c.readDigests()
+	if err != nil {
+		return err
+	}
+
+	update(digests)
+
+	bytes, err := json.MarshalIndent(digests, "", "  ")
+	if err != nil {
+		return bosherr.WrapError(err, "Marshalling compiled package digests")
+	}
+
+	err = c.fs.MkdirAll(filepath.Dir(c.digestsPath), 0700)
+	if err != nil {
+		return bosherr.WrapError(err, "Creating compiled package digests dir")
+	}
+
+	err = c.fs.WriteFile(c.digestsPath+".tmp", bytes)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing compiled package digests '%s'", c.digestsPath)
+	}
+
+	err = c.fs.Rename(c.digestsPath+".tmp", c.digestsPath)
+	if err != nil {
+		return bosherr.WrapErrorf(err, "Writing compiled package digests '%s'", c.digestsPath)
+	}
+
+	return nil
+}
+
+// digestKey identifies a cached tarball by its path within the cache dir
+func (c *compiledPackageCache) digestKey(tarballPath string) string {
+	relPath, err := filepath.Rel(c.dir, tarballPath)
+	if err != nil {
+		relPath = tarballPath
+	}
+
+	return filepath.ToSlash(strings.TrimSuffix(relPath, ".tgz"))
+}
+
+func (c *compiledPackageCache) copyIntoPlace(srcPath, dstPath string) error {
+	err := c.fs.CopyFile(srcPath, dstPath+".tmp")
+	if err != nil {
+		return err
+	}
+
+	return c.fs.Rename(dstPath+".tmp", dstPath)
+}
+
+func (c *compiledPackageCache) entry(pkg birelpkg.Compilable) compiledPackageCacheEntry {
+	return compiledPackageCacheEntry{
+		Name:          pkg.Name(),
+		Fingerprint:   pkg.Fingerprint(),
+		DependencyKey: convertToDependencyKey(ResolveDependencies(pkg)),
+		PackagesDir:   c.packagesDir,
+	}
+}
+
+// paths returns the entry and tarball paths of the package;
+// they are keyed by fingerprint and dependency key like the CompiledPackageRepo
+// and by the packages dir the package was compiled for
+func (c *compiledPackageCache) paths(pkg birelpkg.Compilable) (string, string) {
+	dependencyKey := convertToDependencyKey(ResolveDependencies(pkg))
+	name := fmt.Sprintf("%s-%x", pkg.Fingerprint(), sha1.Sum([]byte(dependencyKey+"\n"+c.packagesDir)))
+
+	base := filepath.Join(c.dir, pkg.Name(), name)
+
+	return base + ".json", base + ".tgz"
+}
@@ -0,0 +1,270 @@
+package pkg_test
+
+import (
+	boshcrypto "github.com/cloudfoundry/bosh-utils/crypto"
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	fakesys "github.com/cloudfoundry/bosh-utils/system/fakes"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	boshrelpkg "github.com/cloudfoundry/bosh-cli/v7/release/pkg"
+	. "github.com/cloudfoundry/bosh-cli/v7/state/pkg"
+)
+
+var _ = Describe("CompiledPackageCache", func() {
+	var (
+		fs    *fakesys.FakeFileSystem
+		cache CompiledPackageCache
+		pkg   *boshrelpkg.Package
+	)
+
+	BeforeEach(func() {
+		fs = fakesys.NewFakeFileSystem()
+		cache = NewCompiledPackageCache("/cache", "/installations/fake-id/packages", "/local/digests.json", fs, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA1}, boshlog.NewLogger(boshlog.LevelNone))
+
+		dependency := newPkg("dep-name", "dep-fp", nil)
+		pkg = newPkg("pkg-name", "pkg-fp", []string{"dep-name"})
+		err := pkg.AttachDependencies([]*boshrelpkg.Package{dependency})
+		Expect(err).ToNot(HaveOccurred())
+
+		err = fs.WriteFileString("/compiled.tgz", "compiled-contents")
+		Expect(err).ToNot(HaveOccurred())
+	})
+
+	It("finds saved packages", func() {
+		Expect(cache.Has(pkg)).To(BeFalse())
+
+		err := cache.Save(pkg, "/compiled.tgz")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cache.Has(pkg)).To(BeTrue())
+
+		path, found, err := cache.Find(pkg)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeTrue())
+		Expect(fs.ReadFileString(path)).To(Equal("compiled-contents"))
+	})
+
+	It("does not find packages whose dependencies have changed", func() {
+		err := cache.Save(pkg, "/compiled.tgz")
+		Expect(err).ToNot(HaveOccurred())
+
+		changedDependency := newPkg("dep-name", "changed-dep-fp", nil)
+		changedPkg := newPkg("pkg-name", "pkg-fp", []string{"dep-name"})
+		err = changedPkg.AttachDependencies([]*boshrelpkg.Package{changedDependency})
+		Expect(err).ToNot(HaveOccurred())
+
+		_, found, err := cache.Find(changedPkg)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeFalse())
+	})
+
+	It("does not find packages compiled for another packages dir", func() {
+		err := cache.Save(pkg, "/compiled.tgz")
+		Expect(err).ToNot(HaveOccurred())
+
+		otherInstallationCache := NewCompiledPackageCache("/cache", "/installations/other-id/packages", "/local/digests.json", fs, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA1}, boshlog.NewLogger(boshlog.LevelNone))
+
+		Expect(otherInstallationCache.Has(pkg)).To(BeFalse())
+
+		_, found, err := otherInstallationCache.Find(pkg)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeFalse())
+
+		err = otherInstallationCache.Save(pkg, "/compiled.tgz")
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(otherInstallationCache.Has(pkg)).To(BeTrue())
+		Expect(cache.Has(pkg)).To(BeTrue())
+	})
+
+	It("does not replace packages that are already cached", func() {
+		err := cache.Save(pkg, "/compiled.tgz")
+		Expect(err).ToNot(HaveOccurred())
+
+		err = fs.WriteFileString("/other.tgz", "other-contents")
+		Expect(err).ToNot(HaveOccurred())
+
+		err = cache.Save(pkg, "/other.tgz")
+		Expect(err).ToNot(HaveOccurred())
+
+		path, _, err := cache.Find(pkg)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(fs.ReadFileString(path)).To(Equal("compiled-contents"))
+	})
+
+	It("does not find but keeps a cached tarball that does not match the recorded digest", func() {
+		err := cache.Save(pkg, "/compiled.tgz")
+		Expect(err).ToNot(HaveOccurred())
+
+		path, _, err := cache.Find(pkg)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = fs.WriteFileString(path, "poisoned-contents")
+		Expect(err).ToNot(HaveOccurred())
+
+		_, found, err := cache.Find(pkg)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeFalse())
+
+		Expect(cache.Has(pkg)).To(BeTrue())
+		Expect(fs.FileExists(path)).To(BeTrue())
+	})
+
+	It("does not find packages without a digest in the digests file", func() {
+		err := cache.Save(pkg, "/compiled.tgz")
+		Expect(err).ToNot(HaveOccurred())
+
+		otherCache := NewCompiledPackageCache("/cache", "/installations/fake-id/packages", "/other/digests.json", fs, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA1}, boshlog.NewLogger(boshlog.LevelNone))
+
+		_, found, err := otherCache.Find(pkg)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeFalse())
+		Expect(otherCache.Has(pkg)).To(BeTrue())
+	})
+
+	It("keeps digests outside of the cache dir", func() {
+		err := cache.Save(pkg, "/compiled.tgz")
+		Expect(err).ToNot(HaveOccurred())
+
+		path, _, err := cache.Find(pkg)
+		Expect(err).ToNot(HaveOccurred())
+
+		entryPath := path[:len(path)-len(".tgz")] + ".json"
+		entry, err := fs.ReadFileString(entryPath)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(entry).ToNot(ContainSubstring("sha1"))
+
+		digests, err := fs.ReadFileString("/local/digests.json")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(digests).To(ContainSubstring(`"pkg-name/pkg-fp-`))
+	})
+
+	It("does not find but keeps a package whose cache entry belongs to another package", func() {
+		err := cache.Save(pkg, "/compiled.tgz")
+		Expect(err).ToNot(HaveOccurred())
+
+		path, _, err := cache.Find(pkg)
+		Expect(err).ToNot(HaveOccurred())
+
+		entryPath := path[:len(path)-len(".tgz")] + ".json"
+		entry, err := fs.ReadFileString(entryPath)
+		Expect(err).ToNot(HaveOccurred())
+
+		err = fs.WriteFileString(entryPath, `{"name":"other-name"`+entry[len(`{"name":"pkg-name"`):])
+		Expect(err).ToNot(HaveOccurred())
+
+		_, found, err := cache.Find(pkg)
+		Expect(err).ToNot(HaveOccurred())
+		Expect(found).To(BeFalse())
+
+		Expect(cache.Has(pkg)).To(BeTrue())
+		Expect(fs.FileExists(path)).To(BeTrue())
+	})
+
+	Context("when two machines share the cache", func() {
+		var otherMachineCache CompiledPackageCache
+
+		newMachineCache := func(digestsPath string) CompiledPackageCache {
+			return NewCompiledPackageCache("/cache", "/installations/fake-id/packages", digestsPath, fs, []boshcrypto.Algorithm{boshcrypto.DigestAlgorithmSHA1}, boshlog.NewLogger(boshlog.LevelNone))
+		}
+
+		Context("with the same digests file", func() {
+			BeforeEach(func() {
+				cache = newMachineCache("/shared/digests.json")
+				otherMachineCache = newMachineCache("/shared/digests.json")
+			})
+
+			It("finds packages cached by the other machine", func() {
+				err := cache.Save(pkg, "/compiled.tgz")
+				Expect(err).ToNot(HaveOccurred())
+
+				path, found, err := otherMachineCache.Find(pkg)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(fs.ReadFileString(path)).To(Equal("compiled-contents"))
+			})
+		})
+
+		Context("with their own digests files", func() {
+			BeforeEach(func() {
+				cache = newMachineCache("/machine-1/digests.json")
+				otherMachineCache = newMachineCache("/machine-2/digests.json")
+			})
+
+			It("does not find packages cached by the other machine and keeps them when compiling them again", func() {
+				err := cache.Save(pkg, "/compiled.tgz")
+				Expect(err).ToNot(HaveOccurred())
+
+				_, found, err := otherMachineCache.Find(pkg)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeFalse())
+
+				err = fs.WriteFileString("/recompiled.tgz", "recompiled-contents")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = otherMachineCache.Save(pkg, "/recompiled.tgz")
+				Expect(err).ToNot(HaveOccurred())
+
+				path, found, err := cache.Find(pkg)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(fs.ReadFileString(path)).To(Equal("compiled-contents"))
+			})
+
+			It("keeps packages trusted by the other machine when its own digest is stale", func() {
+				err := fs.WriteFileString("/old-compiled.tgz", "old-compiled-contents")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = otherMachineCache.Save(pkg, "/old-compiled.tgz")
+				Expect(err).ToNot(HaveOccurred())
+
+				path, _, err := otherMachineCache.Find(pkg)
+				Expect(err).ToNot(HaveOccurred())
+
+				// the package is pruned and cached again by the first machine
+				err = fs.RemoveAll(path[:len(path)-len(".tgz")] + ".json")
+				Expect(err).ToNot(HaveOccurred())
+
+				err = cache.Save(pkg, "/compiled.tgz")
+				Expect(err).ToNot(HaveOccurred())
+
+				_, found, err := otherMachineCache.Find(pkg)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeFalse())
+
+				path, found, err = cache.Find(pkg)
+				Expect(err).ToNot(HaveOccurred())
+				Expect(found).To(BeTrue())
+				Expect(fs.ReadFileString(path)).To(Equal("compiled-contents"))
+			})
+		})
+	})
+
+	It("lists and removes cached packages", func() {
+		err := cache.Save(pkg, "/compiled.tgz")
+		Expect(err).ToNot(HaveOccurred())
+
+		path, _, err := cache.Find(pkg)
+		Expect(err).ToNot(HaveOccurred())
+
+		entryPath := path[:len(path)-len(".tgz")] + ".json"
+		fs.SetGlob("/cache/*/*.json", []string{entryPath})
+
+		packages, err := cache.Entries()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(packages).To(HaveLen(1))
+		Expect(packages[0].Name).To(Equal("pkg-name"))
+		Expect(packages[0].Fingerprint).To(Equal("pkg-fp"))
+		Expect(packages[0].Path).To(Equal(path))
+		Expect(packages[0].Size).To(Equal(int64(len("compiled-contents"))))
+
+		err = cache.Remove(packages[0])
+		Expect(err).ToNot(HaveOccurred())
+		Expect(cache.Has(pkg)).To(BeFalse())
+		Expect(fs.FileExists(path)).To(BeFalse())
+
+		digests, err := fs.ReadFileString("/local/digests.json")
+		Expect(err).ToNot(HaveOccurred())
+		Expect(digests).ToNot(ContainSubstring("pkg-name"))
+	})
+})
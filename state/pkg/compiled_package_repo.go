@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"sort"
 	"strings"
+	"sync"
 
 	bosherr "github.com/cloudfoundry/bosh-utils/errors"
 
@@ -23,6 +24,9 @@ type CompiledPackageRepo interface {
 
 type compiledPackageRepo struct {
 	index biindex.Index
+
+	// index saves are read-modify-write and packages may be compiled concurrently
+	lock sync.RWMutex
 }
 
 func NewCompiledPackageRepo(index biindex.Index) CompiledPackageRepo {
@@ -30,6 +34,9 @@ func NewCompiledPackageRepo(index biindex.Index) CompiledPackageRepo {
 }
 
 func (cpr *compiledPackageRepo) Save(pkg birelpkg.Compilable, record CompiledPackageRecord) error {
+	cpr.lock.Lock()
+	defer cpr.lock.Unlock()
+
 	err := cpr.index.Save(cpr.pkgKey(pkg), record)
 
 	if err != nil {
@@ -42,6 +49,9 @@ func (cpr *compiledPackageRepo) Save(pkg birelpkg.Compilable, record CompiledPac
 func (cpr *compiledPackageRepo) Find(pkg birelpkg.Compilable) (CompiledPackageRecord, bool, error) {
 	var record CompiledPackageRecord
 
+	cpr.lock.RLock()
+	defer cpr.lock.RUnlock()
+
 	err := cpr.index.Find(cpr.pkgKey(pkg), &record)
 	if err != nil {
 		if err == biindex.ErrNotFound {
@@ -62,15 +72,15 @@ type packageToCompiledPackageKey struct {
 	DependencyKey      string
 }
 
-func (cpr compiledPackageRepo) pkgKey(pkg birelpkg.Compilable) packageToCompiledPackageKey {
+func (cpr *compiledPackageRepo) pkgKey(pkg birelpkg.Compilable) packageToCompiledPackageKey {
 	return packageToCompiledPackageKey{
 		PackageName:        pkg.Name(),
 		PackageFingerprint: pkg.Fingerprint(),
-		DependencyKey:      cpr.convertToDependencyKey(ResolveDependencies(pkg)),
+		DependencyKey:      convertToDependencyKey(ResolveDependencies(pkg)),
 	}
 }
 
-func (cpr compiledPackageRepo) convertToDependencyKey(packages []birelpkg.Compilable) string {
+func convertToDependencyKey(packages []birelpkg.Compilable) string {
 	dependencyKeys := []string{}
 
 	for _, pkg := range packages {
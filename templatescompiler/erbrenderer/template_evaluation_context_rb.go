@@ -43,6 +43,8 @@ class TemplateEvaluationContext
 
     @properties = openstruct(properties)
     @raw_properties = properties
+    @links = spec['links'] || {}
+    @link_errors = spec['link_errors'] || {}
     @spec = openstruct(spec)
   end
 
@@ -73,8 +75,21 @@ class TemplateEvaluationContext
     InactiveElseBlock.new
   end
 
+  def link(name)
+    raise UnresolvedLink.new(@link_errors[name]) if @link_errors.key?(name)
+
+    link_spec = @links[name]
+    raise UnknownLink.new(name) if link_spec.nil?
+
+    EvaluationLink.new(link_spec)
+  end
+
   def if_link(name)
-    false
+    link_spec = @links[name]
+    return ActiveElseBlock.new(self) if link_spec.nil?
+
+    yield EvaluationLink.new(link_spec)
+    InactiveElseBlock.new
   end
 
   private
@@ -131,6 +146,72 @@ class TemplateEvaluationContext
     end
   end
 
+  class UnknownLink < StandardError
+    def initialize(name)
+      super("Can't find link '#{name}'")
+    end
+  end
+
+  class UnresolvedLink < StandardError
+  end
+
+  class EvaluationLink
+    attr_reader :instances, :properties, :address
+
+    def initialize(link_spec)
+      @instances = (link_spec['instances'] || []).map { |i| EvaluationLinkInstance.new(i) }
+      @properties = link_spec['properties'] || {}
+      @address = link_spec['address']
+    end
+
+    def p(*args)
+      names = Array(args[0])
+
+      names.each do |name|
+        result = lookup_property(@properties, name)
+        return result unless result.nil?
+      end
+
+      return args[1] if args.length == 2
+      raise UnknownProperty.new(names)
+    end
+
+    def if_p(*names)
+      values = names.map do |name|
+        value = lookup_property(@properties, name)
+        return ActiveElseBlock.new(self) if value.nil?
+        value
+      end
+
+      yield *values
+      InactiveElseBlock.new
+    end
+
+    private
+
+    def lookup_property(collection, name)
+      keys = name.split(".")
+      ref = collection
+
+      keys.each do |key|
+        ref = ref[key]
+        return nil if ref.nil?
+      end
+
+      ref
+    end
+  end
+
+  class EvaluationLinkInstance
+    attr_reader :index, :bootstrap, :address
+
+    def initialize(instance_spec)
+      @index = instance_spec['index']
+      @bootstrap = instance_spec['bootstrap']
+      @address = instance_spec['address']
+    end
+  end
+
   class ActiveElseBlock
     def initialize(template)
       @context = template
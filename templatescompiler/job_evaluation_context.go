@@ -15,6 +15,7 @@ import (
 type jobEvaluationContext struct {
 	releaseJob           bireljob.Job
 	releaseJobProperties *biproperty.Map
+	links                map[string]Link
 	jobProperties        biproperty.Map
 	globalProperties     biproperty.Map
 	deploymentName       string
@@ -43,6 +44,12 @@ type RootContext struct {
 	ClusterProperties biproperty.Map  `json:"cluster_properties"` // values from instance group (deployment job) properties
 	JobProperties     *biproperty.Map `json:"job_properties"`     // values from release job (aka template) properties
 	DefaultProperties biproperty.Map  `json:"default_properties"` // values from release's job's spec
+
+	// Usually is accessed with <%= link('name').p('property') %>
+	Links map[string]Link `json:"links"`
+
+	// Messages raised by link('name') for required links that are not provided
+	LinkErrors map[string]string `json:"link_errors,omitempty"`
 }
 
 type jobContext struct {
@@ -58,6 +65,7 @@ type networkContext struct {
 func NewJobEvaluationContext(
 	releaseJob bireljob.Job,
 	releaseJobProperties *biproperty.Map,
+	links map[string]Link,
 	jobProperties biproperty.Map,
 	globalProperties biproperty.Map,
 	deploymentName string,
@@ -68,6 +76,7 @@ func NewJobEvaluationContext(
 	return jobEvaluationContext{
 		releaseJob:           releaseJob,
 		releaseJobProperties: releaseJobProperties,
+		links:                links,
 		jobProperties:        jobProperties,
 		globalProperties:     globalProperties,
 		deploymentName:       deploymentName,
@@ -93,6 +102,19 @@ func (ec jobEvaluationContext) MarshalJSON() ([]byte, error) {
 		ClusterProperties: ec.jobProperties,
 		JobProperties:     ec.releaseJobProperties,
 		DefaultProperties: defaultProperties,
+		Links:             map[string]Link{},
+	}
+
+	for name, link := range ec.links {
+		if len(link.Unresolved) > 0 {
+			if context.LinkErrors == nil {
+				context.LinkErrors = map[string]string{}
+			}
+			context.LinkErrors[name] = link.Unresolved
+			continue
+		}
+
+		context.Links[name] = link
 	}
 
 	if len(ec.address) > 0 {
@@ -24,6 +24,7 @@ var _ = Describe("JobEvaluationContext", func() {
 	var (
 		releaseJob              *boshreljob.Job
 		jobProperties           *biproperty.Map
+		links                   map[string]Link
 		instanceGroupProperties biproperty.Map
 		deploymentProperties    biproperty.Map
 		erbRenderer             erbrenderer.ERBRenderer
@@ -48,6 +49,7 @@ var _ = Describe("JobEvaluationContext", func() {
 
 		uuidGen = fakeuuid.NewFakeGenerator()
 		jobProperties = nil
+		links = nil
 	})
 
 	JustBeforeEach(func() {
@@ -56,6 +58,7 @@ var _ = Describe("JobEvaluationContext", func() {
 		jobEvaluationContext = NewJobEvaluationContext(
 			*releaseJob,
 			jobProperties,
+			links,
 			instanceGroupProperties,
 			deploymentProperties,
 			"fake-deployment-name",
@@ -102,6 +105,38 @@ var _ = Describe("JobEvaluationContext", func() {
 		generatedContext := act()
 		Expect(generatedContext.Bootstrap).To(Equal(true))
 	})
+
+	It("it has empty links in the spec when the job consumes none", func() {
+		generatedContext := act()
+		Expect(generatedContext.Links).To(Equal(map[string]Link{}))
+	})
+
+	Context("when the job consumes links", func() {
+		BeforeEach(func() {
+			links = map[string]Link{
+				"db": {
+					DeploymentName: "fake-deployment-name",
+					Properties:     biproperty.Map{"port": "5432"},
+					Instances:      []LinkInstance{{Index: 0, Bootstrap: true, Address: "1.2.3.4"}},
+					Address:        "1.2.3.4",
+				},
+			}
+		})
+
+		It("it has the links available in the spec", func() {
+			generatedContext := act()
+			Expect(generatedContext.Links).To(Equal(links))
+			Expect(generatedContext.LinkErrors).To(BeNil())
+		})
+
+		It("it has the errors of unresolved links in the spec instead of the links", func() {
+			links["cache"] = Link{Unresolved: "Cannot resolve link 'cache'"}
+
+			generatedContext := act()
+			Expect(generatedContext.Links).ToNot(HaveKey("cache"))
+			Expect(generatedContext.LinkErrors).To(Equal(map[string]string{"cache": "Cannot resolve link 'cache'"}))
+		})
+	})
 	Context("when the UUID generator raise an error", func() {
 		It("it raises an error", func() {
 			uuidGen.GenerateError = errors.Error("boom")
@@ -111,7 +146,7 @@ var _ = Describe("JobEvaluationContext", func() {
 		})
 	})
 
-	getValueFor := func(key string) string {
+	render := func(erbContents string) string {
 		logger := boshlog.NewLogger(boshlog.LevelNone)
 		fs := boshsys.NewOsFileSystem(logger)
 		commandRunner := boshsys.NewExecCmdRunner(logger)
@@ -121,7 +156,6 @@ var _ = Describe("JobEvaluationContext", func() {
 		Expect(err).ToNot(HaveOccurred())
 		defer os.Remove(srcFile.Name())
 
-		erbContents := fmt.Sprintf("<%%= p('%s') %%>", key)
 		_, err = srcFile.WriteString(erbContents)
 		Expect(err).ToNot(HaveOccurred())
 
@@ -134,6 +168,7 @@ var _ = Describe("JobEvaluationContext", func() {
 		jobEvaluationContext := NewJobEvaluationContext(
 			*releaseJob,
 			jobProperties,
+			links,
 			instanceGroupProperties,
 			deploymentProperties,
 			"fake-deployment-name",
@@ -149,6 +184,30 @@ var _ = Describe("JobEvaluationContext", func() {
 		return (string)(contents)
 	}
 
+	getValueFor := func(key string) string {
+		return render(fmt.Sprintf("<%%= p('%s') %%>", key))
+	}
+
+	Context("when a template uses links", func() {
+		BeforeEach(func() {
+			links = map[string]Link{
+				"db": {
+					Properties: biproperty.Map{"port": "5432"},
+					Instances:  []LinkInstance{{Index: 0, Bootstrap: true, Address: "1.2.3.4"}},
+					Address:    "1.2.3.4",
+				},
+			}
+		})
+
+		It("renders link properties and instances", func() {
+			Expect(render("<%= link('db').p('port') %> <%= link('db').instances.first.address %>")).To(Equal("5432 1.2.3.4"))
+		})
+
+		It("renders the else block of if_link for links that are not consumed", func() {
+			Expect(render("<% if_link('other') do |l| %>other<% end.else do %>none<% end %>")).To(Equal("none"))
+		})
+	})
+
 	Context("when a deployment and instance group set a property", func() {
 		BeforeEach(func() {
 			deploymentProperties = biproperty.Map{
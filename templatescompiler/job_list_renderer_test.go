@@ -81,13 +81,37 @@ var _ = Describe("JobListRenderer", func() {
 	})
 
 	JustBeforeEach(func() {
-		mockJobRenderer.EXPECT().Render(releaseJobs[0], releaseJobProperties[releaseJobs[0].Name()], jobProperties, globalProperties, deploymentName, address).Return(renderedJobs[0], nil)
-		expectRender1 = mockJobRenderer.EXPECT().Render(releaseJobs[1], releaseJobProperties[releaseJobs[1].Name()], jobProperties, globalProperties, deploymentName, address).Return(renderedJobs[1], nil)
+		mockJobRenderer.EXPECT().Render(releaseJobs[0], releaseJobProperties[releaseJobs[0].Name()], map[string]Link{}, jobProperties, globalProperties, deploymentName, address).Return(renderedJobs[0], nil)
+		expectRender1 = mockJobRenderer.EXPECT().Render(releaseJobs[1], releaseJobProperties[releaseJobs[1].Name()], map[string]Link{}, jobProperties, globalProperties, deploymentName, address).Return(renderedJobs[1], nil)
 	})
 
 	Describe("Render", func() {
+		Context("when jobs consume links", func() {
+			BeforeEach(func() {
+				releaseJobs[0].Provides = []boshreljob.LinkDefinition{{Name: "db", Type: "database"}}
+				releaseJobs[1].Consumes = []boshreljob.LinkDefinition{{Name: "db", Type: "database"}}
+			})
+
+			JustBeforeEach(func() {
+				expectRender1.Times(0)
+				mockJobRenderer.EXPECT().Render(releaseJobs[1], releaseJobProperties[releaseJobs[1].Name()], map[string]Link{
+					"db": {
+						DeploymentName: deploymentName,
+						Properties:     biproperty.Map{},
+						Instances:      []LinkInstance{{Index: 0, Bootstrap: true, Address: address}},
+						Address:        address,
+					},
+				}, jobProperties, globalProperties, deploymentName, address).Return(renderedJobs[1], nil)
+			})
+
+			It("passes the resolved links of each job to the JobRenderer", func() {
+				_, err := jobListRenderer.Render(releaseJobs, releaseJobProperties, nil, jobProperties, globalProperties, deploymentName, address)
+				Expect(err).ToNot(HaveOccurred())
+			})
+		})
+
 		It("returns a new RenderedJobList with all the RenderedJobs", func() {
-			renderedJobList, err := jobListRenderer.Render(releaseJobs, releaseJobProperties, jobProperties, globalProperties, deploymentName, address)
+			renderedJobList, err := jobListRenderer.Render(releaseJobs, releaseJobProperties, nil, jobProperties, globalProperties, deploymentName, address)
 			Expect(err).ToNot(HaveOccurred())
 			Expect(renderedJobList.All()).To(Equal([]RenderedJob{
 				renderedJobs[0],
@@ -103,7 +127,7 @@ var _ = Describe("JobListRenderer", func() {
 			It("returns an error and cleans up any sucessfully rendered jobs", func() {
 				renderedJobs[0].EXPECT().DeleteSilently()
 
-				_, err := jobListRenderer.Render(releaseJobs, releaseJobProperties, jobProperties, globalProperties, deploymentName, address)
+				_, err := jobListRenderer.Render(releaseJobs, releaseJobProperties, nil, jobProperties, globalProperties, deploymentName, address)
 				Expect(err).To(HaveOccurred())
 				Expect(err.Error()).To(ContainSubstring("fake-render-error"))
 			})
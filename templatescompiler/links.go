@@ -0,0 +1,303 @@
+package templatescompiler
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	bosherr "github.com/cloudfoundry/bosh-utils/errors"
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+
+	bireljob "github.com/cloudfoundry/bosh-cli/v7/release/job"
+)
+
+// JobLinks configures the links of a release job in the deployment manifest
+type JobLinks struct {
+	Consumes map[string]ConsumedLink
+	Provides map[string]ProvidedLink
+}
+
+type ConsumedLink struct {
+	Blocked    bool
+	From       string
+	Deployment string
+
+	InstanceAddresses []string
+	Properties        *biproperty.Map
+	Address           string
+}
+
+func (l ConsumedLink) isManual() bool {
+	return l.InstanceAddresses != nil || l.Properties != nil || len(l.Address) > 0
+}
+
+type ProvidedLink struct {
+	Blocked bool
+	As      string
+
+	// Shared has no effect since links never leave the instance
+	Shared bool
+}
+
+// Link is exposed to ERB templates with link('name') like the director does
+type Link struct {
+	DeploymentName string         `json:"deployment_name"`
+	Properties     biproperty.Map `json:"properties"`
+	Instances      []LinkInstance `json:"instances"`
+	Address        string         `json:"address"`
+
+	// Unresolved explains why no job provides a required link;
+	// templates that call link() for it fail with this message
+	Unresolved string `json:"-"`
+}
+
+type LinkInstance struct {
+	Index     int    `json:"index"`
+	Bootstrap bool   `json:"bootstrap"`
+	Address   string `json:"address"`
+}
+
+type linkProvider struct {
+	job   bireljob.Job
+	alias string
+	def   bireljob.LinkDefinition
+}
+
+// ResolveLinks matches the links consumed by the jobs of the single instance
+// with the links provided by those jobs or defined manually, keyed by job and link name.
+// Required links that no job provides are returned as Unresolved links.
+func ResolveLinks(
+	releaseJobs []bireljob.Job,
+	releaseJobProperties map[string]*biproperty.Map,
+	releaseJobLinks map[string]JobLinks,
+	jobProperties biproperty.Map,
+	globalProperties biproperty.Map,
+	deploymentName string,
+	address string,
+) (map[string]map[string]Link, error) {
+	var providers []linkProvider
+
+	for _, releaseJob := range releaseJobs {
+		config := releaseJobLinks[releaseJob.Name()]
+
+		for name := range config.Provides {
+			if !hasLinkDefinition(releaseJob.Provides, name) {
+				return nil, bosherr.Errorf("Job '%s' does not provide link '%s'", releaseJob.Name(), name)
+			}
+		}
+
+		for _, def := range releaseJob.Provides {
+			provided := config.Provides[def.Name]
+			if provided.Blocked {
+				continue
+			}
+
+			alias := def.Name
+			if len(provided.As) > 0 {
+				alias = provided.As
+			}
+
+			providers = append(providers, linkProvider{job: releaseJob, alias: alias, def: def})
+		}
+	}
+
+	links := map[string]map[string]Link{}
+
+	for _, releaseJob := range releaseJobs {
+		config := releaseJobLinks[releaseJob.Name()]
+
+		for name := range config.Consumes {
+			if !hasLinkDefinition(releaseJob.Consumes, name) {
+				return nil, bosherr.Errorf("Job '%s' does not consume link '%s'", releaseJob.Name(), name)
+			}
+		}
+
+		jobLinks := map[string]Link{}
+
+		for _, def := range releaseJob.Consumes {
+			consumed := config.Consumes[def.Name]
+			if consumed.Blocked {
+				continue
+			}
+
+			if len(consumed.Deployment) > 0 && consumed.Deployment != deploymentName {
+				return nil, bosherr.Errorf(
+					"Link '%s' of job '%s' cannot be consumed from deployment '%s': only links within the instance are supported",
+					def.Name, releaseJob.Name(), consumed.Deployment)
+			}
+
+			if consumed.isManual() {
+				jobLinks[def.Name] = manualLink(consumed, deploymentName)
+				continue
+			}
+
+			var matches []linkProvider
+
+			for _, provider := range providers {
+				if provider.def.Type != def.Type {
+					continue
+				}
+				if len(consumed.From) > 0 && provider.alias != consumed.From {
+					continue
+				}
+				matches = append(matches, provider)
+			}
+
+			switch len(matches) {
+			case 0:
+				if def.Optional && len(consumed.From) == 0 {
+					continue
+				}
+
+				// Rendering only fails when a template calls link() for it
+				if len(consumed.From) > 0 {
+					jobLinks[def.Name] = Link{Unresolved: fmt.Sprintf("Cannot resolve link '%s' of job '%s': no job provides link '%s' with type '%s'",
+						def.Name, releaseJob.Name(), consumed.From, def.Type)}
+					continue
+				}
+
+				jobLinks[def.Name] = Link{Unresolved: fmt.Sprintf("Cannot resolve link '%s' of job '%s': no job provides a link with type '%s'",
+					def.Name, releaseJob.Name(), def.Type)}
+
+			case 1:
+				jobLinks[def.Name] = providedLink(matches[0], releaseJobProperties, jobProperties, globalProperties, deploymentName, address)
+
+			default:
+				var names []string
+				for _, match := range matches {
+					names = append(names, match.job.Name()+"."+match.alias)
+				}
+				sort.Strings(names)
+
+				return nil, bosherr.Errorf("Cannot resolve link '%s' of job '%s': multiple links with type '%s' are provided by %s, use 'from' to choose one",
+					def.Name, releaseJob.Name(), def.Type, strings.Join(names, ", "))
+			}
+		}
+
+		links[releaseJob.Name()] = jobLinks
+	}
+
+	return links, nil
+}
+
+func hasLinkDefinition(defs []bireljob.LinkDefinition, name string) bool {
+	for _, def := range defs {
+		if def.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func manualLink(consumed ConsumedLink, deploymentName string) Link {
+	link := Link{
+		DeploymentName: deploymentName,
+		Properties:     biproperty.Map{},
+		Instances:      []LinkInstance{},
+		Address:        consumed.Address,
+	}
+
+	if consumed.Properties != nil {
+		link.Properties = *consumed.Properties
+	}
+
+	for i, instanceAddress := range consumed.InstanceAddresses {
+		link.Instances = append(link.Instances, LinkInstance{Index: i, Bootstrap: i == 0, Address: instanceAddress})
+	}
+
+	return link
+}
+
+func providedLink(
+	provider linkProvider,
+	releaseJobProperties map[string]*biproperty.Map,
+	jobProperties biproperty.Map,
+	globalProperties biproperty.Map,
+	deploymentName string,
+	address string,
+) Link {
+	// Properties are looked up the same way as for the templates of the providing job
+	var source biproperty.Map
+	if properties := releaseJobProperties[provider.job.Name()]; properties != nil {
+		source = *properties
+	} else {
+		source = mergeProperties(globalProperties, jobProperties)
+	}
+
+	properties := biproperty.Map{}
+
+	for _, name := range provider.def.Properties {
+		value, found := lookupProperty(source, name)
+		if !found {
+			value = provider.job.Properties[name].Default
+		}
+		setProperty(properties, name, value)
+	}
+
+	return Link{
+		DeploymentName: deploymentName,
+		Properties:     properties,
+		Instances:      []LinkInstance{{Index: 0, Bootstrap: true, Address: address}},
+		Address:        address,
+	}
+}
+
+func mergeProperties(base, override biproperty.Map) biproperty.Map {
+	result := biproperty.Map{}
+
+	for key, value := range base {
+		result[key] = value
+	}
+
+	for key, value := range override {
+		baseMap, baseIsMap := propertyMap(result[key])
+		overrideMap, overrideIsMap := propertyMap(value)
+
+		if baseIsMap && overrideIsMap {
+			result[key] = mergeProperties(baseMap, overrideMap)
+		} else {
+			result[key] = value
+		}
+	}
+
+	return result
+}
+
+func lookupProperty(properties biproperty.Map, name string) (interface{}, bool) {
+	var current interface{} = properties
+
+	for _, key := range strings.Split(name, ".") {
+		currentMap, ok := propertyMap(current)
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = currentMap[key]
+		if !ok || current == nil {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+func setProperty(properties biproperty.Map, name string, value interface{}) {
+	keys := strings.Split(name, ".")
+	current := properties
+
+	for _, key := range keys[:len(keys)-1] {
+		next, ok := current[key].(biproperty.Map)
+		if !ok {
+			next = biproperty.Map{}
+			current[key] = next
+		}
+		current = next
+	}
+
+	current[keys[len(keys)-1]] = value
+}
+
+func propertyMap(value interface{}) (biproperty.Map, bool) {
+	typedValue, ok := value.(biproperty.Map)
+	return typedValue, ok
+}
@@ -0,0 +1,199 @@
+package templatescompiler_test
+
+import (
+	biproperty "github.com/cloudfoundry/bosh-utils/property"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	boshreljob "github.com/cloudfoundry/bosh-cli/v7/release/job"
+	. "github.com/cloudfoundry/bosh-cli/v7/release/resource"
+	. "github.com/cloudfoundry/bosh-cli/v7/templatescompiler"
+)
+
+var _ = Describe("ResolveLinks", func() {
+	var (
+		provider             *boshreljob.Job
+		consumer             *boshreljob.Job
+		releaseJobProperties map[string]*biproperty.Map
+		releaseJobLinks      map[string]JobLinks
+		jobProperties        biproperty.Map
+		globalProperties     biproperty.Map
+	)
+
+	BeforeEach(func() {
+		provider = boshreljob.NewJob(NewResource("fake-provider", "", nil))
+		provider.Provides = []boshreljob.LinkDefinition{
+			{Name: "db", Type: "database", Properties: []string{"db.port", "db.user"}},
+		}
+		provider.Properties = map[string]boshreljob.PropertyDefinition{
+			"db.port": {Default: 5432},
+			"db.user": {Default: "admin"},
+		}
+
+		consumer = boshreljob.NewJob(NewResource("fake-consumer", "", nil))
+		consumer.Consumes = []boshreljob.LinkDefinition{
+			{Name: "database", Type: "database"},
+		}
+
+		releaseJobProperties = map[string]*biproperty.Map{}
+		releaseJobLinks = map[string]JobLinks{}
+		jobProperties = biproperty.Map{}
+		globalProperties = biproperty.Map{}
+	})
+
+	resolve := func() (map[string]map[string]Link, error) {
+		return ResolveLinks(
+			[]boshreljob.Job{*provider, *consumer},
+			releaseJobProperties,
+			releaseJobLinks,
+			jobProperties,
+			globalProperties,
+			"fake-deployment",
+			"10.0.0.5",
+		)
+	}
+
+	It("resolves links by type with the properties of the providing job", func() {
+		releaseJobProperties["fake-provider"] = &biproperty.Map{
+			"db": biproperty.Map{"port": 6543},
+		}
+
+		links, err := resolve()
+		Expect(err).ToNot(HaveOccurred())
+
+		Expect(links["fake-consumer"]).To(Equal(map[string]Link{
+			"database": {
+				DeploymentName: "fake-deployment",
+				Properties:     biproperty.Map{"db": biproperty.Map{"port": 6543, "user": "admin"}},
+				Instances:      []LinkInstance{{Index: 0, Bootstrap: true, Address: "10.0.0.5"}},
+				Address:        "10.0.0.5",
+			},
+		}))
+		Expect(links["fake-provider"]).To(BeEmpty())
+	})
+
+	It("uses instance group and global properties when the providing job has none", func() {
+		globalProperties = biproperty.Map{"db": biproperty.Map{"port": 1111, "user": "global-user"}}
+		jobProperties = biproperty.Map{"db": biproperty.Map{"port": 2222}}
+
+		links, err := resolve()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(links["fake-consumer"]["database"].Properties).To(Equal(biproperty.Map{
+			"db": biproperty.Map{"port": 2222, "user": "global-user"},
+		}))
+	})
+
+	It("uses manually defined links", func() {
+		releaseJobLinks["fake-consumer"] = JobLinks{
+			Consumes: map[string]ConsumedLink{
+				"database": {
+					InstanceAddresses: []string{"db.example.com"},
+					Properties:        &biproperty.Map{"port": 5432},
+					Address:           "db.example.com",
+				},
+			},
+		}
+
+		links, err := resolve()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(links["fake-consumer"]["database"]).To(Equal(Link{
+			DeploymentName: "fake-deployment",
+			Properties:     biproperty.Map{"port": 5432},
+			Instances:      []LinkInstance{{Index: 0, Bootstrap: true, Address: "db.example.com"}},
+			Address:        "db.example.com",
+		}))
+	})
+
+	It("does not resolve blocked links", func() {
+		releaseJobLinks["fake-consumer"] = JobLinks{
+			Consumes: map[string]ConsumedLink{"database": {Blocked: true}},
+		}
+
+		links, err := resolve()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(links["fake-consumer"]).To(BeEmpty())
+	})
+
+	It("returns a required link that is not provided as unresolved", func() {
+		releaseJobLinks["fake-provider"] = JobLinks{
+			Provides: map[string]ProvidedLink{"db": {Blocked: true}},
+		}
+
+		links, err := resolve()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(links["fake-consumer"]).To(Equal(map[string]Link{
+			"database": {Unresolved: "Cannot resolve link 'database' of job 'fake-consumer': no job provides a link with type 'database'"},
+		}))
+	})
+
+	It("skips optional links that are not provided", func() {
+		consumer.Consumes[0].Optional = true
+		provider.Provides = nil
+
+		links, err := resolve()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(links["fake-consumer"]).To(BeEmpty())
+	})
+
+	Context("when multiple jobs provide links with the same type", func() {
+		BeforeEach(func() {
+			provider.Provides = append(provider.Provides, boshreljob.LinkDefinition{Name: "replica", Type: "database"})
+		})
+
+		It("returns an error", func() {
+			_, err := resolve()
+			Expect(err).To(HaveOccurred())
+			Expect(err.Error()).To(ContainSubstring("multiple links with type 'database' are provided by fake-provider.db, fake-provider.replica, use 'from' to choose one"))
+		})
+
+		It("resolves the link named by from", func() {
+			releaseJobLinks["fake-provider"] = JobLinks{
+				Provides: map[string]ProvidedLink{"replica": {As: "secondary-db"}},
+			}
+			releaseJobLinks["fake-consumer"] = JobLinks{
+				Consumes: map[string]ConsumedLink{"database": {From: "secondary-db"}},
+			}
+
+			links, err := resolve()
+			Expect(err).ToNot(HaveOccurred())
+			Expect(links["fake-consumer"]["database"].Properties).To(BeEmpty())
+		})
+	})
+
+	It("returns the link as unresolved when from does not name a provided link", func() {
+		releaseJobLinks["fake-consumer"] = JobLinks{
+			Consumes: map[string]ConsumedLink{"database": {From: "other-db"}},
+		}
+
+		links, err := resolve()
+		Expect(err).ToNot(HaveOccurred())
+		Expect(links["fake-consumer"]["database"].Unresolved).To(ContainSubstring("no job provides link 'other-db' with type 'database'"))
+	})
+
+	It("returns an error when consuming links from another deployment", func() {
+		releaseJobLinks["fake-consumer"] = JobLinks{
+			Consumes: map[string]ConsumedLink{"database": {Deployment: "other-deployment"}},
+		}
+
+		_, err := resolve()
+		Expect(err).To(HaveOccurred())
+		Expect(err.Error()).To(ContainSubstring("cannot be consumed from deployment 'other-deployment'"))
+	})
+
+	It("returns an error when configuring links the job does not declare", func() {
+		releaseJobLinks["fake-consumer"] = JobLinks{
+			Consumes: map[string]ConsumedLink{"cache": {From: "cache"}},
+		}
+
+		_, err := resolve()
+		Expect(err).To(MatchError("Job 'fake-consumer' does not consume link 'cache'"))
+
+		releaseJobLinks["fake-consumer"] = JobLinks{}
+		releaseJobLinks["fake-provider"] = JobLinks{
+			Provides: map[string]ProvidedLink{"cache": {As: "cache"}},
+		}
+
+		_, err = resolve()
+		Expect(err).To(MatchError("Job 'fake-provider' does not provide link 'cache'"))
+	})
+})
@@ -112,6 +112,8 @@ func (ui *ColorUI) colorValueFmt(val Value) Value {
 	if valFmt, ok := val.(ValueFmt); ok {
 		if valFmt.Error {
 			valFmt.Func = ui.errFunc
+		} else if valFmt.Bold {
+			valFmt.Func = ui.boldFunc
 		} else {
 			valFmt.Func = ui.okFunc
 		}
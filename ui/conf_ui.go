@@ -1,6 +1,8 @@
 package ui
 
 import (
+	"io"
+
 	boshlog "github.com/cloudfoundry/bosh-utils/logger"
 
 	. "github.com/cloudfoundry/bosh-cli/v7/ui/table"
@@ -11,6 +13,9 @@ type ConfUI struct {
 	isTTY       bool
 	logger      boshlog.Logger
 	showColumns []Header
+
+	writerUI     *WriterUI
+	ndjsonWriter io.Writer
 }
 
 func NewConfUI(logger boshlog.Logger) *ConfUI {
@@ -20,9 +25,10 @@ func NewConfUI(logger boshlog.Logger) *ConfUI {
 	ui = NewPaddingUI(writerUI)
 
 	return &ConfUI{
-		parent: ui,
-		isTTY:  writerUI.IsTTY(),
-		logger: logger,
+		parent:   ui,
+		isTTY:    writerUI.IsTTY(),
+		logger:   logger,
+		writerUI: writerUI,
 	}
 }
 
@@ -35,11 +41,20 @@ func NewWrappingConfUI(parent UI, logger boshlog.Logger) *ConfUI {
 }
 
 func (ui *ConfUI) EnableTTY(force bool) {
-	if !ui.isTTY && !force {
+	if force {
+		ui.isTTY = true
+	}
+
+	if !ui.isTTY {
 		ui.parent = NewNonTTYUI(ui.parent)
 	}
 }
 
+// IsTTY tells whether output goes to a terminal or TTY-like output was forced
+func (ui *ConfUI) IsTTY() bool {
+	return ui.isTTY
+}
+
 func (ui *ConfUI) EnableColor() {
 	ui.parent = NewColorUI(ui.parent)
 }
@@ -48,6 +63,22 @@ func (ui *ConfUI) EnableJSON() {
 	ui.parent = NewJSONUI(ui.parent, ui.logger)
 }
 
+// EnableNDJSON keeps stdout for newline-delimited JSON
+// and sends all other output to stderr
+func (ui *ConfUI) EnableNDJSON() {
+	if ui.writerUI != nil {
+		ui.ndjsonWriter = ui.writerUI.DivertOutput()
+	}
+}
+
+// NDJSONWriter returns the writer for newline-delimited JSON
+func (ui *ConfUI) NDJSONWriter() io.Writer {
+	if ui.ndjsonWriter != nil {
+		return ui.ndjsonWriter
+	}
+	return blockWriter{ui: ui.parent}
+}
+
 func (ui *ConfUI) ShowColumns(columns []Header) {
 	ui.showColumns = columns
 }
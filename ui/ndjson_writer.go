@@ -0,0 +1,27 @@
+package ui
+
+import (
+	"io"
+)
+
+type ndjsonWriterUI interface {
+	NDJSONWriter() io.Writer
+}
+
+// NDJSONWriter returns the writer of the UI that is kept free of other output
+// when --ndjson is given, or a writer that prints blocks to the UI otherwise
+func NDJSONWriter(ui UI) io.Writer {
+	if writerUI, ok := ui.(ndjsonWriterUI); ok {
+		return writerUI.NDJSONWriter()
+	}
+	return blockWriter{ui: ui}
+}
+
+type blockWriter struct {
+	ui UI
+}
+
+func (w blockWriter) Write(block []byte) (int, error) {
+	w.ui.PrintBlock(block)
+	return len(block), nil
+}
@@ -0,0 +1,46 @@
+package ui_test
+
+import (
+	"bytes"
+
+	boshlog "github.com/cloudfoundry/bosh-utils/logger"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/ui"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("NDJSONWriter", func() {
+	It("prints blocks to UIs that do not keep a separate writer", func() {
+		ui := &fakeui.FakeUI{}
+
+		n, err := NDJSONWriter(ui).Write([]byte("{}\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(n).To(Equal(3))
+		Expect(ui.Blocks).To(Equal([]string{"{}\n"}))
+	})
+
+	It("prints blocks to the parent of a wrapping conf UI", func() {
+		parent := &fakeui.FakeUI{}
+		ui := NewWrappingConfUI(parent, boshlog.NewLogger(boshlog.LevelNone))
+		ui.EnableNDJSON()
+
+		_, err := NDJSONWriter(ui).Write([]byte("{}\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(parent.Blocks).To(Equal([]string{"{}\n"}))
+	})
+
+	It("returns the original output writer of a writer UI after diverting its output", func() {
+		out, errOut := &bytes.Buffer{}, &bytes.Buffer{}
+		ui := NewWriterUI(out, errOut, boshlog.NewLogger(boshlog.LevelNone))
+
+		writer := ui.DivertOutput()
+		ui.PrintLinef("fake-line")
+
+		_, err := writer.Write([]byte("{}\n"))
+		Expect(err).ToNot(HaveOccurred())
+		Expect(out.String()).To(Equal("{}\n"))
+		Expect(errOut.String()).To(Equal("fake-line\n"))
+	})
+})
@@ -94,6 +94,7 @@ type ValueNone struct{}
 type ValueFmt struct {
 	V     Value
 	Error bool
+	Bold  bool
 	Func  func(string, ...interface{}) string
 }
 
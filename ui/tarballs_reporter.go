@@ -0,0 +1,24 @@
+package ui
+
+import (
+	"github.com/dustin/go-humanize"
+)
+
+type TarballsReporter struct {
+	ui UI
+}
+
+func NewTarballsReporter(ui UI) TarballsReporter {
+	return TarballsReporter{ui: ui}
+}
+
+func (r TarballsReporter) TarballsDownloadProgress(downloadedBytes, totalBytes int64, finished, count int) {
+	if totalBytes < 0 {
+		r.ui.BeginLinef("Downloaded %s (%d of %d tarballs)\n",
+			humanize.IBytes(uint64(downloadedBytes)), finished, count)
+		return
+	}
+
+	r.ui.BeginLinef("Downloaded %s of %s (%d of %d tarballs)\n",
+		humanize.IBytes(uint64(downloadedBytes)), humanize.IBytes(uint64(totalBytes)), finished, count)
+}
@@ -0,0 +1,33 @@
+package ui_test
+
+import (
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	. "github.com/cloudfoundry/bosh-cli/v7/ui"
+	fakeui "github.com/cloudfoundry/bosh-cli/v7/ui/fakes"
+)
+
+var _ = Describe("TarballsReporter", func() {
+	var (
+		ui       *fakeui.FakeUI
+		reporter TarballsReporter
+	)
+
+	BeforeEach(func() {
+		ui = &fakeui.FakeUI{}
+		reporter = NewTarballsReporter(ui)
+	})
+
+	Describe("TarballsDownloadProgress", func() {
+		It("prints downloaded and total bytes", func() {
+			reporter.TarballsDownloadProgress(1024, 3*1024*1024, 1, 3)
+			Expect(ui.Said).To(Equal([]string{"Downloaded 1.0 KiB of 3.0 MiB (1 of 3 tarballs)\n"}))
+		})
+
+		It("prints downloaded bytes when the total is unknown", func() {
+			reporter.TarballsDownloadProgress(1024, -1, 0, 2)
+			Expect(ui.Said).To(Equal([]string{"Downloaded 1.0 KiB (0 of 2 tarballs)\n"}))
+		})
+	})
+})
@@ -0,0 +1,36 @@
+package task
+
+import (
+	"strings"
+)
+
+// eventLines splits the event output of tasks into lines; the incomplete
+// last line of a chunk is kept until a later chunk completes it
+type eventLines map[int]string
+
+// Add returns the non-empty lines that the chunk completes
+func (l eventLines) Add(id int, chunk []byte) []string {
+	var lines []string
+
+	l[id] += string(chunk)
+
+	for {
+		idx := strings.Index(l[id], "\n")
+		if idx == -1 {
+			break
+		}
+		if len(l[id][0:idx]) > 0 {
+			lines = append(lines, l[id][0:idx])
+		}
+		l[id] = l[id][idx+1:]
+	}
+
+	return lines
+}
+
+// Flush returns the last line when the output ended without a newline
+func (l eventLines) Flush(id int) string {
+	rest := l[id]
+	delete(l, id)
+	return rest
+}
@@ -0,0 +1,188 @@
+package task
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+	"time"
+
+	"code.cloudfoundry.org/clock"
+)
+
+const (
+	ndjsonTypeEvent = "event"
+	ndjsonTypeTask  = "task"
+)
+
+// NDJSONReporter prints one JSON object per line for each task event
+// and task state change as soon as they are received
+type NDJSONReporter struct {
+	writer      io.Writer
+	timeService clock.Clock
+
+	events      map[int][]*Event
+	states      map[int]string
+	outputLines eventLines
+	sync.Mutex
+}
+
+type ndjsonTaskLine struct {
+	Type   string `json:"type"`
+	TaskID int    `json:"task_id"`
+	State  string `json:"state"`
+	Time   string `json:"time"`
+}
+
+type ndjsonEventLine struct {
+	Type   string `json:"type"`
+	TaskID int    `json:"task_id"`
+	Time   string `json:"time"`
+
+	EventType string `json:"event_type,omitempty"`
+	Message   string `json:"message,omitempty"`
+
+	Stage    string   `json:"stage,omitempty"`
+	Task     string   `json:"task,omitempty"`
+	Tags     []string `json:"tags,omitempty"`
+	Index    int      `json:"index"`
+	Total    int      `json:"total,omitempty"`
+	State    string   `json:"state,omitempty"`
+	Progress int      `json:"progress"`
+	Status   string   `json:"status,omitempty"`
+
+	Error     string `json:"error,omitempty"`
+	ErrorCode int    `json:"error_code,omitempty"`
+
+	StartedAt       string   `json:"started_at,omitempty"`
+	DurationSeconds *float64 `json:"duration_seconds,omitempty"`
+}
+
+// NewNDJSONReporter writes to its own writer so that
+// other output cannot end up between the JSON lines
+func NewNDJSONReporter(writer io.Writer, timeService clock.Clock) *NDJSONReporter {
+	return &NDJSONReporter{
+		writer:      writer,
+		timeService: timeService,
+		events:      map[int][]*Event{},
+		states:      map[int]string{},
+		outputLines: eventLines{},
+	}
+}
+
+func (r *NDJSONReporter) TaskStarted(id int) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.events[id] = []*Event{}
+}
+
+// TaskStateChanged lets the director report intermediate task states
+func (r *NDJSONReporter) TaskStateChanged(id int, state string) {
+	r.Lock()
+	defer r.Unlock()
+
+	r.showState(id, state)
+}
+
+func (r *NDJSONReporter) TaskFinished(id int, state string) {
+	r.Lock()
+	defer r.Unlock()
+
+	if line := r.outputLines.Flush(id); len(line) > 0 {
+		r.showEvent(id, line)
+	}
+
+	r.showState(id, state)
+}
+
+func (r *NDJSONReporter) TaskOutputChunk(id int, chunk []byte) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, line := range r.outputLines.Add(id, chunk) {
+		r.showEvent(id, line)
+	}
+}
+
+func (r *NDJSONReporter) showState(id int, state string) {
+	if len(state) == 0 || r.states[id] == state {
+		return
+	}
+
+	r.states[id] = state
+
+	r.printLine(ndjsonTaskLine{
+		Type:   ndjsonTypeTask,
+		TaskID: id,
+		State:  state,
+		Time:   r.now(),
+	})
+}
+
+func (r *NDJSONReporter) showEvent(id int, str string) {
+	event := Event{TaskID: id}
+
+	err := json.Unmarshal([]byte(str), &event)
+	if err != nil {
+		// Pass through lines that are not events instead of failing the whole stream
+		r.printLine(ndjsonEventLine{Type: ndjsonTypeEvent, TaskID: id, Time: r.now(), Message: str})
+		return
+	}
+
+	for _, ev := range r.events[id] {
+		if ev.IsSame(event) {
+			event.StartEvent = ev
+			break
+		}
+	}
+
+	line := ndjsonEventLine{
+		Type:   ndjsonTypeEvent,
+		TaskID: id,
+		Time:   event.Time().Format(time.RFC3339),
+
+		EventType: event.Type,
+		Message:   event.Message,
+
+		Stage:    event.Stage,
+		Task:     event.Task,
+		Tags:     event.Tags,
+		Index:    event.Index,
+		Total:    event.Total,
+		State:    event.State,
+		Progress: event.Progress,
+		Status:   event.Data.Status,
+
+		Error: event.Data.Error,
+	}
+
+	if event.Error != nil {
+		line.Error = event.Error.Message
+		line.ErrorCode = event.Error.Code
+	}
+
+	if event.StartEvent != nil && (event.State == EventStateFinished || event.State == EventStateFailed) {
+		duration := event.Time().Sub(event.StartEvent.Time()).Seconds()
+		line.StartedAt = event.StartEvent.Time().Format(time.RFC3339)
+		line.DurationSeconds = &duration
+	}
+
+	r.printLine(line)
+
+	if event.IsWorthKeeping() {
+		r.events[id] = append(r.events[id], &event)
+	}
+}
+
+func (r *NDJSONReporter) now() string {
+	return r.timeService.Now().UTC().Format(time.RFC3339)
+}
+
+func (r *NDJSONReporter) printLine(line interface{}) {
+	bytes, err := json.Marshal(line)
+	if err != nil {
+		return
+	}
+
+	_, _ = r.writer.Write(append(bytes, '\n'))
+}
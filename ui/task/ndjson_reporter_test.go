@@ -0,0 +1,158 @@
+package task_test
+
+import (
+	"bytes"
+	"strings"
+	"time"
+
+	"code.cloudfoundry.org/clock/fakeclock"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	boshuit "github.com/cloudfoundry/bosh-cli/v7/ui/task"
+)
+
+var _ = Describe("NDJSONReporter", func() {
+	var (
+		out         *bytes.Buffer
+		timeService *fakeclock.FakeClock
+		reporter    *boshuit.NDJSONReporter
+	)
+
+	BeforeEach(func() {
+		out = &bytes.Buffer{}
+		timeService = fakeclock.NewFakeClock(time.Date(2016, time.January, 9, 6, 23, 25, 0, time.UTC))
+		reporter = boshuit.NewNDJSONReporter(out, timeService)
+	})
+
+	lines := func() []string {
+		if out.Len() == 0 {
+			return nil
+		}
+		return strings.SplitAfter(strings.TrimSuffix(out.String(), "\n"), "\n")
+	}
+
+	It("prints each event on its own line as soon as it is complete", func() {
+		reporter.TaskStarted(123)
+		reporter.TaskOutputChunk(123, []byte(`{"time":1452320605,"stage":"Updating instance","tags":["api"],"task":"api/0","index":1,"total":2,"state":"started","progress":0}`))
+
+		Expect(lines()).To(BeEmpty())
+
+		reporter.TaskOutputChunk(123, []byte("\n"+`{"time":1452320615,"stage":"Updating instance","tags":["api"],"task":"api/0","index":1,"total":2,"state":"finished","progress":100}`+"\n"))
+
+		Expect(lines()).To(HaveLen(2))
+		Expect(lines()[0]).To(HaveSuffix("\n"))
+		Expect(lines()[0]).To(MatchJSON(`{
+			"type": "event",
+			"task_id": 123,
+			"time": "2016-01-09T06:23:25Z",
+			"stage": "Updating instance",
+			"task": "api/0",
+			"tags": ["api"],
+			"index": 1,
+			"total": 2,
+			"state": "started",
+			"progress": 0
+		}`))
+		Expect(lines()[1]).To(MatchJSON(`{
+			"type": "event",
+			"task_id": 123,
+			"time": "2016-01-09T06:23:35Z",
+			"stage": "Updating instance",
+			"task": "api/0",
+			"tags": ["api"],
+			"index": 1,
+			"total": 2,
+			"state": "finished",
+			"progress": 100,
+			"started_at": "2016-01-09T06:23:25Z",
+			"duration_seconds": 10
+		}`))
+	})
+
+	It("includes failures, task errors, statuses and warnings", func() {
+		reporter.TaskStarted(123)
+		reporter.TaskOutputChunk(123, []byte(
+			`{"time":1452320605,"stage":"Updating instance","task":"api/0","state":"in_progress","data":{"status":"installing packages"}}`+"\n"+
+				`{"time":1452320606,"stage":"Updating instance","task":"api/0","state":"failed","data":{"error":"'api/0' is not running after update"}}`+"\n"+
+				`{"time":1452320607,"error":{"code":100,"message":"Bosh::Director::Lock::TimeoutError"}}`+"\n"+
+				`{"time":1452320608,"type":"warning","message":"fake-warning"}`+"\n"))
+
+		Expect(lines()).To(HaveLen(4))
+		Expect(lines()[0]).To(MatchJSON(`{
+			"type": "event",
+			"task_id": 123,
+			"time": "2016-01-09T06:23:25Z",
+			"stage": "Updating instance",
+			"task": "api/0",
+			"index": 0,
+			"state": "in_progress",
+			"progress": 0,
+			"status": "installing packages"
+		}`))
+		Expect(lines()[1]).To(MatchJSON(`{
+			"type": "event",
+			"task_id": 123,
+			"time": "2016-01-09T06:23:26Z",
+			"stage": "Updating instance",
+			"task": "api/0",
+			"index": 0,
+			"state": "failed",
+			"progress": 0,
+			"error": "'api/0' is not running after update",
+			"started_at": "2016-01-09T06:23:25Z",
+			"duration_seconds": 1
+		}`))
+		Expect(lines()[2]).To(MatchJSON(`{
+			"type": "event",
+			"task_id": 123,
+			"time": "2016-01-09T06:23:27Z",
+			"index": 0,
+			"progress": 0,
+			"error": "Bosh::Director::Lock::TimeoutError",
+			"error_code": 100
+		}`))
+		Expect(lines()[3]).To(MatchJSON(`{
+			"type": "event",
+			"task_id": 123,
+			"time": "2016-01-09T06:23:28Z",
+			"event_type": "warning",
+			"message": "fake-warning",
+			"index": 0,
+			"progress": 0
+		}`))
+	})
+
+	It("passes through lines that are not events", func() {
+		reporter.TaskOutputChunk(123, []byte("not-json\n"))
+
+		Expect(lines()).To(HaveLen(1))
+		Expect(lines()[0]).To(MatchJSON(`{"type":"event","task_id":123,"time":"2016-01-09T06:23:25Z","message":"not-json","index":0,"progress":0}`))
+	})
+
+	It("prints task state changes once", func() {
+		reporter.TaskStarted(123)
+		reporter.TaskStateChanged(123, "queued")
+		reporter.TaskStateChanged(123, "processing")
+
+		timeService.Increment(time.Minute)
+
+		reporter.TaskStateChanged(123, "done")
+		reporter.TaskFinished(123, "done")
+
+		Expect(lines()).To(HaveLen(3))
+		Expect(lines()[0]).To(MatchJSON(`{"type":"task","task_id":123,"state":"queued","time":"2016-01-09T06:23:25Z"}`))
+		Expect(lines()[1]).To(MatchJSON(`{"type":"task","task_id":123,"state":"processing","time":"2016-01-09T06:23:25Z"}`))
+		Expect(lines()[2]).To(MatchJSON(`{"type":"task","task_id":123,"state":"done","time":"2016-01-09T06:24:25Z"}`))
+	})
+
+	It("prints final task state and any incomplete last event when task finishes", func() {
+		reporter.TaskStarted(123)
+		reporter.TaskOutputChunk(123, []byte(`{"time":1452320605,"stage":"Deleting","task":"api/0","state":"started"}`))
+		reporter.TaskFinished(123, "error")
+
+		Expect(lines()).To(HaveLen(2))
+		Expect(lines()[0]).To(ContainSubstring(`"stage":"Deleting"`))
+		Expect(lines()[1]).To(MatchJSON(`{"type":"task","task_id":123,"state":"error","time":"2016-01-09T06:23:25Z"}`))
+	})
+})
@@ -20,7 +20,7 @@ type ReporterImpl struct {
 	eventMarkers    []eventMarker
 	lastGlobalEvent *Event
 
-	outputRest map[int]string
+	outputLines eventLines
 	sync.Mutex
 }
 
@@ -41,7 +41,7 @@ func NewReporter(ui boshui.UI, isForEvents bool) *ReporterImpl {
 		isForEvents:  isForEvents,
 		events:       map[int][]*Event{},
 		eventMarkers: []eventMarker{},
-		outputRest:   map[int]string{},
+		outputLines:  eventLines{},
 	}
 }
 
@@ -92,17 +92,8 @@ func (r *ReporterImpl) TaskOutputChunk(id int, chunk []byte) {
 	}
 
 	if r.isForEvents {
-		r.outputRest[id] += string(chunk)
-
-		for {
-			idx := strings.Index(r.outputRest[id], "\n")
-			if idx == -1 {
-				break
-			}
-			if len(r.outputRest[id][0:idx]) > 0 {
-				r.showEvent(id, r.outputRest[id][0:idx])
-			}
-			r.outputRest[id] = r.outputRest[id][idx+1:]
+		for _, line := range r.outputLines.Add(id, chunk) {
+			r.showEvent(id, line)
 		}
 	} else {
 		r.showChunk(chunk)
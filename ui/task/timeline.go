@@ -0,0 +1,284 @@
+package task
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// TimelineStep is a single task of a stage, e.g. updating one instance
+type TimelineStep struct {
+	Stage string
+	Tags  []string
+	Task  string
+
+	Index int
+	Total int
+
+	// State is the last event state seen for the step, e.g. "finished" or "failed";
+	// steps that are still "started" are considered to run until the end of the timeline
+	State string
+
+	Started  time.Time
+	Finished time.Time
+}
+
+func (s TimelineStep) Duration() time.Duration {
+	return s.Finished.Sub(s.Started)
+}
+
+// TimelineStage groups steps with the same stage name and tags
+type TimelineStage struct {
+	Name string
+	Tags []string
+
+	Started  time.Time
+	Finished time.Time
+
+	Steps []*TimelineStep
+}
+
+func (s TimelineStage) Duration() time.Duration {
+	return s.Finished.Sub(s.Started)
+}
+
+// TimelineGap is a period of time when no step was running
+type TimelineGap struct {
+	Started  time.Time
+	Finished time.Time
+}
+
+func (g TimelineGap) Duration() time.Duration {
+	return g.Finished.Sub(g.Started)
+}
+
+type Timeline struct {
+	Started  time.Time
+	Finished time.Time
+
+	Stages []*TimelineStage
+}
+
+// NewTimeline pairs started events with their finished or failed events
+func NewTimeline(events []Event) Timeline {
+	var timeline Timeline
+
+	for i := range events {
+		event := events[i]
+
+		if timeline.Started.IsZero() || event.Time().Before(timeline.Started) {
+			timeline.Started = event.Time()
+		}
+
+		if event.Time().After(timeline.Finished) {
+			timeline.Finished = event.Time()
+		}
+
+		if len(event.Stage) == 0 {
+			continue
+		}
+
+		switch event.State {
+		case EventStateStarted:
+			stage := timeline.findOrAddStage(event)
+			stage.Steps = append(stage.Steps, &TimelineStep{
+				Stage:   event.Stage,
+				Tags:    event.Tags,
+				Task:    event.Task,
+				Index:   event.Index,
+				Total:   event.Total,
+				State:   event.State,
+				Started: event.Time(),
+			})
+
+		case EventStateFinished, EventStateFailed:
+			stage := timeline.findOrAddStage(event)
+			step := stage.findRunningStep(event)
+
+			if step == nil {
+				step = &TimelineStep{
+					Stage:   event.Stage,
+					Tags:    event.Tags,
+					Task:    event.Task,
+					Index:   event.Index,
+					Total:   event.Total,
+					Started: event.Time(),
+				}
+				stage.Steps = append(stage.Steps, step)
+			}
+
+			step.State = event.State
+			step.Finished = event.Time()
+		}
+	}
+
+	for _, stage := range timeline.Stages {
+		for _, step := range stage.Steps {
+			if step.Finished.IsZero() {
+				step.Finished = timeline.Finished
+			}
+
+			if stage.Started.IsZero() || step.Started.Before(stage.Started) {
+				stage.Started = step.Started
+			}
+
+			if step.Finished.After(stage.Finished) {
+				stage.Finished = step.Finished
+			}
+		}
+	}
+
+	return timeline
+}
+
+func (t Timeline) Duration() time.Duration {
+	return t.Finished.Sub(t.Started)
+}
+
+func (t Timeline) Steps() []*TimelineStep {
+	var steps []*TimelineStep
+
+	for _, stage := range t.Stages {
+		steps = append(steps, stage.Steps...)
+	}
+
+	return steps
+}
+
+// CriticalPath walks back from the step that finished last,
+// each time picking the step that finished last before the current one started
+func (t Timeline) CriticalPath() []*TimelineStep {
+	steps := t.Steps()
+
+	sort.SliceStable(steps, func(i, j int) bool {
+		if steps[i].Finished.Equal(steps[j].Finished) {
+			return steps[i].Started.After(steps[j].Started)
+		}
+		return steps[i].Finished.Before(steps[j].Finished)
+	})
+
+	var path []*TimelineStep
+
+	for i := len(steps) - 1; i >= 0; {
+		current := steps[i]
+		path = append([]*TimelineStep{current}, path...)
+
+		next := -1
+
+		for j := i - 1; j >= 0; j-- {
+			if !steps[j].Finished.After(current.Started) {
+				next = j
+				break
+			}
+		}
+
+		i = next
+	}
+
+	return path
+}
+
+// IdleGaps returns periods between the start and the end of the timeline when no step was running
+func (t Timeline) IdleGaps() []TimelineGap {
+	steps := t.Steps()
+
+	sort.SliceStable(steps, func(i, j int) bool {
+		return steps[i].Started.Before(steps[j].Started)
+	})
+
+	var gaps []TimelineGap
+
+	busyUntil := t.Started
+
+	for _, step := range steps {
+		if step.Started.After(busyUntil) {
+			gaps = append(gaps, TimelineGap{Started: busyUntil, Finished: step.Started})
+		}
+
+		if step.Finished.After(busyUntil) {
+			busyUntil = step.Finished
+		}
+	}
+
+	if t.Finished.After(busyUntil) {
+		gaps = append(gaps, TimelineGap{Started: busyUntil, Finished: t.Finished})
+	}
+
+	return gaps
+}
+
+func (t *Timeline) findOrAddStage(event Event) *TimelineStage {
+	for _, stage := range t.Stages {
+		if stage.Name == event.Stage && reflect.DeepEqual(stage.Tags, event.Tags) {
+			return stage
+		}
+	}
+
+	stage := &TimelineStage{Name: event.Stage, Tags: event.Tags}
+	t.Stages = append(t.Stages, stage)
+
+	return stage
+}
+
+func (s *TimelineStage) findRunningStep(event Event) *TimelineStep {
+	for _, step := range s.Steps {
+		if step.Task == event.Task && step.Finished.IsZero() {
+			return step
+		}
+	}
+
+	return nil
+}
+
+// TimelineReporter keeps task events so that they can be shown as a timeline
+// once the event log was fully read
+type TimelineReporter struct {
+	events      map[int][]Event
+	outputLines eventLines
+	sync.Mutex
+}
+
+func NewTimelineReporter() *TimelineReporter {
+	return &TimelineReporter{
+		events:      map[int][]Event{},
+		outputLines: eventLines{},
+	}
+}
+
+func (r *TimelineReporter) TaskStarted(id int) {}
+
+func (r *TimelineReporter) TaskFinished(id int, state string) {
+	r.Lock()
+	defer r.Unlock()
+
+	if line := r.outputLines.Flush(id); len(line) > 0 {
+		r.addEvent(id, line)
+	}
+}
+
+func (r *TimelineReporter) TaskOutputChunk(id int, chunk []byte) {
+	r.Lock()
+	defer r.Unlock()
+
+	for _, line := range r.outputLines.Add(id, chunk) {
+		r.addEvent(id, line)
+	}
+}
+
+func (r *TimelineReporter) addEvent(id int, line string) {
+	event := Event{TaskID: id}
+
+	// Lines that are not events do not belong on the timeline
+	if json.Unmarshal([]byte(line), &event) == nil {
+		r.events[id] = append(r.events[id], event)
+	}
+}
+
+func (r *TimelineReporter) Timeline(id int) Timeline {
+	r.Lock()
+	defer r.Unlock()
+
+	return NewTimeline(r.events[id])
+}
@@ -0,0 +1,141 @@
+package task_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+
+	boshuit "github.com/cloudfoundry/bosh-cli/v7/ui/task"
+)
+
+var _ = Describe("Timeline", func() {
+	var (
+		reporter *boshuit.TimelineReporter
+	)
+
+	at := func(sec int64) time.Time { return time.Unix(1452320600+sec, 0).UTC() }
+
+	BeforeEach(func() {
+		reporter = boshuit.NewTimelineReporter()
+	})
+
+	build := func(lines ...string) boshuit.Timeline {
+		reporter.TaskStarted(123)
+		for _, line := range lines {
+			reporter.TaskOutputChunk(123, []byte(line+"\n"))
+		}
+		reporter.TaskFinished(123, "done")
+		return reporter.Timeline(123)
+	}
+
+	It("groups steps into stages and pairs their start and end events", func() {
+		timeline := build(
+			`{"time":1452320600,"stage":"Preparing deployment","task":"Binding deployment","index":1,"total":1,"state":"started"}`,
+			`{"time":1452320602,"stage":"Preparing deployment","task":"Binding deployment","index":1,"total":1,"state":"finished"}`,
+			`{"time":1452320605,"stage":"Updating instance","tags":["api"],"task":"api/0 (canary)","index":1,"total":2,"state":"started"}`,
+			`{"time":1452320606,"stage":"Updating instance","tags":["api"],"task":"api/0 (canary)","index":1,"total":2,"state":"in_progress","data":{"status":"installing"}}`,
+			`{"time":1452320615,"stage":"Updating instance","tags":["api"],"task":"api/0 (canary)","index":1,"total":2,"state":"finished"}`,
+			`{"time":1452320615,"stage":"Updating instance","tags":["api"],"task":"api/1","index":2,"total":2,"state":"started"}`,
+			`{"time":1452320620,"stage":"Updating instance","tags":["api"],"task":"api/1","index":2,"total":2,"state":"failed","data":{"error":"fake-err"}}`,
+			`{"time":1452320621,"error":{"code":100,"message":"fake-task-err"}}`,
+			"not-json",
+		)
+
+		Expect(timeline.Started).To(Equal(at(0)))
+		Expect(timeline.Finished).To(Equal(at(21)))
+		Expect(timeline.Duration()).To(Equal(21 * time.Second))
+
+		Expect(timeline.Stages).To(HaveLen(2))
+
+		Expect(timeline.Stages[0].Name).To(Equal("Preparing deployment"))
+		Expect(timeline.Stages[0].Duration()).To(Equal(2 * time.Second))
+
+		stage := timeline.Stages[1]
+		Expect(stage.Name).To(Equal("Updating instance"))
+		Expect(stage.Tags).To(Equal([]string{"api"}))
+		Expect(stage.Started).To(Equal(at(5)))
+		Expect(stage.Finished).To(Equal(at(20)))
+
+		Expect(stage.Steps).To(Equal([]*boshuit.TimelineStep{
+			{
+				Stage: "Updating instance", Tags: []string{"api"}, Task: "api/0 (canary)",
+				Index: 1, Total: 2, State: "finished", Started: at(5), Finished: at(15),
+			},
+			{
+				Stage: "Updating instance", Tags: []string{"api"}, Task: "api/1",
+				Index: 2, Total: 2, State: "failed", Started: at(15), Finished: at(20),
+			},
+		}))
+	})
+
+	It("considers steps without end events to run until the end of the timeline", func() {
+		timeline := build(
+			`{"time":1452320600,"stage":"Compiling packages","task":"ruby/abc","index":1,"total":1,"state":"started"}`,
+			`{"time":1452320610,"stage":"Compiling packages","task":"ruby/abc","index":1,"total":1,"state":"in_progress"}`,
+		)
+
+		steps := timeline.Steps()
+		Expect(steps).To(HaveLen(1))
+		Expect(steps[0].State).To(Equal("started"))
+		Expect(steps[0].Duration()).To(Equal(10 * time.Second))
+	})
+
+	It("keeps events split across chunks and the last event without a trailing newline", func() {
+		reporter.TaskStarted(123)
+		reporter.TaskOutputChunk(123, []byte(`{"time":1452320600,"stage":"Compiling packages","task":"ruby/abc",`))
+		reporter.TaskOutputChunk(123, []byte(`"index":1,"total":1,"state":"started"}`+"\n"))
+		reporter.TaskOutputChunk(123, []byte(`{"time":1452320610,"stage":"Compiling packages","task":"ruby/abc","index":1,"total":1,"state":"finished"}`))
+		reporter.TaskFinished(123, "done")
+
+		steps := reporter.Timeline(123).Steps()
+		Expect(steps).To(HaveLen(1))
+		Expect(steps[0].State).To(Equal("finished"))
+		Expect(steps[0].Duration()).To(Equal(10 * time.Second))
+	})
+
+	Describe("CriticalPath", func() {
+		It("follows the longest chain of steps that ends last", func() {
+			timeline := build(
+				`{"time":1452320600,"stage":"Compiling packages","task":"a","state":"started"}`,
+				`{"time":1452320600,"stage":"Compiling packages","task":"b","state":"started"}`,
+				`{"time":1452320603,"stage":"Compiling packages","task":"a","state":"finished"}`,
+				`{"time":1452320608,"stage":"Compiling packages","task":"b","state":"finished"}`,
+				`{"time":1452320610,"stage":"Updating instance","task":"c","state":"started"}`,
+				`{"time":1452320610,"stage":"Updating instance","task":"d","state":"started"}`,
+				`{"time":1452320620,"stage":"Updating instance","task":"c","state":"finished"}`,
+				`{"time":1452320612,"stage":"Updating instance","task":"d","state":"finished"}`,
+			)
+
+			var tasks []string
+			for _, step := range timeline.CriticalPath() {
+				tasks = append(tasks, step.Task)
+			}
+
+			Expect(tasks).To(Equal([]string{"b", "c"}))
+		})
+
+		It("returns no steps when there are none", func() {
+			Expect(build().CriticalPath()).To(BeEmpty())
+		})
+	})
+
+	Describe("IdleGaps", func() {
+		It("returns periods when no step was running", func() {
+			timeline := build(
+				`{"time":1452320600,"stage":"Compiling packages","task":"a","state":"started"}`,
+				`{"time":1452320600,"stage":"Compiling packages","task":"b","state":"started"}`,
+				`{"time":1452320603,"stage":"Compiling packages","task":"a","state":"finished"}`,
+				`{"time":1452320608,"stage":"Compiling packages","task":"b","state":"finished"}`,
+				`{"time":1452320610,"stage":"Updating instance","task":"c","state":"started"}`,
+				`{"time":1452320620,"stage":"Updating instance","task":"c","state":"finished"}`,
+				`{"time":1452320625,"error":{"code":100,"message":"fake-task-err"}}`,
+			)
+
+			Expect(timeline.IdleGaps()).To(Equal([]boshuit.TimelineGap{
+				{Started: at(8), Finished: at(10)},
+				{Started: at(20), Finished: at(25)},
+			}))
+		})
+	})
+})
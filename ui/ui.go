@@ -35,6 +35,14 @@ func NewWriterUI(outWriter, errWriter io.Writer, logger boshlog.Logger) *WriterU
 	}
 }
 
+// DivertOutput sends all further output to the error writer and returns
+// the original output writer for output that must not be mixed with it
+func (ui *WriterUI) DivertOutput() io.Writer {
+	outWriter := ui.outWriter
+	ui.outWriter = ui.errWriter
+	return outWriter
+}
+
 func (ui *WriterUI) IsTTY() bool {
 	file, ok := ui.outWriter.(*os.File)
 
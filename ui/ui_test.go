@@ -151,6 +151,18 @@ var _ = Describe("UI", func() {
 		})
 	})
 
+	Describe("DivertOutput", func() {
+		It("sends further output to errWriter and returns outWriter", func() {
+			writer := ui.(*WriterUI).DivertOutput()
+			Expect(writer).To(BeIdenticalTo(uiOut))
+
+			ui.PrintLinef("fake-line")
+			ui.PrintBlock([]byte("block"))
+			Expect(uiOutBuffer.String()).To(Equal(""))
+			Expect(uiErrBuffer.String()).To(Equal("fake-line\nblock"))
+		})
+	})
+
 	Describe("PrintErrorBlock", func() {
 		It("prints to outWriter as is", func() {
 			ui.PrintErrorBlock("block")